@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// dayLayout é o formato das datas de -from e -to
+const dayLayout = "2006-01-02"
+
+var appointmentCommands = map[string]command{
+	"list":   {usage: "[-dentist \"CRO-UF 00000\" -patient D -from AAAA-MM-DD -to AAAA-MM-DD -status S]", summary: "lista as consultas, em ordem de data", run: listAppointments},
+	"book":   {usage: "-dentist \"CRO-UF 00000\" -patient D -date \"DD/MM/AAAA HH:MM\" -description T [-procedure C -duration M]", summary: "agenda uma consulta", run: bookAppointment},
+	"cancel": {usage: "<id>", summary: "cancela uma consulta", run: cancelAppointment},
+}
+
+func listAppointments(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("appointments list")
+	dentist := flags.String("dentist", "", "CRO do dentista")
+	patient := flags.String("patient", "", "documento do paciente")
+	from := flags.String("from", "", "primeiro dia, "+dayLayout)
+	to := flags.String("to", "", "último dia, "+dayLayout)
+	status := flags.String("status", "", "situação da consulta")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+
+	var registration string
+	if *dentist != "" {
+		r, err := domain.ParseRegistration(*dentist)
+		if err != nil {
+			return err
+		}
+		registration = r.String()
+	}
+	var start, end time.Time
+	if *from != "" {
+		day, err := time.Parse(dayLayout, *from)
+		if err != nil {
+			return fmt.Errorf("invalid -from, expected format %s", dayLayout)
+		}
+		start = day
+	}
+	if *to != "" {
+		day, err := time.Parse(dayLayout, *to)
+		if err != nil {
+			return fmt.Errorf("invalid -to, expected format %s", dayLayout)
+		}
+		// o último dia entra inteiro
+		end = day.AddDate(0, 0, 1)
+	}
+
+	all, err := e.backend.Appointments(ctx)
+	if err != nil {
+		return err
+	}
+	type dated struct {
+		domain.AppointmentDTO
+		at time.Time
+	}
+	var selected []dated
+	for _, a := range all {
+		at, err := time.Parse(appointment.DateLayout, a.AppointmentDate)
+		if err != nil {
+			return fmt.Errorf("appointment %d: %w", a.Id, err)
+		}
+		switch {
+		case registration != "" && a.Dentist.Registration != registration && a.IdDentist != registration,
+			*patient != "" && a.Patient.Document != *patient && a.IdPatient != *patient,
+			*status != "" && a.Status != *status,
+			!start.IsZero() && at.Before(start),
+			!end.IsZero() && !at.Before(end):
+			continue
+		}
+		selected = append(selected, dated{a, at})
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].at.Before(selected[j].at) })
+
+	appointments := make([]domain.AppointmentDTO, len(selected))
+	for i, a := range selected {
+		appointments[i] = a.AppointmentDTO
+	}
+	return printAppointments(e, appointments, appointments)
+}
+
+func bookAppointment(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("appointments book")
+	var a domain.Appointment
+	flags.StringVar(&a.IdDentist, "dentist", "", "CRO do dentista")
+	flags.StringVar(&a.IdPatient, "patient", "", "documento do paciente")
+	flags.StringVar(&a.AppointmentDate, "date", "", "data e hora, "+appointment.DateLayout)
+	flags.StringVar(&a.Description, "description", "", "descrição")
+	flags.StringVar(&a.ProcedureCode, "procedure", "", "código do procedimento")
+	flags.IntVar(&a.Duration, "duration", 0, "duração em minutos; sem ela, a do procedimento")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "dentist", "patient", "date", "description"); err != nil {
+		return err
+	}
+	booked, err := e.backend.BookAppointment(ctx, a)
+	if err != nil {
+		return err
+	}
+	return printAppointments(e, booked, []domain.AppointmentDTO{booked})
+}
+
+func cancelAppointment(ctx context.Context, e *env, args []string) error {
+	id, err := idArg(actionFlags("appointments cancel"), args)
+	if err != nil {
+		return err
+	}
+	cancelled, err := e.backend.CancelAppointment(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printAppointments(e, cancelled, []domain.AppointmentDTO{cancelled})
+}
+
+func printAppointments(e *env, v interface{}, appointments []domain.AppointmentDTO) error {
+	rows := make([][]string, 0, len(appointments))
+	for _, a := range appointments {
+		rows = append(rows, []string{
+			strconv.Itoa(a.Id),
+			a.AppointmentDate,
+			a.Status,
+			a.Dentist.Registration + " " + a.Dentist.Name,
+			a.Patient.Document + " " + a.Patient.Name,
+			a.Description,
+		})
+	}
+	return e.out.print(v, []string{"ID", "DATE", "STATUS", "DENTIST", "PATIENT", "DESCRIPTION"}, rows)
+}
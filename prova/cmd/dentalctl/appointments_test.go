@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// fakeBackend lista consultas fixas; as demais operações não são usadas nos testes
+type fakeBackend struct {
+	backend
+	appointments []domain.AppointmentDTO
+}
+
+func (f *fakeBackend) Appointments(ctx context.Context) ([]domain.AppointmentDTO, error) {
+	return f.appointments, nil
+}
+
+func booked(id int, date, registration, document, status string) domain.AppointmentDTO {
+	return domain.AppointmentDTO{
+		Appointment: domain.Appointment{Id: id, AppointmentDate: date, IdDentist: registration, IdPatient: document, Status: status},
+		Dentist:     domain.Dentist{Registration: registration},
+		Patient:     domain.Patient{Document: document},
+	}
+}
+
+var agenda = []domain.AppointmentDTO{
+	booked(1, "05/03/2024 09:00", "CRO-SP 1234", "111", domain.StatusScheduled),
+	booked(2, "01/03/2024 23:59", "CRO-SP 1234", "222", domain.StatusCompleted),
+	booked(3, "04/03/2024 00:00", "CRO-RJ 99", "111", domain.StatusCancelled),
+	booked(4, "06/03/2024 00:00", "CRO-SP 1234", "111", domain.StatusScheduled),
+	booked(5, "29/02/2024 10:00", "CRO-RJ 99", "222", domain.StatusScheduled),
+}
+
+// listIDs executa appointments list com os argumentos e retorna os ids listados, na ordem
+func listIDs(t *testing.T, args ...string) ([]int, error) {
+	t.Helper()
+	var out bytes.Buffer
+	e := &env{out: &printer{format: "json", w: &out}, backend: &fakeBackend{appointments: agenda}}
+	if err := listAppointments(context.Background(), e, args); err != nil {
+		return nil, err
+	}
+	var listed []domain.AppointmentDTO
+	if err := json.Unmarshal(out.Bytes(), &listed); err != nil {
+		t.Fatalf("decode %s: %v", out.Bytes(), err)
+	}
+	ids := make([]int, len(listed))
+	for i, a := range listed {
+		ids[i] = a.Id
+	}
+	return ids, nil
+}
+
+func TestListAppointmentsFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []int
+	}{
+		{"all, by date", nil, []int{5, 2, 3, 1, 4}},
+		{"dentist", []string{"-dentist", "CRO-SP 1234"}, []int{2, 1, 4}},
+		{"dentist written another way", []string{"-dentist", "cro/sp 1234"}, []int{2, 1, 4}},
+		{"from includes the whole day", []string{"-from", "2024-03-04"}, []int{3, 1, 4}},
+		{"to includes the whole day", []string{"-to", "2024-03-01"}, []int{5, 2}},
+		{"from and to", []string{"-from", "2024-03-01", "-to", "2024-03-05"}, []int{2, 3, 1}},
+		{"a single day", []string{"-from", "2024-03-06", "-to", "2024-03-06"}, []int{4}},
+		{"dentist and period", []string{"-to", "2024-03-05", "-dentist", "CRO-RJ 99"}, []int{5, 3}},
+		{"patient and status", []string{"-patient", "111", "-status", domain.StatusScheduled}, []int{1, 4}},
+		{"nothing in the period", []string{"-from", "2024-04-01"}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := listIDs(t, tt.args...)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestListAppointmentsRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"from in another format", []string{"-from", "04/03/2024"}, "invalid -from"},
+		{"to in another format", []string{"-to", "2024-3-4"}, "invalid -to"},
+		{"invalid registration", []string{"-dentist", "1234"}, "registration"},
+		{"unexpected option", []string{"-date", "2024-03-04"}, "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		if _, err := listIDs(t, tt.args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: list = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
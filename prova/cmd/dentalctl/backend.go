@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/importer"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/internal/user"
+	"github.com/meirafa/prova2-golang/pkg/client"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// errNeedsDatabase indica uma operação sem rota na API
+var errNeedsDatabase = errors.New("not available through the API, run it without -api")
+
+// backend executa as operações no banco, pelos serviços, ou na API, pelo cliente
+type backend interface {
+	Patients(ctx context.Context) ([]domain.Patient, error)
+	Patient(ctx context.Context, id int) (domain.Patient, error)
+	CreatePatient(ctx context.Context, p domain.Patient) (domain.Patient, error)
+	ImportPatients(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error)
+
+	Dentists(ctx context.Context) ([]domain.Dentist, error)
+	Dentist(ctx context.Context, id int) (domain.Dentist, error)
+	CreateDentist(ctx context.Context, d domain.Dentist) (domain.Dentist, error)
+	ImportDentists(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error)
+
+	Appointments(ctx context.Context) ([]domain.AppointmentDTO, error)
+	BookAppointment(ctx context.Context, a domain.Appointment) (domain.AppointmentDTO, error)
+	CancelAppointment(ctx context.Context, id int) (domain.AppointmentDTO, error)
+
+	CreateUser(ctx context.Context, u domain.User, password string) (domain.User, error)
+	ResetPassword(ctx context.Context, username, password string) error
+}
+
+// local opera direto no banco, com as regras dos serviços usados pela API
+type local struct {
+	patients     patient.Service
+	dentists     dentist.Service
+	appointments appointment.Service
+	users        user.Service
+	importer     importer.Service
+}
+
+// newLocal cria os serviços sobre o banco informado
+func newLocal(dsn string) *local {
+	store.DataSourceName = dsn
+	sqlStore := store.NewSQLStore()
+	patients := patient.NewService(patient.NewRepository(sqlStore))
+	dentists := dentist.NewService(dentist.NewRepository(sqlStore))
+	procedures := procedure.NewService(procedure.NewRepository(store.NewSQLProcedure()))
+	return &local{
+		patients:     patients,
+		dentists:     dentists,
+		appointments: appointment.NewService(appointment.NewRepository(store.NewSQLAp()), procedures),
+		// a ferramenta não emite tokens, por isso o serviço de usuários não tem assinador
+		users:    user.NewService(user.NewRepository(store.NewSQLUser()), nil),
+		importer: importer.NewService(patients, dentists),
+	}
+}
+
+func (l *local) Patients(ctx context.Context) ([]domain.Patient, error) {
+	return l.patients.GetAll()
+}
+
+func (l *local) Patient(ctx context.Context, id int) (domain.Patient, error) {
+	return l.patients.GetByID(id)
+}
+
+func (l *local) CreatePatient(ctx context.Context, p domain.Patient) (domain.Patient, error) {
+	return l.patients.Create(p)
+}
+
+func (l *local) ImportPatients(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error) {
+	return l.importer.Patients(data, importOptions(filename, opts))
+}
+
+func (l *local) Dentists(ctx context.Context) ([]domain.Dentist, error) {
+	return l.dentists.GetAll()
+}
+
+func (l *local) Dentist(ctx context.Context, id int) (domain.Dentist, error) {
+	dInterface, err := l.dentists.GetByID(id)
+	if err != nil {
+		return domain.Dentist{}, err
+	}
+	d, ok := dInterface.(domain.Dentist)
+	if !ok || d.Id == 0 {
+		return domain.Dentist{}, errors.New("dentist not found")
+	}
+	return d, nil
+}
+
+func (l *local) CreateDentist(ctx context.Context, d domain.Dentist) (domain.Dentist, error) {
+	return l.dentists.Create(d)
+}
+
+func (l *local) ImportDentists(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error) {
+	return l.importer.Dentists(data, importOptions(filename, opts))
+}
+
+func (l *local) Appointments(ctx context.Context) ([]domain.AppointmentDTO, error) {
+	return l.appointments.GetAll()
+}
+
+func (l *local) BookAppointment(ctx context.Context, a domain.Appointment) (domain.AppointmentDTO, error) {
+	return l.appointments.Create(a)
+}
+
+func (l *local) CancelAppointment(ctx context.Context, id int) (domain.AppointmentDTO, error) {
+	a, err := l.appointments.GetByID(id)
+	if err != nil {
+		return domain.AppointmentDTO{}, err
+	}
+	return l.appointments.Update(id, domain.Appointment{Status: domain.StatusCancelled, Version: a.Version})
+}
+
+func (l *local) CreateUser(ctx context.Context, u domain.User, password string) (domain.User, error) {
+	return l.users.Create(u, password)
+}
+
+func (l *local) ResetPassword(ctx context.Context, username, password string) error {
+	return l.users.ResetPassword(username, password)
+}
+
+// importOptions deduz o formato da extensão do arquivo, como faz a API
+func importOptions(filename string, opts importer.Options) importer.Options {
+	if opts.Format == "" {
+		switch ext := filepath.Ext(filename); ext {
+		case ".csv", ".xlsx":
+			opts.Format = ext[1:]
+		}
+	}
+	return opts
+}
+
+// remote opera pela API
+type remote struct {
+	c *client.Client
+}
+
+func (r *remote) Patients(ctx context.Context) ([]domain.Patient, error) {
+	return r.c.ListPatients(ctx)
+}
+
+func (r *remote) Patient(ctx context.Context, id int) (domain.Patient, error) {
+	return r.c.GetPatient(ctx, id)
+}
+
+func (r *remote) CreatePatient(ctx context.Context, p domain.Patient) (domain.Patient, error) {
+	return r.c.CreatePatient(ctx, p)
+}
+
+func (r *remote) ImportPatients(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error) {
+	return r.c.ImportPatients(ctx, filepath.Base(filename), data, opts)
+}
+
+func (r *remote) Dentists(ctx context.Context) ([]domain.Dentist, error) {
+	return r.c.ListDentists(ctx)
+}
+
+func (r *remote) Dentist(ctx context.Context, id int) (domain.Dentist, error) {
+	return r.c.GetDentist(ctx, id)
+}
+
+func (r *remote) CreateDentist(ctx context.Context, d domain.Dentist) (domain.Dentist, error) {
+	return r.c.CreateDentist(ctx, d)
+}
+
+func (r *remote) ImportDentists(ctx context.Context, filename string, data []byte, opts importer.Options) (domain.ImportReport, error) {
+	return r.c.ImportDentists(ctx, filepath.Base(filename), data, opts)
+}
+
+func (r *remote) Appointments(ctx context.Context) ([]domain.AppointmentDTO, error) {
+	appointments, err := r.c.ListAppointments(ctx)
+	// a API responde 404 quando não há nenhuma consulta
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, nil
+	}
+	return appointments, err
+}
+
+func (r *remote) BookAppointment(ctx context.Context, a domain.Appointment) (domain.AppointmentDTO, error) {
+	return r.c.CreateAppointment(ctx, a)
+}
+
+func (r *remote) CancelAppointment(ctx context.Context, id int) (domain.AppointmentDTO, error) {
+	a, err := r.c.GetAppointment(ctx, id)
+	if err != nil {
+		return domain.AppointmentDTO{}, err
+	}
+	return r.c.PatchAppointment(ctx, id, a.Version, client.AppointmentPatch{Status: domain.StatusCancelled})
+}
+
+func (r *remote) CreateUser(ctx context.Context, u domain.User, password string) (domain.User, error) {
+	return r.c.CreateUser(ctx, u, password)
+}
+
+func (r *remote) ResetPassword(ctx context.Context, username, password string) error {
+	return errNeedsDatabase
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/seed"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// defaultSeedStart é o primeiro dia da agenda gerada, fixo para que a mesma semente gere os mesmos dados
+const defaultSeedStart = "2024-01-01"
+
+var dbCommands = map[string]command{
+	"migrate": {summary: "aplica as migrações pendentes do esquema", database: true, run: migrate},
+	"seed":    {usage: "[-seed N -dentists N -patients N -appointments N -start AAAA-MM-DD] | -file config/db.sql", summary: "gera dados fictícios reproduzíveis, ou inclui os do script com -file", database: true, run: seedDB},
+	"backup":  {usage: "[-out arquivo.sql]", summary: "copia estrutura e dados em SQL; sem -out, na saída padrão", database: true, run: backup},
+}
+
+func migrate(ctx context.Context, e *env, args []string) error {
+	if _, err := parse(actionFlags("db migrate"), args); err != nil {
+		return err
+	}
+	applied, err := store.Migrate(e.db)
+	for _, name := range applied {
+		if err := e.out.message("applied %s", name); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return e.out.message("database is up to date")
+	}
+	return nil
+}
+
+func seedDB(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("db seed")
+	file := flags.String("file", "", "script SQL com os INSERT dos dados, em vez de gerá-los")
+	opts := seed.Options{}
+	flags.Int64Var(&opts.Seed, "seed", 1, "semente; a mesma semente e as mesmas opções geram os mesmos dados")
+	flags.IntVar(&opts.Dentists, "dentists", seed.DefaultDentists, "quantidade de dentistas")
+	flags.IntVar(&opts.Patients, "patients", seed.DefaultPatients, "quantidade de pacientes")
+	flags.IntVar(&opts.Appointments, "appointments", seed.DefaultAppointments, "quantidade de consultas")
+	flags.Float64Var(&opts.Occupancy, "occupancy", seed.DefaultOccupancy, "fração dos horários ocupada")
+	flags.IntVar(&opts.BatchSize, "batch-size", seed.DefaultBatchSize, "registros gravados por transação")
+	start := flags.String("start", defaultSeedStart, "primeiro dia da agenda, "+dayLayout)
+	today := flags.String("today", "", "dia que separa as consultas realizadas das agendadas; sem ele, o meio da agenda")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		script, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		rows, err := store.Seed(e.db, string(script))
+		if err != nil {
+			return err
+		}
+		return e.out.message("inserted %d rows from %s", rows, *file)
+	}
+
+	var err error
+	if opts.Start, err = time.Parse(dayLayout, *start); err != nil {
+		return fmt.Errorf("invalid -start, expected format %s", dayLayout)
+	}
+	if *today != "" {
+		if opts.Today, err = time.Parse(dayLayout, *today); err != nil {
+			return fmt.Errorf("invalid -today, expected format %s", dayLayout)
+		}
+	}
+	store.DataSourceName = e.dsn
+	summary, err := seed.Generate(opts, seed.NewStoreWriter(store.NewSQLStore()))
+	if err != nil {
+		return err
+	}
+	first, last := "", ""
+	if summary.Appointments > 0 {
+		first, last = summary.First.Format(appointment.DateLayout), summary.Last.Format(appointment.DateLayout)
+	}
+	return e.out.print(summary, []string{"SEED", "DENTISTS", "PATIENTS", "APPOINTMENTS", "FIRST", "LAST"},
+		[][]string{{strconv.FormatInt(opts.Seed, 10), strconv.Itoa(summary.Dentists), strconv.Itoa(summary.Patients),
+			strconv.Itoa(summary.Appointments), first, last}})
+}
+
+func backup(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("db backup")
+	out := flags.String("out", "", "arquivo da cópia")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return store.Backup(ctx, e.db, e.out.w)
+	}
+	// a cópia é escrita num arquivo temporário, para não deixar um arquivo incompleto em caso de erro
+	tmp, err := os.CreateTemp(filepath.Dir(*out), ".dentalctl-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := store.Backup(ctx, e.db, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		return err
+	}
+	return e.out.message("backup written to %s", *out)
+}
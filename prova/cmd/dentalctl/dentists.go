@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+var dentistCommands = map[string]command{
+	"list":   {summary: "lista os dentistas", run: listDentists},
+	"get":    {usage: "<id>", summary: "mostra um dentista", run: getDentist},
+	"create": {usage: "-name N -surname S -registration \"CRO-UF 00000\"", summary: "cadastra um dentista", run: createDentist},
+	"import": {usage: "<arquivo> [-dry-run -on-duplicate skip|upsert -format csv|xlsx]", summary: "importa dentistas de uma planilha", run: importSheet("dentists")},
+}
+
+func listDentists(ctx context.Context, e *env, args []string) error {
+	if _, err := parse(actionFlags("dentists list"), args); err != nil {
+		return err
+	}
+	dentists, err := e.backend.Dentists(ctx)
+	if err != nil {
+		return err
+	}
+	return printDentists(e, dentists, dentists)
+}
+
+func getDentist(ctx context.Context, e *env, args []string) error {
+	id, err := idArg(actionFlags("dentists get"), args)
+	if err != nil {
+		return err
+	}
+	d, err := e.backend.Dentist(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printDentists(e, d, []domain.Dentist{d})
+}
+
+func createDentist(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("dentists create")
+	var d domain.Dentist
+	flags.StringVar(&d.Name, "name", "", "nome")
+	flags.StringVar(&d.Surname, "surname", "", "sobrenome")
+	flags.StringVar(&d.Registration, "registration", "", "número do CRO, como \"CRO-SP 12345\"")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "name", "surname", "registration"); err != nil {
+		return err
+	}
+	created, err := e.backend.CreateDentist(ctx, d)
+	if err != nil {
+		return err
+	}
+	return printDentists(e, created, []domain.Dentist{created})
+}
+
+func printDentists(e *env, v interface{}, dentists []domain.Dentist) error {
+	rows := make([][]string, 0, len(dentists))
+	for _, d := range dentists {
+		rows = append(rows, []string{strconv.Itoa(d.Id), d.Name, d.Surname, d.Registration})
+	}
+	return e.out.print(v, []string{"ID", "NAME", "SURNAME", "REGISTRATION"}, rows)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/importer"
+)
+
+// importSheet cria a ação import de pacientes ou dentistas
+func importSheet(resource string) func(ctx context.Context, e *env, args []string) error {
+	return func(ctx context.Context, e *env, args []string) error {
+		flags := actionFlags(resource + " import")
+		var opts importer.Options
+		flags.BoolVar(&opts.DryRun, "dry-run", false, "só valida, sem gravar")
+		flags.StringVar(&opts.OnDuplicate, "on-duplicate", importer.DuplicateSkip, "cadastros existentes são ignorados (skip) ou atualizados (upsert)")
+		flags.StringVar(&opts.Format, "format", "", "csv ou xlsx; sem ele, é deduzido da extensão")
+		flags.IntVar(&opts.BatchSize, "batch-size", importer.DefaultBatchSize, "registros gravados por transação")
+		mapping := flags.String("map", "", "colunas da planilha, como campo=coluna,campo=coluna")
+		files, err := parse(flags, args)
+		if err != nil {
+			return err
+		}
+		if len(files) != 1 {
+			return errors.New("expected the spreadsheet file")
+		}
+		if *mapping != "" {
+			opts.Mapping = make(map[string]string)
+			for _, pair := range strings.Split(*mapping, ",") {
+				field, column, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("invalid mapping %q, expected field=column", pair)
+				}
+				opts.Mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+			}
+		}
+		data, err := os.ReadFile(files[0])
+		if err != nil {
+			return err
+		}
+
+		var report domain.ImportReport
+		if resource == "patients" {
+			report, err = e.backend.ImportPatients(ctx, files[0], data, opts)
+		} else {
+			report, err = e.backend.ImportDentists(ctx, files[0], data, opts)
+		}
+		if err != nil {
+			return err
+		}
+		if err := printImport(e, report); err != nil {
+			return err
+		}
+		if report.Invalid+report.Failed > 0 {
+			return fmt.Errorf("%d invalid and %d failed rows", report.Invalid, report.Failed)
+		}
+		return nil
+	}
+}
+
+// printImport escreve o relatório; em tabela, só as linhas com problema, seguidas dos totais
+func printImport(e *env, report domain.ImportReport) error {
+	if e.out.format == "json" {
+		return e.out.print(report, nil, nil)
+	}
+	var rows [][]string
+	for _, r := range report.Rows {
+		if len(r.Errors) == 0 {
+			continue
+		}
+		rows = append(rows, []string{strconv.Itoa(r.Row), r.Key, r.Status, strings.Join(r.Errors, "; ")})
+	}
+	if len(rows) > 0 {
+		if err := e.out.print(report, []string{"ROW", "KEY", "STATUS", "ERRORS"}, rows); err != nil {
+			return err
+		}
+	}
+	prefix := ""
+	if report.DryRun {
+		prefix = "dry run: "
+	}
+	return e.out.message("%s%d rows: %d created, %d updated, %d skipped, %d invalid, %d failed",
+		prefix, report.Total, report.Created, report.Updated, report.Skipped, report.Invalid, report.Failed)
+}
@@ -0,0 +1,226 @@
+// dentalctl é a ferramenta de administração da clínica. Os cadastros e as consultas podem ser
+// operados direto no banco, pelas mesmas regras da API, ou remotamente pela API com -api; os
+// comandos db e users reset-password exigem acesso ao banco.
+//
+//	dentalctl [opções] <recurso> <ação> [argumentos]
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/pkg/client"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// command é uma ação de um recurso, como patients list
+type command struct {
+	usage   string
+	summary string
+	// database indica que a ação só funciona com acesso direto ao banco
+	database bool
+	run      func(ctx context.Context, e *env, args []string) error
+}
+
+// commands são as ações de cada recurso
+var commands = map[string]map[string]command{
+	"patients":     patientCommands,
+	"dentists":     dentistCommands,
+	"appointments": appointmentCommands,
+	"db":           dbCommands,
+	"users":        userCommands,
+}
+
+// env é o ambiente de um comando: a saída, o acesso ao banco ou à API e as opções globais
+type env struct {
+	out     *printer
+	stdin   io.Reader
+	dsn     string
+	db      *sql.DB
+	backend backend
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	code := exitCode(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr), os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// exitCode escreve o erro do comando e retorna o código de saída: 0 em caso de sucesso e 1 em
+// qualquer falha. A ajuda já foi escrita pelo próprio conjunto de opções.
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	if !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stderr, "dentalctl:", err)
+	}
+	return 1
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("dentalctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	api := flags.String("api", os.Getenv("DENTALCTL_API"), "endereço da API, como http://localhost:8083; sem ele, o banco é acessado diretamente")
+	token := flags.String("token", os.Getenv("DENTALCTL_TOKEN"), "token de acesso à API")
+	username := flags.String("user", os.Getenv("DENTALCTL_USER"), "usuário para login na API, quando não há token")
+	password := flags.String("password", os.Getenv("DENTALCTL_PASSWORD"), "senha do login na API")
+	dsn := flags.String("dsn", envOr("DENTALCTL_DSN", store.DataSourceName), "conexão com o banco MySQL")
+	output := flags.String("o", "table", "formato da saída: table ou json")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("invalid output format %q, expected table or json", *output)
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	resource, action := flags.Arg(0), flags.Arg(1)
+	cmd, ok := commands[resource][action]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command %s %s", resource, action)
+	}
+
+	e := &env{out: &printer{format: *output, w: stdout}, stdin: stdin, dsn: *dsn}
+	switch {
+	case cmd.database:
+		if *api != "" {
+			return fmt.Errorf("%s %s needs direct database access, run it without -api", resource, action)
+		}
+		db, err := openDB(*dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		e.db = db
+	case *api != "":
+		c := client.New(*api, client.WithToken(*token))
+		if *token == "" && *username != "" {
+			if _, err := c.Login(ctx, *username, *password); err != nil {
+				return fmt.Errorf("login: %w", err)
+			}
+		}
+		e.backend = &remote{c}
+	default:
+		db, err := openDB(*dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		e.db = db
+		e.backend = newLocal(*dsn)
+	}
+	return cmd.run(ctx, e, flags.Args()[2:])
+}
+
+func openDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database: %w", err)
+	}
+	return db, nil
+}
+
+func usage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "uso: dentalctl [opções] <recurso> <ação> [argumentos]")
+	fmt.Fprintln(w, "\ncomandos:")
+	resources := make([]string, 0, len(commands))
+	for resource := range commands {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		actions := make([]string, 0, len(commands[resource]))
+		for action := range commands[resource] {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			cmd := commands[resource][action]
+			fmt.Fprintf(w, "  %s\n    \t%s\n", strings.TrimSpace(resource+" "+action+" "+cmd.usage), cmd.summary)
+		}
+	}
+	fmt.Fprintln(w, "\nopções:")
+	flags.PrintDefaults()
+}
+
+// parse lê as opções de uma ação, que podem vir antes ou depois dos argumentos, e retorna os
+// argumentos
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// idArg lê o id, único argumento da ação
+func idArg(flags *flag.FlagSet, args []string) (int, error) {
+	positional, err := parse(flags, args)
+	if err != nil {
+		return 0, err
+	}
+	if len(positional) != 1 {
+		return 0, errors.New("expected the id")
+	}
+	id, err := strconv.Atoi(positional[0])
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid id %q", positional[0])
+	}
+	return id, nil
+}
+
+// required confere se as opções obrigatórias foram informadas
+func required(flags *flag.FlagSet, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if flags.Lookup(name).Value.String() == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// actionFlags cria o conjunto de opções de uma ação
+func actionFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
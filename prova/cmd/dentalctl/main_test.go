@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// want é um trecho do erro esperado
+		want string
+	}{
+		{"no command", nil, flag.ErrHelp.Error()},
+		{"resource without action", []string{"patients"}, flag.ErrHelp.Error()},
+		{"help", []string{"-h"}, flag.ErrHelp.Error()},
+		{"unknown command", []string{"patients", "delete"}, "unknown command patients delete"},
+		{"unknown option", []string{"-x", "patients", "list"}, "flag provided but not defined: -x"},
+		{"invalid output", []string{"-o", "yaml", "patients", "list"}, `invalid output format "yaml"`},
+		{"database command through the api", []string{"-api", "http://clinic.test", "db", "backup"}, "db backup needs direct database access"},
+		{"unreachable database", []string{"-dsn", "user:password@tcp(127.0.0.1:1)/my_db?timeout=1s", "patients", "list"}, "database:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(context.Background(), tt.args, strings.NewReader(""), &stdout, &stderr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("run = %v, want %q", err, tt.want)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("stdout = %q, want nothing", stdout.String())
+			}
+		})
+	}
+
+	// a ajuda lista os comandos
+	var stderr bytes.Buffer
+	run(context.Background(), nil, strings.NewReader(""), &bytes.Buffer{}, &stderr)
+	if !strings.Contains(stderr.String(), "appointments list") || !strings.Contains(stderr.String(), "-dsn") {
+		t.Errorf("usage = %q, want the commands and the options", stderr.String())
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		stderr string
+	}{
+		{"success", nil, 0, ""},
+		{"help", flag.ErrHelp, 1, ""},
+		{"failure", errors.New("invalid id \"x\""), 1, "dentalctl: invalid id \"x\"\n"},
+	}
+	for _, tt := range tests {
+		var stderr bytes.Buffer
+		if code := exitCode(tt.err, &stderr); code != tt.code {
+			t.Errorf("%s: exit code = %d, want %d", tt.name, code, tt.code)
+		}
+		if stderr.String() != tt.stderr {
+			t.Errorf("%s: stderr = %q, want %q", tt.name, stderr.String(), tt.stderr)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(&bytes.Buffer{})
+	name := flags.String("name", "", "")
+	force := flags.Bool("force", false, "")
+
+	// as opções podem vir antes, entre e depois dos argumentos
+	positional, err := parse(flags, []string{"a", "-name", "Ana", "b", "-force"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(positional, []string{"a", "b"}) || *name != "Ana" || !*force {
+		t.Errorf("parse = %q, name %q, force %v", positional, *name, *force)
+	}
+
+	if _, err := parse(flags, []string{"a", "-missing"}); err == nil {
+		t.Error("parse accepted an unknown option")
+	}
+}
+
+func TestIDArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		id   int
+		want string
+	}{
+		{[]string{"3"}, 3, ""},
+		{nil, 0, "expected the id"},
+		{[]string{"3", "4"}, 0, "expected the id"},
+		{[]string{"x"}, 0, `invalid id "x"`},
+		{[]string{"0"}, 0, `invalid id "0"`},
+		{[]string{"-1"}, 0, "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.SetOutput(&bytes.Buffer{})
+		id, err := idArg(flags, tt.args)
+		switch {
+		case tt.want == "" && (err != nil || id != tt.id):
+			t.Errorf("idArg(%q) = %d, %v, want %d", tt.args, id, err, tt.id)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("idArg(%q) = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("dentist", "", "")
+	flags.String("patient", "", "")
+	flags.String("date", "", "")
+	flags.Parse([]string{"-patient", "123"})
+
+	err := required(flags, "dentist", "patient", "date")
+	if err == nil || err.Error() != "missing -dentist, -date" {
+		t.Errorf("required = %v, want the missing options", err)
+	}
+	if err := required(flags, "patient"); err != nil {
+		t.Errorf("required = %v", err)
+	}
+}
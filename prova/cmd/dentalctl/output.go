@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer escreve o resultado dos comandos em tabela ou em JSON
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// print escreve v em JSON ou, em tabela, o cabeçalho e as linhas
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	if p.format == "json" {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// message escreve uma confirmação; em JSON, como {"message": ...}
+func (p *printer) message(format string, args ...interface{}) error {
+	text := fmt.Sprintf(format, args...)
+	if p.format == "json" {
+		return p.print(map[string]string{"message": text}, nil, nil)
+	}
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+)
+
+// createdAtLayout é o formato de created_at esperado pelo banco na inclusão do paciente
+const createdAtLayout = "02/01/2006 15:04:05"
+
+var patientCommands = map[string]command{
+	"list":   {summary: "lista os pacientes", run: listPatients},
+	"get":    {usage: "<id>", summary: "mostra um paciente", run: getPatient},
+	"create": {usage: "-name N -surname S -document D [-email E -birth-date DD/MM/AAAA -phone P]", summary: "cadastra um paciente", run: createPatient},
+	"import": {usage: "<arquivo> [-dry-run -on-duplicate skip|upsert -format csv|xlsx]", summary: "importa pacientes de uma planilha", run: importSheet("patients")},
+}
+
+func listPatients(ctx context.Context, e *env, args []string) error {
+	if _, err := parse(actionFlags("patients list"), args); err != nil {
+		return err
+	}
+	patients, err := e.backend.Patients(ctx)
+	if err != nil {
+		return err
+	}
+	return printPatients(e, patients, patients)
+}
+
+func getPatient(ctx context.Context, e *env, args []string) error {
+	id, err := idArg(actionFlags("patients get"), args)
+	if err != nil {
+		return err
+	}
+	p, err := e.backend.Patient(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printPatients(e, p, []domain.Patient{p})
+}
+
+func createPatient(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("patients create")
+	var p domain.Patient
+	var phones string
+	flags.StringVar(&p.Name, "name", "", "nome")
+	flags.StringVar(&p.Surname, "surname", "", "sobrenome")
+	flags.StringVar(&p.Document, "document", "", "CPF, só os números")
+	flags.StringVar(&p.CreatedAt, "created-at", time.Now().Format(createdAtLayout), "data e hora do cadastro, "+createdAtLayout)
+	flags.StringVar(&p.Email, "email", "", "e-mail")
+	flags.StringVar(&p.BirthDate, "birth-date", "", "data de nascimento, "+patient.BirthDateLayout)
+	flags.StringVar(&phones, "phone", "", "telefones, separados por vírgula")
+	flags.StringVar(&p.PreferredLanguage, "language", "", "idioma preferido, como pt-BR")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "name", "surname", "document"); err != nil {
+		return err
+	}
+	if phones != "" {
+		for _, phone := range strings.Split(phones, ",") {
+			p.Phones = append(p.Phones, strings.TrimSpace(phone))
+		}
+	}
+	created, err := e.backend.CreatePatient(ctx, p)
+	if err != nil {
+		return err
+	}
+	return printPatients(e, created, []domain.Patient{created})
+}
+
+func printPatients(e *env, v interface{}, patients []domain.Patient) error {
+	rows := make([][]string, 0, len(patients))
+	for _, p := range patients {
+		rows = append(rows, []string{strconv.Itoa(p.Id), p.Name, p.Surname, p.Document, p.Email, p.CreatedAt})
+	}
+	return e.out.print(v, []string{"ID", "NAME", "SURNAME", "DOCUMENT", "EMAIL", "CREATED AT"}, rows)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+var userCommands = map[string]command{
+	"create":         {usage: "-username U -role admin|dentist|reception [-dentist-id N] [-password P]", summary: "cadastra um usuário; sem -password, a senha é lida da entrada", run: createUser},
+	"reset-password": {usage: "-username U [-password P]", summary: "troca a senha de um usuário; só com acesso ao banco", run: resetPassword},
+}
+
+func createUser(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("users create")
+	var u domain.User
+	flags.StringVar(&u.Username, "username", "", "login")
+	flags.StringVar(&u.Role, "role", "", "papel: admin, dentist ou reception")
+	flags.IntVar(&u.IdDentist, "dentist-id", 0, "id do dentista, obrigatório no papel dentist")
+	password := flags.String("password", "", "senha inicial")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "username", "role"); err != nil {
+		return err
+	}
+	if err := readPassword(e, password); err != nil {
+		return err
+	}
+	created, err := e.backend.CreateUser(ctx, u, *password)
+	if err != nil {
+		return err
+	}
+	dentistID := ""
+	if created.IdDentist != 0 {
+		dentistID = strconv.Itoa(created.IdDentist)
+	}
+	return e.out.print(created, []string{"ID", "USERNAME", "ROLE", "DENTIST"},
+		[][]string{{strconv.Itoa(created.Id), created.Username, created.Role, dentistID}})
+}
+
+func resetPassword(ctx context.Context, e *env, args []string) error {
+	flags := actionFlags("users reset-password")
+	username := flags.String("username", "", "login")
+	password := flags.String("password", "", "nova senha")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := required(flags, "username"); err != nil {
+		return err
+	}
+	if err := readPassword(e, password); err != nil {
+		return err
+	}
+	if err := e.backend.ResetPassword(ctx, *username, *password); err != nil {
+		return err
+	}
+	return e.out.message("password of %s changed", *username)
+}
+
+// readPassword lê a senha da primeira linha da entrada quando não foi informada na opção, para
+// que não fique no histórico do shell
+func readPassword(e *env, password *string) error {
+	if *password != "" {
+		return nil
+	}
+	line, err := bufio.NewReader(e.stdin).ReadString('\n')
+	*password = strings.TrimRight(line, "\r\n")
+	if *password == "" {
+		if err != nil {
+			return errors.New("missing -password or a password on the standard input")
+		}
+		return errors.New("empty password")
+	}
+	return nil
+}
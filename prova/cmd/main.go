@@ -1,20 +1,51 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/meirafa/prova2-golang/cmd/server/handler"
+	"github.com/meirafa/prova2-golang/cmd/server/rpc"
 	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/board"
+	"github.com/meirafa/prova2-golang/internal/caldav"
+	"github.com/meirafa/prova2-golang/internal/calendar"
+	"github.com/meirafa/prova2-golang/internal/chart"
 	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/internal/export"
+	"github.com/meirafa/prova2-golang/internal/graph"
+	"github.com/meirafa/prova2-golang/internal/importer"
+	"github.com/meirafa/prova2-golang/internal/insurance"
+	"github.com/meirafa/prova2-golang/internal/invoice"
+	"github.com/meirafa/prova2-golang/internal/note"
+	"github.com/meirafa/prova2-golang/internal/outbox"
 	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/internal/reminder"
+	"github.com/meirafa/prova2-golang/internal/report"
+	"github.com/meirafa/prova2-golang/internal/selfservice"
+	"github.com/meirafa/prova2-golang/internal/treatment"
+	"github.com/meirafa/prova2-golang/internal/user"
+	"github.com/meirafa/prova2-golang/internal/webhook"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/notify"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
 	"github.com/meirafa/prova2-golang/pkg/store"
 )
 
 func main() {
 	// 	DB INITIALIZATION
-	db, err := sql.Open("mysql", "user:password@/my_db")
+	db, err := sql.Open("mysql", store.DataSourceName)
 	if err != nil {
 		panic(err)
 	}
@@ -27,8 +58,34 @@ func main() {
 	sqlStore := store.NewSQLStore()
 	apStore := store.NewSQLAp()
 
+	// 	WEBHOOKS
+	webhookRepo := webhook.NewRepository(store.NewSQLWebhook())
+	webhookService := webhook.NewService(webhookRepo)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	go webhook.NewDispatcher(webhookRepo, nil).Run(context.Background(), 5*time.Second)
+
+	// 	OUTBOX
+	// Os eventos gravados junto com as alterações são repassados aos webhooks, ao barramento
+	// interno, que alimenta o gRPC e o painel da recepção, e, com OUTBOX_LOG_EVENTS=true, ao log.
+	eventBus := event.NewBus()
+	boardHub := board.NewHub(board.DefaultHistory, board.DefaultBuffer)
+	boardHub.Listen(eventBus)
+	sinks := []event.Sink{webhookService, eventBus}
+	if os.Getenv("OUTBOX_LOG_EVENTS") == "true" {
+		sinks = append(sinks, event.NewLog(log.Default()))
+	}
+	go outbox.NewRelay(outbox.NewRepository(store.NewSQLOutbox()), sinks...).Run(context.Background(), time.Second)
+
+	// 	APPOINTMENT BOARD
+	// O painel da recepção recebe as alterações de consultas que o relay publica no barramento
+	boardHandler := handler.NewBoardHandler(boardHub)
+
+	procedureRepo := procedure.NewRepository(store.NewSQLProcedure())
+	procedureService := procedure.NewService(procedureRepo)
+	procedureHandler := handler.NewProcedureHandler(procedureService)
+
 	appRepo := appointment.NewRepository(apStore)
-	appService := appointment.NewService(appRepo)
+	appService := appointment.NewService(appRepo, procedureService)
 	appHandler := handler.NewAppointmentHandler(appService)
 
 	dentistRepo := dentist.NewRepository(sqlStore)
@@ -40,44 +97,181 @@ func main() {
 	patientService := patient.NewService(patientRepo)
 	patientHandler := handler.NewPatientHandler(patientService)
 
-	r := gin.Default()
+	// 	AUTHENTICATION
+	secret := os.Getenv("AUTH_SECRET")
+	if secret == "" {
+		log.Fatal("AUTH_SECRET environment variable must be set")
+	}
+	signer := auth.NewSigner(secret)
+
+	userRepo := user.NewRepository(store.NewSQLUser())
+	userService := user.NewService(userRepo, signer)
+	userHandler := handler.NewUserHandler(userService)
+
+	noteRepo := note.NewRepository(store.NewSQLNote())
+	noteService := note.NewService(noteRepo, appService, patientService)
+	noteHandler := handler.NewNoteHandler(noteService)
+
+	chartRepo := chart.NewRepository(store.NewSQLChart())
+	chartService := chart.NewService(chartRepo, appService, patientService)
+	chartHandler := handler.NewChartHandler(chartService)
+
+	treatmentRepo := treatment.NewRepository(store.NewSQLPlan())
+	treatmentService := treatment.NewService(treatmentRepo, procedureService, appService, patientService, dentistService)
+	treatmentHandler := handler.NewTreatmentHandler(treatmentService)
+
+	// 	INSURANCE
+	// Sem INSURER_URL configurada, os pedidos de autorização são respondidos por uma operadora simulada
+	insurer := insurance.NewStubInsurer()
+	if insurerURL := os.Getenv("INSURER_URL"); insurerURL != "" {
+		insurer = insurance.NewHTTPInsurer(insurerURL)
+	}
+	insuranceRepo := insurance.NewRepository(store.NewSQLInsurance())
+	insuranceService := insurance.NewService(insuranceRepo, insurer, patientService, procedureService, appService, treatmentService)
+	insuranceHandler := handler.NewInsuranceHandler(insuranceService)
+
+	invoiceRepo := invoice.NewRepository(store.NewSQLInvoice())
+	invoiceService := invoice.NewService(invoiceRepo, appService, patientService, procedureService, insuranceService)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+
+	// 	CLINIC TIMEZONE
+	// CLINIC_TIMEZONE é o fuso das datas das consultas, usado nos calendários, nos
+	// lembretes e na validade dos links de autoatendimento
+	clinicTimezone := os.Getenv("CLINIC_TIMEZONE")
+	if clinicTimezone == "" {
+		clinicTimezone = "America/Sao_Paulo"
+	}
+	clinicLocation, err := time.LoadLocation(clinicTimezone)
+	if err != nil {
+		panic(err)
+	}
+
+	// 	PATIENT SELF-SERVICE LINKS
+	// Os links usam uma chave derivada para que não possam ser usados como token de acesso
+	publicURL := os.Getenv("PUBLIC_BASE_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:8083"
+	}
+	linkRepo := selfservice.NewRepository(store.NewSQLLink())
+	linkService := selfservice.NewService(linkRepo, auth.NewSigner("appointment-links:"+secret), appService, clinicLocation, publicURL)
+	linkHandler := handler.NewSelfServiceHandler(linkService)
+
+	// 	CALENDAR FEEDS
+	calendarService := calendar.NewService(calendar.NewRepository(store.NewSQLCalendar()), appService, dentistService, patientService, clinicLocation, publicURL)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+
+	// 	CALDAV
+	caldavService := caldav.NewService(caldav.NewRepository(store.NewSQLCalDAV()), appService, dentistService, clinicLocation)
+	caldavHandler := handler.NewCalDAVHandler(caldavService, userService)
 
-	r.GET("/ping", func(c *gin.Context) { c.String(200, "pong") })
+	// 	IMPORT
+	importHandler := handler.NewImportHandler(importer.NewService(patientService, dentistService))
 
-	api := r.Group("/api/")
-	{
-		appointments := api.Group("/appointments")
-		{
-			appointments.GET("", appHandler.GetAll())
-			appointments.GET(":id", appHandler.GetByID())
-			appointments.GET("/patient/:document", appHandler.GetByDocumentPatient())
+	// 	EXPORT
+	exportHandler := handler.NewExportHandler(export.NewService(export.NewRepository(store.NewSQLExport())))
 
-			appointments.POST("", appHandler.Post())
-			appointments.PUT(":id", appHandler.Put())
-			appointments.PATCH(":id", appHandler.Patch())
-			appointments.DELETE(":id", appHandler.Delete())
+	// 	REPORTS
+	reportService := report.NewService(report.NewRepository(store.NewSQLReport()), dentistService, reportSchedule())
+	reportHandler := handler.NewReportHandler(reportService)
+
+	// 	GRAPHQL
+	graphQLHandler := handler.NewGraphQLHandler(graph.NewService(dentistRepo, patientRepo, appRepo))
+
+	// 	REMINDERS
+	reminderScheduler := reminder.NewScheduler(reminder.NewRepository(store.NewSQLReminder()), appService, patientService, linkService, notifiers(), reminder.DefaultOffsets, clinicLocation, time.Minute)
+	go reminderScheduler.Run(context.Background())
+
+	// 	GRPC
+	// Os serviços internos chamam a API gRPC de pkg/clinicpb numa porta separada, com um token de
+	// acesso do mesmo Signer da API REST. O servidor não usa TLS e por isso só escuta em localhost;
+	// GRPC_ADDR muda o endereço, por exemplo para a interface da rede interna.
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = "127.0.0.1:9090"
+	}
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		panic(err)
+	}
+	go rpc.NewServer(signer, appService, dentistService, patientService, eventBus).Serve(grpcListener)
+
+	// 	DOCUMENTATION AND VALIDATION
+	// O documento é gerado do catálogo de handler.Routes; os testes do pacote handler falham quando
+	// o roteador registra uma rota fora dele, para que a documentação não fique para trás. As requisições são conferidas com
+	// o documento antes de chegar aos handlers; em modo de teste do gin, ou com
+	// OPENAPI_VALIDATE_RESPONSES=true, as respostas também.
+	apiDoc := openapi.Build(handler.APIInfo, handler.APITags, handler.Routes())
+	validation := openapi.Options{Responses: gin.Mode() == gin.TestMode || os.Getenv("OPENAPI_VALIDATE_RESPONSES") == "true"}
+
+	r := handler.NewRouter(handler.Handlers{
+		Signer:      signer,
+		Appointment: appHandler,
+		Dentist:     dentistHandler,
+		Patient:     patientHandler,
+		User:        userHandler,
+		Note:        noteHandler,
+		Chart:       chartHandler,
+		Treatment:   treatmentHandler,
+		Procedure:   procedureHandler,
+		Invoice:     invoiceHandler,
+		Insurance:   insuranceHandler,
+		SelfService: linkHandler,
+		Calendar:    calendarHandler,
+		CalDAV:      caldavHandler,
+		Import:      importHandler,
+		Export:      exportHandler,
+		Report:      reportHandler,
+		Webhook:     webhookHandler,
+		Board:       boardHandler,
+		GraphQL:     graphQLHandler,
+	}, apiDoc, validation)
+	r.Run(":8083")
+}
+
+// notifiers retorna os canais de lembrete configurados por variáveis de ambiente.
+// Sem nenhum canal configurado, os lembretes são apenas registrados no log.
+func notifiers() []notify.Notifier {
+	var channels []notify.Notifier
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
 		}
-		dentists := api.Group("/dentists")
-		{
-			dentists.GET("", dentistHandler.GetAll())
-			dentists.GET(":id", dentistHandler.GetByID())
-
-			dentists.POST("", dentistHandler.Post())
-			dentists.PUT(":id", dentistHandler.Put())
-			dentists.PATCH(":id", dentistHandler.Patch())
-			dentists.DELETE(":id", dentistHandler.Delete())
+		channels = append(channels, notify.NewSMTP(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM")))
+	}
+	if url := os.Getenv("SMS_GATEWAY_URL"); url != "" {
+		channels = append(channels, notify.NewSMS(url, os.Getenv("SMS_GATEWAY_TOKEN")))
+	}
+	if url := os.Getenv("REMINDER_WEBHOOK_URL"); url != "" {
+		channels = append(channels, notify.NewWebhook(url))
+	}
+	if len(channels) == 0 {
+		channels = append(channels, notify.NewLog(log.Default()))
+	}
+	return channels
+}
+
+// reportSchedule retorna a jornada usada nos relatórios de ocupação. CLINIC_HOURS_PER_DAY define as
+// horas de atendimento por dia e CLINIC_WORKDAYS os dias da semana, de 0 (domingo) a 6, separados
+// por vírgula; sem elas, vale a jornada de oito horas de segunda a sexta.
+func reportSchedule() report.Schedule {
+	schedule := report.DefaultSchedule
+	if value := os.Getenv("CLINIC_HOURS_PER_DAY"); value != "" {
+		hours, err := strconv.ParseFloat(value, 64)
+		if err != nil || hours <= 0 || hours > 24 {
+			panic("invalid CLINIC_HOURS_PER_DAY: " + value)
 		}
-		patients := api.Group("/patients")
-		{
-			patients.GET("", patientHandler.GetAll())
-			patients.GET(":id", patientHandler.GetByID())
-
-			patients.POST("", patientHandler.Post())
-			patients.PUT(":id", patientHandler.Put())
-			patients.PATCH(":id", patientHandler.Patch())
-			patients.DELETE(":id", patientHandler.Delete())
+		schedule.HoursPerDay = hours
+	}
+	if value := os.Getenv("CLINIC_WORKDAYS"); value != "" {
+		schedule.Workdays = nil
+		for _, day := range strings.Split(value, ",") {
+			weekday, err := strconv.Atoi(strings.TrimSpace(day))
+			if err != nil || weekday < 0 || weekday > 6 {
+				panic("invalid CLINIC_WORKDAYS: " + value)
+			}
+			schedule.Workdays = append(schedule.Workdays, time.Weekday(weekday))
 		}
 	}
-
-	r.Run(":8083")
+	return schedule
 }
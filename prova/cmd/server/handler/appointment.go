@@ -8,6 +8,7 @@ import (
 	"github.com/meirafa/prova2-golang/pkg/web"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type appointmentHandler struct {
@@ -51,6 +52,7 @@ func (h *appointmentHandler) GetByID() gin.HandlerFunc {
 			web.BadResponse(ctx, http.StatusNotFound, "error", err.Error())
 			return
 		}
+		ctx.Header("ETag", appointmentETag(response.Version))
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
@@ -85,21 +87,16 @@ func (h *appointmentHandler) Post() gin.HandlerFunc {
 			return
 		}
 
-		isValid, err := isEmptyAppointment(&appointment)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
-			return
-		}
 		response, err := h.s.Create(appointment)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			web.BadResponse(ctx, appointmentErrorStatus(err, http.StatusBadRequest), "error", err.Error())
 			return
 		}
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
 
-//Put atualiza uma consulta
+//Put atualiza uma consulta. O cabeçalho If-Match deve trazer a ETag da versão lida.
 func (h *appointmentHandler) Put() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		idParam := ctx.Param("id")
@@ -108,6 +105,10 @@ func (h *appointmentHandler) Put() gin.HandlerFunc {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
 			return
 		}
+		version, ok := appointmentIfMatch(ctx)
+		if !ok {
+			return
+		}
 
 		_, err = h.s.GetByID(id)
 		if err != nil {
@@ -125,38 +126,45 @@ func (h *appointmentHandler) Put() gin.HandlerFunc {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid appointment data, verify the fields and try again")
 			return
 		}
+		appointment.Version = version
 
-		isValid, err := isEmptyAppointment(&appointment)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
-			return
-		}
 		response, err := h.s.Update(id, appointment)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusNotFound, "error", err.Error())
+			web.BadResponse(ctx, appointmentErrorStatus(err, http.StatusNotFound), "error", err.Error())
 			return
 		}
+		ctx.Header("ETag", appointmentETag(response.Version))
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
 
-// Patch atualiza uma consulta ou algum de seus campos
+// appointmentPatchRequest são os campos da consulta que podem ser alterados parcialmente
+type appointmentPatchRequest struct {
+	Description     string `json:"description,omitempty"`
+	AppointmentDate string `json:"appointment_date,omitempty" openapi:"format=appointment-date"`
+	IdDentist       string `json:"id_dentist,omitempty" openapi:"format=registration"`
+	IdPatient       string `json:"id_patient,omitempty" openapi:"format=document"`
+	Status          string `json:"status,omitempty" openapi:"enum=scheduled|confirmed|checked_in|completed|cancelled|no_show"`
+	ProcedureCode   string `json:"procedure_code,omitempty"`
+	Duration        int    `json:"duration,omitempty" openapi:"minimum=0"`
+}
+
+// Patch atualiza uma consulta ou algum de seus campos. O cabeçalho If-Match deve trazer a ETag da
+// versão lida.
 func (h *appointmentHandler) Patch() gin.HandlerFunc {
-	type Request struct {
-		Description     string `json:"description,omitempty"`
-		AppointmentDate string `json:"appointment_date,omitempty"`
-		IdDentist       string `json:"id_dentist,omitempty"`
-		IdPatient       string `json:"id_patient,omitempty"`
-	}
 
 	return func(ctx *gin.Context) {
-		var r Request
+		var r appointmentPatchRequest
 		idParam := ctx.Param("id")
 		id, err := strconv.Atoi(idParam)
 		if err != nil {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
 			return
 		}
+		version, ok := appointmentIfMatch(ctx)
+		if !ok {
+			return
+		}
 
 		_, err = h.s.GetByID(id)
 		if err != nil {
@@ -173,18 +181,23 @@ func (h *appointmentHandler) Patch() gin.HandlerFunc {
 			AppointmentDate: r.AppointmentDate,
 			IdDentist:       r.IdDentist,
 			IdPatient:       r.IdPatient,
+			Status:          r.Status,
+			ProcedureCode:   r.ProcedureCode,
+			Duration:        r.Duration,
+			Version:         version,
 		}
 
 		response, err := h.s.Update(id, update)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusNotFound, "error", err.Error())
+			web.BadResponse(ctx, appointmentErrorStatus(err, http.StatusNotFound), "error", err.Error())
 			return
 		}
+		ctx.Header("ETag", appointmentETag(response.Version))
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
 
-//Delete exclui uma consulta
+//Delete exclui uma consulta. O cabeçalho If-Match deve trazer a ETag da versão lida.
 func (h *appointmentHandler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		idParam := ctx.Param("id")
@@ -193,19 +206,57 @@ func (h *appointmentHandler) Delete() gin.HandlerFunc {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
 			return
 		}
-		err = h.s.Delete(id)
+		version, ok := appointmentIfMatch(ctx)
+		if !ok {
+			return
+		}
+		err = h.s.Delete(id, version)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusNotFound, "error", err.Error())
+			web.BadResponse(ctx, appointmentErrorStatus(err, http.StatusNotFound), "error", err.Error())
 			return
 		}
 		web.DeleteResponse(ctx, http.StatusOK, "appointment removed")
 	}
 }
 
-// isEmptyAppointment valida se os campos não estão vazios
-func isEmptyAppointment(appointment *domain.Appointment) (bool, error) {
-	if appointment.Description == "" || appointment.IdDentist == "" || appointment.AppointmentDate == "" || appointment.IdPatient == "" {
-		return false, errors.New("fields can't be empty")
+// appointmentErrorStatus traduz os erros do serviço de consultas para o status HTTP correspondente
+func appointmentErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, appointment.ErrInvalidStatus),
+		errors.Is(err, appointment.ErrInvalidDate),
+		errors.Is(err, appointment.ErrInvalidProcedure):
+		return http.StatusBadRequest
+	case errors.Is(err, appointment.ErrUnavailable):
+		return http.StatusConflict
+	case errors.Is(err, appointment.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	default:
+		return fallback
+	}
+}
+
+// appointmentETag é a ETag da versão da consulta
+func appointmentETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// appointmentIfMatch lê do cabeçalho If-Match a versão que o cliente leu. Sem o cabeçalho, responde
+// 428; "*" aceita qualquer versão e retorna zero. Qualquer outro valor que não seja a ETag de uma
+// versão, inclusive ETags fracas, não corresponde à consulta e é respondido com 412.
+func appointmentIfMatch(ctx *gin.Context) (int, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	switch {
+	case header == "":
+		web.BadResponse(ctx, http.StatusPreconditionRequired, "error", "If-Match header is required, send the ETag returned when the appointment was read")
+		return 0, false
+	case header == "*":
+		return 0, true
+	}
+	if len(header) > 2 && strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) {
+		if version, err := strconv.Atoi(header[1 : len(header)-1]); err == nil && version > 0 {
+			return version, true
+		}
 	}
-	return true, nil
+	web.BadResponse(ctx, http.StatusPreconditionFailed, "error", appointment.ErrVersionMismatch.Error())
+	return 0, false
 }
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/meirafa/prova2-golang/internal/board"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// BoardHeartbeat é o intervalo dos sinais enviados aos painéis sem eventos, para que proxies não
+// encerrem a conexão ociosa e o servidor perceba os clientes que sumiram
+var BoardHeartbeat = 15 * time.Second
+
+// boardRetry é o tempo, em milissegundos, que o navegador espera para reconectar o SSE
+const boardRetry = 3000
+
+// boardWriteTimeout limita a escrita de uma mensagem WebSocket
+const boardWriteTimeout = 10 * time.Second
+
+// boardUpgrader responde com o subprotocolo do token quando o cliente o envia por ele
+var boardUpgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096, Subprotocols: []string{auth.WebSocketProtocol}}
+
+type boardHandler struct {
+	hub *board.Hub
+}
+
+// NewBoardHandler cria um novo controller do painel de consultas
+func NewBoardHandler(hub *board.Hub) *boardHandler {
+	return &boardHandler{
+		hub: hub,
+	}
+}
+
+// Stream envia as alterações das consultas por Server-Sent Events. Na reconexão, o navegador
+// informa o último evento recebido no cabeçalho Last-Event-ID e recebe os que perdeu.
+func (h *boardHandler) Stream() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, lastID, ok := boardParams(ctx)
+		if !ok {
+			return
+		}
+		sub := h.hub.Subscribe(filter, lastID)
+		defer sub.Close()
+
+		header := ctx.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		// desliga o buffer de proxies como o nginx
+		header.Set("X-Accel-Buffering", "no")
+		ctx.Status(http.StatusOK)
+		ctx.Writer.WriteString("retry: " + strconv.Itoa(boardRetry) + "\n\n")
+		for _, e := range sub.Replay {
+			writeBoardEvent(ctx, e)
+		}
+		ctx.Writer.Flush()
+
+		heartbeat := time.NewTicker(BoardHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Request.Context().Done():
+				return
+			case <-sub.Dropped():
+				// o navegador reconecta sozinho e recupera os eventos pelo Last-Event-ID
+				return
+			case e := <-sub.Events():
+				writeBoardEvent(ctx, e)
+			case <-heartbeat.C:
+				ctx.Writer.WriteString(": heartbeat\n\n")
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+func writeBoardEvent(ctx *gin.Context, e domain.BoardEvent) {
+	sse.Encode(ctx.Writer, sse.Event{Id: strconv.FormatInt(e.Id, 10), Event: e.Type, Data: e})
+}
+
+// WebSocket envia as alterações das consultas por WebSocket, uma mensagem JSON por evento. O
+// heartbeat usa quadros ping; o cliente que não responde é desconectado. Na reconexão, o último
+// evento recebido vai no parâmetro last_event_id. O navegador, que não envia o cabeçalho
+// Authorization, informa o token no subprotocolo, como "bearer, <token>".
+func (h *boardHandler) WebSocket() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, lastID, ok := boardParams(ctx)
+		if !ok {
+			return
+		}
+		// a inscrição vem antes do handshake, para que o cliente receba tudo que for publicado
+		// depois de a conexão ser aceita
+		sub := h.hub.Subscribe(filter, lastID)
+		defer sub.Close()
+		// em caso de erro, o Upgrader já respondeu ao cliente
+		conn, err := boardUpgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// as mensagens do cliente são descartadas; a leitura só processa os pongs e percebe o
+		// fechamento da conexão
+		closed := make(chan struct{})
+		conn.SetReadDeadline(time.Now().Add(2 * BoardHeartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * BoardHeartbeat))
+		})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		write := func(e domain.BoardEvent) error {
+			conn.SetWriteDeadline(time.Now().Add(boardWriteTimeout))
+			return conn.WriteJSON(e)
+		}
+		for _, e := range sub.Replay {
+			if err := write(e); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(BoardHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-sub.Dropped():
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client is too slow")
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(boardWriteTimeout))
+				return
+			case e := <-sub.Events():
+				if err := write(e); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(boardWriteTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// boardParams lê os filtros dentist e date e o último evento recebido, do cabeçalho Last-Event-ID
+// ou do parâmetro last_event_id
+func boardParams(ctx *gin.Context) (board.Filter, int64, bool) {
+	filter := board.Filter{Dentist: ctx.Query("dentist")}
+	if date := ctx.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid date, expected format 2006-01-02")
+			return filter, 0, false
+		}
+		filter.Date = parsed
+	}
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid last event id")
+			return filter, 0, false
+		}
+	}
+	return filter, lastID, true
+}
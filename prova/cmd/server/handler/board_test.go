@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/meirafa/prova2-golang/internal/board"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+)
+
+// newBoardServer sobe o roteador com um hub real ligado ao bus e retorna um token válido
+func newBoardServer(t *testing.T) (*httptest.Server, event.Bus, string) {
+	t.Helper()
+	bus := event.NewBus()
+	hub := board.NewHub(board.DefaultHistory, board.DefaultBuffer)
+	hub.Listen(bus)
+
+	h := testHandlers()
+	h.Signer = auth.NewSigner("board")
+	h.Board = NewBoardHandler(hub)
+	doc := openapi.Build(APIInfo, APITags, Routes())
+	server := httptest.NewServer(NewRouter(h, doc, openapi.Options{}))
+	t.Cleanup(server.Close)
+
+	token, err := h.Signer.IssueToken(auth.Claims{UserID: 1, Username: "recepcao", Role: domain.RoleReception}, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	return server, bus, token
+}
+
+func TestBoardStreamRequiresAToken(t *testing.T) {
+	server, _, token := newBoardServer(t)
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer " + token + "x", http.StatusUnauthorized},
+		{"valid token", "Bearer " + token, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/appointments/stream", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			if err != nil || !strings.HasPrefix(line, "retry:") {
+				t.Errorf("first line = %q, %v, want the retry interval", line, err)
+			}
+		})
+	}
+}
+
+func TestBoardWebSocketAcceptsTheTokenInTheSubprotocol(t *testing.T) {
+	server, bus, token := newBoardServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/appointments/stream/ws"
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		protocols []string
+	}{
+		{"no token", nil, nil},
+		{"invalid token in the subprotocol", nil, []string{auth.WebSocketProtocol, token + "x"}},
+		{"token without the bearer subprotocol", nil, []string{token}},
+	}
+	for _, tt := range tests {
+		dialer := websocket.Dialer{Subprotocols: tt.protocols}
+		conn, resp, err := dialer.Dial(url, tt.header)
+		if err == nil {
+			conn.Close()
+			t.Errorf("%s: the connection was accepted", tt.name)
+			continue
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: response = %v, want 401", tt.name, resp)
+		}
+	}
+
+	// clientes que não são navegadores continuam usando o cabeçalho Authorization
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer " + token}})
+	if err != nil {
+		t.Fatalf("Dial with the header: %v", err)
+	}
+	conn.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{auth.WebSocketProtocol, token}}
+	conn, _, err = dialer.Dial(url+"?dentist=CRO-1", nil)
+	if err != nil {
+		t.Fatalf("Dial with the subprotocol: %v", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != auth.WebSocketProtocol {
+		t.Errorf("subprotocol = %q, want %q", conn.Subprotocol(), auth.WebSocketProtocol)
+	}
+
+	a := domain.AppointmentDTO{Appointment: domain.Appointment{Id: 1, IdDentist: "CRO-1", AppointmentDate: "04/03/2024 09:00"}}
+	bus.Publish(context.Background(), event.New(event.AppointmentCreated, a))
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var e domain.BoardEvent
+	if err := conn.ReadJSON(&e); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if e.Type != domain.BoardCreated || e.Appointment == nil || e.Appointment.Id != 1 {
+		t.Errorf("event = %+v, want appointment 1 created", e)
+	}
+}
@@ -0,0 +1,604 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/caldav"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/user"
+)
+
+// CalDAVPath é o caminho base do servidor CalDAV
+const CalDAVPath = "/caldav/"
+
+// Espaços de nomes usados nas mensagens WebDAV e CalDAV
+const (
+	davNS = "DAV:"
+	calNS = "urn:ietf:params:xml:ns:caldav"
+	csNS  = "http://calendarserver.org/ns/"
+)
+
+const (
+	caldavUserKey     = "caldav.user"
+	calendarMediaType = "text/calendar; charset=utf-8"
+	// maxCalendarObject limita o tamanho dos eventos enviados pelos clientes
+	maxCalendarObject = 1 << 20
+	// timeRangeLayout é o formato dos limites do filtro time-range
+	timeRangeLayout = "20060102T150405Z"
+)
+
+type caldavHandler struct {
+	s     caldav.Service
+	users user.Service
+}
+
+// NewCalDAVHandler cria um novo controller do servidor CalDAV das agendas dos dentistas
+func NewCalDAVHandler(s caldav.Service, users user.Service) *caldavHandler {
+	return &caldavHandler{
+		s:     s,
+		users: users,
+	}
+}
+
+// Authenticate valida a autenticação básica, a única suportada por todos os clientes de
+// calendário, e restringe os dentistas à própria agenda
+func (h *caldavHandler) Authenticate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username, password, ok := ctx.Request.BasicAuth()
+		if !ok {
+			h.challenge(ctx)
+			return
+		}
+		u, err := h.users.Authenticate(username, password)
+		if err != nil {
+			h.challenge(ctx)
+			return
+		}
+		if id := ctx.Param("id"); id != "" && u.Role == domain.RoleDentist && id != strconv.Itoa(u.IdDentist) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Set(caldavUserKey, u)
+		ctx.Next()
+	}
+}
+
+func (h *caldavHandler) challenge(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", `Basic realm="CalDAV", charset="UTF-8"`)
+	ctx.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// Options anuncia as classes WebDAV e CalDAV suportadas
+func (h *caldavHandler) Options() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("DAV", "1, calendar-access")
+		ctx.Header("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// PropfindRoot responde à descoberta do principal do usuário autenticado
+func (h *caldavHandler) PropfindRoot() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		names, ok := parsePropfind(ctx)
+		if !ok {
+			return
+		}
+		u := caldavUser(ctx)
+		props := h.principalProps(u)
+		props[xml.Name{Space: davNS, Local: "resourcetype"}] = `<collection xmlns="DAV:"/>`
+		writeMultistatus(ctx, "", propResponse(ctx.Request.URL.Path, props, names))
+	}
+}
+
+// PropfindPrincipal responde às propriedades do principal, entre elas a agenda do dentista
+func (h *caldavHandler) PropfindPrincipal() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		names, ok := parsePropfind(ctx)
+		if !ok {
+			return
+		}
+		u := caldavUser(ctx)
+		if ctx.Param("username") != u.Username {
+			ctx.Status(http.StatusForbidden)
+			return
+		}
+		props := h.principalProps(u)
+		props[xml.Name{Space: davNS, Local: "resourcetype"}] = `<principal xmlns="DAV:"/><collection xmlns="DAV:"/>`
+		props[xml.Name{Space: davNS, Local: "displayname"}] = escapeXML(u.Username)
+		writeMultistatus(ctx, "", propResponse(ctx.Request.URL.Path, props, names))
+	}
+}
+
+// PropfindHome responde à coleção que contém a agenda do dentista
+func (h *caldavHandler) PropfindHome() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		names, ok := parsePropfind(ctx)
+		if !ok {
+			return
+		}
+		collection, err := h.s.Collection(id)
+		if err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		props := h.principalProps(caldavUser(ctx))
+		props[xml.Name{Space: davNS, Local: "resourcetype"}] = `<collection xmlns="DAV:"/>`
+		responses := []davResponse{propResponse(homePath(id), props, names)}
+		if ctx.GetHeader("Depth") != "0" {
+			responses = append(responses, propResponse(collectionPath(id), h.collectionProps(ctx, collection), names))
+		}
+		writeMultistatus(ctx, "", responses...)
+	}
+}
+
+// PropfindCollection responde às propriedades da agenda e, com Depth 1, às ETags dos eventos
+func (h *caldavHandler) PropfindCollection() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		names, ok := parsePropfind(ctx)
+		if !ok {
+			return
+		}
+		collection, err := h.s.Collection(id)
+		if err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		responses := []davResponse{propResponse(collectionPath(id), h.collectionProps(ctx, collection), names)}
+		if ctx.GetHeader("Depth") != "0" {
+			resources, err := h.s.Resources(id)
+			if err != nil {
+				caldavError(ctx, err)
+				return
+			}
+			for _, resource := range resources {
+				responses = append(responses, propResponse(resourcePath(id, resource.Name), resourceProps(resource), names))
+			}
+		}
+		writeMultistatus(ctx, "", responses...)
+	}
+}
+
+// PropfindResource responde às propriedades de um evento
+func (h *caldavHandler) PropfindResource() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		names, ok := parsePropfind(ctx)
+		if !ok {
+			return
+		}
+		resource, err := h.s.Resource(id, ctx.Param("name"))
+		if err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		writeMultistatus(ctx, "", propResponse(resourcePath(id, resource.Name), resourceProps(resource), names))
+	}
+}
+
+// Report atende aos relatórios calendar-multiget, calendar-query e sync-collection da agenda
+func (h *caldavHandler) Report() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		var report reportRequest
+		if err := xml.NewDecoder(io.LimitReader(ctx.Request.Body, maxCalendarObject)).Decode(&report); err != nil {
+			ctx.String(http.StatusBadRequest, "invalid REPORT body")
+			return
+		}
+		names := report.Prop.names()
+
+		switch report.XMLName {
+		case xml.Name{Space: calNS, Local: "calendar-multiget"}:
+			var responses []davResponse
+			for _, href := range report.Hrefs {
+				name, err := url.PathUnescape(path.Base(href))
+				if err != nil || !strings.HasPrefix(href, collectionPath(id)) {
+					responses = append(responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+					continue
+				}
+				resource, err := h.s.Resource(id, name)
+				if errors.Is(err, caldav.ErrNotFound) {
+					responses = append(responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+					continue
+				}
+				if err != nil {
+					caldavError(ctx, err)
+					return
+				}
+				responses = append(responses, propResponse(resourcePath(id, resource.Name), resourceProps(resource), names))
+			}
+			writeMultistatus(ctx, "", responses...)
+
+		case xml.Name{Space: calNS, Local: "calendar-query"}:
+			start, end, err := report.Filter.timeRange()
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			resources, err := h.s.Resources(id)
+			if err != nil {
+				caldavError(ctx, err)
+				return
+			}
+			var responses []davResponse
+			for _, resource := range resources {
+				if (!end.IsZero() && !resource.Start.Before(end)) || (!start.IsZero() && !resource.End.After(start)) {
+					continue
+				}
+				responses = append(responses, propResponse(resourcePath(id, resource.Name), resourceProps(resource), names))
+			}
+			writeMultistatus(ctx, "", responses...)
+
+		case xml.Name{Space: davNS, Local: "sync-collection"}:
+			changes, err := h.s.Changes(id, strings.TrimSpace(report.SyncToken))
+			if errors.Is(err, caldav.ErrInvalidSyncToken) {
+				ctx.Data(http.StatusForbidden, "application/xml; charset=utf-8", []byte(xml.Header+`<error xmlns="DAV:"><valid-sync-token/></error>`))
+				return
+			}
+			if err != nil {
+				caldavError(ctx, err)
+				return
+			}
+			var responses []davResponse
+			for _, resource := range changes.Changed {
+				responses = append(responses, propResponse(resourcePath(id, resource.Name), resourceProps(resource), names))
+			}
+			for _, name := range changes.Removed {
+				responses = append(responses, davResponse{Href: resourcePath(id, name), Status: davStatus(http.StatusNotFound)})
+			}
+			writeMultistatus(ctx, changes.SyncToken, responses...)
+
+		default:
+			ctx.String(http.StatusNotImplemented, "unsupported report "+report.XMLName.Local)
+		}
+	}
+}
+
+// Get entrega um evento da agenda
+func (h *caldavHandler) Get() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		resource, err := h.s.Resource(id, ctx.Param("name"))
+		if err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		ctx.Header("ETag", resource.ETag)
+		ctx.Data(http.StatusOK, calendarMediaType, resource.Data)
+	}
+}
+
+// Put remarca ou cancela uma consulta, ou cria e move horários bloqueados. A ETag não é devolvida
+// porque o evento é normalizado ao ser gravado, e o cliente precisa buscá-lo de novo.
+func (h *caldavHandler) Put() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxCalendarObject))
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "invalid body")
+			return
+		}
+		created, err := h.s.Put(id, ctx.Param("name"), body, ctx.GetHeader("If-Match"), ctx.GetHeader("If-None-Match"))
+		if err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		if created {
+			ctx.Status(http.StatusCreated)
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// Delete cancela a consulta ou libera o horário bloqueado
+func (h *caldavHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := caldavDentistID(ctx)
+		if !ok {
+			return
+		}
+		if err := h.s.Delete(id, ctx.Param("name"), ctx.GetHeader("If-Match")); err != nil {
+			caldavError(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// principalProps são as propriedades de descoberta comuns à raiz, ao principal e à coleção
+func (h *caldavHandler) principalProps(u domain.User) map[xml.Name]string {
+	principal := hrefXML(principalPath(u.Username))
+	props := map[xml.Name]string{
+		{Space: davNS, Local: "current-user-principal"}: principal,
+		{Space: davNS, Local: "principal-URL"}:          principal,
+	}
+	if u.Role == domain.RoleDentist {
+		props[xml.Name{Space: calNS, Local: "calendar-home-set"}] = hrefXML(homePath(u.IdDentist))
+	}
+	return props
+}
+
+func (h *caldavHandler) collectionProps(ctx *gin.Context, c caldav.Collection) map[xml.Name]string {
+	props := h.principalProps(caldavUser(ctx))
+	props[xml.Name{Space: davNS, Local: "resourcetype"}] = `<collection xmlns="DAV:"/><calendar xmlns="` + calNS + `"/>`
+	props[xml.Name{Space: davNS, Local: "displayname"}] = escapeXML(c.DisplayName)
+	props[xml.Name{Space: davNS, Local: "sync-token"}] = escapeXML(c.SyncToken)
+	props[xml.Name{Space: csNS, Local: "getctag"}] = escapeXML(c.SyncToken)
+	props[xml.Name{Space: calNS, Local: "supported-calendar-component-set"}] = `<comp xmlns="` + calNS + `" name="VEVENT"/>`
+	props[xml.Name{Space: davNS, Local: "supported-report-set"}] = supportedReport(calNS, "calendar-multiget") +
+		supportedReport(calNS, "calendar-query") + supportedReport(davNS, "sync-collection")
+	props[xml.Name{Space: davNS, Local: "current-user-privilege-set"}] = `<privilege xmlns="DAV:"><read/></privilege><privilege xmlns="DAV:"><write/></privilege>`
+	return props
+}
+
+func resourceProps(r caldav.Resource) map[xml.Name]string {
+	return map[xml.Name]string{
+		{Space: davNS, Local: "getetag"}:          escapeXML(r.ETag),
+		{Space: davNS, Local: "getcontenttype"}:   "text/calendar; charset=utf-8; component=vevent",
+		{Space: davNS, Local: "resourcetype"}:     "",
+		{Space: calNS, Local: "calendar-data"}:    escapeXML(string(r.Data)),
+		{Space: davNS, Local: "getcontentlength"}: strconv.Itoa(len(r.Data)),
+	}
+}
+
+// caldavErrorStatus traduz os erros do serviço CalDAV para o status HTTP correspondente
+func caldavErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, caldav.ErrNotFound), errors.Is(err, appointment.ErrBlockNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, caldav.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, caldav.ErrNotAllowed), errors.Is(err, caldav.ErrInvalidSyncToken):
+		return http.StatusForbidden
+	case errors.Is(err, caldav.ErrInvalidCalendar), errors.Is(err, appointment.ErrInvalidDate),
+		errors.Is(err, appointment.ErrInvalidProcedure), errors.Is(err, appointment.ErrInvalidStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, appointment.ErrUnavailable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// caldavError responde com texto simples, que é o que os clientes de calendário exibem
+func caldavError(ctx *gin.Context, err error) {
+	ctx.String(caldavErrorStatus(err), err.Error())
+}
+
+func caldavUser(ctx *gin.Context) domain.User {
+	value, _ := ctx.Get(caldavUserKey)
+	u, _ := value.(domain.User)
+	return u
+}
+
+func caldavDentistID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "invalid id provided")
+		return 0, false
+	}
+	return id, true
+}
+
+func principalPath(username string) string {
+	return CalDAVPath + "principals/" + url.PathEscape(username) + "/"
+}
+
+func homePath(dentistID int) string {
+	return CalDAVPath + "dentists/" + strconv.Itoa(dentistID) + "/"
+}
+
+func collectionPath(dentistID int) string {
+	return homePath(dentistID) + "schedule/"
+}
+
+func resourcePath(dentistID int, name string) string {
+	return collectionPath(dentistID) + url.PathEscape(name)
+}
+
+// davAny guarda apenas o nome de um elemento, usado para listar as propriedades pedidas
+type davAny struct {
+	XMLName xml.Name
+}
+
+type davPropNames struct {
+	Names []davAny `xml:",any"`
+}
+
+func (p *davPropNames) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, 0, len(p.Names))
+	for _, n := range p.Names {
+		names = append(names, n.XMLName)
+	}
+	return names
+}
+
+type propfindRequest struct {
+	XMLName xml.Name      `xml:"DAV: propfind"`
+	AllProp *struct{}     `xml:"DAV: allprop"`
+	Prop    *davPropNames `xml:"DAV: prop"`
+}
+
+type timeRangeFilter struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type compFilter struct {
+	Name      string           `xml:"name,attr"`
+	TimeRange *timeRangeFilter `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Filters   []compFilter     `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type queryFilter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// timeRange retorna o intervalo do filtro de VEVENT, com limites zerados quando ausentes
+func (f *queryFilter) timeRange() (time.Time, time.Time, error) {
+	var start, end time.Time
+	if f == nil {
+		return start, end, nil
+	}
+	for _, c := range f.CompFilter.Filters {
+		if c.Name != "VEVENT" || c.TimeRange == nil {
+			continue
+		}
+		var err error
+		if c.TimeRange.Start != "" {
+			if start, err = time.Parse(timeRangeLayout, c.TimeRange.Start); err != nil {
+				return start, end, errors.New("invalid time-range start")
+			}
+		}
+		if c.TimeRange.End != "" {
+			if end, err = time.Parse(timeRangeLayout, c.TimeRange.End); err != nil {
+				return start, end, errors.New("invalid time-range end")
+			}
+		}
+	}
+	return start, end, nil
+}
+
+type reportRequest struct {
+	XMLName   xml.Name
+	Prop      *davPropNames `xml:"DAV: prop"`
+	Hrefs     []string      `xml:"DAV: href"`
+	SyncToken string        `xml:"DAV: sync-token"`
+	Filter    *queryFilter  `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// parsePropfind retorna as propriedades pedidas; nil indica allprop ou corpo vazio
+func parsePropfind(ctx *gin.Context) ([]xml.Name, bool) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxCalendarObject))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "invalid PROPFIND body")
+		return nil, false
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, true
+	}
+	var request propfindRequest
+	if err := xml.Unmarshal(body, &request); err != nil {
+		ctx.String(http.StatusBadRequest, "invalid PROPFIND body")
+		return nil, false
+	}
+	if request.AllProp != nil {
+		return nil, true
+	}
+	return request.Prop.names(), true
+}
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"response"`
+	SyncToken string        `xml:"sync-token,omitempty"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"href"`
+	Status    string        `xml:"status,omitempty"`
+	Propstats []davPropstat `xml:"propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"prop"`
+	Status string  `xml:"status"`
+}
+
+type davProp struct {
+	Values []davValue
+}
+
+// davValue é uma propriedade com o conteúdo XML já serializado
+type davValue struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+// propResponse monta a resposta de um recurso, separando as propriedades pedidas que não existem.
+// Sem nomes pedidos, todas as propriedades conhecidas são devolvidas.
+func propResponse(href string, props map[xml.Name]string, names []xml.Name) davResponse {
+	var found, missing davProp
+	if names == nil {
+		for name, value := range props {
+			found.Values = append(found.Values, davValue{XMLName: name, Inner: value})
+		}
+	}
+	for _, name := range names {
+		if value, ok := props[name]; ok {
+			found.Values = append(found.Values, davValue{XMLName: name, Inner: value})
+		} else {
+			missing.Values = append(missing.Values, davValue{XMLName: name})
+		}
+	}
+
+	response := davResponse{Href: href}
+	if len(found.Values) > 0 || len(missing.Values) == 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: found, Status: davStatus(http.StatusOK)})
+	}
+	if len(missing.Values) > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: missing, Status: davStatus(http.StatusNotFound)})
+	}
+	return response
+}
+
+func writeMultistatus(ctx *gin.Context, syncToken string, responses ...davResponse) {
+	body, err := xml.Marshal(davMultistatus{Responses: responses, SyncToken: syncToken})
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+func davStatus(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+func hrefXML(href string) string {
+	return `<href xmlns="DAV:">` + escapeXML(href) + `</href>`
+}
+
+func supportedReport(space, name string) string {
+	return `<supported-report xmlns="DAV:"><report><` + name + ` xmlns="` + space + `"/></report></supported-report>`
+}
+
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
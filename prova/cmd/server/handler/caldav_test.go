@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/caldav"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/user"
+	"github.com/meirafa/prova2-golang/pkg/ical"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+)
+
+// fakeCalDAV guarda a agenda do dentista 7 em memória, com ETags e token de sincronização
+// incrementados a cada alteração
+type fakeCalDAV struct {
+	resources map[string]caldav.Resource
+	version   int
+}
+
+func (f *fakeCalDAV) syncToken() string {
+	return caldav.SyncTokenPrefix + strconv.Itoa(f.version)
+}
+
+func (f *fakeCalDAV) Collection(dentistID int) (caldav.Collection, error) {
+	if dentistID != 7 {
+		return caldav.Collection{}, caldav.ErrNotFound
+	}
+	return caldav.Collection{IdDentist: dentistID, DisplayName: "Agenda", SyncToken: f.syncToken()}, nil
+}
+
+func (f *fakeCalDAV) Resources(dentistID int) ([]caldav.Resource, error) {
+	resources := make([]caldav.Resource, 0, len(f.resources))
+	for _, r := range f.resources {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources, nil
+}
+
+func (f *fakeCalDAV) Resource(dentistID int, name string) (caldav.Resource, error) {
+	r, ok := f.resources[name]
+	if !ok {
+		return r, caldav.ErrNotFound
+	}
+	return r, nil
+}
+
+func (f *fakeCalDAV) Put(dentistID int, name string, data []byte, ifMatch, ifNoneMatch string) (bool, error) {
+	current, exists := f.resources[name]
+	if (ifNoneMatch == "*" && exists) || (ifMatch != "" && (!exists || ifMatch != current.ETag)) {
+		return false, caldav.ErrPreconditionFailed
+	}
+	event, err := ical.ParseEvent(data, time.UTC)
+	if err != nil {
+		return false, caldav.ErrInvalidCalendar
+	}
+	f.version++
+	f.resources[name] = caldav.Resource{Name: name, ETag: strconv.Quote(strconv.Itoa(f.version)), Data: data, Start: event.Start, End: event.End}
+	return !exists, nil
+}
+
+func (f *fakeCalDAV) Delete(dentistID int, name, ifMatch string) error {
+	current, exists := f.resources[name]
+	if !exists {
+		return caldav.ErrNotFound
+	}
+	if ifMatch != "" && ifMatch != current.ETag {
+		return caldav.ErrPreconditionFailed
+	}
+	f.version++
+	delete(f.resources, name)
+	return nil
+}
+
+func (f *fakeCalDAV) Changes(dentistID int, syncToken string) (caldav.Changes, error) {
+	if syncToken != "" && syncToken != f.syncToken() {
+		return caldav.Changes{}, caldav.ErrInvalidSyncToken
+	}
+	changes := caldav.Changes{SyncToken: f.syncToken()}
+	if syncToken == "" {
+		changes.Changed, _ = f.Resources(dentistID)
+	}
+	return changes, nil
+}
+
+// fakeUsers autentica apenas o dentista 7
+type fakeUsers struct{ user.Service }
+
+func (fakeUsers) Authenticate(username, password string) (domain.User, error) {
+	if username != "ana" || password != "secret" {
+		return domain.User{}, errors.New("invalid credentials")
+	}
+	return domain.User{Id: 1, Username: "ana", Role: domain.RoleDentist, IdDentist: 7}, nil
+}
+
+// davClient é um cliente CalDAV mínimo, que não segue redirecionamentos para conferir a descoberta
+type davClient struct {
+	t      *testing.T
+	url    string
+	client *http.Client
+}
+
+func (c *davClient) do(method, path string, headers map[string]string, body string) (*http.Response, string) {
+	c.t.Helper()
+	request, err := http.NewRequest(method, c.url+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	request.SetBasicAuth("ana", "secret")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer response.Body.Close()
+	data, _ := io.ReadAll(response.Body)
+	return response, string(data)
+}
+
+// multistatus faz a requisição e decodifica a resposta 207
+func (c *davClient) multistatus(method, path, depth, body string) davTestMultistatus {
+	c.t.Helper()
+	response, data := c.do(method, path, map[string]string{"Depth": depth, "Content-Type": "application/xml"}, body)
+	if response.StatusCode != http.StatusMultiStatus {
+		c.t.Fatalf("%s %s: status %d, body %s", method, path, response.StatusCode, data)
+	}
+	var result davTestMultistatus
+	if err := xml.Unmarshal([]byte(data), &result); err != nil {
+		c.t.Fatalf("%s %s: invalid multistatus %v", method, path, err)
+	}
+	return result
+}
+
+type davTestMultistatus struct {
+	Responses []struct {
+		Href      string `xml:"href"`
+		Status    string `xml:"status"`
+		Propstats []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				Principal    string    `xml:"current-user-principal>href"`
+				Home         string    `xml:"calendar-home-set>href"`
+				Calendar     *struct{} `xml:"resourcetype>calendar"`
+				ETag         string    `xml:"getetag"`
+				CalendarData string    `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+	SyncToken string `xml:"sync-token"`
+}
+
+func calendarObject(uid string, start time.Time) string {
+	return string(ical.Calendar{ProdID: "-//test//caldav//PT", Events: []ical.Event{{
+		UID: uid, Start: start, End: start.Add(time.Hour), Summary: "Bloqueio",
+	}}}.Encode(time.Now()))
+}
+
+func TestCalDAVClient(t *testing.T) {
+	service := &fakeCalDAV{resources: map[string]caldav.Resource{}}
+	handlers := testHandlers()
+	handlers.CalDAV = NewCalDAVHandler(service, fakeUsers{})
+	doc := openapi.Build(APIInfo, APITags, Routes())
+	server := httptest.NewServer(NewRouter(handlers, doc, openapi.Options{}))
+	defer server.Close()
+
+	client := &davClient{t: t, url: server.URL, client: &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+
+	// descoberta: well-known, principal, calendar-home-set e a coleção
+	response, _ := client.do("PROPFIND", "/.well-known/caldav", nil, "")
+	if response.StatusCode != http.StatusMovedPermanently || response.Header.Get("Location") != CalDAVPath {
+		t.Fatalf("well-known: status %d, location %q", response.StatusCode, response.Header.Get("Location"))
+	}
+	anonymous, _ := http.NewRequest("PROPFIND", server.URL+CalDAVPath, nil)
+	if response, err := http.DefaultClient.Do(anonymous); err != nil || response.StatusCode != http.StatusUnauthorized || response.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("anonymous PROPFIND wasn't challenged: %v, %v", response, err)
+	}
+
+	propfind := `<?xml version="1.0"?><propfind xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><current-user-principal/><C:calendar-home-set/></prop></propfind>`
+	root := client.multistatus("PROPFIND", CalDAVPath, "0", propfind)
+	principal := root.Responses[0].Propstats[0].Prop.Principal
+	if principal != CalDAVPath+"principals/ana/" {
+		t.Fatalf("current-user-principal = %q", principal)
+	}
+	home := client.multistatus("PROPFIND", principal, "0", propfind).Responses[0].Propstats[0].Prop.Home
+	if home != CalDAVPath+"dentists/7/" {
+		t.Fatalf("calendar-home-set = %q", home)
+	}
+	collections := client.multistatus("PROPFIND", home, "1", "")
+	if len(collections.Responses) != 2 || collections.Responses[1].Propstats[0].Prop.Calendar == nil {
+		t.Fatalf("home doesn't list the calendar: %+v", collections)
+	}
+	collection := collections.Responses[1].Href
+
+	if response, _ := client.do("PROPFIND", CalDAVPath+"dentists/8/", map[string]string{"Depth": "0"}, ""); response.StatusCode != http.StatusForbidden {
+		t.Errorf("another dentist's calendar: status %d, want 403", response.StatusCode)
+	}
+
+	// criação com If-None-Match, que não pode sobrescrever um evento existente
+	start := time.Date(2024, time.March, 5, 14, 0, 0, 0, time.UTC)
+	event := collection + "block-1.ics"
+	headers := map[string]string{"Content-Type": "text/calendar", "If-None-Match": "*"}
+	if response, body := client.do(http.MethodPut, event, headers, calendarObject("block-1", start)); response.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT: status %d, body %s", response.StatusCode, body)
+	}
+	if response, _ := client.do(http.MethodPut, event, headers, calendarObject("block-1", start)); response.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PUT over an existing event: status %d, want 412", response.StatusCode)
+	}
+
+	// calendar-query com time-range devolve o evento com ETag e conteúdo
+	query := `<?xml version="1.0"?><C:calendar-query xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><getetag/><C:calendar-data/></prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"><C:time-range start="20240305T000000Z" end="20240306T000000Z"/></C:comp-filter></C:comp-filter></C:filter></C:calendar-query>`
+	found := client.multistatus("REPORT", collection, "1", query)
+	if len(found.Responses) != 1 || found.Responses[0].Href != event {
+		t.Fatalf("calendar-query = %+v, want %s", found, event)
+	}
+	etag := found.Responses[0].Propstats[0].Prop.ETag
+	if etag == "" || !strings.Contains(found.Responses[0].Propstats[0].Prop.CalendarData, "UID:block-1") {
+		t.Errorf("calendar-query props = %+v", found.Responses[0].Propstats[0].Prop)
+	}
+	outside := strings.Replace(strings.Replace(query, "20240305", "20240310", 1), "20240306", "20240311", 1)
+	if empty := client.multistatus("REPORT", collection, "1", outside); len(empty.Responses) != 0 {
+		t.Errorf("calendar-query outside the range = %+v", empty)
+	}
+
+	response, body := client.do(http.MethodGet, event, nil, "")
+	if response.StatusCode != http.StatusOK || response.Header.Get("ETag") != etag || !strings.Contains(body, "BEGIN:VEVENT") {
+		t.Fatalf("GET: status %d, etag %q, body %s", response.StatusCode, response.Header.Get("ETag"), body)
+	}
+
+	// alteração condicionada à ETag
+	moved := calendarObject("block-1", start.Add(2*time.Hour))
+	if response, _ := client.do(http.MethodPut, event, map[string]string{"If-Match": `"stale"`}, moved); response.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PUT with a stale ETag: status %d, want 412", response.StatusCode)
+	}
+	if response, _ := client.do(http.MethodPut, event, map[string]string{"If-Match": etag}, moved); response.StatusCode != http.StatusNoContent {
+		t.Fatalf("PUT with the current ETag: status %d, want 204", response.StatusCode)
+	}
+
+	// sync-collection sem token devolve tudo; com o token atual, nada
+	sync := client.multistatus("REPORT", collection, "1", `<?xml version="1.0"?><sync-collection xmlns="DAV:"><sync-token/><prop><getetag/></prop></sync-collection>`)
+	if len(sync.Responses) != 1 || sync.SyncToken == "" || sync.Responses[0].Propstats[0].Prop.ETag == etag {
+		t.Fatalf("initial sync = %+v", sync)
+	}
+	etag = sync.Responses[0].Propstats[0].Prop.ETag
+	incremental := client.multistatus("REPORT", collection, "1", `<?xml version="1.0"?><sync-collection xmlns="DAV:"><sync-token>`+sync.SyncToken+`</sync-token><prop><getetag/></prop></sync-collection>`)
+	if len(incremental.Responses) != 0 || incremental.SyncToken != sync.SyncToken {
+		t.Errorf("incremental sync = %+v", incremental)
+	}
+
+	if response, _ := client.do(http.MethodDelete, event, map[string]string{"If-Match": etag}, ""); response.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE: status %d, want 204", response.StatusCode)
+	}
+	if response, _ := client.do(http.MethodGet, event, nil, ""); response.StatusCode != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status %d, want 404", response.StatusCode)
+	}
+}
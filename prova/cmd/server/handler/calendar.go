@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/calendar"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type calendarHandler struct {
+	s calendar.Service
+}
+
+// NewCalendarHandler cria um novo controller dos calendários assinados
+func NewCalendarHandler(s calendar.Service) *calendarHandler {
+	return &calendarHandler{
+		s: s,
+	}
+}
+
+// Issue emite o endereço secreto de assinatura do calendário. Só administradores e o dentista dono
+// da agenda podem emitir o endereço.
+func (h *calendarHandler) Issue(owner string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.Issue(claims, owner, id)
+		if err != nil {
+			web.BadResponse(ctx, calendarErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// Feed entrega o calendário no formato iCalendar. O acesso é feito pelo token na url, pois os
+// clientes de calendário não enviam o cabeçalho de autenticação.
+func (h *calendarHandler) Feed(owner string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		body, err := h.s.Feed(owner, id, ctx.Query("token"))
+		if err != nil {
+			web.BadResponse(ctx, calendarErrorStatus(err), "error", err.Error())
+			return
+		}
+		ctx.Header("Cache-Control", "private, max-age=300")
+		ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", fmt.Sprintf("%s-%d.ics", owner, id)))
+		ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+	}
+}
+
+// calendarErrorStatus traduz os erros do serviço de calendários para o status HTTP correspondente
+func calendarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, calendar.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, calendar.ErrInvalidOwner):
+		return http.StatusBadRequest
+	case errors.Is(err, calendar.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/chart"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type chartHandler struct {
+	s chart.Service
+}
+
+// NewChartHandler cria um novo controller de odontograma
+func NewChartHandler(s chart.Service) *chartHandler {
+	return &chartHandler{
+		s: s,
+	}
+}
+
+// Get retorna o odontograma atual de um paciente
+func (h *chartHandler) Get() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Get(id)
+		if err != nil {
+			web.BadResponse(ctx, chartErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// History retorna o histórico de alterações do odontograma, opcionalmente filtrado por ?tooth=
+func (h *chartHandler) History() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		tooth := 0
+		if toothParam := ctx.Query("tooth"); toothParam != "" {
+			tooth, err = strconv.Atoi(toothParam)
+			if err != nil || !chart.IsValidTooth(tooth) {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid tooth, use FDI numbering")
+				return
+			}
+		}
+		response, err := h.s.History(id, tooth)
+		if err != nil {
+			web.BadResponse(ctx, chartErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// chartPatchRequest registra as alterações do odontograma feitas em uma consulta
+type chartPatchRequest struct {
+	IdAppointment int                     `json:"id_appointment" binding:"required"`
+	Changes       []domain.ToothCondition `json:"changes" binding:"required"`
+}
+
+// Patch registra alterações no odontograma durante uma consulta
+func (h *chartHandler) Patch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var r chartPatchRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid chart data")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.Update(claims, id, r.IdAppointment, r.Changes)
+		if err != nil {
+			web.BadResponse(ctx, chartErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// chartErrorStatus traduz os erros do serviço de odontograma para o status HTTP correspondente
+func chartErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, chart.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, chart.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, chart.ErrInvalidChange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
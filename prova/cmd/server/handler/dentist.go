@@ -63,15 +63,9 @@ func (h *dentistHandler) Post() gin.HandlerFunc {
 			return
 		}
 
-		isValid, err := isEmptyDentist(&dentist)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
-			return
-		}
-
 		response, err := h.s.Create(dentist)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			web.BadResponse(ctx, dentistErrorStatus(err, http.StatusBadRequest), "error", err.Error())
 			return
 		}
 		web.ResponseOK(ctx, http.StatusCreated, response)
@@ -102,32 +96,29 @@ func (h *dentistHandler) Put() gin.HandlerFunc {
 		err = ctx.ShouldBindJSON(&dentist)
 		if err != nil {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid dentist data")
-		}
-
-		isValid, err := isEmptyDentist(&dentist)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
 			return
 		}
 
 		response, err := h.s.Update(id, dentist)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusConflict, "error", err.Error())
+			web.BadResponse(ctx, dentistErrorStatus(err, http.StatusConflict), "error", err.Error())
 			return
 		}
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
 
+// dentistPatchRequest são os campos do dentista que podem ser alterados parcialmente
+type dentistPatchRequest struct {
+	Surname      string `json:"surname,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Registration string `json:"registration,omitempty" openapi:"format=registration"`
+}
+
 // Patch atualiza um dentista ou algum de seus campos
 func (h *dentistHandler) Patch() gin.HandlerFunc {
-	type Request struct {
-		Surname      string `json:"surname,omitempty"`
-		Name         string `json:"name,omitempty"`
-		Registration string `json:"registration,omitempty"`
-	}
 	return func(ctx *gin.Context) {
-		var r Request
+		var r dentistPatchRequest
 		idParam := ctx.Param("id")
 		id, err := strconv.Atoi(idParam)
 		if err != nil {
@@ -152,7 +143,7 @@ func (h *dentistHandler) Patch() gin.HandlerFunc {
 
 		updated, err := h.s.Update(id, update)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			web.BadResponse(ctx, dentistErrorStatus(err, http.StatusBadRequest), "error", err.Error())
 			return
 		}
 		web.ResponseOK(ctx, http.StatusOK, updated)
@@ -177,10 +168,14 @@ func (h *dentistHandler) Delete() gin.HandlerFunc {
 	}
 }
 
-// isEmptyDentist valida se os campos não estão vazios
-func isEmptyDentist(dentist *domain.Dentist) (bool, error) {
-	if dentist.Surname == "" || dentist.Name == "" || dentist.Registration == "" {
-		return false, errors.New("fields can't be empty")
+// dentistErrorStatus traduz os erros do serviço de dentistas para o status HTTP correspondente
+func dentistErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, dentist.ErrRegistrationExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidRegistration):
+		return http.StatusBadRequest
+	default:
+		return fallback
 	}
-	return true, nil
 }
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/export"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// Formatos aceitos nos filtros from e to; quando só a data é informada, to inclui o dia inteiro
+var (
+	exportDateLayouts     = []string{"2006-01-02", "02/01/2006"}
+	exportDateTimeLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04", "02/01/2006 15:04"}
+)
+
+type exportHandler struct {
+	s export.Service
+}
+
+// NewExportHandler cria um novo controller das exportações
+func NewExportHandler(s export.Service) *exportHandler {
+	return &exportHandler{
+		s: s,
+	}
+}
+
+// Appointments exporta as consultas, filtradas por from, to, dentist (CRO), patient (documento) e status
+func (h *exportHandler) Appointments() gin.HandlerFunc {
+	return h.handle("appointments", func(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error {
+		return h.s.Appointments(ctx, w, format, filter)
+	})
+}
+
+// Patients exporta os pacientes, filtrados pela data de cadastro com from e to
+func (h *exportHandler) Patients() gin.HandlerFunc {
+	return h.handle("patients", func(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error {
+		return h.s.Patients(ctx, w, format, filter)
+	})
+}
+
+// Dentists exporta os dentistas
+func (h *exportHandler) Dentists() gin.HandlerFunc {
+	return h.handle("dentists", func(ctx context.Context, w io.Writer, format string, _ domain.ExportFilter) error {
+		return h.s.Dentists(ctx, w, format)
+	})
+}
+
+// handle lê o formato (csv por padrão) e os filtros e escreve a exportação direto na resposta. Os
+// cabeçalhos só são enviados com o primeiro byte; um erro depois disso interrompe o arquivo.
+func (h *exportHandler) handle(name string, run func(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		format := ctx.DefaultQuery("format", export.FormatCSV)
+		contentType, err := export.ContentType(format)
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+		filter := domain.ExportFilter{
+			Dentist: ctx.Query("dentist"),
+			Patient: ctx.Query("patient"),
+			Status:  ctx.Query("status"),
+		}
+		if filter.From, err = parseExportDate(ctx.Query("from"), false); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid from date")
+			return
+		}
+		if filter.To, err = parseExportDate(ctx.Query("to"), true); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid to date")
+			return
+		}
+
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+		w := &exportWriter{ctx: ctx, contentType: contentType, filename: filename}
+		err = run(ctx.Request.Context(), w, format, filter)
+		if err == nil {
+			return
+		}
+		if !w.started {
+			web.BadResponse(ctx, exportErrorStatus(err), "error", err.Error())
+			return
+		}
+		log.Printf("export of %s interrupted: %v", name, err)
+		ctx.Abort()
+	}
+}
+
+// exportWriter envia os cabeçalhos da resposta na primeira escrita
+type exportWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Type", w.contentType)
+		w.ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+		w.ctx.Header("Cache-Control", "no-store")
+		w.ctx.Status(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+// parseExportDate interpreta a data do filtro. Vazia, não filtra; só com a data, end avança para o
+// dia seguinte, já que o fim do período é exclusivo.
+func parseExportDate(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range exportDateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range exportDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if end {
+				t = t.AddDate(0, 0, 1)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+// exportErrorStatus traduz os erros do serviço de exportação para o status HTTP correspondente
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, export.ErrInvalidFormat), errors.Is(err, export.ErrInvalidFilter):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
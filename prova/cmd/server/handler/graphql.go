@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/graph"
+	"github.com/meirafa/prova2-golang/pkg/graphql"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// GraphQLPath é o caminho da API GraphQL
+const GraphQLPath = "/graphql"
+
+// GraphQLLimits são os limites das consultas GraphQL. Nas listas, os subcampos contam uma vez para
+// cada item do limit, de modo que dentists(limit: 100) { appointments(limit: 100) { ... } } é
+// recusada antes de chegar ao banco.
+var GraphQLLimits = graphql.Options{MaxDepth: 8, MaxComplexity: 5000}
+
+type graphQLHandler struct {
+	s      graph.Service
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler cria um novo controller da API GraphQL
+func NewGraphQLHandler(s graph.Service) *graphQLHandler {
+	schema, err := graphql.NewSchema(graphQLQuery())
+	if err != nil {
+		panic(err)
+	}
+	return &graphQLHandler{
+		s:      s,
+		schema: schema,
+	}
+}
+
+// Query executa a consulta enviada no corpo JSON (POST) ou nos parâmetros query, operationName e
+// variables (GET). Os erros da consulta vêm no campo errors da resposta, com status 200.
+func (h *graphQLHandler) Query() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req graphql.Request
+		if ctx.Request.Method == http.MethodGet {
+			req.Query = ctx.Query("query")
+			req.OperationName = ctx.Query("operationName")
+			if variables := ctx.Query("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid variables")
+					return
+				}
+			}
+		} else if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+
+		c := context.WithValue(ctx.Request.Context(), loadersKey{}, newGraphLoaders(ctx.Request.Context(), h.s))
+		ctx.JSON(http.StatusOK, h.schema.Execute(c, req, GraphQLLimits))
+	}
+}
+
+// loadersKey guarda os loaders da requisição no contexto dos resolvers
+type loadersKey struct{}
+
+// graphLoaders são os loaders de uma requisição. As consultas de um dentista ou paciente dependem
+// dos filtros do campo, então há um loader para cada combinação de filtros usada.
+type graphLoaders struct {
+	ctx context.Context
+	s   graph.Service
+
+	dentistsByID           *graphql.Loader
+	dentistsByRegistration *graphql.Loader
+	patientsByID           *graphql.Loader
+	patientsByDocument     *graphql.Loader
+	appointmentsByID       *graphql.Loader
+
+	mu        sync.Mutex
+	byDentist map[appointmentFilter]*graphql.Loader
+	byPatient map[appointmentFilter]*graphql.Loader
+}
+
+// appointmentFilter são os argumentos das consultas de um dentista ou paciente
+type appointmentFilter struct {
+	from, to time.Time
+	status   string
+	page     domain.Page
+}
+
+func newGraphLoaders(ctx context.Context, s graph.Service) *graphLoaders {
+	l := &graphLoaders{
+		ctx:       ctx,
+		s:         s,
+		byDentist: map[appointmentFilter]*graphql.Loader{},
+		byPatient: map[appointmentFilter]*graphql.Loader{},
+	}
+	l.dentistsByID = graphql.NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		found, err := s.DentistsByID(ctx, intKeys(keys))
+		values := map[interface{}]interface{}{}
+		for id, d := range found {
+			values[id] = d
+		}
+		return values, err
+	})
+	l.dentistsByRegistration = graphql.NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		found, err := s.DentistsByRegistration(ctx, stringKeys(keys))
+		values := map[interface{}]interface{}{}
+		for registration, d := range found {
+			values[registration] = d
+		}
+		return values, err
+	})
+	l.patientsByID = graphql.NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		found, err := s.PatientsByID(ctx, intKeys(keys))
+		values := map[interface{}]interface{}{}
+		for id, p := range found {
+			values[id] = p
+		}
+		return values, err
+	})
+	l.patientsByDocument = graphql.NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		found, err := s.PatientsByDocument(ctx, stringKeys(keys))
+		values := map[interface{}]interface{}{}
+		for document, p := range found {
+			values[document] = p
+		}
+		return values, err
+	})
+	l.appointmentsByID = graphql.NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		found, err := s.AppointmentsByID(ctx, intKeys(keys))
+		values := map[interface{}]interface{}{}
+		for id, a := range found {
+			values[id] = a
+		}
+		return values, err
+	})
+	return l
+}
+
+// appointments retorna o loader das consultas por dentista (CRO) ou por paciente (documento)
+// com os filtros informados
+func (l *graphLoaders) appointments(byDentist bool, filter appointmentFilter) *graphql.Loader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	loaders := l.byPatient
+	if byDentist {
+		loaders = l.byDentist
+	}
+	if loader, ok := loaders[filter]; ok {
+		return loader
+	}
+	loader := graphql.NewLoader(l.ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		search := domain.AppointmentSearch{From: filter.from, To: filter.to, Status: filter.status, Page: filter.page}
+		var found map[string][]domain.AppointmentDTO
+		var err error
+		if byDentist {
+			search.Dentists = stringKeys(keys)
+			found, err = l.s.AppointmentsByDentist(ctx, search)
+		} else {
+			search.Patients = stringKeys(keys)
+			found, err = l.s.AppointmentsByPatient(ctx, search)
+		}
+		values := map[interface{}]interface{}{}
+		for _, key := range keys {
+			// quem não tem consultas recebe uma lista vazia, não nula
+			values[key] = append([]domain.AppointmentDTO{}, found[key.(string)]...)
+		}
+		return values, err
+	})
+	loaders[filter] = loader
+	return loader
+}
+
+func loaders(ctx context.Context) *graphLoaders {
+	return ctx.Value(loadersKey{}).(*graphLoaders)
+}
+
+func intKeys(keys []interface{}) []int {
+	ids := make([]int, len(keys))
+	for i, k := range keys {
+		ids[i] = k.(int)
+	}
+	return ids
+}
+
+func stringKeys(keys []interface{}) []string {
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = k.(string)
+	}
+	return values
+}
+
+// graphQLQuery monta o esquema: dentistas, pacientes e consultas, com os relacionamentos entre eles
+func graphQLQuery() *graphql.Object {
+	nonNullString := graphql.NonNullOf(graphql.String)
+	nonNullID := graphql.NonNullOf(graphql.ID)
+	pageArgs := []*graphql.Arg{
+		{Name: "limit", Description: "Quantidade de registros, de 1 a 100", Type: graphql.Int, DefaultValue: graph.DefaultLimit},
+		{Name: "offset", Description: "Registros a pular", Type: graphql.Int, DefaultValue: 0},
+	}
+	listComplexity := graphql.ListComplexity("limit")
+
+	statusValues := make([]*graphql.EnumValue, 0, 6)
+	for _, status := range []string{domain.StatusScheduled, domain.StatusConfirmed, domain.StatusCheckedIn, domain.StatusCompleted, domain.StatusCancelled, domain.StatusNoShow} {
+		statusValues = append(statusValues, &graphql.EnumValue{Name: status})
+	}
+	statusType := &graphql.Enum{Name: "AppointmentStatus", Description: "Situação da consulta", Values: statusValues}
+	filterArgs := append([]*graphql.Arg{
+		{Name: "status", Type: statusType},
+		{Name: "from", Description: "Início do período, como 2006-01-02 ou 2006-01-02T15:04", Type: graphql.String},
+		{Name: "to", Description: "Fim do período, exclusivo; só com a data, inclui o dia inteiro", Type: graphql.String},
+	}, pageArgs...)
+
+	dentistType := &graphql.Object{Name: "Dentist", Description: "Dentista"}
+	patientType := &graphql.Object{Name: "Patient", Description: "Paciente"}
+	appointmentType := &graphql.Object{Name: "Appointment", Description: "Consulta"}
+	addressType := &graphql.Object{Name: "Address", Description: "Endereço do paciente", Fields: []*graphql.Field{
+		{Name: "street", Type: nonNullString},
+		{Name: "number", Type: nonNullString},
+		{Name: "complement", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "district", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "city", Type: nonNullString},
+		{Name: "state", Type: nonNullString},
+		{Name: "zipCode", Type: nonNullString},
+	}}
+	contactType := &graphql.Object{Name: "Contact", Description: "Responsável legal ou contato de emergência", Fields: []*graphql.Field{
+		{Name: "name", Type: nonNullString},
+		{Name: "document", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "phone", Type: nonNullString},
+		{Name: "relationship", Type: graphql.String, Resolve: emptyAsNull},
+	}}
+	consentType := &graphql.Object{Name: "CommunicationConsent", Description: "Canais pelos quais o paciente aceita ser contatado", Fields: []*graphql.Field{
+		{Name: "email", Type: graphql.NonNullOf(graphql.Boolean)},
+		{Name: "sms", Type: graphql.NonNullOf(graphql.Boolean)},
+		{Name: "whatsapp", Type: graphql.NonNullOf(graphql.Boolean)},
+	}}
+	appointmentList := graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(appointmentType)))
+
+	dentistType.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNullID},
+		{Name: "name", Type: nonNullString},
+		{Name: "surname", Type: nonNullString},
+		{Name: "registration", Description: "Número do CRO", Type: nonNullString},
+		{Name: "appointments", Description: "Consultas do dentista, por data", Type: appointmentList, Args: filterArgs, Complexity: listComplexity,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter, err := parseAppointmentFilter(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return loaders(p.Context).appointments(true, filter).Load(p.Source.(domain.Dentist).Registration), nil
+			}},
+	}
+
+	patientType.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNullID},
+		{Name: "name", Type: nonNullString},
+		{Name: "surname", Type: nonNullString},
+		{Name: "document", Type: nonNullString},
+		{Name: "createdAt", Description: "Data de cadastro, como 02/01/2006 15:04", Type: nonNullString},
+		{Name: "email", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "phones", Type: graphql.NonNullOf(graphql.ListOf(nonNullString)), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return append([]string{}, p.Source.(domain.Patient).Phones...), nil
+		}},
+		{Name: "birthDate", Description: "Data de nascimento, como 02/01/2006", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "preferredLanguage", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "address", Type: addressType},
+		{Name: "guardian", Type: contactType},
+		{Name: "emergencyContact", Type: contactType},
+		{Name: "consent", Type: consentType},
+		{Name: "appointments", Description: "Consultas do paciente, por data", Type: appointmentList, Args: filterArgs, Complexity: listComplexity,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter, err := parseAppointmentFilter(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return loaders(p.Context).appointments(false, filter).Load(p.Source.(domain.Patient).Document), nil
+			}},
+	}
+
+	appointmentType.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNullID},
+		{Name: "description", Type: nonNullString},
+		{Name: "appointmentDate", Description: "Data e hora, como 02/01/2006 15:04", Type: nonNullString},
+		{Name: "status", Type: graphql.NonNullOf(statusType)},
+		{Name: "procedureCode", Type: graphql.String, Resolve: emptyAsNull},
+		{Name: "duration", Description: "Duração em minutos", Type: graphql.NonNullOf(graphql.Int)},
+		{Name: "version", Description: "Versão da consulta, a enviar no If-Match das alterações pela API REST", Type: graphql.NonNullOf(graphql.Int)},
+		{Name: "dentist", Type: graphql.NonNullOf(dentistType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			// a consulta já é lida junto com o dentista
+			return p.Source.(domain.AppointmentDTO).Dentist, nil
+		}},
+		{Name: "patient", Type: graphql.NonNullOf(patientType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			// a consulta traz só os dados básicos do paciente; o cadastro completo é buscado em lote
+			return loaders(p.Context).patientsByDocument.Load(p.Source.(domain.AppointmentDTO).IdPatient), nil
+		}},
+	}
+
+	return &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "dentist", Description: "Busca um dentista pelo id ou pelo CRO", Type: dentistType,
+			Args: []*graphql.Arg{{Name: "id", Type: graphql.ID}, {Name: "registration", Type: graphql.String}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				l := loaders(p.Context)
+				if registration, ok := p.Args["registration"].(string); ok {
+					return l.dentistsByRegistration.Load(registration), nil
+				}
+				id, err := idArgument(p.Args, "id or registration")
+				if err != nil {
+					return nil, err
+				}
+				return l.dentistsByID.Load(id), nil
+			}},
+		{Name: "dentists", Description: "Lista os dentistas por id", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(dentistType))), Args: pageArgs, Complexity: listComplexity,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loaders(p.Context).s.Dentists(p.Context, pageArgument(p.Args))
+			}},
+		{Name: "patient", Description: "Busca um paciente pelo id ou pelo documento", Type: patientType,
+			Args: []*graphql.Arg{{Name: "id", Type: graphql.ID}, {Name: "document", Type: graphql.String}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				l := loaders(p.Context)
+				if document, ok := p.Args["document"].(string); ok {
+					return l.patientsByDocument.Load(document), nil
+				}
+				id, err := idArgument(p.Args, "id or document")
+				if err != nil {
+					return nil, err
+				}
+				return l.patientsByID.Load(id), nil
+			}},
+		{Name: "patients", Description: "Lista os pacientes por id", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(patientType))), Args: pageArgs, Complexity: listComplexity,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loaders(p.Context).s.Patients(p.Context, pageArgument(p.Args))
+			}},
+		{Name: "appointment", Description: "Busca uma consulta", Type: appointmentType,
+			Args: []*graphql.Arg{{Name: "id", Type: nonNullID}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArgument(p.Args, "id")
+				if err != nil {
+					return nil, err
+				}
+				return loaders(p.Context).appointmentsByID.Load(id), nil
+			}},
+		{Name: "appointments", Description: "Lista as consultas por data, filtradas por dentista (CRO), paciente (documento), situação e período", Type: appointmentList,
+			Args: append([]*graphql.Arg{
+				{Name: "dentist", Description: "CRO do dentista", Type: graphql.String},
+				{Name: "patient", Description: "Documento do paciente", Type: graphql.String},
+			}, filterArgs...),
+			Complexity: listComplexity,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter, err := parseAppointmentFilter(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				search := domain.AppointmentSearch{From: filter.from, To: filter.to, Status: filter.status, Page: filter.page}
+				if dentist, ok := p.Args["dentist"].(string); ok && dentist != "" {
+					search.Dentists = []string{dentist}
+				}
+				if patient, ok := p.Args["patient"].(string); ok && patient != "" {
+					search.Patients = []string{patient}
+				}
+				return loaders(p.Context).s.Appointments(p.Context, search)
+			}},
+	}}
+}
+
+// emptyAsNull lê o campo de texto do objeto e devolve nulo quando vazio, como os campos omitidos
+// do JSON da API REST
+func emptyAsNull(p graphql.ResolveParams) (interface{}, error) {
+	value, err := graphql.DefaultResolve(p)
+	if s, ok := value.(string); ok && s == "" {
+		return nil, err
+	}
+	return value, err
+}
+
+func pageArgument(args map[string]interface{}) domain.Page {
+	limit, _ := args["limit"].(int)
+	offset, _ := args["offset"].(int)
+	return domain.Page{Limit: limit, Offset: offset}
+}
+
+func idArgument(args map[string]interface{}, name string) (int, error) {
+	value, ok := args["id"].(string)
+	if !ok {
+		return 0, errors.New(name + " is required")
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
+
+// parseAppointmentFilter lê os argumentos de filtro das consultas; as datas seguem os formatos
+// aceitos nas exportações
+func parseAppointmentFilter(args map[string]interface{}) (appointmentFilter, error) {
+	filter := appointmentFilter{page: pageArgument(args)}
+	filter.status, _ = args["status"].(string)
+	var err error
+	from, _ := args["from"].(string)
+	if filter.from, err = parseExportDate(from, false); err != nil {
+		return filter, errors.New("invalid from date")
+	}
+	to, _ := args["to"].(string)
+	if filter.to, err = parseExportDate(to, true); err != nil {
+		return filter, errors.New("invalid to date")
+	}
+	return filter, nil
+}
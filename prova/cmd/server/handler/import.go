@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/importer"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// maxImportSize limita o tamanho da planilha enviada
+const maxImportSize = 10 << 20
+
+type importHandler struct {
+	s importer.Service
+}
+
+// NewImportHandler cria um novo controller das importações de planilhas
+func NewImportHandler(s importer.Service) *importHandler {
+	return &importHandler{
+		s: s,
+	}
+}
+
+// Patients importa pacientes de uma planilha CSV ou XLSX enviada no campo file
+func (h *importHandler) Patients() gin.HandlerFunc {
+	return h.handle(func(data []byte, opts importer.Options) (domain.ImportReport, error) {
+		return h.s.Patients(data, opts)
+	})
+}
+
+// Dentists importa dentistas de uma planilha CSV ou XLSX enviada no campo file
+func (h *importHandler) Dentists() gin.HandlerFunc {
+	return h.handle(func(data []byte, opts importer.Options) (domain.ImportReport, error) {
+		return h.s.Dentists(data, opts)
+	})
+}
+
+// handle lê a planilha e as opções (format, mapping, dry_run, on_duplicate e batch_size), que podem
+// vir no formulário ou na query string, e responde com o relatório por linha
+func (h *importHandler) handle(run func(data []byte, opts importer.Options) (domain.ImportReport, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize+1<<20)
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "spreadsheet must be sent in the file field")
+			return
+		}
+		if header.Size > maxImportSize {
+			web.BadResponse(ctx, http.StatusRequestEntityTooLarge, "error", "spreadsheet is too large")
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid spreadsheet")
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid spreadsheet")
+			return
+		}
+
+		opts := importer.Options{
+			Format:      importOption(ctx, "format"),
+			OnDuplicate: importOption(ctx, "on_duplicate"),
+		}
+		if opts.Format == "" {
+			opts.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+			if opts.Format != "csv" && opts.Format != "xlsx" {
+				opts.Format = ""
+			}
+		}
+		if value := importOption(ctx, "mapping"); value != "" {
+			if err := json.Unmarshal([]byte(value), &opts.Mapping); err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "mapping must be a JSON object of field to column")
+				return
+			}
+		}
+		if value := importOption(ctx, "dry_run"); value != "" {
+			if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid dry_run")
+				return
+			}
+		}
+		if value := importOption(ctx, "batch_size"); value != "" {
+			if opts.BatchSize, err = strconv.Atoi(value); err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid batch_size")
+				return
+			}
+		}
+
+		report, err := run(data, opts)
+		if err != nil {
+			web.BadResponse(ctx, importErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, report)
+	}
+}
+
+// importOption lê a opção do formulário ou, na falta dele, da query string
+func importOption(ctx *gin.Context, name string) string {
+	if value := ctx.PostForm(name); value != "" {
+		return value
+	}
+	return ctx.Query(name)
+}
+
+// importErrorStatus traduz os erros do serviço de importação para o status HTTP correspondente
+func importErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, importer.ErrInvalidFile), errors.Is(err, importer.ErrInvalidMapping),
+		errors.Is(err, importer.ErrInvalidOptions):
+		return http.StatusBadRequest
+	case errors.Is(err, importer.ErrTooManyRows):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/insurance"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type insuranceHandler struct {
+	s insurance.Service
+}
+
+// NewInsuranceHandler cria um novo controller de convênios
+func NewInsuranceHandler(s insurance.Service) *insuranceHandler {
+	return &insuranceHandler{
+		s: s,
+	}
+}
+
+// GetPlans retorna todos os planos de convênio
+func (h *insuranceHandler) GetPlans() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.GetPlans()
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetPlan retorna um plano de convênio por id
+func (h *insuranceHandler) GetPlan() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetPlan(id)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// PostPlan cadastra um novo plano de convênio
+func (h *insuranceHandler) PostPlan() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var plan domain.InsurancePlan
+		if err := ctx.ShouldBindJSON(&plan); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid insurance plan data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.CreatePlan(plan)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// PutPlan substitui os dados e as coberturas de um plano de convênio
+func (h *insuranceHandler) PutPlan() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var plan domain.InsurancePlan
+		if err := ctx.ShouldBindJSON(&plan); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid insurance plan data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.UpdatePlan(id, plan)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetMemberships retorna as adesões a convênios de um paciente
+func (h *insuranceHandler) GetMemberships() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetMemberships(id)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// PostMembership registra a adesão de um paciente a um plano de convênio
+func (h *insuranceHandler) PostMembership() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var membership domain.Membership
+		if err := ctx.ShouldBindJSON(&membership); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid membership data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.AddMembership(id, membership)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// DeleteMembership exclui uma adesão do paciente
+func (h *insuranceHandler) DeleteMembership() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		membershipID, err := strconv.Atoi(ctx.Param("membershipId"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid membership id provided")
+			return
+		}
+		if err := h.s.DeleteMembership(id, membershipID); err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.DeleteResponse(ctx, http.StatusOK, "membership deleted")
+	}
+}
+
+// GetPreAuthorizations retorna os pedidos de autorização prévia de um paciente
+func (h *insuranceHandler) GetPreAuthorizations() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetPreAuthorizations(id)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// PostPreAuthorization solicita a autorização prévia de um procedimento ao convênio do paciente
+func (h *insuranceHandler) PostPreAuthorization() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var authorization domain.PreAuthorization
+		if err := ctx.ShouldBindJSON(&authorization); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid pre-authorization data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.RequestPreAuthorization(id, authorization)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// GetPreAuthorization retorna um pedido de autorização prévia por id
+func (h *insuranceHandler) GetPreAuthorization() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetPreAuthorization(id)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// decisionRequest é a decisão da operadora sobre uma pré-autorização
+type decisionRequest struct {
+	Status    string `json:"status" binding:"required" openapi:"enum=approved|denied"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+// Decide registra a decisão da operadora sobre um pedido de autorização prévia pendente
+func (h *insuranceHandler) Decide() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var r decisionRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid request")
+			return
+		}
+		response, err := h.s.Decide(id, r.Status, r.Reference, r.Reason)
+		if err != nil {
+			web.BadResponse(ctx, insuranceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// insuranceErrorStatus traduz os erros do serviço de convênios para o status HTTP correspondente
+func insuranceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, insurance.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, insurance.ErrInvalidPlan), errors.Is(err, insurance.ErrInvalidMembership), errors.Is(err, insurance.ErrInvalidAuthorization):
+		return http.StatusBadRequest
+	case errors.Is(err, insurance.ErrPlanExists), errors.Is(err, insurance.ErrCardExists), errors.Is(err, insurance.ErrAlreadyDecided):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/invoice"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type invoiceHandler struct {
+	s invoice.Service
+}
+
+// NewInvoiceHandler cria um novo controller de faturas
+func NewInvoiceHandler(s invoice.Service) *invoiceHandler {
+	return &invoiceHandler{
+		s: s,
+	}
+}
+
+// GetAll retorna as faturas, opcionalmente filtradas por ?patient=
+func (h *invoiceHandler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		patientID := 0
+		if patientParam := ctx.Query("patient"); patientParam != "" {
+			var err error
+			patientID, err = strconv.Atoi(patientParam)
+			if err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid patient id provided")
+				return
+			}
+		}
+		response, err := h.s.GetAll(patientID)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetByID retorna uma fatura por id
+func (h *invoiceHandler) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetByID(id)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Post gera uma fatura a partir de consultas concluídas
+func (h *invoiceHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var r invoice.Request
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid invoice data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.Create(r)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// PostPayment registra um pagamento na fatura
+func (h *invoiceHandler) PostPayment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var payment domain.Payment
+		if err := ctx.ShouldBindJSON(&payment); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid payment data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.AddPayment(id, payment)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// Cancel cancela uma fatura sem pagamentos
+func (h *invoiceHandler) Cancel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Cancel(id)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Balance retorna o saldo financeiro de um paciente
+func (h *invoiceHandler) Balance() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Balance(id)
+		if err != nil {
+			web.BadResponse(ctx, invoiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// invoiceErrorStatus traduz os erros do serviço de faturas para o status HTTP correspondente
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, invoice.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, invoice.ErrInvalidInvoice), errors.Is(err, invoice.ErrInvalidPayment):
+		return http.StatusBadRequest
+	case errors.Is(err, invoice.ErrAlreadyInvoiced), errors.Is(err, invoice.ErrClosed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
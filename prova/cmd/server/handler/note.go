@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/note"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type noteHandler struct {
+	s note.Service
+}
+
+// NewNoteHandler cria um novo controller de notas clínicas
+func NewNoteHandler(s note.Service) *noteHandler {
+	return &noteHandler{
+		s: s,
+	}
+}
+
+type noteRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// GetByAppointment retorna as notas clínicas de uma consulta
+func (h *noteHandler) GetByAppointment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.GetByAppointment(claims, id)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetByPatient retorna o histórico de notas clínicas de um paciente
+func (h *noteHandler) GetByPatient() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.GetByPatient(claims, id)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Post registra uma nova nota clínica na consulta
+func (h *noteHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var r noteRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid note data")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.Create(claims, id, r.Content)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// Patch altera o conteúdo de uma nota ainda não assinada
+func (h *noteHandler) Patch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, noteID, ok := noteParams(ctx)
+		if !ok {
+			return
+		}
+		var r noteRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid note data")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.Update(claims, id, noteID, r.Content)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Sign assina uma nota clínica
+func (h *noteHandler) Sign() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, noteID, ok := noteParams(ctx)
+		if !ok {
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.Sign(claims, id, noteID)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// PostAddendum adiciona um adendo a uma nota assinada
+func (h *noteHandler) PostAddendum() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, noteID, ok := noteParams(ctx)
+		if !ok {
+			return
+		}
+		var r noteRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid addendum data")
+			return
+		}
+		claims, _ := auth.FromContext(ctx)
+		response, err := h.s.AddAddendum(claims, id, noteID, r.Content)
+		if err != nil {
+			web.BadResponse(ctx, noteErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// noteParams lê os ids da consulta e da nota da rota
+func noteParams(ctx *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+		return 0, 0, false
+	}
+	noteID, err := strconv.Atoi(ctx.Param("noteId"))
+	if err != nil {
+		web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid note id provided")
+		return 0, 0, false
+	}
+	return id, noteID, true
+}
+
+// noteErrorStatus traduz os erros do serviço de notas para o status HTTP correspondente
+func noteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, note.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, note.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, note.ErrSigned), errors.Is(err, note.ErrNotSigned):
+		return http.StatusConflict
+	case errors.Is(err, note.ErrEmptyContent):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
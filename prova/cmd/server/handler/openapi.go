@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/importer"
+	"github.com/meirafa/prova2-golang/internal/invoice"
+	"github.com/meirafa/prova2-golang/internal/selfservice"
+	"github.com/meirafa/prova2-golang/pkg/graphql"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// Caminhos em que o documento OpenAPI e a interface interativa são publicados
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+)
+
+// APIInfo descreve a API no documento OpenAPI
+var APIInfo = openapi.Info{
+	Title:       "Clínica odontológica",
+	Description: "API de agenda, cadastro e atendimento da clínica. As respostas de êxito trazem o resultado no campo data; as de erro, o envelope com status_code, status e message.",
+	Version:     "1.0.0",
+}
+
+// Formats são os formatos próprios da API usados nos esquemas, conferidos pelo validador
+var Formats = map[string]openapi.Format{
+	// número do CRO, como CRO-SP 12345
+	"registration": func(value string) bool {
+		_, err := domain.ParseRegistration(value)
+		return err == nil
+	},
+	// documento do paciente, só com dígitos
+	"document": func(value string) bool {
+		_, err := strconv.ParseUint(value, 10, 64)
+		return err == nil
+	},
+	// data e hora da consulta, como 25/12/2022 14:30
+	"appointment-date": func(value string) bool {
+		_, err := time.Parse(appointment.DateLayout, value)
+		return err == nil
+	},
+}
+
+// APITags agrupa as rotas na interface
+var APITags = []openapi.Tag{
+	{Name: "appointments", Description: "Consultas"},
+	{Name: "notes", Description: "Notas clínicas das consultas"},
+	{Name: "dentists", Description: "Dentistas"},
+	{Name: "patients", Description: "Pacientes"},
+	{Name: "chart", Description: "Odontograma"},
+	{Name: "treatment-plans", Description: "Planos de tratamento"},
+	{Name: "procedures", Description: "Catálogo de procedimentos"},
+	{Name: "invoices", Description: "Faturas e pagamentos"},
+	{Name: "insurance", Description: "Convênios, adesões e autorizações prévias"},
+	{Name: "webhooks", Description: "Assinaturas e entregas de webhooks"},
+	{Name: "calendar", Description: "Calendários iCalendar"},
+	{Name: "users", Description: "Usuários e autenticação"},
+	{Name: "import", Description: "Importação de planilhas"},
+	{Name: "export", Description: "Exportação de dados"},
+	{Name: "reports", Description: "Relatórios gerenciais"},
+	{Name: "self-service", Description: "Links de confirmação e cancelamento enviados ao paciente"},
+	{Name: "caldav", Description: "Agenda dos dentistas via CalDAV"},
+	{Name: "graphql", Description: "Consultas GraphQL sobre dentistas, pacientes e consultas"},
+	{Name: "system", Description: "Saúde do serviço e documentação"},
+}
+
+// Parâmetros compartilhados pelas rotas de exportação e de relatórios
+var (
+	exportFormat = openapi.Param{Name: "format", Description: "Formato do arquivo, csv por padrão", Enum: []string{"csv", "jsonl", "xlsx"}}
+	exportFrom   = openapi.Param{Name: "from", Description: "Início do período, como 2006-01-02 ou 2006-01-02T15:04"}
+	exportTo     = openapi.Param{Name: "to", Description: "Fim do período, exclusivo; só com a data, inclui o dia inteiro"}
+	reportQuery  = []openapi.Param{
+		{Name: "from", Description: "Início do período, o primeiro dia do mês atual por padrão"},
+		{Name: "to", Description: "Fim do período; só com a data, inclui o dia inteiro"},
+		{Name: "period", Description: "Agrupamento dos resultados", Enum: []string{domain.PeriodDay, domain.PeriodWeek, domain.PeriodMonth}},
+		{Name: "format", Description: "Formato da resposta, json por padrão", Enum: []string{"json", "csv"}},
+	}
+	importForm = []openapi.Param{
+		{Name: "file", Type: "file", Required: true, Description: "Planilha CSV ou XLSX"},
+		{Name: "format", Description: "Formato do arquivo; sem ele, é deduzido da extensão ou do conteúdo", Enum: []string{"csv", "xlsx"}},
+		{Name: "mapping", Description: "Objeto JSON que associa as colunas da planilha aos campos"},
+		{Name: "dry_run", Type: "boolean", Description: "Só valida, sem gravar"},
+		{Name: "on_duplicate", Description: "Registros já cadastrados são ignorados (skip, padrão) ou atualizados (upsert)", Enum: []string{importer.DuplicateSkip, importer.DuplicateUpsert}},
+		{Name: "batch_size", Type: "integer", Description: "Registros gravados por transação"},
+	}
+	tokenQuery = []openapi.Param{{Name: "token", Required: true, Description: "Token secreto do link"}}
+	boardQuery = []openapi.Param{
+		{Name: "dentist", Description: "CRO do dentista"},
+		{Name: "date", Description: "Dia das consultas, como 2006-01-02"},
+		{Name: "last_event_id", Type: "integer", Description: "Último evento recebido, para receber os perdidos; o mesmo que o cabeçalho Last-Event-ID"},
+	}
+	// appointmentIfMatchHeaders traz a ETag devolvida ao buscar a consulta; sem ela a resposta é
+	// 428 e, se a consulta mudou desde então, 412
+	appointmentIfMatchHeaders = []openapi.Param{
+		{Name: "If-Match", Required: true, Description: "ETag da versão lida da consulta, ou * para não conferir"},
+	}
+	graphQLParams = []openapi.Param{
+		{Name: "query", Required: true, Description: "Documento GraphQL"},
+		{Name: "operationName", Description: "Operação a executar, quando o documento tem mais de uma"},
+		{Name: "variables", Description: "Objeto JSON com as variáveis"},
+	}
+)
+
+// Tipos de conteúdo das respostas que não são JSON
+var (
+	exportContentTypes = []string{"text/csv", "application/x-ndjson", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}
+	davContentTypes    = []string{"application/xml"}
+)
+
+// Routes é o catálogo de todas as rotas registradas em cmd/main.go, de onde é gerado o documento
+// OpenAPI. Uma rota nova precisa ser incluída aqui, ou o servidor não inicia.
+func Routes() []openapi.Route {
+	admin := []string{domain.RoleAdmin}
+	office := []string{domain.RoleAdmin, domain.RoleReception}
+	clinical := []string{domain.RoleAdmin, domain.RoleDentist}
+	deleted := web.ErrorResponse{}
+
+	routes := []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/login", Tag: "users", Summary: "Autentica o usuário e emite um token de acesso", Body: loginRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/api/users", Tag: "users", Summary: "Cadastra um usuário", Auth: true, Roles: admin, Body: userRequest{}, Status: http.StatusCreated, Response: domain.User{}},
+
+		{Method: http.MethodPost, Path: "/api/import/patients", Tag: "import", Summary: "Importa pacientes de uma planilha", Auth: true, Roles: office, Form: importForm, Response: domain.ImportReport{}},
+		{Method: http.MethodPost, Path: "/api/import/dentists", Tag: "import", Summary: "Importa dentistas de uma planilha", Auth: true, Roles: office, Form: importForm, Response: domain.ImportReport{}},
+
+		{Method: http.MethodGet, Path: "/api/export/appointments", Tag: "export", Summary: "Exporta as consultas", Auth: true, Roles: office, ContentTypes: exportContentTypes, Query: []openapi.Param{
+			exportFormat, exportFrom, exportTo,
+			{Name: "dentist", Description: "CRO do dentista"},
+			{Name: "patient", Description: "Documento do paciente"},
+			{Name: "status", Description: "Situação da consulta"},
+		}},
+		{Method: http.MethodGet, Path: "/api/export/patients", Tag: "export", Summary: "Exporta os pacientes cadastrados no período", Auth: true, Roles: office, ContentTypes: exportContentTypes, Query: []openapi.Param{exportFormat, exportFrom, exportTo}},
+		{Method: http.MethodGet, Path: "/api/export/dentists", Tag: "export", Summary: "Exporta os dentistas", Auth: true, Roles: office, ContentTypes: exportContentTypes, Query: []openapi.Param{exportFormat}},
+
+		{Method: http.MethodGet, Path: "/api/reports/utilization", Tag: "reports", Summary: "Ocupação da agenda por dentista e período", Auth: true, Roles: admin, Query: reportQuery, Response: []domain.UtilizationRow{}, ContentTypes: []string{"text/csv"}},
+		{Method: http.MethodGet, Path: "/api/reports/no-shows", Tag: "reports", Summary: "Taxa de faltas por dentista e período", Auth: true, Roles: admin, Query: reportQuery, Response: []domain.NoShowRow{}, ContentTypes: []string{"text/csv"}},
+		{Method: http.MethodGet, Path: "/api/reports/new-patients", Tag: "reports", Summary: "Pacientes cadastrados por período", Auth: true, Roles: admin, Query: reportQuery, Response: []domain.NewPatientsRow{}, ContentTypes: []string{"text/csv"}},
+		{Method: http.MethodGet, Path: "/api/reports/appointments-by-dentist", Tag: "reports", Summary: "Consultas de cada dentista por situação", Auth: true, Roles: admin, Query: reportQuery, Response: []domain.DentistAppointmentsRow{}, ContentTypes: []string{"text/csv"}},
+
+		{Method: http.MethodGet, Path: "/api/appointments", Tag: "appointments", Summary: "Lista as consultas", Response: []domain.AppointmentDTO{}},
+		{Method: http.MethodGet, Path: "/api/appointments/:id", Tag: "appointments", Summary: "Busca uma consulta; a versão vai no cabeçalho ETag", Response: domain.AppointmentDTO{}},
+		{Method: http.MethodGet, Path: "/api/appointments/stream", Tag: "appointments", Summary: "Acompanha as alterações das consultas por Server-Sent Events; na reconexão, o cabeçalho Last-Event-ID traz os eventos perdidos", Auth: true, Query: boardQuery, ContentTypes: []string{"text/event-stream"}},
+		{Method: http.MethodGet, Path: "/api/appointments/stream/ws", Tag: "appointments", Summary: "Acompanha as alterações das consultas por WebSocket, uma mensagem JSON por evento; o navegador envia o token no subprotocolo, como \"bearer, <token>\"", Auth: true, Query: boardQuery, Status: http.StatusSwitchingProtocols},
+		{Method: http.MethodGet, Path: "/api/appointments/patient/:document", Tag: "appointments", Summary: "Lista as consultas de um paciente pelo documento", Params: []openapi.Param{{Name: "document", Format: "document"}}, Response: []domain.AppointmentDTO{}},
+		{Method: http.MethodPost, Path: "/api/appointments", Tag: "appointments", Summary: "Agenda uma consulta", Body: domain.Appointment{}, Response: domain.AppointmentDTO{}},
+		{Method: http.MethodPut, Path: "/api/appointments/:id", Tag: "appointments", Summary: "Substitui uma consulta", Headers: appointmentIfMatchHeaders, Body: domain.Appointment{}, Response: domain.AppointmentDTO{}},
+		{Method: http.MethodPatch, Path: "/api/appointments/:id", Tag: "appointments", Summary: "Altera campos de uma consulta", Headers: appointmentIfMatchHeaders, Body: appointmentPatchRequest{}, Response: domain.AppointmentDTO{}},
+		{Method: http.MethodDelete, Path: "/api/appointments/:id", Tag: "appointments", Summary: "Remove uma consulta", Headers: appointmentIfMatchHeaders, Response: deleted, Raw: true},
+		{Method: http.MethodPost, Path: "/api/appointments/:id/links", Tag: "self-service", Summary: "Emite os links de confirmação e cancelamento da consulta", Auth: true, Status: http.StatusCreated, Response: domain.AppointmentLinks{}},
+
+		{Method: http.MethodGet, Path: "/api/appointments/:id/notes", Tag: "notes", Summary: "Lista as notas clínicas da consulta", Auth: true, Response: []domain.ClinicalNote{}},
+		{Method: http.MethodPost, Path: "/api/appointments/:id/notes", Tag: "notes", Summary: "Registra uma nota clínica", Auth: true, Body: noteRequest{}, Status: http.StatusCreated, Response: domain.ClinicalNote{}},
+		{Method: http.MethodPatch, Path: "/api/appointments/:id/notes/:noteId", Tag: "notes", Summary: "Altera uma nota ainda não assinada", Auth: true, Body: noteRequest{}, Response: domain.ClinicalNote{}},
+		{Method: http.MethodPost, Path: "/api/appointments/:id/notes/:noteId/sign", Tag: "notes", Summary: "Assina uma nota clínica", Auth: true, Response: domain.ClinicalNote{}},
+		{Method: http.MethodPost, Path: "/api/appointments/:id/notes/:noteId/addenda", Tag: "notes", Summary: "Acrescenta um adendo a uma nota assinada", Auth: true, Body: noteRequest{}, Status: http.StatusCreated, Response: domain.ClinicalNote{}},
+
+		{Method: http.MethodGet, Path: "/api/dentists", Tag: "dentists", Summary: "Lista os dentistas", Response: []domain.Dentist{}},
+		{Method: http.MethodGet, Path: "/api/dentists/:id", Tag: "dentists", Summary: "Busca um dentista", Response: domain.Dentist{}},
+		{Method: http.MethodPost, Path: "/api/dentists", Tag: "dentists", Summary: "Cadastra um dentista", Body: domain.Dentist{}, Status: http.StatusCreated, Response: domain.Dentist{}},
+		{Method: http.MethodPut, Path: "/api/dentists/:id", Tag: "dentists", Summary: "Substitui um dentista", Body: domain.Dentist{}, Response: domain.Dentist{}},
+		{Method: http.MethodPatch, Path: "/api/dentists/:id", Tag: "dentists", Summary: "Altera campos de um dentista", Body: dentistPatchRequest{}, Response: domain.Dentist{}},
+		{Method: http.MethodDelete, Path: "/api/dentists/:id", Tag: "dentists", Summary: "Remove um dentista", Response: deleted, Raw: true},
+		{Method: http.MethodGet, Path: "/api/dentists/:id/calendar.ics", Tag: "calendar", Summary: "Agenda do dentista em iCalendar", Query: tokenQuery, ContentTypes: []string{calendarMediaType}},
+		{Method: http.MethodPost, Path: "/api/dentists/:id/calendar-feed", Tag: "calendar", Summary: "Emite um novo endereço secreto da agenda do dentista", Auth: true, Roles: clinical, Status: http.StatusCreated, Response: domain.CalendarFeed{}},
+
+		{Method: http.MethodGet, Path: "/api/patients", Tag: "patients", Summary: "Lista os pacientes", Response: []domain.Patient{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id", Tag: "patients", Summary: "Busca um paciente", Response: domain.Patient{}},
+		{Method: http.MethodPost, Path: "/api/patients", Tag: "patients", Summary: "Cadastra um paciente", Body: domain.Patient{}, Status: http.StatusCreated, Response: domain.Patient{}},
+		{Method: http.MethodPut, Path: "/api/patients/:id", Tag: "patients", Summary: "Substitui um paciente", Body: domain.Patient{}, Response: domain.Patient{}},
+		{Method: http.MethodPatch, Path: "/api/patients/:id", Tag: "patients", Summary: "Altera campos de um paciente", Body: patientPatchRequest{}, Response: domain.Patient{}},
+		{Method: http.MethodDelete, Path: "/api/patients/:id", Tag: "patients", Summary: "Remove um paciente", Response: deleted, Raw: true},
+		{Method: http.MethodGet, Path: "/api/patients/:id/calendar.ics", Tag: "calendar", Summary: "Consultas do paciente em iCalendar", Query: tokenQuery, ContentTypes: []string{calendarMediaType}},
+		{Method: http.MethodPost, Path: "/api/patients/:id/calendar-feed", Tag: "calendar", Summary: "Emite um novo endereço secreto do calendário do paciente", Auth: true, Roles: clinical, Status: http.StatusCreated, Response: domain.CalendarFeed{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/notes", Tag: "notes", Summary: "Lista as notas clínicas do paciente; dentistas veem só as das consultas em que o atenderam", Auth: true, Response: []domain.ClinicalNote{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/chart", Tag: "chart", Summary: "Odontograma atual do paciente", Auth: true, Roles: clinical, Response: domain.DentalChart{}},
+		{Method: http.MethodPatch, Path: "/api/patients/:id/chart", Tag: "chart", Summary: "Registra alterações no odontograma", Auth: true, Body: chartPatchRequest{}, Response: domain.DentalChart{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/chart/history", Tag: "chart", Summary: "Histórico do odontograma", Auth: true, Roles: clinical, Query: []openapi.Param{{Name: "tooth", Type: "integer", Description: "Dente, na numeração FDI"}}, Response: []domain.ChartEntry{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/treatment-plans", Tag: "treatment-plans", Summary: "Lista os planos de tratamento do paciente", Auth: true, Roles: clinical, Response: []domain.TreatmentPlan{}},
+		{Method: http.MethodPost, Path: "/api/patients/:id/treatment-plans", Tag: "treatment-plans", Summary: "Cria um plano de tratamento", Auth: true, Roles: clinical, Body: domain.TreatmentPlan{}, Status: http.StatusCreated, Response: domain.TreatmentPlan{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/balance", Tag: "invoices", Summary: "Saldo financeiro do paciente", Auth: true, Roles: office, Response: domain.PatientBalance{}},
+		{Method: http.MethodGet, Path: "/api/patients/:id/memberships", Tag: "insurance", Summary: "Lista as adesões do paciente a convênios", Auth: true, Roles: office, Response: []domain.Membership{}},
+		{Method: http.MethodPost, Path: "/api/patients/:id/memberships", Tag: "insurance", Summary: "Registra a adesão a um convênio", Auth: true, Roles: office, Body: domain.Membership{}, Status: http.StatusCreated, Response: domain.Membership{}},
+		{Method: http.MethodDelete, Path: "/api/patients/:id/memberships/:membershipId", Tag: "insurance", Summary: "Remove uma adesão", Auth: true, Roles: office, Response: deleted, Raw: true},
+		{Method: http.MethodGet, Path: "/api/patients/:id/pre-authorizations", Tag: "insurance", Summary: "Lista as autorizações prévias do paciente", Auth: true, Response: []domain.PreAuthorization{}},
+		{Method: http.MethodPost, Path: "/api/patients/:id/pre-authorizations", Tag: "insurance", Summary: "Solicita uma autorização prévia ao convênio", Auth: true, Body: domain.PreAuthorization{}, Status: http.StatusCreated, Response: domain.PreAuthorization{}},
+
+		{Method: http.MethodGet, Path: "/api/treatment-plans/:id", Tag: "treatment-plans", Summary: "Busca um plano de tratamento", Auth: true, Roles: clinical, Response: domain.TreatmentPlan{}},
+		{Method: http.MethodGet, Path: "/api/treatment-plans/:id/progress", Tag: "treatment-plans", Summary: "Andamento do plano de tratamento", Auth: true, Roles: clinical, Response: domain.PlanProgress{}},
+		{Method: http.MethodPut, Path: "/api/treatment-plans/:id", Tag: "treatment-plans", Summary: "Substitui um plano ainda não aceito", Auth: true, Roles: clinical, Body: domain.TreatmentPlan{}, Response: domain.TreatmentPlan{}},
+		{Method: http.MethodDelete, Path: "/api/treatment-plans/:id", Tag: "treatment-plans", Summary: "Remove um plano de tratamento", Auth: true, Roles: clinical, Response: deleted, Raw: true},
+		{Method: http.MethodPost, Path: "/api/treatment-plans/:id/accept", Tag: "treatment-plans", Summary: "Registra o aceite do plano", Auth: true, Roles: clinical, Response: domain.TreatmentPlan{}},
+		{Method: http.MethodPost, Path: "/api/treatment-plans/:id/cancel", Tag: "treatment-plans", Summary: "Cancela o plano", Auth: true, Roles: clinical, Response: domain.TreatmentPlan{}},
+		{Method: http.MethodPatch, Path: "/api/treatment-plans/:id/items/:itemId", Tag: "treatment-plans", Summary: "Associa um item do plano a uma consulta", Auth: true, Roles: clinical, Body: scheduleItemRequest{}, Response: domain.TreatmentPlan{}},
+
+		{Method: http.MethodGet, Path: "/api/procedures", Tag: "procedures", Summary: "Lista os procedimentos", Auth: true, Roles: office, Response: []domain.Procedure{}},
+		{Method: http.MethodGet, Path: "/api/procedures/:code", Tag: "procedures", Summary: "Busca um procedimento", Auth: true, Roles: office, Response: domain.Procedure{}},
+		{Method: http.MethodPost, Path: "/api/procedures", Tag: "procedures", Summary: "Cadastra um procedimento", Auth: true, Roles: office, Body: domain.Procedure{}, Status: http.StatusCreated, Response: domain.Procedure{}},
+		{Method: http.MethodPut, Path: "/api/procedures/:code", Tag: "procedures", Summary: "Altera um procedimento", Auth: true, Roles: office, Body: procedureUpdateRequest{}, Response: domain.Procedure{}},
+		{Method: http.MethodDelete, Path: "/api/procedures/:code", Tag: "procedures", Summary: "Remove um procedimento", Auth: true, Roles: office, Response: deleted, Raw: true},
+
+		{Method: http.MethodGet, Path: "/api/invoices", Tag: "invoices", Summary: "Lista as faturas", Auth: true, Roles: office, Query: []openapi.Param{{Name: "patient", Type: "integer", Description: "Id do paciente"}}, Response: []domain.Invoice{}},
+		{Method: http.MethodGet, Path: "/api/invoices/:id", Tag: "invoices", Summary: "Busca uma fatura", Auth: true, Roles: office, Response: domain.Invoice{}},
+		{Method: http.MethodPost, Path: "/api/invoices", Tag: "invoices", Summary: "Emite a fatura de uma consulta", Auth: true, Roles: office, Body: invoice.Request{}, Status: http.StatusCreated, Response: domain.Invoice{}},
+		{Method: http.MethodPost, Path: "/api/invoices/:id/payments", Tag: "invoices", Summary: "Registra um pagamento", Auth: true, Roles: office, Body: domain.Payment{}, Status: http.StatusCreated, Response: domain.Invoice{}},
+		{Method: http.MethodPost, Path: "/api/invoices/:id/cancel", Tag: "invoices", Summary: "Cancela uma fatura", Auth: true, Roles: office, Response: domain.Invoice{}},
+
+		{Method: http.MethodGet, Path: "/api/webhooks", Tag: "webhooks", Summary: "Lista as assinaturas", Auth: true, Roles: admin, Response: []domain.WebhookSubscription{}},
+		{Method: http.MethodGet, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Busca uma assinatura", Auth: true, Roles: admin, Response: domain.WebhookSubscription{}},
+		{Method: http.MethodGet, Path: "/api/webhooks/:id/deliveries", Tag: "webhooks", Summary: "Lista as entregas de uma assinatura", Auth: true, Roles: admin, Query: []openapi.Param{{Name: "status", Description: "Situação da entrega"}}, Response: []domain.WebhookDelivery{}},
+		{Method: http.MethodPost, Path: "/api/webhooks", Tag: "webhooks", Summary: "Cria uma assinatura", Auth: true, Roles: admin, Body: domain.WebhookSubscription{}, Status: http.StatusCreated, Response: domain.WebhookSubscription{}},
+		{Method: http.MethodPatch, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Altera uma assinatura", Auth: true, Roles: admin, Body: webhookPatchRequest{}, Response: domain.WebhookSubscription{}},
+		{Method: http.MethodDelete, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Remove uma assinatura", Auth: true, Roles: admin, Response: deleted, Raw: true},
+		{Method: http.MethodGet, Path: "/api/webhook-deliveries/dead", Tag: "webhooks", Summary: "Lista as entregas que esgotaram as tentativas", Auth: true, Roles: admin, Response: []domain.WebhookDelivery{}},
+		{Method: http.MethodPost, Path: "/api/webhook-deliveries/:id/retry", Tag: "webhooks", Summary: "Reagenda uma entrega que falhou", Auth: true, Roles: admin, Response: domain.WebhookDelivery{}},
+
+		{Method: http.MethodGet, Path: "/api/insurance-plans", Tag: "insurance", Summary: "Lista os convênios", Auth: true, Roles: office, Response: []domain.InsurancePlan{}},
+		{Method: http.MethodGet, Path: "/api/insurance-plans/:id", Tag: "insurance", Summary: "Busca um convênio", Auth: true, Roles: office, Response: domain.InsurancePlan{}},
+		{Method: http.MethodPost, Path: "/api/insurance-plans", Tag: "insurance", Summary: "Cadastra um convênio", Auth: true, Roles: office, Body: domain.InsurancePlan{}, Status: http.StatusCreated, Response: domain.InsurancePlan{}},
+		{Method: http.MethodPut, Path: "/api/insurance-plans/:id", Tag: "insurance", Summary: "Substitui um convênio", Auth: true, Roles: office, Body: domain.InsurancePlan{}, Response: domain.InsurancePlan{}},
+		{Method: http.MethodGet, Path: "/api/pre-authorizations/:id", Tag: "insurance", Summary: "Busca uma autorização prévia", Auth: true, Response: domain.PreAuthorization{}},
+		{Method: http.MethodPatch, Path: "/api/pre-authorizations/:id", Tag: "insurance", Summary: "Registra a decisão do convênio", Auth: true, Roles: clinical, Body: decisionRequest{}, Response: domain.PreAuthorization{}},
+
+		// os erros da consulta GraphQL vêm no campo errors, com status 200
+		{Method: http.MethodPost, Path: GraphQLPath, Tag: "graphql", Summary: "Executa uma consulta GraphQL", Auth: true, Body: graphql.Request{}, Response: graphql.Response{}, Raw: true},
+		{Method: http.MethodGet, Path: GraphQLPath, Tag: "graphql", Summary: "Executa uma consulta GraphQL pelos parâmetros da URL", Auth: true, Query: graphQLParams, Response: graphql.Response{}, Raw: true},
+	}
+
+	for _, action := range []string{domain.LinkConfirm, domain.LinkCancel} {
+		path := selfservice.PublicPath + action
+		routes = append(routes,
+			openapi.Route{Method: http.MethodGet, Path: path, Tag: "self-service", Summary: "Mostra a consulta do link (" + action + ")", Query: tokenQuery, Response: domain.LinkPreview{}},
+			openapi.Route{Method: http.MethodPost, Path: path, Tag: "self-service", Summary: "Usa o link (" + action + ")", Query: tokenQuery, Response: domain.LinkPreview{}},
+		)
+	}
+	// todas as rotas acima respondem os erros com o envelope JSON, que traz os campos inválidos
+	// quando a requisição é recusada pela validação
+	for i := range routes {
+		routes[i].ErrorType = web.ValidationErrorResponse{}
+	}
+
+	resource := CalDAVPath + "dentists/:id/schedule/:name"
+	collection := CalDAVPath + "dentists/:id/schedule/"
+	routes = append(routes,
+		openapi.Route{Method: http.MethodGet, Path: "/ping", Tag: "system", Summary: "Verifica se o serviço está no ar", ContentTypes: []string{"text/plain"}},
+		openapi.Route{Method: http.MethodGet, Path: OpenAPIPath, Tag: "system", Summary: "Este documento OpenAPI", ContentTypes: []string{openapi.JSON}},
+		openapi.Route{Method: http.MethodGet, Path: DocsPath, Tag: "system", Summary: "Interface interativa da documentação", ContentTypes: []string{"text/html"}},
+
+		openapi.Route{Method: http.MethodGet, Path: "/.well-known/caldav", Tag: "caldav", Summary: "Redireciona para o servidor CalDAV", Status: http.StatusMovedPermanently},
+		openapi.Route{Method: "PROPFIND", Path: "/.well-known/caldav", Tag: "caldav", Summary: "Redireciona para o servidor CalDAV", Status: http.StatusMovedPermanently},
+		openapi.Route{Method: http.MethodOptions, Path: CalDAVPath, Tag: "caldav", Summary: "Recursos DAV suportados", BasicAuth: true},
+		openapi.Route{Method: "PROPFIND", Path: CalDAVPath, Tag: "caldav", Summary: "Propriedades da raiz e do usuário atual", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: "PROPFIND", Path: CalDAVPath + "principals/:username/", Tag: "caldav", Summary: "Propriedades do usuário", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: "PROPFIND", Path: CalDAVPath + "dentists/:id/", Tag: "caldav", Summary: "Propriedades da pasta de calendários do dentista", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: http.MethodOptions, Path: collection, Tag: "caldav", Summary: "Recursos DAV suportados", BasicAuth: true},
+		openapi.Route{Method: "PROPFIND", Path: collection, Tag: "caldav", Summary: "Propriedades da agenda e de seus eventos", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: "REPORT", Path: collection, Tag: "caldav", Summary: "Consulta da agenda: calendar-query, calendar-multiget ou sync-collection", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: http.MethodOptions, Path: resource, Tag: "caldav", Summary: "Recursos DAV suportados", BasicAuth: true},
+		openapi.Route{Method: "PROPFIND", Path: resource, Tag: "caldav", Summary: "Propriedades de um evento", BasicAuth: true, BodyTypes: davContentTypes, Status: http.StatusMultiStatus, ContentTypes: davContentTypes},
+		openapi.Route{Method: http.MethodGet, Path: resource, Tag: "caldav", Summary: "Evento em iCalendar", BasicAuth: true, ContentTypes: []string{calendarMediaType}},
+		openapi.Route{Method: http.MethodHead, Path: resource, Tag: "caldav", Summary: "Cabeçalhos do evento", BasicAuth: true},
+		openapi.Route{Method: http.MethodPut, Path: resource, Tag: "caldav", Summary: "Cria ou altera um bloqueio de agenda", BasicAuth: true, BodyTypes: []string{calendarMediaType}, Status: http.StatusNoContent},
+		openapi.Route{Method: http.MethodDelete, Path: resource, Tag: "caldav", Summary: "Remove um bloqueio de agenda", BasicAuth: true, Status: http.StatusNoContent},
+	)
+	return routes
+}
+
+type openAPIHandler struct {
+	doc *openapi.Document
+}
+
+// NewOpenAPIHandler cria um novo controller da documentação
+func NewOpenAPIHandler(doc *openapi.Document) *openAPIHandler {
+	return &openAPIHandler{
+		doc: doc,
+	}
+}
+
+// Spec publica o documento OpenAPI
+func (h *openAPIHandler) Spec() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, h.doc)
+	}
+}
+
+// Docs publica a interface interativa que lê o documento de OpenAPIPath
+func (h *openAPIHandler) Docs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page, err := openapi.UI(h.doc.Info.Title, OpenAPIPath)
+		if err != nil {
+			web.BadResponse(ctx, http.StatusInternalServerError, "error", err.Error())
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
+
+// Undocumented lista as rotas registradas no gin que não constam do documento
+func Undocumented(doc *openapi.Document, routes gin.RoutesInfo) []string {
+	registered := make([]string, 0, len(routes))
+	for _, r := range routes {
+		registered = append(registered, r.Method+" "+r.Path)
+	}
+	return doc.Undocumented(registered)
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
@@ -63,12 +62,6 @@ func (h *patientHandler) Post() gin.HandlerFunc {
 			return
 		}
 
-		isValid, err := isEmptyPatient(&patient)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
-			return
-		}
-
 		response, err := h.s.Create(patient)
 		if err != nil {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
@@ -79,7 +72,7 @@ func (h *patientHandler) Post() gin.HandlerFunc {
 	}
 }
 
-//Put atualiza um paciente
+//Put substitui o cadastro de um paciente; os campos opcionais omitidos são apagados
 func (h *patientHandler) Put() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		idParam := ctx.Param("id")
@@ -102,33 +95,38 @@ func (h *patientHandler) Put() gin.HandlerFunc {
 		err = ctx.ShouldBindJSON(&patient)
 		if err != nil {
 			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid patient data")
-		}
-
-		isValid, err := isEmptyPatient(&patient)
-		if !isValid {
-			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
 			return
 		}
 
-		response, err := h.s.Update(id, patient)
+		response, err := h.s.Replace(id, patient)
 		if err != nil {
-			web.BadResponse(ctx, http.StatusConflict, "error", err.Error())
+			web.BadResponse(ctx, patientErrorStatus(err, http.StatusConflict), "error", err.Error())
 			return
 		}
 		web.ResponseOK(ctx, http.StatusOK, response)
 	}
 }
 
+// patientPatchRequest são os campos do paciente que podem ser alterados parcialmente
+type patientPatchRequest struct {
+	Surname           string                       `json:"surname,omitempty"`
+	Name              string                       `json:"name,omitempty"`
+	Document          string                       `json:"document,omitempty"`
+	CreatedAt         string                       `json:"created_at,omitempty"`
+	Email             string                       `json:"email,omitempty"`
+	Phones            []string                     `json:"phones,omitempty"`
+	BirthDate         string                       `json:"birth_date,omitempty"`
+	Address           *domain.Address              `json:"address,omitempty"`
+	Guardian          *domain.Contact              `json:"guardian,omitempty"`
+	EmergencyContact  *domain.Contact              `json:"emergency_contact,omitempty"`
+	PreferredLanguage string                       `json:"preferred_language,omitempty"`
+	Consent           *domain.CommunicationConsent `json:"consent,omitempty"`
+}
+
 // Patch atualiza um paciente ou algum de seus campos
 func (h *patientHandler) Patch() gin.HandlerFunc {
-	type Request struct {
-		Surname   string `json:"surname,omitempty"`
-		Name      string `json:"name,omitempty"`
-		Document  string `json:"document,omitempty"`
-		CreatedAt string `json:"created_at,omitempty"`
-	}
 	return func(ctx *gin.Context) {
-		var r Request
+		var r patientPatchRequest
 		idParam := ctx.Param("id")
 		id, err := strconv.Atoi(idParam)
 		if err != nil {
@@ -145,10 +143,18 @@ func (h *patientHandler) Patch() gin.HandlerFunc {
 			return
 		}
 		update := domain.Patient{
-			Surname:   r.Surname,
-			Name:      r.Name,
-			Document:  r.Document,
-			CreatedAt: r.CreatedAt,
+			Surname:           r.Surname,
+			Name:              r.Name,
+			Document:          r.Document,
+			CreatedAt:         r.CreatedAt,
+			Email:             r.Email,
+			Phones:            r.Phones,
+			BirthDate:         r.BirthDate,
+			Address:           r.Address,
+			Guardian:          r.Guardian,
+			EmergencyContact:  r.EmergencyContact,
+			PreferredLanguage: r.PreferredLanguage,
+			Consent:           r.Consent,
 		}
 		response, err := h.s.Update(id, update)
 		if err != nil {
@@ -177,10 +183,10 @@ func (h *patientHandler) Delete() gin.HandlerFunc {
 	}
 }
 
-// isEmptyPatient valida se os campos não estão vazios
-func isEmptyPatient(patient *domain.Patient) (bool, error) {
-	if patient.Surname == "" || patient.Name == "" || patient.CreatedAt == "" || patient.Document == "" {
-		return false, errors.New("patient fields can't be empty")
+// patientErrorStatus traduz os erros do serviço de pacientes para o status HTTP correspondente
+func patientErrorStatus(err error, fallback int) int {
+	if patient.IsValidationError(err) {
+		return http.StatusBadRequest
 	}
-	return true, nil
+	return fallback
 }
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type procedureHandler struct {
+	s procedure.Service
+}
+
+// NewProcedureHandler cria um novo controller do catálogo de procedimentos
+func NewProcedureHandler(s procedure.Service) *procedureHandler {
+	return &procedureHandler{
+		s: s,
+	}
+}
+
+// GetAll retorna todos os procedimentos do catálogo
+func (h *procedureHandler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.GetAll()
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetByCode retorna um procedimento do catálogo pelo código
+func (h *procedureHandler) GetByCode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.GetByCode(ctx.Param("code"))
+		if err != nil {
+			web.BadResponse(ctx, procedureErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Post insere um novo procedimento no catálogo
+func (h *procedureHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var p domain.Procedure
+		if err := ctx.ShouldBindJSON(&p); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid procedure data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.Create(p)
+		if err != nil {
+			web.BadResponse(ctx, procedureErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// procedureUpdateRequest são os campos do procedimento que podem ser alterados
+type procedureUpdateRequest struct {
+	Name     string  `json:"name"`
+	Duration int     `json:"duration" openapi:"minimum=0"`
+	Price    float64 `json:"price" openapi:"minimum=0"`
+}
+
+// Put atualiza um procedimento do catálogo
+func (h *procedureHandler) Put() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var r procedureUpdateRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid procedure data")
+			return
+		}
+		response, err := h.s.Update(ctx.Param("code"), domain.Procedure{Name: r.Name, Duration: r.Duration, Price: r.Price})
+		if err != nil {
+			web.BadResponse(ctx, procedureErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Delete exclui um procedimento do catálogo
+func (h *procedureHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := h.s.Delete(ctx.Param("code")); err != nil {
+			web.BadResponse(ctx, procedureErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.DeleteResponse(ctx, http.StatusOK, "procedure deleted")
+	}
+}
+
+// procedureErrorStatus traduz os erros do catálogo de procedimentos para o status HTTP correspondente
+func procedureErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, procedure.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, procedure.ErrCodeExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
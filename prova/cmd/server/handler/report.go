@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/report"
+	"github.com/meirafa/prova2-golang/pkg/spreadsheet"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type reportHandler struct {
+	s report.Service
+}
+
+// NewReportHandler cria um novo controller dos relatórios gerenciais
+func NewReportHandler(s report.Service) *reportHandler {
+	return &reportHandler{
+		s: s,
+	}
+}
+
+// Utilization retorna as horas agendadas e disponíveis por dentista, por dia ou semana
+func (h *reportHandler) Utilization() gin.HandlerFunc {
+	return h.handle("utilization", func(f domain.ReportFilter) (interface{}, error) { return h.s.Utilization(f) })
+}
+
+// NoShows retorna a taxa de faltas por dentista e período
+func (h *reportHandler) NoShows() gin.HandlerFunc {
+	return h.handle("no-shows", func(f domain.ReportFilter) (interface{}, error) { return h.s.NoShows(f) })
+}
+
+// NewPatients retorna a quantidade de pacientes cadastrados por período
+func (h *reportHandler) NewPatients() gin.HandlerFunc {
+	return h.handle("new-patients", func(f domain.ReportFilter) (interface{}, error) { return h.s.NewPatients(f) })
+}
+
+// AppointmentsByDentist retorna o total de consultas de cada dentista por situação
+func (h *reportHandler) AppointmentsByDentist() gin.HandlerFunc {
+	return h.handle("appointments-by-dentist", func(f domain.ReportFilter) (interface{}, error) { return h.s.AppointmentsByDentist(f) })
+}
+
+// handle lê o intervalo (from e to, o mês atual por padrão), o agrupamento (period) e o formato
+// (json ou csv) e responde com o relatório
+func (h *reportHandler) handle(name string, run func(domain.ReportFilter) (interface{}, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		format := ctx.DefaultQuery("format", "json")
+		if format != "json" && format != spreadsheet.FormatCSV {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid report format, use json or csv")
+			return
+		}
+		now := time.Now()
+		filter := domain.ReportFilter{
+			From:   time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+			Period: ctx.Query("period"),
+		}
+		filter.To = filter.From.AddDate(0, 1, 0)
+		if value := ctx.Query("from"); value != "" {
+			from, err := parseExportDate(value, false)
+			if err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid from date")
+				return
+			}
+			filter.From = from
+		}
+		if value := ctx.Query("to"); value != "" {
+			to, err := parseExportDate(value, true)
+			if err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid to date")
+				return
+			}
+			filter.To = to
+		}
+
+		rows, err := run(filter)
+		if err != nil {
+			web.BadResponse(ctx, reportErrorStatus(err), "error", err.Error())
+			return
+		}
+		if format == "json" {
+			web.ResponseOK(ctx, http.StatusOK, rows)
+			return
+		}
+
+		header, records, ok := report.Table(rows)
+		if !ok {
+			web.BadResponse(ctx, http.StatusInternalServerError, "error", "report can't be written as csv")
+			return
+		}
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.csv", name, filter.From.Format("20060102"))))
+		ctx.Status(http.StatusOK)
+		w, _ := spreadsheet.NewWriter(ctx.Writer, spreadsheet.FormatCSV)
+		w.Write(header)
+		for _, record := range records {
+			w.Write(record)
+		}
+		w.Close()
+	}
+}
+
+// reportErrorStatus traduz os erros do serviço de relatórios para o status HTTP correspondente
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, report.ErrInvalidRange), errors.Is(err, report.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
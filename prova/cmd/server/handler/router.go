@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/selfservice"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+)
+
+// Handlers reúne os controllers registrados por NewRouter e o Signer que valida os tokens de acesso
+type Handlers struct {
+	Signer      *auth.Signer
+	Appointment *appointmentHandler
+	Dentist     *dentistHandler
+	Patient     *patientHandler
+	User        *userHandler
+	Note        *noteHandler
+	Chart       *chartHandler
+	Treatment   *treatmentHandler
+	Procedure   *procedureHandler
+	Invoice     *invoiceHandler
+	Insurance   *insuranceHandler
+	SelfService *selfServiceHandler
+	Calendar    *calendarHandler
+	CalDAV      *caldavHandler
+	Import      *importHandler
+	Export      *exportHandler
+	Report      *reportHandler
+	Webhook     *webhookHandler
+	Board       *boardHandler
+	GraphQL     *graphQLHandler
+}
+
+// NewRouter registra todas as rotas do servidor. As requisições são conferidas com o documento antes
+// de chegar aos handlers e, com validation.Responses, as respostas também.
+func NewRouter(h Handlers, doc *openapi.Document, validation openapi.Options) *gin.Engine {
+	authenticated := auth.Middleware(h.Signer)
+	clinicalStaff := auth.RequireRole(domain.RoleAdmin, domain.RoleDentist)
+	openAPIHandler := NewOpenAPIHandler(doc)
+
+	r := gin.Default()
+	r.Use(openapi.Middleware(openapi.NewValidator(doc, Formats), validation))
+
+	r.GET("/ping", func(c *gin.Context) { c.String(200, "pong") })
+
+	public := r.Group(selfservice.PublicPath)
+	{
+		public.GET(domain.LinkConfirm, h.SelfService.Preview(domain.LinkConfirm))
+		public.POST(domain.LinkConfirm, h.SelfService.Use(domain.LinkConfirm))
+		public.GET(domain.LinkCancel, h.SelfService.Preview(domain.LinkCancel))
+		public.POST(domain.LinkCancel, h.SelfService.Use(domain.LinkCancel))
+	}
+
+	// Clientes de calendário descobrem o servidor por /.well-known/caldav (RFC 6764)
+	wellKnown := func(ctx *gin.Context) { ctx.Redirect(http.StatusMovedPermanently, CalDAVPath) }
+	r.GET("/.well-known/caldav", wellKnown)
+	r.Handle("PROPFIND", "/.well-known/caldav", wellKnown)
+
+	dav := r.Group(CalDAVPath, h.CalDAV.Authenticate())
+	{
+		dav.OPTIONS("", h.CalDAV.Options())
+		dav.Handle("PROPFIND", "", h.CalDAV.PropfindRoot())
+		dav.Handle("PROPFIND", "principals/:username/", h.CalDAV.PropfindPrincipal())
+		dav.Handle("PROPFIND", "dentists/:id/", h.CalDAV.PropfindHome())
+
+		dav.OPTIONS("dentists/:id/schedule/", h.CalDAV.Options())
+		dav.Handle("PROPFIND", "dentists/:id/schedule/", h.CalDAV.PropfindCollection())
+		dav.Handle("REPORT", "dentists/:id/schedule/", h.CalDAV.Report())
+
+		dav.OPTIONS("dentists/:id/schedule/:name", h.CalDAV.Options())
+		dav.Handle("PROPFIND", "dentists/:id/schedule/:name", h.CalDAV.PropfindResource())
+		dav.GET("dentists/:id/schedule/:name", h.CalDAV.Get())
+		dav.HEAD("dentists/:id/schedule/:name", h.CalDAV.Get())
+		dav.PUT("dentists/:id/schedule/:name", h.CalDAV.Put())
+		dav.DELETE("dentists/:id/schedule/:name", h.CalDAV.Delete())
+	}
+
+	api := r.Group("/api/")
+	{
+		api.POST("/login", h.User.Login())
+		api.POST("/users", authenticated, auth.RequireRole(domain.RoleAdmin), h.User.Post())
+
+		imports := api.Group("/import", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception))
+		{
+			imports.POST("patients", h.Import.Patients())
+			imports.POST("dentists", h.Import.Dentists())
+		}
+
+		exports := api.Group("/export", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception))
+		{
+			exports.GET("appointments", h.Export.Appointments())
+			exports.GET("patients", h.Export.Patients())
+			exports.GET("dentists", h.Export.Dentists())
+		}
+
+		reports := api.Group("/reports", authenticated, auth.RequireRole(domain.RoleAdmin))
+		{
+			reports.GET("utilization", h.Report.Utilization())
+			reports.GET("no-shows", h.Report.NoShows())
+			reports.GET("new-patients", h.Report.NewPatients())
+			reports.GET("appointments-by-dentist", h.Report.AppointmentsByDentist())
+		}
+
+		appointments := api.Group("/appointments")
+		{
+			appointments.GET("", h.Appointment.GetAll())
+			appointments.GET("stream", authenticated, h.Board.Stream())
+			appointments.GET("stream/ws", auth.WebSocketMiddleware(h.Signer), h.Board.WebSocket())
+			appointments.GET(":id", h.Appointment.GetByID())
+			appointments.GET("/patient/:document", h.Appointment.GetByDocumentPatient())
+
+			appointments.POST("", h.Appointment.Post())
+			appointments.PUT(":id", h.Appointment.Put())
+			appointments.PATCH(":id", h.Appointment.Patch())
+			appointments.DELETE(":id", h.Appointment.Delete())
+
+			appointments.POST(":id/links", authenticated, h.SelfService.Issue())
+
+			appointments.GET(":id/notes", authenticated, h.Note.GetByAppointment())
+			appointments.POST(":id/notes", authenticated, h.Note.Post())
+			appointments.PATCH(":id/notes/:noteId", authenticated, h.Note.Patch())
+			appointments.POST(":id/notes/:noteId/sign", authenticated, h.Note.Sign())
+			appointments.POST(":id/notes/:noteId/addenda", authenticated, h.Note.PostAddendum())
+		}
+		dentists := api.Group("/dentists")
+		{
+			dentists.GET("", h.Dentist.GetAll())
+			dentists.GET(":id", h.Dentist.GetByID())
+
+			dentists.POST("", h.Dentist.Post())
+			dentists.PUT(":id", h.Dentist.Put())
+			dentists.PATCH(":id", h.Dentist.Patch())
+			dentists.DELETE(":id", h.Dentist.Delete())
+
+			dentists.GET(":id/calendar.ics", h.Calendar.Feed(domain.FeedDentist))
+			dentists.POST(":id/calendar-feed", authenticated, clinicalStaff, h.Calendar.Issue(domain.FeedDentist))
+		}
+		patients := api.Group("/patients")
+		{
+			patients.GET("", h.Patient.GetAll())
+			patients.GET(":id", h.Patient.GetByID())
+
+			patients.POST("", h.Patient.Post())
+			patients.PUT(":id", h.Patient.Put())
+			patients.PATCH(":id", h.Patient.Patch())
+			patients.DELETE(":id", h.Patient.Delete())
+
+			patients.GET(":id/calendar.ics", h.Calendar.Feed(domain.FeedPatient))
+			patients.POST(":id/calendar-feed", authenticated, clinicalStaff, h.Calendar.Issue(domain.FeedPatient))
+
+			patients.GET(":id/notes", authenticated, h.Note.GetByPatient())
+
+			patients.GET(":id/chart", authenticated, clinicalStaff, h.Chart.Get())
+			patients.PATCH(":id/chart", authenticated, h.Chart.Patch())
+			patients.GET(":id/chart/history", authenticated, clinicalStaff, h.Chart.History())
+
+			patients.GET(":id/treatment-plans", authenticated, clinicalStaff, h.Treatment.GetByPatient())
+			patients.POST(":id/treatment-plans", authenticated, clinicalStaff, h.Treatment.Post())
+
+			patients.GET(":id/balance", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception), h.Invoice.Balance())
+
+			patients.GET(":id/memberships", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception), h.Insurance.GetMemberships())
+			patients.POST(":id/memberships", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception), h.Insurance.PostMembership())
+			patients.DELETE(":id/memberships/:membershipId", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception), h.Insurance.DeleteMembership())
+			patients.GET(":id/pre-authorizations", authenticated, h.Insurance.GetPreAuthorizations())
+			patients.POST(":id/pre-authorizations", authenticated, h.Insurance.PostPreAuthorization())
+		}
+		treatmentPlans := api.Group("/treatment-plans", authenticated, clinicalStaff)
+		{
+			treatmentPlans.GET(":id", h.Treatment.GetByID())
+			treatmentPlans.GET(":id/progress", h.Treatment.Progress())
+
+			treatmentPlans.PUT(":id", h.Treatment.Put())
+			treatmentPlans.DELETE(":id", h.Treatment.Delete())
+			treatmentPlans.POST(":id/accept", h.Treatment.Accept())
+			treatmentPlans.POST(":id/cancel", h.Treatment.Cancel())
+			treatmentPlans.PATCH(":id/items/:itemId", h.Treatment.ScheduleItem())
+		}
+		procedures := api.Group("/procedures", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception))
+		{
+			procedures.GET("", h.Procedure.GetAll())
+			procedures.GET(":code", h.Procedure.GetByCode())
+
+			procedures.POST("", h.Procedure.Post())
+			procedures.PUT(":code", h.Procedure.Put())
+			procedures.DELETE(":code", h.Procedure.Delete())
+		}
+		invoices := api.Group("/invoices", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception))
+		{
+			invoices.GET("", h.Invoice.GetAll())
+			invoices.GET(":id", h.Invoice.GetByID())
+
+			invoices.POST("", h.Invoice.Post())
+			invoices.POST(":id/payments", h.Invoice.PostPayment())
+			invoices.POST(":id/cancel", h.Invoice.Cancel())
+		}
+		webhooks := api.Group("/webhooks", authenticated, auth.RequireRole(domain.RoleAdmin))
+		{
+			webhooks.GET("", h.Webhook.GetAll())
+			webhooks.GET(":id", h.Webhook.GetByID())
+			webhooks.GET(":id/deliveries", h.Webhook.Deliveries())
+
+			webhooks.POST("", h.Webhook.Post())
+			webhooks.PATCH(":id", h.Webhook.Patch())
+			webhooks.DELETE(":id", h.Webhook.Delete())
+		}
+		webhookDeliveries := api.Group("/webhook-deliveries", authenticated, auth.RequireRole(domain.RoleAdmin))
+		{
+			webhookDeliveries.GET("dead", h.Webhook.DeadLetters())
+			webhookDeliveries.POST(":id/retry", h.Webhook.Retry())
+		}
+		insurancePlans := api.Group("/insurance-plans", authenticated, auth.RequireRole(domain.RoleAdmin, domain.RoleReception))
+		{
+			insurancePlans.GET("", h.Insurance.GetPlans())
+			insurancePlans.GET(":id", h.Insurance.GetPlan())
+
+			insurancePlans.POST("", h.Insurance.PostPlan())
+			insurancePlans.PUT(":id", h.Insurance.PutPlan())
+		}
+		preAuthorizations := api.Group("/pre-authorizations", authenticated)
+		{
+			preAuthorizations.GET(":id", h.Insurance.GetPreAuthorization())
+			preAuthorizations.PATCH(":id", clinicalStaff, h.Insurance.Decide())
+		}
+	}
+
+	// 	GRAPHQL
+	// Uma consulta GraphQL pode ler muitos cadastros de uma vez, por isso exige autenticação
+	r.POST(GraphQLPath, authenticated, h.GraphQL.Query())
+	r.GET(GraphQLPath, authenticated, h.GraphQL.Query())
+
+	// 	DOCUMENTATION
+	r.GET(OpenAPIPath, openAPIHandler.Spec())
+	r.GET(DocsPath, openAPIHandler.Docs())
+
+	return r
+}
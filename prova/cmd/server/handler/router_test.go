@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testHandlers monta todos os controllers sem serviços reais, para registrar as rotas nos testes
+func testHandlers() Handlers {
+	return Handlers{
+		Appointment: NewAppointmentHandler(nil),
+		Dentist:     NewDentistHandler(nil),
+		Patient:     NewPatientHandler(nil),
+		User:        NewUserHandler(nil),
+		Note:        NewNoteHandler(nil),
+		Chart:       NewChartHandler(nil),
+		Treatment:   NewTreatmentHandler(nil),
+		Procedure:   NewProcedureHandler(nil),
+		Invoice:     NewInvoiceHandler(nil),
+		Insurance:   NewInsuranceHandler(nil),
+		SelfService: NewSelfServiceHandler(nil),
+		Calendar:    NewCalendarHandler(nil),
+		CalDAV:      NewCalDAVHandler(nil, nil),
+		Import:      NewImportHandler(nil),
+		Export:      NewExportHandler(nil),
+		Report:      NewReportHandler(nil),
+		Webhook:     NewWebhookHandler(nil),
+		Board:       NewBoardHandler(nil),
+		GraphQL:     NewGraphQLHandler(nil),
+	}
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	doc := openapi.Build(APIInfo, APITags, Routes())
+	r := NewRouter(testHandlers(), doc, openapi.Options{})
+	if missing := Undocumented(doc, r.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI catalog: %v", missing)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/selfservice"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type selfServiceHandler struct {
+	s selfservice.Service
+}
+
+// NewSelfServiceHandler cria um novo controller dos links de confirmação e cancelamento de consultas
+func NewSelfServiceHandler(s selfservice.Service) *selfServiceHandler {
+	return &selfServiceHandler{
+		s: s,
+	}
+}
+
+// Issue emite os links de confirmação e cancelamento de uma consulta
+func (h *selfServiceHandler) Issue() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Issue(id)
+		if err != nil {
+			web.BadResponse(ctx, selfServiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// Preview mostra ao paciente a consulta e a ação do link, sem usá-lo. Abrir o link não altera
+// a consulta, pois leitores de e-mail costumam acessar os links das mensagens automaticamente.
+func (h *selfServiceHandler) Preview(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.Preview(ctx.Query("token"), action)
+		if err != nil {
+			web.BadResponse(ctx, selfServiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Use aplica a ação do link à consulta
+func (h *selfServiceHandler) Use(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.Use(ctx.Query("token"), action, ctx.ClientIP(), ctx.Request.UserAgent())
+		if err != nil {
+			web.BadResponse(ctx, selfServiceErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// selfServiceErrorStatus traduz os erros do serviço de links para o status HTTP correspondente
+func selfServiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, selfservice.ErrInvalidLink):
+		return http.StatusNotFound
+	case errors.Is(err, selfservice.ErrExpiredLink), errors.Is(err, selfservice.ErrUsedLink):
+		return http.StatusGone
+	case errors.Is(err, selfservice.ErrNotAllowed), errors.Is(err, appointment.ErrVersionMismatch):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/treatment"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type treatmentHandler struct {
+	s treatment.Service
+}
+
+// NewTreatmentHandler cria um novo controller de planos de tratamento
+func NewTreatmentHandler(s treatment.Service) *treatmentHandler {
+	return &treatmentHandler{
+		s: s,
+	}
+}
+
+// GetByID retorna um plano de tratamento por id
+func (h *treatmentHandler) GetByID() gin.HandlerFunc {
+	return h.byID(func(id int) (domain.TreatmentPlan, error) {
+		return h.s.GetByID(id)
+	})
+}
+
+// GetByPatient retorna os planos de tratamento de um paciente
+func (h *treatmentHandler) GetByPatient() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetByPatient(id)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Post cria um novo plano de tratamento para o paciente
+func (h *treatmentHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var plan domain.TreatmentPlan
+		if err := ctx.ShouldBindJSON(&plan); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid treatment plan data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.Create(id, plan)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// Put substitui os dados de um plano em rascunho
+func (h *treatmentHandler) Put() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var plan domain.TreatmentPlan
+		if err := ctx.ShouldBindJSON(&plan); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid treatment plan data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.Update(id, plan)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Delete exclui um plano de tratamento
+func (h *treatmentHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.DeleteResponse(ctx, http.StatusOK, "treatment plan deleted")
+	}
+}
+
+// Accept registra o aceite do plano pelo paciente
+func (h *treatmentHandler) Accept() gin.HandlerFunc {
+	return h.byID(func(id int) (domain.TreatmentPlan, error) {
+		return h.s.Accept(id)
+	})
+}
+
+// Cancel cancela um plano de tratamento
+func (h *treatmentHandler) Cancel() gin.HandlerFunc {
+	return h.byID(func(id int) (domain.TreatmentPlan, error) {
+		return h.s.Cancel(id)
+	})
+}
+
+// scheduleItemRequest associa um item do plano de tratamento a uma consulta
+type scheduleItemRequest struct {
+	IdAppointment int `json:"id_appointment" binding:"required"`
+}
+
+// ScheduleItem vincula um procedimento do plano a uma consulta
+func (h *treatmentHandler) ScheduleItem() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		itemID, err := strconv.Atoi(ctx.Param("itemId"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid item id provided")
+			return
+		}
+		var r scheduleItemRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid request")
+			return
+		}
+		response, err := h.s.ScheduleItem(id, itemID, r.IdAppointment)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Progress retorna o resumo do andamento do plano
+func (h *treatmentHandler) Progress() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Progress(id)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// byID cria um handler que executa uma operação do serviço sobre o plano da rota
+func (h *treatmentHandler) byID(operation func(id int) (domain.TreatmentPlan, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := operation(id)
+		if err != nil {
+			web.BadResponse(ctx, treatmentErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// treatmentErrorStatus traduz os erros do serviço de planos de tratamento para o status HTTP correspondente
+func treatmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, treatment.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, treatment.ErrInvalidPlan):
+		return http.StatusBadRequest
+	case errors.Is(err, treatment.ErrNotEditable), errors.Is(err, treatment.ErrNotAccepted), errors.Is(err, treatment.ErrInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
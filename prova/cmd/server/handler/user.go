@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/user"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type userHandler struct {
+	s user.Service
+}
+
+// NewUserHandler cria um novo controller de usuários
+func NewUserHandler(s user.Service) *userHandler {
+	return &userHandler{
+		s: s,
+	}
+}
+
+// loginRequest são as credenciais do login
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// loginResponse traz o token de acesso emitido no login
+type loginResponse struct {
+	Token string `json:"token"`
+}
+
+// Login autentica o usuário e retorna um token de acesso
+func (h *userHandler) Login() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var r loginRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid credentials data")
+			return
+		}
+		token, err := h.s.Login(r.Username, r.Password)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, user.ErrInvalidCredentials) {
+				status = http.StatusUnauthorized
+			}
+			web.BadResponse(ctx, status, "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, loginResponse{Token: token})
+	}
+}
+
+// userRequest é o usuário a ser criado, com a senha inicial
+type userRequest struct {
+	domain.User
+	Password string `json:"password" binding:"required"`
+}
+
+// Post cadastra um novo usuário
+func (h *userHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var r userRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid user data")
+			return
+		}
+		response, err := h.s.Create(r.User, r.Password)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, user.ErrUsernameExists) {
+				status = http.StatusConflict
+			}
+			web.BadResponse(ctx, status, "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/webhook"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type webhookHandler struct {
+	s webhook.Service
+}
+
+// NewWebhookHandler cria um novo controller de assinaturas de webhooks
+func NewWebhookHandler(s webhook.Service) *webhookHandler {
+	return &webhookHandler{
+		s: s,
+	}
+}
+
+// GetAll retorna todas as assinaturas
+func (h *webhookHandler) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.GetAll()
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// GetByID retorna uma assinatura por id
+func (h *webhookHandler) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.GetByID(id)
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Post cadastra uma nova assinatura
+func (h *webhookHandler) Post() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var subscription domain.WebhookSubscription
+		if err := ctx.ShouldBindJSON(&subscription); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid webhook data, please verify field(s): "+err.Error())
+			return
+		}
+		response, err := h.s.Create(subscription)
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusCreated, response)
+	}
+}
+
+// webhookPatchRequest são os campos da assinatura que podem ser alterados parcialmente
+type webhookPatchRequest struct {
+	URL    string   `json:"url,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// Patch atualiza os campos informados de uma assinatura
+func (h *webhookHandler) Patch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		var r webhookPatchRequest
+		if err := ctx.ShouldBindJSON(&r); err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid request")
+			return
+		}
+		response, err := h.s.Update(id, domain.WebhookSubscription{
+			URL:    r.URL,
+			Secret: r.Secret,
+			Events: r.Events,
+			Active: r.Active,
+		})
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Delete exclui uma assinatura
+func (h *webhookHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.DeleteResponse(ctx, http.StatusOK, "webhook deleted")
+	}
+}
+
+// Deliveries retorna o histórico de entregas de uma assinatura, opcionalmente filtrado por ?status=
+func (h *webhookHandler) Deliveries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Deliveries(id, ctx.Query("status"))
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// DeadLetters retorna as entregas que esgotaram as tentativas
+func (h *webhookHandler) DeadLetters() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := h.s.DeadLetters()
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// Retry reagenda uma entrega da lista de falhas
+func (h *webhookHandler) Retry() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.BadResponse(ctx, http.StatusBadRequest, "error", "invalid id provided")
+			return
+		}
+		response, err := h.s.Retry(id)
+		if err != nil {
+			web.BadResponse(ctx, webhookErrorStatus(err), "error", err.Error())
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, response)
+	}
+}
+
+// webhookErrorStatus traduz os erros do serviço de webhooks para o status HTTP correspondente
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, webhook.ErrInvalidSubscription):
+		return http.StatusBadRequest
+	case errors.Is(err, webhook.ErrNotDead):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,319 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/pkg/clinicpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WatchBuffer é quantos eventos podem aguardar envio em cada WatchAppointments antes que o
+// stream seja encerrado por lentidão do cliente
+var WatchBuffer = 256
+
+// errVersionRequired recusa as alterações sem a versão lida, que a API REST exige em If-Match
+var errVersionRequired = status.Error(codes.FailedPrecondition, "version is required")
+
+type appointmentServer struct {
+	clinicpb.UnimplementedAppointmentServiceServer
+	s   appointment.Service
+	bus event.Bus
+}
+
+func (srv *appointmentServer) ListAppointments(ctx context.Context, _ *clinicpb.ListAppointmentsRequest) (*clinicpb.ListAppointmentsResponse, error) {
+	appointments, err := srv.s.GetAll()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &clinicpb.ListAppointmentsResponse{Appointments: toAppointments(appointments)}, nil
+}
+
+func (srv *appointmentServer) GetAppointment(ctx context.Context, req *clinicpb.GetAppointmentRequest) (*clinicpb.Appointment, error) {
+	found, err := srv.s.GetByID(int(req.Id))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toAppointment(found), nil
+}
+
+func (srv *appointmentServer) ListAppointmentsByPatient(ctx context.Context, req *clinicpb.ListAppointmentsByPatientRequest) (*clinicpb.ListAppointmentsResponse, error) {
+	appointments, err := srv.s.GetByDocumentPatient(req.Document)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &clinicpb.ListAppointmentsResponse{Appointments: toAppointments(appointments)}, nil
+}
+
+func (srv *appointmentServer) ListAppointmentsByDentist(ctx context.Context, req *clinicpb.ListAppointmentsByDentistRequest) (*clinicpb.ListAppointmentsResponse, error) {
+	appointments, err := srv.s.GetByDentistRegistration(req.Registration)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &clinicpb.ListAppointmentsResponse{Appointments: toAppointments(appointments)}, nil
+}
+
+func (srv *appointmentServer) ListAppointmentsBetween(ctx context.Context, req *clinicpb.ListAppointmentsBetweenRequest) (*clinicpb.ListAppointmentsResponse, error) {
+	if req.Start == nil || req.End == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end are required")
+	}
+	start, end := req.Start.AsTime(), req.End.AsTime()
+	if !end.After(start) {
+		return nil, status.Error(codes.InvalidArgument, "end must be after start")
+	}
+	appointments, err := srv.s.GetByInterval(start, end)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	list := make([]*clinicpb.Appointment, len(appointments))
+	for i, a := range appointments {
+		list[i] = toAppointment(domain.AppointmentDTO{Appointment: a})
+	}
+	return &clinicpb.ListAppointmentsResponse{Appointments: list}, nil
+}
+
+func (srv *appointmentServer) CreateAppointment(ctx context.Context, req *clinicpb.CreateAppointmentRequest) (*clinicpb.Appointment, error) {
+	if req.Appointment == nil {
+		return nil, status.Error(codes.InvalidArgument, "appointment is required")
+	}
+	created, err := srv.s.Create(fromAppointment(req.Appointment))
+	if err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.InvalidArgument)
+	}
+	return toAppointment(created), nil
+}
+
+func (srv *appointmentServer) UpdateAppointment(ctx context.Context, req *clinicpb.UpdateAppointmentRequest) (*clinicpb.Appointment, error) {
+	if req.Appointment == nil {
+		return nil, status.Error(codes.InvalidArgument, "appointment is required")
+	}
+	if req.Appointment.Version == 0 {
+		return nil, errVersionRequired
+	}
+	if _, err := srv.s.GetByID(int(req.Id)); err != nil {
+		return nil, status.Error(codes.NotFound, "appointment not found")
+	}
+	updated, err := srv.s.Update(int(req.Id), fromAppointment(req.Appointment))
+	if err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.NotFound)
+	}
+	return toAppointment(updated), nil
+}
+
+func (srv *appointmentServer) DeleteAppointment(ctx context.Context, req *clinicpb.DeleteAppointmentRequest) (*emptypb.Empty, error) {
+	if req.Version == 0 {
+		return nil, errVersionRequired
+	}
+	if err := srv.s.Delete(int(req.Id), int(req.Version)); err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (srv *appointmentServer) ListScheduleBlocks(ctx context.Context, req *clinicpb.ListScheduleBlocksRequest) (*clinicpb.ListScheduleBlocksResponse, error) {
+	blocks, err := srv.s.GetBlocks(req.Registration)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	list := make([]*clinicpb.ScheduleBlock, len(blocks))
+	for i, b := range blocks {
+		list[i] = toScheduleBlock(b)
+	}
+	return &clinicpb.ListScheduleBlocksResponse{Blocks: list}, nil
+}
+
+func (srv *appointmentServer) GetScheduleBlock(ctx context.Context, req *clinicpb.GetScheduleBlockRequest) (*clinicpb.ScheduleBlock, error) {
+	block, err := srv.s.GetBlock(req.Registration, req.Href)
+	if err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.Internal)
+	}
+	return toScheduleBlock(block), nil
+}
+
+func (srv *appointmentServer) SaveScheduleBlock(ctx context.Context, req *clinicpb.ScheduleBlock) (*clinicpb.ScheduleBlock, error) {
+	saved, err := srv.s.SaveBlock(domain.ScheduleBlock{
+		IdDentist: req.IdDentist,
+		UID:       req.Uid,
+		Href:      req.Href,
+		Start:     req.Start,
+		End:       req.End,
+		Summary:   req.Summary,
+	})
+	if err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.Internal)
+	}
+	return toScheduleBlock(saved), nil
+}
+
+func (srv *appointmentServer) DeleteScheduleBlock(ctx context.Context, req *clinicpb.DeleteScheduleBlockRequest) (*emptypb.Empty, error) {
+	if err := srv.s.DeleteBlock(req.Registration, req.Href); err != nil {
+		return nil, errorStatus(err, appointmentCode, codes.Internal)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchAppointments repassa os eventos de consultas publicados no bus. Os cabeçalhos são enviados
+// logo após a inscrição, de modo que o cliente que os recebe já tem a garantia de ver os eventos
+// publicados a partir dali.
+func (srv *appointmentServer) WatchAppointments(req *clinicpb.WatchAppointmentsRequest, stream clinicpb.AppointmentService_WatchAppointmentsServer) error {
+	events := make(chan domain.Event, WatchBuffer)
+	overflow := make(chan struct{})
+	var once sync.Once
+	unsubscribe := srv.bus.Subscribe(func(e domain.Event) {
+		if !isAppointmentEvent(e.Type) {
+			return
+		}
+		// o bus entrega os eventos em sequência, então um cliente lento não pode segurá-lo
+		select {
+		case events <- e:
+		default:
+			once.Do(func() { close(overflow) })
+		}
+	})
+	defer unsubscribe()
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-overflow:
+			return status.Error(codes.ResourceExhausted, "client is too slow, events were dropped")
+		case e := <-events:
+			message, err := toAppointmentEvent(e)
+			if err != nil {
+				log.Printf("grpc: can't decode event %s: %v", e.Id, err)
+				continue
+			}
+			if a := message.Appointment; a != nil {
+				if (req.Dentist != "" && a.IdDentist != req.Dentist) || (req.Patient != "" && a.IdPatient != req.Patient) {
+					continue
+				}
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func isAppointmentEvent(eventType string) bool {
+	switch eventType {
+	case event.AppointmentCreated, event.AppointmentUpdated, event.AppointmentCancelled, event.AppointmentDeleted:
+		return true
+	}
+	return false
+}
+
+// toAppointmentEvent converte o evento do outbox, cujo conteúdo chega como JSON
+func toAppointmentEvent(e domain.Event) (*clinicpb.AppointmentEvent, error) {
+	message := &clinicpb.AppointmentEvent{Id: e.Id, Type: e.Type}
+	if occurredAt, err := time.Parse(time.RFC3339, e.OccurredAt); err == nil {
+		message.OccurredAt = timestamppb.New(occurredAt)
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	if e.Type == event.AppointmentDeleted {
+		var deleted event.Deleted
+		if err := json.Unmarshal(data, &deleted); err != nil {
+			return nil, err
+		}
+		message.DeletedId = int64(deleted.Id)
+		return message, nil
+	}
+	var a domain.AppointmentDTO
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	message.Appointment = toAppointment(a)
+	return message, nil
+}
+
+// appointmentCode traduz os erros do serviço de consultas, como appointmentErrorStatus no handler REST
+func appointmentCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, appointment.ErrInvalidStatus),
+		errors.Is(err, appointment.ErrInvalidDate),
+		errors.Is(err, appointment.ErrInvalidProcedure):
+		return codes.InvalidArgument
+	case errors.Is(err, appointment.ErrUnavailable):
+		return codes.FailedPrecondition
+	case errors.Is(err, appointment.ErrBlockNotFound):
+		return codes.NotFound
+	case errors.Is(err, appointment.ErrVersionMismatch):
+		return codes.Aborted
+	default:
+		return codes.Unknown
+	}
+}
+
+// toAppointment converte a consulta; o dentista e o paciente só são incluídos quando vieram
+// preenchidos
+func toAppointment(a domain.AppointmentDTO) *clinicpb.Appointment {
+	message := &clinicpb.Appointment{
+		Id:              int64(a.Id),
+		Description:     a.Description,
+		AppointmentDate: a.AppointmentDate,
+		IdDentist:       a.IdDentist,
+		IdPatient:       a.IdPatient,
+		Status:          a.Status,
+		ProcedureCode:   a.ProcedureCode,
+		Duration:        int32(a.Duration),
+		Version:         int64(a.Version),
+	}
+	if a.Dentist.Registration != "" {
+		message.Dentist = toDentist(a.Dentist)
+	}
+	if a.Patient.Document != "" {
+		message.Patient = toPatient(a.Patient)
+	}
+	return message
+}
+
+func toAppointments(appointments []domain.AppointmentDTO) []*clinicpb.Appointment {
+	list := make([]*clinicpb.Appointment, len(appointments))
+	for i, a := range appointments {
+		list[i] = toAppointment(a)
+	}
+	return list
+}
+
+func fromAppointment(a *clinicpb.Appointment) domain.Appointment {
+	return domain.Appointment{
+		Description:     a.Description,
+		AppointmentDate: a.AppointmentDate,
+		IdDentist:       a.IdDentist,
+		IdPatient:       a.IdPatient,
+		Status:          a.Status,
+		ProcedureCode:   a.ProcedureCode,
+		Duration:        int(a.Duration),
+		Version:         int(a.Version),
+	}
+}
+
+func toScheduleBlock(b domain.ScheduleBlock) *clinicpb.ScheduleBlock {
+	return &clinicpb.ScheduleBlock{
+		Id:        int64(b.Id),
+		IdDentist: b.IdDentist,
+		Uid:       b.UID,
+		Href:      b.Href,
+		Start:     b.Start,
+		End:       b.End,
+		Summary:   b.Summary,
+	}
+}
@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/clinicpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type dentistServer struct {
+	clinicpb.UnimplementedDentistServiceServer
+	s dentist.Service
+}
+
+func (srv *dentistServer) ListDentists(ctx context.Context, _ *clinicpb.ListDentistsRequest) (*clinicpb.ListDentistsResponse, error) {
+	dentists, err := srv.s.GetAll()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &clinicpb.ListDentistsResponse{Dentists: toDentists(dentists)}, nil
+}
+
+func (srv *dentistServer) GetDentist(ctx context.Context, req *clinicpb.GetDentistRequest) (*clinicpb.Dentist, error) {
+	found, err := srv.getByID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toDentist(found), nil
+}
+
+func (srv *dentistServer) CreateDentist(ctx context.Context, req *clinicpb.CreateDentistRequest) (*clinicpb.Dentist, error) {
+	if req.Dentist == nil {
+		return nil, status.Error(codes.InvalidArgument, "dentist is required")
+	}
+	created, err := srv.s.Create(fromDentist(req.Dentist))
+	if err != nil {
+		return nil, errorStatus(err, dentistCode, codes.InvalidArgument)
+	}
+	return toDentist(created), nil
+}
+
+func (srv *dentistServer) UpdateDentist(ctx context.Context, req *clinicpb.UpdateDentistRequest) (*clinicpb.Dentist, error) {
+	if req.Dentist == nil {
+		return nil, status.Error(codes.InvalidArgument, "dentist is required")
+	}
+	if _, err := srv.getByID(req.Id); err != nil {
+		return nil, err
+	}
+	updated, err := srv.s.Update(int(req.Id), fromDentist(req.Dentist))
+	if err != nil {
+		return nil, errorStatus(err, dentistCode, codes.InvalidArgument)
+	}
+	return toDentist(updated), nil
+}
+
+func (srv *dentistServer) DeleteDentist(ctx context.Context, req *clinicpb.DeleteDentistRequest) (*emptypb.Empty, error) {
+	if err := srv.s.Delete(int(req.Id)); err != nil {
+		return nil, errorStatus(err, noCode, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (srv *dentistServer) BatchDentists(ctx context.Context, req *clinicpb.BatchDentistsRequest) (*clinicpb.ListDentistsResponse, error) {
+	dentists := make([]domain.Dentist, len(req.Dentists))
+	for i, d := range req.Dentists {
+		dentists[i] = fromDentist(d)
+	}
+	saved, err := srv.s.Batch(dentists)
+	if err != nil {
+		return nil, errorStatus(err, dentistCode, codes.InvalidArgument)
+	}
+	return &clinicpb.ListDentistsResponse{Dentists: toDentists(saved)}, nil
+}
+
+func (srv *dentistServer) getByID(id int64) (domain.Dentist, error) {
+	found, err := srv.s.GetByID(int(id))
+	if err != nil {
+		return domain.Dentist{}, status.Error(codes.NotFound, "dentist not found")
+	}
+	return found.(domain.Dentist), nil
+}
+
+// dentistCode traduz os erros do serviço de dentistas, como dentistErrorStatus no handler REST
+func dentistCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, dentist.ErrRegistrationExists):
+		return codes.AlreadyExists
+	case errors.Is(err, domain.ErrInvalidRegistration), errors.Is(err, dentist.ErrMissingFields):
+		return codes.InvalidArgument
+	default:
+		return codes.Unknown
+	}
+}
+
+func toDentist(d domain.Dentist) *clinicpb.Dentist {
+	return &clinicpb.Dentist{
+		Id:           int64(d.Id),
+		Surname:      d.Surname,
+		Name:         d.Name,
+		Registration: d.Registration,
+	}
+}
+
+func toDentists(dentists []domain.Dentist) []*clinicpb.Dentist {
+	list := make([]*clinicpb.Dentist, len(dentists))
+	for i, d := range dentists {
+		list[i] = toDentist(d)
+	}
+	return list
+}
+
+func fromDentist(d *clinicpb.Dentist) domain.Dentist {
+	return domain.Dentist{
+		Id:           int(d.GetId()),
+		Surname:      d.GetSurname(),
+		Name:         d.GetName(),
+		Registration: d.GetRegistration(),
+	}
+}
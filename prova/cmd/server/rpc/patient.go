@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/clinicpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type patientServer struct {
+	clinicpb.UnimplementedPatientServiceServer
+	s patient.Service
+}
+
+func (srv *patientServer) ListPatients(ctx context.Context, _ *clinicpb.ListPatientsRequest) (*clinicpb.ListPatientsResponse, error) {
+	patients, err := srv.s.GetAll()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &clinicpb.ListPatientsResponse{Patients: toPatients(patients)}, nil
+}
+
+func (srv *patientServer) GetPatient(ctx context.Context, req *clinicpb.GetPatientRequest) (*clinicpb.Patient, error) {
+	found, err := srv.s.GetByID(int(req.Id))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "patient not found")
+	}
+	return toPatient(found), nil
+}
+
+func (srv *patientServer) CreatePatient(ctx context.Context, req *clinicpb.CreatePatientRequest) (*clinicpb.Patient, error) {
+	if req.Patient == nil {
+		return nil, status.Error(codes.InvalidArgument, "patient is required")
+	}
+	created, err := srv.s.Create(fromPatient(req.Patient))
+	if err != nil {
+		return nil, errorStatus(err, patientCode, codes.InvalidArgument)
+	}
+	return toPatient(created), nil
+}
+
+func (srv *patientServer) UpdatePatient(ctx context.Context, req *clinicpb.UpdatePatientRequest) (*clinicpb.Patient, error) {
+	if req.Patient == nil {
+		return nil, status.Error(codes.InvalidArgument, "patient is required")
+	}
+	if _, err := srv.s.GetByID(int(req.Id)); err != nil {
+		return nil, status.Error(codes.NotFound, "patient not found")
+	}
+	updated, err := srv.s.Update(int(req.Id), fromPatient(req.Patient))
+	if err != nil {
+		return nil, errorStatus(err, patientCode, codes.AlreadyExists)
+	}
+	return toPatient(updated), nil
+}
+
+func (srv *patientServer) DeletePatient(ctx context.Context, req *clinicpb.DeletePatientRequest) (*emptypb.Empty, error) {
+	if err := srv.s.Delete(int(req.Id)); err != nil {
+		return nil, errorStatus(err, noCode, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (srv *patientServer) BatchPatients(ctx context.Context, req *clinicpb.BatchPatientsRequest) (*clinicpb.ListPatientsResponse, error) {
+	patients := make([]domain.Patient, len(req.Patients))
+	for i, p := range req.Patients {
+		patients[i] = fromPatient(p)
+	}
+	saved, err := srv.s.Batch(patients)
+	if err != nil {
+		return nil, errorStatus(err, patientCode, codes.InvalidArgument)
+	}
+	return &clinicpb.ListPatientsResponse{Patients: toPatients(saved)}, nil
+}
+
+// patientCode traduz os erros do serviço de pacientes, como patientErrorStatus no handler REST
+func patientCode(err error) codes.Code {
+	if patient.IsValidationError(err) {
+		return codes.InvalidArgument
+	}
+	return codes.Unknown
+}
+
+func toPatient(p domain.Patient) *clinicpb.Patient {
+	message := &clinicpb.Patient{
+		Id:                int64(p.Id),
+		Surname:           p.Surname,
+		Name:              p.Name,
+		Document:          p.Document,
+		CreatedAt:         p.CreatedAt,
+		Email:             p.Email,
+		Phones:            p.Phones,
+		BirthDate:         p.BirthDate,
+		Guardian:          toContact(p.Guardian),
+		EmergencyContact:  toContact(p.EmergencyContact),
+		PreferredLanguage: p.PreferredLanguage,
+	}
+	if a := p.Address; a != nil {
+		message.Address = &clinicpb.Address{
+			Street:     a.Street,
+			Number:     a.Number,
+			Complement: a.Complement,
+			District:   a.District,
+			City:       a.City,
+			State:      a.State,
+			ZipCode:    a.ZipCode,
+		}
+	}
+	if c := p.Consent; c != nil {
+		message.Consent = &clinicpb.CommunicationConsent{Email: c.Email, Sms: c.SMS, Whatsapp: c.WhatsApp}
+	}
+	return message
+}
+
+func toPatients(patients []domain.Patient) []*clinicpb.Patient {
+	list := make([]*clinicpb.Patient, len(patients))
+	for i, p := range patients {
+		list[i] = toPatient(p)
+	}
+	return list
+}
+
+func toContact(c *domain.Contact) *clinicpb.Contact {
+	if c == nil {
+		return nil
+	}
+	return &clinicpb.Contact{Name: c.Name, Document: c.Document, Phone: c.Phone, Relationship: c.Relationship}
+}
+
+func fromPatient(p *clinicpb.Patient) domain.Patient {
+	patient := domain.Patient{
+		Id:                int(p.GetId()),
+		Surname:           p.GetSurname(),
+		Name:              p.GetName(),
+		Document:          p.GetDocument(),
+		CreatedAt:         p.GetCreatedAt(),
+		Email:             p.GetEmail(),
+		Phones:            p.GetPhones(),
+		BirthDate:         p.GetBirthDate(),
+		Guardian:          fromContact(p.GetGuardian()),
+		EmergencyContact:  fromContact(p.GetEmergencyContact()),
+		PreferredLanguage: p.GetPreferredLanguage(),
+	}
+	if a := p.GetAddress(); a != nil {
+		patient.Address = &domain.Address{
+			Street:     a.Street,
+			Number:     a.Number,
+			Complement: a.Complement,
+			District:   a.District,
+			City:       a.City,
+			State:      a.State,
+			ZipCode:    a.ZipCode,
+		}
+	}
+	if c := p.GetConsent(); c != nil {
+		patient.Consent = &domain.CommunicationConsent{Email: c.Email, SMS: c.Sms, WhatsApp: c.Whatsapp}
+	}
+	return patient
+}
+
+func fromContact(c *clinicpb.Contact) *domain.Contact {
+	if c == nil {
+		return nil
+	}
+	return &domain.Contact{Name: c.Name, Document: c.Document, Phone: c.Phone, Relationship: c.Relationship}
+}
@@ -0,0 +1,114 @@
+// Package rpc implementa os serviços gRPC de clinicpb sobre os serviços de domínio, para as chamadas
+// entre serviços internos. Os erros dos serviços são traduzidos para os códigos de status do gRPC
+// da mesma forma que os handlers REST os traduzem para os status HTTP. As chamadas exigem um token
+// de acesso de auth.Signer nos metadados, como o cabeçalho Authorization da API REST.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/clinicpb"
+	"github.com/meirafa/prova2-golang/pkg/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer cria o servidor gRPC com os serviços de consultas, dentistas e pacientes. As alterações
+// de consultas publicadas no bus são repassadas a WatchAppointments. Os tokens de acesso são
+// conferidos com signer.
+func NewServer(signer *auth.Signer, appointments appointment.Service, dentists dentist.Service, patients patient.Service, bus event.Bus, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(recoverUnary, tokenUnary(signer)),
+		grpc.ChainStreamInterceptor(recoverStream, tokenStream(signer)))
+	server := grpc.NewServer(opts...)
+	clinicpb.RegisterAppointmentServiceServer(server, &appointmentServer{s: appointments, bus: bus})
+	clinicpb.RegisterDentistServiceServer(server, &dentistServer{s: dentists})
+	clinicpb.RegisterPatientServiceServer(server, &patientServer{s: patients})
+	return server
+}
+
+// recoverUnary responde INTERNAL em vez de derrubar o processo quando um método entra em pânico
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("grpc: panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoverStream faz o mesmo que recoverUnary para os métodos de stream
+func recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("grpc: panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// tokenUnary exige um token de acesso válido no metadado authorization, no formato "Bearer <token>"
+func tokenUnary(signer *auth.Signer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authenticate(ctx, signer); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// tokenStream faz o mesmo que tokenUnary para os métodos de stream
+func tokenStream(signer *auth.Signer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authenticate(ss.Context(), signer); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authenticate confere o token de acesso enviado nos metadados da chamada
+func authenticate(ctx context.Context, signer *auth.Signer) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	if token == values[0] {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if _, err := signer.ParseToken(token); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// errorStatus converte o erro do serviço num status gRPC. Os erros sem código próprio recebem o
+// código informado, que é o equivalente ao status HTTP usado pelo handler REST da mesma operação.
+func errorStatus(err error, code func(error) codes.Code, fallback codes.Code) error {
+	if c := code(err); c != codes.Unknown {
+		return status.Error(c, err.Error())
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(fallback, err.Error())
+}
+
+func noCode(error) codes.Code {
+	return codes.Unknown
+}
@@ -0,0 +1,298 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/clinicpb"
+	"github.com/meirafa/prova2-golang/pkg/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeAppointments guarda as consultas em memória e confere as versões como o serviço real;
+// os métodos não usados pelos testes ficam com a interface nula
+type fakeAppointments struct {
+	appointment.Service
+	mu    sync.Mutex
+	items map[int]domain.AppointmentDTO
+}
+
+func (f *fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.items[id]
+	if !ok {
+		return domain.AppointmentDTO{}, store.ErrNotFound
+	}
+	return a, nil
+}
+
+func (f *fakeAppointments) Create(a domain.Appointment) (domain.AppointmentDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.items {
+		if existing.IdDentist == a.IdDentist && existing.AppointmentDate == a.AppointmentDate {
+			return domain.AppointmentDTO{}, appointment.ErrUnavailable
+		}
+	}
+	a.Id = len(f.items) + 1
+	a.Version = 1
+	f.items[a.Id] = domain.AppointmentDTO{Appointment: a}
+	return f.items[a.Id], nil
+}
+
+func (f *fakeAppointments) Update(id int, a domain.Appointment) (domain.AppointmentDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.items[id]
+	if !ok {
+		return domain.AppointmentDTO{}, store.ErrNotFound
+	}
+	if a.Version != 0 && a.Version != current.Version {
+		return domain.AppointmentDTO{}, appointment.ErrVersionMismatch
+	}
+	a.Id = id
+	a.Version = current.Version + 1
+	f.items[id] = domain.AppointmentDTO{Appointment: a}
+	return f.items[id], nil
+}
+
+func (f *fakeAppointments) Delete(id, version int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.items[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if version != 0 && version != current.Version {
+		return appointment.ErrVersionMismatch
+	}
+	delete(f.items, id)
+	return nil
+}
+
+// fakePatients exclui os pacientes de um mapa e entra em pânico na listagem, para os testes de
+// recuperação
+type fakePatients struct {
+	patient.Service
+	ids map[int]bool
+}
+
+func (f *fakePatients) GetAll() ([]domain.Patient, error) {
+	panic("database is gone")
+}
+
+func (f *fakePatients) Delete(id int) error {
+	if !f.ids[id] {
+		return store.ErrNotFound
+	}
+	delete(f.ids, id)
+	return nil
+}
+
+type testServer struct {
+	signer       *auth.Signer
+	bus          event.Bus
+	appointments *fakeAppointments
+	conn         *grpc.ClientConn
+}
+
+// newTestServer sobe o servidor num bufconn e retorna a conexão do cliente
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := &testServer{
+		signer:       auth.NewSigner("secret"),
+		bus:          event.NewBus(),
+		appointments: &fakeAppointments{items: map[int]domain.AppointmentDTO{}},
+	}
+	var dentists dentist.Service
+	server := NewServer(ts.signer, ts.appointments, dentists, &fakePatients{ids: map[int]bool{1: true}}, ts.bus)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ts.conn = conn
+	return ts
+}
+
+// withToken retorna o contexto com um token de acesso válido nos metadados
+func (ts *testServer) withToken(t *testing.T) context.Context {
+	t.Helper()
+	token, err := ts.signer.IssueToken(auth.Claims{UserID: 1, Username: "agenda", Role: domain.RoleAdmin}, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("code = %s (%v), want %s", got, err, want)
+	}
+}
+
+func TestTokenIsRequired(t *testing.T) {
+	ts := newTestServer(t)
+	client := clinicpb.NewAppointmentServiceClient(ts.conn)
+
+	expired, _ := ts.signer.IssueToken(auth.Claims{Username: "agenda"}, -time.Minute)
+	forged, _ := auth.NewSigner("other").IssueToken(auth.Claims{Username: "agenda"}, time.Minute)
+	tests := []struct {
+		name string
+		md   []string
+	}{
+		{"no metadata", nil},
+		{"no bearer prefix", []string{"authorization", forged}},
+		{"expired token", []string{"authorization", "Bearer " + expired}},
+		{"token from another signer", []string{"authorization", "Bearer " + forged}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tt.md...)
+			_, err := client.GetAppointment(ctx, &clinicpb.GetAppointmentRequest{Id: 1})
+			wantCode(t, err, codes.Unauthenticated)
+
+			stream, err := client.WatchAppointments(ctx, &clinicpb.WatchAppointmentsRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			wantCode(t, err, codes.Unauthenticated)
+		})
+	}
+}
+
+func TestAppointments(t *testing.T) {
+	ts := newTestServer(t)
+	client := clinicpb.NewAppointmentServiceClient(ts.conn)
+	ctx := ts.withToken(t)
+
+	_, err := client.GetAppointment(ctx, &clinicpb.GetAppointmentRequest{Id: 1})
+	wantCode(t, err, codes.NotFound)
+
+	input := &clinicpb.Appointment{Description: "limpeza", AppointmentDate: "2024-03-04 09:00", IdDentist: "CRO-1", IdPatient: "123"}
+	created, err := client.CreateAppointment(ctx, &clinicpb.CreateAppointmentRequest{Appointment: input})
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if created.Id != 1 || created.Version != 1 {
+		t.Errorf("created = %v, want id 1 at version 1", created)
+	}
+	_, err = client.CreateAppointment(ctx, &clinicpb.CreateAppointmentRequest{Appointment: input})
+	wantCode(t, err, codes.FailedPrecondition)
+	_, err = client.CreateAppointment(ctx, &clinicpb.CreateAppointmentRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+
+	input.Description = "canal"
+	_, err = client.UpdateAppointment(ctx, &clinicpb.UpdateAppointmentRequest{Id: 1, Appointment: input})
+	wantCode(t, err, codes.FailedPrecondition)
+	input.Version = 1
+	updated, err := client.UpdateAppointment(ctx, &clinicpb.UpdateAppointmentRequest{Id: 1, Appointment: input})
+	if err != nil {
+		t.Fatalf("UpdateAppointment: %v", err)
+	}
+	if updated.Description != "canal" || updated.Version != 2 {
+		t.Errorf("updated = %v, want canal at version 2", updated)
+	}
+	_, err = client.UpdateAppointment(ctx, &clinicpb.UpdateAppointmentRequest{Id: 1, Appointment: input})
+	wantCode(t, err, codes.Aborted)
+	_, err = client.UpdateAppointment(ctx, &clinicpb.UpdateAppointmentRequest{Id: 9, Appointment: input})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = client.DeleteAppointment(ctx, &clinicpb.DeleteAppointmentRequest{Id: 1})
+	wantCode(t, err, codes.FailedPrecondition)
+	_, err = client.DeleteAppointment(ctx, &clinicpb.DeleteAppointmentRequest{Id: 1, Version: 1})
+	wantCode(t, err, codes.Aborted)
+	if _, err := client.DeleteAppointment(ctx, &clinicpb.DeleteAppointmentRequest{Id: 1, Version: 2}); err != nil {
+		t.Fatalf("DeleteAppointment: %v", err)
+	}
+	_, err = client.GetAppointment(ctx, &clinicpb.GetAppointmentRequest{Id: 1})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestDeletePatient(t *testing.T) {
+	ts := newTestServer(t)
+	client := clinicpb.NewPatientServiceClient(ts.conn)
+	ctx := ts.withToken(t)
+
+	if _, err := client.DeletePatient(ctx, &clinicpb.DeletePatientRequest{Id: 1}); err != nil {
+		t.Fatalf("DeletePatient: %v", err)
+	}
+	_, err := client.DeletePatient(ctx, &clinicpb.DeletePatientRequest{Id: 1})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestPanicIsRecovered(t *testing.T) {
+	ts := newTestServer(t)
+	client := clinicpb.NewPatientServiceClient(ts.conn)
+
+	_, err := client.ListPatients(ts.withToken(t), &clinicpb.ListPatientsRequest{})
+	wantCode(t, err, codes.Internal)
+	// o servidor continua de pé
+	_, err = client.DeletePatient(ts.withToken(t), &clinicpb.DeletePatientRequest{Id: 1})
+	wantCode(t, err, codes.OK)
+}
+
+func TestWatchAppointments(t *testing.T) {
+	ts := newTestServer(t)
+	client := clinicpb.NewAppointmentServiceClient(ts.conn)
+	ctx, cancel := context.WithCancel(ts.withToken(t))
+	defer cancel()
+
+	stream, err := client.WatchAppointments(ctx, &clinicpb.WatchAppointmentsRequest{Dentist: "CRO-1"})
+	if err != nil {
+		t.Fatalf("WatchAppointments: %v", err)
+	}
+	// os cabeçalhos só chegam depois da inscrição no bus
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("Header: %v", err)
+	}
+
+	other := domain.Appointment{Id: 1, IdDentist: "CRO-2", IdPatient: "123"}
+	mine := domain.Appointment{Id: 2, IdDentist: "CRO-1", IdPatient: "123", Version: 1}
+	ts.bus.Publish(ctx, event.New(event.PatientCreated, domain.Patient{Id: 1}))
+	ts.bus.Publish(ctx, event.New(event.AppointmentCreated, domain.AppointmentDTO{Appointment: other}))
+	ts.bus.Publish(ctx, event.New(event.AppointmentCreated, domain.AppointmentDTO{Appointment: mine}))
+	ts.bus.Publish(ctx, event.New(event.AppointmentDeleted, event.Deleted{Id: 2}))
+
+	got, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if got.Type != event.AppointmentCreated || got.Appointment.GetId() != 2 || got.OccurredAt == nil {
+		t.Errorf("first event = %v, want appointment 2 created", got)
+	}
+	got, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if got.Type != event.AppointmentDeleted || got.DeletedId != 2 {
+		t.Errorf("second event = %v, want appointment 2 deleted", got)
+	}
+
+	cancel()
+	_, err = stream.Recv()
+	wantCode(t, err, codes.Canceled)
+}
@@ -7,20 +7,21 @@ CREATE TABLE `dentists` (
   `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
   `name` varchar(50) DEFAULT NULL,
   `surname` varchar(50) DEFAULT NULL,
-  `registration` varchar(50) DEFAULT NULL
+  `registration` varchar(50) NOT NULL,
+  UNIQUE KEY `uq_dentists_registration` (`registration`)
 );
 
 INSERT INTO dentists (name, surname, registration) VALUES
-("Chris", "Martin", "1234A"),
-("Jonny", "Buckland", "7654S"),
-("Will", "Champion", "56434L"),
-("Guy", "Berryman", "345678S"),
-("Joao", "Borges Santos", "98017"),
-("Fernanda", "Reis",  "99727"),
-("Adriana", "Batista", "96336"),
-("Luiz", "Freitas", "93280"),
-("Paulo", "Mendes", "92144"),
-("Ana", "Monteiro", "90050");
+("Chris", "Martin", "CRO-SP 1234"),
+("Jonny", "Buckland", "CRO-SP 7654"),
+("Will", "Champion", "CRO-RJ 56434"),
+("Guy", "Berryman", "CRO-MG 345678"),
+("Joao", "Borges Santos", "CRO-SP 98017"),
+("Fernanda", "Reis",  "CRO-RJ 99727"),
+("Adriana", "Batista", "CRO-PR 96336"),
+("Luiz", "Freitas", "CRO-RS 93280"),
+("Paulo", "Mendes", "CRO-BA 92144"),
+("Ana", "Monteiro", "CRO-SC 90050");
 
 DROP TABLE IF EXISTS `patients`;
 
@@ -29,7 +30,15 @@ CREATE TABLE `patients` (
   `name` varchar(50) DEFAULT NULL,
   `surname` varchar(50) DEFAULT NULL,
   `document` varchar(50) DEFAULT NULL,
-  `createdAt` varchar(50) DEFAULT NULL
+  `createdAt` varchar(50) DEFAULT NULL,
+  `email` varchar(100) DEFAULT NULL,
+  `phones` json DEFAULT NULL,
+  `birth_date` date DEFAULT NULL,
+  `address` json DEFAULT NULL,
+  `guardian` json DEFAULT NULL,
+  `emergency_contact` json DEFAULT NULL,
+  `preferred_language` varchar(10) DEFAULT NULL,
+  `consent` json DEFAULT NULL
 );
 
 INSERT INTO patients (name, surname, document, createdAt) VALUES
@@ -67,6 +76,10 @@ CREATE TABLE `appointments` (
   `idPatient` int NOT NULL,
   `appointmentDate` varchar(50) NOT NULL,
   `description` varchar(250) NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'scheduled',
+  `procedure_code` varchar(20) NOT NULL DEFAULT '',
+  `duration` int NOT NULL DEFAULT 30,
+  `version` int NOT NULL DEFAULT 1,
   FOREIGN KEY (idDentist) REFERENCES dentists (id),
   FOREIGN KEY (idPatient) REFERENCES patients (id)
 );
@@ -79,4 +92,320 @@ INSERT INTO appointments (idDentist, idPatient, appointmentDate, description) VA
 (7, 6, "30/05/2023 15:30", "lorem ipsum"),
 (8, 11, "30/05/2023 15:30", "lorem ipsum"),
 (9, 17, "30/05/2023 15:30", "lorem ipsum"),
-(9, 18, "30/05/2023 15:30", "lorem ipsum");
\ No newline at end of file
+(9, 18, "30/05/2023 15:30", "lorem ipsum");
+DROP TABLE IF EXISTS `users`;
+
+CREATE TABLE `users` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `username` varchar(50) NOT NULL,
+  `password_hash` varchar(100) NOT NULL,
+  `role` varchar(20) NOT NULL,
+  `id_dentist` int DEFAULT NULL,
+  UNIQUE KEY `uq_users_username` (`username`),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+-- senha inicial "changeme123", troque após o primeiro acesso
+INSERT INTO users (username, password_hash, role) VALUES
+("admin", "$2a$10$wtSU5DDQgNXd0inhR.fYt.45U35n2SJvioa7kRZHfTFZzFm1q.M6u", "admin");
+
+DROP TABLE IF EXISTS `clinical_notes`;
+
+CREATE TABLE `clinical_notes` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_appointment` int NOT NULL,
+  `id_patient` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `id_parent` int DEFAULT NULL,
+  `content` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  `signed_at` datetime DEFAULT NULL,
+  KEY `idx_clinical_notes_patient` (`id_patient`, `created_at`),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id),
+  FOREIGN KEY (id_parent) REFERENCES clinical_notes (id)
+);
+
+DROP TABLE IF EXISTS `dental_chart_entries`;
+
+CREATE TABLE `dental_chart_entries` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_appointment` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `tooth` tinyint NOT NULL,
+  `surface` char(1) NOT NULL DEFAULT '',
+  `condition` varchar(30) NOT NULL,
+  `notes` varchar(250) NOT NULL DEFAULT '',
+  `recorded_at` datetime NOT NULL,
+  KEY `idx_dental_chart_entries_patient` (`id_patient`, `recorded_at`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+DROP TABLE IF EXISTS `procedures`;
+
+CREATE TABLE `procedures` (
+  `code` varchar(20) NOT NULL PRIMARY KEY,
+  `name` varchar(100) NOT NULL,
+  `duration` int NOT NULL,
+  `price` decimal(10,2) NOT NULL
+);
+
+INSERT INTO procedures (code, name, duration, price) VALUES
+("CONS", "Consulta inicial", 30, 150.00),
+("PROF", "Profilaxia e limpeza", 45, 200.00),
+("REST", "Restauração em resina", 60, 250.00),
+("ENDO", "Tratamento de canal", 90, 900.00),
+("EXOD", "Extração simples", 45, 300.00),
+("COROA", "Coroa de porcelana", 60, 1800.00),
+("IMPL", "Implante dentário", 120, 3500.00),
+("CLAR", "Clareamento", 60, 800.00);
+
+DROP TABLE IF EXISTS `treatment_plans`;
+
+CREATE TABLE `treatment_plans` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `title` varchar(100) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `accepted_at` datetime DEFAULT NULL,
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+DROP TABLE IF EXISTS `treatment_plan_items`;
+
+CREATE TABLE `treatment_plan_items` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_plan` int NOT NULL,
+  `position` int NOT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `tooth` tinyint DEFAULT NULL,
+  `price` decimal(10,2) NOT NULL,
+  `id_appointment` int DEFAULT NULL,
+  FOREIGN KEY (id_plan) REFERENCES treatment_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+DROP TABLE IF EXISTS `insurance_plans`;
+
+CREATE TABLE `insurance_plans` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` varchar(100) NOT NULL,
+  `insurer` varchar(100) NOT NULL,
+  UNIQUE KEY `uq_insurance_plans_name` (`insurer`, `name`)
+);
+
+DROP TABLE IF EXISTS `coverage_rules`;
+
+CREATE TABLE `coverage_rules` (
+  `id_plan` int NOT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `percent` decimal(5,2) NOT NULL,
+  `requires_authorization` boolean NOT NULL DEFAULT false,
+  PRIMARY KEY (`id_plan`, `procedure_code`),
+  FOREIGN KEY (id_plan) REFERENCES insurance_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code)
+);
+
+DROP TABLE IF EXISTS `insurance_memberships`;
+
+CREATE TABLE `insurance_memberships` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_plan` int NOT NULL,
+  `card_number` varchar(50) NOT NULL,
+  `valid_from` date NOT NULL,
+  `valid_until` date DEFAULT NULL,
+  UNIQUE KEY `uq_insurance_memberships_card` (`id_plan`, `card_number`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_plan) REFERENCES insurance_plans (id)
+);
+
+DROP TABLE IF EXISTS `pre_authorizations`;
+
+CREATE TABLE `pre_authorizations` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_membership` int NOT NULL,
+  `id_appointment` int DEFAULT NULL,
+  `id_treatment_plan` int DEFAULT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `reference` varchar(100) DEFAULT NULL,
+  `reason` varchar(250) DEFAULT NULL,
+  `requested_at` datetime NOT NULL,
+  `decided_at` datetime DEFAULT NULL,
+  KEY `idx_pre_authorizations_patient` (`id_patient`),
+  FOREIGN KEY (id_membership) REFERENCES insurance_memberships (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_treatment_plan) REFERENCES treatment_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code)
+);
+
+DROP TABLE IF EXISTS `invoices`;
+
+CREATE TABLE `invoices` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_membership` int DEFAULT NULL,
+  `status` varchar(20) NOT NULL,
+  `issued_at` datetime NOT NULL,
+  `subtotal` decimal(10,2) NOT NULL,
+  `discount` decimal(10,2) NOT NULL DEFAULT 0,
+  `tax_rate` decimal(5,2) NOT NULL DEFAULT 0,
+  `tax` decimal(10,2) NOT NULL DEFAULT 0,
+  `total` decimal(10,2) NOT NULL,
+  `insurer_portion` decimal(10,2) NOT NULL DEFAULT 0,
+  `patient_portion` decimal(10,2) NOT NULL,
+  KEY `idx_invoices_patient` (`id_patient`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_membership) REFERENCES insurance_memberships (id)
+);
+
+DROP TABLE IF EXISTS `invoice_items`;
+
+CREATE TABLE `invoice_items` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_invoice` int NOT NULL,
+  `id_appointment` int NOT NULL,
+  `billed_appointment` int DEFAULT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `description` varchar(250) NOT NULL,
+  `amount` decimal(10,2) NOT NULL,
+  `covered_amount` decimal(10,2) NOT NULL DEFAULT 0,
+  UNIQUE KEY `uq_invoice_items_billed_appointment` (`billed_appointment`),
+  FOREIGN KEY (id_invoice) REFERENCES invoices (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+DROP TABLE IF EXISTS `payments`;
+
+CREATE TABLE `payments` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_invoice` int NOT NULL,
+  `method` varchar(20) NOT NULL,
+  `amount` decimal(10,2) NOT NULL,
+  `paid_at` datetime NOT NULL,
+  FOREIGN KEY (id_invoice) REFERENCES invoices (id)
+);
+
+DROP TABLE IF EXISTS `appointment_reminders`;
+
+CREATE TABLE `appointment_reminders` (
+  `id_appointment` int NOT NULL,
+  `kind` varchar(10) NOT NULL,
+  `channel` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `sent_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id_appointment`, `kind`, `channel`),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+DROP TABLE IF EXISTS `appointment_links`;
+
+CREATE TABLE `appointment_links` (
+  `nonce` char(32) NOT NULL PRIMARY KEY,
+  `id_appointment` int NOT NULL,
+  `action` varchar(10) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `used_ip` varchar(45) DEFAULT NULL,
+  `used_user_agent` varchar(255) DEFAULT NULL,
+  `previous_status` varchar(20) DEFAULT NULL,
+  `new_status` varchar(20) DEFAULT NULL,
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+DROP TABLE IF EXISTS `webhook_subscriptions`;
+
+CREATE TABLE `webhook_subscriptions` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `url` varchar(500) NOT NULL,
+  `secret` varchar(100) NOT NULL,
+  `events` json NOT NULL,
+  `active` boolean NOT NULL DEFAULT true,
+  `created_at` datetime NOT NULL
+);
+
+DROP TABLE IF EXISTS `webhook_deliveries`;
+
+CREATE TABLE `webhook_deliveries` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_subscription` int NOT NULL,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(50) NOT NULL,
+  `payload` json NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime DEFAULT NULL,
+  `last_error` varchar(500) DEFAULT NULL,
+  `response_status` int DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `delivered_at` datetime DEFAULT NULL,
+  UNIQUE KEY `uq_webhook_deliveries_event` (`id_subscription`, `event_id`),
+  KEY `idx_webhook_deliveries_due` (`status`, `next_attempt_at`),
+  FOREIGN KEY (id_subscription) REFERENCES webhook_subscriptions (id)
+);
+
+DROP TABLE IF EXISTS `outbox_events`;
+
+CREATE TABLE `outbox_events` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(50) NOT NULL,
+  `aggregate` varchar(20) NOT NULL,
+  `aggregate_id` int NOT NULL,
+  `payload` json NOT NULL,
+  `created_at` datetime NOT NULL,
+  `published_at` datetime DEFAULT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` varchar(500) DEFAULT NULL,
+  `delivered_sinks` varchar(200) NOT NULL DEFAULT '',
+  `claimed_until` datetime DEFAULT NULL,
+  `failed_at` datetime DEFAULT NULL,
+  UNIQUE KEY `uq_outbox_events_event` (`event_id`),
+  KEY `idx_outbox_events_pending` (`published_at`, `id`)
+);
+
+DROP TABLE IF EXISTS `calendar_feeds`;
+
+CREATE TABLE `calendar_feeds` (
+  `owner` varchar(20) NOT NULL,
+  `id_owner` int NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`owner`, `id_owner`)
+);
+
+DROP TABLE IF EXISTS `schedule_blocks`;
+
+CREATE TABLE `schedule_blocks` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_dentist` varchar(50) NOT NULL,
+  `uid` varchar(255) NOT NULL,
+  `href` varchar(255) NOT NULL,
+  `start_at` datetime NOT NULL,
+  `end_at` datetime NOT NULL,
+  `summary` varchar(255) NOT NULL DEFAULT '',
+  UNIQUE KEY `uq_schedule_blocks_href` (`id_dentist`, `href`),
+  KEY `idx_schedule_blocks_interval` (`start_at`, `end_at`)
+);
+
+DROP TABLE IF EXISTS `caldav_sync_states`;
+
+CREATE TABLE `caldav_sync_states` (
+  `id_dentist` int NOT NULL,
+  `token` char(64) NOT NULL,
+  `state` json NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id_dentist`, `token`)
+);
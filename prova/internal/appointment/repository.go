@@ -1,13 +1,19 @@
 package appointment
 
 import (
+	"context"
 	"errors"
+	"time"
+
 	"github.com/meirafa/prova2-golang/internal/domain"
 	"github.com/meirafa/prova2-golang/pkg/store"
 )
 
 var table = store.AP
 
+// sqlDateTimeLayout é o formato de data e hora aceito pelo MySQL
+const sqlDateTimeLayout = "2006-01-02 15:04:05"
+
 type Repository interface {
 	//GetAll retorna todas consultas (appointment)
 	GetAll() (interface{}, error)
@@ -15,12 +21,38 @@ type Repository interface {
 	GetByID(entityId int) (interface{}, error)
 	// GetByDocumentPatient busca uma consulta pelo documento do paciente
 	GetByDocumentPatient(Document string) (interface{}, error)
+	// GetByDentistRegistration busca as consultas de um dentista pelo número do CRO
+	GetByDentistRegistration(registration string) ([]domain.AppointmentDTO, error)
 	// Create cria uma nova consulta
 	Create(a domain.Appointment) (interface{}, error)
 	//Update atualiza uma consulta
 	Update(entityId int, a domain.Appointment) (interface{}, error)
-	//Delete exclui uma consulta
-	Delete(entityId int) error
+	//Delete exclui uma consulta; com version diferente de zero, só se ela ainda estiver nessa versão
+	Delete(entityId, version int) error
+	// GetByDateTimeInterval retorna as consultas marcadas entre duas datas
+	GetByDateTimeInterval(start, end time.Time) ([]domain.Appointment, error)
+	// GetBlocks retorna os horários bloqueados na agenda de um dentista
+	GetBlocks(registration string) ([]domain.ScheduleBlock, error)
+	// GetBlocksByInterval retorna os horários bloqueados que se sobrepõem ao intervalo
+	GetBlocksByInterval(start, end time.Time) ([]domain.ScheduleBlock, error)
+	// GetBlock retorna um horário bloqueado pelo endereço do recurso no calendário
+	GetBlock(registration, href string) (domain.ScheduleBlock, error)
+	// CreateBlock insere um horário bloqueado
+	CreateBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error)
+	// UpdateBlock atualiza um horário bloqueado
+	UpdateBlock(b domain.ScheduleBlock) error
+	// DeleteBlock exclui um horário bloqueado
+	DeleteBlock(id int) error
+	// GetByIDs retorna as consultas com os ids informados, numa única consulta
+	GetByIDs(ctx context.Context, ids []int) ([]domain.AppointmentDTO, error)
+	// Search lista as consultas filtradas, por data
+	Search(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
+	// SearchByDentists lista as consultas dos dentistas de search.Dentists, com a página aplicada a
+	// cada dentista
+	SearchByDentists(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
+	// SearchByPatients lista as consultas dos pacientes de search.Patients, com a página aplicada a
+	// cada paciente
+	SearchByPatients(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
 }
 
 type repository struct {
@@ -44,6 +76,10 @@ func (r *repository) GetByDocumentPatient(Document string) (interface{}, error)
 	return r.store.GetAllAppointmentsByPatientIdentify(Document)
 }
 
+func (r *repository) GetByDentistRegistration(registration string) ([]domain.AppointmentDTO, error) {
+	return r.store.GetAllAppointmentsByDentistsLicense(registration)
+}
+
 func (r *repository) Create(a domain.Appointment) (interface{}, error) {
 	return r.store.Save(a, table)
 }
@@ -66,6 +102,55 @@ func (r *repository) Update(entityId int, a domain.Appointment) (interface{}, er
 	return nil, errors.New("appointment not found")
 }
 
-func (r *repository) Delete(entityId int) error {
-	return r.store.Delete(entityId, table)
+func (r *repository) Delete(entityId, version int) error {
+	return r.store.DeleteVersion(entityId, version)
+}
+
+func (r *repository) GetByDateTimeInterval(start, end time.Time) ([]domain.Appointment, error) {
+	return r.store.GetAllAppointmentsByDateTimeInterval(start.Format(sqlDateTimeLayout), end.Format(sqlDateTimeLayout))
+}
+
+func (r *repository) GetBlocks(registration string) ([]domain.ScheduleBlock, error) {
+	return r.store.GetScheduleBlocks(registration)
+}
+
+func (r *repository) GetBlocksByInterval(start, end time.Time) ([]domain.ScheduleBlock, error) {
+	return r.store.GetScheduleBlocksByInterval(start.Format(sqlDateTimeLayout), end.Format(sqlDateTimeLayout))
+}
+
+func (r *repository) GetBlock(registration, href string) (domain.ScheduleBlock, error) {
+	return r.store.GetScheduleBlock(registration, href)
+}
+
+func (r *repository) CreateBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error) {
+	return r.store.SaveScheduleBlock(b)
+}
+
+func (r *repository) UpdateBlock(b domain.ScheduleBlock) error {
+	return r.store.UpdateScheduleBlock(b)
+}
+
+func (r *repository) DeleteBlock(id int) error {
+	return r.store.DeleteScheduleBlock(id)
+}
+
+func (r *repository) GetByIDs(ctx context.Context, ids []int) ([]domain.AppointmentDTO, error) {
+	found, err := r.store.GetByIDs(ctx, ids, table)
+	if err != nil {
+		return nil, err
+	}
+	appointments, _ := found.([]domain.AppointmentDTO)
+	return appointments, nil
+}
+
+func (r *repository) Search(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	return r.store.SearchAppointments(ctx, search)
+}
+
+func (r *repository) SearchByDentists(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	return r.store.SearchAppointmentsByDentists(ctx, search)
+}
+
+func (r *repository) SearchByPatients(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	return r.store.SearchAppointmentsByPatients(ctx, search)
 }
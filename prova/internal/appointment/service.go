@@ -1,9 +1,37 @@
 package appointment
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// DateLayout é o formato aceito para a data da consulta
+const DateLayout = "02/01/2006 15:04"
+
+// DefaultDuration é a duração, em minutos, das consultas sem procedimento informado
+const DefaultDuration = 30
+
+// lookBehind limita a busca por consultas que começaram antes e ainda ocupam o horário
+const lookBehind = 24 * time.Hour
+
+var (
+	// ErrInvalidStatus indica que a situação informada para a consulta não existe
+	ErrInvalidStatus = errors.New("invalid status, expected one of scheduled, confirmed, checked_in, completed, cancelled or no_show")
+	// ErrInvalidDate indica que a data da consulta não segue o formato esperado
+	ErrInvalidDate = errors.New("invalid appointment_date, expected format " + DateLayout)
+	// ErrInvalidProcedure indica que o procedimento ou a duração da consulta são inválidos
+	ErrInvalidProcedure = errors.New("invalid procedure_code or duration")
+	// ErrUnavailable indica que o dentista já possui uma consulta no horário
+	ErrUnavailable = errors.New("dentist already has an appointment at this time")
+	// ErrBlockNotFound indica que o horário bloqueado não existe na agenda do dentista
+	ErrBlockNotFound = errors.New("schedule block not found")
+	// ErrVersionMismatch indica que a consulta foi alterada desde a versão informada
+	ErrVersionMismatch = errors.New("appointment was modified by another request, reload it and try again")
 )
 
 type Service interface {
@@ -11,22 +39,40 @@ type Service interface {
 	GetAll() ([]domain.AppointmentDTO, error)
 	//GetById retorna uma consulta (appointment) por id
 	GetByID(id int) (domain.AppointmentDTO, error)
+	// GetByIDs retorna as consultas com os ids informados numa única busca; os ids inexistentes
+	// ficam de fora da lista
+	GetByIDs(ids []int) ([]domain.AppointmentDTO, error)
 	// GetByDocumentPatient busca uma consulta pelo documento do paciente
 	GetByDocumentPatient(Document string) ([]domain.AppointmentDTO, error)
+	// GetByDentistRegistration busca as consultas de um dentista pelo número do CRO
+	GetByDentistRegistration(registration string) ([]domain.AppointmentDTO, error)
 	// Create cria uma nova consulta
 	Create(a domain.Appointment) (domain.AppointmentDTO, error)
-	//Update atualiza uma consulta
+	//Update atualiza uma consulta. Com a.Version diferente de zero, a consulta só é atualizada se
+	// ainda estiver nessa versão; caso contrário, retorna ErrVersionMismatch.
 	Update(id int, a domain.Appointment) (domain.AppointmentDTO, error)
-	//Delete exclui uma consulta
-	Delete(id int) error
+	//Delete exclui uma consulta; com version diferente de zero, só se ela ainda estiver nessa versão
+	Delete(id, version int) error
+	// GetByInterval retorna as consultas marcadas entre duas datas
+	GetByInterval(start, end time.Time) ([]domain.Appointment, error)
+	// GetBlocks retorna os horários bloqueados na agenda de um dentista
+	GetBlocks(registration string) ([]domain.ScheduleBlock, error)
+	// GetBlock retorna um horário bloqueado pelo endereço do recurso no calendário
+	GetBlock(registration, href string) (domain.ScheduleBlock, error)
+	// SaveBlock bloqueia um horário na agenda, ou move o bloqueio de mesmo endereço. O horário não
+	// pode se sobrepor a uma consulta ativa do dentista.
+	SaveBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error)
+	// DeleteBlock libera um horário bloqueado
+	DeleteBlock(registration, href string) error
 }
 
 type service struct {
-	r Repository
+	r          Repository
+	procedures procedure.Service
 }
 
-func NewService(r Repository) Service {
-	return &service{r}
+func NewService(r Repository, procedures procedure.Service) Service {
+	return &service{r, procedures}
 }
 
 func (s *service) GetAll() ([]domain.AppointmentDTO, error) {
@@ -53,6 +99,10 @@ func (s *service) GetByID(id int) (domain.AppointmentDTO, error) {
 	return appointment, nil
 }
 
+func (s *service) GetByIDs(ids []int) ([]domain.AppointmentDTO, error) {
+	return s.r.GetByIDs(context.Background(), ids)
+}
+
 func (s *service) GetByDocumentPatient(Document string) ([]domain.AppointmentDTO, error) {
 	list, err := s.r.GetByDocumentPatient(Document)
 	if err != nil {
@@ -65,8 +115,28 @@ func (s *service) GetByDocumentPatient(Document string) ([]domain.AppointmentDTO
 	return appointments, nil
 }
 
+func (s *service) GetByDentistRegistration(registration string) ([]domain.AppointmentDTO, error) {
+	return s.r.GetByDentistRegistration(registration)
+}
+
 func (s *service) Create(a domain.Appointment) (domain.AppointmentDTO, error) {
+	if a.Status == "" {
+		a.Status = domain.StatusScheduled
+	}
+	if !domain.IsValidStatus(a.Status) {
+		return domain.AppointmentDTO{}, ErrInvalidStatus
+	}
+	if err := s.applyProcedure(&a); err != nil {
+		return domain.AppointmentDTO{}, err
+	}
+	if err := s.checkAvailability(a); err != nil {
+		return domain.AppointmentDTO{}, err
+	}
+
 	aSavedInterface, err := s.r.Create(a)
+	if errors.Is(err, store.ErrOverlap) {
+		return domain.AppointmentDTO{}, ErrUnavailable
+	}
 	if err != nil {
 		return domain.AppointmentDTO{}, err
 	}
@@ -78,11 +148,20 @@ func (s *service) Create(a domain.Appointment) (domain.AppointmentDTO, error) {
 	return domain.AppointmentDTO{}, errors.New("failed to save new appointment")
 }
 
+func (s *service) GetByInterval(start, end time.Time) ([]domain.Appointment, error) {
+	return s.r.GetByDateTimeInterval(start, end)
+}
+
 func (s *service) Update(id int, a domain.Appointment) (domain.AppointmentDTO, error) {
 	aUpdate, err := s.GetByID(id)
 	if err != nil {
 		return domain.AppointmentDTO{}, err
 	}
+	// evita validar a mesclagem sobre uma versão que já se sabe antiga; a conferência que vale é a
+	// do UPDATE, feita no store
+	if a.Version != 0 && a.Version != aUpdate.Version {
+		return domain.AppointmentDTO{}, ErrVersionMismatch
+	}
 
 	if a.Description == "" {
 		a.Description = aUpdate.Description
@@ -96,9 +175,37 @@ func (s *service) Update(id int, a domain.Appointment) (domain.AppointmentDTO, e
 	if a.IdPatient == "" {
 		a.IdPatient = aUpdate.IdPatient
 	}
+	if a.Status == "" {
+		a.Status = aUpdate.Status
+	}
+	if !domain.IsValidStatus(a.Status) {
+		return domain.AppointmentDTO{}, ErrInvalidStatus
+	}
+	if a.ProcedureCode == "" {
+		a.ProcedureCode = aUpdate.ProcedureCode
+		if a.Duration == 0 {
+			a.Duration = aUpdate.Duration
+		}
+	}
+	if err := s.applyProcedure(&a); err != nil {
+		return domain.AppointmentDTO{}, err
+	}
 	a.Id = aUpdate.Id
+	moved := a.AppointmentDate != aUpdate.AppointmentDate || a.IdDentist != aUpdate.IdDentist || a.Duration != aUpdate.Duration
+	reactivated := !domain.OccupiesSchedule(aUpdate.Status)
+	if domain.OccupiesSchedule(a.Status) && (moved || reactivated) {
+		if err := s.checkAvailability(a); err != nil {
+			return domain.AppointmentDTO{}, err
+		}
+	}
 
 	updated, err := s.r.Update(id, a)
+	if errors.Is(err, store.ErrVersionMismatch) {
+		return domain.AppointmentDTO{}, ErrVersionMismatch
+	}
+	if errors.Is(err, store.ErrOverlap) {
+		return domain.AppointmentDTO{}, ErrUnavailable
+	}
 	if err != nil {
 		return domain.AppointmentDTO{}, err
 	}
@@ -110,6 +217,130 @@ func (s *service) Update(id int, a domain.Appointment) (domain.AppointmentDTO, e
 	return response, nil
 }
 
-func (s *service) Delete(id int) error {
-	return s.r.Delete(id)
+func (s *service) Delete(id, version int) error {
+	err := s.r.Delete(id, version)
+	if errors.Is(err, store.ErrVersionMismatch) {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
+// applyProcedure valida o procedimento da consulta e usa a sua duração padrão quando a duração não é informada
+func (s *service) applyProcedure(a *domain.Appointment) error {
+	if a.Duration < 0 {
+		return ErrInvalidProcedure
+	}
+	if a.ProcedureCode == "" {
+		if a.Duration == 0 {
+			a.Duration = DefaultDuration
+		}
+		return nil
+	}
+	p, err := s.procedures.GetByCode(a.ProcedureCode)
+	if errors.Is(err, procedure.ErrNotFound) {
+		return ErrInvalidProcedure
+	}
+	if err != nil {
+		return err
+	}
+	if a.Duration == 0 {
+		a.Duration = p.Duration
+	}
+	return nil
+}
+
+// checkAvailability verifica se o horário da consulta não se sobrepõe a outra consulta ativa
+// ou a um horário bloqueado do mesmo dentista. Antecipa o erro para a resposta; a conferência que
+// vale é repetida pelo store dentro da transação que grava a consulta.
+func (s *service) checkAvailability(a domain.Appointment) error {
+	start, err := time.Parse(DateLayout, a.AppointmentDate)
+	if err != nil {
+		return ErrInvalidDate
+	}
+	end := start.Add(time.Duration(a.Duration) * time.Minute)
+
+	if err := s.checkAppointments(a.IdDentist, start, end, a.Id); err != nil {
+		return err
+	}
+	blocks, err := s.r.GetBlocksByInterval(start, end)
+	if err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		if b.IdDentist == a.IdDentist {
+			return ErrUnavailable
+		}
+	}
+	return nil
+}
+
+// checkAppointments verifica se o intervalo não se sobrepõe a uma consulta ativa do dentista,
+// ignorando a consulta informada
+func (s *service) checkAppointments(registration string, start, end time.Time, ignoreID int) error {
+	booked, err := s.r.GetByDateTimeInterval(start.Add(-lookBehind), end)
+	if err != nil {
+		return err
+	}
+	for _, b := range booked {
+		if b.Id == ignoreID || b.IdDentist != registration || !domain.OccupiesSchedule(b.Status) {
+			continue
+		}
+		bStart, err := time.Parse(DateLayout, b.AppointmentDate)
+		if err != nil {
+			continue
+		}
+		bEnd := bStart.Add(time.Duration(b.Duration) * time.Minute)
+		if bStart.Before(end) && start.Before(bEnd) {
+			return ErrUnavailable
+		}
+	}
+	return nil
+}
+
+func (s *service) GetBlocks(registration string) ([]domain.ScheduleBlock, error) {
+	return s.r.GetBlocks(registration)
+}
+
+func (s *service) GetBlock(registration, href string) (domain.ScheduleBlock, error) {
+	b, err := s.r.GetBlock(registration, href)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.ScheduleBlock{}, ErrBlockNotFound
+	}
+	return b, err
+}
+
+func (s *service) SaveBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error) {
+	start, err := time.Parse(DateLayout, b.Start)
+	if err != nil {
+		return domain.ScheduleBlock{}, ErrInvalidDate
+	}
+	end, err := time.Parse(DateLayout, b.End)
+	if err != nil || !end.After(start) {
+		return domain.ScheduleBlock{}, ErrInvalidDate
+	}
+	if err := s.checkAppointments(b.IdDentist, start, end, 0); err != nil {
+		return domain.ScheduleBlock{}, err
+	}
+
+	existing, err := s.r.GetBlock(b.IdDentist, b.Href)
+	if errors.Is(err, store.ErrNotFound) {
+		return s.r.CreateBlock(b)
+	}
+	if err != nil {
+		return domain.ScheduleBlock{}, err
+	}
+	b.Id = existing.Id
+	if err := s.r.UpdateBlock(b); err != nil {
+		return domain.ScheduleBlock{}, err
+	}
+	return b, nil
+}
+
+func (s *service) DeleteBlock(registration, href string) error {
+	b, err := s.GetBlock(registration, href)
+	if err != nil {
+		return err
+	}
+	return s.r.DeleteBlock(b.Id)
 }
+
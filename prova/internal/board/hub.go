@@ -0,0 +1,265 @@
+// Package board distribui as alterações de consultas aos painéis da recepção conectados por SSE ou
+// WebSocket. O Hub recebe os eventos do outbox pelo event.Bus, guarda os mais recentes para a
+// reconexão com Last-Event-ID e entrega a cada cliente pelo seu próprio buffer.
+package board
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+)
+
+// Valores padrão de NewHub
+const (
+	// DefaultHistory é quantos eventos ficam guardados para a reconexão
+	DefaultHistory = 1000
+	// DefaultBuffer é quantos eventos podem aguardar envio a um cliente; o cliente que fica para
+	// trás é desconectado e recupera o que perdeu ao reconectar com Last-Event-ID
+	DefaultBuffer = 64
+)
+
+// Filter seleciona os eventos de um painel. Os campos vazios não filtram.
+type Filter struct {
+	// Dentist é o CRO do dentista
+	Dentist string
+	// Date é o dia das consultas; só a data é considerada
+	Date time.Time
+}
+
+// entry é um evento guardado, com a consulta, o dentista e o dia para os filtros. Quando a
+// consulta muda de dentista ou de dia, previousDentist e previousDay guardam os anteriores, para
+// que os painéis filtrados por eles também recebam a mudança e tirem a consulta da tela.
+type entry struct {
+	event           domain.BoardEvent
+	source          string
+	appointment     int
+	dentist         string
+	day             string
+	previousDentist string
+	previousDay     string
+}
+
+// known é o que o hub sabe de uma consulta pelos eventos guardados, para reconhecer o check-in e
+// filtrar as exclusões, cujo evento só traz o id. É descartado junto com o último evento guardado
+// da consulta, em lastID, para que o mapa não cresça além do histórico.
+type known struct {
+	status  string
+	dentist string
+	day     string
+	lastID  int64
+}
+
+// Hub é o pub/sub em memória do painel de consultas
+type Hub struct {
+	history int
+	buffer  int
+
+	mu          sync.Mutex
+	last        int64
+	entries     []entry
+	sources     map[string]bool
+	known       map[int]known
+	subscribers map[*Subscription]bool
+}
+
+// NewHub cria um hub que guarda os últimos history eventos e até buffer eventos pendentes por
+// cliente. Os ids começam no instante da criação, em milissegundos, para que um Last-Event-ID de
+// antes de uma reinicialização seja reconhecido como antigo.
+func NewHub(history, buffer int) *Hub {
+	return &Hub{
+		history:     history,
+		buffer:      buffer,
+		last:        time.Now().UnixMilli(),
+		sources:     map[string]bool{},
+		known:       map[int]known{},
+		subscribers: map[*Subscription]bool{},
+	}
+}
+
+// Listen inscreve o hub no bus e retorna a função que cancela a inscrição
+func (h *Hub) Listen(bus event.Bus) (unsubscribe func()) {
+	return bus.Subscribe(func(e domain.Event) {
+		if err := h.publish(e); err != nil {
+			log.Printf("board: can't read event %s: %v", e.Id, err)
+		}
+	})
+}
+
+// publish guarda e distribui um evento. Os eventos que não são de consultas são ignorados, assim
+// como os repetidos, já que o relay do outbox entrega pelo menos uma vez.
+func (h *Hub) publish(e domain.Event) error {
+	switch e.Type {
+	case event.AppointmentCreated, event.AppointmentUpdated, event.AppointmentCancelled, event.AppointmentDeleted:
+	default:
+		return nil
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sources[e.Id] {
+		return nil
+	}
+
+	item := entry{source: e.Id, event: domain.BoardEvent{OccurredAt: e.OccurredAt}}
+	if e.Type == event.AppointmentDeleted {
+		var deleted event.Deleted
+		if err := json.Unmarshal(data, &deleted); err != nil {
+			return err
+		}
+		previous := h.known[deleted.Id]
+		delete(h.known, deleted.Id)
+		item.appointment = deleted.Id
+		item.event.Type = domain.BoardDeleted
+		item.event.DeletedId = deleted.Id
+		item.dentist, item.day = previous.dentist, previous.day
+	} else {
+		var a domain.AppointmentDTO
+		if err := json.Unmarshal(data, &a); err != nil {
+			return err
+		}
+		previous, seen := h.known[a.Id]
+		item.event.Appointment = &a
+		item.appointment = a.Id
+		item.dentist, item.day = a.IdDentist, day(a.AppointmentDate)
+		if seen && (previous.dentist != item.dentist || previous.day != item.day) {
+			item.previousDentist, item.previousDay = previous.dentist, previous.day
+		}
+		switch {
+		case e.Type == event.AppointmentCreated:
+			item.event.Type = domain.BoardCreated
+		case e.Type == event.AppointmentCancelled:
+			item.event.Type = domain.BoardCancelled
+		case a.Status == domain.StatusCheckedIn && (!seen || previous.status != domain.StatusCheckedIn):
+			item.event.Type = domain.BoardCheckedIn
+		default:
+			item.event.Type = domain.BoardUpdated
+		}
+	}
+
+	h.last++
+	item.event.Id = h.last
+	if item.event.Type != domain.BoardDeleted {
+		h.known[item.appointment] = known{status: item.event.Appointment.Status, dentist: item.dentist, day: item.day, lastID: item.event.Id}
+	}
+	h.entries = append(h.entries, item)
+	h.sources[item.source] = true
+	if len(h.entries) > h.history {
+		oldest := h.entries[0]
+		delete(h.sources, oldest.source)
+		if k, ok := h.known[oldest.appointment]; ok && k.lastID == oldest.event.Id {
+			delete(h.known, oldest.appointment)
+		}
+		h.entries = h.entries[1:]
+	}
+
+	for s := range h.subscribers {
+		if !s.filter.match(item) {
+			continue
+		}
+		select {
+		case s.events <- item.event:
+		default:
+			log.Printf("board: dropping a client that is %d events behind", h.buffer)
+			delete(h.subscribers, s)
+			close(s.dropped)
+		}
+	}
+	return nil
+}
+
+// Subscribe inscreve um cliente. Com lastID diferente de zero, os eventos posteriores a ele que
+// ainda estão guardados vão para Replay; se algum já foi descartado, ou o id é desconhecido,
+// Replay traz só um evento reset.
+func (h *Hub) Subscribe(f Filter, lastID int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := &Subscription{
+		hub:     h,
+		filter:  filter{dentist: f.Dentist},
+		events:  make(chan domain.BoardEvent, h.buffer),
+		dropped: make(chan struct{}),
+	}
+	if !f.Date.IsZero() {
+		s.filter.day = f.Date.Format("2006-01-02")
+	}
+
+	if lastID != 0 && lastID != h.last {
+		if lastID > h.last || len(h.entries) == 0 || lastID < h.entries[0].event.Id-1 {
+			s.Replay = []domain.BoardEvent{{Id: h.last, Type: domain.BoardReset}}
+		} else {
+			for _, item := range h.entries {
+				if item.event.Id > lastID && s.filter.match(item) {
+					s.Replay = append(s.Replay, item.event)
+				}
+			}
+		}
+	}
+	h.subscribers[s] = true
+	return s
+}
+
+// Subscription é a inscrição de um cliente
+type Subscription struct {
+	// Replay são os eventos perdidos desde o Last-Event-ID, a enviar antes dos de Events
+	Replay []domain.BoardEvent
+
+	hub     *Hub
+	filter  filter
+	events  chan domain.BoardEvent
+	dropped chan struct{}
+}
+
+// Events entrega os eventos publicados depois da inscrição
+func (s *Subscription) Events() <-chan domain.BoardEvent {
+	return s.events
+}
+
+// Dropped é fechado quando o cliente é desconectado por não acompanhar os eventos
+func (s *Subscription) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+// Close cancela a inscrição
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	delete(s.hub.subscribers, s)
+}
+
+type filter struct {
+	dentist string
+	day     string
+}
+
+// match indica se o evento passa no filtro, pelo dentista e dia atuais ou, numa consulta movida,
+// pelos anteriores. As exclusões de consultas que o hub não conhece são entregues a todos, já que
+// o evento não diz de quem era a consulta.
+func (f filter) match(item entry) bool {
+	if item.event.Type == domain.BoardDeleted && item.dentist == "" {
+		return true
+	}
+	moved := item.previousDentist != "" || item.previousDay != ""
+	return f.covers(item.dentist, item.day) || (moved && f.covers(item.previousDentist, item.previousDay))
+}
+
+func (f filter) covers(dentist, day string) bool {
+	return (f.dentist == "" || f.dentist == dentist) && (f.day == "" || f.day == day)
+}
+
+// day retorna o dia da data da consulta no formato 2006-01-02
+func day(appointmentDate string) string {
+	date, err := time.Parse(appointment.DateLayout, appointmentDate)
+	if err != nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
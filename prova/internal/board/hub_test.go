@@ -0,0 +1,202 @@
+package board
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+)
+
+// publish publica no bus a alteração da consulta e retorna o evento
+func publish(t *testing.T, bus event.Bus, eventType string, a domain.Appointment) domain.Event {
+	t.Helper()
+	e := event.New(eventType, domain.AppointmentDTO{Appointment: a})
+	if eventType == event.AppointmentDeleted {
+		e = event.New(eventType, event.Deleted{Id: a.Id})
+	}
+	if err := bus.Publish(context.Background(), e); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	return e
+}
+
+// types resume os eventos como tipo:id da consulta
+func types(events []domain.BoardEvent) []string {
+	var list []string
+	for _, e := range events {
+		id := e.DeletedId
+		if e.Appointment != nil {
+			id = e.Appointment.Id
+		}
+		list = append(list, e.Type+":"+strconv.Itoa(id))
+	}
+	return list
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	bus := event.NewBus()
+	hub := NewHub(3, DefaultBuffer)
+	hub.Listen(bus)
+
+	ana := domain.Appointment{Id: 1, IdDentist: "CRO-1", AppointmentDate: "04/03/2024 09:00", Status: domain.StatusScheduled}
+	bia := domain.Appointment{Id: 2, IdDentist: "CRO-2", AppointmentDate: "05/03/2024 10:00", Status: domain.StatusScheduled}
+	first := hub.Subscribe(Filter{}, 0)
+	defer first.Close()
+
+	publish(t, bus, event.AppointmentCreated, ana)
+	publish(t, bus, event.AppointmentCreated, bia)
+	ana.Status = domain.StatusCheckedIn
+	checkedIn := publish(t, bus, event.AppointmentUpdated, ana)
+	// o relay pode entregar de novo; a repetição é ignorada
+	bus.Publish(context.Background(), checkedIn)
+
+	var live []domain.BoardEvent
+	for len(live) < 3 {
+		select {
+		case e := <-first.Events():
+			live = append(live, e)
+		case <-time.After(time.Second):
+			t.Fatalf("got %v, want 3 events", types(live))
+		}
+	}
+	if want := []string{"created:1", "created:2", "checked_in:1"}; !reflect.DeepEqual(types(live), want) {
+		t.Errorf("live = %v, want %v", types(live), want)
+	}
+	if len(first.Events()) != 0 {
+		t.Errorf("the repeated event was delivered")
+	}
+	start := live[0].Id
+
+	tests := []struct {
+		name   string
+		filter Filter
+		lastID int64
+		want   []string
+	}{
+		{"no last id", Filter{}, 0, nil},
+		{"up to date", Filter{}, start + 2, nil},
+		{"after the first", Filter{}, start, []string{"created:2", "checked_in:1"}},
+		{"by dentist", Filter{Dentist: "CRO-1"}, start - 1, []string{"created:1", "checked_in:1"}},
+		{"by date", Filter{Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)}, start - 1, []string{"created:2"}},
+		{"unknown id", Filter{}, start + 10, []string{"reset:0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := hub.Subscribe(tt.filter, tt.lastID)
+			defer s.Close()
+			if got := types(s.Replay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// o histórico guarda 3 eventos: depois de mais um, o primeiro id já foi descartado
+	publish(t, bus, event.AppointmentDeleted, bia)
+	s := hub.Subscribe(Filter{}, start-1)
+	defer s.Close()
+	if got := types(s.Replay); !reflect.DeepEqual(got, []string{"reset:0"}) || s.Replay[0].Id != start+3 {
+		t.Errorf("replay = %v, want a reset at %d", s.Replay, start+3)
+	}
+	s = hub.Subscribe(Filter{Dentist: "CRO-2"}, start)
+	defer s.Close()
+	if got, want := types(s.Replay), []string{"created:2", "deleted:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay = %v, want %v", got, want)
+	}
+}
+
+func TestKnownIsPrunedWithHistory(t *testing.T) {
+	bus := event.NewBus()
+	hub := NewHub(2, DefaultBuffer)
+	hub.Listen(bus)
+
+	for id := 1; id <= 10; id++ {
+		publish(t, bus, event.AppointmentCreated, domain.Appointment{Id: id, IdDentist: "CRO-1", AppointmentDate: "04/03/2024 09:00"})
+	}
+	if len(hub.known) != 2 || len(hub.sources) != 2 {
+		t.Errorf("known = %d and sources = %d, want 2 and 2", len(hub.known), len(hub.sources))
+	}
+
+	// a consulta 9 continua conhecida enquanto tiver um evento guardado
+	nine := domain.Appointment{Id: 9, IdDentist: "CRO-1", AppointmentDate: "04/03/2024 09:00", Status: domain.StatusCheckedIn}
+	publish(t, bus, event.AppointmentUpdated, nine)
+	if _, ok := hub.known[9]; !ok || len(hub.known) != 2 {
+		t.Errorf("known = %v, want 9 and 10", hub.known)
+	}
+	publish(t, bus, event.AppointmentDeleted, nine)
+	if _, ok := hub.known[9]; ok {
+		t.Errorf("known = %v, want 9 removed on delete", hub.known)
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	bus := event.NewBus()
+	hub := NewHub(DefaultHistory, 1)
+	hub.Listen(bus)
+	s := hub.Subscribe(Filter{}, 0)
+
+	publish(t, bus, event.AppointmentCreated, domain.Appointment{Id: 1})
+	publish(t, bus, event.AppointmentCreated, domain.Appointment{Id: 2})
+	select {
+	case <-s.Dropped():
+	default:
+		t.Fatal("the subscriber was not dropped")
+	}
+	s.Close()
+}
+
+func TestMovedAppointmentReachesTheOldAndNewBoards(t *testing.T) {
+	bus := event.NewBus()
+	hub := NewHub(DefaultHistory, DefaultBuffer)
+	hub.Listen(bus)
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	tuesday := monday.AddDate(0, 0, 1)
+	boards := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"old dentist", Filter{Dentist: "CRO-1"}, []string{"created:1", "updated:1"}},
+		{"old day", Filter{Date: monday}, []string{"created:1", "updated:1"}},
+		{"old dentist and day", Filter{Dentist: "CRO-1", Date: monday}, []string{"created:1", "updated:1"}},
+		{"new dentist", Filter{Dentist: "CRO-2"}, []string{"updated:1", "updated:1"}},
+		{"new day", Filter{Date: tuesday}, []string{"updated:1", "updated:1"}},
+		// a consulta nunca foi do dentista 2 na segunda-feira
+		{"new dentist on the old day", Filter{Dentist: "CRO-2", Date: monday}, nil},
+		{"another dentist", Filter{Dentist: "CRO-3"}, nil},
+	}
+	subscriptions := make([]*Subscription, len(boards))
+	for i, b := range boards {
+		subscriptions[i] = hub.Subscribe(b.filter, 0)
+		defer subscriptions[i].Close()
+	}
+
+	ana := domain.Appointment{Id: 1, IdDentist: "CRO-1", AppointmentDate: "04/03/2024 09:00", Status: domain.StatusScheduled}
+	publish(t, bus, event.AppointmentCreated, ana)
+	ana.IdDentist, ana.AppointmentDate = "CRO-2", "05/03/2024 10:00"
+	publish(t, bus, event.AppointmentUpdated, ana)
+	// uma alteração sem mudança de dentista ou dia só chega aos painéis atuais
+	ana.Description = "retorno"
+	publish(t, bus, event.AppointmentUpdated, ana)
+
+	for i, b := range boards {
+		var got []domain.BoardEvent
+		for len(subscriptions[i].Events()) > 0 {
+			got = append(got, <-subscriptions[i].Events())
+		}
+		if !reflect.DeepEqual(types(got), b.want) {
+			t.Errorf("%s: events = %v, want %v", b.name, types(got), b.want)
+		}
+	}
+
+	// a reconexão também recebe a mudança pelo dentista antigo
+	s := hub.Subscribe(Filter{Dentist: "CRO-1"}, hub.last-3)
+	defer s.Close()
+	if got, want := types(s.Replay), []string{"created:1", "updated:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+package caldav
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetState retorna as ETags dos recursos da agenda registradas para o token
+	GetState(dentistID int, token string) (map[string]string, error)
+	// SaveState registra as ETags dos recursos da agenda para o token
+	SaveState(dentistID int, token string, state map[string]string, expiresBefore time.Time) error
+}
+
+type repository struct {
+	store store.CalDAVStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.CalDAVStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetState(dentistID int, token string) (map[string]string, error) {
+	return r.store.GetSyncState(dentistID, token)
+}
+
+func (r *repository) SaveState(dentistID int, token string, state map[string]string, expiresBefore time.Time) error {
+	return r.store.SaveSyncState(dentistID, token, state, expiresBefore)
+}
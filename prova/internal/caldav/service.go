@@ -0,0 +1,396 @@
+package caldav
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/calendar"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/ical"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// SyncTokenPrefix transforma o token de sincronização na URI exigida pela RFC 6578
+const SyncTokenPrefix = "urn:prova2-golang:sync:"
+
+// syncStateTTL é o tempo pelo qual um token de sincronização continua válido
+const syncStateTTL = 30 * 24 * time.Hour
+
+// appointmentPrefix nomeia os recursos das consultas; os demais nomes são horários bloqueados
+const appointmentPrefix = "appointment-"
+
+// stamp é o DTSTAMP dos recursos. As consultas não registram a data da última alteração, e um
+// DTSTAMP fixo mantém o conteúdo, e portanto a ETag, estável enquanto a consulta não muda.
+var stamp = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	// ErrNotFound indica que a agenda ou o recurso não existe
+	ErrNotFound = errors.New("calendar resource not found")
+	// ErrPreconditionFailed indica que a ETag informada não corresponde à versão atual do recurso
+	ErrPreconditionFailed = errors.New("calendar resource has been modified")
+	// ErrInvalidSyncToken indica um token de sincronização desconhecido ou expirado
+	ErrInvalidSyncToken = errors.New("invalid sync token")
+	// ErrInvalidCalendar indica que o corpo enviado não é um evento iCalendar aceito
+	ErrInvalidCalendar = errors.New("invalid calendar object")
+	// ErrNotAllowed indica que consultas não podem ser criadas por um cliente de calendário
+	ErrNotAllowed = errors.New("appointments cannot be created from a calendar client")
+)
+
+// Collection é a agenda de um dentista
+type Collection struct {
+	IdDentist   int
+	DisplayName string
+	SyncToken   string
+}
+
+// Resource é um evento da agenda, identificado pelo nome dentro da coleção
+type Resource struct {
+	Name  string
+	ETag  string
+	Data  []byte
+	Start time.Time
+	End   time.Time
+}
+
+// Changes são as alterações da agenda desde um token de sincronização
+type Changes struct {
+	Changed   []Resource
+	Removed   []string
+	SyncToken string
+}
+
+type Service interface {
+	// Collection retorna a agenda do dentista e o seu token de sincronização atual
+	Collection(dentistID int) (Collection, error)
+	// Resources retorna os eventos da agenda: consultas e horários bloqueados
+	Resources(dentistID int) ([]Resource, error)
+	// Resource retorna um evento da agenda pelo nome
+	Resource(dentistID int, name string) (Resource, error)
+	// Put aplica um evento enviado pelo cliente. Consultas são remarcadas ou canceladas pelo serviço
+	// de consultas; outros nomes criam ou movem horários bloqueados. Retorna se o recurso foi criado.
+	Put(dentistID int, name string, data []byte, ifMatch, ifNoneMatch string) (bool, error)
+	// Delete cancela a consulta ou libera o horário bloqueado
+	Delete(dentistID int, name, ifMatch string) error
+	// Changes retorna o que mudou desde o token informado, ou todos os eventos sem token
+	Changes(dentistID int, syncToken string) (Changes, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	dentists     dentist.Service
+	location     *time.Location
+}
+
+// NewService cria um novo serviço. As datas das consultas são interpretadas no fuso informado.
+func NewService(r Repository, appointments appointment.Service, dentists dentist.Service, location *time.Location) Service {
+	return &service{r, appointments, dentists, location}
+}
+
+func (s *service) Collection(dentistID int) (Collection, error) {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return Collection{}, err
+	}
+	resources, err := s.resources(d)
+	if err != nil {
+		return Collection{}, err
+	}
+	token, err := s.saveState(dentistID, resources)
+	if err != nil {
+		return Collection{}, err
+	}
+	return Collection{
+		IdDentist:   dentistID,
+		DisplayName: "Agenda - " + d.Name + " " + d.Surname,
+		SyncToken:   SyncTokenPrefix + token,
+	}, nil
+}
+
+func (s *service) Resources(dentistID int) ([]Resource, error) {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resources(d)
+}
+
+func (s *service) Resource(dentistID int, name string) (Resource, error) {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return Resource{}, err
+	}
+	if id, ok := appointmentID(name); ok {
+		_, resource, err := s.appointmentResource(d, id)
+		return resource, err
+	}
+	_, resource, err := s.blockResource(d, name)
+	return resource, err
+}
+
+func (s *service) Put(dentistID int, name string, data []byte, ifMatch, ifNoneMatch string) (bool, error) {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return false, err
+	}
+	e, err := ical.ParseEvent(data, s.location)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+
+	if id, ok := appointmentID(name); ok {
+		a, current, err := s.appointmentResource(d, id)
+		if errors.Is(err, ErrNotFound) {
+			return false, ErrNotAllowed
+		}
+		if err != nil {
+			return false, err
+		}
+		if ifNoneMatch == "*" || (ifMatch != "" && ifMatch != current.ETag) {
+			return false, ErrPreconditionFailed
+		}
+		if e.UID != calendar.AppointmentUID(id) {
+			return false, fmt.Errorf("%w: UID cannot be changed", ErrInvalidCalendar)
+		}
+		update := domain.Appointment{
+			AppointmentDate: e.Start.In(s.location).Format(appointment.DateLayout),
+			Duration:        int(e.End.Sub(e.Start) / time.Minute),
+			Description:     e.Description,
+			Version:         a.Version,
+		}
+		if e.Status == ical.StatusCancelled && a.Status != domain.StatusCancelled {
+			update.Status = domain.StatusCancelled
+		}
+		_, err = s.appointments.Update(id, update)
+		return false, err
+	}
+
+	_, current, err := s.blockResource(d, name)
+	exists := err == nil
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	if (ifNoneMatch == "*" && exists) || (ifMatch != "" && (!exists || ifMatch != current.ETag)) {
+		return false, ErrPreconditionFailed
+	}
+	_, err = s.appointments.SaveBlock(domain.ScheduleBlock{
+		IdDentist: d.Registration,
+		UID:       e.UID,
+		Href:      name,
+		Start:     e.Start.In(s.location).Format(appointment.DateLayout),
+		End:       e.End.In(s.location).Format(appointment.DateLayout),
+		Summary:   e.Summary,
+	})
+	return !exists, err
+}
+
+func (s *service) Delete(dentistID int, name, ifMatch string) error {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return err
+	}
+	if id, ok := appointmentID(name); ok {
+		a, current, err := s.appointmentResource(d, id)
+		if err != nil {
+			return err
+		}
+		if ifMatch != "" && ifMatch != current.ETag {
+			return ErrPreconditionFailed
+		}
+		if a.Status == domain.StatusCancelled {
+			return nil
+		}
+		_, err = s.appointments.Update(id, domain.Appointment{Status: domain.StatusCancelled, Version: a.Version})
+		return err
+	}
+
+	_, current, err := s.blockResource(d, name)
+	if err != nil {
+		return err
+	}
+	if ifMatch != "" && ifMatch != current.ETag {
+		return ErrPreconditionFailed
+	}
+	return s.appointments.DeleteBlock(d.Registration, name)
+}
+
+func (s *service) Changes(dentistID int, syncToken string) (Changes, error) {
+	d, err := s.dentist(dentistID)
+	if err != nil {
+		return Changes{}, err
+	}
+	var previous map[string]string
+	if syncToken != "" {
+		if !strings.HasPrefix(syncToken, SyncTokenPrefix) {
+			return Changes{}, ErrInvalidSyncToken
+		}
+		previous, err = s.r.GetState(dentistID, strings.TrimPrefix(syncToken, SyncTokenPrefix))
+		if errors.Is(err, store.ErrNotFound) {
+			return Changes{}, ErrInvalidSyncToken
+		}
+		if err != nil {
+			return Changes{}, err
+		}
+	}
+
+	resources, err := s.resources(d)
+	if err != nil {
+		return Changes{}, err
+	}
+	token, err := s.saveState(dentistID, resources)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	changes := Changes{SyncToken: SyncTokenPrefix + token}
+	current := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		current[resource.Name] = true
+		if previous == nil || previous[resource.Name] != resource.ETag {
+			changes.Changed = append(changes.Changed, resource)
+		}
+	}
+	for name := range previous {
+		if !current[name] {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	sort.Strings(changes.Removed)
+	return changes, nil
+}
+
+func (s *service) dentist(dentistID int) (domain.Dentist, error) {
+	dInterface, err := s.dentists.GetByID(dentistID)
+	if err != nil {
+		return domain.Dentist{}, ErrNotFound
+	}
+	d, ok := dInterface.(domain.Dentist)
+	if !ok || d.Id == 0 {
+		return domain.Dentist{}, ErrNotFound
+	}
+	return d, nil
+}
+
+// resources gera os eventos das consultas e dos horários bloqueados do dentista
+func (s *service) resources(d domain.Dentist) ([]Resource, error) {
+	appointments, err := s.appointments.GetByDentistRegistration(d.Registration)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.appointments.GetBlocks(d.Registration)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(appointments)+len(blocks))
+	for _, a := range appointments {
+		e, err := calendar.AppointmentEvent(domain.FeedDentist, a, s.location)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, s.render(appointmentName(a.Id), e))
+	}
+	for _, b := range blocks {
+		e, err := s.blockEvent(b)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, s.render(b.Href, e))
+	}
+	return resources, nil
+}
+
+func (s *service) appointmentResource(d domain.Dentist, id int) (domain.AppointmentDTO, Resource, error) {
+	a, err := s.appointments.GetByID(id)
+	if err != nil || a.Id == 0 || a.IdDentist != d.Registration {
+		return domain.AppointmentDTO{}, Resource{}, ErrNotFound
+	}
+	e, err := calendar.AppointmentEvent(domain.FeedDentist, a, s.location)
+	if err != nil {
+		return domain.AppointmentDTO{}, Resource{}, err
+	}
+	return a, s.render(appointmentName(id), e), nil
+}
+
+func (s *service) blockResource(d domain.Dentist, name string) (domain.ScheduleBlock, Resource, error) {
+	b, err := s.appointments.GetBlock(d.Registration, name)
+	if errors.Is(err, appointment.ErrBlockNotFound) {
+		return domain.ScheduleBlock{}, Resource{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.ScheduleBlock{}, Resource{}, err
+	}
+	e, err := s.blockEvent(b)
+	if err != nil {
+		return domain.ScheduleBlock{}, Resource{}, err
+	}
+	return b, s.render(name, e), nil
+}
+
+func (s *service) blockEvent(b domain.ScheduleBlock) (ical.Event, error) {
+	start, err := time.ParseInLocation(appointment.DateLayout, b.Start, s.location)
+	if err != nil {
+		return ical.Event{}, err
+	}
+	end, err := time.ParseInLocation(appointment.DateLayout, b.End, s.location)
+	if err != nil {
+		return ical.Event{}, err
+	}
+	summary := b.Summary
+	if summary == "" {
+		summary = "Horário bloqueado"
+	}
+	return ical.Event{UID: b.UID, Start: start, End: end, Summary: summary, Status: ical.StatusConfirmed}, nil
+}
+
+// render serializa o evento como um objeto de calendário, cuja ETag é o hash do conteúdo
+func (s *service) render(name string, e ical.Event) Resource {
+	data := ical.Calendar{ProdID: calendar.ProdID, Location: s.location, Events: []ical.Event{e}}.Encode(stamp)
+	sum := sha256.Sum256(data)
+	return Resource{
+		Name:  name,
+		ETag:  `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Data:  data,
+		Start: e.Start,
+		End:   e.End,
+	}
+}
+
+// saveState registra as ETags atuais e retorna o token que as identifica. O token é o hash do
+// estado, então a agenda mantém o mesmo token enquanto nada muda.
+func (s *service) saveState(dentistID int, resources []Resource) (string, error) {
+	state := make(map[string]string, len(resources))
+	lines := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		state[resource.Name] = resource.ETag
+		lines = append(lines, resource.Name+" "+resource.ETag)
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	token := hex.EncodeToString(sum[:])
+	if err := s.r.SaveState(dentistID, token, state, time.Now().Add(-syncStateTTL)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func appointmentName(id int) string {
+	return appointmentPrefix + strconv.Itoa(id) + ".ics"
+}
+
+// appointmentID extrai o id da consulta do nome do recurso
+func appointmentID(name string) (int, bool) {
+	if !strings.HasPrefix(name, appointmentPrefix) || !strings.HasSuffix(name, ".ics") {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, appointmentPrefix), ".ics"))
+	return id, err == nil
+}
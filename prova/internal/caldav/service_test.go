@@ -0,0 +1,91 @@
+package caldav
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// fakeAppointments guarda uma consulta e as alterações recebidas; os métodos não usados pelos
+// testes ficam com a interface nula
+type fakeAppointments struct {
+	appointment.Service
+	current domain.AppointmentDTO
+	updates []domain.Appointment
+}
+
+func (f *fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	if id != f.current.Id {
+		return domain.AppointmentDTO{}, errors.New("not found")
+	}
+	return f.current, nil
+}
+
+func (f *fakeAppointments) Update(id int, a domain.Appointment) (domain.AppointmentDTO, error) {
+	f.updates = append(f.updates, a)
+	return f.current, nil
+}
+
+type fakeDentists struct {
+	dentist.Service
+}
+
+func (fakeDentists) GetByID(id int) (interface{}, error) {
+	return domain.Dentist{Id: id, Registration: "CRO-1", Name: "Ana"}, nil
+}
+
+func newTestService() (Service, *fakeAppointments) {
+	appointments := &fakeAppointments{current: domain.AppointmentDTO{
+		Appointment: domain.Appointment{
+			Id:              3,
+			Description:     "limpeza",
+			AppointmentDate: "04/03/2024 09:00",
+			IdDentist:       "CRO-1",
+			IdPatient:       "123",
+			Status:          domain.StatusScheduled,
+			Duration:        30,
+			Version:         5,
+		},
+		Dentist: domain.Dentist{Id: 7, Registration: "CRO-1", Name: "Ana"},
+		Patient: domain.Patient{Id: 1, Document: "123", Name: "Bia"},
+	}}
+	return NewService(nil, appointments, fakeDentists{}, time.UTC), appointments
+}
+
+// TestChangesCarryTheVersion confere que PUT e DELETE de consultas passam a versão conferida pela
+// ETag para o serviço, para que uma alteração feita entre a leitura e a gravação seja recusada
+func TestChangesCarryTheVersion(t *testing.T) {
+	s, appointments := newTestService()
+	name := appointmentName(3)
+	resource, err := s.Resource(7, name)
+	if err != nil {
+		t.Fatalf("Resource: %v", err)
+	}
+
+	data := bytes.Replace(resource.Data, []byte("T090000"), []byte("T083000"), 1)
+	if _, err := s.Put(7, name, data, resource.ETag, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := s.Put(7, name, data, `"stale"`, ""); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Put with a stale ETag = %v, want ErrPreconditionFailed", err)
+	}
+	if err := s.Delete(7, name, ""); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if len(appointments.updates) != 2 {
+		t.Fatalf("got %d updates, want 2", len(appointments.updates))
+	}
+	moved, cancelled := appointments.updates[0], appointments.updates[1]
+	if moved.Version != 5 || moved.AppointmentDate != "04/03/2024 08:30" {
+		t.Errorf("put update = %+v, want 04/03/2024 08:30 at version 5", moved)
+	}
+	if cancelled.Version != 5 || cancelled.Status != domain.StatusCancelled {
+		t.Errorf("delete update = %+v, want cancelled at version 5", cancelled)
+	}
+}
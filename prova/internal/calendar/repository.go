@@ -0,0 +1,29 @@
+package calendar
+
+import (
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetToken retorna o hash do token de assinatura de um calendário
+	GetToken(owner string, ownerID int) (string, error)
+	// SaveToken grava o hash do token de assinatura, substituindo o anterior
+	SaveToken(owner string, ownerID int, tokenHash string) error
+}
+
+type repository struct {
+	store store.CalendarStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.CalendarStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetToken(owner string, ownerID int) (string, error) {
+	return r.store.GetFeedToken(owner, ownerID)
+}
+
+func (r *repository) SaveToken(owner string, ownerID int, tokenHash string) error {
+	return r.store.SaveFeedToken(owner, ownerID, tokenHash)
+}
@@ -0,0 +1,202 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/ical"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// ProdID identifica o sistema que gerou o calendário
+const ProdID = "-//meirafa//prova2-golang//PT"
+
+// uidDomain completa o UID das consultas, que não muda entre gerações do calendário
+const uidDomain = "prova2-golang"
+
+var (
+	// ErrNotFound indica que o dono do calendário não existe ou que o token não confere
+	ErrNotFound = errors.New("calendar not found")
+	// ErrInvalidOwner indica que o dono informado não é dentista nem paciente
+	ErrInvalidOwner = errors.New("invalid calendar owner")
+	// ErrForbidden indica que o usuário não é administrador nem o dentista dono da agenda
+	ErrForbidden = errors.New("only an admin or the dentist who owns the calendar can issue its feed")
+)
+
+type Service interface {
+	// Issue emite um novo token de assinatura para o calendário, invalidando o anterior. Só
+	// administradores emitem qualquer calendário; dentistas emitem apenas o da própria agenda.
+	Issue(caller auth.Claims, owner string, ownerID int) (domain.CalendarFeed, error)
+	// Feed valida o token e gera o calendário com as consultas do dentista ou paciente
+	Feed(owner string, ownerID int, token string) ([]byte, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	dentists     dentist.Service
+	patients     patient.Service
+	location     *time.Location
+	baseURL      string
+}
+
+// NewService cria um novo serviço. As datas das consultas são interpretadas no fuso informado,
+// que também é publicado no calendário.
+func NewService(r Repository, appointments appointment.Service, dentists dentist.Service, patients patient.Service, location *time.Location, baseURL string) Service {
+	return &service{r, appointments, dentists, patients, location, strings.TrimRight(baseURL, "/")}
+}
+
+func (s *service) Issue(caller auth.Claims, owner string, ownerID int) (domain.CalendarFeed, error) {
+	if !canIssue(caller, owner, ownerID) {
+		return domain.CalendarFeed{}, ErrForbidden
+	}
+	if _, err := s.calendar(owner, ownerID); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	token := hex.EncodeToString(buffer)
+	if err := s.r.SaveToken(owner, ownerID, hashToken(token)); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	return domain.CalendarFeed{
+		Owner:   owner,
+		IdOwner: ownerID,
+		Token:   token,
+		URL:     fmt.Sprintf("%s/api/%ss/%d/calendar.ics?token=%s", s.baseURL, owner, ownerID, token),
+	}, nil
+}
+
+func (s *service) Feed(owner string, ownerID int, token string) ([]byte, error) {
+	if owner != domain.FeedDentist && owner != domain.FeedPatient {
+		return nil, ErrInvalidOwner
+	}
+	stored, err := s.r.GetToken(owner, ownerID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(stored)) != 1 {
+		return nil, ErrNotFound
+	}
+	cal, err := s.calendar(owner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return cal.Encode(time.Now()), nil
+}
+
+// canIssue indica se o usuário pode emitir o token do calendário. Como cada emissão invalida o
+// endereço anterior, um usuário qualquer poderia derrubar a assinatura de outro.
+func canIssue(caller auth.Claims, owner string, ownerID int) bool {
+	switch caller.Role {
+	case domain.RoleAdmin:
+		return true
+	case domain.RoleDentist:
+		return owner == domain.FeedDentist && caller.DentistID == ownerID
+	}
+	return false
+}
+
+// calendar monta o calendário com as consultas do dentista ou paciente
+func (s *service) calendar(owner string, ownerID int) (ical.Calendar, error) {
+	var name string
+	var appointments []domain.AppointmentDTO
+	switch owner {
+	case domain.FeedDentist:
+		dInterface, err := s.dentists.GetByID(ownerID)
+		if err != nil {
+			return ical.Calendar{}, ErrNotFound
+		}
+		d, ok := dInterface.(domain.Dentist)
+		if !ok || d.Id == 0 {
+			return ical.Calendar{}, ErrNotFound
+		}
+		name = "Agenda - " + d.Name + " " + d.Surname
+		if appointments, err = s.appointments.GetByDentistRegistration(d.Registration); err != nil {
+			return ical.Calendar{}, err
+		}
+	case domain.FeedPatient:
+		p, err := s.patients.GetByID(ownerID)
+		if err != nil || p.Id == 0 {
+			return ical.Calendar{}, ErrNotFound
+		}
+		name = "Consultas - " + p.Name + " " + p.Surname
+		if appointments, err = s.appointments.GetByDocumentPatient(p.Document); err != nil {
+			return ical.Calendar{}, err
+		}
+	default:
+		return ical.Calendar{}, ErrInvalidOwner
+	}
+
+	cal := ical.Calendar{ProdID: ProdID, Name: name, Location: s.location}
+	for _, a := range appointments {
+		e, err := AppointmentEvent(owner, a, s.location)
+		if err != nil {
+			return ical.Calendar{}, err
+		}
+		cal.Events = append(cal.Events, e)
+	}
+	return cal, nil
+}
+
+// AppointmentEvent converte uma consulta em evento, com o resumo voltado ao dono do calendário.
+// Consultas canceladas continuam no calendário com STATUS:CANCELLED e uma nova sequência, para
+// que os clientes as removam da agenda.
+func AppointmentEvent(owner string, a domain.AppointmentDTO, location *time.Location) (ical.Event, error) {
+	start, err := time.ParseInLocation(appointment.DateLayout, a.AppointmentDate, location)
+	if err != nil {
+		return ical.Event{}, err
+	}
+	duration := a.Duration
+	if duration == 0 {
+		duration = appointment.DefaultDuration
+	}
+
+	e := ical.Event{
+		UID:         AppointmentUID(a.Id),
+		Start:       start,
+		End:         start.Add(time.Duration(duration) * time.Minute),
+		Description: a.Description,
+		Status:      ical.StatusConfirmed,
+	}
+	if owner == domain.FeedDentist {
+		e.Summary = "Consulta - " + a.Patient.Name + " " + a.Patient.Surname
+	} else {
+		e.Summary = "Consulta - Dr(a). " + a.Dentist.Name + " " + a.Dentist.Surname
+	}
+	switch a.Status {
+	case domain.StatusScheduled:
+		e.Status = ical.StatusTentative
+	case domain.StatusCancelled:
+		e.Status = ical.StatusCancelled
+		e.Sequence = 1
+	}
+	return e, nil
+}
+
+// AppointmentUID retorna o UID da consulta, o mesmo em todos os calendários
+func AppointmentUID(id int) string {
+	return fmt.Sprintf("appointment-%d@%s", id, uidDomain)
+}
+
+// hashToken guarda apenas o hash do token, de modo que uma cópia do banco não dê acesso aos calendários
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,187 @@
+package calendar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// fakeRepository guarda o hash dos tokens em memória
+type fakeRepository struct {
+	tokens map[string]string
+}
+
+func (r *fakeRepository) GetToken(owner string, ownerID int) (string, error) {
+	hash, ok := r.tokens[fmt.Sprintf("%s/%d", owner, ownerID)]
+	if !ok {
+		return "", store.ErrNotFound
+	}
+	return hash, nil
+}
+
+func (r *fakeRepository) SaveToken(owner string, ownerID int, tokenHash string) error {
+	r.tokens[fmt.Sprintf("%s/%d", owner, ownerID)] = tokenHash
+	return nil
+}
+
+// fakeAppointments tem uma consulta do dentista 7 com o paciente 1
+type fakeAppointments struct {
+	appointment.Service
+}
+
+var booked = []domain.AppointmentDTO{{
+	Appointment: domain.Appointment{Id: 3, Description: "limpeza", AppointmentDate: "04/03/2024 09:00", IdDentist: "CRO-7", IdPatient: "123", Status: domain.StatusScheduled, Duration: 30},
+	Dentist:     domain.Dentist{Id: 7, Registration: "CRO-7", Name: "Ana"},
+	Patient:     domain.Patient{Id: 1, Document: "123", Name: "Bia"},
+}}
+
+func (fakeAppointments) GetByDentistRegistration(registration string) ([]domain.AppointmentDTO, error) {
+	return booked, nil
+}
+
+func (fakeAppointments) GetByDocumentPatient(document string) ([]domain.AppointmentDTO, error) {
+	return booked, nil
+}
+
+type fakeDentists struct {
+	dentist.Service
+}
+
+func (fakeDentists) GetByID(id int) (interface{}, error) {
+	if id != 7 && id != 8 {
+		return nil, store.ErrNotFound
+	}
+	return domain.Dentist{Id: id, Registration: fmt.Sprintf("CRO-%d", id), Name: "Ana"}, nil
+}
+
+type fakePatients struct {
+	patient.Service
+}
+
+func (fakePatients) GetByID(id int) (domain.Patient, error) {
+	if id != 1 {
+		return domain.Patient{}, store.ErrNotFound
+	}
+	return domain.Patient{Id: 1, Document: "123", Name: "Bia"}, nil
+}
+
+var (
+	admin     = auth.Claims{UserID: 1, Role: domain.RoleAdmin}
+	reception = auth.Claims{UserID: 2, Role: domain.RoleReception}
+	ana       = auth.Claims{UserID: 3, Role: domain.RoleDentist, DentistID: 7}
+	bia       = auth.Claims{UserID: 4, Role: domain.RoleDentist, DentistID: 8}
+)
+
+func newTestService() Service {
+	return NewService(&fakeRepository{tokens: map[string]string{}}, fakeAppointments{}, fakeDentists{}, fakePatients{}, time.UTC, "http://clinic.test/")
+}
+
+func TestIssue(t *testing.T) {
+	s := newTestService()
+	tests := []struct {
+		name    string
+		caller  auth.Claims
+		owner   string
+		ownerID int
+		want    error
+	}{
+		{"admin issues a dentist calendar", admin, domain.FeedDentist, 7, nil},
+		{"admin issues a patient calendar", admin, domain.FeedPatient, 1, nil},
+		{"dentist issues the own calendar", ana, domain.FeedDentist, 7, nil},
+		{"dentist issues another dentist calendar", bia, domain.FeedDentist, 7, ErrForbidden},
+		{"dentist issues a patient calendar", ana, domain.FeedPatient, 1, ErrForbidden},
+		{"reception issues a dentist calendar", reception, domain.FeedDentist, 7, ErrForbidden},
+		{"reception issues a patient calendar", reception, domain.FeedPatient, 1, ErrForbidden},
+		{"admin issues a missing dentist calendar", admin, domain.FeedDentist, 9, ErrNotFound},
+		{"admin issues an unknown owner", admin, "clinic", 1, ErrInvalidOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := s.Issue(tt.caller, tt.owner, tt.ownerID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Issue = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			want := fmt.Sprintf("http://clinic.test/api/%ss/%d/calendar.ics?token=%s", tt.owner, tt.ownerID, feed.Token)
+			if feed.Token == "" || feed.URL != want {
+				t.Errorf("feed = %+v, want the url %s", feed, want)
+			}
+		})
+	}
+}
+
+func TestFeed(t *testing.T) {
+	s := newTestService()
+
+	_, err := s.Feed(domain.FeedDentist, 7, "")
+	wantErr(t, "Feed before issuing", err, ErrNotFound)
+
+	feed, err := s.Issue(ana, domain.FeedDentist, 7)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	body, err := s.Feed(domain.FeedDentist, 7, feed.Token)
+	if err != nil {
+		t.Fatalf("Feed: %v", err)
+	}
+	if !bytes.Contains(body, []byte("BEGIN:VCALENDAR")) || !bytes.Contains(body, []byte("BEGIN:VEVENT")) {
+		t.Errorf("feed = %s, want a calendar with the appointment", body)
+	}
+
+	_, err = s.Feed(domain.FeedDentist, 7, "")
+	wantErr(t, "Feed with an empty token", err, ErrNotFound)
+	_, err = s.Feed(domain.FeedDentist, 7, feed.Token+"0")
+	wantErr(t, "Feed with a wrong token", err, ErrNotFound)
+	_, err = s.Feed(domain.FeedDentist, 8, feed.Token)
+	wantErr(t, "Feed of another dentist with the token", err, ErrNotFound)
+	_, err = s.Feed(domain.FeedPatient, 7, feed.Token)
+	wantErr(t, "Feed of a patient with a dentist token", err, ErrNotFound)
+	_, err = s.Feed("clinic", 7, feed.Token)
+	wantErr(t, "Feed of an unknown owner", err, ErrInvalidOwner)
+}
+
+func TestRotationInvalidatesTheOldToken(t *testing.T) {
+	s := newTestService()
+	old, err := s.Issue(ana, domain.FeedDentist, 7)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// outro usuário não consegue trocar o endereço da agenda
+	_, err = s.Issue(bia, domain.FeedDentist, 7)
+	wantErr(t, "Issue by another dentist", err, ErrForbidden)
+	if _, err := s.Feed(domain.FeedDentist, 7, old.Token); err != nil {
+		t.Errorf("Feed after a refused rotation = %v, want the old token still valid", err)
+	}
+
+	rotated, err := s.Issue(admin, domain.FeedDentist, 7)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if rotated.Token == old.Token {
+		t.Fatal("rotation returned the same token")
+	}
+	_, err = s.Feed(domain.FeedDentist, 7, old.Token)
+	wantErr(t, "Feed with the rotated token", err, ErrNotFound)
+	if _, err := s.Feed(domain.FeedDentist, 7, rotated.Token); err != nil {
+		t.Errorf("Feed with the new token = %v", err)
+	}
+}
+
+func wantErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s = %v, want %v", what, err, want)
+	}
+}
@@ -0,0 +1,68 @@
+package chart
+
+import (
+	"fmt"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// surfaceConditions são condições registradas por face do dente
+var surfaceConditions = map[string]bool{
+	domain.ConditionHealthy:     true,
+	domain.ConditionCaries:      true,
+	domain.ConditionRestoration: true,
+	domain.ConditionSealant:     true,
+	domain.ConditionFracture:    true,
+}
+
+// toothConditions são condições que se aplicam ao dente inteiro
+var toothConditions = map[string]bool{
+	domain.ConditionHealthy:             true,
+	domain.ConditionCrown:               true,
+	domain.ConditionMissing:             true,
+	domain.ConditionImplant:             true,
+	domain.ConditionRootCanal:           true,
+	domain.ConditionExtractionIndicated: true,
+}
+
+var surfaces = map[string]bool{
+	domain.SurfaceMesial:   true,
+	domain.SurfaceDistal:   true,
+	domain.SurfaceOcclusal: true,
+	domain.SurfaceIncisal:  true,
+	domain.SurfaceBuccal:   true,
+	domain.SurfaceLingual:  true,
+}
+
+// IsValidTooth indica se o número segue a notação FDI: 11–48 para dentes permanentes e 51–85 para decíduos
+func IsValidTooth(tooth int) bool {
+	quadrant, position := tooth/10, tooth%10
+	switch {
+	case quadrant >= 1 && quadrant <= 4:
+		return position >= 1 && position <= 8
+	case quadrant >= 5 && quadrant <= 8:
+		return position >= 1 && position <= 5
+	default:
+		return false
+	}
+}
+
+// validateCondition verifica o dente, a face e se a condição é compatível com ela
+func validateCondition(c domain.ToothCondition) error {
+	if !IsValidTooth(c.Tooth) {
+		return fmt.Errorf("%w: tooth %d is not a valid FDI number", ErrInvalidChange, c.Tooth)
+	}
+	if c.Surface == domain.SurfaceWholeTooth {
+		if !toothConditions[c.Condition] {
+			return fmt.Errorf("%w: condition %q requires a surface", ErrInvalidChange, c.Condition)
+		}
+		return nil
+	}
+	if !surfaces[c.Surface] {
+		return fmt.Errorf("%w: surface %q must be one of M, D, O, I, B or L", ErrInvalidChange, c.Surface)
+	}
+	if !surfaceConditions[c.Condition] {
+		return fmt.Errorf("%w: condition %q applies to the whole tooth, omit the surface", ErrInvalidChange, c.Condition)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package chart
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+func TestIsValidTooth(t *testing.T) {
+	valid := []int{11, 18, 21, 28, 31, 38, 41, 48, 51, 55, 61, 65, 71, 75, 81, 85}
+	for _, tooth := range valid {
+		if !IsValidTooth(tooth) {
+			t.Errorf("IsValidTooth(%d) = false, want true", tooth)
+		}
+	}
+	invalid := []int{0, 1, 9, 10, 19, 20, 49, 50, 56, 59, 86, 90, 91, 100, -11}
+	for _, tooth := range invalid {
+		if IsValidTooth(tooth) {
+			t.Errorf("IsValidTooth(%d) = true, want false", tooth)
+		}
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	cases := []struct {
+		name  string
+		c     domain.ToothCondition
+		valid bool
+	}{
+		{"caries on a surface", domain.ToothCondition{Tooth: 16, Surface: domain.SurfaceOcclusal, Condition: domain.ConditionCaries}, true},
+		{"crown on the whole tooth", domain.ToothCondition{Tooth: 11, Condition: domain.ConditionCrown}, true},
+		{"invalid tooth", domain.ToothCondition{Tooth: 19, Condition: domain.ConditionCrown}, false},
+		{"caries without surface", domain.ToothCondition{Tooth: 16, Condition: domain.ConditionCaries}, false},
+		{"crown on a surface", domain.ToothCondition{Tooth: 16, Surface: domain.SurfaceMesial, Condition: domain.ConditionCrown}, false},
+		{"unknown surface", domain.ToothCondition{Tooth: 16, Surface: "X", Condition: domain.ConditionCaries}, false},
+	}
+	for _, c := range cases {
+		err := validateCondition(c.c)
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+		if !c.valid && !errors.Is(err, ErrInvalidChange) {
+			t.Errorf("%s: error = %v, want ErrInvalidChange", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package chart
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetEntries retorna o histórico do odontograma de um paciente
+	GetEntries(patientID int) ([]domain.ChartEntry, error)
+	// SaveEntries registra novas alterações no odontograma
+	SaveEntries(entries []domain.ChartEntry) error
+}
+
+type repository struct {
+	store store.ChartStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.ChartStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetEntries(patientID int) ([]domain.ChartEntry, error) {
+	return r.store.GetChartEntries(patientID)
+}
+
+func (r *repository) SaveEntries(entries []domain.ChartEntry) error {
+	return r.store.SaveChartEntries(entries)
+}
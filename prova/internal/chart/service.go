@@ -0,0 +1,146 @@
+package chart
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+)
+
+var (
+	// ErrNotFound indica que o paciente ou a consulta não existe
+	ErrNotFound = errors.New("patient or appointment not found")
+	// ErrForbidden indica que apenas o dentista da consulta pode alterar o odontograma
+	ErrForbidden = errors.New("only the dentist of the appointment can change the chart")
+	// ErrInvalidChange indica que alguma alteração do odontograma é inválida
+	ErrInvalidChange = errors.New("invalid chart change")
+)
+
+type Service interface {
+	// Get retorna o odontograma atual do paciente
+	Get(patientID int) (domain.DentalChart, error)
+	// History retorna o histórico de alterações, opcionalmente filtrado por dente
+	History(patientID, tooth int) ([]domain.ChartEntry, error)
+	// Update registra alterações no odontograma durante uma consulta
+	Update(caller auth.Claims, patientID, appointmentID int, changes []domain.ToothCondition) (domain.DentalChart, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	patients     patient.Service
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, appointments appointment.Service, patients patient.Service) Service {
+	return &service{r, appointments, patients}
+}
+
+func (s *service) Get(patientID int) (domain.DentalChart, error) {
+	entries, err := s.History(patientID, 0)
+	if err != nil {
+		return domain.DentalChart{}, err
+	}
+	return currentChart(patientID, entries), nil
+}
+
+func (s *service) History(patientID, tooth int) ([]domain.ChartEntry, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return nil, ErrNotFound
+	}
+	entries, err := s.r.GetEntries(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if tooth == 0 {
+		return entries, nil
+	}
+	filtered := []domain.ChartEntry{}
+	for _, entry := range entries {
+		if entry.Tooth == tooth {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+func (s *service) Update(caller auth.Claims, patientID, appointmentID int, changes []domain.ToothCondition) (domain.DentalChart, error) {
+	if len(changes) == 0 {
+		return domain.DentalChart{}, ErrInvalidChange
+	}
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil || a.Id == 0 || a.Patient.Id != patientID {
+		return domain.DentalChart{}, ErrNotFound
+	}
+	if caller.Role != domain.RoleDentist || caller.DentistID != a.Dentist.Id {
+		return domain.DentalChart{}, ErrForbidden
+	}
+
+	entries := make([]domain.ChartEntry, 0, len(changes))
+	for _, change := range changes {
+		if err := validateCondition(change); err != nil {
+			return domain.DentalChart{}, err
+		}
+		entries = append(entries, domain.ChartEntry{
+			IdPatient:     patientID,
+			IdAppointment: appointmentID,
+			IdDentist:     caller.DentistID,
+			Tooth:         change.Tooth,
+			Surface:       change.Surface,
+			Condition:     change.Condition,
+			Notes:         change.Notes,
+		})
+	}
+	if err := s.r.SaveEntries(entries); err != nil {
+		return domain.DentalChart{}, err
+	}
+	return s.Get(patientID)
+}
+
+// currentChart reconstrói o estado atual do odontograma a partir do histórico.
+// Uma condição do dente inteiro substitui as condições registradas antes nas suas faces,
+// e a condição "healthy" remove o registro do dente ou da face.
+func currentChart(patientID int, entries []domain.ChartEntry) domain.DentalChart {
+	type key struct {
+		tooth   int
+		surface string
+	}
+	current := make(map[key]domain.ToothCondition)
+	chart := domain.DentalChart{IdPatient: patientID, Teeth: []domain.ToothCondition{}}
+
+	for _, entry := range entries {
+		if entry.Surface == domain.SurfaceWholeTooth {
+			for k := range current {
+				if k.tooth == entry.Tooth {
+					delete(current, k)
+				}
+			}
+		}
+		k := key{entry.Tooth, entry.Surface}
+		if entry.Condition == domain.ConditionHealthy {
+			delete(current, k)
+		} else {
+			current[k] = domain.ToothCondition{
+				Tooth:     entry.Tooth,
+				Surface:   entry.Surface,
+				Condition: entry.Condition,
+				Notes:     entry.Notes,
+			}
+		}
+		chart.UpdatedAt = entry.RecordedAt
+	}
+
+	for _, condition := range current {
+		chart.Teeth = append(chart.Teeth, condition)
+	}
+	sort.Slice(chart.Teeth, func(i, j int) bool {
+		if chart.Teeth[i].Tooth != chart.Teeth[j].Tooth {
+			return chart.Teeth[i].Tooth < chart.Teeth[j].Tooth
+		}
+		return chart.Teeth[i].Surface < chart.Teeth[j].Surface
+	})
+	return chart
+}
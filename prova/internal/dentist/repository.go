@@ -1,8 +1,8 @@
 package dentist
 
 import (
+	"context"
 	"errors"
-	"log"
 
 	"github.com/meirafa/prova2-golang/internal/domain"
 	"github.com/meirafa/prova2-golang/pkg/store"
@@ -10,6 +10,9 @@ import (
 
 var table = "dentists"
 
+// ErrRegistrationExists indica que já existe um dentista com o mesmo número do CRO
+var ErrRegistrationExists = errors.New("license number already exists on database")
+
 type Repository interface {
 	//GetAll retorna todos os dentistas (dentist) cadastrados
 	GetAll() (interface{}, error)
@@ -21,6 +24,14 @@ type Repository interface {
 	Update(id int, d domain.Dentist) (interface{}, error)
 	//Delete exclui um dentista
 	Delete(id int) error
+	// Batch grava os dentistas numa única transação, incluindo os que não têm id
+	Batch(dentists []domain.Dentist) ([]interface{}, error)
+	// GetByIDs retorna os dentistas com os ids informados, numa única consulta
+	GetByIDs(ctx context.Context, ids []int) ([]domain.Dentist, error)
+	// GetByRegistrations retorna os dentistas com os números do CRO informados, numa única consulta
+	GetByRegistrations(ctx context.Context, registrations []string) ([]domain.Dentist, error)
+	// GetPage retorna uma página dos dentistas, por id
+	GetPage(ctx context.Context, page domain.Page) ([]domain.Dentist, error)
 }
 
 type repository struct {
@@ -41,36 +52,27 @@ func (r *repository) GetByID(id int) (interface{}, error) {
 }
 
 func (r *repository) Create(d domain.Dentist) (interface{}, error) {
-	if !r.validateRegistration(d.Registration) {
-		return nil, errors.New("license number already exists on database")
+	saved, err := r.store.Save(d, table)
+	if errors.Is(err, store.ErrDuplicate) {
+		return nil, ErrRegistrationExists
 	}
-	return r.store.Save(d, table)
+	return saved, err
 }
 
 func (r *repository) Update(id int, d domain.Dentist) (interface{}, error) {
-	var dentists []domain.Dentist
-	dentistsInterface, err := r.GetAll()
+	dentist, err := r.GetByID(id)
 	if err != nil {
-		log.Fatalln("error while trying to fetch data from db")
 		return nil, err
 	}
-	dentists, ok := dentistsInterface.([]domain.Dentist)
-	if !ok {
-
-		return nil, err
+	if dentist == nil {
+		return nil, errors.New("dentist not found")
 	}
 
-	for _, dentist := range dentists {
-		if dentist.Id == id {
-
-			if !r.validateRegistration(d.Registration) && d.Registration != dentist.Registration {
-				return nil, errors.New("license number already exists")
-			}
-
-			return r.store.Update(id, d, table)
-		}
+	updated, err := r.store.Update(id, d, table)
+	if errors.Is(err, store.ErrDuplicate) {
+		return nil, ErrRegistrationExists
 	}
-	return nil, errors.New("dentist not found")
+	return updated, err
 }
 
 func (r *repository) Delete(id int) error {
@@ -78,23 +80,36 @@ func (r *repository) Delete(id int) error {
 	return r.store.Delete(id, table)
 }
 
-func (r *repository) validateRegistration(Registration string) bool {
-
-	var dentists []domain.Dentist
-	dentistsInterface, err := r.GetAll()
-	if err != nil {
-		return false
+func (r *repository) Batch(dentists []domain.Dentist) ([]interface{}, error) {
+	ops := make([]store.BatchOp, 0, len(dentists))
+	for _, d := range dentists {
+		ops = append(ops, store.BatchOp{Id: d.Id, Entity: d})
 	}
-	dentists, ok := dentistsInterface.([]domain.Dentist)
-	if !ok {
-		return false
+	saved, err := r.store.Batch(table, ops)
+	var batchErr *store.BatchError
+	if errors.As(err, &batchErr) && errors.Is(batchErr.Err, store.ErrDuplicate) {
+		return nil, &store.BatchError{Index: batchErr.Index, Err: ErrRegistrationExists}
 	}
+	return saved, err
+}
 
-	for _, dentist := range dentists {
+func (r *repository) GetByIDs(ctx context.Context, ids []int) ([]domain.Dentist, error) {
+	return dentists(r.store.GetByIDs(ctx, ids, table))
+}
+
+func (r *repository) GetByRegistrations(ctx context.Context, registrations []string) ([]domain.Dentist, error) {
+	return dentists(r.store.GetByKeys(ctx, registrations, table))
+}
 
-		if dentist.Registration == Registration {
-			return false
-		}
+func (r *repository) GetPage(ctx context.Context, page domain.Page) ([]domain.Dentist, error) {
+	return dentists(r.store.GetPage(ctx, page, table))
+}
+
+// dentists converte o resultado das buscas em lote do store
+func dentists(found interface{}, err error) ([]domain.Dentist, error) {
+	if err != nil {
+		return nil, err
 	}
-	return true
+	list, _ := found.([]domain.Dentist)
+	return list, nil
 }
@@ -1,22 +1,32 @@
 package dentist
 
 import (
+	"context"
 	"errors"
 
 	"github.com/meirafa/prova2-golang/internal/domain"
 )
 
+// ErrMissingFields indica que o sobrenome, o nome ou o CRO não foram informados
+var ErrMissingFields = errors.New("surname, name and registration can't be empty")
+
 type Service interface {
 	//GetAll retorna todos os dentistas (dentist) cadastrados
 	GetAll() ([]domain.Dentist, error)
 	//GetByID retorna um dentista (dentist) por id
 	GetByID(id int) (interface{}, error)
+	// GetByRegistrations retorna os dentistas com os números do CRO informados numa única busca
+	GetByRegistrations(registrations []string) ([]domain.Dentist, error)
 	// Create insere um novo dentista
 	Create(d domain.Dentist) (domain.Dentist, error)
 	//Update atualiza um dentista
 	Update(id int, d domain.Dentist) (domain.Dentist, error)
 	//Delete exclui um dentista
 	Delete(id int) error
+	// Validate aplica as regras de Create, ou as de Update quando o dentista já existe, sem gravar
+	Validate(d domain.Dentist, current *domain.Dentist) (domain.Dentist, error)
+	// Batch grava os dentistas numa única transação: inclui os que não têm id e atualiza os demais
+	Batch(dentists []domain.Dentist) ([]domain.Dentist, error)
 }
 
 type service struct {
@@ -54,6 +64,12 @@ func (s *service) GetByID(id int) (interface{}, error) {
 }
 
 func (s *service) Create(d domain.Dentist) (domain.Dentist, error) {
+	registration, err := domain.ParseRegistration(d.Registration)
+	if err != nil {
+		return domain.Dentist{}, err
+	}
+	d.Registration = registration.String()
+
 	dSavedInterface, err := s.r.Create(d)
 	if err != nil {
 		return domain.Dentist{}, err
@@ -68,6 +84,20 @@ func (s *service) Create(d domain.Dentist) (domain.Dentist, error) {
 }
 
 func (s *service) Update(id int, d domain.Dentist) (domain.Dentist, error) {
+	dInterface, err := s.GetByID(id)
+	if err != nil {
+		return domain.Dentist{}, err
+	}
+	ddb := dInterface.(domain.Dentist)
+
+	mergeDentist(&d, ddb)
+	registration, err := domain.ParseRegistration(d.Registration)
+	if err != nil {
+		return domain.Dentist{}, err
+	}
+	d.Registration = registration.String()
+	d.Id = ddb.Id
+
 	dUpdatedInterface, err := s.r.Update(id, d)
 
 	if err != nil {
@@ -82,7 +112,56 @@ func (s *service) Update(id int, d domain.Dentist) (domain.Dentist, error) {
 	return domain.Dentist{}, errors.New("failed to update the dentist entry")
 }
 
+func (s *service) GetByRegistrations(registrations []string) ([]domain.Dentist, error) {
+	return s.r.GetByRegistrations(context.Background(), registrations)
+}
+
 func (s *service) Delete(id int) error {
 	return s.r.Delete(id)
 
 }
+
+func (s *service) Validate(d domain.Dentist, current *domain.Dentist) (domain.Dentist, error) {
+	if current != nil {
+		mergeDentist(&d, *current)
+		d.Id = current.Id
+	}
+	if d.Surname == "" || d.Name == "" || d.Registration == "" {
+		return domain.Dentist{}, ErrMissingFields
+	}
+	registration, err := domain.ParseRegistration(d.Registration)
+	if err != nil {
+		return domain.Dentist{}, err
+	}
+	d.Registration = registration.String()
+	return d, nil
+}
+
+func (s *service) Batch(dentists []domain.Dentist) ([]domain.Dentist, error) {
+	results, err := s.r.Batch(dentists)
+	if err != nil {
+		return nil, err
+	}
+	saved := make([]domain.Dentist, 0, len(results))
+	for _, result := range results {
+		dentist, ok := result.(domain.Dentist)
+		if !ok {
+			return nil, errors.New("failed to save the dentists batch")
+		}
+		saved = append(saved, dentist)
+	}
+	return saved, nil
+}
+
+// mergeDentist preenche os campos não informados com os valores já cadastrados
+func mergeDentist(d *domain.Dentist, ddb domain.Dentist) {
+	if d.Surname == "" {
+		d.Surname = ddb.Surname
+	}
+	if d.Name == "" {
+		d.Name = ddb.Name
+	}
+	if d.Registration == "" {
+		d.Registration = ddb.Registration
+	}
+}
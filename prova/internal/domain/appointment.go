@@ -1,9 +1,39 @@
 package domain
 
+// Situações possíveis de uma consulta
+const (
+	StatusScheduled = "scheduled"
+	StatusConfirmed = "confirmed"
+	StatusCheckedIn = "checked_in"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+	StatusNoShow    = "no_show"
+)
+
 type Appointment struct {
 	Id              int    `json:"id"`
 	Description     string `json:"description" binding:"required"`
-	AppointmentDate string `json:"appointment_date" binding:"required"`
-	IdDentist       string `json:"id_dentist" binding:"required"`
-	IdPatient       string `json:"id_patient" binding:"required"`
+	AppointmentDate string `json:"appointment_date" binding:"required" openapi:"format=appointment-date"`
+	IdDentist       string `json:"id_dentist" binding:"required" openapi:"format=registration"`
+	IdPatient       string `json:"id_patient" binding:"required" openapi:"format=document"`
+	Status          string `json:"status,omitempty" openapi:"enum=scheduled|confirmed|checked_in|completed|cancelled|no_show"`
+	ProcedureCode   string `json:"procedure_code,omitempty"`
+	Duration        int    `json:"duration,omitempty" openapi:"minimum=0"`
+	// Version cresce a cada alteração e é a ETag da consulta. Na atualização, é a versão esperada;
+	// zero atualiza sem conferir.
+	Version int `json:"version,omitempty" openapi:"minimum=0"`
+}
+
+// IsValidStatus indica se a situação informada é uma das situações de consulta conhecidas
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusScheduled, StatusConfirmed, StatusCheckedIn, StatusCompleted, StatusCancelled, StatusNoShow:
+		return true
+	}
+	return false
+}
+
+// OccupiesSchedule indica se uma consulta na situação informada ocupa a agenda do dentista
+func OccupiesSchedule(status string) bool {
+	return status != StatusCancelled && status != StatusNoShow
 }
@@ -0,0 +1,39 @@
+package domain
+
+// Ações que o paciente pode executar pelos links enviados junto com os lembretes
+const (
+	LinkConfirm = "confirm"
+	LinkCancel  = "cancel"
+)
+
+// AppointmentLinks reúne os links de confirmação e cancelamento de uma consulta
+type AppointmentLinks struct {
+	IdAppointment int    `json:"id_appointment"`
+	Confirm       string `json:"confirm"`
+	Cancel        string `json:"cancel"`
+	ExpiresAt     string `json:"expires_at"`
+}
+
+// AppointmentLink é o registro de um link emitido, usado para garantir o uso único e para auditoria
+type AppointmentLink struct {
+	Nonce          string `json:"-"`
+	IdAppointment  int    `json:"id_appointment"`
+	Action         string `json:"action"`
+	ExpiresAt      string `json:"expires_at"`
+	UsedAt         string `json:"used_at,omitempty"`
+	UsedIP         string `json:"-"`
+	UsedUserAgent  string `json:"-"`
+	PreviousStatus string `json:"-"`
+	NewStatus      string `json:"-"`
+}
+
+// LinkPreview é o que o paciente vê ao abrir um link, sem dados pessoais além do necessário
+type LinkPreview struct {
+	Action          string `json:"action"`
+	IdAppointment   int    `json:"id_appointment"`
+	AppointmentDate string `json:"appointment_date"`
+	Dentist         string `json:"dentist"`
+	Status          string `json:"status"`
+	ExpiresAt       string `json:"expires_at"`
+	UsedAt          string `json:"used_at,omitempty"`
+}
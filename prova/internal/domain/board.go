@@ -0,0 +1,24 @@
+package domain
+
+// Tipos dos eventos do painel de consultas
+const (
+	BoardCreated   = "created"
+	BoardUpdated   = "updated"
+	BoardCancelled = "cancelled"
+	BoardCheckedIn = "checked_in"
+	BoardDeleted   = "deleted"
+	// BoardReset avisa que eventos se perderam desde o Last-Event-ID informado e que o painel
+	// precisa ser recarregado pela API REST
+	BoardReset = "reset"
+)
+
+// BoardEvent é uma alteração de consulta enviada ao painel da recepção. O Id cresce a cada evento
+// e serve de Last-Event-ID na reconexão.
+type BoardEvent struct {
+	Id          int64           `json:"id"`
+	Type        string          `json:"type" openapi:"enum=created|updated|cancelled|checked_in|deleted|reset"`
+	OccurredAt  string          `json:"occurred_at,omitempty"`
+	Appointment *AppointmentDTO `json:"appointment,omitempty"`
+	// DeletedId é o id da consulta removida, nos eventos deleted
+	DeletedId int `json:"deleted_id,omitempty"`
+}
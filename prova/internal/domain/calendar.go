@@ -0,0 +1,16 @@
+package domain
+
+// Donos dos calendários assinados
+const (
+	FeedDentist = "dentist"
+	FeedPatient = "patient"
+)
+
+// CalendarFeed é o endereço secreto de assinatura do calendário de um dentista ou paciente. O token
+// só é exibido quando emitido; emitir um novo token invalida o anterior.
+type CalendarFeed struct {
+	Owner   string `json:"owner"`
+	IdOwner int    `json:"id_owner"`
+	Token   string `json:"token"`
+	URL     string `json:"url"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+type ClinicalNote struct {
+	Id            int            `json:"id"`
+	IdAppointment int            `json:"id_appointment"`
+	IdPatient     int            `json:"id_patient"`
+	IdDentist     int            `json:"id_dentist"`
+	IdParent      int            `json:"id_parent,omitempty"`
+	Content       string         `json:"content" binding:"required"`
+	CreatedAt     string         `json:"created_at"`
+	SignedAt      string         `json:"signed_at,omitempty"`
+	Addenda       []ClinicalNote `json:"addenda,omitempty"`
+}
+
+// IsSigned indica se a nota já foi assinada e, portanto, não pode mais ser alterada
+func (n ClinicalNote) IsSigned() bool {
+	return n.SignedAt != ""
+}
@@ -0,0 +1,54 @@
+package domain
+
+// Faces do dente segundo a nomenclatura odontológica
+const (
+	SurfaceMesial     = "M"
+	SurfaceDistal     = "D"
+	SurfaceOcclusal   = "O"
+	SurfaceIncisal    = "I"
+	SurfaceBuccal     = "B"
+	SurfaceLingual    = "L"
+	SurfaceWholeTooth = ""
+)
+
+// Condições registradas no odontograma
+const (
+	ConditionHealthy             = "healthy"
+	ConditionCaries              = "caries"
+	ConditionRestoration         = "restoration"
+	ConditionSealant             = "sealant"
+	ConditionFracture            = "fracture"
+	ConditionCrown               = "crown"
+	ConditionMissing             = "missing"
+	ConditionImplant             = "implant"
+	ConditionRootCanal           = "root_canal"
+	ConditionExtractionIndicated = "extraction_indicated"
+)
+
+// ToothCondition representa a condição atual de um dente ou de uma de suas faces
+type ToothCondition struct {
+	Tooth     int    `json:"tooth"`
+	Surface   string `json:"surface,omitempty"`
+	Condition string `json:"condition"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+// DentalChart representa o odontograma atual de um paciente
+type DentalChart struct {
+	IdPatient int              `json:"id_patient"`
+	Teeth     []ToothCondition `json:"teeth"`
+	UpdatedAt string           `json:"updated_at,omitempty"`
+}
+
+// ChartEntry representa uma alteração registrada no odontograma durante uma consulta
+type ChartEntry struct {
+	Id            int    `json:"id"`
+	IdPatient     int    `json:"id_patient"`
+	IdAppointment int    `json:"id_appointment"`
+	IdDentist     int    `json:"id_dentist"`
+	Tooth         int    `json:"tooth"`
+	Surface       string `json:"surface,omitempty"`
+	Condition     string `json:"condition"`
+	Notes         string `json:"notes,omitempty"`
+	RecordedAt    string `json:"recorded_at"`
+}
@@ -4,5 +4,5 @@ type Dentist struct {
 	Id           int    `json:"id"`
 	Surname      string `json:"surname" binding:"required"`
 	Name         string `json:"name" binding:"required"`
-	Registration string `json:"registration" binding:"required"`
+	Registration string `json:"registration" binding:"required" openapi:"format=registration"`
 }
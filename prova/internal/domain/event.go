@@ -0,0 +1,19 @@
+package domain
+
+// Event é um acontecimento de domínio gravado no outbox e repassado aos integradores
+type Event struct {
+	Id         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt string      `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// OutboxEvent é um evento gravado no outbox que ainda aguarda publicação
+type OutboxEvent struct {
+	Id        int    `json:"id"`
+	Event     Event  `json:"event"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+	// DeliveredSinks são os destinos que já aceitaram o evento e não o recebem de novo
+	DeliveredSinks []string `json:"delivered_sinks,omitempty"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// ExportFilter restringe os registros exportados. Datas zeradas e textos vazios não filtram; To é
+// exclusivo.
+type ExportFilter struct {
+	From    time.Time
+	To      time.Time
+	Dentist string
+	Patient string
+	Status  string
+}
@@ -0,0 +1,38 @@
+package domain
+
+// Ações tomadas para cada linha da importação
+const (
+	ImportCreate = "create"
+	ImportUpdate = "update"
+	ImportSkip   = "skip"
+)
+
+// Situações de cada linha da importação
+const (
+	ImportOK      = "ok"
+	ImportInvalid = "invalid"
+	ImportFailed  = "failed"
+)
+
+// ImportReport é o resultado da importação de uma planilha, com a situação de cada linha. Em uma
+// simulação (dry_run) nada é gravado e os totais indicam o que seria feito.
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Invalid int         `json:"invalid"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow é a situação de uma linha da planilha, numerada como no arquivo, cabeçalho incluído
+type ImportRow struct {
+	Row    int      `json:"row"`
+	Key    string   `json:"key"`
+	Action string   `json:"action,omitempty"`
+	Status string   `json:"status"`
+	Id     int      `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+// Situações de um pedido de autorização prévia ao convênio
+const (
+	AuthorizationRequested = "requested"
+	AuthorizationApproved  = "approved"
+	AuthorizationDenied    = "denied"
+)
+
+// InsurancePlan é um plano de convênio odontológico aceito pela clínica
+type InsurancePlan struct {
+	Id       int            `json:"id"`
+	Name     string         `json:"name" binding:"required"`
+	Insurer  string         `json:"insurer" binding:"required"`
+	Coverage []CoverageRule `json:"coverage"`
+}
+
+// CoverageRule define quanto do preço de um procedimento o convênio cobre
+type CoverageRule struct {
+	ProcedureCode         string  `json:"procedure_code" binding:"required"`
+	Percent               float64 `json:"percent"`
+	RequiresAuthorization bool    `json:"requires_authorization"`
+}
+
+// Membership é a adesão de um paciente a um plano de convênio
+type Membership struct {
+	Id         int    `json:"id"`
+	IdPatient  int    `json:"id_patient"`
+	IdPlan     int    `json:"id_plan" binding:"required"`
+	PlanName   string `json:"plan_name"`
+	CardNumber string `json:"card_number" binding:"required"`
+	ValidFrom  string `json:"valid_from" binding:"required"`
+	ValidUntil string `json:"valid_until,omitempty"`
+}
+
+// PreAuthorization é o pedido de autorização prévia de um procedimento ao convênio,
+// vinculado a uma consulta ou a um plano de tratamento do paciente
+type PreAuthorization struct {
+	Id              int    `json:"id"`
+	IdPatient       int    `json:"id_patient"`
+	IdMembership    int    `json:"id_membership" binding:"required"`
+	IdAppointment   int    `json:"id_appointment,omitempty"`
+	IdTreatmentPlan int    `json:"id_treatment_plan,omitempty"`
+	ProcedureCode   string `json:"procedure_code" binding:"required"`
+	Status          string `json:"status"`
+	Reference       string `json:"reference,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	RequestedAt     string `json:"requested_at"`
+	DecidedAt       string `json:"decided_at,omitempty"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+// Situações de uma fatura
+const (
+	InvoiceOpen          = "open"
+	InvoicePartiallyPaid = "partially_paid"
+	InvoicePaid          = "paid"
+	InvoiceCancelled     = "cancelled"
+)
+
+// Formas de pagamento aceitas
+const (
+	PaymentCash      = "cash"
+	PaymentCard      = "card"
+	PaymentInsurance = "insurance"
+)
+
+type Invoice struct {
+	Id             int           `json:"id"`
+	IdPatient      int           `json:"id_patient"`
+	IdMembership   int           `json:"id_membership,omitempty"`
+	Status         string        `json:"status"`
+	IssuedAt       string        `json:"issued_at"`
+	Items          []InvoiceItem `json:"items"`
+	Subtotal       float64       `json:"subtotal"`
+	Discount       float64       `json:"discount"`
+	TaxRate        float64       `json:"tax_rate"`
+	Tax            float64       `json:"tax"`
+	Total          float64       `json:"total"`
+	InsurerPortion float64       `json:"insurer_portion"`
+	PatientPortion float64       `json:"patient_portion"`
+	Paid           float64       `json:"paid"`
+	Balance        float64       `json:"balance"`
+	Payments       []Payment     `json:"payments"`
+}
+
+type InvoiceItem struct {
+	Id            int     `json:"id"`
+	IdAppointment int     `json:"id_appointment"`
+	ProcedureCode string  `json:"procedure_code"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	CoveredAmount float64 `json:"covered_amount"`
+}
+
+type Payment struct {
+	Id        int     `json:"id"`
+	IdInvoice int     `json:"id_invoice"`
+	Method    string  `json:"method" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required"`
+	PaidAt    string  `json:"paid_at"`
+}
+
+// PatientBalance resume a situação financeira de um paciente
+type PatientBalance struct {
+	IdPatient    int     `json:"id_patient"`
+	Invoiced     float64 `json:"invoiced"`
+	Paid         float64 `json:"paid"`
+	Balance      float64 `json:"balance"`
+	OpenInvoices int     `json:"open_invoices"`
+}
@@ -1,9 +1,43 @@
 package domain
 
 type Patient struct {
-	Id        int    `json:"id"`
-	Surname   string `json:"surname" binding:"required"`
-	Name      string `json:"name" binding:"required"`
-	Document  string `json:"document" binding:"required"`
-	CreatedAt string `json:"created_at" binding:"required"`
+	Id                int                   `json:"id"`
+	Surname           string                `json:"surname" binding:"required"`
+	Name              string                `json:"name" binding:"required"`
+	Document          string                `json:"document" binding:"required"`
+	CreatedAt         string                `json:"created_at" binding:"required"`
+	Email             string                `json:"email,omitempty"`
+	Phones            []string              `json:"phones,omitempty"`
+	BirthDate         string                `json:"birth_date,omitempty"`
+	Address           *Address              `json:"address,omitempty"`
+	Guardian          *Contact              `json:"guardian,omitempty"`
+	EmergencyContact  *Contact              `json:"emergency_contact,omitempty"`
+	PreferredLanguage string                `json:"preferred_language,omitempty"`
+	Consent           *CommunicationConsent `json:"consent,omitempty"`
+}
+
+// Address representa o endereço do paciente
+type Address struct {
+	Street     string `json:"street"`
+	Number     string `json:"number"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	ZipCode    string `json:"zip_code"`
+}
+
+// Contact representa um responsável legal ou contato de emergência do paciente
+type Contact struct {
+	Name         string `json:"name"`
+	Document     string `json:"document,omitempty"`
+	Phone        string `json:"phone"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+// CommunicationConsent indica por quais canais o paciente aceita ser contatado
+type CommunicationConsent struct {
+	Email    bool `json:"email"`
+	SMS      bool `json:"sms"`
+	WhatsApp bool `json:"whatsapp"`
 }
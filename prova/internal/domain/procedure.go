@@ -0,0 +1,9 @@
+package domain
+
+// Procedure representa um procedimento do catálogo da clínica
+type Procedure struct {
+	Code     string  `json:"code" binding:"required"`
+	Name     string  `json:"name" binding:"required"`
+	Duration int     `json:"duration" binding:"required" openapi:"minimum=1"`
+	Price    float64 `json:"price" openapi:"minimum=0"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RegistrationFormat descreve o formato aceito para o número do CRO
+const RegistrationFormat = "CRO-UF NNNNN (e.g. CRO-SP 12345)"
+
+// ErrInvalidRegistration indica que o número do CRO não segue o formato esperado
+var ErrInvalidRegistration = errors.New("invalid registration, expected format " + RegistrationFormat)
+
+// states lista as siglas das unidades federativas que possuem conselho regional
+var states = map[string]bool{
+	"AC": true, "AL": true, "AP": true, "AM": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MT": true, "MS": true, "MG": true, "PA": true,
+	"PB": true, "PR": true, "PE": true, "PI": true, "RJ": true, "RN": true, "RS": true,
+	"RO": true, "RR": true, "SC": true, "SP": true, "SE": true, "TO": true,
+}
+
+var registrationPattern = regexp.MustCompile(`^CRO[\s\-/]*([A-Z]{2})[\s\-/]*0*(\d{1,6})$`)
+
+// Registration representa o número de inscrição de um dentista no CRO
+type Registration struct {
+	State  string
+	Number string
+}
+
+// ParseRegistration valida e normaliza um número do CRO, aceitando variações como "cro/sp 12345"
+func ParseRegistration(value string) (Registration, error) {
+	match := registrationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if match == nil || !states[match[1]] {
+		return Registration{}, ErrInvalidRegistration
+	}
+	return Registration{State: match[1], Number: match[2]}, nil
+}
+
+// IsState indica se a sigla informada é de uma unidade federativa válida
+func IsState(uf string) bool {
+	return states[uf]
+}
+
+// String retorna o número do CRO no formato canônico
+func (r Registration) String() string {
+	return fmt.Sprintf("CRO-%s %s", r.State, r.Number)
+}
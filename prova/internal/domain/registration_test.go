@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRegistration(t *testing.T) {
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{"CRO-SP 12345", "CRO-SP 12345"},
+		{"cro/sp 12345", "CRO-SP 12345"},
+		{"  CRO RJ-0099 ", "CRO-RJ 99"},
+		{"CROMG345678", "CRO-MG 345678"},
+		{"CRO-SP 0", "CRO-SP 0"},
+	}
+	for _, c := range cases {
+		registration, err := ParseRegistration(c.value)
+		if err != nil {
+			t.Errorf("ParseRegistration(%q): %v", c.value, err)
+			continue
+		}
+		if got := registration.String(); got != c.want {
+			t.Errorf("ParseRegistration(%q) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
+
+func TestParseRegistrationInvalid(t *testing.T) {
+	for _, value := range []string{"", "12345", "1234A", "CRO-XX 12345", "CRO-SP", "CRO-SP 1234567", "CRO-SP 12A45"} {
+		if _, err := ParseRegistration(value); !errors.Is(err, ErrInvalidRegistration) {
+			t.Errorf("ParseRegistration(%q) error = %v, want ErrInvalidRegistration", value, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// Agrupamentos de período dos relatórios
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// ReportFilter é o intervalo dos relatórios, com To exclusivo, e o agrupamento das linhas
+type ReportFilter struct {
+	From   time.Time
+	To     time.Time
+	Period string
+}
+
+// ReportBucket é uma agregação feita no banco: a quantidade e os minutos de consultas ou bloqueios
+// de um dentista (pelo CRO), em um período e situação. Period é a data de início do período no
+// formato aaaa-mm-dd, vazia quando não há agrupamento.
+type ReportBucket struct {
+	Registration string
+	Period       string
+	Status       string
+	Count        int
+	Minutes      int
+}
+
+// ReportDentist identifica o dentista nas linhas dos relatórios
+type ReportDentist struct {
+	Id           int    `json:"id"`
+	Registration string `json:"registration"`
+	Name         string `json:"name"`
+	Surname      string `json:"surname"`
+}
+
+// UtilizationRow compara as horas agendadas com as disponíveis de um dentista em um período
+type UtilizationRow struct {
+	Dentist        ReportDentist `json:"dentist"`
+	Period         string        `json:"period"`
+	AvailableHours float64       `json:"available_hours"`
+	BlockedHours   float64       `json:"blocked_hours"`
+	BookedHours    float64       `json:"booked_hours"`
+	Utilization    float64       `json:"utilization"`
+}
+
+// NoShowRow é a taxa de faltas de um dentista em um período, sobre as consultas não canceladas
+type NoShowRow struct {
+	Dentist      ReportDentist `json:"dentist"`
+	Period       string        `json:"period"`
+	Appointments int           `json:"appointments"`
+	NoShows      int           `json:"no_shows"`
+	Rate         float64       `json:"rate"`
+}
+
+// NewPatientsRow é a quantidade de pacientes cadastrados em um período
+type NewPatientsRow struct {
+	Period   string `json:"period"`
+	Patients int    `json:"patients"`
+}
+
+// DentistAppointmentsRow resume as consultas de um dentista no intervalo, por situação
+type DentistAppointmentsRow struct {
+	Dentist     ReportDentist `json:"dentist"`
+	Total       int           `json:"total"`
+	Scheduled   int           `json:"scheduled"`
+	Confirmed   int           `json:"confirmed"`
+	CheckedIn   int           `json:"checked_in"`
+	Completed   int           `json:"completed"`
+	Cancelled   int           `json:"cancelled"`
+	NoShow      int           `json:"no_show"`
+	BookedHours float64       `json:"booked_hours"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+// ScheduleBlock é um horário bloqueado na agenda do dentista, criado pelo seu cliente de calendário.
+// Nenhuma consulta pode ser marcada sobre um bloqueio.
+type ScheduleBlock struct {
+	Id        int    `json:"id"`
+	IdDentist string `json:"id_dentist"`
+	UID       string `json:"uid"`
+	Href      string `json:"href"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Summary   string `json:"summary,omitempty"`
+}
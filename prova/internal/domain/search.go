@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Page é uma página de uma listagem: até Limit registros a partir do registro Offset
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// AppointmentSearch restringe as consultas listadas, com os mesmos filtros das exportações.
+// Datas zeradas e listas vazias não filtram; To é exclusivo. Nas buscas agrupadas por dentista
+// ou paciente, a página vale para cada grupo.
+type AppointmentSearch struct {
+	From     time.Time
+	To       time.Time
+	Dentists []string
+	Patients []string
+	Status   string
+	Page
+}
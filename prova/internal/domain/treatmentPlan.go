@@ -0,0 +1,52 @@
+package domain
+
+// Situações de um plano de tratamento
+const (
+	PlanDraft      = "draft"
+	PlanAccepted   = "accepted"
+	PlanInProgress = "in_progress"
+	PlanCompleted  = "completed"
+	PlanCancelled  = "cancelled"
+)
+
+// Situações de um procedimento do plano de tratamento
+const (
+	ItemPending   = "pending"
+	ItemScheduled = "scheduled"
+	ItemDone      = "done"
+)
+
+type TreatmentPlan struct {
+	Id         int                 `json:"id"`
+	IdPatient  int                 `json:"id_patient"`
+	IdDentist  int                 `json:"id_dentist" binding:"required"`
+	Title      string              `json:"title" binding:"required"`
+	Status     string              `json:"status"`
+	CreatedAt  string              `json:"created_at"`
+	AcceptedAt string              `json:"accepted_at,omitempty"`
+	Items      []TreatmentPlanItem `json:"items" binding:"required"`
+}
+
+type TreatmentPlanItem struct {
+	Id            int     `json:"id"`
+	Position      int     `json:"position"`
+	ProcedureCode string  `json:"procedure_code" binding:"required"`
+	ProcedureName string  `json:"procedure_name"`
+	Tooth         int     `json:"tooth,omitempty"`
+	Price         float64 `json:"price"`
+	IdAppointment int     `json:"id_appointment,omitempty"`
+	Status        string  `json:"status"`
+}
+
+// PlanProgress resume o andamento de um plano de tratamento
+type PlanProgress struct {
+	IdPlan         int     `json:"id_plan"`
+	Status         string  `json:"status"`
+	TotalItems     int     `json:"total_items"`
+	PendingItems   int     `json:"pending_items"`
+	ScheduledItems int     `json:"scheduled_items"`
+	DoneItems      int     `json:"done_items"`
+	TotalPrice     float64 `json:"total_price"`
+	DonePrice      float64 `json:"done_price"`
+	Percent        float64 `json:"percent"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+const (
+	RoleAdmin     = "admin"
+	RoleDentist   = "dentist"
+	RoleReception = "reception"
+)
+
+type User struct {
+	Id           int    `json:"id"`
+	Username     string `json:"username" binding:"required"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role" binding:"required"`
+	IdDentist    int    `json:"id_dentist,omitempty"`
+}
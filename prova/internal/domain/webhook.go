@@ -0,0 +1,36 @@
+package domain
+
+import "encoding/json"
+
+// Situações da entrega de um evento a uma assinatura
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription é a inscrição de um integrador para receber eventos em uma url
+type WebhookSubscription struct {
+	Id        int      `json:"id"`
+	URL       string   `json:"url" binding:"required"`
+	Secret    string   `json:"secret,omitempty"`
+	Events    []string `json:"events" binding:"required"`
+	Active    *bool    `json:"active,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// WebhookDelivery registra a entrega de um evento a uma assinatura e suas tentativas
+type WebhookDelivery struct {
+	Id             int             `json:"id"`
+	IdSubscription int             `json:"id_subscription"`
+	EventId        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+}
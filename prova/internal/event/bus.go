@@ -0,0 +1,53 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// Bus distribui os eventos para assinantes dentro do próprio processo
+type Bus interface {
+	Sink
+	// Subscribe inscreve um assinante e retorna a função que cancela a inscrição. Os eventos
+	// são entregues na ordem em que foram publicados.
+	Subscribe(handler func(e domain.Event)) (unsubscribe func())
+}
+
+type bus struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(domain.Event)
+}
+
+// NewBus cria um barramento de eventos em memória
+func NewBus() Bus {
+	return &bus{handlers: map[int]func(domain.Event){}}
+}
+
+func (b *bus) Name() string {
+	return "bus"
+}
+
+func (b *bus) Subscribe(handler func(e domain.Event)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+func (b *bus) Publish(_ context.Context, e domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		handler(e)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// Tipos de evento gravados no outbox junto com as alterações
+const (
+	AppointmentCreated   = "appointment.created"
+	AppointmentUpdated   = "appointment.updated"
+	AppointmentCancelled = "appointment.cancelled"
+	AppointmentDeleted   = "appointment.deleted"
+	PatientCreated       = "patient.created"
+	PatientUpdated       = "patient.updated"
+	PatientDeleted       = "patient.deleted"
+	DentistCreated       = "dentist.created"
+	DentistUpdated       = "dentist.updated"
+	DentistDeleted       = "dentist.deleted"
+)
+
+// All é o curinga que inscreve uma assinatura em todos os eventos
+const All = "*"
+
+// Types lista todos os tipos de evento publicados
+var Types = []string{
+	AppointmentCreated, AppointmentUpdated, AppointmentCancelled, AppointmentDeleted,
+	PatientCreated, PatientUpdated, PatientDeleted,
+	DentistCreated, DentistUpdated, DentistDeleted,
+}
+
+// IsValidType indica se o tipo é um evento publicado ou o curinga
+func IsValidType(eventType string) bool {
+	if eventType == All {
+		return true
+	}
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sink recebe os eventos repassados pelo relay do outbox. A entrega é pelo menos uma vez: um
+// evento pode chegar de novo depois de uma falha, e o Id serve de chave de idempotência.
+type Sink interface {
+	// Name identifica o destino nos logs do relay e nas entregas registradas no outbox, por isso não
+	// deve mudar entre versões
+	Name() string
+	Publish(ctx context.Context, e domain.Event) error
+}
+
+// New cria um evento com identificador único e o instante atual
+func New(eventType string, data interface{}) domain.Event {
+	buffer := make([]byte, 16)
+	rand.Read(buffer)
+	return domain.Event{
+		Id:         hex.EncodeToString(buffer),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+		Data:       data,
+	}
+}
+
+// Deleted é o conteúdo dos eventos de exclusão
+type Deleted struct {
+	Id int `json:"id"`
+}
@@ -0,0 +1,26 @@
+package event
+
+import (
+	"context"
+	"log"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+type logSink struct {
+	logger *log.Logger
+}
+
+// NewLog cria um Sink que registra os eventos no log informado
+func NewLog(logger *log.Logger) Sink {
+	return &logSink{logger}
+}
+
+func (l *logSink) Name() string {
+	return "log"
+}
+
+func (l *logSink) Publish(_ context.Context, e domain.Event) error {
+	l.logger.Printf("event %s %s at %s", e.Type, e.Id, e.OccurredAt)
+	return nil
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"context"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// Appointments entrega as consultas filtradas, uma a uma, à medida que são lidas
+	Appointments(ctx context.Context, filter domain.ExportFilter, fn func(domain.AppointmentDTO) error) error
+	// Patients entrega os pacientes cadastrados no período, um a um, à medida que são lidos
+	Patients(ctx context.Context, filter domain.ExportFilter, fn func(domain.Patient) error) error
+	// Dentists entrega os dentistas, um a um, à medida que são lidos
+	Dentists(ctx context.Context, fn func(domain.Dentist) error) error
+}
+
+type repository struct {
+	store store.ExportStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.ExportStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) Appointments(ctx context.Context, filter domain.ExportFilter, fn func(domain.AppointmentDTO) error) error {
+	return r.store.StreamAppointments(ctx, filter, fn)
+}
+
+func (r *repository) Patients(ctx context.Context, filter domain.ExportFilter, fn func(domain.Patient) error) error {
+	return r.store.StreamPatients(ctx, filter, fn)
+}
+
+func (r *repository) Dentists(ctx context.Context, fn func(domain.Dentist) error) error {
+	return r.store.StreamDentists(ctx, fn)
+}
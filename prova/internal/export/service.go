@@ -0,0 +1,223 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/spreadsheet"
+)
+
+// Formatos de exportação
+const (
+	FormatCSV   = spreadsheet.FormatCSV
+	FormatJSONL = "jsonl"
+	FormatXLSX  = spreadsheet.FormatXLSX
+)
+
+var (
+	// ErrInvalidFormat indica um formato diferente de csv, jsonl e xlsx
+	ErrInvalidFormat = errors.New("invalid export format, use csv, jsonl or xlsx")
+	// ErrInvalidFilter indica um período invertido ou uma situação de consulta desconhecida
+	ErrInvalidFilter = errors.New("invalid export filter")
+)
+
+// Colunas das exportações em planilha. As de pacientes e dentistas usam os nomes aceitos pela
+// importação, de modo que o arquivo exportado possa ser importado novamente.
+var (
+	appointmentHeader = []string{
+		"id", "appointment_date", "duration", "status", "procedure_code", "description",
+		"dentist_id", "dentist_registration", "dentist_name", "dentist_surname",
+		"patient_id", "patient_document", "patient_name", "patient_surname",
+	}
+	patientHeader = []string{
+		"id", "surname", "name", "document", "created_at", "email", "phones", "birth_date", "preferred_language",
+		"address.street", "address.number", "address.complement", "address.district", "address.city",
+		"address.state", "address.zip_code",
+		"guardian.name", "guardian.document", "guardian.phone", "guardian.relationship",
+		"emergency_contact.name", "emergency_contact.document", "emergency_contact.phone",
+		"emergency_contact.relationship",
+		"consent.email", "consent.sms", "consent.whatsapp",
+	}
+	dentistHeader = []string{"id", "surname", "name", "registration"}
+)
+
+type Service interface {
+	// Appointments escreve as consultas filtradas por período, dentista, paciente e situação
+	Appointments(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error
+	// Patients escreve os pacientes cadastrados no período
+	Patients(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error
+	// Dentists escreve todos os dentistas
+	Dentists(ctx context.Context, w io.Writer, format string) error
+}
+
+type service struct {
+	r Repository
+}
+
+// NewService cria um novo serviço. Os registros são escritos à medida que chegam do banco; nada é
+// escrito antes do primeiro registro, de modo que os erros de consulta ainda podem ser respondidos.
+func NewService(r Repository) Service {
+	return &service{r}
+}
+
+// ContentType retorna o tipo de conteúdo do formato, ou ErrInvalidFormat
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatJSONL:
+		return "application/x-ndjson", nil
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", ErrInvalidFormat
+}
+
+func (s *service) Appointments(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error {
+	if filter.Status != "" && !domain.IsValidStatus(filter.Status) {
+		return ErrInvalidFilter
+	}
+	e, err := newEncoder(w, format, appointmentHeader, filter)
+	if err != nil {
+		return err
+	}
+	err = s.r.Appointments(ctx, filter, func(a domain.AppointmentDTO) error {
+		return e.encode(a, func() []string {
+			duration := a.Duration
+			if duration == 0 {
+				duration = appointment.DefaultDuration
+			}
+			return []string{
+				strconv.Itoa(a.Id), a.AppointmentDate, strconv.Itoa(duration), a.Status, a.ProcedureCode, a.Description,
+				strconv.Itoa(a.Dentist.Id), a.Dentist.Registration, a.Dentist.Name, a.Dentist.Surname,
+				strconv.Itoa(a.Patient.Id), a.Patient.Document, a.Patient.Name, a.Patient.Surname,
+			}
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return e.close()
+}
+
+func (s *service) Patients(ctx context.Context, w io.Writer, format string, filter domain.ExportFilter) error {
+	e, err := newEncoder(w, format, patientHeader, filter)
+	if err != nil {
+		return err
+	}
+	err = s.r.Patients(ctx, filter, func(p domain.Patient) error {
+		return e.encode(p, func() []string {
+			row := []string{
+				strconv.Itoa(p.Id), p.Surname, p.Name, p.Document, p.CreatedAt, p.Email,
+				strings.Join(p.Phones, ", "), p.BirthDate, p.PreferredLanguage,
+			}
+			address := p.Address
+			if address == nil {
+				address = &domain.Address{}
+			}
+			row = append(row, address.Street, address.Number, address.Complement, address.District,
+				address.City, address.State, address.ZipCode)
+			row = append(row, contactColumns(p.Guardian)...)
+			row = append(row, contactColumns(p.EmergencyContact)...)
+			if p.Consent == nil {
+				return append(row, "", "", "")
+			}
+			return append(row, strconv.FormatBool(p.Consent.Email), strconv.FormatBool(p.Consent.SMS),
+				strconv.FormatBool(p.Consent.WhatsApp))
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return e.close()
+}
+
+func (s *service) Dentists(ctx context.Context, w io.Writer, format string) error {
+	e, err := newEncoder(w, format, dentistHeader, domain.ExportFilter{})
+	if err != nil {
+		return err
+	}
+	err = s.r.Dentists(ctx, func(d domain.Dentist) error {
+		return e.encode(d, func() []string {
+			return []string{strconv.Itoa(d.Id), d.Surname, d.Name, d.Registration}
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return e.close()
+}
+
+func contactColumns(c *domain.Contact) []string {
+	if c == nil {
+		return []string{"", "", "", ""}
+	}
+	return []string{c.Name, c.Document, c.Phone, c.Relationship}
+}
+
+// encoder escreve os registros como JSON Lines ou como linhas de planilha. A planilha só é aberta
+// no primeiro registro, ou ao final se não houver nenhum.
+type encoder struct {
+	w      io.Writer
+	format string
+	header []string
+	json   *json.Encoder
+	sheet  spreadsheet.Writer
+}
+
+func newEncoder(w io.Writer, format string, header []string, filter domain.ExportFilter) (*encoder, error) {
+	if _, err := ContentType(format); err != nil {
+		return nil, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ErrInvalidFilter
+	}
+	return &encoder{w: w, format: format, header: header}, nil
+}
+
+// encode escreve o registro; row só é chamada nos formatos de planilha, em que as células que
+// começam como fórmula são escritas como texto
+func (e *encoder) encode(record interface{}, row func() []string) error {
+	if e.format == FormatJSONL {
+		if e.json == nil {
+			e.json = json.NewEncoder(e.w)
+		}
+		return e.json.Encode(record)
+	}
+	if err := e.open(); err != nil {
+		return err
+	}
+	values := row()
+	for i := range values {
+		values[i] = spreadsheet.EscapeFormula(values[i])
+	}
+	return e.sheet.Write(values)
+}
+
+func (e *encoder) open() error {
+	if e.sheet != nil {
+		return nil
+	}
+	sheet, err := spreadsheet.NewWriter(e.w, e.format)
+	if err != nil {
+		return err
+	}
+	e.sheet = sheet
+	return e.sheet.Write(e.header)
+}
+
+func (e *encoder) close() error {
+	if e.format == FormatJSONL {
+		return nil
+	}
+	if err := e.open(); err != nil {
+		return err
+	}
+	return e.sheet.Close()
+}
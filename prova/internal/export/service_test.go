@@ -0,0 +1,216 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/spreadsheet"
+)
+
+// fakeRepository entrega registros fixos e guarda o filtro recebido
+type fakeRepository struct {
+	appointments []domain.AppointmentDTO
+	patients     []domain.Patient
+	dentists     []domain.Dentist
+	filter       domain.ExportFilter
+	err          error
+}
+
+func (r *fakeRepository) Appointments(ctx context.Context, filter domain.ExportFilter, fn func(domain.AppointmentDTO) error) error {
+	r.filter = filter
+	if r.err != nil {
+		return r.err
+	}
+	for _, a := range r.appointments {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) Patients(ctx context.Context, filter domain.ExportFilter, fn func(domain.Patient) error) error {
+	r.filter = filter
+	for _, p := range r.patients {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) Dentists(ctx context.Context, fn func(domain.Dentist) error) error {
+	for _, d := range r.dentists {
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	dentist  = domain.Dentist{Id: 7, Surname: "Lima", Name: "Ana", Registration: "CRO-SP 1234"}
+	attacker = domain.Patient{
+		Id: 1, Surname: "@SUM(A1:A9)", Name: `=HYPERLINK("http://evil.test","clique")`, Document: "123",
+		CreatedAt: "01/01/2024 10:00", Phones: []string{"+5511987654321"},
+		Consent: &domain.CommunicationConsent{Email: true},
+	}
+	booked = domain.AppointmentDTO{
+		Appointment: domain.Appointment{Id: 3, AppointmentDate: "04/03/2024 09:00", Status: domain.StatusScheduled, Description: "-2+3"},
+		Dentist:     dentist,
+		Patient:     attacker,
+	}
+)
+
+func newTestService() (Service, *fakeRepository) {
+	r := &fakeRepository{
+		appointments: []domain.AppointmentDTO{booked},
+		patients:     []domain.Patient{attacker},
+		dentists:     []domain.Dentist{dentist},
+	}
+	return NewService(r), r
+}
+
+func TestSpreadsheetOutput(t *testing.T) {
+	wantAppointments := [][]string{appointmentHeader, {
+		"3", "04/03/2024 09:00", "30", "scheduled", "", "'-2+3",
+		"7", "CRO-SP 1234", "Ana", "Lima",
+		"1", "123", `'=HYPERLINK("http://evil.test","clique")`, "'@SUM(A1:A9)",
+	}}
+	wantPatient := []string{
+		"1", "'@SUM(A1:A9)", `'=HYPERLINK("http://evil.test","clique")`, "123", "01/01/2024 10:00", "", "'+5511987654321", "", "",
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "true", "false", "false",
+	}
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			s, _ := newTestService()
+			read := func(write func(w *bytes.Buffer) error) [][]string {
+				t.Helper()
+				var buffer bytes.Buffer
+				if err := write(&buffer); err != nil {
+					t.Fatalf("export: %v", err)
+				}
+				rows, err := spreadsheet.Read(buffer.Bytes(), format, 0)
+				if err != nil {
+					t.Fatalf("read: %v", err)
+				}
+				return rows
+			}
+
+			rows := read(func(w *bytes.Buffer) error {
+				return s.Appointments(context.Background(), w, format, domain.ExportFilter{})
+			})
+			if !reflect.DeepEqual(rows, wantAppointments) {
+				t.Errorf("appointments = %q, want %q", rows, wantAppointments)
+			}
+
+			rows = read(func(w *bytes.Buffer) error {
+				return s.Patients(context.Background(), w, format, domain.ExportFilter{})
+			})
+			if len(rows) != 2 || !reflect.DeepEqual(rows[0], patientHeader) {
+				t.Fatalf("patients = %q, want the header and one row", rows)
+			}
+			// o XLSX não guarda as células vazias do fim da linha
+			got := append(rows[1], make([]string, len(wantPatient)-len(rows[1]))...)
+			if !reflect.DeepEqual(got, wantPatient) {
+				t.Errorf("patient = %q, want %q", got, wantPatient)
+			}
+
+			rows = read(func(w *bytes.Buffer) error {
+				return s.Dentists(context.Background(), w, format)
+			})
+			if want := [][]string{dentistHeader, {"7", "Lima", "Ana", "CRO-SP 1234"}}; !reflect.DeepEqual(rows, want) {
+				t.Errorf("dentists = %q, want %q", rows, want)
+			}
+		})
+	}
+}
+
+func TestFormulaPrefixIsRemovedOnImport(t *testing.T) {
+	for _, value := range []string{attacker.Name, attacker.Surname, "+5511987654321", "-2", "Ana", "'quoted", ""} {
+		if got := spreadsheet.UnescapeFormula(spreadsheet.EscapeFormula(value)); got != value {
+			t.Errorf("round trip of %q = %q", value, got)
+		}
+	}
+}
+
+func TestJSONLinesOutput(t *testing.T) {
+	s, _ := newTestService()
+	var buffer bytes.Buffer
+	if err := s.Patients(context.Background(), &buffer, FormatJSONL, domain.ExportFilter{}); err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	var p domain.Patient
+	if err := json.Unmarshal(buffer.Bytes(), &p); err != nil {
+		t.Fatalf("decode %s: %v", buffer.Bytes(), err)
+	}
+	// o JSON não é aberto como planilha e mantém o valor original
+	if p.Name != attacker.Name {
+		t.Errorf("name = %q, want %q", p.Name, attacker.Name)
+	}
+}
+
+func TestEmptyExportHasTheHeader(t *testing.T) {
+	s := NewService(&fakeRepository{})
+	var buffer bytes.Buffer
+	if err := s.Appointments(context.Background(), &buffer, FormatCSV, domain.ExportFilter{}); err != nil {
+		t.Fatalf("Appointments: %v", err)
+	}
+	if want := strings.Join(appointmentHeader, ",") + "\n"; buffer.String() != want {
+		t.Errorf("export = %q, want only the header", buffer.String())
+	}
+}
+
+func TestFilters(t *testing.T) {
+	s, r := newTestService()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	filter := domain.ExportFilter{From: from, To: to, Dentist: "CRO-SP 1234", Patient: "123", Status: domain.StatusScheduled}
+	if err := s.Appointments(context.Background(), &bytes.Buffer{}, FormatCSV, filter); err != nil {
+		t.Fatalf("Appointments: %v", err)
+	}
+	if r.filter != filter {
+		t.Errorf("filter = %+v, want %+v", r.filter, filter)
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		filter domain.ExportFilter
+		want   error
+	}{
+		{"unknown status", FormatCSV, domain.ExportFilter{Status: "lost"}, ErrInvalidFilter},
+		{"inverted period", FormatCSV, domain.ExportFilter{From: to, To: from}, ErrInvalidFilter},
+		{"empty period", FormatCSV, domain.ExportFilter{From: from, To: from}, ErrInvalidFilter},
+		{"unknown format", "ods", domain.ExportFilter{}, ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		var buffer bytes.Buffer
+		if err := s.Appointments(context.Background(), &buffer, tt.format, tt.filter); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Appointments = %v, want %v", tt.name, err, tt.want)
+		}
+		if buffer.Len() != 0 {
+			t.Errorf("%s: wrote %q before failing", tt.name, buffer.String())
+		}
+	}
+}
+
+func TestQueryErrorWritesNothing(t *testing.T) {
+	s, r := newTestService()
+	r.err = errors.New("connection refused")
+	var buffer bytes.Buffer
+	if err := s.Appointments(context.Background(), &buffer, FormatXLSX, domain.ExportFilter{}); !errors.Is(err, r.err) {
+		t.Errorf("Appointments = %v, want the query error", err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("wrote %d bytes before failing", buffer.Len())
+	}
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+)
+
+// Tamanho das páginas das listagens
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	// ErrInvalidPage indica um limite fora de 1 a MaxLimit ou um deslocamento negativo
+	ErrInvalidPage = fmt.Errorf("invalid page, limit must be between 1 and %d and offset can't be negative", MaxLimit)
+	// ErrInvalidFilter indica um período invertido ou uma situação de consulta desconhecida
+	ErrInvalidFilter = errors.New("invalid appointment filter")
+)
+
+// Service é a leitura em lote usada pela API GraphQL. As buscas por chave recebem as chaves de
+// um nível inteiro da consulta e devolvem os registros encontrados indexados pela chave.
+type Service interface {
+	DentistsByID(ctx context.Context, ids []int) (map[int]domain.Dentist, error)
+	DentistsByRegistration(ctx context.Context, registrations []string) (map[string]domain.Dentist, error)
+	PatientsByID(ctx context.Context, ids []int) (map[int]domain.Patient, error)
+	PatientsByDocument(ctx context.Context, documents []string) (map[string]domain.Patient, error)
+	AppointmentsByID(ctx context.Context, ids []int) (map[int]domain.AppointmentDTO, error)
+	// Dentists lista os dentistas por id
+	Dentists(ctx context.Context, page domain.Page) ([]domain.Dentist, error)
+	// Patients lista os pacientes por id
+	Patients(ctx context.Context, page domain.Page) ([]domain.Patient, error)
+	// Appointments lista as consultas filtradas, por data
+	Appointments(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
+	// AppointmentsByDentist lista as consultas de cada dentista de search.Dentists, pelo CRO
+	AppointmentsByDentist(ctx context.Context, search domain.AppointmentSearch) (map[string][]domain.AppointmentDTO, error)
+	// AppointmentsByPatient lista as consultas de cada paciente de search.Patients, pelo documento
+	AppointmentsByPatient(ctx context.Context, search domain.AppointmentSearch) (map[string][]domain.AppointmentDTO, error)
+}
+
+type service struct {
+	dentists     dentist.Repository
+	patients     patient.Repository
+	appointments appointment.Repository
+}
+
+// NewService cria um novo serviço sobre os repositórios usados pela API REST, para que as duas
+// leiam os mesmos dados da mesma forma
+func NewService(dentists dentist.Repository, patients patient.Repository, appointments appointment.Repository) Service {
+	return &service{dentists: dentists, patients: patients, appointments: appointments}
+}
+
+// checkPage aplica o limite padrão e confere a página
+func checkPage(page domain.Page) (domain.Page, error) {
+	if page.Limit == 0 {
+		page.Limit = DefaultLimit
+	}
+	if page.Limit < 1 || page.Limit > MaxLimit || page.Offset < 0 {
+		return page, ErrInvalidPage
+	}
+	return page, nil
+}
+
+func checkSearch(search domain.AppointmentSearch) (domain.AppointmentSearch, error) {
+	var err error
+	if search.Page, err = checkPage(search.Page); err != nil {
+		return search, err
+	}
+	if search.Status != "" && !domain.IsValidStatus(search.Status) {
+		return search, fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, search.Status)
+	}
+	if !search.From.IsZero() && !search.To.IsZero() && !search.From.Before(search.To) {
+		return search, fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	return search, nil
+}
+
+func (s *service) DentistsByID(ctx context.Context, ids []int) (map[int]domain.Dentist, error) {
+	dentists, err := s.dentists.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.Dentist, len(dentists))
+	for _, d := range dentists {
+		byID[d.Id] = d
+	}
+	return byID, nil
+}
+
+func (s *service) DentistsByRegistration(ctx context.Context, registrations []string) (map[string]domain.Dentist, error) {
+	dentists, err := s.dentists.GetByRegistrations(ctx, registrations)
+	if err != nil {
+		return nil, err
+	}
+	byRegistration := make(map[string]domain.Dentist, len(dentists))
+	for _, d := range dentists {
+		byRegistration[d.Registration] = d
+	}
+	return byRegistration, nil
+}
+
+func (s *service) PatientsByID(ctx context.Context, ids []int) (map[int]domain.Patient, error) {
+	patients, err := s.patients.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.Patient, len(patients))
+	for _, p := range patients {
+		byID[p.Id] = p
+	}
+	return byID, nil
+}
+
+func (s *service) PatientsByDocument(ctx context.Context, documents []string) (map[string]domain.Patient, error) {
+	patients, err := s.patients.GetByDocuments(ctx, documents)
+	if err != nil {
+		return nil, err
+	}
+	byDocument := make(map[string]domain.Patient, len(patients))
+	for _, p := range patients {
+		byDocument[p.Document] = p
+	}
+	return byDocument, nil
+}
+
+func (s *service) AppointmentsByID(ctx context.Context, ids []int) (map[int]domain.AppointmentDTO, error) {
+	appointments, err := s.appointments.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.AppointmentDTO, len(appointments))
+	for _, a := range appointments {
+		byID[a.Id] = a
+	}
+	return byID, nil
+}
+
+func (s *service) Dentists(ctx context.Context, page domain.Page) ([]domain.Dentist, error) {
+	page, err := checkPage(page)
+	if err != nil {
+		return nil, err
+	}
+	return s.dentists.GetPage(ctx, page)
+}
+
+func (s *service) Patients(ctx context.Context, page domain.Page) ([]domain.Patient, error) {
+	page, err := checkPage(page)
+	if err != nil {
+		return nil, err
+	}
+	return s.patients.GetPage(ctx, page)
+}
+
+func (s *service) Appointments(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	search, err := checkSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	return s.appointments.Search(ctx, search)
+}
+
+func (s *service) AppointmentsByDentist(ctx context.Context, search domain.AppointmentSearch) (map[string][]domain.AppointmentDTO, error) {
+	search, err := checkSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.appointments.SearchByDentists(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	byDentist := make(map[string][]domain.AppointmentDTO, len(search.Dentists))
+	for _, a := range appointments {
+		byDentist[a.IdDentist] = append(byDentist[a.IdDentist], a)
+	}
+	return byDentist, nil
+}
+
+func (s *service) AppointmentsByPatient(ctx context.Context, search domain.AppointmentSearch) (map[string][]domain.AppointmentDTO, error) {
+	search, err := checkSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.appointments.SearchByPatients(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	byPatient := make(map[string][]domain.AppointmentDTO, len(search.Patients))
+	for _, a := range appointments {
+		byPatient[a.IdPatient] = append(byPatient[a.IdPatient], a)
+	}
+	return byPatient, nil
+}
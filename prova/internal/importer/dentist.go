@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// dentistFields são os campos aceitos na planilha de dentistas, com os mesmos nomes do JSON
+var dentistFields = []string{"surname", "name", "registration"}
+
+func (s *service) Dentists(data []byte, opts Options) (domain.ImportReport, error) {
+	sh, err := open(data, &opts, dentistFields, "registration")
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	// só os dentistas com os CROs da planilha são buscados, pelo número como está na planilha e
+	// normalizado, que é como o cadastro o grava
+	existing := make(map[string]domain.Dentist)
+	for _, registrations := range sh.keys("registration") {
+		keys := make([]string, 0, 2*len(registrations))
+		for _, registration := range registrations {
+			keys = append(keys, registration)
+			if key := registrationKey(registration); key != registration {
+				keys = append(keys, key)
+			}
+		}
+		list, err := s.dentists.GetByRegistrations(keys)
+		if err != nil {
+			return domain.ImportReport{}, err
+		}
+		for _, d := range list {
+			existing[registrationKey(d.Registration)] = d
+		}
+	}
+
+	report := domain.ImportReport{DryRun: opts.DryRun, Rows: []domain.ImportRow{}}
+	seen := make(map[string]int)
+	valid := make(map[int]domain.Dentist)
+	var pending []int
+	save := func(pending []int) ([]int, error) {
+		dentists := make([]domain.Dentist, 0, len(pending))
+		for _, i := range pending {
+			dentists = append(dentists, valid[i])
+		}
+		saved, err := s.dentists.Batch(dentists)
+		ids := make([]int, 0, len(saved))
+		for _, d := range saved {
+			ids = append(ids, d.Id)
+		}
+		return ids, err
+	}
+
+	for i := 1; i < len(sh.rows); i++ {
+		values, ok := sh.record(i)
+		if !ok {
+			continue
+		}
+		key := registrationKey(values["registration"])
+		row := domain.ImportRow{Row: i + 1, Key: key, Status: domain.ImportOK}
+		d := domain.Dentist{Surname: values["surname"], Name: values["name"], Registration: values["registration"]}
+
+		if first, ok := seen[key]; ok && key != "" {
+			invalidate(&row, fmt.Sprintf("registration: duplicated in row %d", first))
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+		seen[key] = row.Row
+
+		var currentPtr *domain.Dentist
+		row.Action = domain.ImportCreate
+		if current, exists := existing[key]; exists {
+			if opts.OnDuplicate == DuplicateSkip {
+				row.Action, row.Id = domain.ImportSkip, current.Id
+				report.Rows = append(report.Rows, row)
+				continue
+			}
+			row.Action, row.Id = domain.ImportUpdate, current.Id
+			currentPtr = &current
+		}
+
+		validated, err := s.dentists.Validate(d, currentPtr)
+		if err != nil {
+			invalidate(&row, err.Error())
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+		report.Rows = append(report.Rows, row)
+		if opts.DryRun {
+			continue
+		}
+		valid[len(report.Rows)-1] = validated
+		pending = append(pending, len(report.Rows)-1)
+		if len(pending) >= opts.BatchSize {
+			commit(&report, pending, save)
+			pending = nil
+		}
+	}
+	if len(pending) > 0 {
+		commit(&report, pending, save)
+	}
+
+	summarize(&report)
+	return report, nil
+}
+
+// registrationKey normaliza o CRO para comparar cadastros escritos de formas diferentes
+func registrationKey(value string) string {
+	registration, err := domain.ParseRegistration(value)
+	if err != nil {
+		return value
+	}
+	return registration.String()
+}
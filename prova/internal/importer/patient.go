@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// Formatos de created_at esperados pelo banco na inclusão e na atualização do paciente
+const (
+	createdAtCreateLayout = "02/01/2006 15:04:05"
+	createdAtUpdateLayout = "02/01/2006 15:04"
+)
+
+// patientFields são os campos aceitos na planilha de pacientes, com os mesmos nomes do JSON
+var patientFields = []string{
+	"surname", "name", "document", "created_at", "email", "phones", "birth_date", "preferred_language",
+	"address.street", "address.number", "address.complement", "address.district", "address.city",
+	"address.state", "address.zip_code",
+	"guardian.name", "guardian.document", "guardian.phone", "guardian.relationship",
+	"emergency_contact.name", "emergency_contact.document", "emergency_contact.phone",
+	"emergency_contact.relationship",
+	"consent.email", "consent.sms", "consent.whatsapp",
+}
+
+func (s *service) Patients(data []byte, opts Options) (domain.ImportReport, error) {
+	sh, err := open(data, &opts, patientFields, "document")
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	// só os pacientes com os documentos da planilha são buscados
+	existing := make(map[string]domain.Patient)
+	for _, documents := range sh.keys("document") {
+		list, err := s.patients.GetByDocuments(documents)
+		if err != nil {
+			return domain.ImportReport{}, err
+		}
+		for _, p := range list {
+			existing[p.Document] = p
+		}
+	}
+
+	report := domain.ImportReport{DryRun: opts.DryRun, Rows: []domain.ImportRow{}}
+	seen := make(map[string]int)
+	valid := make(map[int]domain.Patient)
+	var pending []int
+	save := func(pending []int) ([]int, error) {
+		patients := make([]domain.Patient, 0, len(pending))
+		for _, i := range pending {
+			patients = append(patients, valid[i])
+		}
+		saved, err := s.patients.Batch(patients)
+		ids := make([]int, 0, len(saved))
+		for _, p := range saved {
+			ids = append(ids, p.Id)
+		}
+		return ids, err
+	}
+
+	for i := 1; i < len(sh.rows); i++ {
+		values, ok := sh.record(i)
+		if !ok {
+			continue
+		}
+		row := domain.ImportRow{Row: i + 1, Key: values["document"], Status: domain.ImportOK}
+		p, errs := parsePatient(values)
+
+		if first, ok := seen[row.Key]; ok && row.Key != "" {
+			invalidate(&row, fmt.Sprintf("document: duplicated in row %d", first))
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+		seen[row.Key] = row.Row
+
+		current, exists := existing[row.Key]
+		if exists && opts.OnDuplicate == DuplicateSkip {
+			row.Action, row.Id = domain.ImportSkip, current.Id
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+
+		var currentPtr *domain.Patient
+		row.Action = domain.ImportCreate
+		layout := createdAtCreateLayout
+		if exists {
+			row.Action, row.Id = domain.ImportUpdate, current.Id
+			layout = createdAtUpdateLayout
+			if t, ok := parseDate(current.CreatedAt); ok {
+				current.CreatedAt = t.Format(layout)
+			}
+			currentPtr = &current
+		} else if p.CreatedAt == "" && len(errs) == 0 {
+			p.CreatedAt = time.Now().Format(layout)
+		}
+		if p.CreatedAt != "" {
+			t, _ := parseDate(p.CreatedAt)
+			p.CreatedAt = t.Format(layout)
+		}
+		if len(errs) > 0 {
+			invalidate(&row, errs...)
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+
+		validated, err := s.patients.Validate(p, currentPtr)
+		if err != nil {
+			invalidate(&row, err.Error())
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+		report.Rows = append(report.Rows, row)
+		if opts.DryRun {
+			continue
+		}
+		valid[len(report.Rows)-1] = validated
+		pending = append(pending, len(report.Rows)-1)
+		if len(pending) >= opts.BatchSize {
+			commit(&report, pending, save)
+			pending = nil
+		}
+	}
+	if len(pending) > 0 {
+		commit(&report, pending, save)
+	}
+
+	summarize(&report)
+	return report, nil
+}
+
+// parsePatient monta o paciente a partir dos valores da linha. Os erros de formato são
+// acumulados para que a linha seja reportada de uma só vez.
+func parsePatient(values map[string]string) (domain.Patient, []string) {
+	var errs []string
+	p := domain.Patient{
+		Surname:           values["surname"],
+		Name:              values["name"],
+		Document:          values["document"],
+		CreatedAt:         values["created_at"],
+		Email:             values["email"],
+		BirthDate:         values["birth_date"],
+		PreferredLanguage: values["preferred_language"],
+	}
+	if p.CreatedAt != "" {
+		if _, ok := parseDate(p.CreatedAt); !ok {
+			errs = append(errs, "created_at: invalid date, use dd/mm/yyyy hh:mm")
+		}
+	}
+	if phones := values["phones"]; phones != "" {
+		p.Phones = strings.FieldsFunc(phones, func(r rune) bool { return r == ',' || r == ';' || r == '/' })
+		for i := range p.Phones {
+			p.Phones[i] = strings.TrimSpace(p.Phones[i])
+		}
+	}
+
+	if hasPrefix(values, "address.") {
+		p.Address = &domain.Address{
+			Street:     values["address.street"],
+			Number:     values["address.number"],
+			Complement: values["address.complement"],
+			District:   values["address.district"],
+			City:       values["address.city"],
+			State:      values["address.state"],
+			ZipCode:    values["address.zip_code"],
+		}
+	}
+	p.Guardian = parseContact(values, "guardian.")
+	p.EmergencyContact = parseContact(values, "emergency_contact.")
+
+	if hasPrefix(values, "consent.") {
+		p.Consent = &domain.CommunicationConsent{}
+		for field, target := range map[string]*bool{
+			"consent.email":    &p.Consent.Email,
+			"consent.sms":      &p.Consent.SMS,
+			"consent.whatsapp": &p.Consent.WhatsApp,
+		} {
+			value, ok := parseBool(values[field])
+			if !ok {
+				errs = append(errs, field+": invalid boolean")
+			}
+			*target = value
+		}
+	}
+	return p, errs
+}
+
+func parseContact(values map[string]string, prefix string) *domain.Contact {
+	if !hasPrefix(values, prefix) {
+		return nil
+	}
+	return &domain.Contact{
+		Name:         values[prefix+"name"],
+		Document:     values[prefix+"document"],
+		Phone:        values[prefix+"phone"],
+		Relationship: values[prefix+"relationship"],
+	}
+}
+
+// hasPrefix indica se algum campo do grupo foi preenchido
+func hasPrefix(values map[string]string, prefix string) bool {
+	for field := range values {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/spreadsheet"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// O que fazer com as linhas cujo documento ou CRO já está cadastrado
+const (
+	DuplicateSkip   = "skip"
+	DuplicateUpsert = "upsert"
+)
+
+const (
+	// DefaultBatchSize é a quantidade de linhas gravadas em cada transação
+	DefaultBatchSize = 100
+	// MaxBatchSize limita o tamanho das transações
+	MaxBatchSize = 1000
+	// MaxRows limita as linhas de uma planilha, sem contar o cabeçalho
+	MaxRows = 10000
+)
+
+var (
+	// ErrInvalidFile indica que a planilha não pôde ser lida
+	ErrInvalidFile = errors.New("invalid spreadsheet")
+	// ErrInvalidMapping indica um mapeamento com campo desconhecido ou coluna inexistente
+	ErrInvalidMapping = errors.New("invalid column mapping")
+	// ErrInvalidOptions indica opções de importação inválidas
+	ErrInvalidOptions = errors.New("invalid import options")
+	// ErrTooManyRows indica uma planilha acima do limite de linhas
+	ErrTooManyRows = fmt.Errorf("spreadsheet exceeds the limit of %d rows", MaxRows)
+)
+
+// Options são as opções de uma importação
+type Options struct {
+	// Format é csv ou xlsx; vazio, é detectado pelo conteúdo
+	Format string
+	// Mapping associa os campos do cadastro aos títulos das colunas da planilha. Os campos não
+	// mapeados são procurados pelo próprio nome, sem diferenciar maiúsculas.
+	Mapping map[string]string
+	// DryRun valida a planilha sem gravar nada
+	DryRun bool
+	// OnDuplicate é skip (padrão) ou upsert, que atualiza o cadastro existente
+	OnDuplicate string
+	// BatchSize é a quantidade de linhas por transação
+	BatchSize int
+}
+
+type Service interface {
+	// Patients importa pacientes, identificados pelo documento
+	Patients(data []byte, opts Options) (domain.ImportReport, error)
+	// Dentists importa dentistas, identificados pelo CRO
+	Dentists(data []byte, opts Options) (domain.ImportReport, error)
+}
+
+type service struct {
+	patients patient.Service
+	dentists dentist.Service
+}
+
+// NewService cria um novo serviço. As linhas são validadas pelas mesmas regras dos cadastros.
+func NewService(patients patient.Service, dentists dentist.Service) Service {
+	return &service{patients, dentists}
+}
+
+// sheet é a planilha lida, com a posição da coluna de cada campo
+type sheet struct {
+	rows    [][]string
+	columns map[string]int
+}
+
+// open valida as opções, lê a planilha e resolve as colunas dos campos. A coluna da chave, que
+// identifica os cadastros existentes, é obrigatória.
+func open(data []byte, opts *Options, fields []string, key string) (sheet, error) {
+	switch opts.OnDuplicate {
+	case "":
+		opts.OnDuplicate = DuplicateSkip
+	case DuplicateSkip, DuplicateUpsert:
+	default:
+		return sheet{}, fmt.Errorf("%w: on_duplicate must be %s or %s", ErrInvalidOptions, DuplicateSkip, DuplicateUpsert)
+	}
+	switch {
+	case opts.BatchSize == 0:
+		opts.BatchSize = DefaultBatchSize
+	case opts.BatchSize < 0 || opts.BatchSize > MaxBatchSize:
+		return sheet{}, fmt.Errorf("%w: batch_size must be between 1 and %d", ErrInvalidOptions, MaxBatchSize)
+	}
+
+	rows, err := spreadsheet.Read(data, opts.Format, MaxRows)
+	switch {
+	case errors.Is(err, spreadsheet.ErrUnsupportedFormat):
+		return sheet{}, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	case errors.Is(err, spreadsheet.ErrTooManyRows):
+		return sheet{}, ErrTooManyRows
+	case err != nil:
+		return sheet{}, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f] = true
+	}
+	for field := range opts.Mapping {
+		if !known[field] {
+			return sheet{}, fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+		}
+	}
+
+	headers := make(map[string]int, len(rows[0]))
+	for i, h := range rows[0] {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if _, ok := headers[h]; !ok && h != "" {
+			headers[h] = i
+		}
+	}
+	columns := make(map[string]int, len(fields))
+	for _, field := range fields {
+		header, mapped := opts.Mapping[field]
+		if !mapped {
+			header = field
+		}
+		i, ok := headers[strings.ToLower(strings.TrimSpace(header))]
+		if !ok {
+			if mapped {
+				return sheet{}, fmt.Errorf("%w: column %q not found", ErrInvalidMapping, header)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns[key]; !ok {
+		return sheet{}, fmt.Errorf("%w: column for %q is required", ErrInvalidMapping, key)
+	}
+	return sheet{rows, columns}, nil
+}
+
+// record retorna os valores da linha por campo, sem o prefixo que a exportação acrescenta às
+// células que começam como fórmula, ou false se a linha estiver em branco
+func (sh sheet) record(i int) (map[string]string, bool) {
+	values := make(map[string]string, len(sh.columns))
+	blank := true
+	for field, column := range sh.columns {
+		if column >= len(sh.rows[i]) {
+			continue
+		}
+		value := spreadsheet.UnescapeFormula(strings.TrimSpace(sh.rows[i][column]))
+		if value != "" {
+			values[field] = value
+			blank = false
+		}
+	}
+	return values, !blank
+}
+
+// keys retorna os valores distintos e preenchidos da coluna do campo, para buscar os cadastros
+// existentes em lotes de no máximo MaxBatchSize chaves
+func (sh sheet) keys(field string) [][]string {
+	column := sh.columns[field]
+	seen := make(map[string]bool)
+	var chunks [][]string
+	var chunk []string
+	for _, row := range sh.rows[1:] {
+		if column >= len(row) {
+			continue
+		}
+		value := spreadsheet.UnescapeFormula(strings.TrimSpace(row[column]))
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		chunk = append(chunk, value)
+		if len(chunk) == MaxBatchSize {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// invalidate marca a linha como inválida com os erros informados
+func invalidate(row *domain.ImportRow, errs ...string) {
+	row.Status = domain.ImportInvalid
+	row.Errors = append(row.Errors, errs...)
+}
+
+// commit grava as linhas pendentes. Como a transação é desfeita quando uma linha falha, essa
+// linha é marcada e as demais são gravadas novamente.
+func commit(report *domain.ImportReport, pending []int, save func(pending []int) ([]int, error)) {
+	pending = append([]int(nil), pending...)
+	for len(pending) > 0 {
+		ids, err := save(pending)
+		var batchErr *store.BatchError
+		if errors.As(err, &batchErr) && batchErr.Index >= 0 && batchErr.Index < len(pending) {
+			fail(&report.Rows[pending[batchErr.Index]], batchErr.Err)
+			pending = append(pending[:batchErr.Index], pending[batchErr.Index+1:]...)
+			continue
+		}
+		if err == nil && len(ids) != len(pending) {
+			err = errors.New("unexpected batch result")
+		}
+		if err != nil {
+			for _, i := range pending {
+				fail(&report.Rows[i], err)
+			}
+			return
+		}
+		for j, i := range pending {
+			report.Rows[i].Id = ids[j]
+		}
+		return
+	}
+}
+
+func fail(row *domain.ImportRow, err error) {
+	row.Status = domain.ImportFailed
+	row.Errors = append(row.Errors, err.Error())
+}
+
+// summarize calcula os totais do relatório
+func summarize(report *domain.ImportReport) {
+	report.Total = len(report.Rows)
+	for _, row := range report.Rows {
+		switch {
+		case row.Status == domain.ImportInvalid:
+			report.Invalid++
+		case row.Status == domain.ImportFailed:
+			report.Failed++
+		case row.Action == domain.ImportSkip:
+			report.Skipped++
+		case row.Action == domain.ImportCreate:
+			report.Created++
+		case row.Action == domain.ImportUpdate:
+			report.Updated++
+		}
+	}
+}
+
+// dateLayouts são os formatos de data aceitos na planilha, além do usado pelo banco
+var dateLayouts = []string{
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02",
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseBool aceita os valores usuais de planilhas em português e inglês
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "1", "x", "s", "sim", "y", "yes", "true", "verdadeiro":
+		return true, true
+	case "", "0", "n", "não", "nao", "no", "false", "falso":
+		return false, true
+	}
+	return false, false
+}
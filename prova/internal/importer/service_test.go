@@ -0,0 +1,288 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/spreadsheet"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// fakePatients valida com as regras do cadastro e grava em memória. GetAll não é implementado,
+// para garantir que a importação busca só os documentos da planilha.
+type fakePatients struct {
+	patient.Service
+	stored  map[string]domain.Patient
+	lookups [][]string
+	batches [][]domain.Patient
+	// failing faz o lote falhar na linha com esse documento, como uma restrição do banco
+	failing string
+}
+
+func newFakePatients(stored ...domain.Patient) *fakePatients {
+	f := &fakePatients{Service: patient.NewService(nil), stored: map[string]domain.Patient{}}
+	for _, p := range stored {
+		f.stored[p.Document] = p
+	}
+	return f
+}
+
+func (f *fakePatients) GetAll() ([]domain.Patient, error) {
+	panic("the import must look the patients up by document")
+}
+
+func (f *fakePatients) GetByDocuments(documents []string) ([]domain.Patient, error) {
+	f.lookups = append(f.lookups, documents)
+	var list []domain.Patient
+	for _, document := range documents {
+		if p, ok := f.stored[document]; ok {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakePatients) Batch(patients []domain.Patient) ([]domain.Patient, error) {
+	f.batches = append(f.batches, patients)
+	for i, p := range patients {
+		if p.Document == f.failing {
+			return nil, &store.BatchError{Index: i, Err: errors.New("duplicate entry")}
+		}
+	}
+	saved := make([]domain.Patient, len(patients))
+	for i, p := range patients {
+		if p.Id == 0 {
+			p.Id = 100 + len(f.stored)
+		}
+		f.stored[p.Document] = p
+		saved[i] = p
+	}
+	return saved, nil
+}
+
+type fakeDentists struct {
+	dentist.Service
+	stored  map[string]domain.Dentist
+	lookups [][]string
+	batches [][]domain.Dentist
+}
+
+func (f *fakeDentists) GetAll() ([]domain.Dentist, error) {
+	panic("the import must look the dentists up by registration")
+}
+
+func (f *fakeDentists) GetByRegistrations(registrations []string) ([]domain.Dentist, error) {
+	f.lookups = append(f.lookups, registrations)
+	var list []domain.Dentist
+	for _, registration := range registrations {
+		if d, ok := f.stored[registration]; ok {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeDentists) Batch(dentists []domain.Dentist) ([]domain.Dentist, error) {
+	f.batches = append(f.batches, dentists)
+	for i := range dentists {
+		if dentists[i].Id == 0 {
+			dentists[i].Id = 100 + i
+		}
+	}
+	return dentists, nil
+}
+
+var ana = domain.Patient{Id: 1, Surname: "Silva", Name: "Ana", Document: "111", CreatedAt: "01/01/2024 10:00"}
+
+const patientsCSV = "Sobrenome;Nome;CPF;E-mail\n" +
+	"Silva;Ana Maria;111;ana@example.com\n" +
+	"Souza;Bia;222;\n" +
+	";;;\n" +
+	"Lima;Cris;333;invalido\n"
+
+var patientMapping = map[string]string{"surname": "Sobrenome", "name": "Nome", "document": "CPF", "email": "E-mail"}
+
+func TestPatientsMapping(t *testing.T) {
+	patients := newFakePatients()
+	s := NewService(patients, nil)
+
+	report, err := s.Patients([]byte(patientsCSV), Options{Mapping: patientMapping})
+	if err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	if report.Total != 3 || report.Created != 2 || report.Invalid != 1 {
+		t.Errorf("report = %+v, want 2 created and 1 invalid out of 3", report)
+	}
+	if row := report.Rows[2]; row.Row != 5 || row.Status != domain.ImportInvalid || len(row.Errors) == 0 {
+		t.Errorf("row = %+v, want row 5 invalid because of the email", row)
+	}
+	if p := patients.stored["111"]; p.Name != "Ana Maria" || p.Email != "ana@example.com" || p.CreatedAt == "" {
+		t.Errorf("stored = %+v, want the mapped columns", p)
+	}
+	if len(patients.lookups) != 1 || strings.Join(patients.lookups[0], ",") != "111,222,333" {
+		t.Errorf("lookups = %v, want only the documents of the spreadsheet", patients.lookups)
+	}
+}
+
+func TestInvalidMapping(t *testing.T) {
+	s := NewService(newFakePatients(), nil)
+	tests := map[string]Options{
+		"unknown field":        {Mapping: map[string]string{"cpf": "CPF"}},
+		"missing column":       {Mapping: map[string]string{"surname": "Apelido", "document": "CPF"}},
+		"missing key column":   {Mapping: map[string]string{"surname": "Sobrenome"}},
+		"key mapped elsewhere": {Mapping: map[string]string{"document": "Documento"}},
+	}
+	for name, opts := range tests {
+		if _, err := s.Patients([]byte(patientsCSV), opts); !errors.Is(err, ErrInvalidMapping) {
+			t.Errorf("%s: Patients = %v, want ErrInvalidMapping", name, err)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	patients := newFakePatients(ana)
+	s := NewService(patients, nil)
+
+	report, err := s.Patients([]byte(patientsCSV), Options{Mapping: patientMapping, DryRun: true, OnDuplicate: DuplicateUpsert})
+	if err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	if !report.DryRun || report.Updated != 1 || report.Created != 1 || report.Invalid != 1 {
+		t.Errorf("report = %+v, want 1 update, 1 create and 1 invalid", report)
+	}
+	if len(patients.batches) != 0 || patients.stored["111"].Name != "Ana" {
+		t.Errorf("dry run wrote %d batches", len(patients.batches))
+	}
+}
+
+func TestOnDuplicate(t *testing.T) {
+	patients := newFakePatients(ana)
+	s := NewService(patients, nil)
+
+	report, err := s.Patients([]byte(patientsCSV), Options{Mapping: patientMapping})
+	if err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	if row := report.Rows[0]; row.Action != domain.ImportSkip || row.Id != 1 || report.Skipped != 1 {
+		t.Errorf("report = %+v, want the existing patient skipped", report)
+	}
+	if patients.stored["111"].Name != "Ana" {
+		t.Error("skip changed the existing patient")
+	}
+
+	patients = newFakePatients(ana)
+	s = NewService(patients, nil)
+	report, err = s.Patients([]byte(patientsCSV), Options{Mapping: patientMapping, OnDuplicate: DuplicateUpsert})
+	if err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	if row := report.Rows[0]; row.Action != domain.ImportUpdate || row.Id != 1 || report.Updated != 1 {
+		t.Errorf("report = %+v, want the existing patient updated", report)
+	}
+	if p := patients.stored["111"]; p.Id != 1 || p.Name != "Ana Maria" {
+		t.Errorf("stored = %+v, want patient 1 updated", p)
+	}
+
+	if _, err := s.Patients([]byte(patientsCSV), Options{Mapping: patientMapping, OnDuplicate: "replace"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("on_duplicate replace = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("surname,name,document\n")
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&b, "Silva,Paciente %d,%d\n", i, 1000+i)
+	}
+	data := []byte(b.String())
+
+	patients := newFakePatients()
+	patients.failing = "1004"
+	report, err := NewService(patients, nil).Patients(data, Options{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("Patients: %v", err)
+	}
+	// lotes de 2: [1001 1002], [1003 1004], que falha e é regravado sem 1004, e [1005]
+	if len(patients.batches) != 4 {
+		t.Errorf("got %d batches, want 4", len(patients.batches))
+	}
+	if report.Created != 4 || report.Failed != 1 || report.Rows[3].Status != domain.ImportFailed {
+		t.Errorf("report = %+v, want 4 created and row 1004 failed", report)
+	}
+
+	s := NewService(newFakePatients(), nil)
+	for _, size := range []int{-1, MaxBatchSize + 1} {
+		if _, err := s.Patients(data, Options{BatchSize: size}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("batch_size %d = %v, want ErrInvalidOptions", size, err)
+		}
+	}
+}
+
+func TestBadFiles(t *testing.T) {
+	s := NewService(newFakePatients(), nil)
+	tests := []struct {
+		name string
+		data string
+		opts Options
+		want error
+	}{
+		{"empty file", "", Options{}, ErrInvalidFile},
+		{"broken xlsx", "PK\x03\x04 broken", Options{}, ErrInvalidFile},
+		{"csv read as xlsx", "document\n1\n", Options{Format: spreadsheet.FormatXLSX}, ErrInvalidFile},
+		{"unclosed quote", "document\n\"1\n", Options{}, ErrInvalidFile},
+		{"unsupported format", "document\n1\n", Options{Format: "ods"}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		if _, err := s.Patients([]byte(tt.data), tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Patients = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestTooManyRows(t *testing.T) {
+	s := NewService(newFakePatients(), nil)
+
+	csv := "document\n" + strings.Repeat("1\n", MaxRows+1)
+	if _, err := s.Patients([]byte(csv), Options{DryRun: true}); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("csv over the limit = %v, want ErrTooManyRows", err)
+	}
+
+	// o limite vale também para o XLSX
+	var buffer strings.Builder
+	w, _ := spreadsheet.NewWriter(&buffer, spreadsheet.FormatXLSX)
+	for i := 0; i <= MaxRows+1; i++ {
+		w.Write([]string{"1"})
+	}
+	w.Close()
+	if _, err := s.Patients([]byte(buffer.String()), Options{DryRun: true}); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("xlsx over the limit = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestDentistsLookUpNormalizedRegistrations(t *testing.T) {
+	dentists := &fakeDentists{
+		Service: dentist.NewService(nil),
+		stored:  map[string]domain.Dentist{"CRO-SP 1234": {Id: 7, Surname: "Lima", Name: "Ana", Registration: "CRO-SP 1234"}},
+	}
+	s := NewService(nil, dentists)
+
+	data := "surname,name,registration\nLima,Ana,cro/sp 1234\nSouza,Bia,CRO-RJ 99\n"
+	report, err := s.Dentists([]byte(data), Options{})
+	if err != nil {
+		t.Fatalf("Dentists: %v", err)
+	}
+	if report.Skipped != 1 || report.Created != 1 || report.Rows[0].Id != 7 {
+		t.Errorf("report = %+v, want dentist 7 skipped and one created", report)
+	}
+	if len(dentists.lookups) != 1 {
+		t.Errorf("got %d lookups, want 1", len(dentists.lookups))
+	}
+	if created := dentists.batches[0][0]; created.Registration != "CRO-RJ 99" {
+		t.Errorf("created = %+v, want the registration normalized", created)
+	}
+}
@@ -0,0 +1,84 @@
+package insurance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// Decision é a resposta da operadora a um pedido de autorização prévia. Status requested
+// indica que a operadora ainda vai analisar o pedido e a decisão será registrada depois.
+type Decision struct {
+	Status    string `json:"status"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+// Insurer envia pedidos de autorização prévia à operadora do convênio
+type Insurer interface {
+	RequestAuthorization(plan domain.InsurancePlan, membership domain.Membership, a domain.PreAuthorization) (Decision, error)
+}
+
+type httpInsurer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPInsurer cria um Insurer que envia os pedidos em JSON para o endpoint da operadora
+func NewHTTPInsurer(url string) Insurer {
+	return &httpInsurer{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (i *httpInsurer) RequestAuthorization(plan domain.InsurancePlan, membership domain.Membership, a domain.PreAuthorization) (Decision, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"insurer":        plan.Insurer,
+		"plan":           plan.Name,
+		"card_number":    membership.CardNumber,
+		"procedure_code": a.ProcedureCode,
+		"request_id":     a.Id,
+	})
+	if err != nil {
+		return Decision{}, err
+	}
+	response, err := i.client.Post(i.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return Decision{}, fmt.Errorf("insurer responded with status %d", response.StatusCode)
+	}
+
+	var decision Decision
+	if err := json.NewDecoder(response.Body).Decode(&decision); err != nil {
+		return Decision{}, err
+	}
+	switch decision.Status {
+	case domain.AuthorizationRequested, domain.AuthorizationApproved, domain.AuthorizationDenied:
+		return decision, nil
+	default:
+		return Decision{}, fmt.Errorf("insurer responded with unknown status %q", decision.Status)
+	}
+}
+
+type stubInsurer struct{}
+
+// NewStubInsurer cria um Insurer local que responde na hora, aprovando os procedimentos
+// cobertos pelo plano e negando os demais. Usado em desenvolvimento e testes, quando não
+// há endpoint da operadora configurado.
+func NewStubInsurer() Insurer {
+	return stubInsurer{}
+}
+
+func (stubInsurer) RequestAuthorization(plan domain.InsurancePlan, membership domain.Membership, a domain.PreAuthorization) (Decision, error) {
+	reference := "STUB-" + strconv.Itoa(a.Id)
+	if rule, ok := coverageFor(plan, a.ProcedureCode); ok && rule.Percent > 0 {
+		return Decision{Status: domain.AuthorizationApproved, Reference: reference}, nil
+	}
+	return Decision{Status: domain.AuthorizationDenied, Reference: reference, Reason: "procedure not covered by plan"}, nil
+}
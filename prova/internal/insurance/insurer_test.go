@@ -0,0 +1,76 @@
+package insurance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+var testPlan = domain.InsurancePlan{
+	Name:    "Essencial",
+	Insurer: "Odonto",
+	Coverage: []domain.CoverageRule{
+		{ProcedureCode: "81000030", Percent: 100},
+		{ProcedureCode: "85100196", Percent: 0},
+	},
+}
+
+func TestStubInsurer(t *testing.T) {
+	insurer := NewStubInsurer()
+	cases := []struct {
+		code   string
+		status string
+	}{
+		{"81000030", domain.AuthorizationApproved},
+		{"85100196", domain.AuthorizationDenied},
+		{"00000000", domain.AuthorizationDenied},
+	}
+	for _, c := range cases {
+		decision, err := insurer.RequestAuthorization(testPlan, domain.Membership{}, domain.PreAuthorization{Id: 7, ProcedureCode: c.code})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", c.code, err)
+		}
+		if decision.Status != c.status {
+			t.Errorf("%s: status = %q, want %q", c.code, decision.Status, c.status)
+		}
+		if decision.Reference != "STUB-7" {
+			t.Errorf("%s: reference = %q, want STUB-7", c.code, decision.Reference)
+		}
+	}
+}
+
+func TestHTTPInsurer(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		json.NewEncoder(w).Encode(Decision{Status: domain.AuthorizationRequested, Reference: "A-1"})
+	}))
+	defer server.Close()
+
+	decision, err := NewHTTPInsurer(server.URL).RequestAuthorization(testPlan, domain.Membership{CardNumber: "123"}, domain.PreAuthorization{Id: 3, ProcedureCode: "81000030"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if decision.Status != domain.AuthorizationRequested || decision.Reference != "A-1" {
+		t.Errorf("decision = %+v", decision)
+	}
+	if received["card_number"] != "123" || received["procedure_code"] != "81000030" || received["insurer"] != "Odonto" {
+		t.Errorf("request = %v", received)
+	}
+}
+
+func TestHTTPInsurerRejectsUnknownStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Decision{Status: "maybe"})
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPInsurer(server.URL).RequestAuthorization(testPlan, domain.Membership{}, domain.PreAuthorization{}); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
@@ -0,0 +1,92 @@
+package insurance
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetPlans retorna todos os planos de convênio
+	GetPlans() ([]domain.InsurancePlan, error)
+	// GetPlan retorna um plano de convênio por id
+	GetPlan(id int) (domain.InsurancePlan, error)
+	// CreatePlan insere um novo plano de convênio
+	CreatePlan(p domain.InsurancePlan) (domain.InsurancePlan, error)
+	// UpdatePlan substitui os dados e as coberturas de um plano
+	UpdatePlan(p domain.InsurancePlan) error
+	// GetMembership retorna uma adesão por id
+	GetMembership(id int) (domain.Membership, error)
+	// GetMemberships retorna as adesões de um paciente
+	GetMemberships(patientID int) ([]domain.Membership, error)
+	// CreateMembership insere uma nova adesão
+	CreateMembership(m domain.Membership) (domain.Membership, error)
+	// DeleteMembership exclui uma adesão de um paciente
+	DeleteMembership(patientID, id int) error
+	// GetPreAuthorization retorna um pedido de autorização prévia por id
+	GetPreAuthorization(id int) (domain.PreAuthorization, error)
+	// GetPreAuthorizations retorna os pedidos de autorização prévia de um paciente
+	GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error)
+	// CreatePreAuthorization insere um novo pedido de autorização prévia
+	CreatePreAuthorization(a domain.PreAuthorization) (domain.PreAuthorization, error)
+	// Decide registra a decisão do convênio sobre um pedido pendente
+	Decide(id int, status, reference, reason string, decidedAt time.Time) error
+}
+
+type repository struct {
+	store store.InsuranceStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.InsuranceStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetPlans() ([]domain.InsurancePlan, error) {
+	return r.store.GetInsurancePlans()
+}
+
+func (r *repository) GetPlan(id int) (domain.InsurancePlan, error) {
+	return r.store.GetInsurancePlanByID(id)
+}
+
+func (r *repository) CreatePlan(p domain.InsurancePlan) (domain.InsurancePlan, error) {
+	return r.store.SaveInsurancePlan(p)
+}
+
+func (r *repository) UpdatePlan(p domain.InsurancePlan) error {
+	return r.store.UpdateInsurancePlan(p)
+}
+
+func (r *repository) GetMembership(id int) (domain.Membership, error) {
+	return r.store.GetMembershipByID(id)
+}
+
+func (r *repository) GetMemberships(patientID int) ([]domain.Membership, error) {
+	return r.store.GetMemberships(patientID)
+}
+
+func (r *repository) CreateMembership(m domain.Membership) (domain.Membership, error) {
+	return r.store.SaveMembership(m)
+}
+
+func (r *repository) DeleteMembership(patientID, id int) error {
+	return r.store.DeleteMembership(patientID, id)
+}
+
+func (r *repository) GetPreAuthorization(id int) (domain.PreAuthorization, error) {
+	return r.store.GetPreAuthorizationByID(id)
+}
+
+func (r *repository) GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error) {
+	return r.store.GetPreAuthorizations(patientID)
+}
+
+func (r *repository) CreatePreAuthorization(a domain.PreAuthorization) (domain.PreAuthorization, error) {
+	return r.store.SavePreAuthorization(a)
+}
+
+func (r *repository) Decide(id int, status, reference, reason string, decidedAt time.Time) error {
+	return r.store.UpdatePreAuthorizationStatus(id, status, reference, reason, decidedAt)
+}
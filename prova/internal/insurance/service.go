@@ -0,0 +1,395 @@
+package insurance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/internal/treatment"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// DateLayout é o formato das datas de validade da adesão
+const DateLayout = "02/01/2006"
+
+var (
+	// ErrNotFound indica que o plano, a adesão, o pedido de autorização ou o paciente não existe
+	ErrNotFound = errors.New("insurance record not found")
+	// ErrInvalidPlan indica que os dados do plano de convênio são inválidos
+	ErrInvalidPlan = errors.New("invalid insurance plan")
+	// ErrPlanExists indica que já existe um plano com o mesmo nome na operadora
+	ErrPlanExists = errors.New("insurance plan already exists for this insurer")
+	// ErrInvalidMembership indica que os dados da adesão são inválidos
+	ErrInvalidMembership = errors.New("invalid insurance membership")
+	// ErrCardExists indica que o número da carteirinha já está cadastrado no plano
+	ErrCardExists = errors.New("card number already registered for this plan")
+	// ErrInvalidAuthorization indica que o pedido de autorização prévia é inválido
+	ErrInvalidAuthorization = errors.New("invalid pre-authorization")
+	// ErrAlreadyDecided indica que o pedido de autorização prévia já foi aprovado ou negado
+	ErrAlreadyDecided = errors.New("pre-authorization has already been decided")
+)
+
+// Coverage é a parte do valor de um procedimento paga pelo convênio do paciente
+type Coverage struct {
+	IdMembership int
+	Percent      float64
+	Amount       float64
+}
+
+type Service interface {
+	// GetPlans retorna todos os planos de convênio
+	GetPlans() ([]domain.InsurancePlan, error)
+	// GetPlan retorna um plano de convênio por id
+	GetPlan(id int) (domain.InsurancePlan, error)
+	// CreatePlan cadastra um novo plano de convênio com suas coberturas
+	CreatePlan(p domain.InsurancePlan) (domain.InsurancePlan, error)
+	// UpdatePlan substitui os dados e as coberturas de um plano
+	UpdatePlan(id int, p domain.InsurancePlan) (domain.InsurancePlan, error)
+	// GetMemberships retorna as adesões a convênios de um paciente
+	GetMemberships(patientID int) ([]domain.Membership, error)
+	// AddMembership registra a adesão de um paciente a um plano
+	AddMembership(patientID int, m domain.Membership) (domain.Membership, error)
+	// DeleteMembership exclui uma adesão de um paciente
+	DeleteMembership(patientID, id int) error
+	// GetPreAuthorization retorna um pedido de autorização prévia por id
+	GetPreAuthorization(id int) (domain.PreAuthorization, error)
+	// GetPreAuthorizations retorna os pedidos de autorização prévia de um paciente
+	GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error)
+	// RequestPreAuthorization registra um pedido de autorização prévia e o envia à operadora
+	RequestPreAuthorization(patientID int, a domain.PreAuthorization) (domain.PreAuthorization, error)
+	// Decide registra a decisão da operadora sobre um pedido pendente
+	Decide(id int, status, reference, reason string) (domain.PreAuthorization, error)
+	// CoverageFor calcula quanto do valor do procedimento da consulta o convênio cobre
+	CoverageFor(a domain.AppointmentDTO, amount float64) (Coverage, error)
+}
+
+type service struct {
+	r            Repository
+	insurer      Insurer
+	patients     patient.Service
+	procedures   procedure.Service
+	appointments appointment.Service
+	treatments   treatment.Service
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, insurer Insurer, patients patient.Service, procedures procedure.Service, appointments appointment.Service, treatments treatment.Service) Service {
+	return &service{r, insurer, patients, procedures, appointments, treatments}
+}
+
+func (s *service) GetPlans() ([]domain.InsurancePlan, error) {
+	return s.r.GetPlans()
+}
+
+func (s *service) GetPlan(id int) (domain.InsurancePlan, error) {
+	p, err := s.r.GetPlan(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.InsurancePlan{}, ErrNotFound
+	}
+	return p, err
+}
+
+func (s *service) CreatePlan(p domain.InsurancePlan) (domain.InsurancePlan, error) {
+	if err := s.validatePlan(&p); err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	saved, err := s.r.CreatePlan(p)
+	if errors.Is(err, store.ErrDuplicate) {
+		return domain.InsurancePlan{}, ErrPlanExists
+	}
+	return saved, err
+}
+
+func (s *service) UpdatePlan(id int, p domain.InsurancePlan) (domain.InsurancePlan, error) {
+	if _, err := s.GetPlan(id); err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	if err := s.validatePlan(&p); err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	p.Id = id
+	if err := s.r.UpdatePlan(p); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			return domain.InsurancePlan{}, ErrPlanExists
+		}
+		return domain.InsurancePlan{}, err
+	}
+	return s.GetPlan(id)
+}
+
+func (s *service) GetMemberships(patientID int) ([]domain.Membership, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return nil, ErrNotFound
+	}
+	return s.r.GetMemberships(patientID)
+}
+
+func (s *service) AddMembership(patientID int, m domain.Membership) (domain.Membership, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return domain.Membership{}, ErrNotFound
+	}
+	if _, err := s.GetPlan(m.IdPlan); err != nil {
+		return domain.Membership{}, fmt.Errorf("%w: insurance plan %d not found", ErrInvalidMembership, m.IdPlan)
+	}
+	m.CardNumber = strings.TrimSpace(m.CardNumber)
+	if m.CardNumber == "" {
+		return domain.Membership{}, fmt.Errorf("%w: card_number is required", ErrInvalidMembership)
+	}
+	from, err := time.Parse(DateLayout, m.ValidFrom)
+	if err != nil {
+		return domain.Membership{}, fmt.Errorf("%w: valid_from must use format %s", ErrInvalidMembership, DateLayout)
+	}
+	if m.ValidUntil != "" {
+		until, err := time.Parse(DateLayout, m.ValidUntil)
+		if err != nil {
+			return domain.Membership{}, fmt.Errorf("%w: valid_until must use format %s", ErrInvalidMembership, DateLayout)
+		}
+		if until.Before(from) {
+			return domain.Membership{}, fmt.Errorf("%w: valid_until can't be before valid_from", ErrInvalidMembership)
+		}
+	}
+	m.IdPatient = patientID
+
+	saved, err := s.r.CreateMembership(m)
+	if errors.Is(err, store.ErrDuplicate) {
+		return domain.Membership{}, ErrCardExists
+	}
+	return saved, err
+}
+
+func (s *service) DeleteMembership(patientID, id int) error {
+	err := s.r.DeleteMembership(patientID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *service) GetPreAuthorization(id int) (domain.PreAuthorization, error) {
+	a, err := s.r.GetPreAuthorization(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.PreAuthorization{}, ErrNotFound
+	}
+	return a, err
+}
+
+func (s *service) GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return nil, ErrNotFound
+	}
+	return s.r.GetPreAuthorizations(patientID)
+}
+
+func (s *service) RequestPreAuthorization(patientID int, a domain.PreAuthorization) (domain.PreAuthorization, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return domain.PreAuthorization{}, ErrNotFound
+	}
+	membership, err := s.r.GetMembership(a.IdMembership)
+	if err != nil || membership.IdPatient != patientID {
+		return domain.PreAuthorization{}, fmt.Errorf("%w: membership %d not found for patient", ErrInvalidAuthorization, a.IdMembership)
+	}
+	a.ProcedureCode = strings.ToUpper(strings.TrimSpace(a.ProcedureCode))
+	if (a.IdAppointment == 0) == (a.IdTreatmentPlan == 0) {
+		return domain.PreAuthorization{}, fmt.Errorf("%w: inform either id_appointment or id_treatment_plan", ErrInvalidAuthorization)
+	}
+
+	on := time.Now()
+	if a.IdAppointment != 0 {
+		ap, err := s.appointments.GetByID(a.IdAppointment)
+		if err != nil || ap.Id == 0 || ap.Patient.Id != patientID {
+			return domain.PreAuthorization{}, fmt.Errorf("%w: appointment %d not found for patient", ErrInvalidAuthorization, a.IdAppointment)
+		}
+		if on, err = time.Parse(appointment.DateLayout, ap.AppointmentDate); err != nil {
+			return domain.PreAuthorization{}, err
+		}
+	} else {
+		plan, err := s.treatments.GetByID(a.IdTreatmentPlan)
+		if err != nil || plan.IdPatient != patientID {
+			return domain.PreAuthorization{}, fmt.Errorf("%w: treatment plan %d not found for patient", ErrInvalidAuthorization, a.IdTreatmentPlan)
+		}
+		if !planHasProcedure(plan, a.ProcedureCode, 0) {
+			return domain.PreAuthorization{}, fmt.Errorf("%w: procedure %q is not part of treatment plan %d", ErrInvalidAuthorization, a.ProcedureCode, a.IdTreatmentPlan)
+		}
+	}
+	if !isValidOn(membership, on) {
+		return domain.PreAuthorization{}, fmt.Errorf("%w: membership %d is not valid on %s", ErrInvalidAuthorization, membership.Id, on.Format(DateLayout))
+	}
+	insurancePlan, err := s.GetPlan(membership.IdPlan)
+	if err != nil {
+		return domain.PreAuthorization{}, err
+	}
+	if _, ok := coverageFor(insurancePlan, a.ProcedureCode); !ok {
+		return domain.PreAuthorization{}, fmt.Errorf("%w: procedure %q is not covered by plan %s", ErrInvalidAuthorization, a.ProcedureCode, insurancePlan.Name)
+	}
+
+	a.IdPatient = patientID
+	a.Status = domain.AuthorizationRequested
+	saved, err := s.r.CreatePreAuthorization(a)
+	if err != nil {
+		return domain.PreAuthorization{}, err
+	}
+
+	// Se a operadora estiver indisponível o pedido continua pendente e a decisão pode ser registrada depois
+	decision, err := s.insurer.RequestAuthorization(insurancePlan, membership, saved)
+	if err != nil || decision.Status == domain.AuthorizationRequested {
+		return saved, nil
+	}
+	return s.Decide(saved.Id, decision.Status, decision.Reference, decision.Reason)
+}
+
+func (s *service) Decide(id int, status, reference, reason string) (domain.PreAuthorization, error) {
+	if status != domain.AuthorizationApproved && status != domain.AuthorizationDenied {
+		return domain.PreAuthorization{}, fmt.Errorf("%w: status must be approved or denied", ErrInvalidAuthorization)
+	}
+	a, err := s.GetPreAuthorization(id)
+	if err != nil {
+		return domain.PreAuthorization{}, err
+	}
+	if a.Status != domain.AuthorizationRequested {
+		return domain.PreAuthorization{}, ErrAlreadyDecided
+	}
+	if err := s.r.Decide(id, status, strings.TrimSpace(reference), strings.TrimSpace(reason), time.Now()); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.PreAuthorization{}, ErrAlreadyDecided
+		}
+		return domain.PreAuthorization{}, err
+	}
+	return s.GetPreAuthorization(id)
+}
+
+// CoverageFor usa a adesão vigente na data da consulta. Procedimentos que exigem autorização
+// prévia só são cobertos com um pedido aprovado para a consulta ou para o plano de tratamento
+// que a inclui.
+func (s *service) CoverageFor(a domain.AppointmentDTO, amount float64) (Coverage, error) {
+	on, err := time.Parse(appointment.DateLayout, a.AppointmentDate)
+	if err != nil {
+		return Coverage{}, err
+	}
+	memberships, err := s.r.GetMemberships(a.Patient.Id)
+	if err != nil {
+		return Coverage{}, err
+	}
+	for _, membership := range memberships {
+		if !isValidOn(membership, on) {
+			continue
+		}
+		plan, err := s.GetPlan(membership.IdPlan)
+		if err != nil {
+			return Coverage{}, err
+		}
+		rule, ok := coverageFor(plan, a.ProcedureCode)
+		if !ok || rule.Percent == 0 {
+			continue
+		}
+		if rule.RequiresAuthorization {
+			authorized, err := s.isAuthorized(membership, a)
+			if err != nil {
+				return Coverage{}, err
+			}
+			if !authorized {
+				continue
+			}
+		}
+		return Coverage{
+			IdMembership: membership.Id,
+			Percent:      rule.Percent,
+			Amount:       math.Round(amount*rule.Percent) / 100,
+		}, nil
+	}
+	return Coverage{}, nil
+}
+
+// isAuthorized verifica se há autorização aprovada para o procedimento da consulta
+func (s *service) isAuthorized(membership domain.Membership, a domain.AppointmentDTO) (bool, error) {
+	authorizations, err := s.r.GetPreAuthorizations(a.Patient.Id)
+	if err != nil {
+		return false, err
+	}
+	for _, authorization := range authorizations {
+		if authorization.Status != domain.AuthorizationApproved ||
+			authorization.IdMembership != membership.Id ||
+			authorization.ProcedureCode != a.ProcedureCode {
+			continue
+		}
+		if authorization.IdAppointment == a.Id {
+			return true, nil
+		}
+		if authorization.IdTreatmentPlan != 0 {
+			plan, err := s.treatments.GetByID(authorization.IdTreatmentPlan)
+			if err != nil {
+				continue
+			}
+			if planHasProcedure(plan, a.ProcedureCode, a.Id) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// validatePlan valida o plano e normaliza os códigos e percentuais das coberturas
+func (s *service) validatePlan(p *domain.InsurancePlan) error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Insurer = strings.TrimSpace(p.Insurer)
+	if p.Name == "" || p.Insurer == "" {
+		return fmt.Errorf("%w: name and insurer are required", ErrInvalidPlan)
+	}
+	seen := make(map[string]bool)
+	for i := range p.Coverage {
+		rule := &p.Coverage[i]
+		rule.ProcedureCode = strings.ToUpper(strings.TrimSpace(rule.ProcedureCode))
+		if seen[rule.ProcedureCode] {
+			return fmt.Errorf("%w: procedure %q is repeated", ErrInvalidPlan, rule.ProcedureCode)
+		}
+		seen[rule.ProcedureCode] = true
+		if _, err := s.procedures.GetByCode(rule.ProcedureCode); err != nil {
+			return fmt.Errorf("%w: procedure %q not found in catalog", ErrInvalidPlan, rule.ProcedureCode)
+		}
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidPlan)
+		}
+		rule.Percent = math.Round(rule.Percent*100) / 100
+	}
+	return nil
+}
+
+// coverageFor retorna a regra de cobertura do plano para o procedimento
+func coverageFor(plan domain.InsurancePlan, procedureCode string) (domain.CoverageRule, bool) {
+	for _, rule := range plan.Coverage {
+		if rule.ProcedureCode == procedureCode {
+			return rule, true
+		}
+	}
+	return domain.CoverageRule{}, false
+}
+
+// isValidOn verifica se a adesão está vigente no dia informado
+func isValidOn(m domain.Membership, on time.Time) bool {
+	day := time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, time.UTC)
+	from, err := time.Parse(DateLayout, m.ValidFrom)
+	if err != nil || day.Before(from) {
+		return false
+	}
+	if m.ValidUntil == "" {
+		return true
+	}
+	until, err := time.Parse(DateLayout, m.ValidUntil)
+	return err == nil && !day.After(until)
+}
+
+// planHasProcedure verifica se o plano de tratamento inclui o procedimento, vinculado à
+// consulta informada quando appointmentID não é zero
+func planHasProcedure(plan domain.TreatmentPlan, procedureCode string, appointmentID int) bool {
+	for _, item := range plan.Items {
+		if item.ProcedureCode == procedureCode && (appointmentID == 0 || item.IdAppointment == appointmentID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package invoice
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetByID retorna uma fatura por id
+	GetByID(id int) (domain.Invoice, error)
+	// GetAll retorna as faturas de um paciente, ou de todos quando patientID é zero
+	GetAll(patientID int) ([]domain.Invoice, error)
+	// Create insere uma nova fatura
+	Create(i domain.Invoice) (domain.Invoice, error)
+	// AddPayment registra um pagamento com a situação que decide devolver para a fatura bloqueada
+	AddPayment(p domain.Payment, decide func(domain.Invoice) (string, error)) error
+	// Cancel cancela uma fatura
+	Cancel(id int) error
+	// GetBalance retorna o saldo de um paciente
+	GetBalance(patientID int) (domain.PatientBalance, error)
+}
+
+type repository struct {
+	store store.InvoiceStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.InvoiceStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetByID(id int) (domain.Invoice, error) {
+	return r.store.GetInvoiceByID(id)
+}
+
+func (r *repository) GetAll(patientID int) ([]domain.Invoice, error) {
+	return r.store.GetInvoices(patientID)
+}
+
+func (r *repository) Create(i domain.Invoice) (domain.Invoice, error) {
+	return r.store.SaveInvoice(i)
+}
+
+func (r *repository) AddPayment(p domain.Payment, decide func(domain.Invoice) (string, error)) error {
+	return r.store.SavePayment(p, decide)
+}
+
+func (r *repository) Cancel(id int) error {
+	return r.store.CancelInvoice(id)
+}
+
+func (r *repository) GetBalance(patientID int) (domain.PatientBalance, error) {
+	return r.store.GetPatientBalance(patientID)
+}
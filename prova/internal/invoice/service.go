@@ -0,0 +1,277 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/insurance"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+var (
+	// ErrNotFound indica que a fatura ou o paciente não existe
+	ErrNotFound = errors.New("invoice not found")
+	// ErrInvalidInvoice indica que os dados da fatura são inválidos
+	ErrInvalidInvoice = errors.New("invalid invoice")
+	// ErrAlreadyInvoiced indica que alguma das consultas já está em outra fatura
+	ErrAlreadyInvoiced = errors.New("one of the appointments has already been invoiced")
+	// ErrInvalidPayment indica que o pagamento é inválido para a fatura
+	ErrInvalidPayment = errors.New("invalid payment")
+	// ErrClosed indica que a fatura está cancelada ou quitada
+	ErrClosed = errors.New("invoice is cancelled or already paid")
+)
+
+// Request reúne os dados para gerar uma fatura a partir de consultas concluídas
+type Request struct {
+	IdPatient      int     `json:"id_patient" binding:"required"`
+	IdAppointments []int   `json:"id_appointments" binding:"required"`
+	Discount       float64 `json:"discount"`
+	TaxRate        float64 `json:"tax_rate"`
+}
+
+type Service interface {
+	// GetByID retorna uma fatura por id
+	GetByID(id int) (domain.Invoice, error)
+	// GetAll retorna as faturas, opcionalmente filtradas por paciente
+	GetAll(patientID int) ([]domain.Invoice, error)
+	// Create gera uma fatura a partir de consultas concluídas do paciente
+	Create(r Request) (domain.Invoice, error)
+	// AddPayment registra um pagamento total ou parcial
+	AddPayment(id int, p domain.Payment) (domain.Invoice, error)
+	// Cancel cancela uma fatura sem pagamentos
+	Cancel(id int) (domain.Invoice, error)
+	// Balance retorna o saldo do paciente
+	Balance(patientID int) (domain.PatientBalance, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	patients     patient.Service
+	procedures   procedure.Service
+	insurance    insurance.Service
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, appointments appointment.Service, patients patient.Service, procedures procedure.Service, insurance insurance.Service) Service {
+	return &service{r, appointments, patients, procedures, insurance}
+}
+
+func (s *service) GetByID(id int) (domain.Invoice, error) {
+	i, err := s.r.GetByID(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.Invoice{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	return rounded(i), nil
+}
+
+func (s *service) GetAll(patientID int) ([]domain.Invoice, error) {
+	invoices, err := s.r.GetAll(patientID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range invoices {
+		invoices[i] = rounded(invoices[i])
+	}
+	return invoices, nil
+}
+
+func (s *service) Create(r Request) (domain.Invoice, error) {
+	if _, err := s.patients.GetByID(r.IdPatient); err != nil {
+		return domain.Invoice{}, ErrNotFound
+	}
+	if len(r.IdAppointments) == 0 {
+		return domain.Invoice{}, fmt.Errorf("%w: at least one appointment is required", ErrInvalidInvoice)
+	}
+	if r.Discount < 0 || r.TaxRate < 0 || r.TaxRate > 100 {
+		return domain.Invoice{}, fmt.Errorf("%w: discount can't be negative and tax_rate must be between 0 and 100", ErrInvalidInvoice)
+	}
+
+	invoice := domain.Invoice{IdPatient: r.IdPatient, Status: domain.InvoiceOpen, TaxRate: r.TaxRate}
+	seen := make(map[int]bool)
+	for _, id := range r.IdAppointments {
+		if seen[id] {
+			return domain.Invoice{}, fmt.Errorf("%w: appointment %d is repeated", ErrInvalidInvoice, id)
+		}
+		seen[id] = true
+
+		item, membershipID, err := s.itemFor(r.IdPatient, id)
+		if err != nil {
+			return domain.Invoice{}, err
+		}
+		// a fatura tem uma só adesão, que é quem recebe a cobrança da parte da operadora
+		if membershipID != 0 && invoice.IdMembership != 0 && membershipID != invoice.IdMembership {
+			return domain.Invoice{}, fmt.Errorf("%w: appointment %d is covered by another membership, invoice it separately", ErrInvalidInvoice, id)
+		}
+		if invoice.IdMembership == 0 {
+			invoice.IdMembership = membershipID
+		}
+		invoice.Items = append(invoice.Items, item)
+		invoice.Subtotal += item.Amount
+		invoice.InsurerPortion += item.CoveredAmount
+	}
+
+	invoice.Subtotal = roundCents(invoice.Subtotal)
+	invoice.InsurerPortion = roundCents(invoice.InsurerPortion)
+	invoice.Discount = roundCents(r.Discount)
+	if invoice.Discount > roundCents(invoice.Subtotal-invoice.InsurerPortion) {
+		return domain.Invoice{}, fmt.Errorf("%w: discount can't exceed the patient's share of the subtotal", ErrInvalidInvoice)
+	}
+	applyTax(&invoice)
+	if invoice.Total == 0 {
+		invoice.Status = domain.InvoicePaid
+	}
+
+	saved, err := s.r.Create(invoice)
+	if errors.Is(err, store.ErrDuplicate) {
+		return domain.Invoice{}, ErrAlreadyInvoiced
+	}
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	return rounded(saved), nil
+}
+
+func (s *service) AddPayment(id int, p domain.Payment) (domain.Invoice, error) {
+	switch p.Method {
+	case domain.PaymentCash, domain.PaymentCard, domain.PaymentInsurance:
+	default:
+		return domain.Invoice{}, fmt.Errorf("%w: method must be cash, card or insurance", ErrInvalidPayment)
+	}
+	p.IdInvoice = id
+	p.Amount = roundCents(p.Amount)
+	// O saldo é conferido sobre a fatura bloqueada pela transação do pagamento
+	err := s.r.AddPayment(p, func(i domain.Invoice) (string, error) {
+		return paymentStatus(rounded(i), p)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.Invoice{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	return s.GetByID(id)
+}
+
+// paymentStatus valida o pagamento contra o saldo da fatura e retorna a situação que ela terá depois dele
+func paymentStatus(i domain.Invoice, p domain.Payment) (string, error) {
+	if i.Status == domain.InvoiceCancelled || i.Status == domain.InvoicePaid {
+		return "", ErrClosed
+	}
+	// Pagamentos do convênio quitam a parte da operadora e os demais a parte do paciente
+	due := i.PatientPortion
+	if p.Method == domain.PaymentInsurance {
+		due = i.InsurerPortion
+	}
+	for _, paid := range i.Payments {
+		if (paid.Method == domain.PaymentInsurance) == (p.Method == domain.PaymentInsurance) {
+			due -= paid.Amount
+		}
+	}
+	due = roundCents(due)
+	if p.Amount <= 0 || p.Amount > due {
+		return "", fmt.Errorf("%w: amount must be positive and at most the %s balance of %.2f", ErrInvalidPayment, payerOf(p.Method), due)
+	}
+	if roundCents(i.Balance-p.Amount) == 0 {
+		return domain.InvoicePaid, nil
+	}
+	return domain.InvoicePartiallyPaid, nil
+}
+
+func (s *service) Cancel(id int) (domain.Invoice, error) {
+	i, err := s.GetByID(id)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	if i.Status == domain.InvoiceCancelled || len(i.Payments) > 0 {
+		return domain.Invoice{}, ErrClosed
+	}
+	if err := s.r.Cancel(id); err != nil {
+		return domain.Invoice{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) Balance(patientID int) (domain.PatientBalance, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return domain.PatientBalance{}, ErrNotFound
+	}
+	b, err := s.r.GetBalance(patientID)
+	if err != nil {
+		return domain.PatientBalance{}, err
+	}
+	b.Invoiced = roundCents(b.Invoiced)
+	b.Paid = roundCents(b.Paid)
+	b.Balance = roundCents(b.Balance)
+	return b, nil
+}
+
+// itemFor gera o item da fatura para uma consulta concluída do paciente, cobrando o preço do procedimento
+// e separando a parte coberta pelo convênio. Retorna também a adesão usada na cobertura.
+func (s *service) itemFor(patientID, appointmentID int) (domain.InvoiceItem, int, error) {
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil || a.Id == 0 {
+		return domain.InvoiceItem{}, 0, fmt.Errorf("%w: appointment %d not found", ErrInvalidInvoice, appointmentID)
+	}
+	if a.Patient.Id != patientID {
+		return domain.InvoiceItem{}, 0, fmt.Errorf("%w: appointment %d belongs to another patient", ErrInvalidInvoice, appointmentID)
+	}
+	if a.Status != domain.StatusCompleted {
+		return domain.InvoiceItem{}, 0, fmt.Errorf("%w: appointment %d is not completed", ErrInvalidInvoice, appointmentID)
+	}
+	if a.ProcedureCode == "" {
+		return domain.InvoiceItem{}, 0, fmt.Errorf("%w: appointment %d has no procedure", ErrInvalidInvoice, appointmentID)
+	}
+	p, err := s.procedures.GetByCode(a.ProcedureCode)
+	if err != nil {
+		return domain.InvoiceItem{}, 0, fmt.Errorf("%w: procedure %q of appointment %d not found", ErrInvalidInvoice, a.ProcedureCode, appointmentID)
+	}
+	coverage, err := s.insurance.CoverageFor(a, p.Price)
+	if err != nil {
+		return domain.InvoiceItem{}, 0, err
+	}
+	return domain.InvoiceItem{
+		IdAppointment: a.Id,
+		ProcedureCode: p.Code,
+		Description:   p.Name + " - " + a.AppointmentDate,
+		Amount:        p.Price,
+		CoveredAmount: coverage.Amount,
+	}, coverage.IdMembership, nil
+}
+
+// applyTax calcula o imposto e o total da fatura. O imposto é dividido entre a operadora e o paciente na
+// proporção das suas partes; o desconto reduz apenas a parte do paciente.
+func applyTax(i *domain.Invoice) {
+	insurerTax := roundCents(i.InsurerPortion * i.TaxRate / 100)
+	i.Tax = roundCents((i.Subtotal - i.Discount) * i.TaxRate / 100)
+	i.Total = roundCents(i.Subtotal - i.Discount + i.Tax)
+	i.InsurerPortion = roundCents(i.InsurerPortion + insurerTax)
+	i.PatientPortion = roundCents(i.Total - i.InsurerPortion)
+}
+
+// rounded arredonda os valores calculados pelo banco para centavos
+func rounded(i domain.Invoice) domain.Invoice {
+	i.Paid = roundCents(i.Paid)
+	i.Balance = roundCents(i.Balance)
+	return i
+}
+
+// payerOf identifica quem é responsável pelo pagamento feito na forma informada
+func payerOf(method string) string {
+	if method == domain.PaymentInsurance {
+		return "insurer"
+	}
+	return "patient"
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,66 @@
+package invoice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+func TestPaymentStatus(t *testing.T) {
+	open := domain.Invoice{
+		Status:         domain.InvoicePartiallyPaid,
+		Total:          300,
+		InsurerPortion: 200,
+		PatientPortion: 100,
+		Paid:           60,
+		Balance:        240,
+		Payments:       []domain.Payment{{Method: domain.PaymentCash, Amount: 60}},
+	}
+	insurerPaid := open
+	insurerPaid.Paid, insurerPaid.Balance = 260, 40
+	insurerPaid.Payments = append([]domain.Payment{{Method: domain.PaymentInsurance, Amount: 200}}, open.Payments...)
+
+	cases := []struct {
+		name    string
+		invoice domain.Invoice
+		payment domain.Payment
+		status  string
+		err     error
+	}{
+		{"part of the patient share", open, domain.Payment{Method: domain.PaymentCard, Amount: 20}, domain.InvoicePartiallyPaid, nil},
+		{"whole insurer share", open, domain.Payment{Method: domain.PaymentInsurance, Amount: 200}, domain.InvoicePartiallyPaid, nil},
+		{"last payment", insurerPaid, domain.Payment{Method: domain.PaymentCash, Amount: 40}, domain.InvoicePaid, nil},
+		{"more than the patient share", open, domain.Payment{Method: domain.PaymentCash, Amount: 40.01}, "", ErrInvalidPayment},
+		{"zero amount", open, domain.Payment{Method: domain.PaymentCash}, "", ErrInvalidPayment},
+		{"paid invoice", domain.Invoice{Status: domain.InvoicePaid}, domain.Payment{Method: domain.PaymentCash, Amount: 1}, "", ErrClosed},
+		{"cancelled invoice", domain.Invoice{Status: domain.InvoiceCancelled}, domain.Payment{Method: domain.PaymentCash, Amount: 1}, "", ErrClosed},
+	}
+	for _, c := range cases {
+		status, err := paymentStatus(c.invoice, c.payment)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: error = %v, want %v", c.name, err, c.err)
+		}
+		if status != c.status {
+			t.Errorf("%s: status = %q, want %q", c.name, status, c.status)
+		}
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	i := domain.Invoice{Subtotal: 1000, Discount: 100, TaxRate: 10, InsurerPortion: 600}
+	applyTax(&i)
+	if i.Tax != 90 || i.Total != 990 {
+		t.Fatalf("tax = %.2f, total = %.2f, want 90 and 990", i.Tax, i.Total)
+	}
+	// 60 do imposto cabem à operadora e 30 ao paciente, cuja parte já tem o desconto
+	if i.InsurerPortion != 660 || i.PatientPortion != 330 {
+		t.Errorf("insurer = %.2f, patient = %.2f, want 660 and 330", i.InsurerPortion, i.PatientPortion)
+	}
+
+	untaxed := domain.Invoice{Subtotal: 200, InsurerPortion: 200}
+	applyTax(&untaxed)
+	if untaxed.Total != 200 || untaxed.InsurerPortion != 200 || untaxed.PatientPortion != 0 {
+		t.Errorf("untaxed invoice = %+v, want the insurer to owe the whole subtotal", untaxed)
+	}
+}
@@ -0,0 +1,56 @@
+package note
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetByID retorna uma nota clínica por id
+	GetByID(id int) (domain.ClinicalNote, error)
+	// GetByAppointment retorna as notas clínicas de uma consulta
+	GetByAppointment(appointmentID int) ([]domain.ClinicalNote, error)
+	// GetByPatient retorna o histórico de notas clínicas de um paciente
+	GetByPatient(patientID int) ([]domain.ClinicalNote, error)
+	// Create insere uma nova nota clínica
+	Create(n domain.ClinicalNote) (domain.ClinicalNote, error)
+	// UpdateContent altera o conteúdo de uma nota não assinada
+	UpdateContent(id int, content string) error
+	// Sign assina uma nota clínica
+	Sign(id int, signedAt time.Time) error
+}
+
+type repository struct {
+	store store.NoteStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.NoteStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetByID(id int) (domain.ClinicalNote, error) {
+	return r.store.GetNoteByID(id)
+}
+
+func (r *repository) GetByAppointment(appointmentID int) ([]domain.ClinicalNote, error) {
+	return r.store.GetNotesByAppointment(appointmentID)
+}
+
+func (r *repository) GetByPatient(patientID int) ([]domain.ClinicalNote, error) {
+	return r.store.GetNotesByPatient(patientID)
+}
+
+func (r *repository) Create(n domain.ClinicalNote) (domain.ClinicalNote, error) {
+	return r.store.SaveNote(n)
+}
+
+func (r *repository) UpdateContent(id int, content string) error {
+	return r.store.UpdateNoteContent(id, content)
+}
+
+func (r *repository) Sign(id int, signedAt time.Time) error {
+	return r.store.SignNote(id, signedAt)
+}
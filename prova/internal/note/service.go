@@ -0,0 +1,250 @@
+package note
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+var (
+	// ErrNotFound indica que a nota, consulta ou paciente não existe
+	ErrNotFound = errors.New("clinical note not found")
+	// ErrForbidden indica que o usuário não é o dentista responsável nem administrador
+	ErrForbidden = errors.New("only the treating dentist or an admin can access clinical notes")
+	// ErrSigned indica que a nota já foi assinada e só pode receber adendos
+	ErrSigned = errors.New("clinical note is signed and can only be amended through addenda")
+	// ErrNotSigned indica que adendos só podem ser adicionados a notas assinadas
+	ErrNotSigned = errors.New("addenda can only be added to signed notes, edit the note instead")
+	// ErrEmptyContent indica que o conteúdo da nota está vazio
+	ErrEmptyContent = errors.New("note content can't be empty")
+)
+
+type Service interface {
+	// GetByAppointment retorna as notas de uma consulta com seus adendos
+	GetByAppointment(caller auth.Claims, appointmentID int) ([]domain.ClinicalNote, error)
+	// GetByPatient retorna o histórico longitudinal de notas de um paciente. Administradores veem
+	// todas; dentistas, só as notas das consultas em que atenderam o paciente.
+	GetByPatient(caller auth.Claims, patientID int) ([]domain.ClinicalNote, error)
+	// Create registra uma nova nota na consulta
+	Create(caller auth.Claims, appointmentID int, content string) (domain.ClinicalNote, error)
+	// Update altera o conteúdo de uma nota ainda não assinada
+	Update(caller auth.Claims, appointmentID, noteID int, content string) (domain.ClinicalNote, error)
+	// Sign assina a nota, tornando-a imutável
+	Sign(caller auth.Claims, appointmentID, noteID int) (domain.ClinicalNote, error)
+	// AddAddendum adiciona um adendo assinado a uma nota assinada
+	AddAddendum(caller auth.Claims, appointmentID, noteID int, content string) (domain.ClinicalNote, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	patients     patient.Service
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, appointments appointment.Service, patients patient.Service) Service {
+	return &service{r, appointments, patients}
+}
+
+func (s *service) GetByAppointment(caller auth.Claims, appointmentID int) ([]domain.ClinicalNote, error) {
+	if _, err := s.treatingAppointment(caller, appointmentID, true); err != nil {
+		return nil, err
+	}
+	notes, err := s.r.GetByAppointment(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return withAddenda(notes), nil
+}
+
+func (s *service) GetByPatient(caller auth.Claims, patientID int) ([]domain.ClinicalNote, error) {
+	// o papel é conferido antes da busca, para que o status não revele quais pacientes existem
+	if caller.Role != domain.RoleAdmin && caller.Role != domain.RoleDentist {
+		return nil, ErrForbidden
+	}
+	p, err := s.patients.GetByID(patientID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	notes, err := s.r.GetByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Role == domain.RoleDentist {
+		appointments, err := s.appointments.GetByDocumentPatient(p.Document)
+		if err != nil {
+			return nil, err
+		}
+		treated := treatedAppointments(appointments, caller.DentistID)
+		if len(treated) == 0 {
+			return nil, ErrForbidden
+		}
+		// as notas de outros dentistas, mesmo sobre o mesmo paciente, ficam de fora
+		visible := notes[:0]
+		for _, n := range notes {
+			if treated[n.IdAppointment] {
+				visible = append(visible, n)
+			}
+		}
+		notes = visible
+	}
+	return withAddenda(notes), nil
+}
+
+func (s *service) Create(caller auth.Claims, appointmentID int, content string) (domain.ClinicalNote, error) {
+	if strings.TrimSpace(content) == "" {
+		return domain.ClinicalNote{}, ErrEmptyContent
+	}
+	a, err := s.treatingAppointment(caller, appointmentID, false)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	return s.r.Create(domain.ClinicalNote{
+		IdAppointment: a.Id,
+		IdPatient:     a.Patient.Id,
+		IdDentist:     caller.DentistID,
+		Content:       content,
+	})
+}
+
+func (s *service) Update(caller auth.Claims, appointmentID, noteID int, content string) (domain.ClinicalNote, error) {
+	if strings.TrimSpace(content) == "" {
+		return domain.ClinicalNote{}, ErrEmptyContent
+	}
+	n, err := s.authoredNote(caller, appointmentID, noteID)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	if n.IsSigned() {
+		return domain.ClinicalNote{}, ErrSigned
+	}
+	if err := s.r.UpdateContent(n.Id, content); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ClinicalNote{}, ErrSigned
+		}
+		return domain.ClinicalNote{}, err
+	}
+	return s.r.GetByID(n.Id)
+}
+
+func (s *service) Sign(caller auth.Claims, appointmentID, noteID int) (domain.ClinicalNote, error) {
+	n, err := s.authoredNote(caller, appointmentID, noteID)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	if n.IsSigned() {
+		return domain.ClinicalNote{}, ErrSigned
+	}
+	if err := s.r.Sign(n.Id, time.Now()); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ClinicalNote{}, ErrSigned
+		}
+		return domain.ClinicalNote{}, err
+	}
+	return s.r.GetByID(n.Id)
+}
+
+func (s *service) AddAddendum(caller auth.Claims, appointmentID, noteID int, content string) (domain.ClinicalNote, error) {
+	if strings.TrimSpace(content) == "" {
+		return domain.ClinicalNote{}, ErrEmptyContent
+	}
+	n, err := s.noteOfAppointment(caller, appointmentID, noteID, false)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	if !n.IsSigned() {
+		return domain.ClinicalNote{}, ErrNotSigned
+	}
+	parentID := n.Id
+	if n.IdParent != 0 {
+		parentID = n.IdParent
+	}
+	return s.r.Create(domain.ClinicalNote{
+		IdAppointment: n.IdAppointment,
+		IdPatient:     n.IdPatient,
+		IdDentist:     caller.DentistID,
+		IdParent:      parentID,
+		Content:       content,
+		SignedAt:      time.Now().Format("02/01/2006 15:04"),
+	})
+}
+
+// treatingAppointment busca a consulta e verifica se o usuário é o dentista responsável por ela.
+// Quando allowAdmin é verdadeiro, administradores também têm acesso. Os demais papéis são
+// recusados antes da busca, como em GetByPatient.
+func (s *service) treatingAppointment(caller auth.Claims, appointmentID int, allowAdmin bool) (domain.AppointmentDTO, error) {
+	admin := allowAdmin && caller.Role == domain.RoleAdmin
+	if !admin && caller.Role != domain.RoleDentist {
+		return domain.AppointmentDTO{}, ErrForbidden
+	}
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil || a.Id == 0 {
+		return domain.AppointmentDTO{}, ErrNotFound
+	}
+	if admin {
+		return a, nil
+	}
+	if caller.DentistID != a.Dentist.Id {
+		return domain.AppointmentDTO{}, ErrForbidden
+	}
+	return a, nil
+}
+
+// noteOfAppointment busca uma nota e garante que ela pertence à consulta informada
+func (s *service) noteOfAppointment(caller auth.Claims, appointmentID, noteID int, allowAdmin bool) (domain.ClinicalNote, error) {
+	if _, err := s.treatingAppointment(caller, appointmentID, allowAdmin); err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	n, err := s.r.GetByID(noteID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && n.IdAppointment != appointmentID) {
+		return domain.ClinicalNote{}, ErrNotFound
+	}
+	return n, err
+}
+
+// authoredNote busca uma nota da consulta e garante que o usuário é o seu autor
+func (s *service) authoredNote(caller auth.Claims, appointmentID, noteID int) (domain.ClinicalNote, error) {
+	n, err := s.noteOfAppointment(caller, appointmentID, noteID, false)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	if n.IdDentist != caller.DentistID {
+		return domain.ClinicalNote{}, ErrForbidden
+	}
+	return n, nil
+}
+
+// treatedAppointments retorna os ids das consultas da lista atendidas pelo dentista
+func treatedAppointments(appointments []domain.AppointmentDTO, dentistID int) map[int]bool {
+	treated := make(map[int]bool)
+	for _, a := range appointments {
+		if a.Dentist.Id == dentistID {
+			treated[a.Id] = true
+		}
+	}
+	return treated
+}
+
+// withAddenda agrupa os adendos sob as notas que eles complementam
+func withAddenda(notes []domain.ClinicalNote) []domain.ClinicalNote {
+	addenda := make(map[int][]domain.ClinicalNote)
+	for _, n := range notes {
+		if n.IdParent != 0 {
+			addenda[n.IdParent] = append(addenda[n.IdParent], n)
+		}
+	}
+	result := make([]domain.ClinicalNote, 0, len(notes))
+	for _, n := range notes {
+		if n.IdParent == 0 {
+			n.Addenda = addenda[n.Id]
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,228 @@
+package note
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// fakeRepository guarda as notas em memória, com a assinatura conferida como no banco
+type fakeRepository struct {
+	notes []domain.ClinicalNote
+}
+
+func (r *fakeRepository) GetByID(id int) (domain.ClinicalNote, error) {
+	for _, n := range r.notes {
+		if n.Id == id {
+			return n, nil
+		}
+	}
+	return domain.ClinicalNote{}, store.ErrNotFound
+}
+
+func (r *fakeRepository) GetByAppointment(appointmentID int) ([]domain.ClinicalNote, error) {
+	var list []domain.ClinicalNote
+	for _, n := range r.notes {
+		if n.IdAppointment == appointmentID {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepository) GetByPatient(patientID int) ([]domain.ClinicalNote, error) {
+	var list []domain.ClinicalNote
+	for _, n := range r.notes {
+		if n.IdPatient == patientID {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepository) Create(n domain.ClinicalNote) (domain.ClinicalNote, error) {
+	n.Id = len(r.notes) + 1
+	r.notes = append(r.notes, n)
+	return n, nil
+}
+
+func (r *fakeRepository) UpdateContent(id int, content string) error {
+	for i, n := range r.notes {
+		if n.Id == id && !n.IsSigned() {
+			r.notes[i].Content = content
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (r *fakeRepository) Sign(id int, signedAt time.Time) error {
+	for i, n := range r.notes {
+		if n.Id == id && !n.IsSigned() {
+			r.notes[i].SignedAt = signedAt.Format("02/01/2006 15:04")
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+// fakeAppointments tem a consulta 1 do dentista 7 e a 2 do dentista 8, ambas do paciente 1
+type fakeAppointments struct {
+	appointment.Service
+}
+
+var appointments = []domain.AppointmentDTO{
+	{Appointment: domain.Appointment{Id: 1}, Dentist: domain.Dentist{Id: 7}, Patient: domain.Patient{Id: 1, Document: "123"}},
+	{Appointment: domain.Appointment{Id: 2}, Dentist: domain.Dentist{Id: 8}, Patient: domain.Patient{Id: 1, Document: "123"}},
+}
+
+func (fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	for _, a := range appointments {
+		if a.Id == id {
+			return a, nil
+		}
+	}
+	return domain.AppointmentDTO{}, store.ErrNotFound
+}
+
+func (fakeAppointments) GetByDocumentPatient(document string) ([]domain.AppointmentDTO, error) {
+	return appointments, nil
+}
+
+type fakePatients struct {
+	patient.Service
+}
+
+func (fakePatients) GetByID(id int) (domain.Patient, error) {
+	if id != 1 {
+		return domain.Patient{}, store.ErrNotFound
+	}
+	return domain.Patient{Id: 1, Document: "123"}, nil
+}
+
+var (
+	admin     = auth.Claims{UserID: 1, Role: domain.RoleAdmin}
+	reception = auth.Claims{UserID: 2, Role: domain.RoleReception}
+	ana       = auth.Claims{UserID: 3, Role: domain.RoleDentist, DentistID: 7}
+	bia       = auth.Claims{UserID: 4, Role: domain.RoleDentist, DentistID: 8}
+	rui       = auth.Claims{UserID: 5, Role: domain.RoleDentist, DentistID: 9}
+)
+
+func newTestService() (Service, *fakeRepository) {
+	r := &fakeRepository{}
+	return NewService(r, fakeAppointments{}, fakePatients{}), r
+}
+
+func wantErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s = %v, want %v", what, err, want)
+	}
+}
+
+func TestOnlyTheAuthorEditsAndSigns(t *testing.T) {
+	s, _ := newTestService()
+
+	_, err := s.Create(bia, 1, "anamnese")
+	wantErr(t, "Create by another dentist", err, ErrForbidden)
+	_, err = s.Create(admin, 1, "anamnese")
+	wantErr(t, "Create by an admin", err, ErrForbidden)
+	_, err = s.Create(ana, 1, "  ")
+	wantErr(t, "Create without content", err, ErrEmptyContent)
+	_, err = s.Create(ana, 9, "anamnese")
+	wantErr(t, "Create on a missing appointment", err, ErrNotFound)
+
+	n, err := s.Create(ana, 1, "anamnese")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if n.IdPatient != 1 || n.IdDentist != 7 {
+		t.Errorf("note = %+v, want patient 1 and dentist 7", n)
+	}
+	_, err = s.Update(bia, 1, n.Id, "outro")
+	wantErr(t, "Update by another dentist", err, ErrForbidden)
+	_, err = s.Update(ana, 2, n.Id, "outro")
+	wantErr(t, "Update through another appointment", err, ErrForbidden)
+	_, err = s.Sign(admin, 1, n.Id)
+	wantErr(t, "Sign by an admin", err, ErrForbidden)
+
+	updated, err := s.Update(ana, 1, n.Id, "anamnese revisada")
+	if err != nil || updated.Content != "anamnese revisada" {
+		t.Fatalf("Update = %+v, %v", updated, err)
+	}
+	signed, err := s.Sign(ana, 1, n.Id)
+	if err != nil || !signed.IsSigned() {
+		t.Fatalf("Sign = %+v, %v", signed, err)
+	}
+
+	// a nota assinada é imutável
+	_, err = s.Update(ana, 1, n.Id, "alterada")
+	wantErr(t, "Update after signing", err, ErrSigned)
+	_, err = s.Sign(ana, 1, n.Id)
+	wantErr(t, "Sign twice", err, ErrSigned)
+}
+
+func TestAddenda(t *testing.T) {
+	s, _ := newTestService()
+	n, _ := s.Create(ana, 1, "anamnese")
+
+	_, err := s.AddAddendum(ana, 1, n.Id, "complemento")
+	wantErr(t, "AddAddendum to an unsigned note", err, ErrNotSigned)
+	s.Sign(ana, 1, n.Id)
+	_, err = s.AddAddendum(bia, 1, n.Id, "complemento")
+	wantErr(t, "AddAddendum by another dentist", err, ErrForbidden)
+
+	first, err := s.AddAddendum(ana, 1, n.Id, "complemento")
+	if err != nil {
+		t.Fatalf("AddAddendum: %v", err)
+	}
+	if first.IdParent != n.Id || !first.IsSigned() {
+		t.Errorf("addendum = %+v, want a signed child of %d", first, n.Id)
+	}
+	// o adendo de um adendo fica sob a nota original
+	second, err := s.AddAddendum(ana, 1, first.Id, "outro complemento")
+	if err != nil || second.IdParent != n.Id {
+		t.Errorf("second addendum = %+v, %v, want a child of %d", second, err, n.Id)
+	}
+
+	notes, err := s.GetByAppointment(admin, 1)
+	if err != nil {
+		t.Fatalf("GetByAppointment: %v", err)
+	}
+	if len(notes) != 1 || len(notes[0].Addenda) != 2 {
+		t.Errorf("notes = %+v, want one note with two addenda", notes)
+	}
+}
+
+func TestGetByPatient(t *testing.T) {
+	s, _ := newTestService()
+	mine, _ := s.Create(ana, 1, "de Ana")
+	s.Create(bia, 2, "de Bia")
+
+	// a recepção recebe 403 tanto para pacientes existentes quanto inexistentes
+	_, err := s.GetByPatient(reception, 1)
+	wantErr(t, "GetByPatient by reception", err, ErrForbidden)
+	_, err = s.GetByPatient(reception, 99)
+	wantErr(t, "GetByPatient of a missing patient by reception", err, ErrForbidden)
+	_, err = s.GetByAppointment(reception, 99)
+	wantErr(t, "GetByAppointment of a missing appointment by reception", err, ErrForbidden)
+	_, err = s.GetByPatient(admin, 99)
+	wantErr(t, "GetByPatient of a missing patient", err, ErrNotFound)
+
+	all, err := s.GetByPatient(admin, 1)
+	if err != nil || len(all) != 2 {
+		t.Errorf("admin sees %+v, %v, want 2 notes", all, err)
+	}
+	visible, err := s.GetByPatient(ana, 1)
+	if err != nil || len(visible) != 1 || visible[0].Id != mine.Id {
+		t.Errorf("dentist sees %+v, %v, want only note %d", visible, err, mine.Id)
+	}
+	_, err = s.GetByPatient(rui, 1)
+	wantErr(t, "GetByPatient by a dentist who never treated the patient", err, ErrForbidden)
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+)
+
+const (
+	// MaxAttempts é o número de tentativas antes de o evento ser abandonado
+	MaxAttempts = 12
+	// BaseDelay é a espera antes da segunda tentativa, dobrada a cada nova falha
+	BaseDelay = 5 * time.Second
+	// MaxDelay limita a espera entre tentativas
+	MaxDelay = 10 * time.Minute
+	// batchSize é o número máximo de eventos publicados a cada rodada
+	batchSize = 100
+	// claimLease é por quanto tempo os eventos de uma rodada ficam reservados à instância que os leu
+	claimLease = time.Minute
+)
+
+type Relay interface {
+	// Run publica os eventos pendentes a cada intervalo até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+	// PublishPending publica os eventos pendentes e retorna quantos foram publicados
+	PublishPending(ctx context.Context) (int, error)
+}
+
+type relay struct {
+	r     Repository
+	sinks []event.Sink
+}
+
+// NewRelay cria o relay que repassa os eventos do outbox aos destinos informados. Um evento só é
+// marcado como publicado quando todos os destinos o aceitam; se algum falhar, ele é reenviado, depois
+// de uma espera crescente, apenas aos destinos que ainda não o aceitaram. Depois de MaxAttempts
+// falhas o evento é abandonado. Várias instâncias podem rodar o relay, cada uma com os eventos que
+// reservou; os destinos usam o Id do evento para descartar repetições.
+func NewRelay(r Repository, sinks ...event.Sink) Relay {
+	return &relay{r, sinks}
+}
+
+func (rl *relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := rl.PublishPending(ctx); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backoff retorna a espera após a tentativa informada, dobrando a cada falha até MaxDelay
+func Backoff(attempt int) time.Duration {
+	delay := BaseDelay
+	for i := 1; i < attempt && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		delay = MaxDelay
+	}
+	return delay
+}
+
+// PublishPending publica os eventos reservados na ordem do outbox. Um evento que falha é adiado
+// sem segurar os seguintes.
+func (rl *relay) PublishPending(ctx context.Context) (int, error) {
+	claimed, err := rl.r.Claim(batchSize, time.Now(), claimLease)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, outboxEvent := range claimed {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+		if err := rl.publish(ctx, &outboxEvent); err != nil {
+			outboxEvent.Attempts++
+			outboxEvent.LastError = err.Error()
+			abandoned := outboxEvent.Attempts >= MaxAttempts
+			log.Printf("outbox: %v", err)
+			if abandoned {
+				log.Printf("outbox: giving up on %s %s after %d attempts", outboxEvent.Event.Type, outboxEvent.Event.Id, outboxEvent.Attempts)
+			}
+			if err := rl.r.MarkFailed(outboxEvent, time.Now().Add(Backoff(outboxEvent.Attempts)), abandoned); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := rl.r.MarkPublished(outboxEvent.Id, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publish entrega o evento aos destinos que ainda não o aceitaram, acrescentando cada um que aceitar
+// a DeliveredSinks, e retorna a primeira falha
+func (rl *relay) publish(ctx context.Context, outboxEvent *domain.OutboxEvent) error {
+	delivered := make(map[string]bool)
+	for _, name := range outboxEvent.DeliveredSinks {
+		delivered[name] = true
+	}
+	var failure error
+	for _, sink := range rl.sinks {
+		if delivered[sink.Name()] {
+			continue
+		}
+		if err := sink.Publish(ctx, outboxEvent.Event); err != nil {
+			if failure == nil {
+				failure = fmt.Errorf("publishing %s %s to %s: %w", outboxEvent.Event.Type, outboxEvent.Event.Id, sink.Name(), err)
+			}
+			continue
+		}
+		outboxEvent.DeliveredSinks = append(outboxEvent.DeliveredSinks, sink.Name())
+	}
+	return failure
+}
@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+type fakeRepository struct {
+	claimed   []domain.OutboxEvent
+	published []int
+	failed    []domain.OutboxEvent
+	retryAt   []time.Time
+	abandoned []bool
+}
+
+func (f *fakeRepository) Claim(limit int, now time.Time, lease time.Duration) ([]domain.OutboxEvent, error) {
+	return f.claimed, nil
+}
+
+func (f *fakeRepository) MarkPublished(id int, at time.Time) error {
+	f.published = append(f.published, id)
+	return nil
+}
+
+func (f *fakeRepository) MarkFailed(e domain.OutboxEvent, retryAt time.Time, abandoned bool) error {
+	f.failed = append(f.failed, e)
+	f.retryAt = append(f.retryAt, retryAt)
+	f.abandoned = append(f.abandoned, abandoned)
+	return nil
+}
+
+// fakeSink registra os eventos recebidos e falha enquanto err estiver definido
+type fakeSink struct {
+	name     string
+	err      error
+	received []string
+}
+
+func (s *fakeSink) Name() string {
+	return s.name
+}
+
+func (s *fakeSink) Publish(ctx context.Context, e domain.Event) error {
+	s.received = append(s.received, e.Id)
+	return s.err
+}
+
+func TestPublishPendingTracksSinks(t *testing.T) {
+	healthy := &fakeSink{name: "bus"}
+	failing := &fakeSink{name: "webhooks", err: errors.New("database is down")}
+	repository := &fakeRepository{claimed: []domain.OutboxEvent{
+		{Id: 1, Event: domain.Event{Id: "a"}},
+		{Id: 2, Event: domain.Event{Id: "b"}, Attempts: 1, DeliveredSinks: []string{"bus"}},
+	}}
+
+	published, err := NewRelay(repository, healthy, failing).PublishPending(context.Background())
+	if err != nil || published != 0 {
+		t.Fatalf("published = %d, err = %v, want 0 and no error", published, err)
+	}
+	// o segundo evento já tinha sido aceito pelo bus e não é reenviado a ele
+	if len(healthy.received) != 1 || healthy.received[0] != "a" {
+		t.Errorf("bus received %v, want only a", healthy.received)
+	}
+	if len(failing.received) != 2 {
+		t.Errorf("webhooks received %v, want both events", failing.received)
+	}
+	if len(repository.failed) != 2 {
+		t.Fatalf("failed = %v, want both events", repository.failed)
+	}
+	first := repository.failed[0]
+	if first.Attempts != 1 || len(first.DeliveredSinks) != 1 || first.DeliveredSinks[0] != "bus" || first.LastError == "" {
+		t.Errorf("first failure = %+v", first)
+	}
+	if wait := time.Until(repository.retryAt[1]); wait > Backoff(2) || wait < Backoff(2)-time.Minute {
+		t.Errorf("second event retries in %v, want about %v", wait, Backoff(2))
+	}
+	if repository.abandoned[0] || repository.abandoned[1] {
+		t.Error("events were abandoned before MaxAttempts")
+	}
+
+	failing.err = nil
+	repository.claimed = repository.failed
+	published, err = NewRelay(repository, healthy, failing).PublishPending(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("published = %d, err = %v, want 2 and no error", published, err)
+	}
+	if len(healthy.received) != 1 {
+		t.Errorf("bus received %v again", healthy.received)
+	}
+}
+
+func TestPublishPendingAbandons(t *testing.T) {
+	failing := &fakeSink{name: "webhooks", err: errors.New("database is down")}
+	repository := &fakeRepository{claimed: []domain.OutboxEvent{{Id: 1, Attempts: MaxAttempts - 1}}}
+
+	if _, err := NewRelay(repository, failing).PublishPending(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !repository.abandoned[0] || repository.failed[0].Attempts != MaxAttempts {
+		t.Errorf("event with %d attempts was not abandoned", repository.failed[0].Attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if Backoff(1) != BaseDelay || Backoff(3) != 4*BaseDelay {
+		t.Errorf("Backoff(1) = %v, Backoff(3) = %v", Backoff(1), Backoff(3))
+	}
+	if Backoff(MaxAttempts*10) != MaxDelay {
+		t.Errorf("Backoff(%d) = %v, want MaxDelay", MaxAttempts*10, Backoff(MaxAttempts*10))
+	}
+}
@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// Claim reserva por lease os eventos pendentes que nenhuma outra instância reservou, na ordem em
+	// que foram gravados
+	Claim(limit int, now time.Time, lease time.Duration) ([]domain.OutboxEvent, error)
+	// MarkPublished registra que o evento foi entregue a todos os destinos
+	MarkPublished(id int, at time.Time) error
+	// MarkFailed registra uma tentativa de publicação que falhou, com os destinos que já aceitaram o
+	// evento. Ele volta a ser tentado a partir de retryAt, ou é abandonado quando abandoned.
+	MarkFailed(e domain.OutboxEvent, retryAt time.Time, abandoned bool) error
+}
+
+type repository struct {
+	store store.OutboxStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.OutboxStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) Claim(limit int, now time.Time, lease time.Duration) ([]domain.OutboxEvent, error) {
+	return r.store.ClaimPendingEvents(limit, now, lease)
+}
+
+func (r *repository) MarkPublished(id int, at time.Time) error {
+	return r.store.MarkEventPublished(id, at)
+}
+
+func (r *repository) MarkFailed(e domain.OutboxEvent, retryAt time.Time, abandoned bool) error {
+	return r.store.MarkEventFailed(e.Id, e.LastError, e.DeliveredSinks, retryAt, abandoned)
+}
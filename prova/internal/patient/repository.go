@@ -1,6 +1,7 @@
 package patient
 
 import (
+	"context"
 	"errors"
 	"log"
 
@@ -21,6 +22,14 @@ type Repository interface {
 	Update(id int, p domain.Patient) (interface{}, error)
 	//Delete exclui um paciente
 	Delete(id int) error
+	// Batch grava os pacientes numa única transação, incluindo os que não têm id
+	Batch(patients []domain.Patient) ([]interface{}, error)
+	// GetByIDs retorna os pacientes com os ids informados, numa única consulta
+	GetByIDs(ctx context.Context, ids []int) ([]domain.Patient, error)
+	// GetByDocuments retorna os pacientes com os documentos informados, numa única consulta
+	GetByDocuments(ctx context.Context, documents []string) ([]domain.Patient, error)
+	// GetPage retorna uma página dos pacientes, por id
+	GetPage(ctx context.Context, page domain.Page) ([]domain.Patient, error)
 }
 
 type repository struct {
@@ -75,6 +84,14 @@ func (r *repository) Delete(id int) error {
 	return r.store.Delete(id, table)
 }
 
+func (r *repository) Batch(patients []domain.Patient) ([]interface{}, error) {
+	ops := make([]store.BatchOp, 0, len(patients))
+	for _, p := range patients {
+		ops = append(ops, store.BatchOp{Id: p.Id, Entity: p})
+	}
+	return r.store.Batch(table, ops)
+}
+
 func (r *repository) validateIdentificationNumber(Document string) bool {
 	var patients []domain.Patient
 	patientsInterface, err := r.GetAll()
@@ -96,3 +113,24 @@ func (r *repository) validateIdentificationNumber(Document string) bool {
 
 	return true
 }
+
+func (r *repository) GetByIDs(ctx context.Context, ids []int) ([]domain.Patient, error) {
+	return patients(r.store.GetByIDs(ctx, ids, table))
+}
+
+func (r *repository) GetByDocuments(ctx context.Context, documents []string) ([]domain.Patient, error) {
+	return patients(r.store.GetByKeys(ctx, documents, table))
+}
+
+func (r *repository) GetPage(ctx context.Context, page domain.Page) ([]domain.Patient, error) {
+	return patients(r.store.GetPage(ctx, page, table))
+}
+
+// patients converte o resultado das buscas em lote do store
+func patients(found interface{}, err error) ([]domain.Patient, error) {
+	if err != nil {
+		return nil, err
+	}
+	list, _ := found.([]domain.Patient)
+	return list, nil
+}
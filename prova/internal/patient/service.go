@@ -1,7 +1,9 @@
 package patient
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/meirafa/prova2-golang/internal/domain"
 )
@@ -9,9 +11,19 @@ import (
 type Service interface {
 	GetAll() ([]domain.Patient, error)
 	GetByID(id int) (domain.Patient, error)
+	// GetByDocuments retorna os pacientes com os documentos informados numa única busca
+	GetByDocuments(documents []string) ([]domain.Patient, error)
 	Create(p domain.Patient) (domain.Patient, error)
+	// Update altera os campos informados, mantendo os já cadastrados nos campos vazios
 	Update(id int, p domain.Patient) (domain.Patient, error)
+	// Replace substitui o cadastro inteiro: os campos opcionais vazios são apagados
+	Replace(id int, p domain.Patient) (domain.Patient, error)
 	Delete(id int) error
+	// Validate aplica as regras de Create, ou as de Update quando o paciente já existe, sem gravar.
+	// Retorna o paciente normalizado e, na atualização, completado com os dados cadastrados.
+	Validate(p domain.Patient, current *domain.Patient) (domain.Patient, error)
+	// Batch grava os pacientes numa única transação: inclui os que não têm id e atualiza os demais
+	Batch(patients []domain.Patient) ([]domain.Patient, error)
 }
 
 type service struct {
@@ -46,7 +58,15 @@ func (s *service) GetByID(id int) (domain.Patient, error) {
 	return patient, nil
 }
 
+func (s *service) GetByDocuments(documents []string) ([]domain.Patient, error) {
+	return s.r.GetByDocuments(context.Background(), documents)
+}
+
 func (s *service) Create(p domain.Patient) (domain.Patient, error) {
+	if err := validateProfile(&p, time.Now()); err != nil {
+		return domain.Patient{}, err
+	}
+
 	pSavedInterface, err := s.r.Create(p)
 	if err != nil {
 		return domain.Patient{}, err
@@ -65,20 +85,24 @@ func (s *service) Update(id int, p domain.Patient) (domain.Patient, error) {
 	if err != nil {
 		return domain.Patient{}, err
 	}
+	mergePatient(&p, pdb)
+	return s.save(pdb.Id, p)
+}
 
-	if p.Surname == "" {
-		p.Surname = pdb.Surname
-	}
-	if p.Name == "" {
-		p.Name = pdb.Name
-	}
-	if p.Document == "" {
-		p.Document = pdb.Document
+func (s *service) Replace(id int, p domain.Patient) (domain.Patient, error) {
+	pdb, err := s.GetByID(id)
+	if err != nil {
+		return domain.Patient{}, err
 	}
-	if p.CreatedAt == "" {
-		p.CreatedAt = pdb.CreatedAt
+	return s.save(pdb.Id, p)
+}
+
+// save valida o perfil e grava o paciente já completo
+func (s *service) save(id int, p domain.Patient) (domain.Patient, error) {
+	if err := validateProfile(&p, time.Now()); err != nil {
+		return domain.Patient{}, err
 	}
-	p.Id = pdb.Id
+	p.Id = id
 	pUpdated, err := s.r.Update(id, p)
 	if err != nil {
 		return domain.Patient{}, err
@@ -94,3 +118,85 @@ func (s *service) Update(id int, p domain.Patient) (domain.Patient, error) {
 func (s *service) Delete(id int) error {
 	return s.r.Delete(id)
 }
+
+func (s *service) Validate(p domain.Patient, current *domain.Patient) (domain.Patient, error) {
+	if current != nil {
+		mergePatient(&p, *current)
+		p.Id = current.Id
+	}
+	switch {
+	case p.Surname == "":
+		return domain.Patient{}, invalid("surname", "can't be empty")
+	case p.Name == "":
+		return domain.Patient{}, invalid("name", "can't be empty")
+	case p.Document == "":
+		return domain.Patient{}, invalid("document", "can't be empty")
+	case p.CreatedAt == "":
+		return domain.Patient{}, invalid("created_at", "can't be empty")
+	}
+	if err := validateProfile(&p, time.Now()); err != nil {
+		return domain.Patient{}, err
+	}
+	return p, nil
+}
+
+func (s *service) Batch(patients []domain.Patient) ([]domain.Patient, error) {
+	results, err := s.r.Batch(patients)
+	if err != nil {
+		return nil, err
+	}
+	saved := make([]domain.Patient, 0, len(results))
+	for _, result := range results {
+		patient, ok := result.(domain.Patient)
+		if !ok {
+			return nil, errors.New("failed to save the patients batch")
+		}
+		saved = append(saved, patient)
+	}
+	return saved, nil
+}
+
+// mergePatient preenche os campos não informados com os valores já cadastrados
+func mergePatient(p *domain.Patient, pdb domain.Patient) {
+	if p.Surname == "" {
+		p.Surname = pdb.Surname
+	}
+	if p.Name == "" {
+		p.Name = pdb.Name
+	}
+	if p.Document == "" {
+		p.Document = pdb.Document
+	}
+	if p.CreatedAt == "" {
+		p.CreatedAt = pdb.CreatedAt
+	}
+	mergeProfile(p, pdb)
+}
+
+// mergeProfile preenche os campos do perfil não informados com os valores já cadastrados
+func mergeProfile(p *domain.Patient, pdb domain.Patient) {
+	if p.Email == "" {
+		p.Email = pdb.Email
+	}
+	if p.Phones == nil {
+		p.Phones = pdb.Phones
+	}
+	if p.BirthDate == "" {
+		p.BirthDate = pdb.BirthDate
+	}
+	if p.Address == nil {
+		p.Address = pdb.Address
+	}
+	if p.Guardian == nil {
+		p.Guardian = pdb.Guardian
+	}
+	if p.EmergencyContact == nil {
+		p.EmergencyContact = pdb.EmergencyContact
+	}
+	if p.PreferredLanguage == "" {
+		p.PreferredLanguage = pdb.PreferredLanguage
+	}
+	if p.Consent == nil {
+		p.Consent = pdb.Consent
+	}
+}
@@ -0,0 +1,85 @@
+package patient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// fakeRepository guarda os pacientes em memória; os métodos não usados pelos testes ficam com a
+// interface nula
+type fakeRepository struct {
+	Repository
+	patients map[int]domain.Patient
+}
+
+func (r *fakeRepository) GetByID(id int) (interface{}, error) {
+	p, ok := r.patients[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *fakeRepository) Update(id int, p domain.Patient) (interface{}, error) {
+	r.patients[id] = p
+	return p, nil
+}
+
+func stored() *fakeRepository {
+	p := adult()
+	p.Id = 1
+	p.Email = "ana@example.com"
+	p.Phones = []string{"11987654321"}
+	p.PreferredLanguage = "pt-BR"
+	p.Consent = &domain.CommunicationConsent{Email: true}
+	return &fakeRepository{patients: map[int]domain.Patient{1: p}}
+}
+
+func TestUpdateKeepsTheOmittedFields(t *testing.T) {
+	r := stored()
+	s := NewService(r)
+
+	updated, err := s.Update(1, domain.Patient{Name: "Ana Maria"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want := r.patients[1]
+	if updated.Name != "Ana Maria" || updated.Email != "ana@example.com" || !reflect.DeepEqual(updated.Phones, []string{"11987654321"}) {
+		t.Errorf("updated = %+v, want the new name and the stored profile", updated)
+	}
+	if !reflect.DeepEqual(updated, want) {
+		t.Errorf("stored = %+v, want %+v", want, updated)
+	}
+}
+
+func TestReplaceClearsTheOmittedFields(t *testing.T) {
+	r := stored()
+	s := NewService(r)
+
+	p := adult()
+	p.Phones = []string{"(21) 3456-7890"}
+	replaced, err := s.Replace(1, p)
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if replaced.Id != 1 || replaced.Email != "" || replaced.PreferredLanguage != "" || replaced.Consent != nil {
+		t.Errorf("replaced = %+v, want the optional fields cleared", replaced)
+	}
+	if !reflect.DeepEqual(replaced.Phones, []string{"2134567890"}) {
+		t.Errorf("phones = %v, want the new phone normalized", replaced.Phones)
+	}
+	if !reflect.DeepEqual(r.patients[1], replaced) {
+		t.Errorf("stored = %+v, want %+v", r.patients[1], replaced)
+	}
+
+	if _, err := s.Replace(2, p); err == nil {
+		t.Error("Replace of a missing patient succeeded")
+	}
+	p.Email = "invalid"
+	if _, err := s.Replace(1, p); !IsValidationError(err) {
+		t.Errorf("Replace with an invalid email = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,128 @@
+package patient
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// BirthDateLayout é o formato aceito para a data de nascimento
+const BirthDateLayout = "02/01/2006"
+
+// adultAge é a idade a partir da qual o paciente dispensa responsável legal
+const adultAge = 18
+
+var (
+	phonePattern    = regexp.MustCompile(`^\+?\d{10,13}$`)
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	languagePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+	zipCodePattern  = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+)
+
+// ValidationError indica que algum campo do perfil do paciente é inválido
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func invalid(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// IsValidationError indica se o erro foi gerado pela validação do perfil
+func IsValidationError(err error) bool {
+	var vErr *ValidationError
+	return errors.As(err, &vErr)
+}
+
+// validateProfile valida os campos opcionais do perfil e normaliza os telefones
+func validateProfile(p *domain.Patient, now time.Time) error {
+	if p.Email != "" {
+		address, err := mail.ParseAddress(p.Email)
+		if err != nil || address.Address != p.Email {
+			return invalid("email", "invalid email address")
+		}
+	}
+
+	for i, phone := range p.Phones {
+		normalized, err := normalizePhone(phone)
+		if err != nil {
+			return invalid("phones", err.Error())
+		}
+		p.Phones[i] = normalized
+	}
+
+	if p.PreferredLanguage != "" && !languagePattern.MatchString(p.PreferredLanguage) {
+		return invalid("preferred_language", "expected a language tag such as pt-BR")
+	}
+
+	if p.Address != nil {
+		if p.Address.Street == "" || p.Address.City == "" {
+			return invalid("address", "street and city can't be empty")
+		}
+		if !domain.IsState(strings.ToUpper(p.Address.State)) {
+			return invalid("address.state", "invalid state")
+		}
+		p.Address.State = strings.ToUpper(p.Address.State)
+		if !zipCodePattern.MatchString(p.Address.ZipCode) {
+			return invalid("address.zip_code", "expected format 00000-000")
+		}
+	}
+
+	if err := validateContact("guardian", p.Guardian); err != nil {
+		return err
+	}
+	if err := validateContact("emergency_contact", p.EmergencyContact); err != nil {
+		return err
+	}
+
+	if p.BirthDate != "" {
+		birthDate, err := time.Parse(BirthDateLayout, p.BirthDate)
+		if err != nil {
+			return invalid("birth_date", "expected format "+BirthDateLayout)
+		}
+		if birthDate.After(now) {
+			return invalid("birth_date", "can't be in the future")
+		}
+		if isMinor(birthDate, now) && p.Guardian == nil {
+			return invalid("guardian", "patients under 18 must have a legal guardian")
+		}
+	}
+	return nil
+}
+
+func validateContact(field string, c *domain.Contact) error {
+	if c == nil {
+		return nil
+	}
+	if c.Name == "" {
+		return invalid(field+".name", "can't be empty")
+	}
+	normalized, err := normalizePhone(c.Phone)
+	if err != nil {
+		return invalid(field+".phone", err.Error())
+	}
+	c.Phone = normalized
+	return nil
+}
+
+// normalizePhone remove a pontuação do telefone e verifica se possui DDD e número
+func normalizePhone(phone string) (string, error) {
+	normalized := phoneSeparators.Replace(strings.TrimSpace(phone))
+	if !phonePattern.MatchString(normalized) {
+		return "", errors.New("invalid phone number, expected area code and number such as (11) 98765-4321")
+	}
+	return normalized, nil
+}
+
+func isMinor(birthDate, now time.Time) bool {
+	return birthDate.AddDate(adultAge, 0, 0).After(now)
+}
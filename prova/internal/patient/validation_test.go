@@ -0,0 +1,105 @@
+package patient
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+var now = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func adult() domain.Patient {
+	return domain.Patient{Surname: "Silva", Name: "Ana", Document: "12345678900", CreatedAt: "2024-01-01"}
+}
+
+func TestValidateProfile(t *testing.T) {
+	guardian := &domain.Contact{Name: "Maria", Phone: "(11) 98765-4321"}
+	address := &domain.Address{Street: "Rua A", Number: "1", City: "São Paulo", State: "sp", ZipCode: "01001-000"}
+	tests := []struct {
+		name   string
+		change func(p *domain.Patient)
+		field  string
+	}{
+		{"empty profile", func(p *domain.Patient) {}, ""},
+		{"valid email", func(p *domain.Patient) { p.Email = "ana@example.com" }, ""},
+		{"email without domain", func(p *domain.Patient) { p.Email = "ana@" }, "email"},
+		{"email with a display name", func(p *domain.Patient) { p.Email = "Ana <ana@example.com>" }, "email"},
+		{"valid phones", func(p *domain.Patient) { p.Phones = []string{"(11) 98765-4321", "+5511987654321"} }, ""},
+		{"phone without area code", func(p *domain.Patient) { p.Phones = []string{"98765-4321"} }, "phones"},
+		{"phone with letters", func(p *domain.Patient) { p.Phones = []string{"11 9876A-4321"} }, "phones"},
+		{"valid language", func(p *domain.Patient) { p.PreferredLanguage = "pt-BR" }, ""},
+		{"invalid language", func(p *domain.Patient) { p.PreferredLanguage = "portuguese" }, "preferred_language"},
+		{"valid address", func(p *domain.Patient) { p.Address = address }, ""},
+		{"address without city", func(p *domain.Patient) {
+			p.Address = &domain.Address{Street: "Rua A", State: "SP", ZipCode: "01001000"}
+		}, "address"},
+		{"address with unknown state", func(p *domain.Patient) {
+			p.Address = &domain.Address{Street: "Rua A", City: "X", State: "XX", ZipCode: "01001000"}
+		}, "address.state"},
+		{"address with invalid zip code", func(p *domain.Patient) {
+			p.Address = &domain.Address{Street: "Rua A", City: "X", State: "SP", ZipCode: "1001"}
+		}, "address.zip_code"},
+		{"guardian without name", func(p *domain.Patient) { p.Guardian = &domain.Contact{Phone: "11987654321"} }, "guardian.name"},
+		{"emergency contact with invalid phone", func(p *domain.Patient) { p.EmergencyContact = &domain.Contact{Name: "Rui", Phone: "123"} }, "emergency_contact.phone"},
+		{"adult birth date", func(p *domain.Patient) { p.BirthDate = "04/03/2006" }, ""},
+		{"birth date in another format", func(p *domain.Patient) { p.BirthDate = "2006-03-04" }, "birth_date"},
+		{"birth date in the future", func(p *domain.Patient) { p.BirthDate = "05/03/2024" }, "birth_date"},
+		{"minor without guardian", func(p *domain.Patient) { p.BirthDate = "05/03/2006" }, "guardian"},
+		{"minor with guardian", func(p *domain.Patient) { p.BirthDate = "05/03/2006"; p.Guardian = guardian }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := adult()
+			tt.change(&p)
+			err := validateProfile(&p, now)
+			var vErr *ValidationError
+			switch {
+			case tt.field == "" && err != nil:
+				t.Errorf("validateProfile = %v, want no error", err)
+			case tt.field != "" && (!errors.As(err, &vErr) || vErr.Field != tt.field):
+				t.Errorf("validateProfile = %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateProfileNormalizes(t *testing.T) {
+	p := adult()
+	p.Phones = []string{"(11) 98765-4321"}
+	p.Guardian = &domain.Contact{Name: "Maria", Phone: "11 3456.7890"}
+	p.Address = &domain.Address{Street: "Rua A", City: "São Paulo", State: "sp", ZipCode: "01001-000"}
+	if err := validateProfile(&p, now); err != nil {
+		t.Fatalf("validateProfile: %v", err)
+	}
+	if !reflect.DeepEqual(p.Phones, []string{"11987654321"}) || p.Guardian.Phone != "1134567890" || p.Address.State != "SP" {
+		t.Errorf("got phones %v, guardian phone %s and state %s", p.Phones, p.Guardian.Phone, p.Address.State)
+	}
+}
+
+func TestValidateRequiresTheBaseFields(t *testing.T) {
+	s := NewService(nil)
+	for field, change := range map[string]func(p *domain.Patient){
+		"surname":    func(p *domain.Patient) { p.Surname = "" },
+		"name":       func(p *domain.Patient) { p.Name = "" },
+		"document":   func(p *domain.Patient) { p.Document = "" },
+		"created_at": func(p *domain.Patient) { p.CreatedAt = "" },
+	} {
+		p := adult()
+		change(&p)
+		_, err := s.Validate(p, nil)
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) || vErr.Field != field {
+			t.Errorf("Validate without %s = %v, want a validation error on it", field, err)
+		}
+
+		// na atualização, o campo vazio mantém o valor cadastrado
+		current := adult()
+		current.Id = 3
+		if got, err := s.Validate(p, &current); err != nil || got.Id != 3 {
+			t.Errorf("Validate of an update without %s = %+v, %v, want the stored value", field, got, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package procedure
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetAll retorna todos os procedimentos do catálogo
+	GetAll() ([]domain.Procedure, error)
+	// GetByCode retorna um procedimento do catálogo pelo código
+	GetByCode(code string) (domain.Procedure, error)
+	// Create insere um novo procedimento no catálogo
+	Create(p domain.Procedure) (domain.Procedure, error)
+	// Update atualiza um procedimento do catálogo
+	Update(code string, p domain.Procedure) (domain.Procedure, error)
+	// Delete exclui um procedimento do catálogo
+	Delete(code string) error
+}
+
+type repository struct {
+	store store.ProcedureStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.ProcedureStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetAll() ([]domain.Procedure, error) {
+	return r.store.GetProcedures()
+}
+
+func (r *repository) GetByCode(code string) (domain.Procedure, error) {
+	return r.store.GetProcedureByCode(code)
+}
+
+func (r *repository) Create(p domain.Procedure) (domain.Procedure, error) {
+	return r.store.SaveProcedure(p)
+}
+
+func (r *repository) Update(code string, p domain.Procedure) (domain.Procedure, error) {
+	return r.store.UpdateProcedure(code, p)
+}
+
+func (r *repository) Delete(code string) error {
+	return r.store.DeleteProcedure(code)
+}
@@ -0,0 +1,103 @@
+package procedure
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+var (
+	// ErrNotFound indica que o código não existe no catálogo de procedimentos
+	ErrNotFound = errors.New("procedure not found in catalog")
+	// ErrCodeExists indica que já existe um procedimento com o mesmo código
+	ErrCodeExists = errors.New("procedure code already exists in catalog")
+	// ErrInvalidProcedure indica que os dados do procedimento são inválidos
+	ErrInvalidProcedure = errors.New("invalid procedure: code and name are required, duration must be positive and price can't be negative")
+)
+
+type Service interface {
+	// GetAll retorna todos os procedimentos do catálogo
+	GetAll() ([]domain.Procedure, error)
+	// GetByCode retorna um procedimento do catálogo pelo código
+	GetByCode(code string) (domain.Procedure, error)
+	// Create insere um novo procedimento no catálogo
+	Create(p domain.Procedure) (domain.Procedure, error)
+	// Update atualiza um procedimento do catálogo
+	Update(code string, p domain.Procedure) (domain.Procedure, error)
+	// Delete exclui um procedimento do catálogo
+	Delete(code string) error
+}
+
+type service struct {
+	r Repository
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository) Service {
+	return &service{r}
+}
+
+func (s *service) GetAll() ([]domain.Procedure, error) {
+	return s.r.GetAll()
+}
+
+func (s *service) GetByCode(code string) (domain.Procedure, error) {
+	p, err := s.r.GetByCode(code)
+	if errors.Is(err, store.ErrNotFound) {
+		return p, ErrNotFound
+	}
+	return p, err
+}
+
+func (s *service) Create(p domain.Procedure) (domain.Procedure, error) {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if err := validate(&p); err != nil {
+		return domain.Procedure{}, err
+	}
+	saved, err := s.r.Create(p)
+	if errors.Is(err, store.ErrDuplicate) {
+		return domain.Procedure{}, ErrCodeExists
+	}
+	return saved, err
+}
+
+func (s *service) Update(code string, p domain.Procedure) (domain.Procedure, error) {
+	pdb, err := s.GetByCode(code)
+	if err != nil {
+		return domain.Procedure{}, err
+	}
+	if p.Name == "" {
+		p.Name = pdb.Name
+	}
+	if p.Duration == 0 {
+		p.Duration = pdb.Duration
+	}
+	p.Code = pdb.Code
+	if err := validate(&p); err != nil {
+		return domain.Procedure{}, err
+	}
+	updated, err := s.r.Update(code, p)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.Procedure{}, ErrNotFound
+	}
+	return updated, err
+}
+
+func (s *service) Delete(code string) error {
+	err := s.r.Delete(code)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func validate(p *domain.Procedure) error {
+	if p.Code == "" || strings.TrimSpace(p.Name) == "" || p.Duration <= 0 || p.Price < 0 {
+		return ErrInvalidProcedure
+	}
+	p.Price = math.Round(p.Price*100) / 100
+	return nil
+}
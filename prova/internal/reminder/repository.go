@@ -0,0 +1,38 @@
+package reminder
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// Claim reserva o envio de um lembrete, falhando quando ele já foi reservado. Reservas em envio
+	// criadas antes de staleBefore são consideradas abandonadas e podem ser retomadas.
+	Claim(appointmentID int, kind, channel string, staleBefore time.Time) error
+	// Complete registra o resultado do envio de um lembrete
+	Complete(appointmentID int, kind, channel, status string) error
+	// Release libera a reserva de um lembrete que não pôde ser enviado
+	Release(appointmentID int, kind, channel string) error
+}
+
+type repository struct {
+	store store.ReminderStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.ReminderStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) Claim(appointmentID int, kind, channel string, staleBefore time.Time) error {
+	return r.store.ClaimReminder(appointmentID, kind, channel, staleBefore)
+}
+
+func (r *repository) Complete(appointmentID int, kind, channel, status string) error {
+	return r.store.CompleteReminder(appointmentID, kind, channel, status)
+}
+
+func (r *repository) Release(appointmentID int, kind, channel string) error {
+	return r.store.ReleaseReminder(appointmentID, kind, channel)
+}
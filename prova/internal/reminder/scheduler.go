@@ -0,0 +1,219 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/selfservice"
+	"github.com/meirafa/prova2-golang/pkg/notify"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// Situações registradas para cada lembrete
+const (
+	StatusSent    = "sent"
+	StatusSkipped = "skipped"
+)
+
+// EventReminder identifica as mensagens de lembrete de consulta
+const EventReminder = "appointment.reminder"
+
+// ClaimTimeout é o tempo após o qual uma reserva ainda em envio é dada como abandonada, por uma
+// instância que parou antes de registrar o resultado, e o lembrete volta a ser enviado
+const ClaimTimeout = 10 * time.Minute
+
+// DefaultOffsets são as antecedências padrão dos lembretes
+var DefaultOffsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
+
+type Scheduler interface {
+	// Run envia os lembretes devidos a cada intervalo até o contexto ser cancelado
+	Run(ctx context.Context)
+	// SendDue envia os lembretes devidos no instante informado e retorna quantos foram enviados
+	SendDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type scheduler struct {
+	r            Repository
+	appointments appointment.Service
+	patients     patient.Service
+	links        selfservice.Service
+	notifiers    []notify.Notifier
+	offsets      []time.Duration
+	location     *time.Location
+	interval     time.Duration
+}
+
+// NewScheduler cria o agendador de lembretes, que a cada intervalo busca as consultas que
+// começam dentro da maior antecedência e envia o lembrete por todos os notifiers. Quando links
+// é informado, o lembrete inclui os links para o paciente confirmar ou cancelar a consulta.
+// As datas das consultas são lidas no fuso da clínica informado em location.
+func NewScheduler(r Repository, appointments appointment.Service, patients patient.Service, links selfservice.Service, notifiers []notify.Notifier, offsets []time.Duration, location *time.Location, interval time.Duration) Scheduler {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &scheduler{r, appointments, patients, links, notifiers, sorted, location, interval}
+}
+
+func (s *scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SendDue(ctx, time.Now()); err != nil {
+			log.Printf("reminder: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue envia, para cada consulta, apenas o lembrete da menor antecedência que já foi
+// alcançada: uma consulta marcada para daqui a uma hora recebe só o lembrete de 2h. Cada
+// lembrete é reservado no banco antes do envio, então é enviado uma única vez mesmo que o
+// servidor seja reiniciado ou que haja mais de uma instância rodando.
+func (s *scheduler) SendDue(ctx context.Context, now time.Time) (int, error) {
+	if len(s.offsets) == 0 || len(s.notifiers) == 0 {
+		return 0, nil
+	}
+	// as datas das consultas estão no horário da clínica, não no do servidor
+	now = now.In(s.location)
+	upcoming, err := s.appointments.GetByInterval(now, now.Add(s.offsets[len(s.offsets)-1]))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, a := range upcoming {
+		if a.Status != domain.StatusScheduled && a.Status != domain.StatusConfirmed {
+			continue
+		}
+		start, err := time.ParseInLocation(appointment.DateLayout, a.AppointmentDate, s.location)
+		if err != nil || start.Before(now) {
+			continue
+		}
+		kind := s.kindFor(start.Sub(now))
+
+		var message *notify.Message
+		var recipient domain.Patient
+		for _, n := range s.notifiers {
+			if err := s.r.Claim(a.Id, kind, n.Channel(), now.Add(-ClaimTimeout)); err != nil {
+				if errors.Is(err, store.ErrDuplicate) {
+					continue
+				}
+				return sent, err
+			}
+			if message == nil {
+				if message, recipient, err = s.message(a.Id, kind); err != nil {
+					// uma consulta sem dados completos, por exemplo de um paciente excluído, não
+					// pode impedir os lembretes das demais
+					log.Printf("reminder: %s reminder for appointment %d: %v", kind, a.Id, err)
+					if err := s.r.Release(a.Id, kind, n.Channel()); err != nil {
+						return sent, err
+					}
+					break
+				}
+			}
+
+			status := StatusSent
+			if !allowed(n.Channel(), recipient) {
+				status = StatusSkipped
+			} else if err := n.Send(ctx, *message); errors.Is(err, notify.ErrNoRecipient) {
+				status = StatusSkipped
+			} else if err != nil {
+				log.Printf("reminder: %s reminder for appointment %d via %s: %v", kind, a.Id, n.Channel(), err)
+				if err := s.r.Release(a.Id, kind, n.Channel()); err != nil {
+					return sent, err
+				}
+				continue
+			}
+			if err := s.r.Complete(a.Id, kind, n.Channel(), status); err != nil {
+				return sent, err
+			}
+			if status == StatusSent {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// kindFor retorna o rótulo da menor antecedência maior ou igual ao tempo até a consulta
+func (s *scheduler) kindFor(until time.Duration) string {
+	for _, offset := range s.offsets {
+		if until <= offset {
+			return label(offset)
+		}
+	}
+	return label(s.offsets[len(s.offsets)-1])
+}
+
+// message monta o lembrete com os dados completos da consulta e do paciente
+func (s *scheduler) message(appointmentID int, kind string) (*notify.Message, domain.Patient, error) {
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil {
+		return nil, domain.Patient{}, err
+	}
+	p, err := s.patients.GetByID(a.Patient.Id)
+	if err != nil {
+		return nil, domain.Patient{}, err
+	}
+
+	message := &notify.Message{
+		Event:   EventReminder,
+		Email:   p.Email,
+		Subject: "Lembrete de consulta",
+		Body: fmt.Sprintf("Olá %s, lembramos da sua consulta com Dr(a). %s %s em %s.",
+			p.Name, a.Dentist.Name, a.Dentist.Surname, a.AppointmentDate),
+		Data: map[string]interface{}{
+			"id_appointment":   a.Id,
+			"appointment_date": a.AppointmentDate,
+			"id_patient":       p.Id,
+			"id_dentist":       a.IdDentist,
+			"reminder":         kind,
+		},
+	}
+	if len(p.Phones) > 0 {
+		message.Phone = p.Phones[0]
+	}
+	if s.links != nil {
+		links, err := s.links.Issue(a.Id)
+		if err != nil {
+			return nil, domain.Patient{}, err
+		}
+		message.Body += fmt.Sprintf("\nPara confirmar: %s\nPara cancelar: %s", links.Confirm, links.Cancel)
+		message.Data["confirm_url"] = links.Confirm
+		message.Data["cancel_url"] = links.Cancel
+	}
+	return message, p, nil
+}
+
+// allowed verifica o consentimento do paciente para o canal. Sem consentimento registrado,
+// todos os canais são permitidos.
+func allowed(channel string, p domain.Patient) bool {
+	if p.Consent == nil {
+		return true
+	}
+	switch channel {
+	case "email":
+		return p.Consent.Email
+	case "sms":
+		return p.Consent.SMS
+	default:
+		return true
+	}
+}
+
+func label(offset time.Duration) string {
+	if offset%time.Hour == 0 {
+		return fmt.Sprintf("%dh", offset/time.Hour)
+	}
+	return fmt.Sprintf("%dm", offset/time.Minute)
+}
@@ -0,0 +1,312 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/pkg/notify"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// clinic é o fuso da clínica nos testes, três horas atrás de UTC
+var clinic = time.FixedZone("BRT", -3*60*60)
+
+// now é o instante dos testes: 09:00 no horário da clínica
+var now = time.Date(2024, 3, 4, 9, 0, 0, 0, clinic)
+
+type key struct {
+	appointment   int
+	kind, channel string
+}
+
+type claim struct {
+	status    string
+	createdAt time.Time
+}
+
+// fakeRepository reproduz a chave primária de appointment_reminders em memória
+type fakeRepository struct {
+	mu     sync.Mutex
+	claims map[key]claim
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{claims: map[key]claim{}}
+}
+
+func (r *fakeRepository) Claim(appointmentID int, kind, channel string, staleBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{appointmentID, kind, channel}
+	if c, ok := r.claims[k]; ok && (c.status != "sending" || !c.createdAt.Before(staleBefore)) {
+		return store.ErrDuplicate
+	}
+	r.claims[k] = claim{status: "sending", createdAt: now}
+	return nil
+}
+
+func (r *fakeRepository) Complete(appointmentID int, kind, channel, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{appointmentID, kind, channel}
+	c, ok := r.claims[k]
+	if !ok {
+		return store.ErrNotFound
+	}
+	c.status = status
+	r.claims[k] = c
+	return nil
+}
+
+func (r *fakeRepository) Release(appointmentID int, kind, channel string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{appointmentID, kind, channel}
+	if c, ok := r.claims[k]; ok && c.status == "sending" {
+		delete(r.claims, k)
+	}
+	return nil
+}
+
+func (r *fakeRepository) status(appointmentID int, kind, channel string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.claims[key{appointmentID, kind, channel}].status
+}
+
+// fakeAppointments retorna as consultas marcadas; o paciente de cada consulta tem o id dela
+type fakeAppointments struct {
+	appointment.Service
+	list []domain.Appointment
+}
+
+func (f *fakeAppointments) GetByInterval(start, end time.Time) ([]domain.Appointment, error) {
+	return f.list, nil
+}
+
+func (f *fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	for _, a := range f.list {
+		if a.Id == id {
+			return domain.AppointmentDTO{Appointment: a, Patient: domain.Patient{Id: id}}, nil
+		}
+	}
+	return domain.AppointmentDTO{}, store.ErrNotFound
+}
+
+type fakePatients struct {
+	patient.Service
+	patients map[int]domain.Patient
+}
+
+func (f *fakePatients) GetByID(id int) (domain.Patient, error) {
+	p, ok := f.patients[id]
+	if !ok {
+		return domain.Patient{}, store.ErrNotFound
+	}
+	return p, nil
+}
+
+// fakeNotifier registra as mensagens enviadas por consulta
+type fakeNotifier struct {
+	channel string
+	mu      sync.Mutex
+	sent    map[interface{}]int
+	err     error
+}
+
+func newFakeNotifier(channel string) *fakeNotifier {
+	return &fakeNotifier{channel: channel, sent: map[interface{}]int{}}
+}
+
+func (n *fakeNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *fakeNotifier) Send(ctx context.Context, m notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.sent[m.Data["id_appointment"]]++
+	return nil
+}
+
+func (n *fakeNotifier) total() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	total := 0
+	for _, count := range n.sent {
+		total += count
+	}
+	return total
+}
+
+// scheduled retorna uma consulta marcada para daqui a until, no horário da clínica
+func scheduled(id int, until time.Duration) domain.Appointment {
+	return domain.Appointment{Id: id, AppointmentDate: now.Add(until).Format(appointment.DateLayout), Status: domain.StatusScheduled}
+}
+
+type testScheduler struct {
+	Scheduler
+	r        *fakeRepository
+	patients *fakePatients
+	email    *fakeNotifier
+	sms      *fakeNotifier
+}
+
+func newTestScheduler(list ...domain.Appointment) *testScheduler {
+	ts := &testScheduler{
+		r:        newFakeRepository(),
+		patients: &fakePatients{patients: map[int]domain.Patient{}},
+		email:    newFakeNotifier("email"),
+		sms:      newFakeNotifier("sms"),
+	}
+	for _, a := range list {
+		ts.patients.patients[a.Id] = domain.Patient{Id: a.Id, Name: "Ana", Email: "ana@example.com", Phones: []string{"11987654321"}}
+	}
+	ts.Scheduler = NewScheduler(ts.r, &fakeAppointments{list: list}, ts.patients, nil, []notify.Notifier{ts.email, ts.sms}, DefaultOffsets, clinic, time.Minute)
+	return ts
+}
+
+func (ts *testScheduler) sendDue(t *testing.T, at time.Time) int {
+	t.Helper()
+	sent, err := ts.SendDue(context.Background(), at)
+	if err != nil {
+		t.Fatalf("SendDue: %v", err)
+	}
+	return sent
+}
+
+func TestSendDueClaimsAndCompletes(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, 90*time.Minute), scheduled(2, 20*time.Hour), scheduled(3, -time.Hour))
+
+	if sent := ts.sendDue(t, now); sent != 4 {
+		t.Errorf("sent = %d, want 4", sent)
+	}
+	// só o lembrete da menor antecedência já alcançada
+	for _, want := range []key{{1, "2h", "email"}, {1, "2h", "sms"}, {2, "24h", "email"}, {2, "24h", "sms"}} {
+		if got := ts.r.status(want.appointment, want.kind, want.channel); got != StatusSent {
+			t.Errorf("reminder %v = %q, want sent", want, got)
+		}
+	}
+	if got := ts.r.status(1, "24h", "email"); got != "" {
+		t.Errorf("24h reminder of appointment 1 = %q, want none", got)
+	}
+
+	// o lembrete completo não é enviado de novo
+	if sent := ts.sendDue(t, now.Add(time.Minute)); sent != 0 {
+		t.Errorf("sent again = %d, want 0", sent)
+	}
+}
+
+func TestSendDueReleasesFailedSends(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, time.Hour))
+	ts.sms.err = errors.New("gateway timeout")
+
+	if sent := ts.sendDue(t, now); sent != 1 {
+		t.Errorf("sent = %d, want only the email", sent)
+	}
+	if got := ts.r.status(1, "2h", "sms"); got != "" {
+		t.Errorf("failed sms reminder = %q, want the claim released", got)
+	}
+
+	ts.sms.err = nil
+	if sent := ts.sendDue(t, now.Add(time.Minute)); sent != 1 {
+		t.Errorf("retry sent = %d, want the sms", sent)
+	}
+	if ts.email.total() != 1 || ts.sms.total() != 1 {
+		t.Errorf("got %d emails and %d sms, want one of each", ts.email.total(), ts.sms.total())
+	}
+}
+
+func TestSendDueSkipsChannelsWithoutConsent(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, time.Hour))
+	p := ts.patients.patients[1]
+	p.Consent = &domain.CommunicationConsent{Email: true}
+	ts.patients.patients[1] = p
+
+	if sent := ts.sendDue(t, now); sent != 1 {
+		t.Errorf("sent = %d, want only the email", sent)
+	}
+	if got := ts.r.status(1, "2h", "sms"); got != StatusSkipped {
+		t.Errorf("sms reminder = %q, want skipped", got)
+	}
+}
+
+func TestSendDueRetakesStaleClaims(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, time.Hour))
+	ts.r.claims[key{1, "2h", "email"}] = claim{status: "sending", createdAt: now.Add(-ClaimTimeout - time.Minute)}
+	ts.r.claims[key{1, "2h", "sms"}] = claim{status: "sending", createdAt: now.Add(-time.Minute)}
+
+	if sent := ts.sendDue(t, now); sent != 1 {
+		t.Errorf("sent = %d, want only the abandoned email", sent)
+	}
+	if ts.sms.total() != 0 {
+		t.Error("sms sent while another instance holds its claim")
+	}
+}
+
+func TestSendDueContinuesAfterABadAppointment(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, time.Hour), scheduled(2, time.Hour))
+	delete(ts.patients.patients, 1)
+
+	if sent := ts.sendDue(t, now); sent != 2 {
+		t.Errorf("sent = %d, want both reminders of appointment 2", sent)
+	}
+	if got := ts.r.status(1, "2h", "email"); got != "" {
+		t.Errorf("reminder of the deleted patient = %q, want the claim released", got)
+	}
+}
+
+func TestSendDueUsesTheClinicTimezone(t *testing.T) {
+	ts := newTestScheduler(scheduled(1, 90*time.Minute))
+
+	// o relógio do servidor em UTC marca 12:00 enquanto na clínica são 09:00
+	if sent := ts.sendDue(t, now.UTC()); sent != 2 {
+		t.Errorf("sent = %d, want the 2h reminders", sent)
+	}
+	if got := ts.r.status(1, "2h", "email"); got != StatusSent {
+		t.Errorf("reminder = %q, want the 2h reminder sent", got)
+	}
+}
+
+func TestConcurrentSendDueDeliversOnce(t *testing.T) {
+	var list []domain.Appointment
+	for id := 1; id <= 50; id++ {
+		list = append(list, scheduled(id, time.Duration(id)*time.Minute))
+	}
+	ts := newTestScheduler(list...)
+
+	var wg sync.WaitGroup
+	totals := make([]int, 2)
+	for i := range totals {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sent, err := ts.SendDue(context.Background(), now)
+			if err != nil {
+				t.Errorf("SendDue: %v", err)
+			}
+			totals[i] = sent
+		}(i)
+	}
+	wg.Wait()
+
+	if totals[0]+totals[1] != 100 {
+		t.Errorf("sent %d + %d, want 100 reminders in total", totals[0], totals[1])
+	}
+	for _, n := range []*fakeNotifier{ts.email, ts.sms} {
+		for id := 1; id <= 50; id++ {
+			if got := n.sent[id]; got != 1 {
+				t.Errorf("%s reminders for appointment %d = %d, want exactly one", n.channel, id, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// AppointmentBuckets agrega as consultas por dentista, período e situação
+	AppointmentBuckets(filter domain.ReportFilter, defaultMinutes int) ([]domain.ReportBucket, error)
+	// Blocks lista os bloqueios de agenda do intervalo, recortados a ele
+	Blocks(filter domain.ReportFilter) ([]domain.ScheduleBlock, error)
+	// PatientBuckets conta os pacientes cadastrados em cada período
+	PatientBuckets(filter domain.ReportFilter) ([]domain.ReportBucket, error)
+}
+
+type repository struct {
+	store store.ReportStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.ReportStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) AppointmentBuckets(filter domain.ReportFilter, defaultMinutes int) ([]domain.ReportBucket, error) {
+	return r.store.AppointmentBuckets(filter, defaultMinutes)
+}
+
+func (r *repository) Blocks(filter domain.ReportFilter) ([]domain.ScheduleBlock, error) {
+	return r.store.Blocks(filter)
+}
+
+func (r *repository) PatientBuckets(filter domain.ReportFilter) ([]domain.ReportBucket, error) {
+	return r.store.PatientBuckets(filter)
+}
@@ -0,0 +1,383 @@
+package report
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// periodLayout é o formato da data de início dos períodos, o mesmo gerado pelo banco
+const periodLayout = "2006-01-02"
+
+const (
+	// MaxDailyRange limita o intervalo dos relatórios agrupados por dia
+	MaxDailyRange = 366 * 24 * time.Hour
+	// MaxRange limita o intervalo dos demais relatórios
+	MaxRange = 5 * 366 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidRange indica um intervalo invertido ou maior que o permitido
+	ErrInvalidRange = errors.New("invalid report range")
+	// ErrInvalidPeriod indica um agrupamento diferente de day, week e month
+	ErrInvalidPeriod = errors.New("invalid report period, use day, week or month")
+)
+
+// Schedule é a jornada usada para calcular as horas disponíveis de cada dentista
+type Schedule struct {
+	HoursPerDay float64
+	Workdays    []time.Weekday
+}
+
+// DefaultSchedule é a jornada de oito horas de segunda a sexta
+var DefaultSchedule = Schedule{
+	HoursPerDay: 8,
+	Workdays:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+}
+
+type Service interface {
+	// Utilization compara as horas agendadas com as disponíveis por dentista e período (padrão week)
+	Utilization(filter domain.ReportFilter) ([]domain.UtilizationRow, error)
+	// NoShows calcula a taxa de faltas por dentista e período (padrão month)
+	NoShows(filter domain.ReportFilter) ([]domain.NoShowRow, error)
+	// NewPatients conta os pacientes cadastrados por período (padrão month)
+	NewPatients(filter domain.ReportFilter) ([]domain.NewPatientsRow, error)
+	// AppointmentsByDentist resume as consultas de cada dentista no intervalo, das mais às menos numerosas
+	AppointmentsByDentist(filter domain.ReportFilter) ([]domain.DentistAppointmentsRow, error)
+}
+
+type service struct {
+	r        Repository
+	dentists dentist.Service
+	schedule Schedule
+}
+
+// NewService cria um novo serviço. As horas disponíveis são as da jornada nos dias úteis de cada
+// período, descontados os bloqueios de agenda.
+func NewService(r Repository, dentists dentist.Service, schedule Schedule) Service {
+	return &service{r, dentists, schedule}
+}
+
+func (s *service) Utilization(filter domain.ReportFilter) ([]domain.UtilizationRow, error) {
+	windows, err := prepare(&filter, domain.PeriodWeek)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.r.AppointmentBuckets(filter, appointment.DefaultDuration)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.r.Blocks(filter)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := blockBuckets(list, filter.Period, filter.From.Location())
+	if err != nil {
+		return nil, err
+	}
+	dentists, err := s.reportDentists(appointments, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	booked := make(map[string]int)
+	for _, b := range appointments {
+		if b.Status != domain.StatusCancelled {
+			booked[b.Registration+"|"+b.Period] += b.Minutes
+		}
+	}
+	blocked := make(map[string]int)
+	for _, b := range blocks {
+		blocked[b.Registration+"|"+b.Period] += b.Minutes
+	}
+
+	rows := make([]domain.UtilizationRow, 0, len(dentists)*len(windows))
+	for _, d := range dentists {
+		for _, w := range windows {
+			key := d.Registration + "|" + w.key
+			blockedHours := float64(blocked[key]) / 60
+			available := math.Max(float64(s.workdays(w))*s.schedule.HoursPerDay-blockedHours, 0)
+			row := domain.UtilizationRow{
+				Dentist:        d,
+				Period:         w.key,
+				AvailableHours: round(available),
+				BlockedHours:   round(blockedHours),
+				BookedHours:    round(float64(booked[key]) / 60),
+			}
+			if available > 0 {
+				row.Utilization = round(float64(booked[key]) / 60 / available)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (s *service) NoShows(filter domain.ReportFilter) ([]domain.NoShowRow, error) {
+	windows, err := prepare(&filter, domain.PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.r.AppointmentBuckets(filter, appointment.DefaultDuration)
+	if err != nil {
+		return nil, err
+	}
+	dentists, err := s.reportDentists(appointments, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	total := make(map[string]int)
+	noShows := make(map[string]int)
+	for _, b := range appointments {
+		key := b.Registration + "|" + b.Period
+		switch b.Status {
+		case domain.StatusCancelled:
+		case domain.StatusNoShow:
+			noShows[key] += b.Count
+			total[key] += b.Count
+		default:
+			total[key] += b.Count
+		}
+	}
+
+	rows := []domain.NoShowRow{}
+	for _, d := range dentists {
+		for _, w := range windows {
+			key := d.Registration + "|" + w.key
+			if total[key] == 0 {
+				continue
+			}
+			rows = append(rows, domain.NoShowRow{
+				Dentist:      d,
+				Period:       w.key,
+				Appointments: total[key],
+				NoShows:      noShows[key],
+				Rate:         round(float64(noShows[key]) / float64(total[key])),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (s *service) NewPatients(filter domain.ReportFilter) ([]domain.NewPatientsRow, error) {
+	windows, err := prepare(&filter, domain.PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.r.PatientBuckets(filter)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(buckets))
+	for _, b := range buckets {
+		counts[b.Period] += b.Count
+	}
+
+	rows := make([]domain.NewPatientsRow, 0, len(windows))
+	for _, w := range windows {
+		rows = append(rows, domain.NewPatientsRow{Period: w.key, Patients: counts[w.key]})
+	}
+	return rows, nil
+}
+
+func (s *service) AppointmentsByDentist(filter domain.ReportFilter) ([]domain.DentistAppointmentsRow, error) {
+	filter.Period = ""
+	if err := validate(filter); err != nil {
+		return nil, err
+	}
+	appointments, err := s.r.AppointmentBuckets(filter, appointment.DefaultDuration)
+	if err != nil {
+		return nil, err
+	}
+	dentists, err := s.reportDentists(appointments, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]domain.DentistAppointmentsRow, 0, len(dentists))
+	index := make(map[string]int, len(dentists))
+	for _, d := range dentists {
+		index[d.Registration] = len(rows)
+		rows = append(rows, domain.DentistAppointmentsRow{Dentist: d})
+	}
+	for _, b := range appointments {
+		row := &rows[index[b.Registration]]
+		row.Total += b.Count
+		switch b.Status {
+		case domain.StatusScheduled:
+			row.Scheduled += b.Count
+		case domain.StatusConfirmed:
+			row.Confirmed += b.Count
+		case domain.StatusCheckedIn:
+			row.CheckedIn += b.Count
+		case domain.StatusCompleted:
+			row.Completed += b.Count
+		case domain.StatusCancelled:
+			row.Cancelled += b.Count
+		case domain.StatusNoShow:
+			row.NoShow += b.Count
+		}
+		if b.Status != domain.StatusCancelled {
+			row.BookedHours += float64(b.Minutes) / 60
+		}
+	}
+	for i := range rows {
+		rows[i].BookedHours = round(rows[i].BookedHours)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Total > rows[j].Total })
+	return rows, nil
+}
+
+// reportDentists lista os dentistas cadastrados e, ao final, os CROs que só aparecem nas agregações
+func (s *service) reportDentists(bucketLists ...[]domain.ReportBucket) ([]domain.ReportDentist, error) {
+	list, err := s.dentists.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	dentists := make([]domain.ReportDentist, 0, len(list))
+	known := make(map[string]bool, len(list))
+	for _, d := range list {
+		dentists = append(dentists, domain.ReportDentist{Id: d.Id, Registration: d.Registration, Name: d.Name, Surname: d.Surname})
+		known[d.Registration] = true
+	}
+	for _, buckets := range bucketLists {
+		for _, b := range buckets {
+			if !known[b.Registration] {
+				dentists = append(dentists, domain.ReportDentist{Registration: b.Registration})
+				known[b.Registration] = true
+			}
+		}
+	}
+	return dentists, nil
+}
+
+// blockBuckets divide cada bloqueio nos dias que ele ocupa e soma os minutos por dentista e
+// período, para que um bloqueio de vários dias conte em cada dia e não só no dia em que começa
+func blockBuckets(blocks []domain.ScheduleBlock, period string, location *time.Location) ([]domain.ReportBucket, error) {
+	var buckets []domain.ReportBucket
+	index := make(map[string]int)
+	for _, b := range blocks {
+		start, err := time.ParseInLocation(appointment.DateLayout, b.Start, location)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.ParseInLocation(appointment.DateLayout, b.End, location)
+		if err != nil {
+			return nil, err
+		}
+		counted := make(map[int]bool)
+		for day := start; day.Before(end); {
+			next := periodStart(day, domain.PeriodDay).AddDate(0, 0, 1)
+			if next.After(end) {
+				next = end
+			}
+			bucket := domain.ReportBucket{Registration: b.IdDentist, Period: periodStart(day, period).Format(periodLayout)}
+			i, ok := index[bucket.Registration+"|"+bucket.Period]
+			if !ok {
+				i = len(buckets)
+				index[bucket.Registration+"|"+bucket.Period] = i
+				buckets = append(buckets, bucket)
+			}
+			if !counted[i] {
+				buckets[i].Count++
+				counted[i] = true
+			}
+			buckets[i].Minutes += int(next.Sub(day) / time.Minute)
+			day = next
+		}
+	}
+	return buckets, nil
+}
+
+// workdays conta os dias úteis da jornada dentro da janela
+func (s *service) workdays(w window) int {
+	count := 0
+	for day := w.start; day.Before(w.end); day = day.AddDate(0, 0, 1) {
+		for _, weekday := range s.schedule.Workdays {
+			if day.Weekday() == weekday {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// window é um período do relatório, recortado pelo intervalo pedido
+type window struct {
+	key        string
+	start, end time.Time
+}
+
+// prepare aplica o agrupamento padrão, valida o filtro e lista os períodos do intervalo
+func prepare(filter *domain.ReportFilter, defaultPeriod string) ([]window, error) {
+	if filter.Period == "" {
+		filter.Period = defaultPeriod
+	}
+	if err := validate(*filter); err != nil {
+		return nil, err
+	}
+
+	var windows []window
+	start := periodStart(filter.From, filter.Period)
+	for start.Before(filter.To) {
+		var next time.Time
+		switch filter.Period {
+		case domain.PeriodDay:
+			next = start.AddDate(0, 0, 1)
+		case domain.PeriodWeek:
+			next = start.AddDate(0, 0, 7)
+		default:
+			next = start.AddDate(0, 1, 0)
+		}
+		w := window{key: start.Format(periodLayout), start: start, end: next}
+		if w.start.Before(filter.From) {
+			w.start = filter.From
+		}
+		if w.end.After(filter.To) {
+			w.end = filter.To
+		}
+		windows = append(windows, w)
+		start = next
+	}
+	return windows, nil
+}
+
+func validate(filter domain.ReportFilter) error {
+	switch filter.Period {
+	case "", domain.PeriodDay, domain.PeriodWeek, domain.PeriodMonth:
+	default:
+		return ErrInvalidPeriod
+	}
+	limit := MaxRange
+	if filter.Period == domain.PeriodDay {
+		limit = MaxDailyRange
+	}
+	if !filter.From.Before(filter.To) || filter.To.Sub(filter.From) > limit {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// periodStart retorna o início do período que contém a data: o dia, a segunda-feira da semana ou
+// o primeiro dia do mês
+func periodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case domain.PeriodWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case domain.PeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
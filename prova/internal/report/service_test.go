@@ -0,0 +1,217 @@
+package report
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// fakeRepository devolve agregações fixas e guarda o filtro recebido
+type fakeRepository struct {
+	appointments []domain.ReportBucket
+	blocks       []domain.ScheduleBlock
+	filter       domain.ReportFilter
+}
+
+func (r *fakeRepository) AppointmentBuckets(filter domain.ReportFilter, defaultMinutes int) ([]domain.ReportBucket, error) {
+	r.filter = filter
+	return r.appointments, nil
+}
+
+func (r *fakeRepository) Blocks(filter domain.ReportFilter) ([]domain.ScheduleBlock, error) {
+	return r.blocks, nil
+}
+
+func (r *fakeRepository) PatientBuckets(filter domain.ReportFilter) ([]domain.ReportBucket, error) {
+	return nil, nil
+}
+
+type fakeDentists struct {
+	dentist.Service
+}
+
+func (fakeDentists) GetAll() ([]domain.Dentist, error) {
+	return []domain.Dentist{{Id: 7, Registration: "CRO-7", Name: "Ana"}}, nil
+}
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter domain.ReportFilter
+		want   []window
+	}{
+		{
+			// 06/03/2024 é uma quarta-feira: a primeira semana começa na segunda e é recortada
+			"weeks clipped to the range",
+			domain.ReportFilter{From: date(2024, 3, 6, 0), To: date(2024, 3, 20, 0), Period: domain.PeriodWeek},
+			[]window{
+				{"2024-03-04", date(2024, 3, 6, 0), date(2024, 3, 11, 0)},
+				{"2024-03-11", date(2024, 3, 11, 0), date(2024, 3, 18, 0)},
+				{"2024-03-18", date(2024, 3, 18, 0), date(2024, 3, 20, 0)},
+			},
+		},
+		{
+			"months across the year",
+			domain.ReportFilter{From: date(2023, 12, 15, 0), To: date(2024, 2, 1, 0), Period: domain.PeriodMonth},
+			[]window{
+				{"2023-12-01", date(2023, 12, 15, 0), date(2024, 1, 1, 0)},
+				{"2024-01-01", date(2024, 1, 1, 0), date(2024, 2, 1, 0)},
+			},
+		},
+		{
+			"days with a partial last day",
+			domain.ReportFilter{From: date(2024, 2, 28, 0), To: date(2024, 3, 1, 12), Period: domain.PeriodDay},
+			[]window{
+				{"2024-02-28", date(2024, 2, 28, 0), date(2024, 2, 29, 0)},
+				{"2024-02-29", date(2024, 2, 29, 0), date(2024, 3, 1, 0)},
+				{"2024-03-01", date(2024, 3, 1, 0), date(2024, 3, 1, 12)},
+			},
+		},
+		{
+			"default period",
+			domain.ReportFilter{From: date(2024, 3, 1, 0), To: date(2024, 4, 1, 0)},
+			[]window{{"2024-03-01", date(2024, 3, 1, 0), date(2024, 4, 1, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows, err := prepare(&tt.filter, domain.PeriodMonth)
+			if err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+			if !reflect.DeepEqual(windows, tt.want) {
+				t.Errorf("windows = %v, want %v", windows, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareRejectsInvalidFilters(t *testing.T) {
+	from := date(2024, 1, 1, 0)
+	tests := []struct {
+		name   string
+		filter domain.ReportFilter
+		want   error
+	}{
+		{"unknown period", domain.ReportFilter{From: from, To: from.AddDate(0, 1, 0), Period: "year"}, ErrInvalidPeriod},
+		{"inverted range", domain.ReportFilter{From: from, To: from.AddDate(0, 0, -1)}, ErrInvalidRange},
+		{"empty range", domain.ReportFilter{From: from, To: from}, ErrInvalidRange},
+		{"daily range over the limit", domain.ReportFilter{From: from, To: from.Add(MaxDailyRange + time.Hour), Period: domain.PeriodDay}, ErrInvalidRange},
+		{"range over the limit", domain.ReportFilter{From: from, To: from.Add(MaxRange + time.Hour)}, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		if _, err := prepare(&tt.filter, domain.PeriodWeek); !errors.Is(err, tt.want) {
+			t.Errorf("%s: prepare = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// o mesmo intervalo longo é aceito quando agrupado por semana
+	filter := domain.ReportFilter{From: from, To: from.Add(MaxDailyRange + time.Hour)}
+	if _, err := prepare(&filter, domain.PeriodWeek); err != nil {
+		t.Errorf("weekly range over the daily limit = %v", err)
+	}
+}
+
+func TestWorkdays(t *testing.T) {
+	s := &service{schedule: DefaultSchedule}
+	tests := []struct {
+		name string
+		w    window
+		want int
+	}{
+		{"full week", window{start: date(2024, 3, 4, 0), end: date(2024, 3, 11, 0)}, 5},
+		{"weekend", window{start: date(2024, 3, 9, 0), end: date(2024, 3, 11, 0)}, 0},
+		{"from wednesday", window{start: date(2024, 3, 6, 0), end: date(2024, 3, 11, 0)}, 3},
+		{"leap february", window{start: date(2024, 2, 1, 0), end: date(2024, 3, 1, 0)}, 21},
+	}
+	for _, tt := range tests {
+		if got := s.workdays(tt.w); got != tt.want {
+			t.Errorf("%s: workdays = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	s.schedule.Workdays = []time.Weekday{time.Saturday}
+	if got := s.workdays(window{start: date(2024, 3, 1, 0), end: date(2024, 4, 1, 0)}); got != 5 {
+		t.Errorf("saturdays in march = %d, want 5", got)
+	}
+}
+
+func TestBlockBucketsSplitsBlocksPerDay(t *testing.T) {
+	// de sexta às 18h a segunda às 10h
+	blocks := []domain.ScheduleBlock{
+		{Id: 1, IdDentist: "CRO-7", Start: "08/03/2024 18:00", End: "11/03/2024 10:00"},
+		{Id: 2, IdDentist: "CRO-7", Start: "11/03/2024 14:00", End: "11/03/2024 15:30"},
+	}
+	tests := []struct {
+		period string
+		want   []domain.ReportBucket
+	}{
+		{domain.PeriodDay, []domain.ReportBucket{
+			{Registration: "CRO-7", Period: "2024-03-08", Count: 1, Minutes: 6 * 60},
+			{Registration: "CRO-7", Period: "2024-03-09", Count: 1, Minutes: 24 * 60},
+			{Registration: "CRO-7", Period: "2024-03-10", Count: 1, Minutes: 24 * 60},
+			{Registration: "CRO-7", Period: "2024-03-11", Count: 2, Minutes: 10*60 + 90},
+		}},
+		{domain.PeriodWeek, []domain.ReportBucket{
+			{Registration: "CRO-7", Period: "2024-03-04", Count: 1, Minutes: 54 * 60},
+			{Registration: "CRO-7", Period: "2024-03-11", Count: 2, Minutes: 10*60 + 90},
+		}},
+		{domain.PeriodMonth, []domain.ReportBucket{
+			{Registration: "CRO-7", Period: "2024-03-01", Count: 2, Minutes: 64*60 + 90},
+		}},
+	}
+	for _, tt := range tests {
+		buckets, err := blockBuckets(blocks, tt.period, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: blockBuckets: %v", tt.period, err)
+		}
+		if !reflect.DeepEqual(buckets, tt.want) {
+			t.Errorf("%s: buckets = %+v, want %+v", tt.period, buckets, tt.want)
+		}
+	}
+
+	if _, err := blockBuckets([]domain.ScheduleBlock{{Start: "2024-03-08 18:00", End: "11/03/2024 10:00"}}, domain.PeriodDay, time.UTC); err == nil {
+		t.Error("blockBuckets accepted a malformed date")
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	r := &fakeRepository{
+		appointments: []domain.ReportBucket{
+			{Registration: "CRO-7", Period: "2024-03-04", Status: domain.StatusCompleted, Count: 2, Minutes: 120},
+			{Registration: "CRO-7", Period: "2024-03-04", Status: domain.StatusCancelled, Count: 1, Minutes: 60},
+		},
+		// de segunda às 20h a terça às 2h: quatro horas na segunda e duas na terça
+		blocks: []domain.ScheduleBlock{{Id: 1, IdDentist: "CRO-7", Start: "04/03/2024 20:00", End: "05/03/2024 02:00"}},
+	}
+	s := NewService(r, fakeDentists{}, DefaultSchedule)
+
+	rows, err := s.Utilization(domain.ReportFilter{From: date(2024, 3, 4, 0), To: date(2024, 3, 6, 0), Period: domain.PeriodDay})
+	if err != nil {
+		t.Fatalf("Utilization: %v", err)
+	}
+	ana := domain.ReportDentist{Id: 7, Registration: "CRO-7", Name: "Ana"}
+	want := []domain.UtilizationRow{
+		{Dentist: ana, Period: "2024-03-04", AvailableHours: 4, BlockedHours: 4, BookedHours: 2, Utilization: 0.5},
+		{Dentist: ana, Period: "2024-03-05", AvailableHours: 6, BlockedHours: 2},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %+v, want %+v", rows, want)
+	}
+
+	// sem agrupamento, o relatório usa semanas
+	if _, err := s.Utilization(domain.ReportFilter{From: date(2024, 3, 4, 0), To: date(2024, 3, 6, 0)}); err != nil {
+		t.Fatalf("Utilization: %v", err)
+	}
+	if r.filter.Period != domain.PeriodWeek {
+		t.Errorf("period = %q, want week", r.filter.Period)
+	}
+}
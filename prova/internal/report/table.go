@@ -0,0 +1,53 @@
+package report
+
+import (
+	"strconv"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// Table converte as linhas de um relatório em cabeçalho e valores, para a saída em CSV. Retorna
+// false se o tipo das linhas não for de um relatório.
+func Table(rows interface{}) ([]string, [][]string, bool) {
+	var records [][]string
+	switch rows := rows.(type) {
+	case []domain.UtilizationRow:
+		for _, r := range rows {
+			records = append(records, append(dentistColumns(r.Dentist), r.Period, number(r.AvailableHours),
+				number(r.BlockedHours), number(r.BookedHours), number(r.Utilization)))
+		}
+		return append(dentistHeader(), "period", "available_hours", "blocked_hours", "booked_hours", "utilization"), records, true
+	case []domain.NoShowRow:
+		for _, r := range rows {
+			records = append(records, append(dentistColumns(r.Dentist), r.Period, strconv.Itoa(r.Appointments),
+				strconv.Itoa(r.NoShows), number(r.Rate)))
+		}
+		return append(dentistHeader(), "period", "appointments", "no_shows", "rate"), records, true
+	case []domain.NewPatientsRow:
+		for _, r := range rows {
+			records = append(records, []string{r.Period, strconv.Itoa(r.Patients)})
+		}
+		return []string{"period", "patients"}, records, true
+	case []domain.DentistAppointmentsRow:
+		for _, r := range rows {
+			records = append(records, append(dentistColumns(r.Dentist), strconv.Itoa(r.Total), strconv.Itoa(r.Scheduled),
+				strconv.Itoa(r.Confirmed), strconv.Itoa(r.CheckedIn), strconv.Itoa(r.Completed),
+				strconv.Itoa(r.Cancelled), strconv.Itoa(r.NoShow), number(r.BookedHours)))
+		}
+		return append(dentistHeader(), "total", "scheduled", "confirmed", "checked_in", "completed", "cancelled",
+			"no_show", "booked_hours"), records, true
+	}
+	return nil, nil, false
+}
+
+func dentistHeader() []string {
+	return []string{"dentist_id", "dentist_registration", "dentist_name", "dentist_surname"}
+}
+
+func dentistColumns(d domain.ReportDentist) []string {
+	return []string{strconv.Itoa(d.Id), d.Registration, d.Name, d.Surname}
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
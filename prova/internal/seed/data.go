@@ -0,0 +1,54 @@
+package seed
+
+// Os dados abaixo são fictícios; nomes e lugares comuns no Brasil, sem acentos para que também
+// sirvam aos e-mails.
+
+var firstNames = []string{
+	"Ana", "Maria", "Juliana", "Fernanda", "Patricia", "Aline", "Camila", "Amanda", "Bruna", "Jessica",
+	"Leticia", "Mariana", "Beatriz", "Larissa", "Gabriela", "Vanessa", "Luciana", "Renata", "Carolina", "Adriana",
+	"Isabela", "Helena", "Sofia", "Laura", "Manuela", "Valentina", "Alice", "Clara", "Lorena", "Natalia",
+	"Joao", "Jose", "Antonio", "Francisco", "Carlos", "Paulo", "Pedro", "Lucas", "Luiz", "Marcos",
+	"Gabriel", "Rafael", "Daniel", "Marcelo", "Bruno", "Eduardo", "Felipe", "Rodrigo", "Gustavo", "Mateus",
+	"Thiago", "Diego", "Leonardo", "Vinicius", "Henrique", "Arthur", "Bernardo", "Heitor", "Davi", "Samuel",
+}
+
+var surnames = []string{
+	"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+	"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+	"Rocha", "Dias", "Nascimento", "Andrade", "Moreira", "Nunes", "Marques", "Machado", "Mendes", "Freitas",
+	"Cardoso", "Ramos", "Goncalves", "Santana", "Teixeira", "Araujo", "Castro", "Pinto", "Correia", "Moura",
+	"Batista", "Reis", "Monteiro", "Campos", "Cavalcanti", "Duarte", "Farias", "Matos", "Pires", "Borges",
+}
+
+var streets = []string{
+	"Rua das Flores", "Avenida Brasil", "Rua XV de Novembro", "Rua Sete de Setembro", "Avenida Paulista",
+	"Rua Tiradentes", "Rua Santos Dumont", "Avenida Getulio Vargas", "Rua da Paz", "Rua Dom Pedro II",
+	"Avenida Rio Branco", "Rua Sao Jose", "Rua Barao do Rio Branco", "Rua Marechal Deodoro", "Rua Bela Vista",
+}
+
+var districts = []string{"Centro", "Jardim America", "Vila Nova", "Boa Vista", "Santa Cecilia", "Liberdade", "Bela Vista", "Sao Francisco"}
+
+// city é uma cidade com a sigla do estado e o DDD
+type city struct {
+	name     string
+	state    string
+	areaCode string
+}
+
+// cities são as cidades dos endereços, repetidas conforme o peso de cada uma
+var cities = []city{
+	{"Sao Paulo", "SP", "11"}, {"Sao Paulo", "SP", "11"}, {"Sao Paulo", "SP", "11"}, {"Campinas", "SP", "19"},
+	{"Rio de Janeiro", "RJ", "21"}, {"Rio de Janeiro", "RJ", "21"}, {"Niteroi", "RJ", "21"},
+	{"Belo Horizonte", "MG", "31"}, {"Uberlandia", "MG", "34"}, {"Curitiba", "PR", "41"}, {"Londrina", "PR", "43"},
+	{"Porto Alegre", "RS", "51"}, {"Florianopolis", "SC", "48"}, {"Salvador", "BA", "71"}, {"Recife", "PE", "81"},
+	{"Fortaleza", "CE", "85"}, {"Brasilia", "DF", "61"}, {"Goiania", "GO", "62"}, {"Belem", "PA", "91"}, {"Manaus", "AM", "92"},
+}
+
+// descriptions são os motivos das consultas
+var descriptions = []string{
+	"Avaliacao inicial", "Limpeza e profilaxia", "Restauracao", "Tratamento de canal", "Extracao",
+	"Clareamento", "Manutencao de aparelho", "Retorno", "Raio-X panoramico", "Aplicacao de fluor",
+	"Raspagem periodontal", "Ajuste de protese", "Urgencia: dor de dente", "Moldagem para coroa",
+}
+
+var relationships = []string{"mae", "pai", "avo", "tia", "tio"}
@@ -0,0 +1,459 @@
+// Package seed gera cadastros e consultas fictícios, mas realistas, para demonstrações e testes de
+// carga. A mesma semente e as mesmas opções geram sempre os mesmos dados.
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+)
+
+// Volumes e agenda padrão
+const (
+	DefaultDentists     = 10
+	DefaultPatients     = 200
+	DefaultAppointments = 1000
+	DefaultBatchSize    = 500
+	DefaultSlot         = 30 * time.Minute
+	DefaultOccupancy    = 0.6
+	// MaxRows limita a quantidade de registros de cada tipo
+	MaxRows = 1000000
+)
+
+// createdAtLayout é o formato de created_at esperado pelo banco na inclusão do paciente
+const createdAtLayout = "02/01/2006 15:04:05"
+
+// ErrInvalidOptions indica volumes ou agenda inválidos
+var ErrInvalidOptions = errors.New("invalid seed options")
+
+// Shift é um turno de atendimento, em horas do dia
+type Shift struct {
+	Start, End int
+}
+
+// DefaultShifts são os turnos padrão, das 8h às 18h com intervalo de almoço
+var DefaultShifts = []Shift{{8, 12}, {13, 18}}
+
+// DefaultWorkdays são os dias de atendimento padrão, de segunda a sexta
+var DefaultWorkdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// Options são os volumes e a agenda dos dados gerados
+type Options struct {
+	Seed         int64
+	Dentists     int
+	Patients     int
+	Appointments int
+	// Start é o primeiro dia da agenda. Só a data é usada, no fuso local, em que o banco interpreta
+	// as datas das consultas.
+	Start time.Time
+	// Today separa as consultas passadas, com situação final, das futuras; zero é o meio da agenda.
+	// Como em Start, só a data é usada.
+	Today time.Time
+	// Workdays e Shifts são os dias e turnos de atendimento, divididos em horários de Slot
+	Workdays []time.Weekday
+	Shifts   []Shift
+	Slot     time.Duration
+	// Occupancy é a fração dos horários de cada dentista ocupada por consultas
+	Occupancy float64
+	// BatchSize é a quantidade de registros enviada de cada vez ao Writer
+	BatchSize int
+}
+
+// Writer grava os dados gerados, em lotes. Os dentistas e os pacientes são gravados antes das
+// consultas, que os referenciam pelo CRO e pelo documento.
+type Writer interface {
+	Dentists(dentists []domain.Dentist) error
+	Patients(patients []domain.Patient) error
+	Appointments(appointments []domain.Appointment) error
+}
+
+// Summary resume os dados gerados
+type Summary struct {
+	Dentists     int       `json:"dentists"`
+	Patients     int       `json:"patients"`
+	Appointments int       `json:"appointments"`
+	First        time.Time `json:"first"`
+	Last         time.Time `json:"last"`
+}
+
+// Generate gera os dados e os grava em w. Cada dentista atende um paciente por horário e cada
+// paciente tem no máximo uma consulta por horário, de modo que não há conflitos de agenda.
+func Generate(opts Options, w Writer) (Summary, error) {
+	opts, err := withDefaults(opts)
+	if err != nil {
+		return Summary{}, err
+	}
+	g := &generator{rng: rand.New(rand.NewSource(opts.Seed)), opts: opts}
+	var summary Summary
+
+	registrations := make([]string, 0, opts.Dentists)
+	err = batches(opts.Dentists, opts.BatchSize, func(n int) error {
+		dentists := make([]domain.Dentist, n)
+		for i := range dentists {
+			dentists[i] = g.dentist()
+			registrations = append(registrations, dentists[i].Registration)
+		}
+		return w.Dentists(dentists)
+	})
+	if err != nil {
+		return summary, fmt.Errorf("dentists: %w", err)
+	}
+	summary.Dentists = len(registrations)
+
+	documents := make([]string, 0, opts.Patients)
+	err = batches(opts.Patients, opts.BatchSize, func(n int) error {
+		patients := make([]domain.Patient, n)
+		for i := range patients {
+			patients[i] = g.patient()
+			documents = append(documents, patients[i].Document)
+		}
+		return w.Patients(patients)
+	})
+	if err != nil {
+		return summary, fmt.Errorf("patients: %w", err)
+	}
+	summary.Patients = len(documents)
+
+	summary.Appointments, summary.First, summary.Last, err = g.appointments(registrations, documents, w)
+	if err != nil {
+		return summary, fmt.Errorf("appointments: %w", err)
+	}
+	return summary, nil
+}
+
+func withDefaults(opts Options) (Options, error) {
+	if opts.Workdays == nil {
+		opts.Workdays = DefaultWorkdays
+	}
+	if opts.Shifts == nil {
+		opts.Shifts = DefaultShifts
+	}
+	if opts.Slot == 0 {
+		opts.Slot = DefaultSlot
+	}
+	if opts.Occupancy == 0 {
+		opts.Occupancy = DefaultOccupancy
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Start.IsZero() {
+		return opts, fmt.Errorf("%w: start date is required", ErrInvalidOptions)
+	}
+	opts.Start = localDay(opts.Start)
+	if !opts.Today.IsZero() {
+		opts.Today = localDay(opts.Today)
+	}
+
+	switch {
+	case opts.Dentists < 0 || opts.Patients < 0 || opts.Appointments < 0,
+		opts.Dentists > MaxRows || opts.Patients > MaxRows || opts.Appointments > MaxRows:
+		return opts, fmt.Errorf("%w: volumes must be between 0 and %d", ErrInvalidOptions, MaxRows)
+	case opts.Appointments > 0 && (opts.Dentists == 0 || opts.Patients == 0):
+		return opts, fmt.Errorf("%w: appointments need dentists and patients", ErrInvalidOptions)
+	case len(opts.Workdays) == 0 || opts.Slot < time.Minute:
+		return opts, fmt.Errorf("%w: empty schedule", ErrInvalidOptions)
+	case opts.Occupancy < 0 || opts.Occupancy > 1:
+		return opts, fmt.Errorf("%w: occupancy must be between 0 and 1", ErrInvalidOptions)
+	case opts.BatchSize < 0:
+		return opts, fmt.Errorf("%w: invalid batch size", ErrInvalidOptions)
+	}
+	for _, weekday := range opts.Workdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return opts, fmt.Errorf("%w: invalid workday %d", ErrInvalidOptions, weekday)
+		}
+	}
+	for _, s := range opts.Shifts {
+		if s.Start < 0 || s.End > 24 || s.Start >= s.End {
+			return opts, fmt.Errorf("%w: invalid shift %d-%d", ErrInvalidOptions, s.Start, s.End)
+		}
+	}
+	if len(slots(opts)) == 0 {
+		return opts, fmt.Errorf("%w: empty schedule", ErrInvalidOptions)
+	}
+	return opts, nil
+}
+
+// localDay é a meia-noite, no fuso local, da data de t
+func localDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// batches chama write com o tamanho de cada lote até completar total
+func batches(total, size int, write func(n int) error) error {
+	for done := 0; done < total; done += size {
+		n := size
+		if total-done < n {
+			n = total - done
+		}
+		if err := write(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slots são os inícios dos horários de um dia, contados da meia-noite
+func slots(opts Options) []time.Duration {
+	var starts []time.Duration
+	for _, s := range opts.Shifts {
+		end := time.Duration(s.End) * time.Hour
+		for at := time.Duration(s.Start) * time.Hour; at+opts.Slot <= end; at += opts.Slot {
+			starts = append(starts, at)
+		}
+	}
+	return starts
+}
+
+type generator struct {
+	rng  *rand.Rand
+	opts Options
+
+	registrations map[string]bool
+	documents     map[string]bool
+	emails        int
+}
+
+func (g *generator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
+
+func (g *generator) dentist() domain.Dentist {
+	if g.registrations == nil {
+		g.registrations = make(map[string]bool)
+	}
+	var registration string
+	for registration == "" || g.registrations[registration] {
+		// os números de CRO não começam com zero, que o cadastro descartaria
+		registration = fmt.Sprintf("CRO-%s %d", g.cities().state, 1000+g.rng.Intn(99000))
+	}
+	g.registrations[registration] = true
+	return domain.Dentist{
+		Name:         g.pick(firstNames),
+		Surname:      g.pick(surnames),
+		Registration: registration,
+	}
+}
+
+func (g *generator) cities() city {
+	return cities[g.rng.Intn(len(cities))]
+}
+
+func (g *generator) patient() domain.Patient {
+	name, surname := g.pick(firstNames), g.pick(surnames)+" "+g.pick(surnames)
+	home := g.cities()
+	// cadastrados nos três anos anteriores ao início da agenda
+	createdAt := g.opts.Start.Add(-time.Duration(g.rng.Int63n(int64(3 * 365 * 24 * time.Hour))))
+	createdAt = createdAt.Truncate(time.Second)
+
+	p := domain.Patient{
+		Name:              name,
+		Surname:           surname,
+		Document:          g.cpf(),
+		CreatedAt:         createdAt.Format(createdAtLayout),
+		Phones:            []string{g.phone(home)},
+		PreferredLanguage: "pt-BR",
+		Consent: &domain.CommunicationConsent{
+			Email:    g.rng.Float64() < 0.8,
+			SMS:      g.rng.Float64() < 0.5,
+			WhatsApp: g.rng.Float64() < 0.7,
+		},
+	}
+	g.emails++
+	p.Email = strings.ToLower(fmt.Sprintf("%s.%s%d@example.com", name, strings.ReplaceAll(surname, " ", "."), g.emails))
+
+	// 15% de menores de idade, que precisam de responsável legal
+	age := 18 + g.rng.Intn(70)
+	minor := g.rng.Float64() < 0.15
+	if minor {
+		age = 3 + g.rng.Intn(15)
+	}
+	birthDate := g.opts.Start.AddDate(-age, 0, -g.rng.Intn(365))
+	p.BirthDate = birthDate.Format(patient.BirthDateLayout)
+	if minor {
+		p.Guardian = &domain.Contact{
+			Name:         g.pick(firstNames) + " " + surname,
+			Document:     g.cpf(),
+			Phone:        g.phone(home),
+			Relationship: g.pick(relationships),
+		}
+	}
+
+	if g.rng.Float64() < 0.7 {
+		p.Address = &domain.Address{
+			Street:   g.pick(streets),
+			Number:   strconv.Itoa(1 + g.rng.Intn(3000)),
+			District: g.pick(districts),
+			City:     home.name,
+			State:    home.state,
+			ZipCode:  fmt.Sprintf("%05d-%03d", g.rng.Intn(100000), g.rng.Intn(1000)),
+		}
+	}
+	return p
+}
+
+// cpf gera um CPF válido, com os dígitos verificadores, diferente dos já gerados
+func (g *generator) cpf() string {
+	if g.documents == nil {
+		g.documents = make(map[string]bool)
+	}
+	for {
+		digits := make([]int, 11)
+		same := true
+		for i := 0; i < 9; i++ {
+			digits[i] = g.rng.Intn(10)
+			same = same && digits[i] == digits[0]
+		}
+		if same {
+			// CPFs com todos os dígitos iguais são inválidos
+			continue
+		}
+		digits[9] = cpfDigit(digits[:9])
+		digits[10] = cpfDigit(digits[:10])
+		var b strings.Builder
+		for _, d := range digits {
+			b.WriteByte(byte('0' + d))
+		}
+		if document := b.String(); !g.documents[document] {
+			g.documents[document] = true
+			return document
+		}
+	}
+}
+
+// cpfDigit calcula o dígito verificador do CPF para os dígitos anteriores
+func cpfDigit(digits []int) int {
+	sum := 0
+	for i, d := range digits {
+		sum += d * (len(digits) + 1 - i)
+	}
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+	return 0
+}
+
+// phone gera um celular com o DDD da cidade, no formato normalizado do cadastro
+func (g *generator) phone(c city) string {
+	return fmt.Sprintf("%s9%08d", c.areaCode, g.rng.Intn(100000000))
+}
+
+// appointments preenche a agenda dia a dia a partir de Start: em cada horário de atendimento,
+// cada dentista tem uma consulta com probabilidade Occupancy, com um paciente que ainda não tem
+// consulta naquele horário
+func (g *generator) appointments(registrations, documents []string, w Writer) (int, time.Time, time.Time, error) {
+	total := g.opts.Appointments
+	if total == 0 {
+		return 0, time.Time{}, time.Time{}, nil
+	}
+	daySlots := slots(g.opts)
+	today := g.opts.Today
+	if today.IsZero() {
+		// meio da agenda estimada pela ocupação
+		perDay := float64(len(registrations)*len(daySlots)) * g.opts.Occupancy
+		today = g.workday(g.opts.Start, int(float64(total)/perDay/2))
+	}
+
+	var first, last time.Time
+	batch := make([]domain.Appointment, 0, g.opts.BatchSize)
+	busy := make(map[int]bool)
+	created := 0
+	for day := g.workday(g.opts.Start, 0); created < total; day = g.workday(day.AddDate(0, 0, 1), 0) {
+		for _, offset := range daySlots {
+			// o horário é montado pelo relógio, e não somado à meia-noite, para não se deslocar nos
+			// dias de mudança do horário de verão
+			y, m, d := day.Date()
+			at := time.Date(y, m, d, 0, int(offset/time.Minute), 0, 0, time.Local)
+			for k := range busy {
+				delete(busy, k)
+			}
+			for _, registration := range registrations {
+				if created == total || len(busy) == len(documents) {
+					break
+				}
+				if g.rng.Float64() >= g.opts.Occupancy {
+					continue
+				}
+				p := g.rng.Intn(len(documents))
+				for busy[p] {
+					p = g.rng.Intn(len(documents))
+				}
+				busy[p] = true
+
+				batch = append(batch, domain.Appointment{
+					Description:     g.pick(descriptions),
+					AppointmentDate: at.Format(appointment.DateLayout),
+					IdDentist:       registration,
+					IdPatient:       documents[p],
+					Status:          g.status(at.Before(today)),
+					Duration:        int(g.opts.Slot / time.Minute),
+				})
+				if first.IsZero() {
+					first = at
+				}
+				last = at
+				created++
+				if len(batch) == cap(batch) {
+					if err := w.Appointments(batch); err != nil {
+						return created - len(batch), first, last, err
+					}
+					batch = batch[:0]
+				}
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := w.Appointments(batch); err != nil {
+			return created - len(batch), first, last, err
+		}
+	}
+	return created, first, last, nil
+}
+
+// workday retorna o n-ésimo dia de atendimento a partir de day, ele incluído
+func (g *generator) workday(day time.Time, n int) time.Time {
+	for {
+		for _, weekday := range g.opts.Workdays {
+			if day.Weekday() == weekday {
+				if n == 0 {
+					return day
+				}
+				n--
+				break
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// status sorteia a situação: as consultas passadas foram realizadas, na maioria, e as futuras
+// estão agendadas ou confirmadas
+func (g *generator) status(past bool) string {
+	r := g.rng.Float64()
+	if past {
+		switch {
+		case r < 0.85:
+			return domain.StatusCompleted
+		case r < 0.93:
+			return domain.StatusNoShow
+		default:
+			return domain.StatusCancelled
+		}
+	}
+	switch {
+	case r < 0.70:
+		return domain.StatusScheduled
+	case r < 0.95:
+		return domain.StatusConfirmed
+	default:
+		return domain.StatusCancelled
+	}
+}
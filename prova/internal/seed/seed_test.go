@@ -0,0 +1,119 @@
+package seed
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// memoryWriter guarda os dados gerados
+type memoryWriter struct {
+	dentists     []domain.Dentist
+	patients     []domain.Patient
+	appointments []domain.Appointment
+}
+
+func (w *memoryWriter) Dentists(dentists []domain.Dentist) error {
+	w.dentists = append(w.dentists, dentists...)
+	return nil
+}
+
+func (w *memoryWriter) Patients(patients []domain.Patient) error {
+	w.patients = append(w.patients, patients...)
+	return nil
+}
+
+func (w *memoryWriter) Appointments(appointments []domain.Appointment) error {
+	w.appointments = append(w.appointments, appointments...)
+	return nil
+}
+
+func TestCPFDigit(t *testing.T) {
+	// 529.982.247-25 e 111.444.777-35 são CPFs válidos
+	for _, cpf := range [][]int{{5, 2, 9, 9, 8, 2, 2, 4, 7, 2, 5}, {1, 1, 1, 4, 4, 4, 7, 7, 7, 3, 5}} {
+		if got := cpfDigit(cpf[:9]); got != cpf[9] {
+			t.Errorf("first digit of %v = %d, want %d", cpf[:9], got, cpf[9])
+		}
+		if got := cpfDigit(cpf[:10]); got != cpf[10] {
+			t.Errorf("second digit of %v = %d, want %d", cpf[:10], got, cpf[10])
+		}
+	}
+	// resto menor que 2 resulta em zero: 6 * 2 = 12, resto 1
+	if got := cpfDigit([]int{0, 0, 0, 0, 0, 0, 0, 0, 6}); got != 0 {
+		t.Errorf("cpfDigit with rest 1 = %d, want 0", got)
+	}
+}
+
+func TestWithDefaultsRejectsInvalidOptions(t *testing.T) {
+	start := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+	cases := map[string]Options{
+		"no start":            {},
+		"invalid workday":     {Start: start, Workdays: []time.Weekday{time.Monday, 7}},
+		"no workdays":         {Start: start, Workdays: []time.Weekday{}},
+		"invalid shift":       {Start: start, Shifts: []Shift{{18, 8}}},
+		"occupancy":           {Start: start, Occupancy: 2},
+		"orphan appointments": {Start: start, Appointments: 1},
+	}
+	for name, opts := range cases {
+		if _, err := withDefaults(opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%s: err = %v, want ErrInvalidOptions", name, err)
+		}
+	}
+}
+
+func TestGenerateSchedulesInLocalTime(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone America/New_York is not available: %v", err)
+	}
+	local := time.Local
+	time.Local = location
+	defer func() { time.Local = local }()
+
+	// a agenda atravessa a mudança para o horário de verão, em 10/03/2024
+	opts := Options{Seed: 7, Dentists: 3, Patients: 20, Appointments: 300, Start: time.Date(2024, time.March, 7, 0, 0, 0, 0, time.UTC)}
+	w := &memoryWriter{}
+	summary, err := Generate(opts, w)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if summary.Appointments != 300 || len(w.appointments) != 300 || len(w.dentists) != 3 || len(w.patients) != 20 {
+		t.Fatalf("summary = %+v", summary)
+	}
+
+	busy := map[string]bool{}
+	for _, a := range w.appointments {
+		at, err := time.ParseInLocation(appointment.DateLayout, a.AppointmentDate, time.Local)
+		if err != nil {
+			t.Fatalf("invalid appointment date %q", a.AppointmentDate)
+		}
+		if hour := at.Hour(); hour < 8 || hour >= 18 || hour == 12 {
+			t.Errorf("appointment at %s is outside the shifts", a.AppointmentDate)
+		}
+		if weekday := at.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			t.Errorf("appointment at %s is on a %s", a.AppointmentDate, weekday)
+		}
+		for _, key := range []string{a.IdDentist + " " + a.AppointmentDate, a.IdPatient + " " + a.AppointmentDate} {
+			if busy[key] {
+				t.Errorf("%s has two appointments", key)
+			}
+			busy[key] = true
+		}
+	}
+	if summary.First.Location() != time.Local || summary.First.Before(time.Date(2024, time.March, 7, 8, 0, 0, 0, time.Local)) {
+		t.Errorf("first appointment at %v, want 07/03/2024 from 8:00 in the local time zone", summary.First)
+	}
+
+	again := &memoryWriter{}
+	if _, err := Generate(opts, again); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for i := range w.appointments {
+		if w.appointments[i] != again.appointments[i] {
+			t.Fatalf("appointment %d differs with the same seed: %+v and %+v", i, w.appointments[i], again.appointments[i])
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package seed
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// storeWriter grava os dados em lotes pelo Batch do store, uma transação por lote
+type storeWriter struct {
+	s store.Store
+}
+
+// NewStoreWriter cria um Writer que grava em qualquer implementação de store.Store
+func NewStoreWriter(s store.Store) Writer {
+	return &storeWriter{s}
+}
+
+func (w *storeWriter) Dentists(dentists []domain.Dentist) error {
+	ops := make([]store.BatchOp, len(dentists))
+	for i, d := range dentists {
+		ops[i] = store.BatchOp{Entity: d}
+	}
+	_, err := w.s.Batch(store.DE, ops)
+	return err
+}
+
+func (w *storeWriter) Patients(patients []domain.Patient) error {
+	ops := make([]store.BatchOp, len(patients))
+	for i, p := range patients {
+		ops[i] = store.BatchOp{Entity: p}
+	}
+	_, err := w.s.Batch(store.PE, ops)
+	return err
+}
+
+func (w *storeWriter) Appointments(appointments []domain.Appointment) error {
+	ops := make([]store.BatchOp, len(appointments))
+	for i, a := range appointments {
+		ops[i] = store.BatchOp{Entity: a}
+	}
+	_, err := w.s.Batch(store.AP, ops)
+	return err
+}
@@ -0,0 +1,44 @@
+package selfservice
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetByNonce retorna um link emitido
+	GetByNonce(nonce string) (domain.AppointmentLink, error)
+	// Create registra um link emitido
+	Create(l domain.AppointmentLink, expiresAt time.Time) error
+	// Use marca o link como usado, falhando quando ele já foi usado
+	Use(l domain.AppointmentLink, usedAt time.Time) error
+	// Release desfaz o uso de um link
+	Release(nonce string) error
+}
+
+type repository struct {
+	store store.LinkStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.LinkStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetByNonce(nonce string) (domain.AppointmentLink, error) {
+	return r.store.GetLink(nonce)
+}
+
+func (r *repository) Create(l domain.AppointmentLink, expiresAt time.Time) error {
+	return r.store.SaveLink(l, expiresAt)
+}
+
+func (r *repository) Use(l domain.AppointmentLink, usedAt time.Time) error {
+	return r.store.UseLink(l, usedAt)
+}
+
+func (r *repository) Release(nonce string) error {
+	return r.store.ReleaseLink(nonce)
+}
@@ -0,0 +1,207 @@
+package selfservice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// PublicPath é o caminho público onde os links são abertos, seguido da ação
+const PublicPath = "/public/appointments/"
+
+// maxUserAgent é o tamanho da coluna que registra o navegador usado para abrir o link
+const maxUserAgent = 255
+
+var (
+	// ErrInvalidLink indica que o token foi adulterado, não existe ou não corresponde à ação
+	ErrInvalidLink = errors.New("invalid link")
+	// ErrExpiredLink indica que o link passou da validade
+	ErrExpiredLink = errors.New("link has expired")
+	// ErrUsedLink indica que o link já foi usado
+	ErrUsedLink = errors.New("link has already been used")
+	// ErrNotAllowed indica que a situação atual da consulta não permite a ação
+	ErrNotAllowed = errors.New("appointment can no longer be changed by this link")
+)
+
+// claims é o conteúdo assinado do token embutido no link
+type claims struct {
+	Nonce         string `json:"jti"`
+	AppointmentID int    `json:"aid"`
+	Action        string `json:"act"`
+	ExpiresAt     int64  `json:"exp"`
+}
+
+type Service interface {
+	// Issue emite os links de confirmação e cancelamento de uma consulta, válidos até o seu início
+	Issue(appointmentID int) (domain.AppointmentLinks, error)
+	// Preview valida o token e mostra a consulta e a ação, sem usar o link
+	Preview(token, action string) (domain.LinkPreview, error)
+	// Use aplica a ação do link à consulta e invalida o link, registrando o acesso. Se a consulta
+	// for alterada durante o uso, retorna appointment.ErrVersionMismatch e o link continua válido.
+	Use(token, action, ip, userAgent string) (domain.LinkPreview, error)
+}
+
+type service struct {
+	r            Repository
+	signer       *auth.Signer
+	appointments appointment.Service
+	location     *time.Location
+	baseURL      string
+}
+
+// NewService cria um novo serviço. O signer deve usar uma chave própria para os links, de modo
+// que um token de link nunca seja aceito como token de acesso à API. Os links valem até o início
+// da consulta, lido no fuso da clínica informado em location.
+func NewService(r Repository, signer *auth.Signer, appointments appointment.Service, location *time.Location, baseURL string) Service {
+	return &service{r, signer, appointments, location, strings.TrimRight(baseURL, "/")}
+}
+
+func (s *service) Issue(appointmentID int) (domain.AppointmentLinks, error) {
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil || a.Id == 0 {
+		return domain.AppointmentLinks{}, ErrInvalidLink
+	}
+	if !allowed(domain.LinkCancel, a.Status) {
+		return domain.AppointmentLinks{}, ErrNotAllowed
+	}
+	start, err := time.ParseInLocation(appointment.DateLayout, a.AppointmentDate, s.location)
+	if err != nil {
+		return domain.AppointmentLinks{}, err
+	}
+	if !start.After(time.Now()) {
+		return domain.AppointmentLinks{}, ErrNotAllowed
+	}
+
+	links := domain.AppointmentLinks{IdAppointment: a.Id, ExpiresAt: start.Format(appointment.DateLayout)}
+	for _, action := range []string{domain.LinkConfirm, domain.LinkCancel} {
+		nonce, err := newNonce()
+		if err != nil {
+			return domain.AppointmentLinks{}, err
+		}
+		link := domain.AppointmentLink{Nonce: nonce, IdAppointment: a.Id, Action: action}
+		if err := s.r.Create(link, start); err != nil {
+			return domain.AppointmentLinks{}, err
+		}
+		token, err := s.signer.Sign(claims{Nonce: nonce, AppointmentID: a.Id, Action: action, ExpiresAt: start.Unix()})
+		if err != nil {
+			return domain.AppointmentLinks{}, err
+		}
+		address := s.baseURL + PublicPath + action + "?token=" + url.QueryEscape(token)
+		if action == domain.LinkConfirm {
+			links.Confirm = address
+		} else {
+			links.Cancel = address
+		}
+	}
+	return links, nil
+}
+
+func (s *service) Preview(token, action string) (domain.LinkPreview, error) {
+	link, a, err := s.verify(token, action)
+	if err != nil {
+		return domain.LinkPreview{}, err
+	}
+	return preview(link, a), nil
+}
+
+func (s *service) Use(token, action, ip, userAgent string) (domain.LinkPreview, error) {
+	link, a, err := s.verify(token, action)
+	if err != nil {
+		return domain.LinkPreview{}, err
+	}
+	if link.UsedAt != "" {
+		return domain.LinkPreview{}, ErrUsedLink
+	}
+	if !allowed(action, a.Status) {
+		return domain.LinkPreview{}, ErrNotAllowed
+	}
+
+	if len(userAgent) > maxUserAgent {
+		userAgent = userAgent[:maxUserAgent]
+	}
+	link.UsedIP = ip
+	link.UsedUserAgent = userAgent
+	link.PreviousStatus = a.Status
+	link.NewStatus = domain.StatusConfirmed
+	if action == domain.LinkCancel {
+		link.NewStatus = domain.StatusCancelled
+	}
+	// O link é marcado como usado antes de alterar a consulta para que dois acessos
+	// simultâneos não apliquem a ação duas vezes
+	if err := s.r.Use(link, time.Now()); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.LinkPreview{}, ErrUsedLink
+		}
+		return domain.LinkPreview{}, err
+	}
+	// a versão lida em verify garante que a situação conferida por allowed ainda é a atual; se a
+	// consulta mudou nesse meio tempo, o link é liberado e Update retorna ErrVersionMismatch
+	if _, err := s.appointments.Update(a.Id, domain.Appointment{Status: link.NewStatus, Version: a.Version}); err != nil {
+		s.r.Release(link.Nonce)
+		return domain.LinkPreview{}, err
+	}
+	return s.Preview(token, action)
+}
+
+// verify confere a assinatura, a validade e a ação do token e carrega o link e a consulta
+func (s *service) verify(token, action string) (domain.AppointmentLink, domain.AppointmentDTO, error) {
+	var c claims
+	if err := s.signer.Verify(token, &c); err != nil || c.Action != action {
+		return domain.AppointmentLink{}, domain.AppointmentDTO{}, ErrInvalidLink
+	}
+	if time.Now().Unix() > c.ExpiresAt {
+		return domain.AppointmentLink{}, domain.AppointmentDTO{}, ErrExpiredLink
+	}
+	link, err := s.r.GetByNonce(c.Nonce)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && (link.IdAppointment != c.AppointmentID || link.Action != c.Action)) {
+		return domain.AppointmentLink{}, domain.AppointmentDTO{}, ErrInvalidLink
+	}
+	if err != nil {
+		return domain.AppointmentLink{}, domain.AppointmentDTO{}, err
+	}
+	a, err := s.appointments.GetByID(link.IdAppointment)
+	if err != nil || a.Id == 0 {
+		return domain.AppointmentLink{}, domain.AppointmentDTO{}, ErrInvalidLink
+	}
+	return link, a, nil
+}
+
+// allowed indica se a situação da consulta permite a ação: só consultas agendadas podem ser
+// confirmadas, e consultas agendadas ou confirmadas podem ser canceladas
+func allowed(action, status string) bool {
+	switch action {
+	case domain.LinkConfirm:
+		return status == domain.StatusScheduled
+	case domain.LinkCancel:
+		return status == domain.StatusScheduled || status == domain.StatusConfirmed
+	}
+	return false
+}
+
+func preview(link domain.AppointmentLink, a domain.AppointmentDTO) domain.LinkPreview {
+	return domain.LinkPreview{
+		Action:          link.Action,
+		IdAppointment:   a.Id,
+		AppointmentDate: a.AppointmentDate,
+		Dentist:         strings.TrimSpace(a.Dentist.Name + " " + a.Dentist.Surname),
+		Status:          a.Status,
+		ExpiresAt:       link.ExpiresAt,
+		UsedAt:          link.UsedAt,
+	}
+}
+
+func newNonce() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
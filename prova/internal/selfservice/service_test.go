@@ -0,0 +1,274 @@
+package selfservice
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// clinic é o fuso da clínica nos testes, três horas atrás de UTC
+var clinic = time.FixedZone("BRT", -3*60*60)
+
+// fakeRepository guarda os links emitidos em memória
+type fakeRepository struct {
+	links   map[string]domain.AppointmentLink
+	expires map[string]time.Time
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{links: map[string]domain.AppointmentLink{}, expires: map[string]time.Time{}}
+}
+
+func (r *fakeRepository) GetByNonce(nonce string) (domain.AppointmentLink, error) {
+	l, ok := r.links[nonce]
+	if !ok {
+		return domain.AppointmentLink{}, store.ErrNotFound
+	}
+	return l, nil
+}
+
+func (r *fakeRepository) Create(l domain.AppointmentLink, expiresAt time.Time) error {
+	l.ExpiresAt = expiresAt.Format(appointment.DateLayout)
+	r.links[l.Nonce] = l
+	r.expires[l.Nonce] = expiresAt
+	return nil
+}
+
+func (r *fakeRepository) Use(l domain.AppointmentLink, usedAt time.Time) error {
+	if r.links[l.Nonce].UsedAt != "" {
+		return store.ErrNotFound
+	}
+	l.UsedAt = usedAt.Format(appointment.DateLayout)
+	r.links[l.Nonce] = l
+	return nil
+}
+
+func (r *fakeRepository) Release(nonce string) error {
+	l := r.links[nonce]
+	l.UsedAt = ""
+	r.links[nonce] = l
+	return nil
+}
+
+// fakeAppointments guarda uma consulta e confere as versões como o serviço real
+type fakeAppointments struct {
+	appointment.Service
+	current domain.AppointmentDTO
+	// concurrent, se definido, altera a consulta logo antes da próxima atualização, como outra
+	// requisição que chega entre a leitura e a gravação
+	concurrent func(a *domain.AppointmentDTO)
+}
+
+func (f *fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	if id != f.current.Id {
+		return domain.AppointmentDTO{}, store.ErrNotFound
+	}
+	return f.current, nil
+}
+
+func (f *fakeAppointments) Update(id int, a domain.Appointment) (domain.AppointmentDTO, error) {
+	if f.concurrent != nil {
+		f.concurrent(&f.current)
+		f.current.Version++
+		f.concurrent = nil
+	}
+	if id != f.current.Id {
+		return domain.AppointmentDTO{}, store.ErrNotFound
+	}
+	if a.Version != 0 && a.Version != f.current.Version {
+		return domain.AppointmentDTO{}, appointment.ErrVersionMismatch
+	}
+	f.current.Status = a.Status
+	f.current.Version++
+	return f.current, nil
+}
+
+// startingIn retorna a data da consulta daqui a until, escrita no horário da clínica
+func startingIn(until time.Duration) string {
+	return time.Now().Add(until).In(clinic).Format(appointment.DateLayout)
+}
+
+func newTestService(date string) (Service, *fakeRepository, *fakeAppointments) {
+	appointments := &fakeAppointments{current: domain.AppointmentDTO{Appointment: domain.Appointment{
+		Id:              3,
+		AppointmentDate: date,
+		Status:          domain.StatusScheduled,
+		Version:         2,
+	}}}
+	r := newFakeRepository()
+	return NewService(r, auth.NewSigner("links"), appointments, clinic, "http://clinic.test/"), r, appointments
+}
+
+// token extrai o token de um link emitido
+func token(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse %s: %v", link, err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestIssueExpiresAtTheStartInTheClinicTimezone(t *testing.T) {
+	date := startingIn(2 * time.Hour)
+	s, r, _ := newTestService(date)
+
+	links, err := s.Issue(3)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if links.ExpiresAt != date {
+		t.Errorf("expires at %s, want the appointment start %s", links.ExpiresAt, date)
+	}
+	start, _ := time.ParseInLocation(appointment.DateLayout, date, clinic)
+	for nonce, expiresAt := range r.expires {
+		if !expiresAt.Equal(start) {
+			t.Errorf("link %s expires at %s, want %s", nonce, expiresAt, start)
+		}
+	}
+	if _, err := s.Preview(token(t, links.Confirm), domain.LinkConfirm); err != nil {
+		t.Errorf("Preview: %v", err)
+	}
+
+	// uma consulta que já começou no horário da clínica não recebe links
+	s, _, _ = newTestService(startingIn(-time.Hour))
+	if _, err := s.Issue(3); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Issue of a past appointment = %v, want ErrNotAllowed", err)
+	}
+}
+
+func TestUse(t *testing.T) {
+	s, r, appointments := newTestService(startingIn(2 * time.Hour))
+	links, err := s.Issue(3)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	confirmed, err := s.Use(token(t, links.Confirm), domain.LinkConfirm, "203.0.113.7", "Mozilla/5.0")
+	if err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	if confirmed.Status != domain.StatusConfirmed || confirmed.UsedAt == "" {
+		t.Errorf("preview = %+v, want the appointment confirmed and the link used", confirmed)
+	}
+	if appointments.current.Version != 3 {
+		t.Errorf("version = %d, want 3", appointments.current.Version)
+	}
+	for _, l := range r.links {
+		if l.Action == domain.LinkConfirm && (l.PreviousStatus != domain.StatusScheduled || l.UsedIP != "203.0.113.7") {
+			t.Errorf("link = %+v, want the access recorded", l)
+		}
+	}
+
+	_, err = s.Use(token(t, links.Confirm), domain.LinkConfirm, "", "")
+	wantErr(t, "Use of a used link", err, ErrUsedLink)
+	_, err = s.Use(token(t, links.Cancel), domain.LinkConfirm, "", "")
+	wantErr(t, "Use of the cancel link to confirm", err, ErrInvalidLink)
+
+	// a consulta confirmada ainda pode ser cancelada
+	if _, err := s.Use(token(t, links.Cancel), domain.LinkCancel, "", ""); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	if appointments.current.Status != domain.StatusCancelled {
+		t.Errorf("status = %s, want cancelled", appointments.current.Status)
+	}
+}
+
+func TestUseRejectsExpiredLinks(t *testing.T) {
+	s, r, appointments := newTestService(startingIn(2 * time.Hour))
+	if err := r.Create(domain.AppointmentLink{Nonce: "old", IdAppointment: 3, Action: domain.LinkConfirm}, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	expired, err := auth.NewSigner("links").Sign(claims{Nonce: "old", AppointmentID: 3, Action: domain.LinkConfirm, ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	_, err = s.Use(expired, domain.LinkConfirm, "", "")
+	wantErr(t, "Use of an expired link", err, ErrExpiredLink)
+	_, err = s.Preview(expired, domain.LinkConfirm)
+	wantErr(t, "Preview of an expired link", err, ErrExpiredLink)
+	if appointments.current.Status != domain.StatusScheduled || r.links["old"].UsedAt != "" {
+		t.Errorf("the expired link changed the appointment or was marked as used")
+	}
+
+	// o token assinado com outra chave não é aceito
+	forged, _ := auth.NewSigner("api").Sign(claims{Nonce: "old", AppointmentID: 3, Action: domain.LinkConfirm, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	_, err = s.Use(forged, domain.LinkConfirm, "", "")
+	wantErr(t, "Use of a forged link", err, ErrInvalidLink)
+}
+
+func TestUseRejectsDisallowedActions(t *testing.T) {
+	tests := []struct {
+		status string
+		action string
+	}{
+		{domain.StatusConfirmed, domain.LinkConfirm},
+		{domain.StatusCheckedIn, domain.LinkConfirm},
+		{domain.StatusCheckedIn, domain.LinkCancel},
+		{domain.StatusCompleted, domain.LinkCancel},
+		{domain.StatusCancelled, domain.LinkCancel},
+		{domain.StatusNoShow, domain.LinkConfirm},
+	}
+	for _, tt := range tests {
+		s, r, appointments := newTestService(startingIn(2 * time.Hour))
+		links, err := s.Issue(3)
+		if err != nil {
+			t.Fatalf("Issue: %v", err)
+		}
+		appointments.current.Status = tt.status
+		link := links.Confirm
+		if tt.action == domain.LinkCancel {
+			link = links.Cancel
+		}
+
+		_, err = s.Use(token(t, link), tt.action, "", "")
+		wantErr(t, tt.action+" of a "+tt.status+" appointment", err, ErrNotAllowed)
+		if appointments.current.Status != tt.status || appointments.current.Version != 2 {
+			t.Errorf("%s of a %s appointment changed it to %+v", tt.action, tt.status, appointments.current.Appointment)
+		}
+		for _, l := range r.links {
+			if l.UsedAt != "" {
+				t.Errorf("%s of a %s appointment marked the link as used", tt.action, tt.status)
+			}
+		}
+	}
+}
+
+func TestUseKeepsAConcurrentChange(t *testing.T) {
+	s, r, appointments := newTestService(startingIn(2 * time.Hour))
+	links, err := s.Issue(3)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// a recepção cancela a consulta depois de o link conferir que ela estava agendada
+	appointments.concurrent = func(a *domain.AppointmentDTO) { a.Status = domain.StatusCancelled }
+	_, err = s.Use(token(t, links.Confirm), domain.LinkConfirm, "", "")
+	wantErr(t, "Use during a concurrent change", err, appointment.ErrVersionMismatch)
+	if appointments.current.Status != domain.StatusCancelled {
+		t.Errorf("status = %s, want the cancellation kept", appointments.current.Status)
+	}
+	for _, l := range r.links {
+		if l.UsedAt != "" {
+			t.Errorf("link %s was not released", l.Action)
+		}
+	}
+
+	// ao tentar de novo, o link vê a situação atual
+	_, err = s.Use(token(t, links.Confirm), domain.LinkConfirm, "", "")
+	wantErr(t, "Use after the cancellation", err, ErrNotAllowed)
+}
+
+func wantErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s = %v, want %v", what, err, want)
+	}
+}
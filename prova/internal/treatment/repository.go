@@ -0,0 +1,68 @@
+package treatment
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetByID retorna um plano de tratamento por id
+	GetByID(id int) (domain.TreatmentPlan, error)
+	// GetByPatient retorna os planos de tratamento de um paciente
+	GetByPatient(patientID int) ([]domain.TreatmentPlan, error)
+	// Create insere um novo plano de tratamento
+	Create(p domain.TreatmentPlan) (domain.TreatmentPlan, error)
+	// Update substitui os dados de um plano em rascunho
+	Update(p domain.TreatmentPlan) error
+	// UpdateStatus altera a situação de um plano
+	UpdateStatus(id int, status string, acceptedAt time.Time) error
+	// AdvanceStatus altera a situação de um plano somente se ela ainda for from
+	AdvanceStatus(id int, from, to string) error
+	// ScheduleItem vincula um procedimento do plano a uma consulta
+	ScheduleItem(planID, itemID, appointmentID int) error
+	// Delete exclui um plano de tratamento
+	Delete(id int) error
+}
+
+type repository struct {
+	store store.PlanStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.PlanStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetByID(id int) (domain.TreatmentPlan, error) {
+	return r.store.GetPlanByID(id)
+}
+
+func (r *repository) GetByPatient(patientID int) ([]domain.TreatmentPlan, error) {
+	return r.store.GetPlansByPatient(patientID)
+}
+
+func (r *repository) Create(p domain.TreatmentPlan) (domain.TreatmentPlan, error) {
+	return r.store.SavePlan(p)
+}
+
+func (r *repository) Update(p domain.TreatmentPlan) error {
+	return r.store.UpdatePlan(p)
+}
+
+func (r *repository) UpdateStatus(id int, status string, acceptedAt time.Time) error {
+	return r.store.UpdatePlanStatus(id, status, acceptedAt)
+}
+
+func (r *repository) AdvanceStatus(id int, from, to string) error {
+	return r.store.AdvancePlanStatus(id, from, to)
+}
+
+func (r *repository) ScheduleItem(planID, itemID, appointmentID int) error {
+	return r.store.UpdatePlanItemAppointment(planID, itemID, appointmentID)
+}
+
+func (r *repository) Delete(id int) error {
+	return r.store.DeletePlan(id)
+}
@@ -0,0 +1,351 @@
+package treatment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/chart"
+	"github.com/meirafa/prova2-golang/internal/dentist"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/patient"
+	"github.com/meirafa/prova2-golang/internal/procedure"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+var (
+	// ErrNotFound indica que o plano, o procedimento do plano ou o paciente não existe
+	ErrNotFound = errors.New("treatment plan not found")
+	// ErrInvalidPlan indica que os dados do plano são inválidos
+	ErrInvalidPlan = errors.New("invalid treatment plan")
+	// ErrNotEditable indica que o plano já foi aceito ou cancelado e não pode mais ser alterado
+	ErrNotEditable = errors.New("only draft treatment plans can be changed")
+	// ErrNotAccepted indica que os procedimentos só podem ser agendados após o aceite do paciente
+	ErrNotAccepted = errors.New("treatment plan must be accepted by the patient before scheduling")
+	// ErrInUse indica que o plano é referenciado por pedidos de autorização e não pode ser excluído
+	ErrInUse = errors.New("treatment plan is referenced by pre-authorizations and can't be deleted")
+)
+
+type Service interface {
+	// GetByID retorna um plano de tratamento com o andamento de cada procedimento
+	GetByID(id int) (domain.TreatmentPlan, error)
+	// GetByPatient retorna os planos de tratamento de um paciente
+	GetByPatient(patientID int) ([]domain.TreatmentPlan, error)
+	// Create cria um novo plano de tratamento em rascunho
+	Create(patientID int, p domain.TreatmentPlan) (domain.TreatmentPlan, error)
+	// Update substitui os dados de um plano em rascunho
+	Update(id int, p domain.TreatmentPlan) (domain.TreatmentPlan, error)
+	// Delete exclui um plano de tratamento ainda em rascunho
+	Delete(id int) error
+	// Accept registra o aceite do plano pelo paciente
+	Accept(id int) (domain.TreatmentPlan, error)
+	// Cancel cancela um plano de tratamento
+	Cancel(id int) (domain.TreatmentPlan, error)
+	// ScheduleItem vincula um procedimento do plano a uma consulta do paciente
+	ScheduleItem(planID, itemID, appointmentID int) (domain.TreatmentPlan, error)
+	// Progress retorna o resumo do andamento do plano
+	Progress(id int) (domain.PlanProgress, error)
+}
+
+type service struct {
+	r            Repository
+	procedures   procedure.Service
+	appointments appointment.Service
+	patients     patient.Service
+	dentists     dentist.Service
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, procedures procedure.Service, appointments appointment.Service, patients patient.Service, dentists dentist.Service) Service {
+	return &service{r, procedures, appointments, patients, dentists}
+}
+
+func (s *service) GetByID(id int) (domain.TreatmentPlan, error) {
+	p, err := s.r.GetByID(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.TreatmentPlan{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return s.withProgress(p)
+}
+
+func (s *service) GetByPatient(patientID int) ([]domain.TreatmentPlan, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return nil, ErrNotFound
+	}
+	plans, err := s.r.GetByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.progress(plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (s *service) Create(patientID int, p domain.TreatmentPlan) (domain.TreatmentPlan, error) {
+	if _, err := s.patients.GetByID(patientID); err != nil {
+		return domain.TreatmentPlan{}, ErrNotFound
+	}
+	if err := s.prepare(&p); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	p.IdPatient = patientID
+	p.Status = domain.PlanDraft
+
+	saved, err := s.r.Create(p)
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return s.withProgress(saved)
+}
+
+func (s *service) Update(id int, p domain.TreatmentPlan) (domain.TreatmentPlan, error) {
+	pdb, err := s.GetByID(id)
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if pdb.Status != domain.PlanDraft {
+		return domain.TreatmentPlan{}, ErrNotEditable
+	}
+	if err := s.prepare(&p); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	p.Id = id
+
+	if err := s.r.Update(p); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.TreatmentPlan{}, ErrNotEditable
+		}
+		return domain.TreatmentPlan{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) Delete(id int) error {
+	pdb, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if pdb.Status != domain.PlanDraft {
+		return ErrNotEditable
+	}
+	err = s.r.Delete(id)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		// o plano deixou de ser rascunho depois da leitura
+		return ErrNotEditable
+	case errors.Is(err, store.ErrReferenced):
+		return ErrInUse
+	}
+	return err
+}
+
+func (s *service) Accept(id int) (domain.TreatmentPlan, error) {
+	p, err := s.GetByID(id)
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if p.Status != domain.PlanDraft {
+		return domain.TreatmentPlan{}, ErrNotEditable
+	}
+	if err := s.r.UpdateStatus(id, domain.PlanAccepted, time.Now()); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) Cancel(id int) (domain.TreatmentPlan, error) {
+	p, err := s.GetByID(id)
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if p.Status == domain.PlanCancelled || p.Status == domain.PlanCompleted {
+		return domain.TreatmentPlan{}, ErrNotEditable
+	}
+	if err := s.r.UpdateStatus(id, domain.PlanCancelled, time.Time{}); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) ScheduleItem(planID, itemID, appointmentID int) (domain.TreatmentPlan, error) {
+	p, err := s.GetByID(planID)
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if p.Status != domain.PlanAccepted && p.Status != domain.PlanInProgress {
+		return domain.TreatmentPlan{}, ErrNotAccepted
+	}
+	a, err := s.appointments.GetByID(appointmentID)
+	if err != nil || a.Id == 0 {
+		return domain.TreatmentPlan{}, fmt.Errorf("%w: appointment %d not found", ErrInvalidPlan, appointmentID)
+	}
+	if a.Patient.Id != p.IdPatient {
+		return domain.TreatmentPlan{}, fmt.Errorf("%w: appointment %d belongs to another patient", ErrInvalidPlan, appointmentID)
+	}
+
+	if err := s.r.ScheduleItem(planID, itemID, appointmentID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.TreatmentPlan{}, ErrNotFound
+		}
+		return domain.TreatmentPlan{}, err
+	}
+	return s.GetByID(planID)
+}
+
+func (s *service) Progress(id int) (domain.PlanProgress, error) {
+	p, err := s.GetByID(id)
+	if err != nil {
+		return domain.PlanProgress{}, err
+	}
+
+	progress := domain.PlanProgress{IdPlan: p.Id, Status: p.Status, TotalItems: len(p.Items)}
+	for _, item := range p.Items {
+		progress.TotalPrice += item.Price
+		switch item.Status {
+		case domain.ItemDone:
+			progress.DoneItems++
+			progress.DonePrice += item.Price
+		case domain.ItemScheduled:
+			progress.ScheduledItems++
+		default:
+			progress.PendingItems++
+		}
+	}
+	progress.TotalPrice = roundCents(progress.TotalPrice)
+	progress.DonePrice = roundCents(progress.DonePrice)
+	if progress.TotalItems > 0 {
+		progress.Percent = math.Round(float64(progress.DoneItems)/float64(progress.TotalItems)*10000) / 100
+	}
+	return progress, nil
+}
+
+// prepare valida o plano e preenche nome, preço e posição de cada procedimento a partir do catálogo
+func (s *service) prepare(p *domain.TreatmentPlan) error {
+	if strings.TrimSpace(p.Title) == "" || len(p.Items) == 0 {
+		return fmt.Errorf("%w: title and at least one item are required", ErrInvalidPlan)
+	}
+	if _, err := s.dentists.GetByID(p.IdDentist); err != nil {
+		return fmt.Errorf("%w: dentist %d not found", ErrInvalidPlan, p.IdDentist)
+	}
+
+	for i := range p.Items {
+		item := &p.Items[i]
+		proc, err := s.procedures.GetByCode(item.ProcedureCode)
+		if errors.Is(err, procedure.ErrNotFound) {
+			return fmt.Errorf("%w: procedure %q not found in catalog", ErrInvalidPlan, item.ProcedureCode)
+		}
+		if err != nil {
+			return err
+		}
+		if item.Tooth != 0 && !chart.IsValidTooth(item.Tooth) {
+			return fmt.Errorf("%w: tooth %d is not a valid FDI number", ErrInvalidPlan, item.Tooth)
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("%w: price can't be negative", ErrInvalidPlan)
+		}
+		if item.Price == 0 {
+			item.Price = proc.Price
+		}
+		item.Price = roundCents(item.Price)
+		item.ProcedureName = proc.Name
+		item.Position = i + 1
+		item.IdAppointment = 0
+	}
+	return nil
+}
+
+// withProgress calcula o andamento de um único plano
+func (s *service) withProgress(p domain.TreatmentPlan) (domain.TreatmentPlan, error) {
+	plans := []domain.TreatmentPlan{p}
+	if err := s.progress(plans); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return plans[0], nil
+}
+
+// progress calcula a situação de cada procedimento pela consulta vinculada a ele, buscando as
+// consultas de todos os planos de uma só vez: o procedimento é concluído quando a consulta é
+// concluída. A situação do plano aceito passa a em andamento ou concluído conforme os
+// procedimentos avançam, e a mudança é gravada para que as listagens vejam a mesma situação.
+func (s *service) progress(plans []domain.TreatmentPlan) error {
+	var ids []int
+	for _, p := range plans {
+		for _, item := range p.Items {
+			if item.IdAppointment != 0 {
+				ids = append(ids, item.IdAppointment)
+			}
+		}
+	}
+	statuses := make(map[int]string, len(ids))
+	if len(ids) > 0 {
+		found, err := s.appointments.GetByIDs(ids)
+		if err != nil {
+			return err
+		}
+		for _, a := range found {
+			statuses[a.Id] = a.Status
+		}
+	}
+
+	for i := range plans {
+		p := &plans[i]
+		done, started := 0, false
+		for j := range p.Items {
+			item := &p.Items[j]
+			item.Status = domain.ItemPending
+			status, ok := statuses[item.IdAppointment]
+			if !ok {
+				continue
+			}
+			switch status {
+			case domain.StatusCompleted:
+				item.Status = domain.ItemDone
+				done++
+				started = true
+			case domain.StatusCancelled, domain.StatusNoShow:
+			default:
+				item.Status = domain.ItemScheduled
+				started = true
+			}
+		}
+
+		next := p.Status
+		switch {
+		case p.Status != domain.PlanAccepted && p.Status != domain.PlanInProgress:
+		case len(p.Items) > 0 && done == len(p.Items):
+			next = domain.PlanCompleted
+		case started:
+			next = domain.PlanInProgress
+		}
+		if next == p.Status {
+			continue
+		}
+		err := s.r.AdvanceStatus(p.Id, p.Status, next)
+		if errors.Is(err, store.ErrNotFound) {
+			// o plano mudou de situação depois da leitura, por exemplo com um cancelamento; vale
+			// a situação gravada
+			current, err := s.r.GetByID(p.Id)
+			if err != nil {
+				return err
+			}
+			p.Status = current.Status
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		p.Status = next
+	}
+	return nil
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,204 @@
+package treatment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+// fakeRepository guarda os planos em memória; os métodos não usados pelos testes ficam com a
+// interface nula
+type fakeRepository struct {
+	Repository
+	plans map[int]domain.TreatmentPlan
+}
+
+func (r *fakeRepository) GetByID(id int) (domain.TreatmentPlan, error) {
+	p, ok := r.plans[id]
+	if !ok {
+		return domain.TreatmentPlan{}, store.ErrNotFound
+	}
+	// devolve uma cópia dos procedimentos, como uma nova leitura do banco
+	p.Items = append([]domain.TreatmentPlanItem(nil), p.Items...)
+	return p, nil
+}
+
+func (r *fakeRepository) UpdateStatus(id int, status string, acceptedAt time.Time) error {
+	p, ok := r.plans[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	p.Status = status
+	if !acceptedAt.IsZero() {
+		p.AcceptedAt = acceptedAt.Format("02/01/2006 15:04")
+	}
+	r.plans[id] = p
+	return nil
+}
+
+func (r *fakeRepository) AdvanceStatus(id int, from, to string) error {
+	p, ok := r.plans[id]
+	if !ok || p.Status != from {
+		return store.ErrNotFound
+	}
+	p.Status = to
+	r.plans[id] = p
+	return nil
+}
+
+// fakeAppointments guarda a situação de cada consulta e conta as buscas
+type fakeAppointments struct {
+	appointment.Service
+	statuses map[int]string
+	err      error
+	calls    int
+	// onFetch roda a cada busca, para simular uma alteração concorrente
+	onFetch func()
+}
+
+func (f *fakeAppointments) GetByIDs(ids []int) ([]domain.AppointmentDTO, error) {
+	f.calls++
+	if f.onFetch != nil {
+		f.onFetch()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	var list []domain.AppointmentDTO
+	for _, id := range ids {
+		if status, ok := f.statuses[id]; ok {
+			list = append(list, domain.AppointmentDTO{Appointment: domain.Appointment{Id: id, Status: status}})
+		}
+	}
+	return list, nil
+}
+
+func plan(status string, appointments ...int) domain.TreatmentPlan {
+	p := domain.TreatmentPlan{Id: 1, IdPatient: 1, IdDentist: 7, Title: "reabilitação", Status: status}
+	for i, id := range appointments {
+		p.Items = append(p.Items, domain.TreatmentPlanItem{Id: i + 1, Position: i + 1, ProcedureCode: "P1", Price: 100, IdAppointment: id})
+	}
+	return p
+}
+
+func newTestService(p domain.TreatmentPlan, statuses map[int]string) (Service, *fakeRepository, *fakeAppointments) {
+	r := &fakeRepository{plans: map[int]domain.TreatmentPlan{p.Id: p}}
+	appointments := &fakeAppointments{statuses: statuses}
+	return NewService(r, nil, appointments, nil, nil), r, appointments
+}
+
+func TestProgress(t *testing.T) {
+	s, r, appointments := newTestService(plan(domain.PlanAccepted, 10, 11, 12, 0), map[int]string{
+		10: domain.StatusCompleted,
+		11: domain.StatusScheduled,
+		12: domain.StatusCancelled,
+	})
+
+	progress, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	want := domain.PlanProgress{IdPlan: 1, Status: domain.PlanInProgress, TotalItems: 4, PendingItems: 2, ScheduledItems: 1, DoneItems: 1, TotalPrice: 400, DonePrice: 100, Percent: 25}
+	if progress != want {
+		t.Errorf("progress = %+v, want %+v", progress, want)
+	}
+	if appointments.calls != 1 {
+		t.Errorf("got %d appointment lookups, want a single one", appointments.calls)
+	}
+	// a situação calculada é gravada, para que as listagens e os filtros concordem com o andamento
+	if got := r.plans[1].Status; got != domain.PlanInProgress {
+		t.Errorf("stored status = %s, want %s", got, domain.PlanInProgress)
+	}
+
+	appointments.statuses = map[int]string{10: domain.StatusCompleted, 11: domain.StatusCompleted, 12: domain.StatusCompleted}
+	p := r.plans[1]
+	p.Items[3].IdAppointment = 13
+	appointments.statuses[13] = domain.StatusCompleted
+	r.plans[1] = p
+	got, err := s.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != domain.PlanCompleted || r.plans[1].Status != domain.PlanCompleted {
+		t.Errorf("status = %s, stored %s, want completed", got.Status, r.plans[1].Status)
+	}
+}
+
+func TestProgressKeepsTheStatusOfPlansNotAccepted(t *testing.T) {
+	for _, status := range []string{domain.PlanDraft, domain.PlanCancelled} {
+		s, r, _ := newTestService(plan(status, 10), map[int]string{10: domain.StatusCompleted})
+		got, err := s.GetByID(1)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != status || r.plans[1].Status != status {
+			t.Errorf("%s plan became %s, stored %s", status, got.Status, r.plans[1].Status)
+		}
+		if got.Items[0].Status != domain.ItemDone {
+			t.Errorf("%s plan item = %s, want done", status, got.Items[0].Status)
+		}
+	}
+}
+
+func TestProgressReturnsTheLookupError(t *testing.T) {
+	s, _, appointments := newTestService(plan(domain.PlanAccepted, 10), nil)
+	appointments.err = errors.New("connection refused")
+
+	if _, err := s.Progress(1); !errors.Is(err, appointments.err) {
+		t.Errorf("Progress = %v, want the lookup error", err)
+	}
+}
+
+func TestProgressKeepsAConcurrentCancellation(t *testing.T) {
+	s, r, appointments := newTestService(plan(domain.PlanAccepted, 10), map[int]string{10: domain.StatusScheduled})
+	appointments.onFetch = func() { r.UpdateStatus(1, domain.PlanCancelled, time.Time{}) }
+
+	got, err := s.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != domain.PlanCancelled || r.plans[1].Status != domain.PlanCancelled {
+		t.Errorf("status = %s, stored %s, want the cancellation kept", got.Status, r.plans[1].Status)
+	}
+}
+
+func TestAcceptAndCancel(t *testing.T) {
+	s, r, _ := newTestService(plan(domain.PlanDraft, 0), nil)
+
+	accepted, err := s.Accept(1)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if accepted.Status != domain.PlanAccepted || accepted.AcceptedAt == "" {
+		t.Errorf("accepted = %+v, want accepted with the acceptance date", accepted)
+	}
+	if _, err := s.Accept(1); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("Accept twice = %v, want ErrNotEditable", err)
+	}
+
+	cancelled, err := s.Cancel(1)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancelled.Status != domain.PlanCancelled {
+		t.Errorf("cancelled = %+v, want cancelled", cancelled)
+	}
+	if _, err := s.Cancel(1); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("Cancel twice = %v, want ErrNotEditable", err)
+	}
+	if _, err := s.Accept(1); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("Accept after cancelling = %v, want ErrNotEditable", err)
+	}
+
+	r.plans[1] = plan(domain.PlanCompleted, 0)
+	if _, err := s.Cancel(1); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("Cancel of a completed plan = %v, want ErrNotEditable", err)
+	}
+	if _, err := s.Cancel(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel of a missing plan = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,36 @@
+package user
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetByUsername retorna um usuário pelo login
+	GetByUsername(username string) (domain.User, error)
+	// Create insere um novo usuário
+	Create(u domain.User) (domain.User, error)
+	// UpdatePassword substitui o hash da senha de um usuário
+	UpdatePassword(username, passwordHash string) error
+}
+
+type repository struct {
+	store store.UserStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.UserStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetByUsername(username string) (domain.User, error) {
+	return r.store.GetUserByUsername(username)
+}
+
+func (r *repository) Create(u domain.User) (domain.User, error) {
+	return r.store.SaveUser(u)
+}
+
+func (r *repository) UpdatePassword(username, passwordHash string) error {
+	return r.store.UpdateUserPassword(username, passwordHash)
+}
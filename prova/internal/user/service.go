@@ -0,0 +1,106 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenTTL é o tempo de validade dos tokens de acesso
+const tokenTTL = 12 * time.Hour
+
+// minPasswordLength é o tamanho mínimo aceito para senhas
+const minPasswordLength = 8
+
+var (
+	// ErrInvalidCredentials indica que o login ou a senha não conferem
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUsernameExists indica que já existe um usuário com o mesmo login
+	ErrUsernameExists = errors.New("username already exists")
+	// ErrInvalidUser indica que os dados do usuário são inválidos
+	ErrInvalidUser = errors.New("invalid user: role must be admin, dentist or reception, dentists must have id_dentist and passwords at least 8 characters")
+)
+
+type Service interface {
+	// Login valida as credenciais e retorna um token de acesso
+	Login(username, password string) (string, error)
+	// Authenticate valida as credenciais e retorna o usuário, para clientes que usam autenticação básica
+	Authenticate(username, password string) (domain.User, error)
+	// Create cadastra um novo usuário com a senha informada
+	Create(u domain.User, password string) (domain.User, error)
+	// ResetPassword substitui a senha de um usuário
+	ResetPassword(username, password string) error
+}
+
+type service struct {
+	r      Repository
+	signer *auth.Signer
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository, signer *auth.Signer) Service {
+	return &service{r, signer}
+}
+
+func (s *service) Login(username, password string) (string, error) {
+	u, err := s.Authenticate(username, password)
+	if err != nil {
+		return "", err
+	}
+	return s.signer.IssueToken(auth.Claims{
+		UserID:    u.Id,
+		Username:  u.Username,
+		Role:      u.Role,
+		DentistID: u.IdDentist,
+	}, tokenTTL)
+}
+
+func (s *service) Authenticate(username, password string) (domain.User, error) {
+	u, err := s.r.GetByUsername(username)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return domain.User{}, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+func (s *service) Create(u domain.User, password string) (domain.User, error) {
+	switch {
+	case u.Role != domain.RoleAdmin && u.Role != domain.RoleDentist && u.Role != domain.RoleReception,
+		u.Role == domain.RoleDentist && u.IdDentist == 0,
+		len(password) < minPasswordLength:
+		return domain.User{}, ErrInvalidUser
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.PasswordHash = string(hash)
+
+	saved, err := s.r.Create(u)
+	if errors.Is(err, store.ErrDuplicate) {
+		return domain.User{}, ErrUsernameExists
+	}
+	return saved, err
+}
+
+func (s *service) ResetPassword(username, password string) error {
+	if len(password) < minPasswordLength {
+		return ErrInvalidUser
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.r.UpdatePassword(username, string(hash))
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+const (
+	// MaxAttempts é o número de tentativas antes de a entrega ir para a lista de falhas
+	MaxAttempts = 8
+	// BaseDelay é a espera antes da segunda tentativa, dobrada a cada nova falha
+	BaseDelay = 30 * time.Second
+	// MaxDelay limita a espera entre tentativas
+	MaxDelay = 6 * time.Hour
+	// batchSize é o número máximo de entregas tentadas a cada rodada
+	batchSize = 50
+)
+
+// Cabeçalhos enviados em cada entrega
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Sign calcula a assinatura enviada no cabeçalho X-Webhook-Signature, no formato
+// t=<unix>,v1=<hex(HMAC-SHA256(secret, "<unix>.<corpo>"))>. O integrador deve recalculá-la
+// com o segredo da assinatura e rejeitar mensagens com assinatura diferente ou muito antigas.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff retorna a espera após a tentativa informada, dobrando a cada falha até MaxDelay
+func Backoff(attempt int) time.Duration {
+	delay := BaseDelay
+	for i := 1; i < attempt && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		delay = MaxDelay
+	}
+	return delay
+}
+
+type Dispatcher interface {
+	// Run tenta as entregas pendentes a cada intervalo até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+	// DeliverDue tenta as entregas pendentes cuja vez já chegou e retorna quantas foram entregues
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+type dispatcher struct {
+	r      Repository
+	client *http.Client
+}
+
+// NewDispatcher cria o dispatcher que envia as entregas agendadas pelo Publish
+func NewDispatcher(r Repository, client *http.Client) Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &dispatcher{r, client}
+}
+
+func (d *dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil {
+			log.Printf("webhook: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	due, err := d.r.GetDue(time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	subscriptions := make(map[int]domain.WebhookSubscription)
+	delivered := 0
+	for _, delivery := range due {
+		sub, ok := subscriptions[delivery.IdSubscription]
+		if !ok {
+			if sub, err = d.r.GetByID(delivery.IdSubscription); err != nil {
+				return delivered, err
+			}
+			subscriptions[sub.Id] = sub
+		}
+
+		delivery.Attempts++
+		delivery.ResponseStatus, err = d.send(ctx, sub, delivery)
+		var next time.Time
+		switch {
+		case err == nil:
+			delivery.Status = domain.DeliveryDelivered
+			delivery.LastError = ""
+			delivered++
+		case delivery.Attempts >= MaxAttempts:
+			delivery.Status = domain.DeliveryDead
+			delivery.LastError = err.Error()
+		default:
+			delivery.LastError = err.Error()
+			next = time.Now().Add(Backoff(delivery.Attempts))
+		}
+		if err := d.r.UpdateDelivery(delivery, next); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// send faz o POST assinado da entrega e considera sucesso apenas respostas 2xx
+func (d *dispatcher) send(ctx context.Context, sub domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, strconv.Itoa(delivery.Id))
+	request.Header.Set(HeaderSignature, Sign(sub.Secret, time.Now().Unix(), delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("receiver responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// fakeRepository guarda as entregas em memória; os métodos não usados pelo dispatcher ficam
+// com a interface embutida
+type fakeRepository struct {
+	Repository
+	subscription domain.WebhookSubscription
+	due          []domain.WebhookDelivery
+	updated      []domain.WebhookDelivery
+	next         []time.Time
+}
+
+func (f *fakeRepository) GetDue(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return f.due, nil
+}
+
+func (f *fakeRepository) GetByID(id int) (domain.WebhookSubscription, error) {
+	return f.subscription, nil
+}
+
+func (f *fakeRepository) UpdateDelivery(d domain.WebhookDelivery, nextAttemptAt time.Time) error {
+	f.updated = append(f.updated, d)
+	f.next = append(f.next, nextAttemptAt)
+	return nil
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{0, BaseDelay},
+		{1, BaseDelay},
+		{2, 2 * BaseDelay},
+		{3, 4 * BaseDelay},
+		{5, 16 * BaseDelay},
+		{100, MaxDelay},
+	}
+	for _, c := range cases {
+		if got := Backoff(c.attempt); got != c.delay {
+			t.Errorf("Backoff(%d) = %v, want %v", c.attempt, got, c.delay)
+		}
+	}
+	for attempt := 1; attempt < 50; attempt++ {
+		if Backoff(attempt+1) < Backoff(attempt) {
+			t.Fatalf("Backoff(%d) is shorter than Backoff(%d)", attempt+1, attempt)
+		}
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	payload := []byte(`{"id":1}`)
+	repository := &fakeRepository{
+		subscription: domain.WebhookSubscription{Id: 1, URL: receiver.URL, Secret: "s3cret"},
+		due:          []domain.WebhookDelivery{{Id: 9, IdSubscription: 1, EventType: "appointment.created", Payload: payload}},
+	}
+	delivered, err := NewDispatcher(repository, receiver.Client()).DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("delivered = %d, want 1", delivered)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	if received.Header.Get(HeaderEvent) != "appointment.created" || received.Header.Get(HeaderDelivery) != "9" {
+		t.Errorf("headers = %v", received.Header)
+	}
+	signature := received.Header.Get(HeaderSignature)
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+	if err != nil || signature != Sign("s3cret", timestamp, payload) {
+		t.Errorf("signature %q doesn't match the payload", signature)
+	}
+	if got := repository.updated[0]; got.Status != domain.DeliveryDelivered || got.Attempts != 1 || got.ResponseStatus != http.StatusOK {
+		t.Errorf("delivery = %+v", got)
+	}
+}
+
+func TestDeliverDueRetriesAndGivesUp(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	repository := &fakeRepository{
+		subscription: domain.WebhookSubscription{Id: 1, URL: receiver.URL},
+		due: []domain.WebhookDelivery{
+			{Id: 1, IdSubscription: 1, Attempts: 2},
+			{Id: 2, IdSubscription: 1, Attempts: MaxAttempts - 1},
+		},
+	}
+	before := time.Now()
+	delivered, err := NewDispatcher(repository, receiver.Client()).DeliverDue(context.Background())
+	if err != nil || delivered != 0 {
+		t.Fatalf("delivered = %d, err = %v, want 0 and no error", delivered, err)
+	}
+
+	retried := repository.updated[0]
+	if retried.Status == domain.DeliveryDead || retried.ResponseStatus != http.StatusServiceUnavailable || retried.LastError == "" {
+		t.Errorf("retried delivery = %+v", retried)
+	}
+	if wait := repository.next[0].Sub(before); wait < Backoff(3) || wait > Backoff(3)+time.Minute {
+		t.Errorf("next attempt in %v, want about %v", wait, Backoff(3))
+	}
+	if dead := repository.updated[1]; dead.Status != domain.DeliveryDead || dead.Attempts != MaxAttempts {
+		t.Errorf("exhausted delivery = %+v, want it dead", dead)
+	}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+type Repository interface {
+	// GetAll retorna todas as assinaturas
+	GetAll() ([]domain.WebhookSubscription, error)
+	// GetByID retorna uma assinatura por id
+	GetByID(id int) (domain.WebhookSubscription, error)
+	// Create insere uma nova assinatura
+	Create(s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	// Update atualiza uma assinatura
+	Update(s domain.WebhookSubscription) error
+	// Delete exclui uma assinatura
+	Delete(id int) error
+	// Enqueue agenda as entregas de um evento
+	Enqueue(deliveries []domain.WebhookDelivery) error
+	// GetDue retorna as entregas pendentes que já podem ser tentadas
+	GetDue(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	// GetDeliveries retorna o histórico de entregas
+	GetDeliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error)
+	// GetDelivery retorna uma entrega por id
+	GetDelivery(id int) (domain.WebhookDelivery, error)
+	// UpdateDelivery registra o resultado de uma tentativa de entrega
+	UpdateDelivery(d domain.WebhookDelivery, nextAttemptAt time.Time) error
+}
+
+type repository struct {
+	store store.WebhookStore
+}
+
+// NewRepository cria um novo repositório
+func NewRepository(store store.WebhookStore) Repository {
+	return &repository{store}
+}
+
+func (r *repository) GetAll() ([]domain.WebhookSubscription, error) {
+	return r.store.GetSubscriptions()
+}
+
+func (r *repository) GetByID(id int) (domain.WebhookSubscription, error) {
+	return r.store.GetSubscriptionByID(id)
+}
+
+func (r *repository) Create(s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	return r.store.SaveSubscription(s)
+}
+
+func (r *repository) Update(s domain.WebhookSubscription) error {
+	return r.store.UpdateSubscription(s)
+}
+
+func (r *repository) Delete(id int) error {
+	return r.store.DeleteSubscription(id)
+}
+
+func (r *repository) Enqueue(deliveries []domain.WebhookDelivery) error {
+	return r.store.SaveDeliveries(deliveries)
+}
+
+func (r *repository) GetDue(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return r.store.GetDueDeliveries(now, limit)
+}
+
+func (r *repository) GetDeliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error) {
+	return r.store.GetDeliveries(subscriptionID, status)
+}
+
+func (r *repository) GetDelivery(id int) (domain.WebhookDelivery, error) {
+	return r.store.GetDeliveryByID(id)
+}
+
+func (r *repository) UpdateDelivery(d domain.WebhookDelivery, nextAttemptAt time.Time) error {
+	return r.store.UpdateDelivery(d, nextAttemptAt)
+}
@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+	"github.com/meirafa/prova2-golang/pkg/store"
+)
+
+var (
+	// ErrNotFound indica que a assinatura ou a entrega não existe
+	ErrNotFound = errors.New("webhook not found")
+	// ErrInvalidSubscription indica que os dados da assinatura são inválidos
+	ErrInvalidSubscription = errors.New("invalid webhook subscription")
+	// ErrNotDead indica que apenas entregas na lista de falhas podem ser reenviadas
+	ErrNotDead = errors.New("only dead deliveries can be retried")
+)
+
+type Service interface {
+	event.Sink
+	// GetAll retorna todas as assinaturas, sem os segredos
+	GetAll() ([]domain.WebhookSubscription, error)
+	// GetByID retorna uma assinatura por id, sem o segredo
+	GetByID(id int) (domain.WebhookSubscription, error)
+	// Create cadastra uma assinatura, gerando o segredo quando não informado. O segredo só é
+	// exibido nesta resposta.
+	Create(s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	// Update atualiza uma assinatura, mantendo os campos não informados
+	Update(id int, s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	// Delete exclui uma assinatura
+	Delete(id int) error
+	// Deliveries retorna o histórico de entregas de uma assinatura
+	Deliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error)
+	// DeadLetters retorna as entregas que esgotaram as tentativas
+	DeadLetters() ([]domain.WebhookDelivery, error)
+	// Retry reagenda uma entrega da lista de falhas
+	Retry(deliveryID int) (domain.WebhookDelivery, error)
+}
+
+type service struct {
+	r Repository
+}
+
+// NewService cria um novo serviço
+func NewService(r Repository) Service {
+	return &service{r}
+}
+
+func (s *service) GetAll() ([]domain.WebhookSubscription, error) {
+	subscriptions, err := s.r.GetAll()
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, err
+}
+
+func (s *service) GetByID(id int) (domain.WebhookSubscription, error) {
+	subscription, err := s.r.GetByID(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.WebhookSubscription{}, ErrNotFound
+	}
+	subscription.Secret = ""
+	return subscription, err
+}
+
+func (s *service) Create(sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+		sub.Secret = secret
+	}
+	if err := validate(&sub); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	saved, err := s.r.Create(sub)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	saved.Secret = sub.Secret
+	return saved, nil
+}
+
+func (s *service) Update(id int, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	sdb, err := s.r.GetByID(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.WebhookSubscription{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if sub.URL == "" {
+		sub.URL = sdb.URL
+	}
+	if sub.Secret == "" {
+		sub.Secret = sdb.Secret
+	}
+	if sub.Events == nil {
+		sub.Events = sdb.Events
+	}
+	if sub.Active == nil {
+		sub.Active = sdb.Active
+	}
+	if err := validate(&sub); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	sub.Id = id
+	if err := s.r.Update(sub); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) Delete(id int) error {
+	err := s.r.Delete(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *service) Deliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error) {
+	if _, err := s.GetByID(subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.r.GetDeliveries(subscriptionID, status)
+}
+
+func (s *service) DeadLetters() ([]domain.WebhookDelivery, error) {
+	return s.r.GetDeliveries(0, domain.DeliveryDead)
+}
+
+func (s *service) Retry(deliveryID int) (domain.WebhookDelivery, error) {
+	d, err := s.r.GetDelivery(deliveryID)
+	if errors.Is(err, store.ErrNotFound) {
+		return domain.WebhookDelivery{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if d.Status != domain.DeliveryDead {
+		return domain.WebhookDelivery{}, ErrNotDead
+	}
+	d.Status = domain.DeliveryPending
+	d.Attempts = 0
+	if err := s.r.UpdateDelivery(d, time.Now()); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return s.r.GetDelivery(deliveryID)
+}
+
+func (s *service) Name() string {
+	return "webhooks"
+}
+
+// Publish agenda a entrega do evento para cada assinatura ativa inscrita no seu tipo. O envio
+// é feito pelo Dispatcher, então a publicação não depende da disponibilidade dos integradores.
+// Republicar um evento não duplica as entregas já agendadas.
+func (s *service) Publish(_ context.Context, e domain.Event) error {
+	subscriptions, err := s.r.GetAll()
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	var deliveries []domain.WebhookDelivery
+	for _, sub := range subscriptions {
+		if (sub.Active != nil && !*sub.Active) || !subscribed(sub, e.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			IdSubscription: sub.Id,
+			EventId:        e.Id,
+			EventType:      e.Type,
+			Payload:        payload,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.r.Enqueue(deliveries)
+}
+
+func subscribed(sub domain.WebhookSubscription, eventType string) bool {
+	for _, t := range sub.Events {
+		if t == event.All || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// validate exige uma url http(s) absoluta e ao menos um tipo de evento conhecido
+func validate(sub *domain.WebhookSubscription) error {
+	sub.URL = strings.TrimSpace(sub.URL)
+	address, err := url.Parse(sub.URL)
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https address", ErrInvalidSubscription)
+	}
+	if len(sub.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidSubscription)
+	}
+	for _, t := range sub.Events {
+		if !event.IsValidType(t) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, t)
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
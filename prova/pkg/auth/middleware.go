@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+const claimsKey = "auth.claims"
+
+// WebSocketProtocol é o subprotocolo com que o cliente WebSocket envia o token de acesso
+const WebSocketProtocol = "bearer"
+
+// Middleware exige um token de acesso válido no cabeçalho Authorization
+func Middleware(s *Signer) gin.HandlerFunc {
+	return authenticate(s, headerToken)
+}
+
+// WebSocketMiddleware faz o mesmo que Middleware nas rotas WebSocket. Como o navegador não envia
+// cabeçalhos ao abrir a conexão, o token também é aceito no Sec-WebSocket-Protocol, como
+// "bearer, <token>"; o servidor deve então responder com o subprotocolo WebSocketProtocol.
+func WebSocketMiddleware(s *Signer) gin.HandlerFunc {
+	return authenticate(s, func(ctx *gin.Context) string {
+		if token := headerToken(ctx); token != "" {
+			return token
+		}
+		protocols := strings.Split(ctx.GetHeader("Sec-WebSocket-Protocol"), ",")
+		if len(protocols) == 2 && strings.TrimSpace(protocols[0]) == WebSocketProtocol {
+			return strings.TrimSpace(protocols[1])
+		}
+		return ""
+	})
+}
+
+func authenticate(s *Signer, token func(ctx *gin.Context) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value := token(ctx)
+		if value == "" {
+			web.BadResponse(ctx, http.StatusUnauthorized, "error", "missing bearer token")
+			ctx.Abort()
+			return
+		}
+		claims, err := s.ParseToken(value)
+		if err != nil {
+			web.BadResponse(ctx, http.StatusUnauthorized, "error", err.Error())
+			ctx.Abort()
+			return
+		}
+		ctx.Set(claimsKey, claims)
+		ctx.Next()
+	}
+}
+
+// headerToken retorna o token do cabeçalho Authorization, no formato "Bearer <token>"
+func headerToken(ctx *gin.Context) string {
+	header := ctx.GetHeader("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header {
+		return ""
+	}
+	return token
+}
+
+// RequireRole permite o acesso apenas aos usuários com um dos papéis informados
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, _ := FromContext(ctx)
+		for _, role := range roles {
+			if claims.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+		web.BadResponse(ctx, http.StatusForbidden, "error", "you don't have permission to access this resource")
+		ctx.Abort()
+	}
+}
+
+// FromContext retorna as claims do usuário autenticado na requisição
+func FromContext(ctx *gin.Context) (Claims, bool) {
+	value, ok := ctx.Get(claimsKey)
+	if !ok {
+		return Claims{}, false
+	}
+	claims, ok := value.(Claims)
+	return claims, ok
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken indica que o token está malformado ou a assinatura não confere
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken indica que o token já passou da data de expiração
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Signer assina e verifica tokens com HMAC-SHA256
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner cria um novo assinador de tokens a partir de uma chave secreta
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign serializa o payload em JSON e retorna o token no formato payload.assinatura
+func (s *Signer) Sign(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + s.signature(encoded), nil
+}
+
+// Verify confere a assinatura do token e decodifica o payload
+func (s *Signer) Verify(token string, payload interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(s.signature(parts[0]))) {
+		return ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (s *Signer) signature(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Claims identifica o usuário autenticado
+type Claims struct {
+	UserID    int    `json:"uid"`
+	Username  string `json:"sub"`
+	Role      string `json:"role"`
+	DentistID int    `json:"did,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// IssueToken gera um token de acesso válido pelo tempo informado
+func (s *Signer) IssueToken(claims Claims, ttl time.Duration) (string, error) {
+	claims.ExpiresAt = time.Now().Add(ttl).Unix()
+	return s.Sign(claims)
+}
+
+// ParseToken valida o token de acesso e retorna suas claims
+func (s *Signer) ParseToken(token string) (Claims, error) {
+	var claims Claims
+	if err := s.Verify(token, &claims); err != nil {
+		return Claims{}, err
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return Claims{}, ErrExpiredToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// ListAppointments lista todas as consultas. Sem nenhuma consulta cadastrada, a API responde com
+// ErrNotFound.
+func (c *Client) ListAppointments(ctx context.Context) ([]AppointmentDTO, error) {
+	var out []AppointmentDTO
+	err := c.call(ctx, listAppointments, nil, nil, nil, &out)
+	return out, err
+}
+
+// GetAppointment busca uma consulta
+func (c *Client) GetAppointment(ctx context.Context, id int) (AppointmentDTO, error) {
+	var out AppointmentDTO
+	err := c.call(ctx, getAppointment, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// ListAppointmentsByPatient lista as consultas do paciente pelo documento
+func (c *Client) ListAppointmentsByPatient(ctx context.Context, document string) ([]AppointmentDTO, error) {
+	var out []AppointmentDTO
+	err := c.call(ctx, listAppointmentsByPatient, []string{document}, nil, nil, &out)
+	return out, err
+}
+
+// CreateAppointment agenda uma consulta
+func (c *Client) CreateAppointment(ctx context.Context, a Appointment) (AppointmentDTO, error) {
+	var out AppointmentDTO
+	err := c.call(ctx, createAppointment, nil, nil, a, &out)
+	return out, err
+}
+
+// UpdateAppointment substitui uma consulta. version é a versão lida (AppointmentDTO.Version); se a
+// consulta foi alterada desde então, o erro corresponde a ErrPreconditionFailed. Zero substitui sem
+// conferir.
+func (c *Client) UpdateAppointment(ctx context.Context, id, version int, a Appointment) (AppointmentDTO, error) {
+	var out AppointmentDTO
+	err := c.callWithHeader(ctx, updateAppointment, []string{itoa(id)}, nil, ifMatch(version), a, &out)
+	return out, err
+}
+
+// PatchAppointment altera os campos informados de uma consulta, conferindo a versão como
+// UpdateAppointment
+func (c *Client) PatchAppointment(ctx context.Context, id, version int, patch AppointmentPatch) (AppointmentDTO, error) {
+	var out AppointmentDTO
+	err := c.callWithHeader(ctx, patchAppointment, []string{itoa(id)}, nil, ifMatch(version), patch, &out)
+	return out, err
+}
+
+// DeleteAppointment remove uma consulta, conferindo a versão como UpdateAppointment
+func (c *Client) DeleteAppointment(ctx context.Context, id, version int) error {
+	return c.callWithHeader(ctx, deleteAppointment, []string{itoa(id)}, nil, ifMatch(version), nil, nil)
+}
+
+// ifMatch monta o cabeçalho If-Match com a ETag da versão, ou * para a versão zero
+func ifMatch(version int) http.Header {
+	if version == 0 {
+		return http.Header{"If-Match": {"*"}}
+	}
+	return http.Header{"If-Match": {`"` + strconv.Itoa(version) + `"`}}
+}
+
+// IssueAppointmentLinks emite os links de confirmação e cancelamento da consulta
+func (c *Client) IssueAppointmentLinks(ctx context.Context, id int) (AppointmentLinks, error) {
+	var out AppointmentLinks
+	err := c.call(ctx, issueAppointmentLinks, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// ListAppointmentNotes lista as notas clínicas da consulta
+func (c *Client) ListAppointmentNotes(ctx context.Context, appointmentID int) ([]ClinicalNote, error) {
+	var out []ClinicalNote
+	err := c.call(ctx, listAppointmentNotes, []string{itoa(appointmentID)}, nil, nil, &out)
+	return out, err
+}
+
+// CreateNote registra uma nota clínica na consulta
+func (c *Client) CreateNote(ctx context.Context, appointmentID int, content string) (ClinicalNote, error) {
+	var out ClinicalNote
+	err := c.call(ctx, createNote, []string{itoa(appointmentID)}, nil, noteContent{Content: content}, &out)
+	return out, err
+}
+
+// UpdateNote altera o texto de uma nota ainda não assinada
+func (c *Client) UpdateNote(ctx context.Context, appointmentID, noteID int, content string) (ClinicalNote, error) {
+	var out ClinicalNote
+	err := c.call(ctx, updateNote, []string{itoa(appointmentID), itoa(noteID)}, nil, noteContent{Content: content}, &out)
+	return out, err
+}
+
+// SignNote assina uma nota clínica
+func (c *Client) SignNote(ctx context.Context, appointmentID, noteID int) (ClinicalNote, error) {
+	var out ClinicalNote
+	err := c.call(ctx, signNote, []string{itoa(appointmentID), itoa(noteID)}, nil, nil, &out)
+	return out, err
+}
+
+// AddAddendum acrescenta um adendo a uma nota assinada
+func (c *Client) AddAddendum(ctx context.Context, appointmentID, noteID int, content string) (ClinicalNote, error) {
+	var out ClinicalNote
+	err := c.call(ctx, addAddendum, []string{itoa(appointmentID), itoa(noteID)}, nil, noteContent{Content: content}, &out)
+	return out, err
+}
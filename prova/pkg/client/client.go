@@ -0,0 +1,266 @@
+// Package client é o cliente Go da API da clínica. Os tipos são os mesmos usados pelos handlers e
+// as rotas vêm de um catálogo conferido com o documento OpenAPI do servidor (veja Verify).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// Valores padrão das opções
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 200 * time.Millisecond
+)
+
+// Erros correspondentes aos status HTTP mais comuns, para uso com errors.Is
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	// ErrPreconditionFailed indica que o recurso foi alterado desde a versão informada
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error é uma resposta de erro da API, com o envelope do pacote web
+type Error struct {
+	StatusCode int
+	Status     string
+	Message    string
+	// Fields traz os campos inválidos quando a requisição é recusada pela validação
+	Fields []web.FieldError
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+	for _, f := range e.Fields {
+		message += fmt.Sprintf("; %s %s: %s", f.In, f.Field, f.Message)
+	}
+	return message
+}
+
+// Is associa o erro aos erros de status, como ErrNotFound
+func (e *Error) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusPreconditionFailed:
+		return target == ErrPreconditionFailed
+	}
+	return false
+}
+
+// Client é um cliente da API. É seguro para uso concorrente.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	retries int
+	backoff time.Duration
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configura o cliente
+type Option func(*Client)
+
+// WithHTTPClient usa o cliente HTTP informado, por exemplo com outro timeout ou transporte
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
+}
+
+// WithToken envia o token de acesso em todas as requisições
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRetries define quantas vezes as chamadas idempotentes (GET, PUT e DELETE) são repetidas após
+// falhas de rede ou respostas 429, 502, 503 e 504, e a espera inicial, dobrada a cada tentativa
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New cria um cliente para a API publicada em baseURL, como http://localhost:8083
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: DefaultTimeout},
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken troca o token de acesso enviado nas requisições
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Login autentica o usuário e passa a enviar o token emitido nas requisições seguintes
+func (c *Client) Login(ctx context.Context, username, password string) (string, error) {
+	var response token
+	if err := c.call(ctx, login, nil, nil, credentials{Username: username, Password: password}, &response); err != nil {
+		return "", err
+	}
+	c.SetToken(response.Token)
+	return response.Token, nil
+}
+
+// call faz a chamada ao endpoint, substituindo os parâmetros de caminho na ordem em que aparecem,
+// e decodifica o campo data da resposta em out. Nos endpoints sem envelope, out deve ser *[]byte.
+func (c *Client) call(ctx context.Context, e endpoint, params []string, query url.Values, body, out interface{}) error {
+	return c.callWithHeader(ctx, e, params, query, nil, body, out)
+}
+
+// callWithHeader é como call, enviando também os cabeçalhos informados, como o If-Match
+func (c *Client) callWithHeader(ctx context.Context, e endpoint, params []string, query url.Values, header http.Header, body, out interface{}) error {
+	path, err := e.expand(params)
+	if err != nil {
+		return err
+	}
+	address := c.baseURL + path
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+	payload, contentType, err := encode(body)
+	if err != nil {
+		return err
+	}
+
+	retries := 0
+	if e.idempotent() {
+		retries = c.retries
+	}
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		status, data, err := c.send(ctx, e.method, address, header, payload, contentType)
+		retry := err != nil || status == http.StatusTooManyRequests || status == http.StatusBadGateway ||
+			status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+		if !retry || attempt >= retries || ctx.Err() != nil {
+			if err != nil {
+				return err
+			}
+			return decode(status, data, e.raw, out)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// form é um corpo já codificado, como o multipart das importações
+type form struct {
+	contentType string
+	data        []byte
+}
+
+// encode serializa o corpo da requisição em JSON, exceto os corpos já codificados
+func encode(body interface{}) ([]byte, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case form:
+		return b.data, b.contentType, nil
+	}
+	payload, err := json.Marshal(body)
+	return payload, "application/json", err
+}
+
+func (c *Client) send(ctx context.Context, method, address string, header http.Header, payload []byte, contentType string) (int, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, address, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.mu.RLock()
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	c.mu.RUnlock()
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// decode interpreta a resposta: os erros viram *Error e as respostas de êxito têm o campo data
+// decodificado em out
+func decode(status int, data []byte, raw bool, out interface{}) error {
+	if status >= http.StatusBadRequest {
+		var envelope web.ValidationErrorResponse
+		if err := json.Unmarshal(data, &envelope); err != nil || envelope.Message == "" {
+			return &Error{StatusCode: status, Status: "error", Message: strings.TrimSpace(http.StatusText(status) + " " + string(data))}
+		}
+		return &Error{StatusCode: status, Status: envelope.Status, Message: envelope.Message, Fields: envelope.Errors}
+	}
+	if out == nil {
+		return nil
+	}
+	if raw {
+		if b, ok := out.(*[]byte); ok {
+			*b = data
+			return nil
+		}
+		return json.Unmarshal(data, out)
+	}
+	envelope := web.Response{Data: out}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("invalid api response: %w", err)
+	}
+	return nil
+}
+
+func itoa(id int) string {
+	return strconv.Itoa(id)
+}
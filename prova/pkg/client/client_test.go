@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/cmd/server/handler"
+	"github.com/meirafa/prova2-golang/internal/appointment"
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/user"
+	"github.com/meirafa/prova2-golang/pkg/auth"
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+)
+
+func apiDocument() *openapi.Document {
+	return openapi.Build(handler.APIInfo, handler.APITags, handler.Routes())
+}
+
+func TestVerify(t *testing.T) {
+	if problems := Verify(apiDocument()); len(problems) > 0 {
+		t.Errorf("client routes don't match the OpenAPI document: %v", problems)
+	}
+}
+
+// fakeAppointments guarda as consultas em memória, conferindo a versão como o serviço real
+type fakeAppointments struct {
+	appointment.Service
+	appointments map[int]domain.AppointmentDTO
+}
+
+func (f *fakeAppointments) GetAll() ([]domain.AppointmentDTO, error) {
+	var all []domain.AppointmentDTO
+	for _, a := range f.appointments {
+		all = append(all, a)
+	}
+	return all, nil
+}
+
+func (f *fakeAppointments) GetByID(id int) (domain.AppointmentDTO, error) {
+	a, ok := f.appointments[id]
+	if !ok {
+		return a, errors.New("appointment not found")
+	}
+	return a, nil
+}
+
+func (f *fakeAppointments) Create(a domain.Appointment) (domain.AppointmentDTO, error) {
+	a.Id = len(f.appointments) + 1
+	a.Version = 1
+	dto := domain.AppointmentDTO{
+		Appointment: a,
+		Dentist:     domain.Dentist{Id: 1, Name: "Ana", Surname: "Souza", Registration: a.IdDentist},
+		Patient:     domain.Patient{Id: 1, Name: "Bruno", Surname: "Lima", Document: a.IdPatient, CreatedAt: "2024-01-02"},
+	}
+	f.appointments[a.Id] = dto
+	return dto, nil
+}
+
+func (f *fakeAppointments) Update(id int, a domain.Appointment) (domain.AppointmentDTO, error) {
+	current, err := f.GetByID(id)
+	if err != nil {
+		return current, err
+	}
+	if a.Version != 0 && a.Version != current.Version {
+		return current, appointment.ErrVersionMismatch
+	}
+	a.Id, a.Version = id, current.Version+1
+	current.Appointment = a
+	f.appointments[id] = current
+	return current, nil
+}
+
+func (f *fakeAppointments) Delete(id, version int) error {
+	current, err := f.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != current.Version {
+		return appointment.ErrVersionMismatch
+	}
+	delete(f.appointments, id)
+	return nil
+}
+
+// fakeUsers emite tokens para o administrador com o Signer do roteador
+type fakeUsers struct {
+	user.Service
+	signer *auth.Signer
+}
+
+func (f fakeUsers) Login(username, password string) (string, error) {
+	if username != "admin" || password != "secret" {
+		return "", user.ErrInvalidCredentials
+	}
+	return f.signer.IssueToken(auth.Claims{UserID: 1, Username: username, Role: domain.RoleAdmin}, time.Hour)
+}
+
+func (f fakeUsers) Create(u domain.User, password string) (domain.User, error) {
+	u.Id = 2
+	return u, nil
+}
+
+func TestClientAgainstRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	signer := auth.NewSigner("test secret")
+	handlers := handler.Handlers{
+		Signer:      signer,
+		Appointment: handler.NewAppointmentHandler(&fakeAppointments{appointments: map[int]domain.AppointmentDTO{}}),
+		Dentist:     handler.NewDentistHandler(nil),
+		Patient:     handler.NewPatientHandler(nil),
+		User:        handler.NewUserHandler(fakeUsers{signer: signer}),
+		Note:        handler.NewNoteHandler(nil),
+		Chart:       handler.NewChartHandler(nil),
+		Treatment:   handler.NewTreatmentHandler(nil),
+		Procedure:   handler.NewProcedureHandler(nil),
+		Invoice:     handler.NewInvoiceHandler(nil),
+		Insurance:   handler.NewInsuranceHandler(nil),
+		SelfService: handler.NewSelfServiceHandler(nil),
+		Calendar:    handler.NewCalendarHandler(nil),
+		CalDAV:      handler.NewCalDAVHandler(nil, nil),
+		Import:      handler.NewImportHandler(nil),
+		Export:      handler.NewExportHandler(nil),
+		Report:      handler.NewReportHandler(nil),
+		Webhook:     handler.NewWebhookHandler(nil),
+		Board:       handler.NewBoardHandler(nil),
+		GraphQL:     handler.NewGraphQLHandler(nil),
+	}
+	// as respostas também são conferidas com o documento, como nos testes do servidor
+	server := httptest.NewServer(handler.NewRouter(handlers, apiDocument(), openapi.Options{Responses: true}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := New(server.URL, WithRetries(0, 0))
+
+	if _, err := c.ListAppointments(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListAppointments without appointments: err = %v, want ErrNotFound", err)
+	}
+
+	_, err := c.CreateAppointment(ctx, Appointment{Description: "Limpeza", AppointmentDate: "amanhã", IdDentist: "CRO-SP 12345", IdPatient: "12345678900"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrBadRequest) || len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "appointment_date" {
+		t.Fatalf("CreateAppointment with an invalid date: err = %v", err)
+	}
+
+	created, err := c.CreateAppointment(ctx, Appointment{Description: "Limpeza", AppointmentDate: "25/12/2030 14:30", IdDentist: "CRO-SP 12345", IdPatient: "12345678900"})
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	read, err := c.GetAppointment(ctx, created.Id)
+	if err != nil || read.Version != 1 || read.Dentist.Registration != "CRO-SP 12345" {
+		t.Fatalf("GetAppointment = %+v, %v", read, err)
+	}
+
+	change := read.Appointment
+	change.Description = "Limpeza e flúor"
+	updated, err := c.UpdateAppointment(ctx, read.Id, read.Version, change)
+	if err != nil || updated.Version != 2 || updated.Description != change.Description {
+		t.Fatalf("UpdateAppointment = %+v, %v", updated, err)
+	}
+	if _, err := c.UpdateAppointment(ctx, read.Id, read.Version, change); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("UpdateAppointment with a stale version: err = %v, want ErrPreconditionFailed", err)
+	}
+	if err := c.DeleteAppointment(ctx, read.Id, updated.Version); err != nil {
+		t.Fatalf("DeleteAppointment: %v", err)
+	}
+	if _, err := c.GetAppointment(ctx, read.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAppointment after DeleteAppointment: err = %v, want ErrNotFound", err)
+	}
+
+	reception := User{Username: "recepcao", Role: domain.RoleReception}
+	if _, err := c.CreateUser(ctx, reception, "s3nha-forte"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CreateUser without a token: err = %v, want ErrUnauthorized", err)
+	}
+	if _, err := c.Login(ctx, "admin", "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Login with a wrong password: err = %v, want ErrUnauthorized", err)
+	}
+	if _, err := c.Login(ctx, "admin", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if u, err := c.CreateUser(ctx, reception, "s3nha-forte"); err != nil || u.Id != 2 {
+		t.Errorf("CreateUser = %+v, %v", u, err)
+	}
+}
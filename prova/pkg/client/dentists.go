@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListDentists lista os dentistas
+func (c *Client) ListDentists(ctx context.Context) ([]Dentist, error) {
+	var out []Dentist
+	err := c.call(ctx, listDentists, nil, nil, nil, &out)
+	return out, err
+}
+
+// GetDentist busca um dentista
+func (c *Client) GetDentist(ctx context.Context, id int) (Dentist, error) {
+	var out Dentist
+	err := c.call(ctx, getDentist, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// CreateDentist cadastra um dentista
+func (c *Client) CreateDentist(ctx context.Context, d Dentist) (Dentist, error) {
+	var out Dentist
+	err := c.call(ctx, createDentist, nil, nil, d, &out)
+	return out, err
+}
+
+// UpdateDentist substitui um dentista
+func (c *Client) UpdateDentist(ctx context.Context, id int, d Dentist) (Dentist, error) {
+	var out Dentist
+	err := c.call(ctx, updateDentist, []string{itoa(id)}, nil, d, &out)
+	return out, err
+}
+
+// PatchDentist altera os campos informados de um dentista
+func (c *Client) PatchDentist(ctx context.Context, id int, patch DentistPatch) (Dentist, error) {
+	var out Dentist
+	err := c.call(ctx, patchDentist, []string{itoa(id)}, nil, patch, &out)
+	return out, err
+}
+
+// DeleteDentist remove um dentista
+func (c *Client) DeleteDentist(ctx context.Context, id int) error {
+	return c.call(ctx, deleteDentist, []string{itoa(id)}, nil, nil, nil)
+}
+
+// IssueDentistCalendarFeed emite um novo endereço secreto da agenda do dentista
+func (c *Client) IssueDentistCalendarFeed(ctx context.Context, id int) (CalendarFeed, error) {
+	var out CalendarFeed
+	err := c.call(ctx, issueDentistCalendar, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// DentistCalendar baixa a agenda do dentista em iCalendar, com o token do endereço secreto
+func (c *Client) DentistCalendar(ctx context.Context, id int, feedToken string) ([]byte, error) {
+	var out []byte
+	err := c.call(ctx, dentistCalendar, []string{itoa(id)}, url.Values{"token": {feedToken}}, nil, &out)
+	return out, err
+}
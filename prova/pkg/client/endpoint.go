@@ -0,0 +1,130 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/pkg/openapi"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// endpoint é uma rota da API usada pelo cliente, com amostras dos tipos do corpo e da resposta
+// para a conferência com o documento do servidor
+type endpoint struct {
+	method   string
+	path     string
+	body     interface{}
+	response interface{}
+	// raw indica resposta sem o envelope web.Response
+	raw bool
+}
+
+// idempotent indica se a chamada pode ser repetida sem efeitos adicionais
+func (e endpoint) idempotent() bool {
+	switch e.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// expand substitui os parâmetros do caminho (:id) pelos valores, na ordem
+func (e endpoint) expand(params []string) (string, error) {
+	segments := strings.Split(e.path, "/")
+	next := 0
+	for i, s := range segments {
+		if !strings.HasPrefix(s, ":") {
+			continue
+		}
+		if next >= len(params) {
+			return "", fmt.Errorf("missing parameter %s for %s %s", s, e.method, e.path)
+		}
+		segments[i] = url.PathEscape(params[next])
+		next++
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// deleted é a resposta das exclusões, sem o envelope
+var deleted = web.ErrorResponse{}
+
+var (
+	login      = endpoint{method: http.MethodPost, path: "/api/login", body: credentials{}, response: token{}}
+	createUser = endpoint{method: http.MethodPost, path: "/api/users", body: newUser{}, response: User{}}
+
+	importPatients = endpoint{method: http.MethodPost, path: "/api/import/patients", response: ImportReport{}}
+	importDentists = endpoint{method: http.MethodPost, path: "/api/import/dentists", response: ImportReport{}}
+
+	listAppointments          = endpoint{method: http.MethodGet, path: "/api/appointments", response: []AppointmentDTO{}}
+	getAppointment            = endpoint{method: http.MethodGet, path: "/api/appointments/:id", response: AppointmentDTO{}}
+	listAppointmentsByPatient = endpoint{method: http.MethodGet, path: "/api/appointments/patient/:document", response: []AppointmentDTO{}}
+	createAppointment         = endpoint{method: http.MethodPost, path: "/api/appointments", body: Appointment{}, response: AppointmentDTO{}}
+	updateAppointment         = endpoint{method: http.MethodPut, path: "/api/appointments/:id", body: Appointment{}, response: AppointmentDTO{}}
+	patchAppointment          = endpoint{method: http.MethodPatch, path: "/api/appointments/:id", body: AppointmentPatch{}, response: AppointmentDTO{}}
+	deleteAppointment         = endpoint{method: http.MethodDelete, path: "/api/appointments/:id", response: deleted, raw: true}
+	issueAppointmentLinks     = endpoint{method: http.MethodPost, path: "/api/appointments/:id/links", response: AppointmentLinks{}}
+	listAppointmentNotes      = endpoint{method: http.MethodGet, path: "/api/appointments/:id/notes", response: []ClinicalNote{}}
+	createNote                = endpoint{method: http.MethodPost, path: "/api/appointments/:id/notes", body: noteContent{}, response: ClinicalNote{}}
+	updateNote                = endpoint{method: http.MethodPatch, path: "/api/appointments/:id/notes/:noteId", body: noteContent{}, response: ClinicalNote{}}
+	signNote                  = endpoint{method: http.MethodPost, path: "/api/appointments/:id/notes/:noteId/sign", response: ClinicalNote{}}
+	addAddendum               = endpoint{method: http.MethodPost, path: "/api/appointments/:id/notes/:noteId/addenda", body: noteContent{}, response: ClinicalNote{}}
+
+	listDentists         = endpoint{method: http.MethodGet, path: "/api/dentists", response: []Dentist{}}
+	getDentist           = endpoint{method: http.MethodGet, path: "/api/dentists/:id", response: Dentist{}}
+	createDentist        = endpoint{method: http.MethodPost, path: "/api/dentists", body: Dentist{}, response: Dentist{}}
+	updateDentist        = endpoint{method: http.MethodPut, path: "/api/dentists/:id", body: Dentist{}, response: Dentist{}}
+	patchDentist         = endpoint{method: http.MethodPatch, path: "/api/dentists/:id", body: DentistPatch{}, response: Dentist{}}
+	deleteDentist        = endpoint{method: http.MethodDelete, path: "/api/dentists/:id", response: deleted, raw: true}
+	dentistCalendar      = endpoint{method: http.MethodGet, path: "/api/dentists/:id/calendar.ics", raw: true}
+	issueDentistCalendar = endpoint{method: http.MethodPost, path: "/api/dentists/:id/calendar-feed", response: CalendarFeed{}}
+
+	listPatients            = endpoint{method: http.MethodGet, path: "/api/patients", response: []Patient{}}
+	getPatient              = endpoint{method: http.MethodGet, path: "/api/patients/:id", response: Patient{}}
+	createPatient           = endpoint{method: http.MethodPost, path: "/api/patients", body: Patient{}, response: Patient{}}
+	updatePatient           = endpoint{method: http.MethodPut, path: "/api/patients/:id", body: Patient{}, response: Patient{}}
+	patchPatient            = endpoint{method: http.MethodPatch, path: "/api/patients/:id", body: PatientPatch{}, response: Patient{}}
+	deletePatient           = endpoint{method: http.MethodDelete, path: "/api/patients/:id", response: deleted, raw: true}
+	patientCalendar         = endpoint{method: http.MethodGet, path: "/api/patients/:id/calendar.ics", raw: true}
+	issuePatientCalendar    = endpoint{method: http.MethodPost, path: "/api/patients/:id/calendar-feed", response: CalendarFeed{}}
+	listPatientNotes        = endpoint{method: http.MethodGet, path: "/api/patients/:id/notes", response: []ClinicalNote{}}
+	getChart                = endpoint{method: http.MethodGet, path: "/api/patients/:id/chart", response: DentalChart{}}
+	updateChart             = endpoint{method: http.MethodPatch, path: "/api/patients/:id/chart", body: ChartUpdate{}, response: DentalChart{}}
+	chartHistory            = endpoint{method: http.MethodGet, path: "/api/patients/:id/chart/history", response: []ChartEntry{}}
+	listTreatmentPlans      = endpoint{method: http.MethodGet, path: "/api/patients/:id/treatment-plans", response: []TreatmentPlan{}}
+	createTreatmentPlan     = endpoint{method: http.MethodPost, path: "/api/patients/:id/treatment-plans", body: TreatmentPlan{}, response: TreatmentPlan{}}
+	patientBalance          = endpoint{method: http.MethodGet, path: "/api/patients/:id/balance", response: PatientBalance{}}
+	listMemberships         = endpoint{method: http.MethodGet, path: "/api/patients/:id/memberships", response: []Membership{}}
+	addMembership           = endpoint{method: http.MethodPost, path: "/api/patients/:id/memberships", body: Membership{}, response: Membership{}}
+	deleteMembership        = endpoint{method: http.MethodDelete, path: "/api/patients/:id/memberships/:membershipId", response: deleted, raw: true}
+	listPreAuthorizations   = endpoint{method: http.MethodGet, path: "/api/patients/:id/pre-authorizations", response: []PreAuthorization{}}
+	requestPreAuthorization = endpoint{method: http.MethodPost, path: "/api/patients/:id/pre-authorizations", body: PreAuthorization{}, response: PreAuthorization{}}
+)
+
+// endpoints lista todas as rotas usadas pelo cliente
+var endpoints = []endpoint{
+	login, createUser, importPatients, importDentists,
+
+	listAppointments, getAppointment, listAppointmentsByPatient, createAppointment, updateAppointment,
+	patchAppointment, deleteAppointment, issueAppointmentLinks,
+	listAppointmentNotes, createNote, updateNote, signNote, addAddendum,
+
+	listDentists, getDentist, createDentist, updateDentist, patchDentist, deleteDentist,
+	dentistCalendar, issueDentistCalendar,
+
+	listPatients, getPatient, createPatient, updatePatient, patchPatient, deletePatient,
+	patientCalendar, issuePatientCalendar, listPatientNotes,
+	getChart, updateChart, chartHistory, listTreatmentPlans, createTreatmentPlan, patientBalance,
+	listMemberships, addMembership, deleteMembership, listPreAuthorizations, requestPreAuthorization,
+}
+
+// Verify confere as rotas do cliente com o documento OpenAPI do servidor: cada rota precisa estar
+// documentada, com os mesmos esquemas do corpo e da resposta. Retorna as divergências.
+func Verify(doc *openapi.Document) []string {
+	routes := make([]openapi.Route, 0, len(endpoints))
+	for _, e := range endpoints {
+		routes = append(routes, openapi.Route{Method: e.method, Path: e.path, Body: e.body, Response: e.response, Raw: e.raw})
+	}
+	return doc.Compare(openapi.Build(openapi.Info{}, nil, routes))
+}
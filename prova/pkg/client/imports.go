@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"strconv"
+)
+
+// ImportPatients envia uma planilha CSV ou XLSX de pacientes e retorna o relatório por linha
+func (c *Client) ImportPatients(ctx context.Context, filename string, data []byte, opts ImportOptions) (ImportReport, error) {
+	return c.importSheet(ctx, importPatients, filename, data, opts)
+}
+
+// ImportDentists envia uma planilha CSV ou XLSX de dentistas e retorna o relatório por linha
+func (c *Client) ImportDentists(ctx context.Context, filename string, data []byte, opts ImportOptions) (ImportReport, error) {
+	return c.importSheet(ctx, importDentists, filename, data, opts)
+}
+
+func (c *Client) importSheet(ctx context.Context, e endpoint, filename string, data []byte, opts ImportOptions) (ImportReport, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	file, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	if _, err := file.Write(data); err != nil {
+		return ImportReport{}, err
+	}
+	fields := map[string]string{
+		"format":       opts.Format,
+		"on_duplicate": opts.OnDuplicate,
+	}
+	if opts.DryRun {
+		fields["dry_run"] = "true"
+	}
+	if opts.BatchSize != 0 {
+		fields["batch_size"] = strconv.Itoa(opts.BatchSize)
+	}
+	if len(opts.Mapping) > 0 {
+		mapping, err := json.Marshal(opts.Mapping)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		fields["mapping"] = string(mapping)
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := w.WriteField(name, value); err != nil {
+			return ImportReport{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return ImportReport{}, err
+	}
+
+	var out ImportReport
+	err = c.call(ctx, e, nil, nil, form{contentType: w.FormDataContentType(), data: buf.Bytes()}, &out)
+	return out, err
+}
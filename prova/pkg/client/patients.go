@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListPatients lista os pacientes
+func (c *Client) ListPatients(ctx context.Context) ([]Patient, error) {
+	var out []Patient
+	err := c.call(ctx, listPatients, nil, nil, nil, &out)
+	return out, err
+}
+
+// GetPatient busca um paciente
+func (c *Client) GetPatient(ctx context.Context, id int) (Patient, error) {
+	var out Patient
+	err := c.call(ctx, getPatient, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// CreatePatient cadastra um paciente
+func (c *Client) CreatePatient(ctx context.Context, p Patient) (Patient, error) {
+	var out Patient
+	err := c.call(ctx, createPatient, nil, nil, p, &out)
+	return out, err
+}
+
+// UpdatePatient substitui um paciente
+func (c *Client) UpdatePatient(ctx context.Context, id int, p Patient) (Patient, error) {
+	var out Patient
+	err := c.call(ctx, updatePatient, []string{itoa(id)}, nil, p, &out)
+	return out, err
+}
+
+// PatchPatient altera os campos informados de um paciente
+func (c *Client) PatchPatient(ctx context.Context, id int, patch PatientPatch) (Patient, error) {
+	var out Patient
+	err := c.call(ctx, patchPatient, []string{itoa(id)}, nil, patch, &out)
+	return out, err
+}
+
+// DeletePatient remove um paciente
+func (c *Client) DeletePatient(ctx context.Context, id int) error {
+	return c.call(ctx, deletePatient, []string{itoa(id)}, nil, nil, nil)
+}
+
+// IssuePatientCalendarFeed emite um novo endereço secreto do calendário do paciente
+func (c *Client) IssuePatientCalendarFeed(ctx context.Context, id int) (CalendarFeed, error) {
+	var out CalendarFeed
+	err := c.call(ctx, issuePatientCalendar, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// PatientCalendar baixa as consultas do paciente em iCalendar, com o token do endereço secreto
+func (c *Client) PatientCalendar(ctx context.Context, id int, feedToken string) ([]byte, error) {
+	var out []byte
+	err := c.call(ctx, patientCalendar, []string{itoa(id)}, url.Values{"token": {feedToken}}, nil, &out)
+	return out, err
+}
+
+// ListPatientNotes lista as notas clínicas do paciente
+func (c *Client) ListPatientNotes(ctx context.Context, id int) ([]ClinicalNote, error) {
+	var out []ClinicalNote
+	err := c.call(ctx, listPatientNotes, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// GetChart retorna o odontograma atual do paciente
+func (c *Client) GetChart(ctx context.Context, id int) (DentalChart, error) {
+	var out DentalChart
+	err := c.call(ctx, getChart, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// UpdateChart registra alterações no odontograma durante uma consulta
+func (c *Client) UpdateChart(ctx context.Context, id int, update ChartUpdate) (DentalChart, error) {
+	var out DentalChart
+	err := c.call(ctx, updateChart, []string{itoa(id)}, nil, update, &out)
+	return out, err
+}
+
+// ChartHistory retorna o histórico do odontograma; tooth diferente de zero filtra um dente
+func (c *Client) ChartHistory(ctx context.Context, id, tooth int) ([]ChartEntry, error) {
+	var query url.Values
+	if tooth != 0 {
+		query = url.Values{"tooth": {itoa(tooth)}}
+	}
+	var out []ChartEntry
+	err := c.call(ctx, chartHistory, []string{itoa(id)}, query, nil, &out)
+	return out, err
+}
+
+// ListTreatmentPlans lista os planos de tratamento do paciente
+func (c *Client) ListTreatmentPlans(ctx context.Context, id int) ([]TreatmentPlan, error) {
+	var out []TreatmentPlan
+	err := c.call(ctx, listTreatmentPlans, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// CreateTreatmentPlan cria um plano de tratamento para o paciente
+func (c *Client) CreateTreatmentPlan(ctx context.Context, id int, plan TreatmentPlan) (TreatmentPlan, error) {
+	var out TreatmentPlan
+	err := c.call(ctx, createTreatmentPlan, []string{itoa(id)}, nil, plan, &out)
+	return out, err
+}
+
+// PatientBalance retorna o saldo financeiro do paciente
+func (c *Client) PatientBalance(ctx context.Context, id int) (PatientBalance, error) {
+	var out PatientBalance
+	err := c.call(ctx, patientBalance, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// ListMemberships lista as adesões do paciente a convênios
+func (c *Client) ListMemberships(ctx context.Context, id int) ([]Membership, error) {
+	var out []Membership
+	err := c.call(ctx, listMemberships, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// AddMembership registra a adesão do paciente a um convênio
+func (c *Client) AddMembership(ctx context.Context, id int, m Membership) (Membership, error) {
+	var out Membership
+	err := c.call(ctx, addMembership, []string{itoa(id)}, nil, m, &out)
+	return out, err
+}
+
+// DeleteMembership remove uma adesão do paciente
+func (c *Client) DeleteMembership(ctx context.Context, id, membershipID int) error {
+	return c.call(ctx, deleteMembership, []string{itoa(id), itoa(membershipID)}, nil, nil, nil)
+}
+
+// ListPreAuthorizations lista as autorizações prévias do paciente
+func (c *Client) ListPreAuthorizations(ctx context.Context, id int) ([]PreAuthorization, error) {
+	var out []PreAuthorization
+	err := c.call(ctx, listPreAuthorizations, []string{itoa(id)}, nil, nil, &out)
+	return out, err
+}
+
+// RequestPreAuthorization solicita uma autorização prévia ao convênio do paciente
+func (c *Client) RequestPreAuthorization(ctx context.Context, id int, a PreAuthorization) (PreAuthorization, error) {
+	var out PreAuthorization
+	err := c.call(ctx, requestPreAuthorization, []string{itoa(id)}, nil, a, &out)
+	return out, err
+}
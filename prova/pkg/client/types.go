@@ -0,0 +1,94 @@
+package client
+
+import (
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/importer"
+)
+
+// Tipos da API, os mesmos usados pelos handlers
+type (
+	Appointment          = domain.Appointment
+	AppointmentDTO       = domain.AppointmentDTO
+	AppointmentLinks     = domain.AppointmentLinks
+	ClinicalNote         = domain.ClinicalNote
+	Dentist              = domain.Dentist
+	Patient              = domain.Patient
+	Address              = domain.Address
+	Contact              = domain.Contact
+	CommunicationConsent = domain.CommunicationConsent
+	CalendarFeed         = domain.CalendarFeed
+	DentalChart          = domain.DentalChart
+	ToothCondition       = domain.ToothCondition
+	ChartEntry           = domain.ChartEntry
+	TreatmentPlan        = domain.TreatmentPlan
+	PatientBalance       = domain.PatientBalance
+	Membership           = domain.Membership
+	PreAuthorization     = domain.PreAuthorization
+	User                 = domain.User
+	ImportReport         = domain.ImportReport
+	ImportRow            = domain.ImportRow
+	// ImportOptions são as opções das importações; Format vazio é deduzido da extensão do arquivo
+	ImportOptions = importer.Options
+)
+
+// AppointmentPatch são os campos da consulta alterados por PatchAppointment; os vazios são mantidos
+type AppointmentPatch struct {
+	Description     string `json:"description,omitempty"`
+	AppointmentDate string `json:"appointment_date,omitempty" openapi:"format=appointment-date"`
+	IdDentist       string `json:"id_dentist,omitempty" openapi:"format=registration"`
+	IdPatient       string `json:"id_patient,omitempty" openapi:"format=document"`
+	Status          string `json:"status,omitempty" openapi:"enum=scheduled|confirmed|checked_in|completed|cancelled|no_show"`
+	ProcedureCode   string `json:"procedure_code,omitempty"`
+	Duration        int    `json:"duration,omitempty" openapi:"minimum=0"`
+}
+
+// DentistPatch são os campos do dentista alterados por PatchDentist; os vazios são mantidos
+type DentistPatch struct {
+	Surname      string `json:"surname,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Registration string `json:"registration,omitempty" openapi:"format=registration"`
+}
+
+// PatientPatch são os campos do paciente alterados por PatchPatient; os vazios são mantidos
+type PatientPatch struct {
+	Surname           string                `json:"surname,omitempty"`
+	Name              string                `json:"name,omitempty"`
+	Document          string                `json:"document,omitempty"`
+	CreatedAt         string                `json:"created_at,omitempty"`
+	Email             string                `json:"email,omitempty"`
+	Phones            []string              `json:"phones,omitempty"`
+	BirthDate         string                `json:"birth_date,omitempty"`
+	Address           *Address              `json:"address,omitempty"`
+	Guardian          *Contact              `json:"guardian,omitempty"`
+	EmergencyContact  *Contact              `json:"emergency_contact,omitempty"`
+	PreferredLanguage string                `json:"preferred_language,omitempty"`
+	Consent           *CommunicationConsent `json:"consent,omitempty"`
+}
+
+// ChartUpdate são as alterações do odontograma registradas durante uma consulta
+type ChartUpdate struct {
+	IdAppointment int              `json:"id_appointment" binding:"required"`
+	Changes       []ToothCondition `json:"changes" binding:"required"`
+}
+
+// credentials é o corpo do login
+type credentials struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// token é a resposta do login
+type token struct {
+	Token string `json:"token"`
+}
+
+// noteContent é o corpo das notas clínicas e adendos
+type noteContent struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// newUser é o corpo do cadastro de usuário, com a senha inicial
+type newUser struct {
+	User
+	Password string `json:"password" binding:"required"`
+}
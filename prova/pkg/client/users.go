@@ -0,0 +1,10 @@
+package client
+
+import "context"
+
+// CreateUser cadastra um usuário com a senha inicial; exige o papel admin
+func (c *Client) CreateUser(ctx context.Context, u User, password string) (User, error) {
+	var out User
+	err := c.call(ctx, createUser, nil, nil, newUser{User: u, Password: password}, &out)
+	return out, err
+}
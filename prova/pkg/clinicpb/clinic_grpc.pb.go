@@ -0,0 +1,1167 @@
+// Contrato gRPC da clínica para as chamadas entre serviços internos. As operações espelham os
+// serviços de consultas, dentistas e pacientes; os campos seguem os formatos da API REST.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: clinic.proto
+
+package clinicpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AppointmentService_ListAppointments_FullMethodName          = "/clinic.v1.AppointmentService/ListAppointments"
+	AppointmentService_GetAppointment_FullMethodName            = "/clinic.v1.AppointmentService/GetAppointment"
+	AppointmentService_ListAppointmentsByPatient_FullMethodName = "/clinic.v1.AppointmentService/ListAppointmentsByPatient"
+	AppointmentService_ListAppointmentsByDentist_FullMethodName = "/clinic.v1.AppointmentService/ListAppointmentsByDentist"
+	AppointmentService_ListAppointmentsBetween_FullMethodName   = "/clinic.v1.AppointmentService/ListAppointmentsBetween"
+	AppointmentService_CreateAppointment_FullMethodName         = "/clinic.v1.AppointmentService/CreateAppointment"
+	AppointmentService_UpdateAppointment_FullMethodName         = "/clinic.v1.AppointmentService/UpdateAppointment"
+	AppointmentService_DeleteAppointment_FullMethodName         = "/clinic.v1.AppointmentService/DeleteAppointment"
+	AppointmentService_ListScheduleBlocks_FullMethodName        = "/clinic.v1.AppointmentService/ListScheduleBlocks"
+	AppointmentService_GetScheduleBlock_FullMethodName          = "/clinic.v1.AppointmentService/GetScheduleBlock"
+	AppointmentService_SaveScheduleBlock_FullMethodName         = "/clinic.v1.AppointmentService/SaveScheduleBlock"
+	AppointmentService_DeleteScheduleBlock_FullMethodName       = "/clinic.v1.AppointmentService/DeleteScheduleBlock"
+	AppointmentService_WatchAppointments_FullMethodName         = "/clinic.v1.AppointmentService/WatchAppointments"
+)
+
+// AppointmentServiceClient is the client API for AppointmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AppointmentServiceClient interface {
+	// ListAppointments retorna todas as consultas
+	ListAppointments(ctx context.Context, in *ListAppointmentsRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error)
+	// GetAppointment busca uma consulta pelo id
+	GetAppointment(ctx context.Context, in *GetAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error)
+	// ListAppointmentsByPatient lista as consultas de um paciente pelo documento
+	ListAppointmentsByPatient(ctx context.Context, in *ListAppointmentsByPatientRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error)
+	// ListAppointmentsByDentist lista as consultas de um dentista pelo CRO
+	ListAppointmentsByDentist(ctx context.Context, in *ListAppointmentsByDentistRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error)
+	// ListAppointmentsBetween lista as consultas marcadas no período, sem dentista e paciente
+	ListAppointmentsBetween(ctx context.Context, in *ListAppointmentsBetweenRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error)
+	// CreateAppointment agenda uma consulta
+	CreateAppointment(ctx context.Context, in *CreateAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error)
+	// UpdateAppointment substitui uma consulta
+	UpdateAppointment(ctx context.Context, in *UpdateAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error)
+	// DeleteAppointment remove uma consulta
+	DeleteAppointment(ctx context.Context, in *DeleteAppointmentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListScheduleBlocks lista os horários bloqueados na agenda de um dentista
+	ListScheduleBlocks(ctx context.Context, in *ListScheduleBlocksRequest, opts ...grpc.CallOption) (*ListScheduleBlocksResponse, error)
+	// GetScheduleBlock busca um horário bloqueado pelo endereço no calendário
+	GetScheduleBlock(ctx context.Context, in *GetScheduleBlockRequest, opts ...grpc.CallOption) (*ScheduleBlock, error)
+	// SaveScheduleBlock bloqueia um horário, ou move o bloqueio de mesmo endereço
+	SaveScheduleBlock(ctx context.Context, in *ScheduleBlock, opts ...grpc.CallOption) (*ScheduleBlock, error)
+	// DeleteScheduleBlock libera um horário bloqueado
+	DeleteScheduleBlock(ctx context.Context, in *DeleteScheduleBlockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchAppointments envia as alterações de consultas à medida que são publicadas. Um cliente que
+	// não acompanha o ritmo dos eventos tem o stream encerrado com RESOURCE_EXHAUSTED.
+	WatchAppointments(ctx context.Context, in *WatchAppointmentsRequest, opts ...grpc.CallOption) (AppointmentService_WatchAppointmentsClient, error)
+}
+
+type appointmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAppointmentServiceClient(cc grpc.ClientConnInterface) AppointmentServiceClient {
+	return &appointmentServiceClient{cc}
+}
+
+func (c *appointmentServiceClient) ListAppointments(ctx context.Context, in *ListAppointmentsRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error) {
+	out := new(ListAppointmentsResponse)
+	err := c.cc.Invoke(ctx, AppointmentService_ListAppointments_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) GetAppointment(ctx context.Context, in *GetAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error) {
+	out := new(Appointment)
+	err := c.cc.Invoke(ctx, AppointmentService_GetAppointment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) ListAppointmentsByPatient(ctx context.Context, in *ListAppointmentsByPatientRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error) {
+	out := new(ListAppointmentsResponse)
+	err := c.cc.Invoke(ctx, AppointmentService_ListAppointmentsByPatient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) ListAppointmentsByDentist(ctx context.Context, in *ListAppointmentsByDentistRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error) {
+	out := new(ListAppointmentsResponse)
+	err := c.cc.Invoke(ctx, AppointmentService_ListAppointmentsByDentist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) ListAppointmentsBetween(ctx context.Context, in *ListAppointmentsBetweenRequest, opts ...grpc.CallOption) (*ListAppointmentsResponse, error) {
+	out := new(ListAppointmentsResponse)
+	err := c.cc.Invoke(ctx, AppointmentService_ListAppointmentsBetween_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) CreateAppointment(ctx context.Context, in *CreateAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error) {
+	out := new(Appointment)
+	err := c.cc.Invoke(ctx, AppointmentService_CreateAppointment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) UpdateAppointment(ctx context.Context, in *UpdateAppointmentRequest, opts ...grpc.CallOption) (*Appointment, error) {
+	out := new(Appointment)
+	err := c.cc.Invoke(ctx, AppointmentService_UpdateAppointment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) DeleteAppointment(ctx context.Context, in *DeleteAppointmentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AppointmentService_DeleteAppointment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) ListScheduleBlocks(ctx context.Context, in *ListScheduleBlocksRequest, opts ...grpc.CallOption) (*ListScheduleBlocksResponse, error) {
+	out := new(ListScheduleBlocksResponse)
+	err := c.cc.Invoke(ctx, AppointmentService_ListScheduleBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) GetScheduleBlock(ctx context.Context, in *GetScheduleBlockRequest, opts ...grpc.CallOption) (*ScheduleBlock, error) {
+	out := new(ScheduleBlock)
+	err := c.cc.Invoke(ctx, AppointmentService_GetScheduleBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) SaveScheduleBlock(ctx context.Context, in *ScheduleBlock, opts ...grpc.CallOption) (*ScheduleBlock, error) {
+	out := new(ScheduleBlock)
+	err := c.cc.Invoke(ctx, AppointmentService_SaveScheduleBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) DeleteScheduleBlock(ctx context.Context, in *DeleteScheduleBlockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AppointmentService_DeleteScheduleBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appointmentServiceClient) WatchAppointments(ctx context.Context, in *WatchAppointmentsRequest, opts ...grpc.CallOption) (AppointmentService_WatchAppointmentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AppointmentService_ServiceDesc.Streams[0], AppointmentService_WatchAppointments_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &appointmentServiceWatchAppointmentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AppointmentService_WatchAppointmentsClient interface {
+	Recv() (*AppointmentEvent, error)
+	grpc.ClientStream
+}
+
+type appointmentServiceWatchAppointmentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *appointmentServiceWatchAppointmentsClient) Recv() (*AppointmentEvent, error) {
+	m := new(AppointmentEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AppointmentServiceServer is the server API for AppointmentService service.
+// All implementations must embed UnimplementedAppointmentServiceServer
+// for forward compatibility
+type AppointmentServiceServer interface {
+	// ListAppointments retorna todas as consultas
+	ListAppointments(context.Context, *ListAppointmentsRequest) (*ListAppointmentsResponse, error)
+	// GetAppointment busca uma consulta pelo id
+	GetAppointment(context.Context, *GetAppointmentRequest) (*Appointment, error)
+	// ListAppointmentsByPatient lista as consultas de um paciente pelo documento
+	ListAppointmentsByPatient(context.Context, *ListAppointmentsByPatientRequest) (*ListAppointmentsResponse, error)
+	// ListAppointmentsByDentist lista as consultas de um dentista pelo CRO
+	ListAppointmentsByDentist(context.Context, *ListAppointmentsByDentistRequest) (*ListAppointmentsResponse, error)
+	// ListAppointmentsBetween lista as consultas marcadas no período, sem dentista e paciente
+	ListAppointmentsBetween(context.Context, *ListAppointmentsBetweenRequest) (*ListAppointmentsResponse, error)
+	// CreateAppointment agenda uma consulta
+	CreateAppointment(context.Context, *CreateAppointmentRequest) (*Appointment, error)
+	// UpdateAppointment substitui uma consulta
+	UpdateAppointment(context.Context, *UpdateAppointmentRequest) (*Appointment, error)
+	// DeleteAppointment remove uma consulta
+	DeleteAppointment(context.Context, *DeleteAppointmentRequest) (*emptypb.Empty, error)
+	// ListScheduleBlocks lista os horários bloqueados na agenda de um dentista
+	ListScheduleBlocks(context.Context, *ListScheduleBlocksRequest) (*ListScheduleBlocksResponse, error)
+	// GetScheduleBlock busca um horário bloqueado pelo endereço no calendário
+	GetScheduleBlock(context.Context, *GetScheduleBlockRequest) (*ScheduleBlock, error)
+	// SaveScheduleBlock bloqueia um horário, ou move o bloqueio de mesmo endereço
+	SaveScheduleBlock(context.Context, *ScheduleBlock) (*ScheduleBlock, error)
+	// DeleteScheduleBlock libera um horário bloqueado
+	DeleteScheduleBlock(context.Context, *DeleteScheduleBlockRequest) (*emptypb.Empty, error)
+	// WatchAppointments envia as alterações de consultas à medida que são publicadas. Um cliente que
+	// não acompanha o ritmo dos eventos tem o stream encerrado com RESOURCE_EXHAUSTED.
+	WatchAppointments(*WatchAppointmentsRequest, AppointmentService_WatchAppointmentsServer) error
+	mustEmbedUnimplementedAppointmentServiceServer()
+}
+
+// UnimplementedAppointmentServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAppointmentServiceServer struct {
+}
+
+func (UnimplementedAppointmentServiceServer) ListAppointments(context.Context, *ListAppointmentsRequest) (*ListAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppointments not implemented")
+}
+func (UnimplementedAppointmentServiceServer) GetAppointment(context.Context, *GetAppointmentRequest) (*Appointment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAppointment not implemented")
+}
+func (UnimplementedAppointmentServiceServer) ListAppointmentsByPatient(context.Context, *ListAppointmentsByPatientRequest) (*ListAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppointmentsByPatient not implemented")
+}
+func (UnimplementedAppointmentServiceServer) ListAppointmentsByDentist(context.Context, *ListAppointmentsByDentistRequest) (*ListAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppointmentsByDentist not implemented")
+}
+func (UnimplementedAppointmentServiceServer) ListAppointmentsBetween(context.Context, *ListAppointmentsBetweenRequest) (*ListAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppointmentsBetween not implemented")
+}
+func (UnimplementedAppointmentServiceServer) CreateAppointment(context.Context, *CreateAppointmentRequest) (*Appointment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAppointment not implemented")
+}
+func (UnimplementedAppointmentServiceServer) UpdateAppointment(context.Context, *UpdateAppointmentRequest) (*Appointment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAppointment not implemented")
+}
+func (UnimplementedAppointmentServiceServer) DeleteAppointment(context.Context, *DeleteAppointmentRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAppointment not implemented")
+}
+func (UnimplementedAppointmentServiceServer) ListScheduleBlocks(context.Context, *ListScheduleBlocksRequest) (*ListScheduleBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduleBlocks not implemented")
+}
+func (UnimplementedAppointmentServiceServer) GetScheduleBlock(context.Context, *GetScheduleBlockRequest) (*ScheduleBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScheduleBlock not implemented")
+}
+func (UnimplementedAppointmentServiceServer) SaveScheduleBlock(context.Context, *ScheduleBlock) (*ScheduleBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveScheduleBlock not implemented")
+}
+func (UnimplementedAppointmentServiceServer) DeleteScheduleBlock(context.Context, *DeleteScheduleBlockRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteScheduleBlock not implemented")
+}
+func (UnimplementedAppointmentServiceServer) WatchAppointments(*WatchAppointmentsRequest, AppointmentService_WatchAppointmentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAppointments not implemented")
+}
+func (UnimplementedAppointmentServiceServer) mustEmbedUnimplementedAppointmentServiceServer() {}
+
+// UnsafeAppointmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AppointmentServiceServer will
+// result in compilation errors.
+type UnsafeAppointmentServiceServer interface {
+	mustEmbedUnimplementedAppointmentServiceServer()
+}
+
+func RegisterAppointmentServiceServer(s grpc.ServiceRegistrar, srv AppointmentServiceServer) {
+	s.RegisterService(&AppointmentService_ServiceDesc, srv)
+}
+
+func _AppointmentService_ListAppointments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppointmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).ListAppointments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_ListAppointments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).ListAppointments(ctx, req.(*ListAppointmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_GetAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).GetAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_GetAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).GetAppointment(ctx, req.(*GetAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_ListAppointmentsByPatient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppointmentsByPatientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).ListAppointmentsByPatient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_ListAppointmentsByPatient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).ListAppointmentsByPatient(ctx, req.(*ListAppointmentsByPatientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_ListAppointmentsByDentist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppointmentsByDentistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).ListAppointmentsByDentist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_ListAppointmentsByDentist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).ListAppointmentsByDentist(ctx, req.(*ListAppointmentsByDentistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_ListAppointmentsBetween_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppointmentsBetweenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).ListAppointmentsBetween(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_ListAppointmentsBetween_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).ListAppointmentsBetween(ctx, req.(*ListAppointmentsBetweenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_CreateAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).CreateAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_CreateAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).CreateAppointment(ctx, req.(*CreateAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_UpdateAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).UpdateAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_UpdateAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).UpdateAppointment(ctx, req.(*UpdateAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_DeleteAppointment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAppointmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).DeleteAppointment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_DeleteAppointment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).DeleteAppointment(ctx, req.(*DeleteAppointmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_ListScheduleBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduleBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).ListScheduleBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_ListScheduleBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).ListScheduleBlocks(ctx, req.(*ListScheduleBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_GetScheduleBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScheduleBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).GetScheduleBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_GetScheduleBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).GetScheduleBlock(ctx, req.(*GetScheduleBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_SaveScheduleBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleBlock)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).SaveScheduleBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_SaveScheduleBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).SaveScheduleBlock(ctx, req.(*ScheduleBlock))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_DeleteScheduleBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppointmentServiceServer).DeleteScheduleBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AppointmentService_DeleteScheduleBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppointmentServiceServer).DeleteScheduleBlock(ctx, req.(*DeleteScheduleBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppointmentService_WatchAppointments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAppointmentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AppointmentServiceServer).WatchAppointments(m, &appointmentServiceWatchAppointmentsServer{stream})
+}
+
+type AppointmentService_WatchAppointmentsServer interface {
+	Send(*AppointmentEvent) error
+	grpc.ServerStream
+}
+
+type appointmentServiceWatchAppointmentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *appointmentServiceWatchAppointmentsServer) Send(m *AppointmentEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AppointmentService_ServiceDesc is the grpc.ServiceDesc for AppointmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AppointmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clinic.v1.AppointmentService",
+	HandlerType: (*AppointmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAppointments",
+			Handler:    _AppointmentService_ListAppointments_Handler,
+		},
+		{
+			MethodName: "GetAppointment",
+			Handler:    _AppointmentService_GetAppointment_Handler,
+		},
+		{
+			MethodName: "ListAppointmentsByPatient",
+			Handler:    _AppointmentService_ListAppointmentsByPatient_Handler,
+		},
+		{
+			MethodName: "ListAppointmentsByDentist",
+			Handler:    _AppointmentService_ListAppointmentsByDentist_Handler,
+		},
+		{
+			MethodName: "ListAppointmentsBetween",
+			Handler:    _AppointmentService_ListAppointmentsBetween_Handler,
+		},
+		{
+			MethodName: "CreateAppointment",
+			Handler:    _AppointmentService_CreateAppointment_Handler,
+		},
+		{
+			MethodName: "UpdateAppointment",
+			Handler:    _AppointmentService_UpdateAppointment_Handler,
+		},
+		{
+			MethodName: "DeleteAppointment",
+			Handler:    _AppointmentService_DeleteAppointment_Handler,
+		},
+		{
+			MethodName: "ListScheduleBlocks",
+			Handler:    _AppointmentService_ListScheduleBlocks_Handler,
+		},
+		{
+			MethodName: "GetScheduleBlock",
+			Handler:    _AppointmentService_GetScheduleBlock_Handler,
+		},
+		{
+			MethodName: "SaveScheduleBlock",
+			Handler:    _AppointmentService_SaveScheduleBlock_Handler,
+		},
+		{
+			MethodName: "DeleteScheduleBlock",
+			Handler:    _AppointmentService_DeleteScheduleBlock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAppointments",
+			Handler:       _AppointmentService_WatchAppointments_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "clinic.proto",
+}
+
+const (
+	DentistService_ListDentists_FullMethodName  = "/clinic.v1.DentistService/ListDentists"
+	DentistService_GetDentist_FullMethodName    = "/clinic.v1.DentistService/GetDentist"
+	DentistService_CreateDentist_FullMethodName = "/clinic.v1.DentistService/CreateDentist"
+	DentistService_UpdateDentist_FullMethodName = "/clinic.v1.DentistService/UpdateDentist"
+	DentistService_DeleteDentist_FullMethodName = "/clinic.v1.DentistService/DeleteDentist"
+	DentistService_BatchDentists_FullMethodName = "/clinic.v1.DentistService/BatchDentists"
+)
+
+// DentistServiceClient is the client API for DentistService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DentistServiceClient interface {
+	ListDentists(ctx context.Context, in *ListDentistsRequest, opts ...grpc.CallOption) (*ListDentistsResponse, error)
+	GetDentist(ctx context.Context, in *GetDentistRequest, opts ...grpc.CallOption) (*Dentist, error)
+	CreateDentist(ctx context.Context, in *CreateDentistRequest, opts ...grpc.CallOption) (*Dentist, error)
+	UpdateDentist(ctx context.Context, in *UpdateDentistRequest, opts ...grpc.CallOption) (*Dentist, error)
+	DeleteDentist(ctx context.Context, in *DeleteDentistRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// BatchDentists grava os dentistas numa única transação: inclui os sem id e atualiza os demais
+	BatchDentists(ctx context.Context, in *BatchDentistsRequest, opts ...grpc.CallOption) (*ListDentistsResponse, error)
+}
+
+type dentistServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDentistServiceClient(cc grpc.ClientConnInterface) DentistServiceClient {
+	return &dentistServiceClient{cc}
+}
+
+func (c *dentistServiceClient) ListDentists(ctx context.Context, in *ListDentistsRequest, opts ...grpc.CallOption) (*ListDentistsResponse, error) {
+	out := new(ListDentistsResponse)
+	err := c.cc.Invoke(ctx, DentistService_ListDentists_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dentistServiceClient) GetDentist(ctx context.Context, in *GetDentistRequest, opts ...grpc.CallOption) (*Dentist, error) {
+	out := new(Dentist)
+	err := c.cc.Invoke(ctx, DentistService_GetDentist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dentistServiceClient) CreateDentist(ctx context.Context, in *CreateDentistRequest, opts ...grpc.CallOption) (*Dentist, error) {
+	out := new(Dentist)
+	err := c.cc.Invoke(ctx, DentistService_CreateDentist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dentistServiceClient) UpdateDentist(ctx context.Context, in *UpdateDentistRequest, opts ...grpc.CallOption) (*Dentist, error) {
+	out := new(Dentist)
+	err := c.cc.Invoke(ctx, DentistService_UpdateDentist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dentistServiceClient) DeleteDentist(ctx context.Context, in *DeleteDentistRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, DentistService_DeleteDentist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dentistServiceClient) BatchDentists(ctx context.Context, in *BatchDentistsRequest, opts ...grpc.CallOption) (*ListDentistsResponse, error) {
+	out := new(ListDentistsResponse)
+	err := c.cc.Invoke(ctx, DentistService_BatchDentists_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DentistServiceServer is the server API for DentistService service.
+// All implementations must embed UnimplementedDentistServiceServer
+// for forward compatibility
+type DentistServiceServer interface {
+	ListDentists(context.Context, *ListDentistsRequest) (*ListDentistsResponse, error)
+	GetDentist(context.Context, *GetDentistRequest) (*Dentist, error)
+	CreateDentist(context.Context, *CreateDentistRequest) (*Dentist, error)
+	UpdateDentist(context.Context, *UpdateDentistRequest) (*Dentist, error)
+	DeleteDentist(context.Context, *DeleteDentistRequest) (*emptypb.Empty, error)
+	// BatchDentists grava os dentistas numa única transação: inclui os sem id e atualiza os demais
+	BatchDentists(context.Context, *BatchDentistsRequest) (*ListDentistsResponse, error)
+	mustEmbedUnimplementedDentistServiceServer()
+}
+
+// UnimplementedDentistServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDentistServiceServer struct {
+}
+
+func (UnimplementedDentistServiceServer) ListDentists(context.Context, *ListDentistsRequest) (*ListDentistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDentists not implemented")
+}
+func (UnimplementedDentistServiceServer) GetDentist(context.Context, *GetDentistRequest) (*Dentist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDentist not implemented")
+}
+func (UnimplementedDentistServiceServer) CreateDentist(context.Context, *CreateDentistRequest) (*Dentist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDentist not implemented")
+}
+func (UnimplementedDentistServiceServer) UpdateDentist(context.Context, *UpdateDentistRequest) (*Dentist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDentist not implemented")
+}
+func (UnimplementedDentistServiceServer) DeleteDentist(context.Context, *DeleteDentistRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDentist not implemented")
+}
+func (UnimplementedDentistServiceServer) BatchDentists(context.Context, *BatchDentistsRequest) (*ListDentistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDentists not implemented")
+}
+func (UnimplementedDentistServiceServer) mustEmbedUnimplementedDentistServiceServer() {}
+
+// UnsafeDentistServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DentistServiceServer will
+// result in compilation errors.
+type UnsafeDentistServiceServer interface {
+	mustEmbedUnimplementedDentistServiceServer()
+}
+
+func RegisterDentistServiceServer(s grpc.ServiceRegistrar, srv DentistServiceServer) {
+	s.RegisterService(&DentistService_ServiceDesc, srv)
+}
+
+func _DentistService_ListDentists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDentistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).ListDentists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_ListDentists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).ListDentists(ctx, req.(*ListDentistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DentistService_GetDentist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDentistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).GetDentist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_GetDentist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).GetDentist(ctx, req.(*GetDentistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DentistService_CreateDentist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDentistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).CreateDentist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_CreateDentist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).CreateDentist(ctx, req.(*CreateDentistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DentistService_UpdateDentist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDentistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).UpdateDentist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_UpdateDentist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).UpdateDentist(ctx, req.(*UpdateDentistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DentistService_DeleteDentist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDentistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).DeleteDentist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_DeleteDentist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).DeleteDentist(ctx, req.(*DeleteDentistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DentistService_BatchDentists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDentistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DentistServiceServer).BatchDentists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DentistService_BatchDentists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DentistServiceServer).BatchDentists(ctx, req.(*BatchDentistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DentistService_ServiceDesc is the grpc.ServiceDesc for DentistService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DentistService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clinic.v1.DentistService",
+	HandlerType: (*DentistServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDentists",
+			Handler:    _DentistService_ListDentists_Handler,
+		},
+		{
+			MethodName: "GetDentist",
+			Handler:    _DentistService_GetDentist_Handler,
+		},
+		{
+			MethodName: "CreateDentist",
+			Handler:    _DentistService_CreateDentist_Handler,
+		},
+		{
+			MethodName: "UpdateDentist",
+			Handler:    _DentistService_UpdateDentist_Handler,
+		},
+		{
+			MethodName: "DeleteDentist",
+			Handler:    _DentistService_DeleteDentist_Handler,
+		},
+		{
+			MethodName: "BatchDentists",
+			Handler:    _DentistService_BatchDentists_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "clinic.proto",
+}
+
+const (
+	PatientService_ListPatients_FullMethodName  = "/clinic.v1.PatientService/ListPatients"
+	PatientService_GetPatient_FullMethodName    = "/clinic.v1.PatientService/GetPatient"
+	PatientService_CreatePatient_FullMethodName = "/clinic.v1.PatientService/CreatePatient"
+	PatientService_UpdatePatient_FullMethodName = "/clinic.v1.PatientService/UpdatePatient"
+	PatientService_DeletePatient_FullMethodName = "/clinic.v1.PatientService/DeletePatient"
+	PatientService_BatchPatients_FullMethodName = "/clinic.v1.PatientService/BatchPatients"
+)
+
+// PatientServiceClient is the client API for PatientService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PatientServiceClient interface {
+	ListPatients(ctx context.Context, in *ListPatientsRequest, opts ...grpc.CallOption) (*ListPatientsResponse, error)
+	GetPatient(ctx context.Context, in *GetPatientRequest, opts ...grpc.CallOption) (*Patient, error)
+	CreatePatient(ctx context.Context, in *CreatePatientRequest, opts ...grpc.CallOption) (*Patient, error)
+	UpdatePatient(ctx context.Context, in *UpdatePatientRequest, opts ...grpc.CallOption) (*Patient, error)
+	DeletePatient(ctx context.Context, in *DeletePatientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// BatchPatients grava os pacientes numa única transação: inclui os sem id e atualiza os demais
+	BatchPatients(ctx context.Context, in *BatchPatientsRequest, opts ...grpc.CallOption) (*ListPatientsResponse, error)
+}
+
+type patientServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPatientServiceClient(cc grpc.ClientConnInterface) PatientServiceClient {
+	return &patientServiceClient{cc}
+}
+
+func (c *patientServiceClient) ListPatients(ctx context.Context, in *ListPatientsRequest, opts ...grpc.CallOption) (*ListPatientsResponse, error) {
+	out := new(ListPatientsResponse)
+	err := c.cc.Invoke(ctx, PatientService_ListPatients_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *patientServiceClient) GetPatient(ctx context.Context, in *GetPatientRequest, opts ...grpc.CallOption) (*Patient, error) {
+	out := new(Patient)
+	err := c.cc.Invoke(ctx, PatientService_GetPatient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *patientServiceClient) CreatePatient(ctx context.Context, in *CreatePatientRequest, opts ...grpc.CallOption) (*Patient, error) {
+	out := new(Patient)
+	err := c.cc.Invoke(ctx, PatientService_CreatePatient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *patientServiceClient) UpdatePatient(ctx context.Context, in *UpdatePatientRequest, opts ...grpc.CallOption) (*Patient, error) {
+	out := new(Patient)
+	err := c.cc.Invoke(ctx, PatientService_UpdatePatient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *patientServiceClient) DeletePatient(ctx context.Context, in *DeletePatientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PatientService_DeletePatient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *patientServiceClient) BatchPatients(ctx context.Context, in *BatchPatientsRequest, opts ...grpc.CallOption) (*ListPatientsResponse, error) {
+	out := new(ListPatientsResponse)
+	err := c.cc.Invoke(ctx, PatientService_BatchPatients_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PatientServiceServer is the server API for PatientService service.
+// All implementations must embed UnimplementedPatientServiceServer
+// for forward compatibility
+type PatientServiceServer interface {
+	ListPatients(context.Context, *ListPatientsRequest) (*ListPatientsResponse, error)
+	GetPatient(context.Context, *GetPatientRequest) (*Patient, error)
+	CreatePatient(context.Context, *CreatePatientRequest) (*Patient, error)
+	UpdatePatient(context.Context, *UpdatePatientRequest) (*Patient, error)
+	DeletePatient(context.Context, *DeletePatientRequest) (*emptypb.Empty, error)
+	// BatchPatients grava os pacientes numa única transação: inclui os sem id e atualiza os demais
+	BatchPatients(context.Context, *BatchPatientsRequest) (*ListPatientsResponse, error)
+	mustEmbedUnimplementedPatientServiceServer()
+}
+
+// UnimplementedPatientServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPatientServiceServer struct {
+}
+
+func (UnimplementedPatientServiceServer) ListPatients(context.Context, *ListPatientsRequest) (*ListPatientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPatients not implemented")
+}
+func (UnimplementedPatientServiceServer) GetPatient(context.Context, *GetPatientRequest) (*Patient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPatient not implemented")
+}
+func (UnimplementedPatientServiceServer) CreatePatient(context.Context, *CreatePatientRequest) (*Patient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePatient not implemented")
+}
+func (UnimplementedPatientServiceServer) UpdatePatient(context.Context, *UpdatePatientRequest) (*Patient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePatient not implemented")
+}
+func (UnimplementedPatientServiceServer) DeletePatient(context.Context, *DeletePatientRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePatient not implemented")
+}
+func (UnimplementedPatientServiceServer) BatchPatients(context.Context, *BatchPatientsRequest) (*ListPatientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchPatients not implemented")
+}
+func (UnimplementedPatientServiceServer) mustEmbedUnimplementedPatientServiceServer() {}
+
+// UnsafePatientServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PatientServiceServer will
+// result in compilation errors.
+type UnsafePatientServiceServer interface {
+	mustEmbedUnimplementedPatientServiceServer()
+}
+
+func RegisterPatientServiceServer(s grpc.ServiceRegistrar, srv PatientServiceServer) {
+	s.RegisterService(&PatientService_ServiceDesc, srv)
+}
+
+func _PatientService_ListPatients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPatientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).ListPatients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_ListPatients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).ListPatients(ctx, req.(*ListPatientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PatientService_GetPatient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPatientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).GetPatient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_GetPatient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).GetPatient(ctx, req.(*GetPatientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PatientService_CreatePatient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePatientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).CreatePatient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_CreatePatient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).CreatePatient(ctx, req.(*CreatePatientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PatientService_UpdatePatient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePatientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).UpdatePatient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_UpdatePatient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).UpdatePatient(ctx, req.(*UpdatePatientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PatientService_DeletePatient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePatientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).DeletePatient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_DeletePatient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).DeletePatient(ctx, req.(*DeletePatientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PatientService_BatchPatients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPatientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PatientServiceServer).BatchPatients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PatientService_BatchPatients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PatientServiceServer).BatchPatients(ctx, req.(*BatchPatientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PatientService_ServiceDesc is the grpc.ServiceDesc for PatientService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PatientService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clinic.v1.PatientService",
+	HandlerType: (*PatientServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPatients",
+			Handler:    _PatientService_ListPatients_Handler,
+		},
+		{
+			MethodName: "GetPatient",
+			Handler:    _PatientService_GetPatient_Handler,
+		},
+		{
+			MethodName: "CreatePatient",
+			Handler:    _PatientService_CreatePatient_Handler,
+		},
+		{
+			MethodName: "UpdatePatient",
+			Handler:    _PatientService_UpdatePatient_Handler,
+		},
+		{
+			MethodName: "DeletePatient",
+			Handler:    _PatientService_DeletePatient_Handler,
+		},
+		{
+			MethodName: "BatchPatients",
+			Handler:    _PatientService_BatchPatients_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "clinic.proto",
+}
@@ -0,0 +1,5 @@
+// Package clinicpb contém as mensagens e os serviços gRPC gerados de clinic.proto. Para gerar de
+// novo, com protoc, protoc-gen-go e protoc-gen-go-grpc instalados, rode go generate neste pacote.
+package clinicpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative clinic.proto
@@ -0,0 +1,179 @@
+// Package graphql é uma implementação enxuta de GraphQL para a API: interpreta consultas,
+// confere com o esquema, limita profundidade e complexidade e executa os resolvers nível a nível,
+// de modo que os Loader agrupem as buscas de um mesmo nível numa única consulta ao banco.
+// Só operações de leitura (query) são aceitas.
+package graphql
+
+import (
+	"sort"
+	"strings"
+)
+
+// Location é a posição de um trecho da consulta, com linha e coluna contadas a partir de 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Document é uma consulta interpretada
+type Document struct {
+	Operations []*OperationDefinition
+	Fragments  []*FragmentDefinition
+}
+
+// fragment retorna o fragmento com o nome informado
+func (d *Document) fragment(name string) *FragmentDefinition {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// OperationDefinition é uma operação do documento, como query Agenda($id: ID!) { ... }
+type OperationDefinition struct {
+	// Type é query, mutation ou subscription
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// VariableDefinition declara uma variável da operação
+type VariableDefinition struct {
+	Name       string
+	Type       *TypeRef
+	Default    interface{}
+	HasDefault bool
+	Loc        Location
+}
+
+// TypeRef é a referência a um tipo na declaração de variáveis, como [ID!]!
+type TypeRef struct {
+	// Name é o nome do tipo; vazio nas listas, descritas por Elem
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection é um campo, um fragmento nomeado ou um fragmento em linha
+type Selection interface {
+	location() Location
+}
+
+// FieldSelection é um campo selecionado, com apelido, argumentos e subcampos
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// ResponseKey é o nome do campo na resposta: o apelido, se houver
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread é o uso de um fragmento nomeado, como ...DadosDoPaciente
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+// InlineFragment é um fragmento em linha, como ... on Patient { ... }
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+// FragmentDefinition é a definição de um fragmento nomeado
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+// Directive é uma diretiva como @include(if: $detalhes)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+// Argument é um argumento de campo ou de diretiva
+type Argument struct {
+	Name  string
+	Value interface{}
+	Loc   Location
+}
+
+func (f *FieldSelection) location() Location { return f.Loc }
+func (f *FragmentSpread) location() Location { return f.Loc }
+func (f *InlineFragment) location() Location { return f.Loc }
+
+// Valores literais da consulta. Textos, booleanos e null são representados por string, bool e nil;
+// listas por []interface{} e objetos por map[string]interface{}.
+type (
+	// Variable é a referência a uma variável, sem o $
+	Variable string
+	// EnumLiteral é um valor de enum escrito na consulta
+	EnumLiteral string
+	// IntLiteral é um número inteiro escrito na consulta
+	IntLiteral string
+	// FloatLiteral é um número com parte decimal ou expoente escrito na consulta
+	FloatLiteral string
+)
+
+// printLiteral escreve o valor na notação da linguagem, para mensagens de erro
+func printLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case Variable:
+		return "$" + string(v)
+	case EnumLiteral:
+		return string(v)
+	case IntLiteral:
+		return string(v)
+	case FloatLiteral:
+		return string(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = printLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		fields := make([]string, 0, len(v))
+		for name, item := range v {
+			fields = append(fields, name+": "+printLiteral(item))
+		}
+		sort.Strings(fields)
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return inspect(v)
+}
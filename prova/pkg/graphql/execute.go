@@ -0,0 +1,436 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// Request é uma requisição GraphQL, no formato usado sobre HTTP
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	// Extensions é aceito para compatibilidade com os clientes que o enviam, mas é ignorado
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Response é o resultado da execução. Data fica ausente quando a consulta é recusada antes da
+// execução e é null quando um erro anula a raiz.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+// Error é um erro da consulta, com a posição no texto e o caminho do campo na resposta
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap retorna o erro devolvido pelo resolver, se houver
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Execute interpreta, confere e executa a consulta. Os erros fazem parte da resposta; as
+// variáveis vêm do JSON e podem usar números json.Number ou float64.
+func (s *Schema) Execute(ctx context.Context, req Request, opts Options) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+	variables, errs := s.variables(op, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if errs := s.validate(doc, op, variables, opts); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{
+		ctx:       context.WithValue(ctx, schemaKey{}, s),
+		doc:       doc,
+		variables: variables,
+	}
+	root := e.object(s.query, nil, nil, nil, nil, op.SelectionSet)
+	e.run()
+
+	var data interface{} = root
+	if root.dead {
+		data = nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return &Response{Errors: append(e.errs, &Error{Message: "can't encode the response: " + err.Error()})}
+	}
+	return &Response{Data: raw, Errors: e.errs}
+}
+
+// operation escolhe a operação a executar
+func (d *Document) operation(name string) (*OperationDefinition, error) {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0], nil
+		}
+		if len(d.Operations) == 0 {
+			return nil, &Error{Message: "Must provide an operation."}
+		}
+		return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf(`Unknown operation named "%s".`, name)}
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error(), err: err}
+}
+
+// executor resolve os campos nível a nível: todos os campos de um nível são resolvidos, depois os
+// Thunk devolvidos são chamados e só então os subcampos são enfileirados para o nível seguinte
+type executor struct {
+	ctx       context.Context
+	doc       *Document
+	variables map[string]interface{}
+	queue     []*task
+	errs      []*Error
+}
+
+// task é um campo a resolver sobre um objeto
+type task struct {
+	object *Object
+	source interface{}
+	def    *Field
+	nodes  []*FieldSelection
+	key    string
+	result *result
+	path   []interface{}
+
+	value interface{}
+	err   error
+}
+
+func (e *executor) run() {
+	for len(e.queue) > 0 {
+		level := e.queue
+		e.queue = nil
+		live := level[:0]
+		for _, t := range level {
+			if t.result.alive() {
+				live = append(live, t)
+			}
+		}
+		for _, t := range live {
+			e.resolve(t)
+		}
+		for _, t := range live {
+			for t.err == nil {
+				thunk, ok := t.value.(Thunk)
+				if !ok {
+					break
+				}
+				t.value, t.err = e.call(t, thunk)
+			}
+		}
+		for _, t := range live {
+			e.complete(t)
+		}
+	}
+}
+
+func (e *executor) resolve(t *task) {
+	args, err := arguments(t.def.Args, t.nodes[0].Arguments, e.variables)
+	if err != nil {
+		t.err = err
+		return
+	}
+	resolve := t.def.Resolve
+	if resolve == nil {
+		resolve = DefaultResolve
+	}
+	t.value, t.err = e.call(t, func() (interface{}, error) {
+		return resolve(ResolveParams{
+			Context: e.ctx,
+			Source:  t.source,
+			Args:    args,
+			Info:    ResolveInfo{FieldName: t.def.Name, ParentType: t.object, Path: t.path},
+		})
+	})
+}
+
+// call chama o resolver ou o Thunk, convertendo os panics em erro do campo
+func (e *executor) call(t *task, fn func() (interface{}, error)) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("graphql: panic resolving %s.%s: %v", t.object.Name, t.def.Name, r)
+			value, err = nil, fmt.Errorf("internal error resolving %s.%s", t.object.Name, t.def.Name)
+		}
+	}()
+	return fn()
+}
+
+func (e *executor) complete(t *task) {
+	nonNull := isNonNull(t.def.Type)
+	if t.err != nil {
+		e.fieldError(t.nodes, t.path, t.err)
+		e.nullField(t, nonNull)
+		return
+	}
+	nullify := func() bool {
+		t.result.values[t.key] = nil
+		return nonNull
+	}
+	value, ok := e.value(t.def.Type, t.object, t.def, t.nodes, t.value, t.path, t.result, nullify)
+	if !ok {
+		e.nullField(t, nonNull)
+		return
+	}
+	t.result.values[t.key] = value
+}
+
+// nullField anula o campo, ou o objeto inteiro quando o campo não aceita nulo
+func (e *executor) nullField(t *task, nonNull bool) {
+	if nonNull {
+		t.result.fail()
+		return
+	}
+	t.result.values[t.key] = nil
+}
+
+func (e *executor) fieldError(nodes []*FieldSelection, path []interface{}, err error) {
+	locations := make([]Location, len(nodes))
+	for i, n := range nodes {
+		locations[i] = n.Loc
+	}
+	e.errs = append(e.errs, &Error{Message: err.Error(), Locations: locations, Path: path, err: err})
+}
+
+// value completa o valor devolvido pelo resolver conforme o tipo. Retorna false quando o valor
+// fica nulo por causa de um erro já registrado; quem chamou decide se o nulo sobe ao objeto de
+// cima. nullify anula a posição do valor na resposta e informa se o objeto de cima também precisa
+// ser anulado; é guardada nos objetos, cujos campos só são completados nos níveis seguintes.
+func (e *executor) value(t Type, parent *Object, def *Field, nodes []*FieldSelection, value interface{}, path []interface{}, owner *result, nullify func() bool) (interface{}, bool) {
+	if n, ok := t.(*NonNull); ok {
+		v, ok := e.value(n.Of, parent, def, nodes, value, path, owner, nullify)
+		if !ok {
+			return nil, false
+		}
+		if v == nil {
+			e.fieldError(nodes, path, fmt.Errorf(`Cannot return null for non-nullable field %s.%s.`, parent.Name, def.Name))
+			return nil, false
+		}
+		return v, true
+	}
+	if isNil(value) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		v, err := t.Serialize(value)
+		if err != nil {
+			e.fieldError(nodes, path, err)
+			return nil, false
+		}
+		return v, true
+	case *Enum:
+		for _, v := range t.Values {
+			if reflect.DeepEqual(v.value(), value) {
+				return v.Name, true
+			}
+		}
+		e.fieldError(nodes, path, fmt.Errorf(`Enum "%s" cannot represent value: %s`, t.Name, inspect(value)))
+		return nil, false
+	case *List:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fieldError(nodes, path, fmt.Errorf(`Expected Iterable, but did not find one for field "%s.%s".`, parent.Name, def.Name))
+			return nil, false
+		}
+		list := make([]interface{}, rv.Len())
+		itemNonNull := isNonNull(t.Of)
+		for i := range list {
+			i := i
+			itemPath := append(append(make([]interface{}, 0, len(path)+1), path...), i)
+			itemNullify := func() bool {
+				list[i] = nil
+				if itemNonNull {
+					return nullify()
+				}
+				return false
+			}
+			item, ok := e.value(t.Of, parent, def, nodes, rv.Index(i).Interface(), itemPath, owner, itemNullify)
+			if !ok {
+				if itemNonNull {
+					return nil, false
+				}
+				item = nil
+			}
+			list[i] = item
+		}
+		return list, true
+	case *Object:
+		var selections [][]Selection
+		for _, n := range nodes {
+			selections = append(selections, n.SelectionSet)
+		}
+		return e.object(t, value, path, owner, nullify, selections...), true
+	}
+	e.fieldError(nodes, path, fmt.Errorf("unexpected type %s", t))
+	return nil, false
+}
+
+// object cria o resultado do objeto e enfileira os campos selecionados para o próximo nível
+func (e *executor) object(t *Object, source interface{}, path []interface{}, owner *result, nullify func() bool, selections ...[]Selection) *result {
+	var groups []*group
+	index := map[string]*group{}
+	for _, s := range selections {
+		e.collect(t, s, &groups, index, map[string]bool{})
+	}
+
+	r := &result{values: make(map[string]interface{}, len(groups)), owner: owner, nullify: nullify}
+	for _, g := range groups {
+		r.keys = append(r.keys, g.key)
+		r.values[g.key] = nil
+		e.queue = append(e.queue, &task{
+			object: t,
+			source: source,
+			def:    t.field(g.nodes[0].Name),
+			nodes:  g.nodes,
+			key:    g.key,
+			result: r,
+			path:   append(append(make([]interface{}, 0, len(path)+1), path...), g.key),
+		})
+	}
+	return r
+}
+
+// group reúne os campos selecionados com o mesmo nome na resposta
+type group struct {
+	key   string
+	nodes []*FieldSelection
+}
+
+// collect reúne os campos da seleção, incluindo os dos fragmentos, respeitando @skip e @include
+func (e *executor) collect(t *Object, selections []Selection, groups *[]*group, index map[string]*group, visited map[string]bool) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			if !included(s.Directives, e.variables) {
+				continue
+			}
+			key := s.ResponseKey()
+			g, ok := index[key]
+			if !ok {
+				g = &group{key: key}
+				index[key] = g
+				*groups = append(*groups, g)
+			}
+			g.nodes = append(g.nodes, s)
+		case *FragmentSpread:
+			if visited[s.Name] || !included(s.Directives, e.variables) {
+				continue
+			}
+			visited[s.Name] = true
+			f := e.doc.fragment(s.Name)
+			if f == nil || f.TypeCondition != t.Name {
+				continue
+			}
+			e.collect(t, f.SelectionSet, groups, index, visited)
+		case *InlineFragment:
+			if !included(s.Directives, e.variables) || (s.TypeCondition != "" && s.TypeCondition != t.Name) {
+				continue
+			}
+			e.collect(t, s.SelectionSet, groups, index, visited)
+		}
+	}
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// result é um objeto da resposta, com os campos na ordem da seleção
+type result struct {
+	keys   []string
+	values map[string]interface{}
+	// owner é o objeto que contém este, direta ou indiretamente por listas; nullify anula a
+	// posição deste objeto e informa se o owner também precisa ser anulado
+	owner   *result
+	nullify func() bool
+	dead    bool
+}
+
+// fail anula o objeto por um campo não nulo que ficou nulo, subindo enquanto as posições não
+// aceitarem nulo. Sem posição, o objeto é a raiz e a resposta fica com data null.
+func (r *result) fail() {
+	for r != nil && !r.dead {
+		r.dead = true
+		if r.nullify == nil || !r.nullify() {
+			return
+		}
+		r = r.owner
+	}
+}
+
+// alive indica se o objeto ainda faz parte da resposta
+func (r *result) alive() bool {
+	for ; r != nil; r = r.owner {
+		if r.dead {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *result) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(r.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,309 @@
+package graphql
+
+// directiveDefinition é uma diretiva aceita nas consultas
+type directiveDefinition struct {
+	name        string
+	description string
+	locations   []string
+	args        []*Arg
+}
+
+func (d *directiveDefinition) at(location string) bool {
+	for _, l := range d.locations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+var directiveArgs = []*Arg{{Name: "if", Type: NonNullOf(Boolean)}}
+
+var directives = []*directiveDefinition{
+	{
+		name:        "include",
+		description: "Inclui o campo ou fragmento só quando o argumento if é verdadeiro.",
+		locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:        directiveArgs,
+	},
+	{
+		name:        "skip",
+		description: "Omite o campo ou fragmento quando o argumento if é verdadeiro.",
+		locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:        directiveArgs,
+	},
+}
+
+func directive(name string) *directiveDefinition {
+	for _, d := range directives {
+		if d.name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Tipos da introspecção, com os campos que as ferramentas como o GraphiQL consultam
+var (
+	typeKindEnum = &Enum{
+		Name:        "__TypeKind",
+		Description: "Categorias de tipo.",
+		Values: []*EnumValue{
+			{Name: "SCALAR"}, {Name: "OBJECT"}, {Name: "INTERFACE"}, {Name: "UNION"},
+			{Name: "ENUM"}, {Name: "INPUT_OBJECT"}, {Name: "LIST"}, {Name: "NON_NULL"},
+		},
+	}
+	directiveLocationEnum = &Enum{
+		Name:        "__DirectiveLocation",
+		Description: "Locais em que uma diretiva pode ser usada.",
+		Values: []*EnumValue{
+			{Name: "QUERY"}, {Name: "MUTATION"}, {Name: "SUBSCRIPTION"}, {Name: "FIELD"},
+			{Name: "FRAGMENT_DEFINITION"}, {Name: "FRAGMENT_SPREAD"}, {Name: "INLINE_FRAGMENT"},
+			{Name: "VARIABLE_DEFINITION"}, {Name: "SCHEMA"}, {Name: "SCALAR"}, {Name: "OBJECT"},
+			{Name: "FIELD_DEFINITION"}, {Name: "ARGUMENT_DEFINITION"}, {Name: "INTERFACE"},
+			{Name: "UNION"}, {Name: "ENUM"}, {Name: "ENUM_VALUE"}, {Name: "INPUT_OBJECT"},
+			{Name: "INPUT_FIELD_DEFINITION"},
+		},
+	}
+
+	schemaType     = &Object{Name: "__Schema", Description: "O esquema da API: tipos, tipo de consulta e diretivas."}
+	typeType       = &Object{Name: "__Type", Description: "Um tipo do esquema."}
+	fieldType      = &Object{Name: "__Field", Description: "Um campo de objeto."}
+	inputValueType = &Object{Name: "__InputValue", Description: "Um argumento."}
+	enumValueType  = &Object{Name: "__EnumValue", Description: "Um valor de enum."}
+	directiveType  = &Object{Name: "__Directive", Description: "Uma diretiva aceita nas consultas."}
+
+	schemaField = &Field{
+		Name:        "__schema",
+		Description: "Descreve o esquema.",
+		Type:        NonNullOf(schemaType),
+	}
+	typeField = &Field{
+		Name:        "__type",
+		Description: "Descreve o tipo com o nome informado.",
+		Type:        typeType,
+		Args:        []*Arg{{Name: "name", Type: NonNullOf(String)}},
+	}
+)
+
+// schemaKey guarda o esquema em execução no contexto, para os resolvers da introspecção
+type schemaKey struct{}
+
+func currentSchema(p ResolveParams) *Schema {
+	s, _ := p.Context.Value(schemaKey{}).(*Schema)
+	return s
+}
+
+// Os resolvers da introspecção recebem o valor de origem já convertido para o tipo descrito
+type (
+	schemaResolver    func(*Schema, ResolveParams) (interface{}, error)
+	typeResolver      func(Type, ResolveParams) (interface{}, error)
+	fieldResolver     func(*Field, ResolveParams) (interface{}, error)
+	argResolver       func(*Arg, ResolveParams) (interface{}, error)
+	enumValueResolver func(*EnumValue, ResolveParams) (interface{}, error)
+	directiveResolver func(*directiveDefinition, ResolveParams) (interface{}, error)
+)
+
+func (fn schemaResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(*Schema), p)
+}
+
+func (fn typeResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(Type), p)
+}
+
+func (fn fieldResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(*Field), p)
+}
+
+func (fn argResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(*Arg), p)
+}
+
+func (fn enumValueResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(*EnumValue), p)
+}
+
+func (fn directiveResolver) resolve(p ResolveParams) (interface{}, error) {
+	return fn(p.Source.(*directiveDefinition), p)
+}
+
+func init() {
+	includeDeprecated := []*Arg{{Name: "includeDeprecated", Type: Boolean, DefaultValue: false}}
+	nonNullString := NonNullOf(String)
+	nonNullBoolean := NonNullOf(Boolean)
+
+	schemaField.Resolve = func(p ResolveParams) (interface{}, error) {
+		return currentSchema(p), nil
+	}
+	typeField.Resolve = func(p ResolveParams) (interface{}, error) {
+		t := currentSchema(p).types[p.Args["name"].(string)]
+		if t == nil {
+			return nil, nil
+		}
+		return t, nil
+	}
+
+	schemaType.Fields = []*Field{
+		{Name: "description", Type: String, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "types", Type: NonNullOf(ListOf(NonNullOf(typeType))), Resolve: schemaResolver(func(s *Schema, _ ResolveParams) (interface{}, error) {
+			types := make([]Type, len(s.names))
+			for i, name := range s.names {
+				types[i] = s.types[name]
+			}
+			return types, nil
+		}).resolve},
+		{Name: "queryType", Type: NonNullOf(typeType), Resolve: schemaResolver(func(s *Schema, _ ResolveParams) (interface{}, error) {
+			return s.query, nil
+		}).resolve},
+		{Name: "mutationType", Type: typeType, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "subscriptionType", Type: typeType, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "directives", Type: NonNullOf(ListOf(NonNullOf(directiveType))), Resolve: func(ResolveParams) (interface{}, error) {
+			return directives, nil
+		}},
+	}
+
+	typeType.Fields = []*Field{
+		{Name: "kind", Type: NonNullOf(typeKindEnum), Resolve: typeResolver(func(t Type, _ ResolveParams) (interface{}, error) {
+			return t.kind(), nil
+		}).resolve},
+		{Name: "name", Type: String, Resolve: typeResolver(func(t Type, _ ResolveParams) (interface{}, error) {
+			switch t.(type) {
+			case *List, *NonNull:
+				return nil, nil
+			}
+			return t.String(), nil
+		}).resolve},
+		{Name: "description", Type: String, Resolve: typeResolver(func(t Type, _ ResolveParams) (interface{}, error) {
+			var description string
+			switch t := t.(type) {
+			case *Scalar:
+				description = t.Description
+			case *Enum:
+				description = t.Description
+			case *Object:
+				description = t.Description
+			}
+			return optional(description), nil
+		}).resolve},
+		{Name: "specifiedByURL", Type: String, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "fields", Type: ListOf(NonNullOf(fieldType)), Args: includeDeprecated, Resolve: typeResolver(func(t Type, p ResolveParams) (interface{}, error) {
+			object, ok := t.(*Object)
+			if !ok {
+				return nil, nil
+			}
+			all := p.Args["includeDeprecated"] == true
+			fields := []*Field{}
+			for _, f := range object.Fields {
+				if all || f.DeprecationReason == "" {
+					fields = append(fields, f)
+				}
+			}
+			return fields, nil
+		}).resolve},
+		{Name: "interfaces", Type: ListOf(NonNullOf(typeType)), Resolve: typeResolver(func(t Type, _ ResolveParams) (interface{}, error) {
+			if _, ok := t.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}).resolve},
+		{Name: "possibleTypes", Type: ListOf(NonNullOf(typeType)), Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "enumValues", Type: ListOf(NonNullOf(enumValueType)), Args: includeDeprecated, Resolve: typeResolver(func(t Type, p ResolveParams) (interface{}, error) {
+			enum, ok := t.(*Enum)
+			if !ok {
+				return nil, nil
+			}
+			all := p.Args["includeDeprecated"] == true
+			values := []*EnumValue{}
+			for _, v := range enum.Values {
+				if all || v.DeprecationReason == "" {
+					values = append(values, v)
+				}
+			}
+			return values, nil
+		}).resolve},
+		{Name: "inputFields", Type: ListOf(NonNullOf(inputValueType)), Args: includeDeprecated, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "ofType", Type: typeType, Resolve: typeResolver(func(t Type, _ ResolveParams) (interface{}, error) {
+			switch t := t.(type) {
+			case *List:
+				return t.Of, nil
+			case *NonNull:
+				return t.Of, nil
+			}
+			return nil, nil
+		}).resolve},
+	}
+
+	fieldType.Fields = []*Field{
+		{Name: "name", Type: nonNullString},
+		{Name: "description", Type: String, Resolve: fieldResolver(func(f *Field, _ ResolveParams) (interface{}, error) {
+			return optional(f.Description), nil
+		}).resolve},
+		{Name: "args", Type: NonNullOf(ListOf(NonNullOf(inputValueType))), Args: includeDeprecated, Resolve: fieldResolver(func(f *Field, _ ResolveParams) (interface{}, error) {
+			if f.Args == nil {
+				return []*Arg{}, nil
+			}
+			return f.Args, nil
+		}).resolve},
+		{Name: "type", Type: NonNullOf(typeType)},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: fieldResolver(func(f *Field, _ ResolveParams) (interface{}, error) {
+			return f.DeprecationReason != "", nil
+		}).resolve},
+		{Name: "deprecationReason", Type: String, Resolve: fieldResolver(func(f *Field, _ ResolveParams) (interface{}, error) {
+			return optional(f.DeprecationReason), nil
+		}).resolve},
+	}
+
+	inputValueType.Fields = []*Field{
+		{Name: "name", Type: nonNullString},
+		{Name: "description", Type: String, Resolve: argResolver(func(a *Arg, _ ResolveParams) (interface{}, error) {
+			return optional(a.Description), nil
+		}).resolve},
+		{Name: "type", Type: NonNullOf(typeType)},
+		{Name: "defaultValue", Type: String, Resolve: argResolver(func(a *Arg, _ ResolveParams) (interface{}, error) {
+			if a.DefaultValue == nil {
+				return nil, nil
+			}
+			return printValue(a.Type, a.DefaultValue), nil
+		}).resolve},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: func(ResolveParams) (interface{}, error) { return false, nil }},
+		{Name: "deprecationReason", Type: String, Resolve: func(ResolveParams) (interface{}, error) { return nil, nil }},
+	}
+
+	enumValueType.Fields = []*Field{
+		{Name: "name", Type: nonNullString},
+		{Name: "description", Type: String, Resolve: enumValueResolver(func(v *EnumValue, _ ResolveParams) (interface{}, error) {
+			return optional(v.Description), nil
+		}).resolve},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: enumValueResolver(func(v *EnumValue, _ ResolveParams) (interface{}, error) {
+			return v.DeprecationReason != "", nil
+		}).resolve},
+		{Name: "deprecationReason", Type: String, Resolve: enumValueResolver(func(v *EnumValue, _ ResolveParams) (interface{}, error) {
+			return optional(v.DeprecationReason), nil
+		}).resolve},
+	}
+
+	directiveType.Fields = []*Field{
+		{Name: "name", Type: nonNullString, Resolve: directiveResolver(func(d *directiveDefinition, _ ResolveParams) (interface{}, error) {
+			return d.name, nil
+		}).resolve},
+		{Name: "description", Type: String, Resolve: directiveResolver(func(d *directiveDefinition, _ ResolveParams) (interface{}, error) {
+			return optional(d.description), nil
+		}).resolve},
+		{Name: "locations", Type: NonNullOf(ListOf(NonNullOf(directiveLocationEnum))), Resolve: directiveResolver(func(d *directiveDefinition, _ ResolveParams) (interface{}, error) {
+			return d.locations, nil
+		}).resolve},
+		{Name: "args", Type: NonNullOf(ListOf(NonNullOf(inputValueType))), Args: includeDeprecated, Resolve: directiveResolver(func(d *directiveDefinition, _ ResolveParams) (interface{}, error) {
+			return d.args, nil
+		}).resolve},
+		{Name: "isRepeatable", Type: nonNullBoolean, Resolve: func(ResolveParams) (interface{}, error) { return false, nil }},
+	}
+}
+
+// optional devolve nulo para os textos vazios
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc busca os valores de várias chaves de uma vez. As chaves sem valor no mapa resultam em
+// nulo; um erro vale para todas as chaves do lote.
+type BatchFunc func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error)
+
+// Loader agrupa as chaves pedidas pelos resolvers de um mesmo nível da consulta e as busca numa
+// única chamada, guardando os valores já buscados. Um Loader deve ser criado por requisição,
+// para que os valores não sejam reaproveitados entre usuários.
+type Loader struct {
+	ctx   context.Context
+	fetch BatchFunc
+
+	mu      sync.Mutex
+	cache   map[interface{}]*loaded
+	pending []interface{}
+	batches int
+}
+
+type loaded struct {
+	done  bool
+	value interface{}
+	err   error
+}
+
+// NewLoader cria um Loader que busca as chaves com fetch
+func NewLoader(ctx context.Context, fetch BatchFunc) *Loader {
+	return &Loader{ctx: ctx, fetch: fetch, cache: map[interface{}]*loaded{}}
+}
+
+// Load registra a chave no lote pendente e retorna um Thunk com o valor. O lote é buscado na
+// primeira chamada de um dos Thunk, depois que o nível inteiro foi resolvido.
+func (l *Loader) Load(key interface{}) Thunk {
+	l.mu.Lock()
+	entry, ok := l.cache[key]
+	if !ok {
+		entry = &loaded{}
+		l.cache[key] = entry
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !entry.done {
+			l.dispatch()
+		}
+		return entry.value, entry.err
+	}
+}
+
+// Batches retorna quantas buscas foram feitas
+func (l *Loader) Batches() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.batches
+}
+
+// dispatch busca o lote pendente; deve ser chamada com o mutex travado
+func (l *Loader) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	l.batches++
+	values, err := l.fetch(l.ctx, keys)
+	for _, key := range keys {
+		entry := l.cache[key]
+		entry.done = true
+		entry.value = values[key]
+		entry.err = err
+	}
+}
@@ -0,0 +1,415 @@
+package graphql
+
+// Parse interpreta o texto de uma consulta. Só as definições executáveis (operações e fragmentos)
+// são aceitas; os erros de sintaxe são *Error com a posição do problema.
+func Parse(source string) (*Document, error) {
+	p := &parser{lex: newLexer(source)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	for {
+		if p.tok.kind == tokenEOF {
+			if len(doc.Operations) == 0 && len(doc.Fragments) == 0 {
+				return nil, p.unexpected()
+			}
+			return doc, nil
+		}
+		if p.peek("{") {
+			op := &OperationDefinition{Type: "query", Loc: p.tok.loc}
+			var err error
+			if op.SelectionSet, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+			continue
+		}
+		if p.tok.kind != tokenName {
+			return nil, p.unexpected()
+		}
+		switch p.tok.value {
+		case "query", "mutation", "subscription":
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case "fragment":
+			f, err := p.fragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments = append(doc.Fragments, f)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorfAt(p.tok.loc, "Unexpected %s.", p.tok)
+}
+
+// peek informa se o token atual é a pontuação informada
+func (p *parser) peek(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == punctuator
+}
+
+// skip consome a pontuação, se for o token atual
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(punctuator) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.lex.errorfAt(p.tok.loc, "Expected %q, found %s.", punctuator, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.lex.errorfAt(p.tok.loc, "Expected Name, found %s.", p.tok)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(word string) error {
+	if p.tok.kind != tokenName || p.tok.value != word {
+		return p.lex.errorfAt(p.tok.loc, "Expected %q, found %s.", word, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) operation() (*OperationDefinition, error) {
+	op := &OperationDefinition{Type: p.tok.value, Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.tok.kind == tokenName {
+		if op.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if op.Variables, err = p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if op.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []*VariableDefinition
+	for {
+		loc := p.tok.loc
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		def := &VariableDefinition{Name: name, Loc: loc}
+		if def.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			def.HasDefault = true
+			if def.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.directives(true); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+		if ok, err := p.skip(")"); err != nil || ok {
+			return defs, err
+		}
+	}
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	t := &TypeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.Elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		if t.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	ok, err := p.skip("!")
+	t.NonNull = ok
+	return t, err
+}
+
+func (p *parser) fragmentDefinition() (*FragmentDefinition, error) {
+	f := &FragmentDefinition{Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value == "on" {
+		return nil, p.unexpected()
+	}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.keyword("on"); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+		if ok, err := p.skip("}"); err != nil || ok {
+			return selections, err
+		}
+	}
+}
+
+func (p *parser) selection() (Selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragment(loc)
+	}
+
+	f := &FieldSelection{Loc: loc}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = f.Name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if f.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// fragment interpreta o que segue as reticências: um fragmento nomeado ou em linha
+func (p *parser) fragment(loc Location) (Selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives(false)
+		return spread, err
+	}
+	inline := &InlineFragment{Loc: loc}
+	var err error
+	if p.tok.kind == tokenName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if inline.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if inline.Directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if inline.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []*Argument
+	for {
+		arg := &Argument{Loc: p.tok.loc}
+		var err error
+		if arg.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if ok, err := p.skip(")"); err != nil || ok {
+			return args, err
+		}
+	}
+}
+
+func (p *parser) directives(constant bool) ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		d := &Directive{Loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if d.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if p.peek("(") {
+			if d.Arguments, err = p.arguments(constant); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// value interpreta um valor literal; constant recusa variáveis, como nos valores padrão
+func (p *parser) value(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		return IntLiteral(tok.value), p.advance()
+	case tokenFloat:
+		return FloatLiteral(tok.value), p.advance()
+	case tokenString:
+		return tok.value, p.advance()
+	case tokenName:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return EnumLiteral(tok.value), nil
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			return Variable(name), err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for {
+				if ok, err := p.skip("]"); err != nil || ok {
+					return list, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := map[string]interface{}{}
+			for {
+				if ok, err := p.skip("}"); err != nil || ok {
+					return object, err
+				}
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return nil, p.unexpected()
+}
@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	doc, err := Parse(`
+		# agenda do dentista
+		query Agenda($id: ID!, $limit: Int = 10, $status: [String!]) @cached {
+			dentist(id: $id) {
+				nome: name
+				appointments(limit: $limit, filter: {from: "2024-01-01", tags: [1, 2.5, true, null, SCHEDULED]}) {
+					...Horario
+					... on Appointment @include(if: true) { description(format: """
+						texto
+					""") }
+				}
+			}
+		}
+
+		fragment Horario on Appointment {
+			id
+			date
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(doc.Operations) != 1 || len(doc.Fragments) != 1 {
+		t.Fatalf("got %d operations and %d fragments, want 1 and 1", len(doc.Operations), len(doc.Fragments))
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Agenda" {
+		t.Errorf("operation = %s %s, want query Agenda", op.Type, op.Name)
+	}
+	if op.Loc != (Location{Line: 3, Column: 3}) {
+		t.Errorf("operation location = %+v, want 3:3", op.Loc)
+	}
+	if len(op.Directives) != 1 || op.Directives[0].Name != "cached" {
+		t.Errorf("operation directives = %+v, want @cached", op.Directives)
+	}
+
+	var types []string
+	for _, v := range op.Variables {
+		types = append(types, "$"+v.Name+": "+v.Type.String())
+	}
+	if want := []string{"$id: ID!", "$limit: Int", "$status: [String!]"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("variables = %v, want %v", types, want)
+	}
+	if limit := op.Variables[1]; !limit.HasDefault || limit.Default != IntLiteral("10") {
+		t.Errorf("$limit default = %#v, want 10", limit.Default)
+	}
+	if op.Variables[0].HasDefault {
+		t.Error("$id has a default value")
+	}
+
+	dentist := op.SelectionSet[0].(*FieldSelection)
+	if dentist.Name != "dentist" || dentist.Arguments[0].Value != Variable("id") {
+		t.Errorf("dentist = %+v, want dentist(id: $id)", dentist)
+	}
+	name := dentist.SelectionSet[0].(*FieldSelection)
+	if name.Alias != "nome" || name.Name != "name" || name.ResponseKey() != "nome" {
+		t.Errorf("alias = %s: %s, want nome: name", name.Alias, name.Name)
+	}
+
+	appointments := dentist.SelectionSet[1].(*FieldSelection)
+	filter := appointments.Arguments[1].Value
+	want := map[string]interface{}{
+		"from": "2024-01-01",
+		"tags": []interface{}{IntLiteral("1"), FloatLiteral("2.5"), true, nil, EnumLiteral("SCHEDULED")},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %#v, want %#v", filter, want)
+	}
+
+	spread, ok := appointments.SelectionSet[0].(*FragmentSpread)
+	if !ok || spread.Name != "Horario" {
+		t.Errorf("first selection = %#v, want ...Horario", appointments.SelectionSet[0])
+	}
+	inline, ok := appointments.SelectionSet[1].(*InlineFragment)
+	if !ok || inline.TypeCondition != "Appointment" || inline.Directives[0].Name != "include" {
+		t.Fatalf("second selection = %#v, want ... on Appointment @include", appointments.SelectionSet[1])
+	}
+	format := inline.SelectionSet[0].(*FieldSelection).Arguments[0].Value
+	if format != "texto" {
+		t.Errorf("block string = %q, want %q", format, "texto")
+	}
+
+	fragment := doc.Fragments[0]
+	if fragment.Name != "Horario" || fragment.TypeCondition != "Appointment" || len(fragment.SelectionSet) != 2 {
+		t.Errorf("fragment = %+v, want Horario on Appointment with 2 fields", fragment)
+	}
+}
+
+func TestParseShorthandQuery(t *testing.T) {
+	doc, err := Parse(`{ a, b: c }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "" || len(op.SelectionSet) != 2 {
+		t.Errorf("operation = %+v, want anonymous query with 2 fields", op)
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		message string
+		loc     Location
+	}{
+		{``, "Syntax Error: Unexpected <EOF>.", Location{1, 1}},
+		{`{ dentist(id: 1 }`, `Syntax Error: Expected Name, found "}".`, Location{1, 17}},
+		{"{\n  dentist {\n    name\n  }", "Syntax Error: Expected Name, found <EOF>.", Location{4, 4}},
+		{`{ a(b: "sem fim) }`, "Syntax Error: Unterminated string.", Location{1, 19}},
+		{`{ a(b: 01) }`, "Syntax Error: Invalid number, unexpected digit after 0.", Location{1, 9}},
+		{`{ a(b: $) }`, `Syntax Error: Expected Name, found ")".`, Location{1, 9}},
+		{`{ ...on }`, `Syntax Error: Expected Name, found "}".`, Location{1, 9}},
+		{`fragment on on X { a }`, `Syntax Error: Unexpected Name "on".`, Location{1, 10}},
+		{`{ a } ?`, "Syntax Error: Unexpected character '?'.", Location{1, 7}},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var gqlErr *Error
+		if !errors.As(err, &gqlErr) {
+			t.Errorf("Parse(%q) error = %v, want *Error", tt.query, err)
+			continue
+		}
+		if gqlErr.Message != tt.message {
+			t.Errorf("Parse(%q) message = %q, want %q", tt.query, gqlErr.Message, tt.message)
+		}
+		if len(gqlErr.Locations) != 1 || gqlErr.Locations[0] != tt.loc {
+			t.Errorf("Parse(%q) locations = %v, want %v", tt.query, gqlErr.Locations, tt.loc)
+		}
+	}
+}
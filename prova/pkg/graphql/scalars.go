@@ -0,0 +1,205 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Escalares da especificação
+var (
+	Int = &Scalar{
+		Name:         "Int",
+		Description:  "Número inteiro de 32 bits com sinal.",
+		Serialize:    serializeInt,
+		ParseValue:   serializeInt,
+		ParseLiteral: parseIntLiteral,
+	}
+	Float = &Scalar{
+		Name:         "Float",
+		Description:  "Número de ponto flutuante de precisão dupla.",
+		Serialize:    serializeFloat,
+		ParseValue:   serializeFloat,
+		ParseLiteral: parseFloatLiteral,
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "Texto em UTF-8.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case fmt.Stringer:
+				return v.String(), nil
+			}
+			if s, ok := stringKind(value); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("String cannot represent value: %s", inspect(value))
+		},
+		ParseValue:   parseString,
+		ParseLiteral: parseString,
+	}
+	Boolean = &Scalar{
+		Name:         "Boolean",
+		Description:  "Verdadeiro ou falso.",
+		Serialize:    parseBoolean,
+		ParseValue:   parseBoolean,
+		ParseLiteral: parseBoolean,
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "Identificador, escrito como texto; aceita também números inteiros.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if s, ok := stringKind(value); ok {
+				return s, nil
+			}
+			if n, ok := integer(value); ok {
+				return strconv.FormatInt(n, 10), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent value: %s", inspect(value))
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			if n, ok := integer(value); ok {
+				return strconv.FormatInt(n, 10), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent value: %s", inspect(value))
+		},
+		ParseLiteral: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case IntLiteral:
+				return string(v), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent a non-string and non-integer value: %s", printLiteral(value))
+		},
+	}
+)
+
+func serializeInt(value interface{}) (interface{}, error) {
+	n, ok := integer(value)
+	if !ok {
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", inspect(value))
+	}
+	if n > math.MaxInt32 || n < math.MinInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", inspect(value))
+	}
+	return int(n), nil
+}
+
+func parseIntLiteral(value interface{}) (interface{}, error) {
+	literal, ok := value.(IntLiteral)
+	if !ok {
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", printLiteral(value))
+	}
+	n, err := strconv.ParseInt(string(literal), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", literal)
+	}
+	return int(n), nil
+}
+
+func serializeFloat(value interface{}) (interface{}, error) {
+	if n, ok := integer(value); ok {
+		return float64(n), nil
+	}
+	switch v := value.(type) {
+	case float64:
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			return v, nil
+		}
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %s", inspect(value))
+}
+
+func parseFloatLiteral(value interface{}) (interface{}, error) {
+	var text string
+	switch v := value.(type) {
+	case IntLiteral:
+		text = string(v)
+	case FloatLiteral:
+		text = string(v)
+	default:
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %s", printLiteral(value))
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %s", text)
+	}
+	return f, nil
+}
+
+func parseString(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value: %s", printLiteral(value))
+}
+
+func parseBoolean(value interface{}) (interface{}, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", printLiteral(value))
+}
+
+// integer converte os tipos inteiros do Go, json.Number e float64 sem parte decimal
+func integer(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), true
+		}
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() <= math.MaxInt64 {
+			return int64(rv.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// stringKind converte os tipos derivados de string, como os nomes de situação
+func stringKind(value interface{}) (string, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.String {
+		return rv.String(), true
+	}
+	return "", false
+}
+
+// inspect descreve um valor para mensagens de erro
+func inspect(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case json.Number:
+		return v.String()
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
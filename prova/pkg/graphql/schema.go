@@ -0,0 +1,432 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type é um tipo do esquema: *Scalar, *Enum, *Object, *List ou *NonNull
+type Type interface {
+	// String é o tipo na notação da linguagem, como [Appointment!]!
+	String() string
+	kind() string
+}
+
+// Scalar é um tipo folha, como Int e String
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converte o valor devolvido pelo resolver no valor da resposta
+	Serialize func(value interface{}) (interface{}, error)
+	// ParseValue converte o valor de uma variável, decodificado do JSON com números json.Number
+	ParseValue func(value interface{}) (interface{}, error)
+	// ParseLiteral converte um valor escrito na consulta: IntLiteral, FloatLiteral, string ou bool
+	ParseLiteral func(value interface{}) (interface{}, error)
+}
+
+// Enum é um tipo folha com valores nomeados
+type Enum struct {
+	Name        string
+	Description string
+	Values      []*EnumValue
+}
+
+// EnumValue é um valor do enum. Value é o valor recebido e devolvido pelos resolvers; sem ele,
+// é usado o próprio nome.
+type EnumValue struct {
+	Name              string
+	Description       string
+	Value             interface{}
+	DeprecationReason string
+}
+
+func (v *EnumValue) value() interface{} {
+	if v.Value == nil {
+		return v.Name
+	}
+	return v.Value
+}
+
+// Object é um tipo com campos. Os campos podem ser atribuídos depois da criação, para os tipos que
+// se referenciam, desde que antes de NewSchema.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	fields map[string]*Field
+}
+
+// Field é um campo de um Object
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Arg
+	// Resolve obtém o valor do campo. Sem resolver, o valor é lido do campo de mesmo nome do
+	// objeto de origem, que pode ser um mapa ou uma struct (pelo nome no JSON, sem distinguir
+	// maiúsculas e sublinhados, de modo que createdAt lê o campo com tag created_at).
+	Resolve ResolveFunc
+	// Complexity calcula o custo do campo a partir do custo dos subcampos e dos argumentos já
+	// convertidos; sem ela, o custo é 1 mais o dos subcampos. Veja ListComplexity.
+	Complexity        func(args map[string]interface{}, childComplexity int) int
+	DeprecationReason string
+}
+
+// Arg é um argumento de campo. DefaultValue é usado quando o argumento é omitido e já está no
+// formato recebido pelo resolver; nil indica argumento sem valor padrão.
+type Arg struct {
+	Name         string
+	Description  string
+	Type         Type
+	DefaultValue interface{}
+}
+
+// List é uma lista de valores do tipo Of
+type List struct {
+	Of Type
+}
+
+// NonNull é um tipo que não aceita nulo
+type NonNull struct {
+	Of Type
+}
+
+// ListOf cria o tipo lista de t
+func ListOf(t Type) *List {
+	return &List{Of: t}
+}
+
+// NonNullOf cria o tipo não nulo de t
+func NonNullOf(t Type) *NonNull {
+	return &NonNull{Of: t}
+}
+
+func (t *Scalar) String() string  { return t.Name }
+func (t *Enum) String() string    { return t.Name }
+func (t *Object) String() string  { return t.Name }
+func (t *List) String() string    { return "[" + t.Of.String() + "]" }
+func (t *NonNull) String() string { return t.Of.String() + "!" }
+
+func (t *Scalar) kind() string  { return "SCALAR" }
+func (t *Enum) kind() string    { return "ENUM" }
+func (t *Object) kind() string  { return "OBJECT" }
+func (t *List) kind() string    { return "LIST" }
+func (t *NonNull) kind() string { return "NON_NULL" }
+
+// field retorna o campo do objeto, incluindo __typename
+func (t *Object) field(name string) *Field {
+	if name == typenameField.Name {
+		return typenameField
+	}
+	return t.fields[name]
+}
+
+// ResolveParams são os dados recebidos pelo resolver
+type ResolveParams struct {
+	Context context.Context
+	// Source é o valor do objeto ao qual o campo pertence; nil nos campos de Query
+	Source interface{}
+	// Args são os argumentos convertidos: Int como int, Float como float64, String e ID como
+	// string, Boolean como bool, enums pelo Value e listas como []interface{}
+	Args map[string]interface{}
+	Info ResolveInfo
+}
+
+// ResolveInfo descreve o campo sendo resolvido
+type ResolveInfo struct {
+	FieldName  string
+	ParentType *Object
+	// Path é o caminho do campo na resposta, com nomes e índices de listas
+	Path []interface{}
+}
+
+// ResolveFunc obtém o valor de um campo. Pode retornar um Thunk, que é chamado só depois que
+// todos os campos do mesmo nível foram resolvidos; é assim que os Loader agrupam as buscas.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// Thunk é um valor adiado, obtido com a chamada da função
+type Thunk func() (interface{}, error)
+
+var typenameField = &Field{
+	Name: "__typename",
+	Type: NonNullOf(String),
+	Resolve: func(p ResolveParams) (interface{}, error) {
+		return p.Info.ParentType.Name, nil
+	},
+	Complexity: func(map[string]interface{}, int) int { return 0 },
+}
+
+// Schema é um esquema de consultas pronto para execução
+type Schema struct {
+	query *Object
+	types map[string]Type
+	names []string
+}
+
+// NewSchema monta o esquema a partir do tipo Query, reunindo os tipos alcançáveis por ele.
+// Retorna erro para nomes repetidos ou inválidos e objetos sem campos.
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{query: query, types: map[string]Type{}}
+	for _, t := range []Type{Int, Float, String, Boolean, ID} {
+		s.types[t.String()] = t
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	if err := s.collect(schemaType); err != nil {
+		return nil, err
+	}
+	for _, d := range directives {
+		for _, arg := range d.args {
+			if err := s.collect(arg.Type); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for name := range s.types {
+		s.names = append(s.names, name)
+	}
+	sort.Strings(s.names)
+
+	query.fields[schemaField.Name] = schemaField
+	query.fields[typeField.Name] = typeField
+	return s, nil
+}
+
+// collect registra o tipo e os tipos alcançáveis a partir dele
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.collect(t.Of)
+	case *NonNull:
+		if _, ok := t.Of.(*NonNull); ok {
+			return fmt.Errorf("graphql: invalid type %s", t)
+		}
+		return s.collect(t.Of)
+	}
+
+	name := t.String()
+	if !validName(name) {
+		return fmt.Errorf("graphql: invalid type name %q", name)
+	}
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: type %s is defined more than once", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	switch t := t.(type) {
+	case *Enum:
+		if len(t.Values) == 0 {
+			return fmt.Errorf("graphql: enum %s has no values", name)
+		}
+		for _, v := range t.Values {
+			if !validName(v.Name) || v.Name == "true" || v.Name == "false" || v.Name == "null" {
+				return fmt.Errorf("graphql: invalid value %q in enum %s", v.Name, name)
+			}
+		}
+	case *Object:
+		if len(t.Fields) == 0 {
+			return fmt.Errorf("graphql: object %s has no fields", name)
+		}
+		fields := make(map[string]*Field, len(t.Fields)+2)
+		for _, f := range t.Fields {
+			if !validName(f.Name) || strings.HasPrefix(f.Name, "__") {
+				return fmt.Errorf("graphql: invalid field name %s.%s", name, f.Name)
+			}
+			if _, ok := fields[f.Name]; ok {
+				return fmt.Errorf("graphql: field %s.%s is defined more than once", name, f.Name)
+			}
+			if f.Type == nil {
+				return fmt.Errorf("graphql: field %s.%s has no type", name, f.Name)
+			}
+			fields[f.Name] = f
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.Args {
+				if !isInputType(arg.Type) {
+					return fmt.Errorf("graphql: argument %s of %s.%s must be a scalar or an enum", arg.Name, name, f.Name)
+				}
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+		if t.fields == nil {
+			t.fields = fields
+		}
+	}
+	return nil
+}
+
+// Type retorna o tipo nomeado do esquema
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+func validName(name string) bool {
+	if name == "" || !isNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isNameContinue(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isInputType indica se o tipo pode ser usado em argumentos e variáveis
+func isInputType(t Type) bool {
+	switch t := t.(type) {
+	case *List:
+		return isInputType(t.Of)
+	case *NonNull:
+		return isInputType(t.Of)
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+// namedType retorna o tipo sem os modificadores de lista e não nulo
+func namedType(t Type) Type {
+	for {
+		switch u := t.(type) {
+		case *List:
+			t = u.Of
+		case *NonNull:
+			t = u.Of
+		default:
+			return t
+		}
+	}
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+// fieldIndexes guarda a posição dos campos de struct encontrados pelo resolver padrão
+var fieldIndexes sync.Map
+
+type fieldKey struct {
+	t    reflect.Type
+	name string
+}
+
+// DefaultResolve é o resolver dos campos sem Resolve, descrito em Field.Resolve. Pode ser usado
+// por resolvers que só ajustam o valor lido.
+func DefaultResolve(p ResolveParams) (interface{}, error) {
+	return defaultResolve(p.Source, p.Info.FieldName)
+}
+
+// defaultResolve lê o campo do objeto de origem, como descrito em Field.Resolve
+func defaultResolve(source interface{}, name string) (interface{}, error) {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name], nil
+	}
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't read field %s from %T", name, source)
+	}
+
+	key := fieldKey{v.Type(), name}
+	cached, ok := fieldIndexes.Load(key)
+	if !ok {
+		cached = findField(v.Type(), normalize(name))
+		fieldIndexes.Store(key, cached)
+	}
+	index := cached.([]int)
+	if index == nil {
+		return nil, fmt.Errorf("%s has no field %s", v.Type(), name)
+	}
+	for i, step := range index {
+		if i > 0 {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return nil, nil
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(step)
+	}
+	return v.Interface(), nil
+}
+
+// findField procura o campo pelo nome normalizado, inclusive nas structs embutidas
+func findField(t reflect.Type, name string) []int {
+	var embedded [][]int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" {
+			embedded = append(embedded, f.Index)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if (tag != "" && normalize(tag) == name) || (tag == "" && normalize(f.Name) == name) {
+			return f.Index
+		}
+	}
+	for _, index := range embedded {
+		ft := t.Field(index[0]).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		if inner := findField(ft, name); inner != nil {
+			return append(index, inner...)
+		}
+	}
+	return nil
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// ListComplexity é a complexidade de um campo de lista: os subcampos contam uma vez para cada
+// item pedido no argumento informado, como limit
+func ListComplexity(arg string) func(args map[string]interface{}, childComplexity int) int {
+	return func(args map[string]interface{}, childComplexity int) int {
+		n, _ := args[arg].(int)
+		if n < 1 {
+			n = 1
+		}
+		return saturate(1 + int64(n)*int64(childComplexity))
+	}
+}
+
+// maxComplexity limita as contas de complexidade, para não estourar com argumentos enormes
+const maxComplexity = 1 << 30
+
+func saturate(n int64) int {
+	if n > maxComplexity {
+		return maxComplexity
+	}
+	return int(n)
+}
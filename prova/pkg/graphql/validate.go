@@ -0,0 +1,388 @@
+package graphql
+
+import "fmt"
+
+// Options são os limites aplicados antes da execução. Os campos de introspecção (__schema e
+// __type) não contam para os limites.
+type Options struct {
+	// MaxDepth é o maior aninhamento de campos aceito; zero não limita
+	MaxDepth int
+	// MaxComplexity é o maior custo aceito, somado com Field.Complexity; zero não limita
+	MaxComplexity int
+}
+
+// validator confere o documento com o esquema e calcula a profundidade e a complexidade da
+// operação executada
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	defined   map[string]*VariableDefinition
+	used      map[string]bool
+	spread    map[string]bool
+	visiting  map[string]bool
+	depth     int
+	errs      []*Error
+}
+
+func (v *validator) errorf(loc Location, format string, args ...interface{}) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+// validate confere o documento inteiro e a operação escolhida, cujas variáveis já foram
+// convertidas, e aplica os limites
+func (s *Schema) validate(doc *Document, op *OperationDefinition, variables map[string]interface{}, opts Options) []*Error {
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		variables: variables,
+		spread:    map[string]bool{},
+	}
+
+	names := map[string]bool{}
+	for _, o := range doc.Operations {
+		if o.Name == "" && len(doc.Operations) > 1 {
+			v.errorf(o.Loc, "This anonymous operation must be the only defined operation.")
+		}
+		if o.Name != "" {
+			if names[o.Name] {
+				v.errorf(o.Loc, `There can be only one operation named "%s".`, o.Name)
+			}
+			names[o.Name] = true
+		}
+	}
+	fragments := map[string]bool{}
+	for _, f := range doc.Fragments {
+		if fragments[f.Name] {
+			v.errorf(f.Loc, `There can be only one fragment named "%s".`, f.Name)
+		}
+		fragments[f.Name] = true
+	}
+
+	var complexity int
+	for _, o := range doc.Operations {
+		if o.Type != "query" {
+			v.errorf(o.Loc, "Schema is not configured to execute %s operation.", o.Type)
+			continue
+		}
+		v.defined = map[string]*VariableDefinition{}
+		v.used = map[string]bool{}
+		for _, def := range o.Variables {
+			if v.defined[def.Name] != nil {
+				v.errorf(def.Loc, `There can be only one variable named "$%s".`, def.Name)
+			}
+			v.defined[def.Name] = def
+		}
+		v.directives(o.Directives, "QUERY")
+		cost := v.selectionSet(s.query, o.SelectionSet, 0, false, map[string]*FieldSelection{})
+		for _, def := range o.Variables {
+			switch {
+			case v.used[def.Name]:
+			case o.Name == "":
+				v.errorf(def.Loc, `Variable "$%s" is never used.`, def.Name)
+			default:
+				v.errorf(def.Loc, `Variable "$%s" is never used in operation "%s".`, def.Name, o.Name)
+			}
+		}
+		if o == op {
+			complexity = cost
+		}
+	}
+	for _, f := range doc.Fragments {
+		if !v.spread[f.Name] {
+			v.errorf(f.Loc, `Fragment "%s" is never used.`, f.Name)
+		}
+	}
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	if opts.MaxDepth > 0 && v.depth > opts.MaxDepth {
+		v.errorf(op.Loc, "Query depth %d exceeds the maximum of %d.", v.depth, opts.MaxDepth)
+	}
+	if opts.MaxComplexity > 0 && complexity > opts.MaxComplexity {
+		v.errorf(op.Loc, "Query complexity %d exceeds the maximum of %d.", complexity, opts.MaxComplexity)
+	}
+	return v.errs
+}
+
+// selectionSet confere a seleção sobre o objeto e retorna o custo dos campos. fields guarda os
+// campos já vistos por nome na resposta, para recusar nomes repetidos com campos diferentes.
+func (v *validator) selectionSet(object *Object, selections []Selection, depth int, introspection bool, fields map[string]*FieldSelection) int {
+	cost := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			cost = saturate(int64(cost) + int64(v.field(object, s, depth, introspection, fields)))
+		case *FragmentSpread:
+			v.directives(s.Directives, "FRAGMENT_SPREAD")
+			f := v.doc.fragment(s.Name)
+			if f == nil {
+				v.errorf(s.Loc, `Unknown fragment "%s".`, s.Name)
+				continue
+			}
+			v.spread[f.Name] = true
+			if v.visiting[f.Name] {
+				v.errorf(s.Loc, `Cannot spread fragment "%s" within itself.`, f.Name)
+				continue
+			}
+			if !v.typeCondition(object, f.TypeCondition, f.Loc, s.Loc, `Fragment "`+f.Name+`"`) {
+				continue
+			}
+			if v.visiting == nil {
+				v.visiting = map[string]bool{}
+			}
+			v.visiting[f.Name] = true
+			v.directives(f.Directives, "FRAGMENT_DEFINITION")
+			if included(s.Directives, v.variables) {
+				cost = saturate(int64(cost) + int64(v.selectionSet(object, f.SelectionSet, depth, introspection, fields)))
+			} else {
+				v.selectionSet(object, f.SelectionSet, depth, introspection, fields)
+			}
+			delete(v.visiting, f.Name)
+		case *InlineFragment:
+			v.directives(s.Directives, "INLINE_FRAGMENT")
+			if s.TypeCondition != "" && !v.typeCondition(object, s.TypeCondition, s.Loc, s.Loc, "Fragment") {
+				continue
+			}
+			n := v.selectionSet(object, s.SelectionSet, depth, introspection, fields)
+			if included(s.Directives, v.variables) {
+				cost = saturate(int64(cost) + int64(n))
+			}
+		}
+	}
+	return cost
+}
+
+// typeCondition confere o tipo de um fragmento usado sobre o objeto; o esquema só tem objetos,
+// então o tipo precisa ser o próprio objeto
+func (v *validator) typeCondition(object *Object, name string, definition, use Location, what string) bool {
+	t, ok := v.schema.types[name]
+	if !ok {
+		v.errorf(definition, `Unknown type "%s".`, name)
+		return false
+	}
+	if _, ok := t.(*Object); !ok {
+		v.errorf(definition, `%s cannot condition on non composite type "%s".`, what, name)
+		return false
+	}
+	if t != Type(object) {
+		v.errorf(use, `%s cannot be spread here as objects of type "%s" can never be of type "%s".`, what, object.Name, name)
+		return false
+	}
+	return true
+}
+
+func (v *validator) field(object *Object, s *FieldSelection, depth int, introspection bool, fields map[string]*FieldSelection) int {
+	def := object.field(s.Name)
+	if def == nil {
+		v.errorf(s.Loc, `Cannot query field "%s" on type "%s".`, s.Name, object.Name)
+		return 0
+	}
+	key := s.ResponseKey()
+	if previous, ok := fields[key]; ok && previous.Name != s.Name {
+		v.errorf(s.Loc, `Fields "%s" conflict because "%s" and "%s" are different fields. Use different aliases on the fields to fetch both if this was intentional.`, key, previous.Name, s.Name)
+	} else if !ok {
+		fields[key] = s
+	}
+	v.directives(s.Directives, "FIELD")
+	v.arguments(s.Loc, def.Args, s.Arguments, fmt.Sprintf(`field "%s.%s"`, object.Name, def.Name))
+
+	introspection = introspection || def == schemaField || def == typeField
+	depth++
+	if !introspection && depth > v.depth {
+		v.depth = depth
+	}
+
+	var children int
+	switch t := namedType(def.Type).(type) {
+	case *Object:
+		if len(s.SelectionSet) == 0 {
+			v.errorf(s.Loc, `Field "%s" of type "%s" must have a selection of subfields. Did you mean "%s { ... }"?`, s.Name, def.Type, s.Name)
+			return 0
+		}
+		children = v.selectionSet(t, s.SelectionSet, depth, introspection, map[string]*FieldSelection{})
+	default:
+		if len(s.SelectionSet) > 0 {
+			v.errorf(s.Loc, `Field "%s" must not have a selection since type "%s" has no subfields.`, s.Name, def.Type)
+			return 0
+		}
+	}
+
+	if introspection || !included(s.Directives, v.variables) {
+		return 0
+	}
+	args, err := arguments(def.Args, s.Arguments, v.variables)
+	if err != nil {
+		// o erro do argumento já foi registrado
+		return 0
+	}
+	if def.Complexity != nil {
+		return def.Complexity(args, children)
+	}
+	return saturate(1 + int64(children))
+}
+
+// arguments confere os argumentos informados com os declarados
+func (v *validator) arguments(loc Location, defs []*Arg, args []*Argument, owner string) {
+	seen := map[string]bool{}
+	for _, arg := range args {
+		if seen[arg.Name] {
+			v.errorf(arg.Loc, `There can be only one argument named "%s".`, arg.Name)
+			continue
+		}
+		seen[arg.Name] = true
+		var def *Arg
+		for _, d := range defs {
+			if d.Name == arg.Name {
+				def = d
+			}
+		}
+		if def == nil {
+			v.errorf(arg.Loc, `Unknown argument "%s" on %s.`, arg.Name, owner)
+			continue
+		}
+		v.value(def.Type, def.DefaultValue != nil, arg.Value, arg.Loc, arg.Name)
+	}
+	for _, def := range defs {
+		if isNonNull(def.Type) && def.DefaultValue == nil && !seen[def.Name] {
+			v.errorf(loc, `Argument "%s" of type "%s" is required on %s, but it was not provided.`, def.Name, def.Type, owner)
+		}
+	}
+}
+
+// value confere um valor literal, incluindo o uso das variáveis nele
+func (v *validator) value(t Type, hasDefault bool, value interface{}, loc Location, name string) {
+	if variable, ok := value.(Variable); ok {
+		def := v.defined[string(variable)]
+		if def == nil {
+			v.errorf(loc, `Variable "$%s" is not defined.`, variable)
+			return
+		}
+		v.used[def.Name] = true
+		declared, err := v.schema.typeOf(def.Type)
+		if err != nil {
+			// o tipo desconhecido é informado na conversão das variáveis
+			return
+		}
+		if !allowed(declared, def.HasDefault && def.Default != nil, t, hasDefault) {
+			v.errorf(loc, `Variable "$%s" of type "%s" used in position expecting type "%s".`, variable, def.Type, t)
+		}
+		return
+	}
+	if list, ok := value.([]interface{}); ok {
+		if l, ok := unwrapNonNull(t).(*List); ok {
+			for _, item := range list {
+				v.value(l.Of, false, item, loc, name)
+			}
+			return
+		}
+	}
+	if containsVariable(value) {
+		v.markVariables(value, loc)
+		return
+	}
+	if _, err := coerceLiteral(t, value, nil); err != nil {
+		v.errorf(loc, `Argument "%s" has invalid value %s: %s`, name, printLiteral(value), err)
+	}
+}
+
+func (v *validator) markVariables(value interface{}, loc Location) {
+	switch value := value.(type) {
+	case Variable:
+		if v.defined[string(value)] == nil {
+			v.errorf(loc, `Variable "$%s" is not defined.`, value)
+			return
+		}
+		v.used[string(value)] = true
+	case []interface{}:
+		for _, item := range value {
+			v.markVariables(item, loc)
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			v.markVariables(item, loc)
+		}
+	}
+}
+
+func containsVariable(value interface{}) bool {
+	switch value := value.(type) {
+	case Variable:
+		return true
+	case []interface{}:
+		for _, item := range value {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowed indica se uma variável do tipo declarado pode ser usada na posição, que aceita um tipo
+// nulo no lugar de um não nulo quando há valor padrão
+func allowed(variable Type, variableDefault bool, position Type, positionDefault bool) bool {
+	if p, ok := position.(*NonNull); ok {
+		if _, ok := variable.(*NonNull); !ok {
+			if !variableDefault && !positionDefault {
+				return false
+			}
+			return compatible(variable, p.Of)
+		}
+	}
+	return compatible(variable, position)
+}
+
+// compatible confere os tipos como na regra de uso de variáveis da especificação
+func compatible(variable, position Type) bool {
+	if p, ok := position.(*NonNull); ok {
+		v, ok := variable.(*NonNull)
+		return ok && compatible(v.Of, p.Of)
+	}
+	if v, ok := variable.(*NonNull); ok {
+		return compatible(v.Of, position)
+	}
+	if p, ok := position.(*List); ok {
+		v, ok := variable.(*List)
+		return ok && compatible(v.Of, p.Of)
+	}
+	if _, ok := variable.(*List); ok {
+		return false
+	}
+	return variable == position
+}
+
+func unwrapNonNull(t Type) Type {
+	if n, ok := t.(*NonNull); ok {
+		return n.Of
+	}
+	return t
+}
+
+// directives confere as diretivas usadas no local
+func (v *validator) directives(list []*Directive, location string) {
+	seen := map[string]bool{}
+	for _, d := range list {
+		def := directive(d.Name)
+		if def == nil {
+			v.errorf(d.Loc, `Unknown directive "@%s".`, d.Name)
+			continue
+		}
+		if seen[d.Name] {
+			v.errorf(d.Loc, `The directive "@%s" can only be used once at this location.`, d.Name)
+		}
+		seen[d.Name] = true
+		if !def.at(location) {
+			v.errorf(d.Loc, `Directive "@%s" may not be used on %s.`, d.Name, location)
+			continue
+		}
+		v.arguments(d.Loc, def.args, d.Arguments, fmt.Sprintf(`directive "@%s"`, d.Name))
+	}
+}
@@ -0,0 +1,289 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testDentist struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testAppointment struct {
+	ID        int `json:"id"`
+	DentistID int `json:"dentist_id"`
+}
+
+// testSchema monta um esquema pequeno, com a busca dos dentistas das consultas agrupada pelo
+// loader retornado pela função loader de cada requisição
+func testSchema(t *testing.T, loader func(ctx context.Context) *Loader) *Schema {
+	t.Helper()
+	dentist := &Object{
+		Name: "Dentist",
+		Fields: []*Field{
+			{Name: "id", Type: NonNullOf(ID)},
+			{Name: "name", Type: NonNullOf(String)},
+		},
+	}
+	appointment := &Object{
+		Name: "Appointment",
+		Fields: []*Field{
+			{Name: "id", Type: NonNullOf(ID)},
+			{
+				Name: "dentist",
+				Type: dentist,
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return loader(p.Context).Load(p.Source.(testAppointment).DentistID), nil
+				},
+			},
+		},
+	}
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{
+				Name: "dentist",
+				Type: dentist,
+				Args: []*Arg{{Name: "id", Type: NonNullOf(ID)}},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return testDentist{ID: 1, Name: "Ana"}, nil
+				},
+			},
+			{
+				Name:       "appointments",
+				Type:       NonNullOf(ListOf(NonNullOf(appointment))),
+				Args:       []*Arg{{Name: "limit", Type: Int, DefaultValue: 10}},
+				Complexity: ListComplexity("limit"),
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					var list []testAppointment
+					for i := 0; i < p.Args["limit"].(int); i++ {
+						list = append(list, testAppointment{ID: i + 1, DentistID: i%2 + 1})
+					}
+					return list, nil
+				},
+			},
+		},
+	}
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return schema
+}
+
+// messages retorna as mensagens de erro da resposta
+func messages(resp *Response) []string {
+	var list []string
+	for _, e := range resp.Errors {
+		list = append(list, e.Message)
+	}
+	return list
+}
+
+func TestValidationErrors(t *testing.T) {
+	schema := testSchema(t, nil)
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			"unknown field",
+			`{ dentist(id: 1) { name email } }`,
+			[]string{`Cannot query field "email" on type "Dentist".`},
+		},
+		{
+			"missing argument",
+			`{ dentist { name } }`,
+			[]string{`Argument "id" of type "ID!" is required on field "Query.dentist", but it was not provided.`},
+		},
+		{
+			"unknown argument",
+			`{ appointments(first: 1) { id } }`,
+			[]string{`Unknown argument "first" on field "Query.appointments".`},
+		},
+		{
+			"invalid literal",
+			`{ appointments(limit: "dez") { id } }`,
+			[]string{`Argument "limit" has invalid value "dez": Int cannot represent non-integer value: "dez"`},
+		},
+		{
+			"missing subselection",
+			`{ dentist(id: 1) }`,
+			[]string{`Field "dentist" of type "Dentist" must have a selection of subfields. Did you mean "dentist { ... }"?`},
+		},
+		{
+			"subselection on scalar",
+			`{ dentist(id: 1) { name { first } } }`,
+			[]string{`Field "name" must not have a selection since type "String!" has no subfields.`},
+		},
+		{
+			"conflicting aliases",
+			`{ dentist(id: 1) { x: id x: name } }`,
+			[]string{`Fields "x" conflict because "id" and "name" are different fields. Use different aliases on the fields to fetch both if this was intentional.`},
+		},
+		{
+			"unknown fragment",
+			`{ dentist(id: 1) { ...Dados } }`,
+			[]string{`Unknown fragment "Dados".`},
+		},
+		{
+			"unused fragment",
+			`{ dentist(id: 1) { name } } fragment Dados on Dentist { id }`,
+			[]string{`Fragment "Dados" is never used.`},
+		},
+		{
+			"fragment cycle",
+			`{ dentist(id: 1) { ...A } } fragment A on Dentist { ...B } fragment B on Dentist { ...A }`,
+			[]string{`Cannot spread fragment "A" within itself.`},
+		},
+		{
+			"fragment on the wrong type",
+			`{ dentist(id: 1) { ... on Appointment { id } } }`,
+			[]string{`Fragment cannot be spread here as objects of type "Dentist" can never be of type "Appointment".`},
+		},
+		{
+			"undefined variable",
+			`{ dentist(id: $id) { name } }`,
+			[]string{`Variable "$id" is not defined.`},
+		},
+		{
+			"unused variable",
+			`query ($id: ID) { dentist(id: 1) { name } }`,
+			[]string{`Variable "$id" is never used.`},
+		},
+		{
+			"variable of the wrong type",
+			`query ($id: ID) { dentist(id: $id) { name } }`,
+			[]string{`Variable "$id" of type "ID" used in position expecting type "ID!".`},
+		},
+		{
+			"mutation",
+			`mutation { dentist(id: 1) { name } }`,
+			[]string{"Schema is not configured to execute mutation operation."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), Request{Query: tt.query}, Options{})
+			if got := messages(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %q, want %q", got, tt.want)
+			}
+			if resp.Data != nil {
+				t.Errorf("data = %s, want none", resp.Data)
+			}
+			for _, e := range resp.Errors {
+				if len(e.Locations) == 0 && tt.name != "mutation" {
+					t.Errorf("error %q has no location", e.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestAnonymousOperationWithOthers(t *testing.T) {
+	schema := testSchema(t, nil)
+	req := Request{Query: `{ dentist(id: 1) { name } } query Outra { dentist(id: 2) { name } }`, OperationName: "Outra"}
+	resp := schema.Execute(context.Background(), req, Options{})
+	want := []string{"This anonymous operation must be the only defined operation."}
+	if got := messages(resp); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	schema := testSchema(t, func(ctx context.Context) *Loader {
+		return NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+			return map[interface{}]interface{}{}, nil
+		})
+	})
+	tests := []struct {
+		name  string
+		query string
+		opts  Options
+		want  []string
+	}{
+		{"depth within limit", `{ appointments(limit: 1) { dentist { name } } }`, Options{MaxDepth: 3}, nil},
+		{
+			"depth over limit",
+			`{ appointments(limit: 1) { dentist { name } } }`,
+			Options{MaxDepth: 2},
+			[]string{"Query depth 3 exceeds the maximum of 2."},
+		},
+		{
+			"depth through fragments",
+			`{ appointments(limit: 1) { ...Consulta } } fragment Consulta on Appointment { dentist { id } }`,
+			Options{MaxDepth: 2},
+			[]string{"Query depth 3 exceeds the maximum of 2."},
+		},
+		// 1 + 5 * (id 1 + dentist (1 + name 1)) = 16
+		{"complexity within limit", `{ appointments(limit: 5) { id dentist { name } } }`, Options{MaxComplexity: 16}, nil},
+		{
+			"complexity over limit",
+			`{ appointments(limit: 5) { id dentist { name } } }`,
+			Options{MaxComplexity: 15},
+			[]string{"Query complexity 16 exceeds the maximum of 15."},
+		},
+		{
+			"complexity from variables and defaults",
+			`query ($n: Int) { a: appointments(limit: $n) { id } b: appointments { id } }`,
+			Options{MaxComplexity: 100},
+			[]string{"Query complexity 112 exceeds the maximum of 100."},
+		},
+		{
+			"huge limit saturates",
+			`{ appointments(limit: 2000000000) { id dentist { name } } }`,
+			Options{MaxComplexity: 1000},
+			[]string{"Query complexity 1073741824 exceeds the maximum of 1000."},
+		},
+		{
+			"introspection is not counted",
+			`{ __schema { types { name fields { name type { name ofType { name } } } } } }`,
+			Options{MaxDepth: 1, MaxComplexity: 1},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Query: tt.query, Variables: map[string]interface{}{"n": json.Number("100")}}
+			resp := schema.Execute(context.Background(), req, tt.opts)
+			if got := messages(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoaderBatchesEachLevel(t *testing.T) {
+	var loader *Loader
+	var fetched [][]interface{}
+	schema := testSchema(t, func(ctx context.Context) *Loader {
+		if loader == nil {
+			loader = NewLoader(ctx, func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+				fetched = append(fetched, keys)
+				values := map[interface{}]interface{}{}
+				for _, key := range keys {
+					values[key] = testDentist{ID: key.(int), Name: map[int]string{1: "Ana", 2: "Bia"}[key.(int)]}
+				}
+				return values, nil
+			})
+		}
+		return loader
+	})
+
+	resp := schema.Execute(context.Background(), Request{Query: `{ appointments(limit: 4) { id dentist { name } } }`}, Options{})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %q", messages(resp))
+	}
+	want := `{"appointments":[{"id":"1","dentist":{"name":"Ana"}},{"id":"2","dentist":{"name":"Bia"}},` +
+		`{"id":"3","dentist":{"name":"Ana"}},{"id":"4","dentist":{"name":"Bia"}}]}`
+	if string(resp.Data) != want {
+		t.Errorf("data = %s, want %s", resp.Data, want)
+	}
+	if loader.Batches() != 1 || !reflect.DeepEqual(fetched, [][]interface{}{{1, 2}}) {
+		t.Errorf("fetched %v in %d batches, want [[1 2]] in 1", fetched, loader.Batches())
+	}
+}
@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// variables converte os valores das variáveis recebidos no JSON para os tipos declarados na
+// operação, aplicando os valores padrão
+func (s *Schema) variables(op *OperationDefinition, values map[string]interface{}) (map[string]interface{}, []*Error) {
+	coerced := map[string]interface{}{}
+	var errs []*Error
+	for _, def := range op.Variables {
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{def.Loc}})
+		}
+		t, err := s.typeOf(def.Type)
+		if err != nil {
+			fail("%s", err)
+			continue
+		}
+		if !isInputType(t) {
+			fail(`Variable "$%s" cannot be non-input type "%s".`, def.Name, def.Type)
+			continue
+		}
+		value, provided := values[def.Name]
+		switch {
+		case !provided && def.HasDefault:
+			v, err := coerceLiteral(t, def.Default, nil)
+			if err != nil {
+				fail(`Variable "$%s" has invalid default value: %s`, def.Name, err)
+				continue
+			}
+			coerced[def.Name] = v
+		case !provided || value == nil:
+			if isNonNull(t) {
+				if provided {
+					fail(`Variable "$%s" of non-null type "%s" must not be null.`, def.Name, def.Type)
+				} else {
+					fail(`Variable "$%s" of required type "%s" was not provided.`, def.Name, def.Type)
+				}
+				continue
+			}
+			if provided {
+				coerced[def.Name] = nil
+			}
+		default:
+			v, err := coerceValue(t, value)
+			if err != nil {
+				fail(`Variable "$%s" got invalid value %s; %s`, def.Name, inspect(value), err)
+				continue
+			}
+			coerced[def.Name] = v
+		}
+	}
+	return coerced, errs
+}
+
+// typeOf resolve a referência de tipo da declaração de variável
+func (s *Schema) typeOf(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.typeOf(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(elem)
+	} else {
+		named, ok := s.types[ref.Name]
+		if !ok {
+			return nil, fmt.Errorf(`Unknown type "%s".`, ref.Name)
+		}
+		t = named
+	}
+	if ref.NonNull {
+		t = NonNullOf(t)
+	}
+	return t, nil
+}
+
+// coerceValue converte um valor decodificado do JSON para o tipo de entrada
+func coerceValue(t Type, value interface{}) (interface{}, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf(`Expected non-nullable type "%s" not to be null.`, t)
+		}
+		return coerceValue(t.Of, value)
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceValue(t.Of, value)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceValue(t.Of, item)
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			list[i] = v
+		}
+		return list, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		return t.ParseValue(value)
+	case *Enum:
+		if value == nil {
+			return nil, nil
+		}
+		name, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf(`Enum "%s" cannot represent non-string value: %s.`, t.Name, inspect(value))
+		}
+		for _, v := range t.Values {
+			if v.Name == name {
+				return v.value(), nil
+			}
+		}
+		return nil, fmt.Errorf(`Value %s does not exist in "%s" enum.`, inspect(value), t.Name)
+	}
+	return nil, fmt.Errorf(`Type "%s" is not an input type.`, t)
+}
+
+// coerceLiteral converte um valor escrito na consulta para o tipo de entrada; as variáveis são
+// trocadas pelos valores já convertidos
+func coerceLiteral(t Type, value interface{}, variables map[string]interface{}) (interface{}, error) {
+	if v, ok := value.(Variable); ok {
+		return variables[string(v)], nil
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf(`Expected value of type "%s", found null.`, t)
+		}
+		v, err := coerceLiteral(t.Of, value, variables)
+		if err == nil && v == nil {
+			return nil, fmt.Errorf(`Expected value of type "%s", found %s.`, t, printLiteral(value))
+		}
+		return v, err
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceLiteral(t.Of, value, variables)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceLiteral(t.Of, item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		return t.ParseLiteral(value)
+	case *Enum:
+		if value == nil {
+			return nil, nil
+		}
+		name, ok := value.(EnumLiteral)
+		if !ok {
+			return nil, fmt.Errorf(`Enum "%s" cannot represent non-enum value: %s.`, t.Name, printLiteral(value))
+		}
+		for _, v := range t.Values {
+			if v.Name == string(name) {
+				return v.value(), nil
+			}
+		}
+		return nil, fmt.Errorf(`Value "%s" does not exist in "%s" enum.`, name, t.Name)
+	}
+	return nil, fmt.Errorf(`Type "%s" is not an input type.`, t)
+}
+
+// arguments converte os argumentos do campo, aplicando os valores padrão. Argumentos omitidos
+// sem valor padrão, ou com variável não informada, ficam de fora do mapa.
+func arguments(defs []*Arg, args []*Argument, variables map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		var arg *Argument
+		for _, a := range args {
+			if a.Name == def.Name {
+				arg = a
+				break
+			}
+		}
+		if arg != nil {
+			if v, ok := arg.Value.(Variable); ok {
+				if _, provided := variables[string(v)]; !provided {
+					arg = nil
+				}
+			}
+		}
+		if arg == nil {
+			if def.DefaultValue != nil {
+				values[def.Name] = def.DefaultValue
+			}
+			continue
+		}
+		v, err := coerceLiteral(def.Type, arg.Value, variables)
+		if err != nil {
+			return nil, fmt.Errorf(`Argument "%s" has invalid value %s: %s`, def.Name, printLiteral(arg.Value), err)
+		}
+		if v == nil && isNonNull(def.Type) {
+			return nil, fmt.Errorf(`Argument "%s" of non-null type "%s" must not be null.`, def.Name, def.Type)
+		}
+		values[def.Name] = v
+	}
+	return values, nil
+}
+
+// included avalia as diretivas @skip e @include
+func included(directives []*Directive, variables map[string]interface{}) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		args, err := arguments(directiveArgs, d.Arguments, variables)
+		if err != nil {
+			continue
+		}
+		condition, _ := args["if"].(bool)
+		if condition == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// printValue escreve um valor já convertido na notação da linguagem, como nos valores padrão
+// mostrados pela introspecção
+func printValue(t Type, value interface{}) string {
+	switch t := t.(type) {
+	case *NonNull:
+		return printValue(t.Of, value)
+	case *List:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice {
+			return printValue(t.Of, value)
+		}
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = EnumLiteral(printValue(t.Of, rv.Index(i).Interface()))
+		}
+		return printLiteral(items)
+	case *Enum:
+		for _, v := range t.Values {
+			if v.value() == value {
+				return v.Name
+			}
+		}
+	}
+	return inspect(value)
+}
@@ -0,0 +1,121 @@
+// Package ical gera calendários no formato iCalendar (RFC 5545) para assinatura em clientes como
+// Google Agenda e Outlook.
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Situações de um evento (STATUS)
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+	// maxLineOctets é o tamanho máximo de uma linha antes da dobra, sem o CRLF
+	maxLineOctets = 75
+)
+
+// Calendar é um calendário com eventos em um único fuso horário
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// Event é um compromisso do calendário. O UID deve ser estável para que os clientes atualizem o
+// evento em vez de duplicá-lo, e Sequence deve crescer a cada alteração relevante.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      string
+	Sequence    int
+	Modified    time.Time
+}
+
+// Encode serializa o calendário, incluindo a definição do fuso horário usado pelos eventos
+func (c Calendar) Encode(now time.Time) []byte {
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + c.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	w.line("X-WR-TIMEZONE:" + location.String())
+	writeTimezone(w, location, c.Events)
+
+	stamp := now.UTC().Format(utcLayout)
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + escape(e.UID))
+		w.line("DTSTAMP:" + stamp)
+		w.line("DTSTART;TZID=" + location.String() + ":" + e.Start.In(location).Format(dateTimeLayout))
+		w.line("DTEND;TZID=" + location.String() + ":" + e.End.In(location).Format(dateTimeLayout))
+		w.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.Status != "" {
+			w.line("STATUS:" + e.Status)
+		}
+		w.line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+		if !e.Modified.IsZero() {
+			w.line("LAST-MODIFIED:" + e.Modified.UTC().Format(utcLayout))
+		}
+		w.line("END:VEVENT")
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line escreve uma linha de conteúdo terminada em CRLF, dobrando-a a cada 75 octetos sem partir
+// caracteres UTF-8
+func (w *writer) line(content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		w.buf.WriteString(content[:cut])
+		w.buf.WriteString("\r\n ")
+		content = content[cut:]
+		// as linhas de continuação começam com um espaço, que conta no limite
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(content)
+	w.buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape aplica o escape dos valores do tipo TEXT
+func escape(text string) string {
+	return escaper.Replace(text)
+}
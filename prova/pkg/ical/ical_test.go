@@ -0,0 +1,99 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return location
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	location := loadLocation(t, "America/Sao_Paulo")
+	event := Event{
+		UID:         "appointment-42@prova",
+		Start:       time.Date(2024, time.March, 5, 14, 30, 0, 0, location),
+		End:         time.Date(2024, time.March, 5, 15, 0, 0, 0, location),
+		Summary:     "Consulta; limpeza, revisão",
+		Description: "Trazer exames\nChegar 10 minutos antes",
+		Status:      StatusConfirmed,
+		Sequence:    3,
+	}
+	data := Calendar{ProdID: "-//prova//agenda//PT", Name: "Agenda", Location: location, Events: []Event{event}}.Encode(time.Now())
+
+	parsed, err := ParseEvent(data, time.UTC)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if parsed.UID != event.UID || parsed.Summary != event.Summary || parsed.Description != event.Description {
+		t.Errorf("parsed = %+v, want %+v", parsed, event)
+	}
+	if !parsed.Start.Equal(event.Start) || !parsed.End.Equal(event.End) {
+		t.Errorf("parsed %v - %v, want %v - %v", parsed.Start, parsed.End, event.Start, event.End)
+	}
+	if parsed.Status != StatusConfirmed || parsed.Sequence != 3 {
+		t.Errorf("status = %q, sequence = %d", parsed.Status, parsed.Sequence)
+	}
+}
+
+func TestEncodeFoldsLines(t *testing.T) {
+	summary := strings.Repeat("Avaliação ortodôntica ", 10)
+	data := Calendar{ProdID: "-//prova//agenda//PT", Events: []Event{{
+		UID:     "1",
+		Start:   time.Date(2024, time.March, 5, 14, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, time.March, 5, 15, 0, 0, 0, time.UTC),
+		Summary: summary,
+	}}}.Encode(time.Now())
+
+	text := string(data)
+	if !strings.HasSuffix(text, "END:VCALENDAR\r\n") {
+		t.Fatalf("calendar doesn't end with a CRLF terminated END:VCALENDAR")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line has %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a character: %q", line)
+		}
+	}
+	parsed, err := ParseEvent(data, time.UTC)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if parsed.Summary != summary {
+		t.Errorf("summary = %q, want %q", parsed.Summary, summary)
+	}
+}
+
+func TestEncodeTimezone(t *testing.T) {
+	location := loadLocation(t, "America/New_York")
+	data := string(Calendar{ProdID: "-//prova//agenda//PT", Location: location, Events: []Event{{
+		UID:   "1",
+		Start: time.Date(2024, time.July, 1, 9, 0, 0, 0, location),
+		End:   time.Date(2024, time.July, 1, 10, 0, 0, 0, location),
+	}}}.Encode(time.Now()))
+
+	for _, want := range []string{"TZID:America/New_York", "BEGIN:DAYLIGHT", "BEGIN:STANDARD", "TZOFFSETTO:-0400", "TZOFFSETTO:-0500", "DTSTART;TZID=America/New_York:20240701T090000"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("calendar is missing %q", want)
+		}
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	cases := map[int]string{0: "+0000", -3 * 3600: "-0300", 5*3600 + 30*60: "+0530", -(3*3600 + 6*60 + 28): "-030628"}
+	for seconds, want := range cases {
+		if got := formatOffset(seconds); got != want {
+			t.Errorf("formatOffset(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoEvent indica que o calendário não possui um VEVENT
+	ErrNoEvent = errors.New("calendar has no VEVENT")
+	// ErrRecurring indica um evento recorrente, que não é aceito
+	ErrRecurring = errors.New("recurring events are not supported")
+)
+
+const dateLayout = "20060102"
+
+// property é uma linha de conteúdo já desdobrada, com os parâmetros em maiúsculas
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseEvent lê o VEVENT principal de um objeto iCalendar, o que não tem RECURRENCE-ID. Horários
+// sem fuso e com TZID desconhecido são interpretados no fuso informado.
+func ParseEvent(data []byte, location *time.Location) (Event, error) {
+	properties, err := unfold(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	var event *Event
+	var current Event
+	var inEvent, override bool
+	var duration time.Duration
+	var hasEnd bool
+	depth := 0
+	for _, p := range properties {
+		switch {
+		case p.name == "BEGIN":
+			depth++
+			if strings.EqualFold(p.value, "VEVENT") {
+				inEvent, override, hasEnd, duration = true, false, false, 0
+				current = Event{}
+			}
+			continue
+		case p.name == "END":
+			depth--
+			if inEvent && strings.EqualFold(p.value, "VEVENT") {
+				inEvent = false
+				if !hasEnd {
+					current.End = current.Start.Add(duration)
+				}
+				if !override && event == nil {
+					e := current
+					event = &e
+				}
+			}
+			continue
+		case !inEvent || depth > 2:
+			// propriedades do VCALENDAR e de componentes aninhados, como VALARM
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			current.UID = p.value
+		case "SUMMARY":
+			current.Summary = unescape(p.value)
+		case "DESCRIPTION":
+			current.Description = unescape(p.value)
+		case "STATUS":
+			current.Status = strings.ToUpper(p.value)
+		case "SEQUENCE":
+			current.Sequence, _ = strconv.Atoi(p.value)
+		case "RECURRENCE-ID":
+			override = true
+		case "RRULE", "RDATE":
+			return Event{}, ErrRecurring
+		case "DTSTART":
+			if current.Start, err = parseTime(p, location); err != nil {
+				return Event{}, err
+			}
+			if p.params["VALUE"] == "DATE" && duration == 0 {
+				duration = 24 * time.Hour
+			}
+		case "DTEND":
+			if current.End, err = parseTime(p, location); err != nil {
+				return Event{}, err
+			}
+			hasEnd = true
+		case "DURATION":
+			if duration, err = parseDuration(p.value); err != nil {
+				return Event{}, err
+			}
+		}
+	}
+	if event == nil {
+		return Event{}, ErrNoEvent
+	}
+	if event.UID == "" || event.Start.IsZero() {
+		return Event{}, fmt.Errorf("VEVENT must have UID and DTSTART")
+	}
+	if !event.End.After(event.Start) {
+		return Event{}, fmt.Errorf("VEVENT must end after it starts")
+	}
+	return *event, nil
+}
+
+// unfold junta as linhas dobradas e separa nome, parâmetros e valor de cada propriedade
+func unfold(data []byte) ([]property, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	properties := make([]property, 0, len(lines))
+	for _, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, p)
+	}
+	return properties, nil
+}
+
+// parseLine separa uma linha no formato NOME;PARAM=VALOR:VALOR, respeitando parâmetros entre aspas
+func parseLine(line string) (property, error) {
+	quoted := false
+	separator := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			separator = i
+			break
+		}
+	}
+	if separator < 0 {
+		return property{}, fmt.Errorf("invalid content line %q", line)
+	}
+
+	parts := strings.Split(line[:separator], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[separator+1:]}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// parseTime interpreta DATE-TIME em UTC, com TZID ou flutuante, e DATE como início do dia
+func parseTime(p property, location *time.Location) (time.Time, error) {
+	if tzid, ok := p.params["TZID"]; ok {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	switch {
+	case p.params["VALUE"] == "DATE" || len(p.value) == len(dateLayout):
+		return time.ParseInLocation(dateLayout, p.value, location)
+	case strings.HasSuffix(p.value, "Z"):
+		return time.Parse(utcLayout, p.value)
+	default:
+		return time.ParseInLocation(dateTimeLayout, p.value, location)
+	}
+}
+
+// parseDuration interpreta durações como P1D, PT1H30M e P1W
+func parseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q", value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, invalid
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, invalid
+		}
+		number = ""
+		switch {
+		case r == 'W' && !inTime:
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, invalid
+		}
+	}
+	if number != "" {
+		return 0, invalid
+	}
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// unescape desfaz o escape dos valores do tipo TEXT
+func unescape(text string) string {
+	return unescaper.Replace(text)
+}
@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// calendar monta um objeto iCalendar com as linhas informadas, terminadas em CRLF
+func calendar(lines ...string) []byte {
+	return []byte(strings.Join(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...), "END:VCALENDAR"), "\r\n") + "\r\n")
+}
+
+func TestParseEvent(t *testing.T) {
+	location := time.FixedZone("BRT", -3*3600)
+	cases := []struct {
+		name  string
+		lines []string
+		start time.Time
+		end   time.Time
+	}{
+		{"utc", []string{"DTSTART:20240305T170000Z", "DTEND:20240305T173000Z"},
+			time.Date(2024, 3, 5, 17, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC)},
+		{"floating in the given location", []string{"DTSTART:20240305T140000", "DURATION:PT1H30M"},
+			time.Date(2024, 3, 5, 14, 0, 0, 0, location), time.Date(2024, 3, 5, 15, 30, 0, 0, location)},
+		{"unknown tzid falls back to the location", []string{"DTSTART;TZID=\"Custom: Zone\":20240305T140000", "DURATION:PT45M"},
+			time.Date(2024, 3, 5, 14, 0, 0, 0, location), time.Date(2024, 3, 5, 14, 45, 0, 0, location)},
+		{"all day", []string{"DTSTART;VALUE=DATE:20240305"},
+			time.Date(2024, 3, 5, 0, 0, 0, 0, location), time.Date(2024, 3, 6, 0, 0, 0, 0, location)},
+	}
+	for _, c := range cases {
+		lines := append([]string{"BEGIN:VEVENT", "UID:1"}, c.lines...)
+		event, err := ParseEvent(calendar(append(lines, "END:VEVENT")...), location)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !event.Start.Equal(c.start) || !event.End.Equal(c.end) {
+			t.Errorf("%s: got %v - %v, want %v - %v", c.name, event.Start, event.End, c.start, c.end)
+		}
+	}
+}
+
+func TestParseEventSkipsOverridesAndAlarms(t *testing.T) {
+	data := calendar(
+		"BEGIN:VEVENT",
+		"UID:1",
+		"RECURRENCE-ID:20240306T140000Z",
+		"SUMMARY:Exceção",
+		"DTSTART:20240306T150000Z",
+		"DTEND:20240306T160000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:1",
+		"SUMMARY:Consulta de ",
+		" retorno",
+		"DTSTART:20240305T140000Z",
+		"DTEND:20240305T150000Z",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Lembrete",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+	)
+	event, err := ParseEvent(data, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if event.Summary != "Consulta de retorno" || event.Description != "" {
+		t.Errorf("event = %+v, want the main event without the alarm description", event)
+	}
+}
+
+func TestParseEventErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []string
+		err   error
+	}{
+		{"no event", nil, ErrNoEvent},
+		{"recurring", []string{"BEGIN:VEVENT", "UID:1", "DTSTART:20240305T140000Z", "RRULE:FREQ=WEEKLY", "END:VEVENT"}, ErrRecurring},
+		{"missing uid", []string{"BEGIN:VEVENT", "DTSTART:20240305T140000Z", "DURATION:PT1H", "END:VEVENT"}, nil},
+		{"ends before it starts", []string{"BEGIN:VEVENT", "UID:1", "DTSTART:20240305T140000Z", "DTEND:20240305T130000Z", "END:VEVENT"}, nil},
+		{"invalid line", []string{"BEGIN:VEVENT", "UID 1", "END:VEVENT"}, nil},
+	}
+	for _, c := range cases {
+		_, err := ParseEvent(calendar(c.lines...), time.UTC)
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: error = %v, want %v", c.name, err, c.err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"PT30M":     30 * time.Minute,
+		"PT1H30M":   90 * time.Minute,
+		"P1D":       24 * time.Hour,
+		"P1W":       7 * 24 * time.Hour,
+		"P1DT2H":    26 * time.Hour,
+		"-PT15M":    -15 * time.Minute,
+		"+PT10S":    10 * time.Second,
+		"P0D":       0,
+		"PT1H0M10S": time.Hour + 10*time.Second,
+	}
+	for value, want := range valid {
+		got, err := parseDuration(value)
+		if err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "30M", "P1H", "PT1D", "PT1", "PTXM"} {
+		if got, err := parseDuration(value); err == nil {
+			t.Errorf("parseDuration(%q) = %v, want an error", value, got)
+		}
+	}
+}
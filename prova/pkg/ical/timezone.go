@@ -0,0 +1,71 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// writeTimezone escreve o VTIMEZONE do fuso, com uma observância para cada mudança de deslocamento
+// entre o ano anterior ao primeiro evento e o ano seguinte ao último, tiradas da base de fusos do Go
+func writeTimezone(w *writer, location *time.Location, events []Event) {
+	from, until := timezoneRange(location, events)
+
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + location.String())
+	current := from
+	_, offset := current.Zone()
+	writeObservance(w, current, offset)
+	for {
+		_, end := current.ZoneBounds()
+		if end.IsZero() || !end.Before(until) {
+			break
+		}
+		writeObservance(w, end, offset)
+		current = end
+		_, offset = current.Zone()
+	}
+	w.line("END:VTIMEZONE")
+}
+
+// timezoneRange retorna o período coberto pelas observâncias do fuso
+func timezoneRange(location *time.Location, events []Event) (time.Time, time.Time) {
+	first, last := time.Now().Year(), time.Now().Year()
+	for _, e := range events {
+		if year := e.Start.In(location).Year(); year < first {
+			first = year
+		}
+		if year := e.End.In(location).Year(); year > last {
+			last = year
+		}
+	}
+	return time.Date(first-1, time.January, 1, 0, 0, 0, 0, location), time.Date(last+2, time.January, 1, 0, 0, 0, 0, location)
+}
+
+// writeObservance escreve a observância que começa no instante informado. O DTSTART é a hora local
+// ainda no deslocamento anterior, como pede a RFC 5545.
+func writeObservance(w *writer, start time.Time, offsetFrom int) {
+	name, offsetTo := start.Zone()
+	kind := "STANDARD"
+	if start.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + start.In(time.FixedZone("", offsetFrom)).Format(dateTimeLayout))
+	w.line("TZOFFSETFROM:" + formatOffset(offsetFrom))
+	w.line("TZOFFSETTO:" + formatOffset(offsetTo))
+	w.line("TZNAME:" + escape(name))
+	w.line("END:" + kind)
+}
+
+// formatOffset formata um deslocamento em segundos como +hhmm ou +hhmmss
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	if seconds%60 != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds/60%60)
+}
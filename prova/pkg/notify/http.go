@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// postJSON envia o corpo em JSON para a url, tratando respostas fora da faixa 2xx como erro
+func postJSON(ctx context.Context, client *http.Client, url, token string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", url, response.StatusCode)
+	}
+	return nil
+}
+
+type smsNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewSMS cria um Notifier que envia SMS por um gateway HTTP, que recebe {"to", "message"} em JSON
+func NewSMS(url, token string) Notifier {
+	return &smsNotifier{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *smsNotifier) Channel() string {
+	return "sms"
+}
+
+func (n *smsNotifier) Send(ctx context.Context, m Message) error {
+	if m.Phone == "" {
+		return ErrNoRecipient
+	}
+	return postJSON(ctx, n.client, n.url, n.token, map[string]string{
+		"to":      m.Phone,
+		"message": m.Body,
+	})
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook cria um Notifier que publica a mensagem completa em JSON na url informada
+func NewWebhook(url string) Notifier {
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *webhookNotifier) Channel() string {
+	return "webhook"
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, m Message) error {
+	return postJSON(ctx, n.client, n.url, "", m)
+}
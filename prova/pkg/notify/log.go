@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLog cria um Notifier que apenas registra as mensagens no log informado.
+// Com logger nil as mensagens são descartadas, o que é útil em testes.
+func NewLog(logger *log.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Channel() string {
+	return "log"
+}
+
+func (n *logNotifier) Send(ctx context.Context, m Message) error {
+	if n.logger != nil {
+		n.logger.Printf("[%s] to=%q phone=%q subject=%q", m.Event, m.Email, m.Phone, m.Subject)
+	}
+	return nil
+}
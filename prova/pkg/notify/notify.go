@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoRecipient indica que a mensagem não tem o endereço exigido pelo canal
+var ErrNoRecipient = errors.New("message has no recipient for this channel")
+
+// Message é uma notificação a ser enviada a um paciente. Cada canal usa o endereço
+// que lhe interessa: e-mail, telefone ou os dados estruturados no caso de webhooks.
+type Message struct {
+	Event   string                 `json:"event"`
+	Email   string                 `json:"email,omitempty"`
+	Phone   string                 `json:"phone,omitempty"`
+	Subject string                 `json:"subject"`
+	Body    string                 `json:"body"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notifier envia mensagens por um canal de comunicação
+type Notifier interface {
+	// Channel identifica o canal, usado para registrar os envios
+	Channel() string
+	// Send envia a mensagem, retornando ErrNoRecipient quando ela não tem endereço para o canal
+	Send(ctx context.Context, m Message) error
+}
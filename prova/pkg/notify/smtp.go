@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type smtpNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP cria um Notifier que envia e-mails pelo servidor SMTP informado.
+// A autenticação é feita apenas quando username é informado.
+func NewSMTP(host, port, username, password, from string) Notifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpNotifier{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (n *smtpNotifier) Channel() string {
+	return "email"
+}
+
+func (n *smtpNotifier) Send(ctx context.Context, m Message) error {
+	if m.Email == "" {
+		return ErrNoRecipient
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", n.from)
+	fmt.Fprintf(&message, "To: %s\r\n", m.Email)
+	fmt.Fprintf(&message, "Subject: %s\r\n", m.Subject)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(m.Body)
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{m.Email}, []byte(message.String()))
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compare confere se cada operação de other, como a de um cliente gerado do mesmo catálogo,
+// existe no documento com os mesmos esquemas do corpo JSON e da resposta de êxito. Os esquemas
+// são comparados com as referências expandidas, de modo que os nomes dos tipos não importam.
+// Retorna as divergências, ordenadas.
+func (d *Document) Compare(other *Document) []string {
+	var diffs []string
+	for route, theirs := range other.routes {
+		ours, ok := d.routes[route]
+		if !ok {
+			diffs = append(diffs, route+": not documented")
+			continue
+		}
+		if a, b := d.expand(requestSchema(ours)), other.expand(requestSchema(theirs)); a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: request body differs: documented %s, got %s", route, a, b))
+		}
+		if a, b := d.expand(successSchema(ours)), other.expand(successSchema(theirs)); a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: response differs: documented %s, got %s", route, a, b))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+func requestSchema(op *Operation) *Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content[JSON].Schema
+}
+
+// successSchema retorna o esquema JSON da primeira resposta 2xx
+func successSchema(op *Operation) *Schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if media, ok := op.Responses[code].Content[JSON]; ok {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+// expand serializa o esquema com as referências substituídas pelos componentes
+func (d *Document) expand(s *Schema) string {
+	data, _ := json.Marshal(d.inline(s, map[string]bool{}))
+	return string(data)
+}
+
+func (d *Document) inline(s *Schema, visiting map[string]bool) *Schema {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		component, ok := d.Components.Schemas[name]
+		if !ok || visiting[name] {
+			// tipos recursivos são comparados só até a primeira repetição
+			return &Schema{Type: "object"}
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return d.inline(component, visiting)
+	}
+	out := *s
+	out.Items = d.inline(s.Items, visiting)
+	out.AdditionalProperties = d.inline(s.AdditionalProperties, visiting)
+	if s.Properties != nil {
+		out.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, p := range s.Properties {
+			out.Properties[name] = d.inline(p, visiting)
+		}
+	}
+	if s.AllOf != nil {
+		out.AllOf = make([]*Schema, len(s.AllOf))
+		for i, p := range s.AllOf {
+			out.AllOf[i] = d.inline(p, visiting)
+		}
+	}
+	if s.Required != nil {
+		out.Required = append([]string(nil), s.Required...)
+		sort.Strings(out.Required)
+	}
+	return &out
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// Options configura o middleware de validação
+type Options struct {
+	// Responses confere também as respostas JSON. As respostas ficam em memória até a conferência
+	// e, fora do contrato, são trocadas por um erro 500 com as divergências; use em testes.
+	Responses bool
+}
+
+// Middleware confere os parâmetros e o corpo JSON das requisições com o documento antes que
+// cheguem aos handlers, respondendo 400 com os campos inválidos. Deve ser registrado antes das
+// rotas; as rotas que não constam do documento seguem sem conferência.
+func Middleware(v *Validator, opts Options) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		op, ok := v.doc.Operation(ctx.Request.Method, ctx.FullPath())
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		path := make(map[string]string, len(ctx.Params))
+		for _, p := range ctx.Params {
+			path[p.Key] = p.Value
+		}
+		errs := v.Params(op, path, ctx.Request.URL.Query())
+		if op.RequestBody != nil && op.RequestBody.Content[JSON].Schema != nil {
+			body, err := io.ReadAll(ctx.Request.Body)
+			if err != nil {
+				web.BadResponse(ctx, http.StatusBadRequest, "error", "can't read the request body")
+				ctx.Abort()
+				return
+			}
+			// o handler lê o corpo novamente
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+			errs = append(errs, v.Body(op, body)...)
+		}
+		if len(errs) > 0 {
+			web.ValidationResponse(ctx, http.StatusBadRequest, "invalid request", errs)
+			ctx.Abort()
+			return
+		}
+
+		if !opts.Responses {
+			ctx.Next()
+			return
+		}
+		w := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Next()
+		ctx.Writer = w.ResponseWriter
+		if w.passthrough || w.body.Len() == 0 {
+			return
+		}
+		if errs := v.Response(op, w.Status(), w.body.Bytes()); len(errs) > 0 {
+			log.Printf("response of %s %s doesn't match the OpenAPI document: %v", ctx.Request.Method, ctx.FullPath(), errs)
+			web.ValidationResponse(ctx, http.StatusInternalServerError, "response doesn't match the API contract", errs)
+			return
+		}
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// responseRecorder guarda as respostas JSON para conferência; as demais, como arquivos e
+// calendários, são escritas diretamente
+type responseRecorder struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	passthrough bool
+	decided     bool
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.passthrough = !strings.HasPrefix(w.Header().Get("Content-Type"), JSON)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
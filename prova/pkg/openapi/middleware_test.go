@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+type item struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name" binding:"required"`
+	Status string `json:"status" openapi:"enum=active|archived"`
+}
+
+// testRouter registra as rotas de itens; o parâmetro broken faz o handler responder fora do contrato
+func testRouter(opts Options) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	doc := Build(Info{Title: "test"}, nil, []Route{
+		{Method: http.MethodGet, Path: "/items/:id", Query: []Param{{Name: "broken", Type: "boolean"}}, Response: item{}, ErrorType: web.ErrorResponse{}},
+		{Method: http.MethodPost, Path: "/items", Body: item{}, Status: http.StatusCreated, Response: item{}, ErrorType: web.ErrorResponse{}},
+		{Method: http.MethodGet, Path: "/items/:id/label", ContentTypes: []string{"text/plain"}},
+	})
+	calls := 0
+	r := gin.New()
+	r.Use(Middleware(NewValidator(doc, nil), opts))
+	r.GET("/items/:id", func(ctx *gin.Context) {
+		calls++
+		if ctx.Query("broken") == "true" {
+			web.ResponseOK(ctx, http.StatusOK, map[string]interface{}{"id": "1", "status": "deleted"})
+			return
+		}
+		web.ResponseOK(ctx, http.StatusOK, item{Id: 1, Name: "Resina", Status: "active"})
+	})
+	r.POST("/items", func(ctx *gin.Context) {
+		calls++
+		web.ResponseOK(ctx, http.StatusCreated, item{Id: 2, Name: "Resina"})
+	})
+	r.GET("/items/:id/label", func(ctx *gin.Context) {
+		calls++
+		ctx.String(http.StatusOK, "not json")
+	})
+	r.GET("/undocumented", func(ctx *gin.Context) {
+		calls++
+		ctx.JSON(http.StatusTeapot, gin.H{"anything": true})
+	})
+	return r, &calls
+}
+
+func serve(r *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", JSON)
+	r.ServeHTTP(w, request)
+	return w
+}
+
+func fieldErrors(t *testing.T, w *httptest.ResponseRecorder) []web.FieldError {
+	t.Helper()
+	var response web.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid error body %s: %v", w.Body, err)
+	}
+	return response.Errors
+}
+
+func TestMiddlewareValidatesRequests(t *testing.T) {
+	r, calls := testRouter(Options{})
+
+	w := serve(r, http.MethodGet, "/items/abc?broken=maybe", "")
+	if w.Code != http.StatusBadRequest || len(fieldErrors(t, w)) != 2 {
+		t.Errorf("invalid params: status %d, body %s", w.Code, w.Body)
+	}
+	w = serve(r, http.MethodPost, "/items", `{"id":"2","name":""}`)
+	if w.Code != http.StatusBadRequest || len(fieldErrors(t, w)) != 2 {
+		t.Errorf("invalid body: status %d, body %s", w.Code, w.Body)
+	}
+	if *calls != 0 {
+		t.Errorf("handlers were called %d times for invalid requests", *calls)
+	}
+
+	if w = serve(r, http.MethodPost, "/items", `{"name":"Resina"}`); w.Code != http.StatusCreated {
+		t.Errorf("valid body: status %d, body %s", w.Code, w.Body)
+	}
+	// sem a conferência das respostas, uma resposta fora do contrato chega ao cliente
+	if w = serve(r, http.MethodGet, "/items/1?broken=true", ""); w.Code != http.StatusOK {
+		t.Errorf("unchecked response: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestMiddlewareValidatesResponses(t *testing.T) {
+	r, _ := testRouter(Options{Responses: true})
+
+	w := serve(r, http.MethodGet, "/items/1", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Resina"`) {
+		t.Errorf("valid response: status %d, body %s", w.Code, w.Body)
+	}
+
+	w = serve(r, http.MethodGet, "/items/1?broken=true", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("response outside the contract: status %d, body %s", w.Code, w.Body)
+	}
+	fields := map[string]bool{}
+	for _, e := range fieldErrors(t, w) {
+		if e.In != "response" {
+			t.Errorf("error %+v isn't about the response", e)
+		}
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"data.id", "data.name", "data.status"} {
+		if !fields[field] {
+			t.Errorf("missing error for %s in %v", field, fields)
+		}
+	}
+
+	// respostas que não são JSON e rotas fora do documento não são conferidas
+	if w = serve(r, http.MethodGet, "/items/1/label", ""); w.Code != http.StatusOK || w.Body.String() != "not json" {
+		t.Errorf("text response: status %d, body %s", w.Code, w.Body)
+	}
+	if w = serve(r, http.MethodGet, "/undocumented", ""); w.Code != http.StatusTeapot {
+		t.Errorf("undocumented route: status %d, body %s", w.Code, w.Body)
+	}
+}
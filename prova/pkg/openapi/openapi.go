@@ -0,0 +1,370 @@
+// Package openapi monta o documento OpenAPI 3 da API a partir de um catálogo de rotas, com os
+// esquemas gerados por reflexão dos tipos usados nas requisições e respostas.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version é a versão da especificação OpenAPI gerada
+const Version = "3.0.3"
+
+// Tipos de conteúdo usados com frequência nas rotas
+const (
+	JSON      = "application/json"
+	Multipart = "multipart/form-data"
+)
+
+// Document é o documento OpenAPI
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	routes     map[string]*Operation // método e caminho no formato do gin
+}
+
+// Info descreve a API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag agrupa as operações na interface
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem associa o método, em minúsculas, à operação. Métodos que o OpenAPI não descreve, como
+// os do WebDAV, são publicados como extensões x-<método>.
+type PathItem map[string]*Operation
+
+// Operation é uma rota documentada
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter é um parâmetro de caminho ou de query string
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody é o corpo da requisição em cada tipo de conteúdo aceito
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType é o esquema de um tipo de conteúdo
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response é uma resposta possível da operação
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Components guarda os esquemas nomeados e os esquemas de segurança
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme descreve uma forma de autenticação
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Schema é um esquema JSON no subconjunto usado pelo OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	MinLength            int                `json:"minLength,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Param é um parâmetro de caminho, de query string ou campo de formulário de uma rota
+type Param struct {
+	Name        string
+	Description string
+	// Type é o tipo do esquema: string (padrão), integer, number, boolean ou file
+	Type     string
+	Required bool
+	Enum     []string
+	// Format é um dos formatos conhecidos pelo Validator
+	Format string
+}
+
+// Route é a documentação de uma rota, com o caminho no formato do gin (:id)
+type Route struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Auth indica que a rota exige o token de acesso; Roles lista os papéis aceitos
+	Auth  bool
+	Roles []string
+	// BasicAuth indica autenticação HTTP Basic, usada pelo CalDAV
+	BasicAuth bool
+	// Params descreve os parâmetros de caminho; sem descrição, id e os terminados em Id são
+	// inteiros positivos e os demais, texto
+	Params []Param
+	Query  []Param
+	// Headers descreve os cabeçalhos da requisição, como o If-Match
+	Headers []Param
+	// Body é um valor do tipo do corpo JSON; Form lista os campos de um corpo multipart
+	Body interface{}
+	Form []Param
+	// BodyTypes são os tipos de conteúdo de um corpo que não é JSON, descrito como texto
+	BodyTypes []string
+	// Status é o código de êxito, 200 por padrão
+	Status int
+	// Response é um valor do tipo devolvido no campo data do envelope de êxito
+	Response interface{}
+	// Raw indica que Response é devolvido sem o envelope
+	Raw bool
+	// ContentTypes são os tipos de uma resposta que não é JSON, descrita como texto ou binário
+	ContentTypes []string
+	// ErrorType é o valor do envelope de erro; nil omite as respostas de erro
+	ErrorType interface{}
+}
+
+// Build monta o documento a partir do catálogo de rotas
+func Build(info Info, tags []Tag, routes []Route) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Tags:    tags,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token obtido em POST /api/login"},
+				"basic":  {Type: "http", Scheme: "basic", Description: "Usuário e senha, usados pelos clientes CalDAV"},
+			},
+		},
+		routes: map[string]*Operation{},
+	}
+	g := &generator{schemas: d.Components.Schemas, types: map[string]reflect.Type{}}
+
+	for _, r := range routes {
+		op := &Operation{
+			Summary:     r.Summary,
+			OperationID: operationID(r.Method, r.Path),
+			Responses:   map[string]Response{},
+		}
+		if r.Tag != "" {
+			op.Tags = []string{r.Tag}
+		}
+		if len(r.Roles) > 0 {
+			op.Description = "Papéis permitidos: " + strings.Join(r.Roles, ", ")
+		}
+		switch {
+		case r.Auth:
+			op.Security = []map[string][]string{{"bearer": {}}}
+		case r.BasicAuth:
+			op.Security = []map[string][]string{{"basic": {}}}
+		}
+
+		for _, name := range pathParams(r.Path) {
+			p := pathParam(name, r.Params)
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Description: p.Description, Required: true, Schema: paramSchema(p)})
+		}
+		for _, p := range r.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: p.Name, In: "query", Description: p.Description, Required: p.Required, Schema: paramSchema(p)})
+		}
+		for _, p := range r.Headers {
+			op.Parameters = append(op.Parameters, Parameter{Name: p.Name, In: "header", Description: p.Description, Required: p.Required, Schema: paramSchema(p)})
+		}
+
+		switch {
+		case r.Body != nil:
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{JSON: {Schema: g.schema(r.Body)}}}
+		case len(r.Form) > 0:
+			form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			for _, p := range r.Form {
+				form.Properties[p.Name] = paramSchema(p)
+				if p.Required {
+					form.Required = append(form.Required, p.Name)
+				}
+			}
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{Multipart: {Schema: form}}}
+		case len(r.BodyTypes) > 0:
+			content := map[string]MediaType{}
+			for _, t := range r.BodyTypes {
+				content[t] = MediaType{Schema: &Schema{Type: "string"}}
+			}
+			op.RequestBody = &RequestBody{Required: true, Content: content}
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status), Content: map[string]MediaType{}}
+		if r.Response != nil {
+			schema := g.schema(r.Response)
+			if !r.Raw {
+				schema = &Schema{Type: "object", Properties: map[string]*Schema{"data": schema}, Required: []string{"data"}}
+			}
+			success.Content[JSON] = MediaType{Schema: schema}
+		}
+		for _, t := range r.ContentTypes {
+			format := "binary"
+			if strings.HasPrefix(t, "text/") || strings.HasSuffix(t, "xml") || strings.Contains(t, "ndjson") {
+				format = ""
+			}
+			success.Content[t] = MediaType{Schema: &Schema{Type: "string", Format: format}}
+		}
+		if len(success.Content) == 0 {
+			success.Content = nil
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		if r.ErrorType != nil {
+			op.Responses["default"] = Response{Description: "Erro", Content: map[string]MediaType{JSON: {Schema: g.schema(r.ErrorType)}}}
+		}
+
+		path := openAPIPath(r.Path)
+		item, ok := d.Paths[path]
+		if !ok {
+			item = PathItem{}
+			d.Paths[path] = item
+		}
+		item[methodKey(r.Method)] = op
+		d.routes[r.Method+" "+r.Path] = op
+	}
+	return d
+}
+
+// Operation retorna a operação documentada para o método e o caminho no formato do gin
+func (d *Document) Operation(method, path string) (*Operation, bool) {
+	op, ok := d.routes[method+" "+path]
+	return op, ok
+}
+
+// Undocumented lista, ordenadas, as rotas registradas que não constam do documento. Cada rota é
+// informada como "MÉTODO caminho", com o caminho no formato do gin.
+func (d *Document) Undocumented(registered []string) []string {
+	var missing []string
+	for _, route := range registered {
+		if _, ok := d.routes[route]; !ok {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// openAPIPath converte os parâmetros do gin (:id e *path) para o formato {id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			names = append(names, s[1:])
+		}
+	}
+	return names
+}
+
+// methodKey retorna a chave do método no PathItem
+func methodKey(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodOptions,
+		http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return strings.ToLower(method)
+	}
+	return "x-" + strings.ToLower(method)
+}
+
+// operationID gera um identificador estável a partir do método e do caminho, como getApiDentistsId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			if upper && r >= 'a' && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
+
+// pathParam retorna a descrição do parâmetro de caminho, ou a padrão
+func pathParam(name string, params []Param) Param {
+	for _, p := range params {
+		if p.Name == name {
+			return p
+		}
+	}
+	if name == "id" || strings.HasSuffix(name, "Id") {
+		return Param{Name: name, Type: "integer"}
+	}
+	return Param{Name: name}
+}
+
+func paramSchema(p Param) *Schema {
+	switch p.Type {
+	case "", "string":
+		return &Schema{Type: "string", Format: p.Format, Description: p.Description, Enum: p.Enum}
+	case "file":
+		return &Schema{Type: "string", Format: "binary", Description: p.Description}
+	case "integer":
+		s := &Schema{Type: p.Type, Format: p.Format, Description: p.Description, Enum: p.Enum}
+		if p.Name == "id" || strings.HasSuffix(p.Name, "Id") {
+			minimum := 1.0
+			s.Minimum = &minimum
+		}
+		return s
+	default:
+		return &Schema{Type: p.Type, Format: p.Format, Description: p.Description, Enum: p.Enum}
+	}
+}
@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator converte tipos Go em esquemas. Structs nomeadas viram componentes referenciados por
+// $ref; os campos seguem as tags json e são obrigatórios quando têm binding:"required". A tag
+// openapi acrescenta restrições, separadas por vírgula: format=<nome>, enum=<a|b|c> e minimum=<n>.
+type generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func (g *generator) schema(v interface{}) *Schema {
+	return g.typeSchema(reflect.TypeOf(v))
+}
+
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.typeSchema(t.Elem())
+		if s.Ref != "" {
+			// no OpenAPI 3.0, nullable ao lado de $ref é ignorado
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		format := ""
+		if t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64 {
+			format = "int64"
+		}
+		return &Schema{Type: "integer", Format: format}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// slices e mapas vazios são codificados como null
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := g.componentName(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.types[name] = t
+			// reserva o nome antes de gerar, para tipos que se referenciam
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	// interface{} e demais tipos aceitam qualquer valor
+	return &Schema{}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields acrescenta os campos da struct, incluindo os das structs embutidas sem tag json
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := g.typeSchema(f.Type)
+		if err := constrain(field, f.Tag.Get("openapi")); err != nil {
+			panic(fmt.Sprintf("openapi: field %s.%s: %v", t.Name(), f.Name, err))
+		}
+		if strings.Contains(f.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+			// como no gin, um texto obrigatório não pode ser vazio
+			if field.Type == "string" {
+				field.MinLength = 1
+			}
+		}
+		s.Properties[name] = field
+	}
+}
+
+// constrain aplica as restrições da tag openapi ao esquema do campo
+func constrain(s *Schema, tag string) error {
+	if tag == "" {
+		return nil
+	}
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "format":
+			s.Format = value
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		case "minimum":
+			minimum, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid minimum %q", value)
+			}
+			s.Minimum = &minimum
+		default:
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return nil
+}
+
+// componentName é o nome do tipo com a inicial maiúscula, já que os tipos dos handlers não são
+// exportados. Tipos homônimos de pacotes diferentes recebem o nome do pacote como prefixo.
+func (g *generator) componentName(t reflect.Type) string {
+	name := capitalize(t.Name())
+	if other, ok := g.types[name]; ok && other != t {
+		pkg := t.PkgPath()
+		name = capitalize(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	return name
+}
+
+func capitalize(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
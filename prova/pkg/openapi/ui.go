@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// uiTemplate é a página da interface interativa (Swagger UI), carregada de uma CDN
+var uiTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui", deepLinking: true, persistAuthorization: true });
+    };
+  </script>
+</body>
+</html>
+`))
+
+// UI gera a página da interface interativa que lê o documento publicado em specURL
+func UI(title, specURL string) ([]byte, error) {
+	var b bytes.Buffer
+	err := uiTemplate.Execute(&b, struct{ Title, SpecURL string }{title, specURL})
+	return b.Bytes(), err
+}
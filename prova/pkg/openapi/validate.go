@@ -0,0 +1,271 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meirafa/prova2-golang/pkg/web"
+)
+
+// Format verifica um formato de texto próprio da API, como o número do CRO
+type Format func(value string) bool
+
+// Validator confere requisições e respostas com os esquemas do documento. Campos desconhecidos são
+// recusados; textos vazios são tratados como ausentes nas verificações de formato e de enum, como
+// fazem os handlers nas alterações parciais.
+type Validator struct {
+	doc     *Document
+	formats map[string]Format
+}
+
+// NewValidator cria um validador do documento com os formatos próprios da API. Formatos sem
+// verificação cadastrada são aceitos.
+func NewValidator(doc *Document, formats map[string]Format) *Validator {
+	return &Validator{doc: doc, formats: formats}
+}
+
+// Params confere os parâmetros de caminho e de query string da operação
+func (v *Validator) Params(op *Operation, path map[string]string, query url.Values) []web.FieldError {
+	var errs []web.FieldError
+	for _, p := range op.Parameters {
+		var value string
+		var ok bool
+		switch p.In {
+		case "path":
+			value, ok = path[p.Name]
+		case "query":
+			ok = query.Has(p.Name)
+			value = query.Get(p.Name)
+		default:
+			continue
+		}
+		if !ok {
+			if p.Required {
+				errs = append(errs, web.FieldError{In: p.In, Field: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		if value == "" && p.In == "query" && !p.Required {
+			continue
+		}
+		errs = append(errs, v.value(p.Schema, paramValue(p.Schema, value), p.In, p.Name)...)
+	}
+	return errs
+}
+
+// Body confere o corpo JSON da requisição. Operações sem corpo JSON documentado não são conferidas.
+func (v *Validator) Body(op *Operation, body []byte) []web.FieldError {
+	if op.RequestBody == nil {
+		return nil
+	}
+	media, ok := op.RequestBody.Content[JSON]
+	if !ok {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return []web.FieldError{{In: "body", Message: "request body is required"}}
+		}
+		return nil
+	}
+	value, err := decode(body)
+	if err != nil {
+		return []web.FieldError{{In: "body", Message: "invalid JSON: " + err.Error()}}
+	}
+	return v.value(media.Schema, value, "body", "")
+}
+
+// Response confere uma resposta JSON com o esquema documentado para o status
+func (v *Validator) Response(op *Operation, status int, body []byte) []web.FieldError {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []web.FieldError{{In: "response", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+	media, ok := response.Content[JSON]
+	if !ok {
+		return []web.FieldError{{In: "response", Message: fmt.Sprintf("status %d has no documented JSON body", status)}}
+	}
+	value, err := decode(body)
+	if err != nil {
+		return []web.FieldError{{In: "response", Message: "invalid JSON: " + err.Error()}}
+	}
+	return v.value(media.Schema, value, "response", "")
+}
+
+// value confere um valor JSON decodificado com o esquema; field é o caminho do valor no documento
+func (v *Validator) value(s *Schema, value interface{}, in, field string) []web.FieldError {
+	fail := func(format string, args ...interface{}) []web.FieldError {
+		return []web.FieldError{{In: in, Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if s.Ref != "" {
+		resolved, ok := v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			return fail("unknown schema %s", s.Ref)
+		}
+		return v.value(resolved, value, in, field)
+	}
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return fail("can't be null")
+	}
+	var errs []web.FieldError
+	for _, part := range s.AllOf {
+		errs = append(errs, v.value(part, value, in, field)...)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("expected an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				errs = append(errs, web.FieldError{In: in, Field: join(field, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			item := object[name]
+			property, ok := s.Properties[name]
+			if !ok {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				errs = append(errs, web.FieldError{In: in, Field: join(field, name), Message: "unknown field"})
+				continue
+			}
+			errs = append(errs, v.value(property, item, in, join(field, name))...)
+		}
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			return fail("expected an array")
+		}
+		if s.Items != nil {
+			for i, item := range list {
+				errs = append(errs, v.value(s.Items, item, in, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fail("expected a string")
+		}
+		if utf8.RuneCountInString(text) < s.MinLength {
+			return fail("can't be empty")
+		}
+		if text == "" {
+			break
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, text) {
+			return fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		if !v.format(s.Format, text) {
+			return fail("invalid %s", s.Format)
+		}
+	case "integer", "number":
+		expected := "expected a number"
+		if s.Type == "integer" {
+			expected = "expected an integer"
+		}
+		number, ok := value.(json.Number)
+		if !ok {
+			return fail(expected)
+		}
+		n, err := number.Float64()
+		if err != nil {
+			return fail(expected)
+		}
+		if s.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return fail(expected)
+			}
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fail("must be at least %v", *s.Minimum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("expected a boolean")
+		}
+	}
+	return errs
+}
+
+// format confere os formatos padrão do OpenAPI usados pela API e os próprios cadastrados
+func (v *Validator) format(name, value string) bool {
+	switch name {
+	case "":
+		return true
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	}
+	if check, ok := v.formats[name]; ok {
+		return check(value)
+	}
+	return true
+}
+
+// paramValue converte o texto do parâmetro para o tipo do esquema, de modo que seja conferido
+// como um valor JSON; um texto que não pode ser convertido é mantido e recusado pela conferência
+func paramValue(s *Schema, value string) interface{} {
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func decode(body []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var value interface{}
+	if err := d.Decode(&value); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
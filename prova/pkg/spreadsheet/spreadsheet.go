@@ -0,0 +1,104 @@
+// Package spreadsheet lê e escreve planilhas CSV e XLSX como linhas de texto, para importação e
+// exportação de cadastros. Na leitura, a primeira linha retornada é o cabeçalho, exatamente como
+// está no arquivo.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formatos aceitos
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+const (
+	// MaxColumns é a última coluna do Excel, XFD
+	MaxColumns = 16384
+	// MaxCells limita as células de uma planilha, contando as vazias entre as preenchidas, para
+	// que uma referência distante não faça a leitura reservar memória sem limite
+	MaxCells = 1 << 22
+)
+
+var (
+	// ErrUnsupportedFormat indica um formato diferente de CSV e XLSX
+	ErrUnsupportedFormat = errors.New("unsupported spreadsheet format, use csv or xlsx")
+	// ErrEmpty indica uma planilha sem cabeçalho
+	ErrEmpty = errors.New("spreadsheet is empty")
+	// ErrTooManyRows indica uma planilha com mais linhas que o limite informado na leitura
+	ErrTooManyRows = errors.New("spreadsheet has too many rows")
+	// ErrTooManyCells indica uma planilha acima de MaxCells
+	ErrTooManyCells = fmt.Errorf("spreadsheet exceeds the limit of %d cells", MaxCells)
+)
+
+// zipMagic é a assinatura dos arquivos zip, como o XLSX
+var zipMagic = []byte("PK\x03\x04")
+
+// Read lê a planilha no formato informado. Sem formato, o XLSX é reconhecido pela assinatura zip e
+// qualquer outro conteúdo é tratado como CSV. Com maxRows maior que zero, planilhas com mais de
+// maxRows linhas além do cabeçalho retornam ErrTooManyRows.
+func Read(data []byte, format string, maxRows int) ([][]string, error) {
+	if format == "" {
+		format = FormatCSV
+		if bytes.HasPrefix(data, zipMagic) {
+			format = FormatXLSX
+		}
+	}
+
+	var rows [][]string
+	var err error
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		rows, err = ReadCSV(data, maxRows)
+	case FormatXLSX:
+		rows, err = ReadXLSX(data, maxRows)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+	return rows, nil
+}
+
+// ReadCSV lê um CSV separado por vírgula ou ponto e vírgula, este comum nas planilhas exportadas
+// em português. O separador é escolhido pelo cabeçalho e o BOM do UTF-8 é descartado. O limite
+// de linhas é o mesmo de Read.
+func ReadCSV(data []byte, maxRows int) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxRows > 0 && len(rows) > maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+}
@@ -0,0 +1,154 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xlsx monta uma pasta de trabalho com a planilha informada e as partes opcionais
+func xlsx(t *testing.T, sheetData string, parts map[string]string) []byte {
+	t.Helper()
+	all := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Pacientes" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		all[name] = content
+	}
+	var buffer bytes.Buffer
+	z := zip.NewWriter(&buffer)
+	for name, content := range all {
+		f, err := z.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := z.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := xlsx(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><r><t>Nasci</t></r><r><t>mento</t></r></is></c></row>`+
+		`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3" t="b"><v>1</v></c><c r="C3" s="1"><v>45355</v></c><c r="D3" s="1"><v>45355.375</v></c><c r="E3"><v>42</v></c></row>`,
+		map[string]string{
+			"xl/sharedStrings.xml": `<sst><si><t>Nome</t></si><si><t>Ana</t></si></sst>`,
+			"xl/styles.xml":        `<styleSheet><cellXfs><xf numFmtId="0"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		})
+
+	rows, err := ReadXLSX(data, 0)
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	want := [][]string{
+		{"Nome", "", "Nascimento"},
+		nil,
+		{"Ana", "true", "04/03/2024", "04/03/2024 09:00", "42"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXBoundsTheReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		sheet   string
+		maxRows int
+		// want é um trecho do erro esperado; vazio, a leitura deve funcionar
+		want string
+	}{
+		{"last column", `<row r="1"><c r="XFD1" t="inlineStr"><is><t>x</t></is></c></row>`, 10, ""},
+		{"column after XFD", `<row r="1"><c r="XFE1" t="inlineStr"><is><t>x</t></is></c></row>`, 10, "invalid cell reference"},
+		{"column far beyond XFD", `<row r="1"><c r="ZZZZZZZZZZZZZZ1" t="inlineStr"><is><t>x</t></is></c></row>`, 10, "invalid cell reference"},
+		{"last row", `<row r="11"><c r="A11"><v>1</v></c></row>`, 10, ""},
+		{"row after the limit", `<row r="12"><c r="A12"><v>1</v></c></row>`, 10, ErrTooManyRows.Error()},
+		{"row far beyond the limit", `<row r="99999999"><c r="A99999999"><v>1</v></c></row>`, 10, ErrTooManyRows.Error()},
+		{"rows without number after the limit", strings.Repeat(`<row><c><v>1</v></c></row>`, 12), 10, ErrTooManyRows.Error()},
+		{"distant row without limit", `<row r="99999999"><c r="A99999999"><v>1</v></c></row>`, 0, ErrTooManyCells.Error()},
+		{"distant columns in many rows", strings.Repeat(`<row><c r="XFC1"><v>1</v></c></row>`, 300), 0, ErrTooManyCells.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadXLSX(xlsx(t, tt.sheet, nil), tt.maxRows)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("ReadXLSX = %v, want no error", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("ReadXLSX = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXRejectsBrokenFiles(t *testing.T) {
+	tests := map[string][]byte{
+		"not a zip":           []byte("PK\x03\x04 not really"),
+		"missing workbook":    xlsx(t, "", map[string]string{"xl/workbook.xml": "<workbook/>"}),
+		"bad shared string":   xlsx(t, `<row r="1"><c r="A1" t="s"><v>3</v></c></row>`, map[string]string{"xl/sharedStrings.xml": `<sst/>`}),
+		"malformed worksheet": xlsx(t, `<row r="1"><c r="A1">`, nil),
+	}
+	for name, data := range tests {
+		if _, err := ReadXLSX(data, 0); err == nil {
+			t.Errorf("%s: ReadXLSX succeeded", name)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	rows, err := ReadCSV([]byte("\xef\xbb\xbfnome;documento\nAna; 123\n\"Silva; Bia\";456\n"), 0)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	want := [][]string{{"nome", "documento"}, {"Ana", "123"}, {"Silva; Bia", "456"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	data := []byte("nome\nAna\nBia\nCris\n")
+	if _, err := ReadCSV(data, 3); err != nil {
+		t.Errorf("ReadCSV at the limit = %v", err)
+	}
+	if _, err := ReadCSV(data, 2); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("ReadCSV over the limit = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	var buffer bytes.Buffer
+	w, err := NewWriter(&buffer, FormatXLSX)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	w.Write([]string{"nome", "", "obs"})
+	w.Write([]string{"Ana & Bia", "x", "<b>"})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// o XLSX é reconhecido pela assinatura zip
+	rows, err := Read(buffer.Bytes(), "", 0)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := [][]string{{"nome", "", "obs"}, {"Ana & Bia", "x", "<b>"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	if _, err := Read([]byte("a,b\n"), "ods", 0); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Read ods = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := Read(nil, FormatCSV, 0); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Read empty = %v, want ErrEmpty", err)
+	}
+}
@@ -0,0 +1,159 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Writer escreve uma planilha linha a linha, sem mantê-la em memória. Close conclui o arquivo e
+// deve ser chamado mesmo que nenhuma linha tenha sido escrita.
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// NewWriter cria um Writer no formato informado
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return &csvWriter{csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, "Dados")
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// formulaPrefixes são os caracteres com que o Excel e outros editores começam uma fórmula
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula prefixa com ' o valor que começa como fórmula, para que um texto vindo do cadastro,
+// como um nome "=HYPERLINK(...)", seja exibido como texto ao abrir a planilha
+func EscapeFormula(value string) string {
+	if value != "" && strings.IndexByte(formulaPrefixes, value[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeFormula desfaz EscapeFormula, para que uma planilha exportada possa ser importada
+// novamente com os mesmos valores
+func UnescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.IndexByte(formulaPrefixes, value[1]) >= 0 {
+		return value[1:]
+	}
+	return value
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(row []string) error {
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Partes fixas do XLSX. As células são gravadas como texto em linha, o que dispensa a tabela de
+// strings compartilhadas, que só poderia ser escrita depois de todas as linhas.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// XLSXWriter escreve uma pasta de trabalho com uma única planilha
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// NewXLSXWriter grava as partes fixas do arquivo e abre a planilha com o nome informado
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	z := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "%s", escapeXML(sheetName), 1)},
+	} {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return &XLSXWriter{zip: z, sheet: sheet}, nil
+}
+
+// Write acrescenta uma linha à planilha
+func (x *XLSXWriter) Write(row []string) error {
+	x.rows++
+	number := strconv.Itoa(x.rows)
+	x.sheet.WriteString(`<row r="` + number + `">`)
+	for i, value := range row {
+		if value == "" {
+			continue
+		}
+		x.sheet.WriteString(`<c r="` + columnName(i) + number + `" t="inlineStr"><is><t xml:space="preserve">`)
+		x.sheet.WriteString(escapeXML(value))
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Close fecha a planilha e o arquivo zip
+func (x *XLSXWriter) Close() error {
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+func escapeXML(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// columnName converte o índice da coluna, a partir de zero, no nome usado pelo Excel, como AB
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,320 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts usados para as células de data, os mesmos aceitos pelos cadastros
+const (
+	dateLayout     = "02/01/2006"
+	dateTimeLayout = "02/01/2006 15:04"
+)
+
+// ErrNoSheet indica um XLSX sem planilhas
+var ErrNoSheet = errors.New("xlsx has no worksheet")
+
+type workbookXML struct {
+	Properties struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText é o texto de uma string compartilhada ou em linha, simples ou dividido em trechos formatados
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type sharedStringsXML struct {
+	Items []richText `xml:"si"`
+}
+
+type stylesXML struct {
+	NumFmts []struct {
+		Id   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtId int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			S      int      `xml:"s,attr"`
+			V      string   `xml:"v"`
+			Inline richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX lê a primeira planilha da pasta de trabalho. Linhas e colunas vazias entre as
+// preenchidas são mantidas, para que a numeração corresponda à do Excel, e as células formatadas
+// como data são convertidas para dd/mm/aaaa, com a hora quando houver. O limite de linhas é o
+// mesmo de Read, e as referências são conferidas antes de preencher as linhas e colunas vazias.
+func ReadXLSX(data []byte, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var workbook workbookXML
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, ErrNoSheet
+	}
+	sheetPath, err := sheetTarget(files, workbook.Sheets[0].RID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared sharedStringsXML
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	var styles stylesXML
+	if _, ok := files["xl/styles.xml"]; ok {
+		if err := decodePart(files, "xl/styles.xml", &styles); err != nil {
+			return nil, err
+		}
+	}
+	dateStyles := dateStyles(styles)
+
+	var sheet worksheetXML
+	if err := decodePart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	cells := 0
+	for _, row := range sheet.Rows {
+		// linhas sem o atributo r seguem a anterior
+		number := row.R
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		if maxRows > 0 && number > maxRows+1 {
+			return nil, ErrTooManyRows
+		}
+		// as linhas vazias antes desta também contam no limite de células
+		if number-1 > len(rows) {
+			cells += number - 1 - len(rows)
+		}
+		if cells > MaxCells {
+			return nil, ErrTooManyCells
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for _, c := range row.Cells {
+			column := len(values)
+			if c.R != "" {
+				if column, err = columnIndex(c.R); err != nil {
+					return nil, err
+				}
+			}
+			if column >= MaxColumns {
+				return nil, fmt.Errorf("invalid cell reference %q", c.R)
+			}
+			// a célula e as vazias antes dela
+			cells++
+			if column > len(values) {
+				cells += column - len(values)
+			}
+			if cells > MaxCells {
+				return nil, ErrTooManyCells
+			}
+			for len(values) < column {
+				values = append(values, "")
+			}
+
+			var value string
+			switch c.T {
+			case "s":
+				i, err := strconv.Atoi(c.V)
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid shared string in cell %s", c.R)
+				}
+				value = shared.Items[i].String()
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = strconv.FormatBool(c.V == "1")
+			case "", "n":
+				value = c.V
+				if dateStyles[c.S] && c.V != "" {
+					if serial, err := strconv.ParseFloat(c.V, 64); err == nil {
+						value = serialDate(serial, workbook.Properties.Date1904)
+					}
+				}
+			default:
+				// str (resultado de fórmula) e e (erro) trazem o texto no próprio valor
+				value = c.V
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// sheetTarget resolve o caminho da planilha a partir do relacionamento da pasta de trabalho
+func sheetTarget(files map[string]*zip.File, rid string) (string, error) {
+	var rels relationshipsXML
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, r := range rels.Relationships {
+		if r.Id != rid {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(r.Target, "/"), nil
+		}
+		return path.Join("xl", r.Target), nil
+	}
+	return "", ErrNoSheet
+}
+
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid xlsx: missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx: %s: %w", name, err)
+	}
+	return nil
+}
+
+// dateStyles indica quais estilos de célula formatam o número como data
+func dateStyles(styles stylesXML) map[int]bool {
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, f := range styles.NumFmts {
+		custom[f.Id] = f.Code
+	}
+	result := make(map[int]bool)
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtId
+		// formatos de data embutidos no Excel
+		if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) {
+			result[i] = true
+			continue
+		}
+		if code, ok := custom[id]; ok && isDateFormat(code) {
+			result[i] = true
+		}
+	}
+	return result
+}
+
+// isDateFormat reconhece códigos de formato com dia, mês ou ano, ignorando textos entre aspas e
+// seções entre colchetes, como cores e moedas
+func isDateFormat(code string) bool {
+	quoted, bracket := false, false
+	for _, r := range strings.ToLower(code) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			bracket = true
+		case r == ']':
+			bracket = false
+		case bracket:
+		case r == 'd' || r == 'm' || r == 'y':
+			return true
+		}
+	}
+	return false
+}
+
+// serialDate converte o número serial do Excel, que conta dias desde 1899-12-30 (ou 1904-01-01),
+// numa data ou data e hora
+func serialDate(serial float64, date1904 bool) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days, fraction := math.Modf(serial)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(math.Round(fraction*24*60*60)) * time.Second)
+	if fraction == 0 {
+		return t.Format(dateLayout)
+	}
+	return t.Format(dateTimeLayout)
+}
+
+// columnIndex converte a referência da célula, como AB12, no índice da coluna a partir de zero.
+// Colunas além de XFD são recusadas.
+func columnIndex(ref string) (int, error) {
+	column := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			column = column*26 + int(r-'A'+1)
+		} else if r >= 'a' && r <= 'z' {
+			column = column*26 + int(r-'a'+1)
+		} else {
+			break
+		}
+		if column > MaxColumns {
+			return 0, fmt.Errorf("invalid cell reference %q", ref)
+		}
+	}
+	if column == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
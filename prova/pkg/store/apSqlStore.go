@@ -1,8 +1,10 @@
 package store
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"time"
 
 	"github.com/meirafa/prova2-golang/internal/domain"
 )
@@ -13,11 +15,27 @@ type ApStore interface {
 	GetAllAppointmentsByPatientIdentify(identifyNumber string) ([]domain.AppointmentDTO, error)
 	GetAllAppointmentsByDentistsLicense(Registration string) ([]domain.AppointmentDTO, error)
 	GetAllAppointmentsByDateTimeInterval(startDateTime, endDateTime string) ([]domain.Appointment, error)
+	GetScheduleBlocks(registration string) ([]domain.ScheduleBlock, error)
+	GetScheduleBlocksByInterval(startDateTime, endDateTime string) ([]domain.ScheduleBlock, error)
+	GetScheduleBlock(registration, href string) (domain.ScheduleBlock, error)
+	SaveScheduleBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error)
+	UpdateScheduleBlock(b domain.ScheduleBlock) error
+	DeleteScheduleBlock(id int) error
+	// DeleteVersion exclui a consulta somente se ela ainda estiver na versão informada; a versão
+	// zero exclui sem conferir
+	DeleteVersion(entityID, version int) error
+	// SearchAppointments lista as consultas filtradas, por data, com a página aplicada ao conjunto
+	SearchAppointments(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
+	// SearchAppointmentsByDentists lista as consultas dos dentistas de search.Dentists, ordenadas
+	// por dentista e data, com a página aplicada a cada dentista
+	SearchAppointmentsByDentists(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
+	// SearchAppointmentsByPatients é como SearchAppointmentsByDentists, com os pacientes de search.Patients
+	SearchAppointmentsByPatients(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error)
 }
 
 // NewSQLAp - Inicializa interface ApStore
 func NewSQLAp() ApStore {
-	database, err := sql.Open("mysql", "user:password@/my_db")
+	database, err := sql.Open("mysql", DataSourceName)
 	if err != nil {
 		panic(err)
 	}
@@ -34,13 +52,21 @@ type appointmentStore struct {
 
 // GetAllAppointmentsByPatientIdentify - retorna uma lista de todas as consultas feitas por um paciente através do seu número de identidade
 func (sa *appointmentStore) GetAllAppointmentsByPatientIdentify(identifyNumber string) ([]domain.AppointmentDTO, error) {
-	var appointment domain.AppointmentDTO
-	var appointments []domain.AppointmentDTO
+	return sa.queryAppointments("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id_patient = ? ORDER BY a.appointment_date", identifyNumber)
+}
+
+// GetAllAppointmentsByDentistsLicense - retorna uma lista de todas as consultas feitas por um dentista através do seu número de licença
+func (sa *appointmentStore) GetAllAppointmentsByDentistsLicense(Registration string) ([]domain.AppointmentDTO, error) {
+	return sa.queryAppointments("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id_dentist = ? ORDER BY a.appointment_date", Registration)
+}
 
-	query := "SELECT a.id, a.description, DATE_FORMAT(a.appointment_date,'%d/%m/%Y %H:%i') appointment_date,a.id_dentist,a.id_patient,d.id,d.surname,d.name,d.registration,p.id,p.surname,p.name,p.document,DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') created_at FROM appointments a INNER JOIN dentists d on a.id_dentist = d.registration INNER JOIN patients p on a.id_patient = p.document WHERE a.id_patient = ? ORDER BY a.appointment_date"
-	rows, err := sa.db.Query(query, identifyNumber)
+// GetAllAppointmentsByDateTimeInterval - retorna uma lista de todos os compromissos durante um intervalo de data e hora. Usado principalmente para validar se uma data está disponível.
+func (sa *appointmentStore) GetAllAppointmentsByDateTimeInterval(startDateTime, endDateTime string) ([]domain.Appointment, error) {
+	var appointment domain.Appointment
+	var appointments []domain.Appointment
+	rows, err := sa.db.Query("SELECT id, description, DATE_FORMAT(appointment_date,'%d/%m/%Y %H:%i'), id_dentist, id_patient, status, procedure_code, duration, version FROM appointments WHERE appointment_date BETWEEN ? AND ? ORDER BY appointment_date", startDateTime, endDateTime)
 	if err != nil {
-		return appointments, err
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -51,15 +77,10 @@ func (sa *appointmentStore) GetAllAppointmentsByPatientIdentify(identifyNumber s
 			&appointment.AppointmentDate,
 			&appointment.IdDentist,
 			&appointment.IdPatient,
-			&appointment.Dentist.Id,
-			&appointment.Dentist.Surname,
-			&appointment.Dentist.Name,
-			&appointment.Dentist.Registration,
-			&appointment.Patient.Id,
-			&appointment.Patient.Surname,
-			&appointment.Patient.Name,
-			&appointment.Patient.Document,
-			&appointment.Patient.CreatedAt); err != nil {
+			&appointment.Status,
+			&appointment.ProcedureCode,
+			&appointment.Duration,
+			&appointment.Version); err != nil {
 			return appointments, err
 		}
 		appointments = append(appointments, appointment)
@@ -67,32 +88,48 @@ func (sa *appointmentStore) GetAllAppointmentsByPatientIdentify(identifyNumber s
 	return appointments, nil
 }
 
-// GetAllAppointmentsByDentistsLicense - retorna uma lista de todas as consultas feitas por um dentista através do seu número de licença
-func (sa *appointmentStore) GetAllAppointmentsByDentistsLicense(Registration string) ([]domain.AppointmentDTO, error) {
+// appointmentColumns lista as colunas lidas de uma consulta junto com o dentista e o paciente
+const appointmentColumns = "a.id, a.description, DATE_FORMAT(a.appointment_date,'%d/%m/%Y %H:%i') appointment_date,a.id_dentist,a.id_patient,a.status,a.procedure_code,a.duration,a.version,d.id,d.surname,d.name,d.registration,p.id,p.surname,p.name,p.document,DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') created_at"
+
+// appointmentJoins relaciona a consulta ao dentista pelo CRO e ao paciente pelo documento
+const appointmentJoins = " FROM appointments a INNER JOIN dentists d on a.id_dentist = d.registration INNER JOIN patients p on a.id_patient = p.document"
+
+// scanAppointment lê uma linha selecionada com appointmentColumns
+func scanAppointment(row rowScanner) (domain.AppointmentDTO, error) {
 	var appointment domain.AppointmentDTO
-	var appointments []domain.AppointmentDTO
+	err := row.Scan(
+		&appointment.Id,
+		&appointment.Description,
+		&appointment.AppointmentDate,
+		&appointment.IdDentist,
+		&appointment.IdPatient,
+		&appointment.Status,
+		&appointment.ProcedureCode,
+		&appointment.Duration,
+		&appointment.Version,
+		&appointment.Dentist.Id,
+		&appointment.Dentist.Surname,
+		&appointment.Dentist.Name,
+		&appointment.Dentist.Registration,
+		&appointment.Patient.Id,
+		&appointment.Patient.Surname,
+		&appointment.Patient.Name,
+		&appointment.Patient.Document,
+		&appointment.Patient.CreatedAt)
+	return appointment, err
+}
 
-	query := "SELECT a.id, a.description, DATE_FORMAT(a.appointment_date,'%d/%m/%Y %H:%i') appointment_date,a.id_dentist,a.id_patient,d.id,d.surname,d.name,d.registration,p.id,p.surname,p.name,p.document,DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') created_at FROM appointments a INNER JOIN dentists d on a.id_dentist = d.registration INNER JOIN patients p on a.id_patient = p.document WHERE a.id_dentist = ? ORDER BY a.appointment_date"
-	rows, err := sa.db.Query(query, Registration)
+func (sa *appointmentStore) queryAppointments(query string, args ...interface{}) ([]domain.AppointmentDTO, error) {
+	var appointments []domain.AppointmentDTO
+	rows, err := sa.db.Query(query, args...)
 	if err != nil {
-		log.Fatalln(err)
+		return appointments, err
 	}
+	defer rows.Close()
+
 	for rows.Next() {
-		if err := rows.Scan(
-			&appointment.Id,
-			&appointment.Description,
-			&appointment.AppointmentDate,
-			&appointment.IdDentist,
-			&appointment.IdPatient,
-			&appointment.Dentist.Id,
-			&appointment.Dentist.Surname,
-			&appointment.Dentist.Name,
-			&appointment.Dentist.Registration,
-			&appointment.Patient.Id,
-			&appointment.Patient.Surname,
-			&appointment.Patient.Name,
-			&appointment.Patient.Document,
-			&appointment.Patient.CreatedAt); err != nil {
+		appointment, err := scanAppointment(rows)
+		if err != nil {
 			return appointments, err
 		}
 		appointments = append(appointments, appointment)
@@ -100,24 +137,167 @@ func (sa *appointmentStore) GetAllAppointmentsByDentistsLicense(Registration str
 	return appointments, nil
 }
 
-// GetAllAppointmentsByDateTimeInterval - retorna uma lista de todos os compromissos durante um intervalo de data e hora. Usado principalmente para validar se uma data está disponível.
-func (sa *appointmentStore) GetAllAppointmentsByDateTimeInterval(startDateTime, endDateTime string) ([]domain.Appointment, error) {
-	var appointment domain.Appointment
-	var appointments []domain.Appointment
-	rows, err := sa.db.Query("SELECT * FROM appointments WHERE appointment_date BETWEEN ? AND ?", startDateTime, endDateTime)
+// SearchAppointments lista as consultas filtradas, por data, com a página aplicada ao conjunto
+func (sa *appointmentStore) SearchAppointments(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	conditions, args := appointmentConditions(search)
+	args = append(args, search.Limit, search.Offset)
+	return sa.selectAppointments(ctx, "SELECT "+appointmentColumns+appointmentJoins+where(conditions)+" ORDER BY a.appointment_date, a.id LIMIT ? OFFSET ?", args...)
+}
+
+// SearchAppointmentsByDentists lista as consultas de cada dentista de search.Dentists
+func (sa *appointmentStore) SearchAppointmentsByDentists(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	if len(search.Dentists) == 0 {
+		return nil, nil
+	}
+	return sa.appointmentsPerGroup(ctx, "id_dentist", search)
+}
+
+// SearchAppointmentsByPatients lista as consultas de cada paciente de search.Patients
+func (sa *appointmentStore) SearchAppointmentsByPatients(ctx context.Context, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	if len(search.Patients) == 0 {
+		return nil, nil
+	}
+	return sa.appointmentsPerGroup(ctx, "id_patient", search)
+}
+
+// appointmentsPerGroup numera as consultas de cada dentista ou paciente por data e seleciona as da
+// página, de modo que todos os grupos venham no mesmo SELECT
+func (sa *appointmentStore) appointmentsPerGroup(ctx context.Context, column string, search domain.AppointmentSearch) ([]domain.AppointmentDTO, error) {
+	conditions, args := appointmentConditions(search)
+	args = append(args, search.Offset, search.Offset+search.Limit)
+	numbered := "SELECT a.id, ROW_NUMBER() OVER (PARTITION BY a." + column + " ORDER BY a.appointment_date, a.id) position FROM appointments a" + where(conditions)
+	return sa.selectAppointments(ctx, "SELECT "+appointmentColumns+appointmentJoins+
+		" INNER JOIN ("+numbered+") n ON n.id = a.id WHERE n.position > ? AND n.position <= ?"+
+		" ORDER BY a."+column+", a.appointment_date, a.id", args...)
+}
+
+// appointmentConditions monta as condições dos filtros sobre a tabela appointments com o apelido a
+func appointmentConditions(search domain.AppointmentSearch) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if !search.From.IsZero() {
+		conditions = append(conditions, "a.appointment_date >= ?")
+		args = append(args, search.From)
+	}
+	if !search.To.IsZero() {
+		conditions = append(conditions, "a.appointment_date < ?")
+		args = append(args, search.To)
+	}
+	if len(search.Dentists) > 0 {
+		conditions = append(conditions, "a.id_dentist IN ("+placeholders(len(search.Dentists))+")")
+		args = append(args, strs(search.Dentists)...)
+	}
+	if len(search.Patients) > 0 {
+		conditions = append(conditions, "a.id_patient IN ("+placeholders(len(search.Patients))+")")
+		args = append(args, strs(search.Patients)...)
+	}
+	if search.Status != "" {
+		conditions = append(conditions, "a.status = ?")
+		args = append(args, search.Status)
+	}
+	return conditions, args
+}
+
+const scheduleBlockColumns = "id, id_dentist, uid, href, DATE_FORMAT(start_at,'%d/%m/%Y %H:%i'), DATE_FORMAT(end_at,'%d/%m/%Y %H:%i'), summary"
+
+func scanScheduleBlock(row rowScanner) (domain.ScheduleBlock, error) {
+	var block domain.ScheduleBlock
+	err := row.Scan(&block.Id, &block.IdDentist, &block.UID, &block.Href, &block.Start, &block.End, &block.Summary)
+	return block, err
+}
+
+func (sa *appointmentStore) queryScheduleBlocks(query string, args ...interface{}) ([]domain.ScheduleBlock, error) {
+	rows, err := sa.db.Query(query, args...)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
+	defer rows.Close()
+
+	var blocks []domain.ScheduleBlock
 	for rows.Next() {
-		if err := rows.Scan(
-			&appointment.Id,
-			&appointment.Description,
-			&appointment.AppointmentDate,
-			&appointment.IdDentist,
-			&appointment.IdPatient); err != nil {
-			return appointments, err
+		block, err := scanScheduleBlock(rows)
+		if err != nil {
+			return blocks, err
 		}
-		appointments = append(appointments, appointment)
+		blocks = append(blocks, block)
 	}
-	return appointments, nil
+	return blocks, rows.Err()
+}
+
+// GetScheduleBlocks - retorna os horários bloqueados na agenda de um dentista
+func (sa *appointmentStore) GetScheduleBlocks(registration string) ([]domain.ScheduleBlock, error) {
+	return sa.queryScheduleBlocks("SELECT "+scheduleBlockColumns+" FROM schedule_blocks WHERE id_dentist = ? ORDER BY start_at", registration)
+}
+
+// GetScheduleBlocksByInterval - retorna os horários bloqueados que se sobrepõem a um intervalo de data e hora
+func (sa *appointmentStore) GetScheduleBlocksByInterval(startDateTime, endDateTime string) ([]domain.ScheduleBlock, error) {
+	return sa.queryScheduleBlocks("SELECT "+scheduleBlockColumns+" FROM schedule_blocks WHERE start_at < ? AND end_at > ? ORDER BY start_at", endDateTime, startDateTime)
+}
+
+// GetScheduleBlock - retorna um horário bloqueado pelo endereço do recurso no calendário
+func (sa *appointmentStore) GetScheduleBlock(registration, href string) (domain.ScheduleBlock, error) {
+	block, err := scanScheduleBlock(sa.db.QueryRow("SELECT "+scheduleBlockColumns+" FROM schedule_blocks WHERE id_dentist = ? AND href = ?", registration, href))
+	if errors.Is(err, sql.ErrNoRows) {
+		return block, ErrNotFound
+	}
+	return block, err
+}
+
+// SaveScheduleBlock - insere um horário bloqueado
+func (sa *appointmentStore) SaveScheduleBlock(b domain.ScheduleBlock) (domain.ScheduleBlock, error) {
+	start, end, err := parseBlockInterval(b)
+	if err != nil {
+		return domain.ScheduleBlock{}, err
+	}
+	result, err := sa.db.Exec("INSERT INTO schedule_blocks(id_dentist, uid, href, start_at, end_at, summary) VALUES (?,?,?,?,?,?)",
+		b.IdDentist,
+		b.UID,
+		b.Href,
+		start,
+		end,
+		b.Summary)
+	if err != nil {
+		return domain.ScheduleBlock{}, mapError(err)
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.ScheduleBlock{}, err
+	}
+	b.Id = int(lastInsertedID)
+	return b, nil
+}
+
+// UpdateScheduleBlock - atualiza o intervalo e a descrição de um horário bloqueado
+func (sa *appointmentStore) UpdateScheduleBlock(b domain.ScheduleBlock) error {
+	start, end, err := parseBlockInterval(b)
+	if err != nil {
+		return err
+	}
+	result, err := sa.db.Exec("UPDATE schedule_blocks SET uid = ?, start_at = ?, end_at = ?, summary = ? WHERE id = ?",
+		b.UID,
+		start,
+		end,
+		b.Summary,
+		b.Id)
+	return expectOneRow(result, err)
+}
+
+// DeleteVersion - exclui a consulta se ela ainda estiver na versão informada
+func (sa *appointmentStore) DeleteVersion(entityID, version int) error {
+	return auxDelete(AP, sa.sqlStore, entityID, version)
+}
+
+// DeleteScheduleBlock - exclui um horário bloqueado
+func (sa *appointmentStore) DeleteScheduleBlock(id int) error {
+	result, err := sa.db.Exec("DELETE FROM schedule_blocks WHERE id = ?", id)
+	return expectOneRow(result, err)
+}
+
+func parseBlockInterval(b domain.ScheduleBlock) (time.Time, time.Time, error) {
+	start, err := time.Parse("02/01/2006 15:04", b.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse("02/01/2006 15:04", b.End)
+	return start, end, err
 }
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// backupBatch é a quantidade de linhas em cada INSERT da cópia
+const backupBatch = 500
+
+// sqlEscaper escapa os caracteres especiais dos textos do MySQL
+var sqlEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\x00", `\0`, "\x1a", `\Z`)
+
+// Backup escreve em w uma cópia do banco em SQL: a estrutura de cada tabela, como informada por
+// SHOW CREATE TABLE, e os registros. O script recria as tabelas e pode ser executado no cliente
+// mysql para restaurar a cópia. Toda a leitura acontece numa única transação somente leitura, para
+// que a cópia seja um retrato consistente do banco mesmo com a API em uso.
+func Backup(ctx context.Context, db *sql.DB, w io.Writer) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	out := bufio.NewWriter(w)
+	tables, err := tableNames(ctx, tx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "-- cópia gerada em %s\nSET FOREIGN_KEY_CHECKS=0;\n", time.Now().Format(time.RFC3339))
+	for _, table := range tables {
+		var name, create string
+		if err := tx.QueryRowContext(ctx, "SHOW CREATE TABLE `"+table+"`").Scan(&name, &create); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nDROP TABLE IF EXISTS `%s`;\n%s;\n", table, create)
+		if err := backupRows(ctx, tx, out, table); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	fmt.Fprint(out, "\nSET FOREIGN_KEY_CHECKS=1;\n")
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func tableNames(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SHOW FULL TABLES WHERE Table_type = 'BASE TABLE'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name, kind string
+		if err := rows.Scan(&name, &kind); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func backupRows(ctx context.Context, tx *sql.Tx, out *bufio.Writer, table string) error {
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM `"+table+"`")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]sql.RawBytes, len(columns))
+	targets := make([]interface{}, len(columns))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	header := "INSERT INTO `" + table + "` (`" + strings.Join(columns, "`, `") + "`) VALUES\n"
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		if count%backupBatch == 0 {
+			if count > 0 {
+				out.WriteString(";\n")
+			}
+			out.WriteString(header)
+		} else {
+			out.WriteString(",\n")
+		}
+		out.WriteString("(")
+		for i, v := range values {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(sqlLiteral(v))
+		}
+		out.WriteString(")")
+		count++
+	}
+	if count > 0 {
+		out.WriteString(";\n")
+	}
+	return rows.Err()
+}
+
+// sqlLiteral escreve o valor como texto SQL, que o MySQL converte para o tipo da coluna
+func sqlLiteral(v sql.RawBytes) string {
+	if v == nil {
+		return "NULL"
+	}
+	return "'" + sqlEscaper.Replace(string(v)) + "'"
+}
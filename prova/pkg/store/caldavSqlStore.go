@@ -0,0 +1,66 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CalDAVStore - Define o contrato de persistência dos estados de sincronização das agendas CalDAV.
+type CalDAVStore interface {
+	GetSyncState(dentistID int, token string) (map[string]string, error)
+	SaveSyncState(dentistID int, token string, state map[string]string, expiresBefore time.Time) error
+}
+
+// NewSQLCalDAV - Inicializa interface CalDAVStore
+func NewSQLCalDAV() CalDAVStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &caldavStore{db: database}
+}
+
+type caldavStore struct {
+	db *sql.DB
+}
+
+// GetSyncState - retorna as ETags dos recursos da agenda no momento em que o token foi emitido
+func (sc *caldavStore) GetSyncState(dentistID int, token string) (map[string]string, error) {
+	var state []byte
+	err := sc.db.QueryRow("SELECT state FROM caldav_sync_states WHERE id_dentist = ? AND token = ?", dentistID, token).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	etags := map[string]string{}
+	return etags, json.Unmarshal(state, &etags)
+}
+
+// SaveSyncState - grava o estado da agenda para o token e descarta os estados emitidos antes do limite
+func (sc *caldavStore) SaveSyncState(dentistID int, token string, state map[string]string, expiresBefore time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT INTO caldav_sync_states(id_dentist, token, state, created_at) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE created_at = VALUES(created_at)",
+		dentistID,
+		token,
+		string(data),
+		time.Now()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM caldav_sync_states WHERE id_dentist = ? AND created_at < ?", dentistID, expiresBefore); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CalendarStore - Define o contrato de persistência dos tokens de assinatura dos calendários.
+type CalendarStore interface {
+	GetFeedToken(owner string, ownerID int) (string, error)
+	SaveFeedToken(owner string, ownerID int, tokenHash string) error
+}
+
+// NewSQLCalendar - Inicializa interface CalendarStore
+func NewSQLCalendar() CalendarStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &calendarStore{db: database}
+}
+
+type calendarStore struct {
+	db *sql.DB
+}
+
+// GetFeedToken - retorna o hash do token de assinatura do calendário de um dentista ou paciente
+func (sc *calendarStore) GetFeedToken(owner string, ownerID int) (string, error) {
+	var tokenHash string
+	err := sc.db.QueryRow("SELECT token_hash FROM calendar_feeds WHERE owner = ? AND id_owner = ?", owner, ownerID).Scan(&tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return tokenHash, err
+}
+
+// SaveFeedToken - grava o hash do token de assinatura, substituindo o token anterior
+func (sc *calendarStore) SaveFeedToken(owner string, ownerID int, tokenHash string) error {
+	_, err := sc.db.Exec("INSERT INTO calendar_feeds(owner, id_owner, token_hash, created_at) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE token_hash = VALUES(token_hash), created_at = VALUES(created_at)",
+		owner,
+		ownerID,
+		tokenHash,
+		time.Now())
+	return err
+}
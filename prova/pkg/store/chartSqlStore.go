@@ -0,0 +1,80 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// ChartStore - Define o contrato de persistência do histórico do odontograma.
+type ChartStore interface {
+	GetChartEntries(patientID int) ([]domain.ChartEntry, error)
+	SaveChartEntries(entries []domain.ChartEntry) error
+}
+
+// NewSQLChart - Inicializa interface ChartStore
+func NewSQLChart() ChartStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &chartStore{db: database}
+}
+
+type chartStore struct {
+	db *sql.DB
+}
+
+// GetChartEntries - retorna todas as alterações do odontograma de um paciente em ordem cronológica
+func (sc *chartStore) GetChartEntries(patientID int) ([]domain.ChartEntry, error) {
+	rows, err := sc.db.Query("SELECT id, id_patient, id_appointment, id_dentist, tooth, surface, `condition`, notes, DATE_FORMAT(recorded_at,'%d/%m/%Y %H:%i') FROM dental_chart_entries WHERE id_patient = ? ORDER BY recorded_at, id", patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.ChartEntry
+	for rows.Next() {
+		var entry domain.ChartEntry
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.IdPatient,
+			&entry.IdAppointment,
+			&entry.IdDentist,
+			&entry.Tooth,
+			&entry.Surface,
+			&entry.Condition,
+			&entry.Notes,
+			&entry.RecordedAt); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SaveChartEntries - registra as alterações do odontograma em uma única transação
+func (sc *chartStore) SaveChartEntries(entries []domain.ChartEntry) error {
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, entry := range entries {
+		if _, err := tx.Exec("INSERT INTO dental_chart_entries(id_patient, id_appointment, id_dentist, tooth, surface, `condition`, notes, recorded_at) VALUES (?,?,?,?,?,?,?,?)",
+			entry.IdPatient,
+			entry.IdAppointment,
+			entry.IdDentist,
+			entry.Tooth,
+			entry.Surface,
+			entry.Condition,
+			entry.Notes,
+			now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Códigos de erro do MySQL traduzidos por mapError
+const (
+	// mysqlDuplicateEntry é a violação de índice único
+	mysqlDuplicateEntry = 1062
+	// mysqlRowIsReferenced é a exclusão de uma linha referenciada por chave estrangeira
+	mysqlRowIsReferenced = 1451
+)
+
+var (
+	// ErrDuplicate indica que a operação violou uma restrição de unicidade do banco
+	ErrDuplicate = errors.New("entity already exists on database")
+	// ErrNotFound indica que nenhuma linha corresponde ao filtro informado
+	ErrNotFound = errors.New("entity not found at database")
+	// ErrVersionMismatch indica que a linha foi alterada desde a versão informada
+	ErrVersionMismatch = errors.New("entity was modified since the informed version")
+	// ErrReferenced indica que a linha não pode ser excluída porque outras a referenciam
+	ErrReferenced = errors.New("entity is referenced by other records")
+	// ErrOverlap indica que a consulta se sobrepõe a outra consulta ativa ou a um bloqueio da agenda do dentista
+	ErrOverlap = errors.New("entity overlaps another record in the schedule")
+)
+
+// mapError traduz erros do driver para os erros expostos pelo pacote store
+func mapError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return ErrDuplicate
+		case mysqlRowIsReferenced:
+			return ErrReferenced
+		}
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// ExportStore - Define o contrato de leitura em fluxo usado nas exportações. Cada registro é
+// entregue à função assim que lido do banco, sem montar a lista inteira em memória; se a função
+// retornar erro, a leitura é interrompida.
+type ExportStore interface {
+	StreamAppointments(ctx context.Context, filter domain.ExportFilter, fn func(domain.AppointmentDTO) error) error
+	StreamPatients(ctx context.Context, filter domain.ExportFilter, fn func(domain.Patient) error) error
+	StreamDentists(ctx context.Context, fn func(domain.Dentist) error) error
+}
+
+// NewSQLExport - Inicializa interface ExportStore
+func NewSQLExport() ExportStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &exportStore{db: database}
+}
+
+type exportStore struct {
+	db *sql.DB
+}
+
+// StreamAppointments - lê as consultas por data, filtradas pelo período, dentista, paciente e situação
+func (se *exportStore) StreamAppointments(ctx context.Context, filter domain.ExportFilter, fn func(domain.AppointmentDTO) error) error {
+	var conditions []string
+	var args []interface{}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "a.appointment_date >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "a.appointment_date < ?")
+		args = append(args, filter.To)
+	}
+	if filter.Dentist != "" {
+		conditions = append(conditions, "a.id_dentist = ?")
+		args = append(args, filter.Dentist)
+	}
+	if filter.Patient != "" {
+		conditions = append(conditions, "a.id_patient = ?")
+		args = append(args, filter.Patient)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "a.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	rows, err := se.db.QueryContext(ctx, "SELECT "+appointmentColumns+appointmentJoins+where(conditions)+" ORDER BY a.appointment_date, a.id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		appointment, err := scanAppointment(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(appointment); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamPatients - lê os pacientes por id, filtrados pela data de cadastro
+func (se *exportStore) StreamPatients(ctx context.Context, filter domain.ExportFilter, fn func(domain.Patient) error) error {
+	var conditions []string
+	var args []interface{}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "p.created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "p.created_at < ?")
+		args = append(args, filter.To)
+	}
+
+	rows, err := se.db.QueryContext(ctx, "SELECT "+patientColumns+" FROM patients p"+where(conditions)+" ORDER BY p.id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		patient, err := scanPatient(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(patient); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamDentists - lê os dentistas por id
+func (se *exportStore) StreamDentists(ctx context.Context, fn func(domain.Dentist) error) error {
+	rows, err := se.db.QueryContext(ctx, "SELECT id, surname, name, registration FROM dentists ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dentist domain.Dentist
+		if err := rows.Scan(&dentist.Id, &dentist.Surname, &dentist.Name, &dentist.Registration); err != nil {
+			return err
+		}
+		if err := fn(dentist); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// where monta a cláusula WHERE com as condições informadas
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
@@ -0,0 +1,331 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// InsuranceStore - Define o contrato de persistência dos convênios, adesões e autorizações prévias.
+type InsuranceStore interface {
+	GetInsurancePlans() ([]domain.InsurancePlan, error)
+	GetInsurancePlanByID(id int) (domain.InsurancePlan, error)
+	SaveInsurancePlan(p domain.InsurancePlan) (domain.InsurancePlan, error)
+	UpdateInsurancePlan(p domain.InsurancePlan) error
+	GetMembershipByID(id int) (domain.Membership, error)
+	GetMemberships(patientID int) ([]domain.Membership, error)
+	SaveMembership(m domain.Membership) (domain.Membership, error)
+	DeleteMembership(patientID, id int) error
+	GetPreAuthorizationByID(id int) (domain.PreAuthorization, error)
+	GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error)
+	SavePreAuthorization(a domain.PreAuthorization) (domain.PreAuthorization, error)
+	UpdatePreAuthorizationStatus(id int, status, reference, reason string, decidedAt time.Time) error
+}
+
+// NewSQLInsurance - Inicializa interface InsuranceStore
+func NewSQLInsurance() InsuranceStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &insuranceStore{db: database}
+}
+
+type insuranceStore struct {
+	db *sql.DB
+}
+
+// coverageRules - carrega as regras de cobertura de um plano
+func (si *insuranceStore) coverageRules(planID int) ([]domain.CoverageRule, error) {
+	rows, err := si.db.Query("SELECT procedure_code, percent, requires_authorization FROM coverage_rules WHERE id_plan = ? ORDER BY procedure_code", planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []domain.CoverageRule{}
+	for rows.Next() {
+		var rule domain.CoverageRule
+		if err := rows.Scan(&rule.ProcedureCode, &rule.Percent, &rule.RequiresAuthorization); err != nil {
+			return rules, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetInsurancePlans - retorna todos os planos de convênio com suas coberturas
+func (si *insuranceStore) GetInsurancePlans() ([]domain.InsurancePlan, error) {
+	rows, err := si.db.Query("SELECT id, name, insurer FROM insurance_plans ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []domain.InsurancePlan
+	for rows.Next() {
+		var plan domain.InsurancePlan
+		if err := rows.Scan(&plan.Id, &plan.Name, &plan.Insurer); err != nil {
+			return plans, err
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return plans, err
+	}
+	for i := range plans {
+		if plans[i].Coverage, err = si.coverageRules(plans[i].Id); err != nil {
+			return plans, err
+		}
+	}
+	return plans, nil
+}
+
+// GetInsurancePlanByID - retorna um plano de convênio com suas coberturas
+func (si *insuranceStore) GetInsurancePlanByID(id int) (domain.InsurancePlan, error) {
+	var plan domain.InsurancePlan
+	err := si.db.QueryRow("SELECT id, name, insurer FROM insurance_plans WHERE id = ?", id).Scan(&plan.Id, &plan.Name, &plan.Insurer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return plan, ErrNotFound
+	}
+	if err != nil {
+		return plan, err
+	}
+	plan.Coverage, err = si.coverageRules(plan.Id)
+	return plan, err
+}
+
+// SaveInsurancePlan - insere o plano e suas coberturas em uma única transação
+func (si *insuranceStore) SaveInsurancePlan(p domain.InsurancePlan) (domain.InsurancePlan, error) {
+	tx, err := si.db.Begin()
+	if err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO insurance_plans(name, insurer) VALUES (?,?)", p.Name, p.Insurer)
+	if err != nil {
+		return domain.InsurancePlan{}, mapError(err)
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	if err := insertCoverageRules(tx, int(lastInsertedID), p.Coverage); err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.InsurancePlan{}, err
+	}
+	return si.GetInsurancePlanByID(int(lastInsertedID))
+}
+
+// UpdateInsurancePlan - substitui os dados e as coberturas de um plano
+func (si *insuranceStore) UpdateInsurancePlan(p domain.InsurancePlan) error {
+	tx, err := si.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE insurance_plans SET name = ?, insurer = ? WHERE id = ?", p.Name, p.Insurer, p.Id)
+	if err := expectOneRow(result, mapError(err)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM coverage_rules WHERE id_plan = ?", p.Id); err != nil {
+		return err
+	}
+	if err := insertCoverageRules(tx, p.Id, p.Coverage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertCoverageRules(tx *sql.Tx, planID int, rules []domain.CoverageRule) error {
+	for _, rule := range rules {
+		if _, err := tx.Exec("INSERT INTO coverage_rules(id_plan, procedure_code, percent, requires_authorization) VALUES (?,?,?,?)",
+			planID,
+			rule.ProcedureCode,
+			rule.Percent,
+			rule.RequiresAuthorization); err != nil {
+			return mapError(err)
+		}
+	}
+	return nil
+}
+
+const membershipColumns = "m.id, m.id_patient, m.id_plan, ip.name, m.card_number, DATE_FORMAT(m.valid_from,'%d/%m/%Y'), DATE_FORMAT(m.valid_until,'%d/%m/%Y')"
+
+func scanMembership(row rowScanner) (domain.Membership, error) {
+	var membership domain.Membership
+	var validUntil sql.NullString
+	err := row.Scan(
+		&membership.Id,
+		&membership.IdPatient,
+		&membership.IdPlan,
+		&membership.PlanName,
+		&membership.CardNumber,
+		&membership.ValidFrom,
+		&validUntil)
+	membership.ValidUntil = validUntil.String
+	return membership, err
+}
+
+// GetMembershipByID - retorna uma adesão a convênio por id
+func (si *insuranceStore) GetMembershipByID(id int) (domain.Membership, error) {
+	membership, err := scanMembership(si.db.QueryRow("SELECT "+membershipColumns+" FROM insurance_memberships m INNER JOIN insurance_plans ip on m.id_plan = ip.id WHERE m.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return membership, ErrNotFound
+	}
+	return membership, err
+}
+
+// GetMemberships - retorna as adesões a convênios de um paciente, da mais recente à mais antiga
+func (si *insuranceStore) GetMemberships(patientID int) ([]domain.Membership, error) {
+	rows, err := si.db.Query("SELECT "+membershipColumns+" FROM insurance_memberships m INNER JOIN insurance_plans ip on m.id_plan = ip.id WHERE m.id_patient = ? ORDER BY m.valid_from DESC", patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []domain.Membership{}
+	for rows.Next() {
+		membership, err := scanMembership(rows)
+		if err != nil {
+			return memberships, err
+		}
+		memberships = append(memberships, membership)
+	}
+	return memberships, rows.Err()
+}
+
+// SaveMembership - insere uma nova adesão de paciente a um convênio
+func (si *insuranceStore) SaveMembership(m domain.Membership) (domain.Membership, error) {
+	validFrom, err := time.Parse("02/01/2006", m.ValidFrom)
+	if err != nil {
+		return domain.Membership{}, err
+	}
+	var validUntil interface{}
+	if m.ValidUntil != "" {
+		parsed, err := time.Parse("02/01/2006", m.ValidUntil)
+		if err != nil {
+			return domain.Membership{}, err
+		}
+		validUntil = parsed
+	}
+	result, err := si.db.Exec("INSERT INTO insurance_memberships(id_patient, id_plan, card_number, valid_from, valid_until) VALUES (?,?,?,?,?)",
+		m.IdPatient,
+		m.IdPlan,
+		m.CardNumber,
+		validFrom,
+		validUntil)
+	if err != nil {
+		return domain.Membership{}, mapError(err)
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.Membership{}, err
+	}
+	return si.GetMembershipByID(int(lastInsertedID))
+}
+
+// DeleteMembership - exclui uma adesão de um paciente
+func (si *insuranceStore) DeleteMembership(patientID, id int) error {
+	result, err := si.db.Exec("DELETE FROM insurance_memberships WHERE id = ? AND id_patient = ?", id, patientID)
+	return expectOneRow(result, err)
+}
+
+const preAuthorizationColumns = "a.id, a.id_patient, a.id_membership, a.id_appointment, a.id_treatment_plan, a.procedure_code, a.status, a.reference, a.reason, DATE_FORMAT(a.requested_at,'%d/%m/%Y %H:%i'), DATE_FORMAT(a.decided_at,'%d/%m/%Y %H:%i')"
+
+func scanPreAuthorization(row rowScanner) (domain.PreAuthorization, error) {
+	var authorization domain.PreAuthorization
+	var appointmentID, planID sql.NullInt64
+	var reference, reason, decidedAt sql.NullString
+	err := row.Scan(
+		&authorization.Id,
+		&authorization.IdPatient,
+		&authorization.IdMembership,
+		&appointmentID,
+		&planID,
+		&authorization.ProcedureCode,
+		&authorization.Status,
+		&reference,
+		&reason,
+		&authorization.RequestedAt,
+		&decidedAt)
+	authorization.IdAppointment = int(appointmentID.Int64)
+	authorization.IdTreatmentPlan = int(planID.Int64)
+	authorization.Reference = reference.String
+	authorization.Reason = reason.String
+	authorization.DecidedAt = decidedAt.String
+	return authorization, err
+}
+
+// GetPreAuthorizationByID - retorna um pedido de autorização prévia por id
+func (si *insuranceStore) GetPreAuthorizationByID(id int) (domain.PreAuthorization, error) {
+	authorization, err := scanPreAuthorization(si.db.QueryRow("SELECT "+preAuthorizationColumns+" FROM pre_authorizations a WHERE a.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return authorization, ErrNotFound
+	}
+	return authorization, err
+}
+
+// GetPreAuthorizations - retorna os pedidos de autorização prévia de um paciente, do mais recente ao mais antigo
+func (si *insuranceStore) GetPreAuthorizations(patientID int) ([]domain.PreAuthorization, error) {
+	rows, err := si.db.Query("SELECT "+preAuthorizationColumns+" FROM pre_authorizations a WHERE a.id_patient = ? ORDER BY a.requested_at DESC, a.id DESC", patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authorizations := []domain.PreAuthorization{}
+	for rows.Next() {
+		authorization, err := scanPreAuthorization(rows)
+		if err != nil {
+			return authorizations, err
+		}
+		authorizations = append(authorizations, authorization)
+	}
+	return authorizations, rows.Err()
+}
+
+// SavePreAuthorization - insere um novo pedido de autorização prévia
+func (si *insuranceStore) SavePreAuthorization(a domain.PreAuthorization) (domain.PreAuthorization, error) {
+	var appointmentID, planID interface{}
+	if a.IdAppointment != 0 {
+		appointmentID = a.IdAppointment
+	}
+	if a.IdTreatmentPlan != 0 {
+		planID = a.IdTreatmentPlan
+	}
+	result, err := si.db.Exec("INSERT INTO pre_authorizations(id_patient, id_membership, id_appointment, id_treatment_plan, procedure_code, status, requested_at) VALUES (?,?,?,?,?,?,?)",
+		a.IdPatient,
+		a.IdMembership,
+		appointmentID,
+		planID,
+		a.ProcedureCode,
+		a.Status,
+		time.Now())
+	if err != nil {
+		return domain.PreAuthorization{}, err
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.PreAuthorization{}, err
+	}
+	return si.GetPreAuthorizationByID(int(lastInsertedID))
+}
+
+// UpdatePreAuthorizationStatus - registra a decisão do convênio sobre um pedido ainda pendente
+func (si *insuranceStore) UpdatePreAuthorizationStatus(id int, status, reference, reason string, decidedAt time.Time) error {
+	result, err := si.db.Exec("UPDATE pre_authorizations SET status = ?, reference = COALESCE(?, reference), reason = ?, decided_at = ? WHERE id = ? AND status = ?",
+		status,
+		nullString(reference),
+		nullString(reason),
+		decidedAt,
+		id,
+		domain.AuthorizationRequested)
+	return expectOneRow(result, err)
+}
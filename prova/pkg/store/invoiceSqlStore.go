@@ -0,0 +1,272 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// InvoiceStore - Define o contrato de persistência das faturas e pagamentos.
+type InvoiceStore interface {
+	GetInvoiceByID(id int) (domain.Invoice, error)
+	GetInvoices(patientID int) ([]domain.Invoice, error)
+	SaveInvoice(i domain.Invoice) (domain.Invoice, error)
+	SavePayment(p domain.Payment, decide func(domain.Invoice) (string, error)) error
+	CancelInvoice(id int) error
+	GetPatientBalance(patientID int) (domain.PatientBalance, error)
+}
+
+// NewSQLInvoice - Inicializa interface InvoiceStore
+func NewSQLInvoice() InvoiceStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &invoiceStore{db: database}
+}
+
+type invoiceStore struct {
+	db *sql.DB
+}
+
+const invoiceColumns = "i.id, i.id_patient, i.id_membership, i.status, DATE_FORMAT(i.issued_at,'%d/%m/%Y %H:%i'), i.subtotal, i.discount, i.tax_rate, i.tax, i.total, i.insurer_portion, i.patient_portion, COALESCE((SELECT SUM(py.amount) FROM payments py WHERE py.id_invoice = i.id), 0)"
+
+func scanInvoice(row rowScanner) (domain.Invoice, error) {
+	var invoice domain.Invoice
+	var membershipID sql.NullInt64
+	err := row.Scan(
+		&invoice.Id,
+		&invoice.IdPatient,
+		&membershipID,
+		&invoice.Status,
+		&invoice.IssuedAt,
+		&invoice.Subtotal,
+		&invoice.Discount,
+		&invoice.TaxRate,
+		&invoice.Tax,
+		&invoice.Total,
+		&invoice.InsurerPortion,
+		&invoice.PatientPortion,
+		&invoice.Paid)
+	invoice.IdMembership = int(membershipID.Int64)
+	invoice.Balance = invoice.Total - invoice.Paid
+	return invoice, err
+}
+
+// invoiceDetails - carrega os itens e os pagamentos de uma fatura
+func (si *invoiceStore) invoiceDetails(invoice *domain.Invoice) error {
+	rows, err := si.db.Query("SELECT id, id_appointment, procedure_code, description, amount, covered_amount FROM invoice_items WHERE id_invoice = ? ORDER BY id", invoice.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	invoice.Items = []domain.InvoiceItem{}
+	for rows.Next() {
+		var item domain.InvoiceItem
+		if err := rows.Scan(&item.Id, &item.IdAppointment, &item.ProcedureCode, &item.Description, &item.Amount, &item.CoveredAmount); err != nil {
+			return err
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	payments, err := si.db.Query("SELECT id, id_invoice, method, amount, DATE_FORMAT(paid_at,'%d/%m/%Y %H:%i') FROM payments WHERE id_invoice = ? ORDER BY paid_at, id", invoice.Id)
+	if err != nil {
+		return err
+	}
+	defer payments.Close()
+
+	invoice.Payments = []domain.Payment{}
+	for payments.Next() {
+		var payment domain.Payment
+		if err := payments.Scan(&payment.Id, &payment.IdInvoice, &payment.Method, &payment.Amount, &payment.PaidAt); err != nil {
+			return err
+		}
+		invoice.Payments = append(invoice.Payments, payment)
+	}
+	return payments.Err()
+}
+
+// GetInvoiceByID - retorna uma fatura com seus itens e pagamentos
+func (si *invoiceStore) GetInvoiceByID(id int) (domain.Invoice, error) {
+	invoice, err := scanInvoice(si.db.QueryRow("SELECT "+invoiceColumns+" FROM invoices i WHERE i.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return invoice, ErrNotFound
+	}
+	if err != nil {
+		return invoice, err
+	}
+	return invoice, si.invoiceDetails(&invoice)
+}
+
+// GetInvoices - retorna as faturas de um paciente, ou de todos quando patientID é zero
+func (si *invoiceStore) GetInvoices(patientID int) ([]domain.Invoice, error) {
+	rows, err := si.db.Query("SELECT "+invoiceColumns+" FROM invoices i WHERE ? = 0 OR i.id_patient = ? ORDER BY i.issued_at DESC", patientID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []domain.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return invoices, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return invoices, err
+	}
+	for i := range invoices {
+		if err := si.invoiceDetails(&invoices[i]); err != nil {
+			return invoices, err
+		}
+	}
+	return invoices, nil
+}
+
+// SaveInvoice - insere a fatura e seus itens em uma única transação. O índice único em billed_appointment
+// garante que cada consulta esteja em apenas uma fatura não cancelada.
+func (si *invoiceStore) SaveInvoice(i domain.Invoice) (domain.Invoice, error) {
+	tx, err := si.db.Begin()
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	defer tx.Rollback()
+
+	var membershipID interface{}
+	if i.IdMembership != 0 {
+		membershipID = i.IdMembership
+	}
+	result, err := tx.Exec("INSERT INTO invoices(id_patient, id_membership, status, issued_at, subtotal, discount, tax_rate, tax, total, insurer_portion, patient_portion) VALUES (?,?,?,?,?,?,?,?,?,?,?)",
+		i.IdPatient,
+		membershipID,
+		i.Status,
+		time.Now(),
+		i.Subtotal,
+		i.Discount,
+		i.TaxRate,
+		i.Tax,
+		i.Total,
+		i.InsurerPortion,
+		i.PatientPortion)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	for _, item := range i.Items {
+		if _, err := tx.Exec("INSERT INTO invoice_items(id_invoice, id_appointment, billed_appointment, procedure_code, description, amount, covered_amount) VALUES (?,?,?,?,?,?,?)",
+			lastInsertedID,
+			item.IdAppointment,
+			item.IdAppointment,
+			item.ProcedureCode,
+			item.Description,
+			item.Amount,
+			item.CoveredAmount); err != nil {
+			return domain.Invoice{}, mapError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Invoice{}, err
+	}
+	return si.GetInvoiceByID(int(lastInsertedID))
+}
+
+// SavePayment - registra um pagamento e atualiza a situação da fatura na mesma transação. A fatura e os seus
+// pagamentos são lidos com bloqueio e entregues a decide, que valida o valor e devolve a nova situação; assim dois
+// pagamentos simultâneos não conseguem ultrapassar o saldo.
+func (si *invoiceStore) SavePayment(p domain.Payment, decide func(domain.Invoice) (string, error)) error {
+	tx, err := si.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	invoice, err := scanInvoice(tx.QueryRow("SELECT "+invoiceColumns+" FROM invoices i WHERE i.id = ? FOR UPDATE", p.IdInvoice))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// A soma de invoiceColumns vem de uma leitura sem bloqueio, por isso os pagamentos são relidos aqui
+	rows, err := tx.Query("SELECT id, id_invoice, method, amount, DATE_FORMAT(paid_at,'%d/%m/%Y %H:%i') FROM payments WHERE id_invoice = ? ORDER BY paid_at, id FOR UPDATE", p.IdInvoice)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	invoice.Payments = []domain.Payment{}
+	invoice.Paid = 0
+	for rows.Next() {
+		var payment domain.Payment
+		if err := rows.Scan(&payment.Id, &payment.IdInvoice, &payment.Method, &payment.Amount, &payment.PaidAt); err != nil {
+			return err
+		}
+		invoice.Payments = append(invoice.Payments, payment)
+		invoice.Paid += payment.Amount
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	invoice.Balance = invoice.Total - invoice.Paid
+
+	invoiceStatus, err := decide(invoice)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO payments(id_invoice, method, amount, paid_at) VALUES (?,?,?,?)",
+		p.IdInvoice,
+		p.Method,
+		p.Amount,
+		time.Now()); err != nil {
+		return err
+	}
+	result, err := tx.Exec("UPDATE invoices SET status = ? WHERE id = ?", invoiceStatus, p.IdInvoice)
+	if err := expectOneRow(result, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CancelInvoice - cancela a fatura e libera as suas consultas para serem faturadas novamente
+func (si *invoiceStore) CancelInvoice(id int) error {
+	tx, err := si.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE invoices SET status = ? WHERE id = ?", domain.InvoiceCancelled, id)
+	if err := expectOneRow(result, err); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE invoice_items SET billed_appointment = NULL WHERE id_invoice = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPatientBalance - calcula o total faturado, pago e em aberto de um paciente, ignorando faturas canceladas
+func (si *invoiceStore) GetPatientBalance(patientID int) (domain.PatientBalance, error) {
+	balance := domain.PatientBalance{IdPatient: patientID}
+	err := si.db.QueryRow(`SELECT
+			COALESCE(SUM(i.total), 0),
+			COALESCE(SUM((SELECT COALESCE(SUM(py.amount), 0) FROM payments py WHERE py.id_invoice = i.id)), 0),
+			COUNT(CASE WHEN i.status IN ('open', 'partially_paid') THEN 1 END)
+		FROM invoices i WHERE i.id_patient = ? AND i.status <> 'cancelled'`, patientID).Scan(
+		&balance.Invoiced,
+		&balance.Paid,
+		&balance.OpenInvoices)
+	balance.Balance = balance.Invoiced - balance.Paid
+	return balance, err
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// LinkStore - Define o contrato de persistência dos links de confirmação e cancelamento de consultas.
+type LinkStore interface {
+	GetLink(nonce string) (domain.AppointmentLink, error)
+	SaveLink(l domain.AppointmentLink, expiresAt time.Time) error
+	UseLink(l domain.AppointmentLink, usedAt time.Time) error
+	ReleaseLink(nonce string) error
+}
+
+// NewSQLLink - Inicializa interface LinkStore
+func NewSQLLink() LinkStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &linkStore{db: database}
+}
+
+type linkStore struct {
+	db *sql.DB
+}
+
+// GetLink - retorna um link emitido pelo seu identificador único
+func (sl *linkStore) GetLink(nonce string) (domain.AppointmentLink, error) {
+	var link domain.AppointmentLink
+	var usedAt, usedIP, userAgent, previousStatus, newStatus sql.NullString
+	err := sl.db.QueryRow("SELECT nonce, id_appointment, action, DATE_FORMAT(expires_at,'%d/%m/%Y %H:%i'), DATE_FORMAT(used_at,'%d/%m/%Y %H:%i'), used_ip, used_user_agent, previous_status, new_status FROM appointment_links WHERE nonce = ?", nonce).Scan(
+		&link.Nonce,
+		&link.IdAppointment,
+		&link.Action,
+		&link.ExpiresAt,
+		&usedAt,
+		&usedIP,
+		&userAgent,
+		&previousStatus,
+		&newStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, ErrNotFound
+	}
+	link.UsedAt = usedAt.String
+	link.UsedIP = usedIP.String
+	link.UsedUserAgent = userAgent.String
+	link.PreviousStatus = previousStatus.String
+	link.NewStatus = newStatus.String
+	return link, err
+}
+
+// SaveLink - registra um link emitido
+func (sl *linkStore) SaveLink(l domain.AppointmentLink, expiresAt time.Time) error {
+	_, err := sl.db.Exec("INSERT INTO appointment_links(nonce, id_appointment, action, created_at, expires_at) VALUES (?,?,?,?,?)",
+		l.Nonce,
+		l.IdAppointment,
+		l.Action,
+		time.Now(),
+		expiresAt)
+	return mapError(err)
+}
+
+// UseLink - marca o link como usado, registrando quem usou e a mudança de situação da consulta.
+// Retorna ErrNotFound quando o link já foi usado, garantindo o uso único mesmo com acessos simultâneos.
+func (sl *linkStore) UseLink(l domain.AppointmentLink, usedAt time.Time) error {
+	result, err := sl.db.Exec("UPDATE appointment_links SET used_at = ?, used_ip = ?, used_user_agent = ?, previous_status = ?, new_status = ? WHERE nonce = ? AND used_at IS NULL",
+		usedAt,
+		nullString(l.UsedIP),
+		nullString(l.UsedUserAgent),
+		l.PreviousStatus,
+		l.NewStatus,
+		l.Nonce)
+	return expectOneRow(result, err)
+}
+
+// ReleaseLink - desfaz o uso de um link cuja ação não pôde ser aplicada à consulta
+func (sl *linkStore) ReleaseLink(nonce string) error {
+	_, err := sl.db.Exec("UPDATE appointment_links SET used_at = NULL, used_ip = NULL, used_user_agent = NULL, previous_status = NULL, new_status = NULL WHERE nonce = ?", nonce)
+	return err
+}
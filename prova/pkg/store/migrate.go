@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// migrations são os arquivos do esquema, aplicados em ordem de nome
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate aplica as migrações ainda não registradas na tabela schema_migrations e retorna os
+// nomes das aplicadas. O MySQL não desfaz alterações de esquema, por isso cada arquivo é
+// registrado logo após ser executado e uma falha interrompe as seguintes. Todas as instruções
+// rodam na mesma conexão, de modo que variáveis de sessão e PREPARE valem até o fim do arquivo.
+func Migrate(db *sql.DB) ([]string, error) {
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"name varchar(100) NOT NULL PRIMARY KEY, " +
+		"applied_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP)"); err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool)
+	rows, err := db.Query("SELECT name FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		done[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	sort.Strings(names)
+	var applied []string
+	for _, path := range names {
+		name := strings.TrimPrefix(path, "migrations/")
+		if done[name] {
+			continue
+		}
+		script, err := migrations.ReadFile(path)
+		if err != nil {
+			return applied, err
+		}
+		for _, statement := range Statements(string(script)) {
+			if _, err := conn.ExecContext(context.Background(), statement); err != nil {
+				return applied, fmt.Errorf("migration %s: %w", name, err)
+			}
+		}
+		if _, err := conn.ExecContext(context.Background(), "INSERT INTO schema_migrations (name) VALUES (?)", name); err != nil {
+			return applied, err
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
+
+// Seed executa as instruções INSERT de um script SQL, como o config/db.sql, numa única transação;
+// as demais instruções, como DROP e CREATE, são ignoradas. Retorna a quantidade de linhas incluídas.
+func Seed(db *sql.DB, script string) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var total int64
+	for _, statement := range Statements(script) {
+		if !strings.HasPrefix(strings.ToUpper(statement), "INSERT") {
+			continue
+		}
+		result, err := tx.Exec(statement)
+		if err != nil {
+			return 0, mapError(err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, tx.Commit()
+}
+
+// Statements separa um script SQL em instruções, sem os comentários de linha. O ponto e vírgula
+// dentro de textos e nomes entre crases não encerra a instrução.
+func Statements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && quote != '`' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == ';':
+			if s := strings.TrimSpace(current.String()); s != "" {
+				statements = append(statements, s)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		statements = append(statements, s)
+	}
+	return statements
+}
@@ -0,0 +1,297 @@
+-- Esquema inicial, o mesmo de config/db.sql, sem os dados de exemplo. As alterações seguintes
+-- entram em novos arquivos numerados; os já aplicados não devem ser editados.
+
+CREATE TABLE IF NOT EXISTS `dentists` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` varchar(50) DEFAULT NULL,
+  `surname` varchar(50) DEFAULT NULL,
+  `registration` varchar(50) NOT NULL,
+  UNIQUE KEY `uq_dentists_registration` (`registration`)
+);
+
+CREATE TABLE IF NOT EXISTS `patients` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` varchar(50) DEFAULT NULL,
+  `surname` varchar(50) DEFAULT NULL,
+  `document` varchar(50) DEFAULT NULL,
+  `createdAt` varchar(50) DEFAULT NULL,
+  `email` varchar(100) DEFAULT NULL,
+  `phones` json DEFAULT NULL,
+  `birth_date` date DEFAULT NULL,
+  `address` json DEFAULT NULL,
+  `guardian` json DEFAULT NULL,
+  `emergency_contact` json DEFAULT NULL,
+  `preferred_language` varchar(10) DEFAULT NULL,
+  `consent` json DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `appointments` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `idDentist` int NOT NULL,
+  `idPatient` int NOT NULL,
+  `appointmentDate` varchar(50) NOT NULL,
+  `description` varchar(250) NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'scheduled',
+  `procedure_code` varchar(20) NOT NULL DEFAULT '',
+  `duration` int NOT NULL DEFAULT 30,
+  FOREIGN KEY (idDentist) REFERENCES dentists (id),
+  FOREIGN KEY (idPatient) REFERENCES patients (id)
+);
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `username` varchar(50) NOT NULL,
+  `password_hash` varchar(100) NOT NULL,
+  `role` varchar(20) NOT NULL,
+  `id_dentist` int DEFAULT NULL,
+  UNIQUE KEY `uq_users_username` (`username`),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+CREATE TABLE IF NOT EXISTS `clinical_notes` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_appointment` int NOT NULL,
+  `id_patient` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `id_parent` int DEFAULT NULL,
+  `content` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  `signed_at` datetime DEFAULT NULL,
+  KEY `idx_clinical_notes_patient` (`id_patient`, `created_at`),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id),
+  FOREIGN KEY (id_parent) REFERENCES clinical_notes (id)
+);
+
+CREATE TABLE IF NOT EXISTS `dental_chart_entries` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_appointment` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `tooth` tinyint NOT NULL,
+  `surface` char(1) NOT NULL DEFAULT '',
+  `condition` varchar(30) NOT NULL,
+  `notes` varchar(250) NOT NULL DEFAULT '',
+  `recorded_at` datetime NOT NULL,
+  KEY `idx_dental_chart_entries_patient` (`id_patient`, `recorded_at`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+CREATE TABLE IF NOT EXISTS `procedures` (
+  `code` varchar(20) NOT NULL PRIMARY KEY,
+  `name` varchar(100) NOT NULL,
+  `duration` int NOT NULL,
+  `price` decimal(10,2) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `treatment_plans` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_dentist` int NOT NULL,
+  `title` varchar(100) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `accepted_at` datetime DEFAULT NULL,
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_dentist) REFERENCES dentists (id)
+);
+
+CREATE TABLE IF NOT EXISTS `treatment_plan_items` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_plan` int NOT NULL,
+  `position` int NOT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `tooth` tinyint DEFAULT NULL,
+  `price` decimal(10,2) NOT NULL,
+  `id_appointment` int DEFAULT NULL,
+  FOREIGN KEY (id_plan) REFERENCES treatment_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+CREATE TABLE IF NOT EXISTS `insurance_plans` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` varchar(100) NOT NULL,
+  `insurer` varchar(100) NOT NULL,
+  UNIQUE KEY `uq_insurance_plans_name` (`insurer`, `name`)
+);
+
+CREATE TABLE IF NOT EXISTS `coverage_rules` (
+  `id_plan` int NOT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `percent` decimal(5,2) NOT NULL,
+  `requires_authorization` boolean NOT NULL DEFAULT false,
+  PRIMARY KEY (`id_plan`, `procedure_code`),
+  FOREIGN KEY (id_plan) REFERENCES insurance_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code)
+);
+
+CREATE TABLE IF NOT EXISTS `insurance_memberships` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_plan` int NOT NULL,
+  `card_number` varchar(50) NOT NULL,
+  `valid_from` date NOT NULL,
+  `valid_until` date DEFAULT NULL,
+  UNIQUE KEY `uq_insurance_memberships_card` (`id_plan`, `card_number`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_plan) REFERENCES insurance_plans (id)
+);
+
+CREATE TABLE IF NOT EXISTS `pre_authorizations` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_membership` int NOT NULL,
+  `id_appointment` int DEFAULT NULL,
+  `id_treatment_plan` int DEFAULT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `reference` varchar(100) DEFAULT NULL,
+  `reason` varchar(250) DEFAULT NULL,
+  `requested_at` datetime NOT NULL,
+  `decided_at` datetime DEFAULT NULL,
+  KEY `idx_pre_authorizations_patient` (`id_patient`),
+  FOREIGN KEY (id_membership) REFERENCES insurance_memberships (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id),
+  FOREIGN KEY (id_treatment_plan) REFERENCES treatment_plans (id),
+  FOREIGN KEY (procedure_code) REFERENCES procedures (code)
+);
+
+CREATE TABLE IF NOT EXISTS `invoices` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_patient` int NOT NULL,
+  `id_membership` int DEFAULT NULL,
+  `status` varchar(20) NOT NULL,
+  `issued_at` datetime NOT NULL,
+  `subtotal` decimal(10,2) NOT NULL,
+  `discount` decimal(10,2) NOT NULL DEFAULT 0,
+  `tax_rate` decimal(5,2) NOT NULL DEFAULT 0,
+  `tax` decimal(10,2) NOT NULL DEFAULT 0,
+  `total` decimal(10,2) NOT NULL,
+  `insurer_portion` decimal(10,2) NOT NULL DEFAULT 0,
+  `patient_portion` decimal(10,2) NOT NULL,
+  KEY `idx_invoices_patient` (`id_patient`),
+  FOREIGN KEY (id_patient) REFERENCES patients (id),
+  FOREIGN KEY (id_membership) REFERENCES insurance_memberships (id)
+);
+
+CREATE TABLE IF NOT EXISTS `invoice_items` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_invoice` int NOT NULL,
+  `id_appointment` int NOT NULL,
+  `billed_appointment` int DEFAULT NULL,
+  `procedure_code` varchar(20) NOT NULL,
+  `description` varchar(250) NOT NULL,
+  `amount` decimal(10,2) NOT NULL,
+  `covered_amount` decimal(10,2) NOT NULL DEFAULT 0,
+  UNIQUE KEY `uq_invoice_items_billed_appointment` (`billed_appointment`),
+  FOREIGN KEY (id_invoice) REFERENCES invoices (id),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+CREATE TABLE IF NOT EXISTS `payments` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_invoice` int NOT NULL,
+  `method` varchar(20) NOT NULL,
+  `amount` decimal(10,2) NOT NULL,
+  `paid_at` datetime NOT NULL,
+  FOREIGN KEY (id_invoice) REFERENCES invoices (id)
+);
+
+CREATE TABLE IF NOT EXISTS `appointment_reminders` (
+  `id_appointment` int NOT NULL,
+  `kind` varchar(10) NOT NULL,
+  `channel` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `sent_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id_appointment`, `kind`, `channel`),
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+CREATE TABLE IF NOT EXISTS `appointment_links` (
+  `nonce` char(32) NOT NULL PRIMARY KEY,
+  `id_appointment` int NOT NULL,
+  `action` varchar(10) NOT NULL,
+  `created_at` datetime NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `used_ip` varchar(45) DEFAULT NULL,
+  `used_user_agent` varchar(255) DEFAULT NULL,
+  `previous_status` varchar(20) DEFAULT NULL,
+  `new_status` varchar(20) DEFAULT NULL,
+  FOREIGN KEY (id_appointment) REFERENCES appointments (id)
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_subscriptions` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `url` varchar(500) NOT NULL,
+  `secret` varchar(100) NOT NULL,
+  `events` json NOT NULL,
+  `active` boolean NOT NULL DEFAULT true,
+  `created_at` datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_subscription` int NOT NULL,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(50) NOT NULL,
+  `payload` json NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime DEFAULT NULL,
+  `last_error` varchar(500) DEFAULT NULL,
+  `response_status` int DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `delivered_at` datetime DEFAULT NULL,
+  UNIQUE KEY `uq_webhook_deliveries_event` (`id_subscription`, `event_id`),
+  KEY `idx_webhook_deliveries_due` (`status`, `next_attempt_at`),
+  FOREIGN KEY (id_subscription) REFERENCES webhook_subscriptions (id)
+);
+
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(50) NOT NULL,
+  `aggregate` varchar(20) NOT NULL,
+  `aggregate_id` int NOT NULL,
+  `payload` json NOT NULL,
+  `created_at` datetime NOT NULL,
+  `published_at` datetime DEFAULT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` varchar(500) DEFAULT NULL,
+  UNIQUE KEY `uq_outbox_events_event` (`event_id`),
+  KEY `idx_outbox_events_pending` (`published_at`, `id`)
+);
+
+CREATE TABLE IF NOT EXISTS `calendar_feeds` (
+  `owner` varchar(20) NOT NULL,
+  `id_owner` int NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`owner`, `id_owner`)
+);
+
+CREATE TABLE IF NOT EXISTS `schedule_blocks` (
+  `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `id_dentist` varchar(50) NOT NULL,
+  `uid` varchar(255) NOT NULL,
+  `href` varchar(255) NOT NULL,
+  `start_at` datetime NOT NULL,
+  `end_at` datetime NOT NULL,
+  `summary` varchar(255) NOT NULL DEFAULT '',
+  UNIQUE KEY `uq_schedule_blocks_href` (`id_dentist`, `href`),
+  KEY `idx_schedule_blocks_interval` (`start_at`, `end_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `caldav_sync_states` (
+  `id_dentist` int NOT NULL,
+  `token` char(64) NOT NULL,
+  `state` json NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id_dentist`, `token`)
+);
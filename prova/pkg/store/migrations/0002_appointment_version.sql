@@ -0,0 +1,4 @@
+-- Versão da consulta para o controle de concorrência otimista: cresce a cada atualização e é
+-- conferida no próprio UPDATE e DELETE.
+
+ALTER TABLE `appointments` ADD COLUMN `version` int NOT NULL DEFAULT 1;
@@ -0,0 +1,46 @@
+-- Colunas incluídas depois do esquema original de config/db.sql. Num banco criado por aquele
+-- script, a 0001 não altera dentists, patients e appointments, que já existem; aqui cada tabela
+-- recebe só as colunas que ainda não tem, de modo que o arquivo também roda num banco criado
+-- pela 0001. O ALTER é montado a partir do information_schema e executado com PREPARE.
+
+SET @missing = (SELECT GROUP_CONCAT(CONCAT('ADD COLUMN `', c.name, '` ', c.definition) SEPARATOR ', ')
+  FROM (
+    SELECT 'email' name, 'varchar(100) DEFAULT NULL' definition
+    UNION ALL SELECT 'phones', 'json DEFAULT NULL'
+    UNION ALL SELECT 'birth_date', 'date DEFAULT NULL'
+    UNION ALL SELECT 'address', 'json DEFAULT NULL'
+    UNION ALL SELECT 'guardian', 'json DEFAULT NULL'
+    UNION ALL SELECT 'emergency_contact', 'json DEFAULT NULL'
+    UNION ALL SELECT 'preferred_language', 'varchar(10) DEFAULT NULL'
+    UNION ALL SELECT 'consent', 'json DEFAULT NULL'
+  ) c
+  WHERE NOT EXISTS (SELECT 1 FROM information_schema.columns i
+    WHERE i.table_schema = DATABASE() AND i.table_name = 'patients' AND i.column_name = c.name));
+SET @statement = IFNULL(CONCAT('ALTER TABLE `patients` ', @missing), 'DO 0');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
+
+SET @missing = (SELECT GROUP_CONCAT(CONCAT('ADD COLUMN `', c.name, '` ', c.definition) SEPARATOR ', ')
+  FROM (
+    SELECT 'status' name, 'varchar(20) NOT NULL DEFAULT ''scheduled''' definition
+    UNION ALL SELECT 'procedure_code', 'varchar(20) NOT NULL DEFAULT '''''
+    UNION ALL SELECT 'duration', 'int NOT NULL DEFAULT 30'
+  ) c
+  WHERE NOT EXISTS (SELECT 1 FROM information_schema.columns i
+    WHERE i.table_schema = DATABASE() AND i.table_name = 'appointments' AND i.column_name = c.name));
+SET @statement = IFNULL(CONCAT('ALTER TABLE `appointments` ', @missing), 'DO 0');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
+
+-- o CRO passou a ser obrigatório e único; números duplicados precisam ser corrigidos antes
+ALTER TABLE `dentists` MODIFY COLUMN `registration` varchar(50) NOT NULL;
+
+SET @statement = IF(EXISTS (SELECT 1 FROM information_schema.statistics
+    WHERE table_schema = DATABASE() AND table_name = 'dentists' AND index_name = 'uq_dentists_registration'),
+  'DO 0',
+  'ALTER TABLE `dentists` ADD UNIQUE KEY `uq_dentists_registration` (`registration`)');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
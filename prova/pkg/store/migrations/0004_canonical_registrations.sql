@@ -0,0 +1,29 @@
+-- Normaliza os números do CRO já gravados para o formato canônico (CRO-UF N), o mesmo de
+-- domain.Registration.String. As consultas e os horários bloqueados referenciam o dentista pelo
+-- número, então as três tabelas mudam na mesma transação. Números fora do padrão, sem a UF, ficam
+-- como estão e precisam ser corrigidos pela API.
+
+SET @canonical = '^CRO[-/[:space:]]*([A-Z]{2})[-/[:space:]]*0*([0-9]{1,6})$';
+
+START TRANSACTION;
+
+-- num banco criado pela 0001, appointments ainda não tem a coluna id_dentist
+SET @statement = IF(EXISTS (SELECT 1 FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'appointments' AND column_name = 'id_dentist'),
+  'UPDATE appointments a INNER JOIN dentists d ON a.id_dentist = d.registration
+     SET a.id_dentist = REGEXP_REPLACE(UPPER(TRIM(d.registration)), @canonical, ''CRO-$1 $2'')
+     WHERE UPPER(TRIM(d.registration)) REGEXP @canonical',
+  'DO 0');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
+
+UPDATE schedule_blocks b INNER JOIN dentists d ON b.id_dentist = d.registration
+  SET b.id_dentist = REGEXP_REPLACE(UPPER(TRIM(d.registration)), @canonical, 'CRO-$1 $2')
+  WHERE UPPER(TRIM(d.registration)) REGEXP @canonical;
+
+UPDATE dentists
+  SET registration = REGEXP_REPLACE(UPPER(TRIM(registration)), @canonical, 'CRO-$1 $2')
+  WHERE UPPER(TRIM(registration)) REGEXP @canonical;
+
+COMMIT;
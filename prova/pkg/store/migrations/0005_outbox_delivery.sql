@@ -0,0 +1,16 @@
+-- Controle de entrega do outbox: os destinos que já aceitaram o evento, a reserva do relay que o
+-- está publicando e o instante em que o evento foi abandonado depois de esgotar as tentativas.
+-- Como na 0003, só as colunas que ainda não existem são incluídas, já que config/db.sql as cria.
+
+SET @missing = (SELECT GROUP_CONCAT(CONCAT('ADD COLUMN `', c.name, '` ', c.definition) SEPARATOR ', ')
+  FROM (
+    SELECT 'delivered_sinks' name, 'varchar(200) NOT NULL DEFAULT ''''' definition
+    UNION ALL SELECT 'claimed_until', 'datetime DEFAULT NULL'
+    UNION ALL SELECT 'failed_at', 'datetime DEFAULT NULL'
+  ) c
+  WHERE NOT EXISTS (SELECT 1 FROM information_schema.columns i
+    WHERE i.table_schema = DATABASE() AND i.table_name = 'outbox_events' AND i.column_name = c.name));
+SET @statement = IFNULL(CONCAT('ALTER TABLE `outbox_events` ', @missing), 'DO 0');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
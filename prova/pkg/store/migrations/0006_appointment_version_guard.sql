@@ -0,0 +1,10 @@
+-- Garante a coluna de versão das consultas da 0002 nos bancos que ainda não a têm. Como na 0003,
+-- ela só é incluída se não existir, já que config/db.sql também a cria.
+
+SET @statement = IF(EXISTS (SELECT 1 FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'appointments' AND column_name = 'version'),
+  'DO 0',
+  'ALTER TABLE `appointments` ADD COLUMN `version` int NOT NULL DEFAULT 1');
+PREPARE migration FROM @statement;
+EXECUTE migration;
+DEALLOCATE PREPARE migration;
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// NoteStore - Define o contrato de persistência das notas clínicas.
+type NoteStore interface {
+	GetNoteByID(id int) (domain.ClinicalNote, error)
+	GetNotesByAppointment(appointmentID int) ([]domain.ClinicalNote, error)
+	GetNotesByPatient(patientID int) ([]domain.ClinicalNote, error)
+	SaveNote(n domain.ClinicalNote) (domain.ClinicalNote, error)
+	UpdateNoteContent(id int, content string) error
+	SignNote(id int, signedAt time.Time) error
+}
+
+// NewSQLNote - Inicializa interface NoteStore
+func NewSQLNote() NoteStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &noteStore{db: database}
+}
+
+type noteStore struct {
+	db *sql.DB
+}
+
+const noteColumns = "n.id, n.id_appointment, n.id_patient, n.id_dentist, n.id_parent, n.content, DATE_FORMAT(n.created_at,'%d/%m/%Y %H:%i'), DATE_FORMAT(n.signed_at,'%d/%m/%Y %H:%i')"
+
+func scanNote(row rowScanner) (domain.ClinicalNote, error) {
+	var note domain.ClinicalNote
+	var parentID sql.NullInt64
+	var signedAt sql.NullString
+	if err := row.Scan(
+		&note.Id,
+		&note.IdAppointment,
+		&note.IdPatient,
+		&note.IdDentist,
+		&parentID,
+		&note.Content,
+		&note.CreatedAt,
+		&signedAt); err != nil {
+		return note, err
+	}
+	note.IdParent = int(parentID.Int64)
+	note.SignedAt = signedAt.String
+	return note, nil
+}
+
+func (sn *noteStore) queryNotes(query string, args ...interface{}) ([]domain.ClinicalNote, error) {
+	rows, err := sn.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []domain.ClinicalNote
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return notes, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// GetNoteByID - retorna uma nota clínica por id
+func (sn *noteStore) GetNoteByID(id int) (domain.ClinicalNote, error) {
+	note, err := scanNote(sn.db.QueryRow("SELECT "+noteColumns+" FROM clinical_notes n WHERE n.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note, ErrNotFound
+	}
+	return note, err
+}
+
+// GetNotesByAppointment - retorna as notas clínicas e adendos de uma consulta em ordem cronológica
+func (sn *noteStore) GetNotesByAppointment(appointmentID int) ([]domain.ClinicalNote, error) {
+	return sn.queryNotes("SELECT "+noteColumns+" FROM clinical_notes n WHERE n.id_appointment = ? ORDER BY n.created_at, n.id", appointmentID)
+}
+
+// GetNotesByPatient - retorna o histórico de notas clínicas de um paciente em ordem cronológica
+func (sn *noteStore) GetNotesByPatient(patientID int) ([]domain.ClinicalNote, error) {
+	return sn.queryNotes("SELECT "+noteColumns+" FROM clinical_notes n WHERE n.id_patient = ? ORDER BY n.created_at, n.id", patientID)
+}
+
+// SaveNote - insere uma nova nota clínica ou adendo
+func (sn *noteStore) SaveNote(n domain.ClinicalNote) (domain.ClinicalNote, error) {
+	var parentID, signedAt interface{}
+	if n.IdParent != 0 {
+		parentID = n.IdParent
+	}
+	now := time.Now()
+	if n.IsSigned() {
+		signedAt = now
+	}
+	result, err := sn.db.Exec("INSERT INTO clinical_notes(id_appointment, id_patient, id_dentist, id_parent, content, created_at, signed_at) VALUES (?,?,?,?,?,?,?)",
+		n.IdAppointment,
+		n.IdPatient,
+		n.IdDentist,
+		parentID,
+		n.Content,
+		now,
+		signedAt)
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.ClinicalNote{}, err
+	}
+	return sn.GetNoteByID(int(lastInsertedID))
+}
+
+// UpdateNoteContent - altera o conteúdo de uma nota ainda não assinada
+func (sn *noteStore) UpdateNoteContent(id int, content string) error {
+	result, err := sn.db.Exec("UPDATE clinical_notes SET content = ? WHERE id = ? AND signed_at IS NULL", content, id)
+	return expectOneRow(result, err)
+}
+
+// SignNote - assina uma nota, tornando-a imutável
+func (sn *noteStore) SignNote(id int, signedAt time.Time) error {
+	result, err := sn.db.Exec("UPDATE clinical_notes SET signed_at = ? WHERE id = ? AND signed_at IS NULL", signedAt, id)
+	return expectOneRow(result, err)
+}
+
+// expectOneRow retorna ErrNotFound quando o comando não alterou nenhuma linha
+func expectOneRow(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
+)
+
+// OutboxStore - Define o contrato de leitura dos eventos gravados no outbox pelas alterações de consultas,
+// dentistas e pacientes.
+type OutboxStore interface {
+	ClaimPendingEvents(limit int, now time.Time, lease time.Duration) ([]domain.OutboxEvent, error)
+	MarkEventPublished(id int, at time.Time) error
+	MarkEventFailed(id int, reason string, deliveredSinks []string, retryAt time.Time, abandoned bool) error
+}
+
+// NewSQLOutbox - Inicializa interface OutboxStore
+func NewSQLOutbox() OutboxStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &outboxStore{db: database}
+}
+
+type outboxStore struct {
+	db *sql.DB
+}
+
+// insertEvent grava o evento no outbox dentro da transação da alteração que o gerou, de modo que o
+// evento só exista se a alteração for confirmada
+func insertEvent(tx *sql.Tx, eventType string, aggregate string, aggregateID int, data interface{}) error {
+	e := event.New(eventType, data)
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO outbox_events(event_id, event_type, aggregate, aggregate_id, payload, created_at) VALUES (?,?,?,?,?,?)",
+		e.Id,
+		e.Type,
+		aggregate,
+		aggregateID,
+		string(payload),
+		time.Now())
+	return err
+}
+
+// ClaimPendingEvents - reserva, por lease, os eventos pendentes que não estão reservados por outra instância do
+// relay, na ordem em que foram gravados. O SKIP LOCKED faz duas instâncias que reservam ao mesmo tempo
+// ficarem com eventos diferentes em vez de esperarem uma pela outra.
+func (so *outboxStore) ClaimPendingEvents(limit int, now time.Time, lease time.Duration) ([]domain.OutboxEvent, error) {
+	tx, err := so.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, payload, attempts, last_error, delivered_sinks FROM outbox_events WHERE published_at IS NULL AND failed_at IS NULL AND (claimed_until IS NULL OR claimed_until <= ?) ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED", now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var outboxEvent domain.OutboxEvent
+		var payload []byte
+		var lastError sql.NullString
+		var deliveredSinks string
+		if err := rows.Scan(&outboxEvent.Id, &payload, &outboxEvent.Attempts, &lastError, &deliveredSinks); err != nil {
+			return nil, err
+		}
+		var data json.RawMessage
+		outboxEvent.Event.Data = &data
+		if err := json.Unmarshal(payload, &outboxEvent.Event); err != nil {
+			return nil, err
+		}
+		outboxEvent.Event.Data = data
+		outboxEvent.LastError = lastError.String
+		if deliveredSinks != "" {
+			outboxEvent.DeliveredSinks = strings.Split(deliveredSinks, ",")
+		}
+		events = append(events, outboxEvent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	claimedUntil := now.Add(lease)
+	for _, outboxEvent := range events {
+		if _, err := tx.Exec("UPDATE outbox_events SET claimed_until = ? WHERE id = ?", claimedUntil, outboxEvent.Id); err != nil {
+			return nil, err
+		}
+	}
+	return events, tx.Commit()
+}
+
+// MarkEventPublished - registra que o evento foi entregue a todos os destinos
+func (so *outboxStore) MarkEventPublished(id int, at time.Time) error {
+	result, err := so.db.Exec("UPDATE outbox_events SET published_at = ?, attempts = attempts + 1, last_error = NULL, claimed_until = NULL WHERE id = ?", at, id)
+	return expectOneRow(result, err)
+}
+
+// MarkEventFailed - registra uma tentativa de publicação que falhou e os destinos que já aceitaram o evento. O
+// evento volta a ser reservado a partir de retryAt ou, quando abandoned, deixa de ser publicado.
+func (so *outboxStore) MarkEventFailed(id int, reason string, deliveredSinks []string, retryAt time.Time, abandoned bool) error {
+	if len(reason) > maxDeliveryError {
+		reason = reason[:maxDeliveryError]
+	}
+	var failedAt interface{}
+	if abandoned {
+		failedAt = time.Now()
+	}
+	result, err := so.db.Exec("UPDATE outbox_events SET attempts = attempts + 1, last_error = ?, delivered_sinks = ?, claimed_until = ?, failed_at = ? WHERE id = ?",
+		reason,
+		strings.Join(deliveredSinks, ","),
+		retryAt,
+		failedAt,
+		id)
+	return expectOneRow(result, err)
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// patientColumns lista as colunas lidas da tabela patients, incluindo os campos do perfil
+const patientColumns = "p.id, p.surname, p.name, p.document, DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i'), p.email, p.phones, DATE_FORMAT(p.birth_date,'%d/%m/%Y'), p.address, p.guardian, p.emergency_contact, p.preferred_language, p.consent"
+
+// rowScanner é implementado por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPatient lê uma linha selecionada com patientColumns
+func scanPatient(row rowScanner) (domain.Patient, error) {
+	var patient domain.Patient
+	var email, birthDate, language sql.NullString
+	var phones, address, guardian, emergencyContact, consent []byte
+
+	if err := row.Scan(
+		&patient.Id,
+		&patient.Surname,
+		&patient.Name,
+		&patient.Document,
+		&patient.CreatedAt,
+		&email,
+		&phones,
+		&birthDate,
+		&address,
+		&guardian,
+		&emergencyContact,
+		&language,
+		&consent); err != nil {
+		return patient, err
+	}
+	patient.Email = email.String
+	patient.BirthDate = birthDate.String
+	patient.PreferredLanguage = language.String
+
+	for _, field := range []struct {
+		raw    []byte
+		target interface{}
+	}{
+		{phones, &patient.Phones},
+		{address, &patient.Address},
+		{guardian, &patient.Guardian},
+		{emergencyContact, &patient.EmergencyContact},
+		{consent, &patient.Consent},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.target); err != nil {
+			return patient, err
+		}
+	}
+	return patient, nil
+}
+
+// patientProfileArgs retorna os valores das colunas do perfil na ordem de patientColumns
+func patientProfileArgs(patient domain.Patient) ([]interface{}, error) {
+	var birthDate interface{}
+	if patient.BirthDate != "" {
+		parsed, err := time.Parse("02/01/2006", patient.BirthDate)
+		if err != nil {
+			return nil, err
+		}
+		birthDate = parsed
+	}
+
+	var phones []string
+	if len(patient.Phones) > 0 {
+		phones = patient.Phones
+	}
+	encoded := make([]interface{}, 0, 5)
+	for _, value := range []interface{}{phones, patient.Address, patient.Guardian, patient.EmergencyContact, patient.Consent} {
+		column, err := jsonColumn(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, column)
+	}
+
+	return []interface{}{
+		nullString(patient.Email),
+		encoded[0],
+		birthDate,
+		encoded[1],
+		encoded[2],
+		encoded[3],
+		nullString(patient.PreferredLanguage),
+		encoded[4],
+	}, nil
+}
+
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// jsonColumn serializa o valor para uma coluna JSON, gravando NULL quando não informado
+func jsonColumn(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case []string:
+		if v == nil {
+			return nil, nil
+		}
+	case *domain.Address:
+		if v == nil {
+			return nil, nil
+		}
+	case *domain.Contact:
+		if v == nil {
+			return nil, nil
+		}
+	case *domain.CommunicationConsent:
+		if v == nil {
+			return nil, nil
+		}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
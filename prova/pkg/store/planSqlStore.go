@@ -0,0 +1,237 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// PlanStore - Define o contrato de persistência dos planos de tratamento.
+type PlanStore interface {
+	GetPlanByID(id int) (domain.TreatmentPlan, error)
+	GetPlansByPatient(patientID int) ([]domain.TreatmentPlan, error)
+	SavePlan(p domain.TreatmentPlan) (domain.TreatmentPlan, error)
+	UpdatePlan(p domain.TreatmentPlan) error
+	UpdatePlanStatus(id int, status string, acceptedAt time.Time) error
+	AdvancePlanStatus(id int, from, to string) error
+	UpdatePlanItemAppointment(planID, itemID, appointmentID int) error
+	DeletePlan(id int) error
+}
+
+// NewSQLPlan - Inicializa interface PlanStore
+func NewSQLPlan() PlanStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &planStore{db: database}
+}
+
+type planStore struct {
+	db *sql.DB
+}
+
+const planColumns = "t.id, t.id_patient, t.id_dentist, t.title, t.status, DATE_FORMAT(t.created_at,'%d/%m/%Y %H:%i'), DATE_FORMAT(t.accepted_at,'%d/%m/%Y %H:%i')"
+
+func scanPlan(row rowScanner) (domain.TreatmentPlan, error) {
+	var plan domain.TreatmentPlan
+	var acceptedAt sql.NullString
+	err := row.Scan(
+		&plan.Id,
+		&plan.IdPatient,
+		&plan.IdDentist,
+		&plan.Title,
+		&plan.Status,
+		&plan.CreatedAt,
+		&acceptedAt)
+	plan.AcceptedAt = acceptedAt.String
+	return plan, err
+}
+
+// planItems - retorna os procedimentos de um plano na ordem em que devem ser executados
+func (st *planStore) planItems(planID int) ([]domain.TreatmentPlanItem, error) {
+	rows, err := st.db.Query("SELECT i.id, i.position, i.procedure_code, pr.name, i.tooth, i.price, i.id_appointment FROM treatment_plan_items i INNER JOIN procedures pr on i.procedure_code = pr.code WHERE i.id_plan = ? ORDER BY i.position", planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.TreatmentPlanItem{}
+	for rows.Next() {
+		var item domain.TreatmentPlanItem
+		var tooth, appointmentID sql.NullInt64
+		if err := rows.Scan(
+			&item.Id,
+			&item.Position,
+			&item.ProcedureCode,
+			&item.ProcedureName,
+			&tooth,
+			&item.Price,
+			&appointmentID); err != nil {
+			return items, err
+		}
+		item.Tooth = int(tooth.Int64)
+		item.IdAppointment = int(appointmentID.Int64)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetPlanByID - retorna um plano de tratamento com seus procedimentos
+func (st *planStore) GetPlanByID(id int) (domain.TreatmentPlan, error) {
+	plan, err := scanPlan(st.db.QueryRow("SELECT "+planColumns+" FROM treatment_plans t WHERE t.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return plan, ErrNotFound
+	}
+	if err != nil {
+		return plan, err
+	}
+	plan.Items, err = st.planItems(plan.Id)
+	return plan, err
+}
+
+// GetPlansByPatient - retorna os planos de tratamento de um paciente, do mais recente ao mais antigo
+func (st *planStore) GetPlansByPatient(patientID int) ([]domain.TreatmentPlan, error) {
+	rows, err := st.db.Query("SELECT "+planColumns+" FROM treatment_plans t WHERE t.id_patient = ? ORDER BY t.created_at DESC", patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []domain.TreatmentPlan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return plans, err
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return plans, err
+	}
+	for i := range plans {
+		if plans[i].Items, err = st.planItems(plans[i].Id); err != nil {
+			return plans, err
+		}
+	}
+	return plans, nil
+}
+
+// SavePlan - insere o plano e seus procedimentos em uma única transação
+func (st *planStore) SavePlan(p domain.TreatmentPlan) (domain.TreatmentPlan, error) {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO treatment_plans(id_patient, id_dentist, title, status, created_at) VALUES (?,?,?,?,?)",
+		p.IdPatient,
+		p.IdDentist,
+		p.Title,
+		p.Status,
+		time.Now())
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if err := insertPlanItems(tx, int(lastInsertedID), p.Items); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.TreatmentPlan{}, err
+	}
+	return st.GetPlanByID(int(lastInsertedID))
+}
+
+// UpdatePlan - substitui o título, o dentista e os procedimentos de um plano ainda em rascunho
+func (st *planStore) UpdatePlan(p domain.TreatmentPlan) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE treatment_plans SET id_dentist = ?, title = ? WHERE id = ? AND status = ?",
+		p.IdDentist,
+		p.Title,
+		p.Id,
+		domain.PlanDraft)
+	if err := expectOneRow(result, err); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM treatment_plan_items WHERE id_plan = ?", p.Id); err != nil {
+		return err
+	}
+	if err := insertPlanItems(tx, p.Id, p.Items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertPlanItems(tx *sql.Tx, planID int, items []domain.TreatmentPlanItem) error {
+	for _, item := range items {
+		var tooth interface{}
+		if item.Tooth != 0 {
+			tooth = item.Tooth
+		}
+		if _, err := tx.Exec("INSERT INTO treatment_plan_items(id_plan, position, procedure_code, tooth, price) VALUES (?,?,?,?,?)",
+			planID,
+			item.Position,
+			item.ProcedureCode,
+			tooth,
+			item.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdatePlanStatus - altera a situação do plano, registrando a data de aceite quando informada
+func (st *planStore) UpdatePlanStatus(id int, status string, acceptedAt time.Time) error {
+	var accepted interface{}
+	if !acceptedAt.IsZero() {
+		accepted = acceptedAt
+	}
+	result, err := st.db.Exec("UPDATE treatment_plans SET status = ?, accepted_at = COALESCE(?, accepted_at) WHERE id = ?", status, accepted, id)
+	return expectOneRow(result, err)
+}
+
+// AdvancePlanStatus - altera a situação do plano somente se ela ainda for a informada em from;
+// retorna ErrNotFound quando o plano mudou de situação depois da leitura
+func (st *planStore) AdvancePlanStatus(id int, from, to string) error {
+	result, err := st.db.Exec("UPDATE treatment_plans SET status = ? WHERE id = ? AND status = ?", to, id, from)
+	return expectOneRow(result, err)
+}
+
+// UpdatePlanItemAppointment - vincula um procedimento do plano a uma consulta
+func (st *planStore) UpdatePlanItemAppointment(planID, itemID, appointmentID int) error {
+	result, err := st.db.Exec("UPDATE treatment_plan_items SET id_appointment = ? WHERE id = ? AND id_plan = ?", appointmentID, itemID, planID)
+	return expectOneRow(result, err)
+}
+
+// DeletePlan - exclui um plano ainda em rascunho e seus procedimentos
+func (st *planStore) DeletePlan(id int) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE i FROM treatment_plan_items i INNER JOIN treatment_plans t ON t.id = i.id_plan WHERE t.id = ? AND t.status = ?", id, domain.PlanDraft); err != nil {
+		return mapError(err)
+	}
+	result, err := tx.Exec("DELETE FROM treatment_plans WHERE id = ? AND status = ?", id, domain.PlanDraft)
+	if err != nil {
+		return mapError(err)
+	}
+	if err := expectOneRow(result, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
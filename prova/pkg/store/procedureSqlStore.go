@@ -0,0 +1,96 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// ProcedureStore - Define o contrato de persistência do catálogo de procedimentos.
+type ProcedureStore interface {
+	GetProcedures() ([]domain.Procedure, error)
+	GetProcedureByCode(code string) (domain.Procedure, error)
+	SaveProcedure(p domain.Procedure) (domain.Procedure, error)
+	UpdateProcedure(code string, p domain.Procedure) (domain.Procedure, error)
+	DeleteProcedure(code string) error
+}
+
+// NewSQLProcedure - Inicializa interface ProcedureStore
+func NewSQLProcedure() ProcedureStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &procedureStore{db: database}
+}
+
+type procedureStore struct {
+	db *sql.DB
+}
+
+// GetProcedures - retorna todos os procedimentos do catálogo ordenados pelo código
+func (sp *procedureStore) GetProcedures() ([]domain.Procedure, error) {
+	rows, err := sp.db.Query("SELECT code, name, duration, price FROM procedures ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var procedures []domain.Procedure
+	for rows.Next() {
+		var procedure domain.Procedure
+		if err := rows.Scan(&procedure.Code, &procedure.Name, &procedure.Duration, &procedure.Price); err != nil {
+			return procedures, err
+		}
+		procedures = append(procedures, procedure)
+	}
+	return procedures, rows.Err()
+}
+
+// GetProcedureByCode - retorna um procedimento do catálogo pelo código
+func (sp *procedureStore) GetProcedureByCode(code string) (domain.Procedure, error) {
+	var procedure domain.Procedure
+	err := sp.db.QueryRow("SELECT code, name, duration, price FROM procedures WHERE code = ?", code).Scan(
+		&procedure.Code,
+		&procedure.Name,
+		&procedure.Duration,
+		&procedure.Price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return procedure, ErrNotFound
+	}
+	return procedure, err
+}
+
+// SaveProcedure - insere um novo procedimento no catálogo
+func (sp *procedureStore) SaveProcedure(p domain.Procedure) (domain.Procedure, error) {
+	_, err := sp.db.Exec("INSERT INTO procedures(code, name, duration, price) VALUES (?,?,?,?)",
+		p.Code,
+		p.Name,
+		p.Duration,
+		p.Price)
+	if err != nil {
+		return domain.Procedure{}, mapError(err)
+	}
+	return p, nil
+}
+
+// UpdateProcedure - atualiza nome, duração e preço de um procedimento
+func (sp *procedureStore) UpdateProcedure(code string, p domain.Procedure) (domain.Procedure, error) {
+	result, err := sp.db.Exec("UPDATE procedures SET name = ?, duration = ?, price = ? WHERE code = ?",
+		p.Name,
+		p.Duration,
+		p.Price,
+		code)
+	if err := expectOneRow(result, err); err != nil {
+		return domain.Procedure{}, err
+	}
+	p.Code = code
+	return p, nil
+}
+
+// DeleteProcedure - exclui um procedimento do catálogo
+func (sp *procedureStore) DeleteProcedure(code string) error {
+	result, err := sp.db.Exec("DELETE FROM procedures WHERE code = ?", code)
+	return expectOneRow(result, err)
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ReminderStore - Define o contrato de persistência dos lembretes enviados.
+type ReminderStore interface {
+	ClaimReminder(appointmentID int, kind, channel string, staleBefore time.Time) error
+	CompleteReminder(appointmentID int, kind, channel, status string) error
+	ReleaseReminder(appointmentID int, kind, channel string) error
+}
+
+// NewSQLReminder - Inicializa interface ReminderStore
+func NewSQLReminder() ReminderStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &reminderStore{db: database}
+}
+
+type reminderStore struct {
+	db *sql.DB
+}
+
+// ClaimReminder - reserva o envio de um lembrete. A chave primária garante que cada lembrete
+// seja reservado uma única vez, retornando ErrDuplicate quando ele já foi enviado ou está em envio.
+// Uma reserva em envio criada antes de staleBefore é de uma instância que parou no meio do envio e
+// é descartada antes da nova reserva.
+func (sr *reminderStore) ClaimReminder(appointmentID int, kind, channel string, staleBefore time.Time) error {
+	if _, err := sr.db.Exec("DELETE FROM appointment_reminders WHERE id_appointment = ? AND kind = ? AND channel = ? AND status = ? AND created_at < ?",
+		appointmentID,
+		kind,
+		channel,
+		"sending",
+		staleBefore); err != nil {
+		return err
+	}
+	_, err := sr.db.Exec("INSERT INTO appointment_reminders(id_appointment, kind, channel, status, created_at) VALUES (?,?,?,?,?)",
+		appointmentID,
+		kind,
+		channel,
+		"sending",
+		time.Now())
+	return mapError(err)
+}
+
+// CompleteReminder - registra o resultado do envio de um lembrete reservado
+func (sr *reminderStore) CompleteReminder(appointmentID int, kind, channel, status string) error {
+	result, err := sr.db.Exec("UPDATE appointment_reminders SET status = ?, sent_at = ? WHERE id_appointment = ? AND kind = ? AND channel = ?",
+		status,
+		time.Now(),
+		appointmentID,
+		kind,
+		channel)
+	return expectOneRow(result, err)
+}
+
+// ReleaseReminder - libera a reserva de um lembrete que falhou para que seja tentado novamente
+func (sr *reminderStore) ReleaseReminder(appointmentID int, kind, channel string) error {
+	_, err := sr.db.Exec("DELETE FROM appointment_reminders WHERE id_appointment = ? AND kind = ? AND channel = ? AND status = ?",
+		appointmentID,
+		kind,
+		channel,
+		"sending")
+	return err
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// ReportStore - Define as agregações usadas pelos relatórios gerenciais. Os intervalos incluem
+// From e excluem To.
+type ReportStore interface {
+	AppointmentBuckets(filter domain.ReportFilter, defaultMinutes int) ([]domain.ReportBucket, error)
+	Blocks(filter domain.ReportFilter) ([]domain.ScheduleBlock, error)
+	PatientBuckets(filter domain.ReportFilter) ([]domain.ReportBucket, error)
+}
+
+// NewSQLReport - Inicializa interface ReportStore
+func NewSQLReport() ReportStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &reportStore{db: database}
+}
+
+type reportStore struct {
+	db *sql.DB
+}
+
+// AppointmentBuckets - conta as consultas e soma suas durações por dentista, período e situação.
+// Consultas sem duração contam com defaultMinutes.
+func (sr *reportStore) AppointmentBuckets(filter domain.ReportFilter, defaultMinutes int) ([]domain.ReportBucket, error) {
+	bucket, err := periodExpression("a.appointment_date", filter.Period)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sr.db.Query("SELECT a.id_dentist, "+bucket+", a.status, COUNT(*), COALESCE(SUM(CASE WHEN a.duration > 0 THEN a.duration ELSE ? END), 0)"+
+		" FROM appointments a WHERE a.appointment_date >= ? AND a.appointment_date < ? GROUP BY 1, 2, 3 ORDER BY 1, 2, 3",
+		defaultMinutes, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []domain.ReportBucket
+	for rows.Next() {
+		var b domain.ReportBucket
+		if err := rows.Scan(&b.Registration, &b.Period, &b.Status, &b.Count, &b.Minutes); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// Blocks - retorna os bloqueios de agenda que se sobrepõem ao intervalo, recortados a ele e
+// ordenados por dentista e início
+func (sr *reportStore) Blocks(filter domain.ReportFilter) ([]domain.ScheduleBlock, error) {
+	rows, err := sr.db.Query("SELECT id, id_dentist, DATE_FORMAT(GREATEST(start_at, ?),'%d/%m/%Y %H:%i'), DATE_FORMAT(LEAST(end_at, ?),'%d/%m/%Y %H:%i')"+
+		" FROM schedule_blocks WHERE start_at < ? AND end_at > ? ORDER BY id_dentist, start_at",
+		filter.From, filter.To, filter.To, filter.From)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []domain.ScheduleBlock
+	for rows.Next() {
+		var b domain.ScheduleBlock
+		if err := rows.Scan(&b.Id, &b.IdDentist, &b.Start, &b.End); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// PatientBuckets - conta os pacientes pela data de cadastro em cada período
+func (sr *reportStore) PatientBuckets(filter domain.ReportFilter) ([]domain.ReportBucket, error) {
+	bucket, err := periodExpression("p.created_at", filter.Period)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sr.db.Query("SELECT "+bucket+", COUNT(*) FROM patients p WHERE p.created_at >= ? AND p.created_at < ? GROUP BY 1 ORDER BY 1",
+		filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []domain.ReportBucket
+	for rows.Next() {
+		var b domain.ReportBucket
+		if err := rows.Scan(&b.Period, &b.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// periodExpression retorna a expressão SQL da data de início do período da coluna: o próprio dia,
+// a segunda-feira da semana ou o primeiro dia do mês. Sem agrupamento, todas as linhas caem no
+// mesmo período vazio.
+func periodExpression(column, period string) (string, error) {
+	switch period {
+	case "":
+		return "''", nil
+	case domain.PeriodDay:
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')", nil
+	case domain.PeriodWeek:
+		return "DATE_FORMAT(DATE_SUB(DATE(" + column + "), INTERVAL WEEKDAY(" + column + ") DAY), '%Y-%m-%d')", nil
+	case domain.PeriodMonth:
+		return "DATE_FORMAT(" + column + ", '%Y-%m-01')", nil
+	default:
+		return "", errors.New("invalid report period")
+	}
+}
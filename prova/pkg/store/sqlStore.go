@@ -1,14 +1,17 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/meirafa/prova2-golang/internal/domain"
+	"github.com/meirafa/prova2-golang/internal/event"
 )
 
 var (
@@ -17,9 +20,13 @@ var (
 	PE = "patients"
 )
 
+// DataSourceName é a string de conexão com o banco. clientFoundRows faz o MySQL contar as
+// linhas encontradas em um UPDATE, mesmo quando os valores não mudam.
+var DataSourceName = "user:password@/my_db?clientFoundRows=true"
+
 // NewSQLStore estabelece conexão com a interface
 func NewSQLStore() Store {
-	database, err := sql.Open("mysql", "user:password@/my_db")
+	database, err := sql.Open("mysql", DataSourceName)
 	if err != nil {
 		panic(err)
 	}
@@ -92,47 +99,182 @@ func (s *sqlStore) Update(entityID int, entity interface{}, tableName string) (i
 func (s *sqlStore) Delete(entityID int, tableName string) error {
 	switch tableName {
 	case AP:
-		return auxDelete(tableName, s, entityID)
+		return auxDelete(tableName, s, entityID, 0)
 	case DE:
-		return auxDelete(tableName, s, entityID)
+		return auxDelete(tableName, s, entityID, 0)
 	case PE:
-		return auxDelete(tableName, s, entityID)
+		return auxDelete(tableName, s, entityID, 0)
 	default:
 		return errors.New("failed to delete")
 	}
 }
 
+// Batch grava as operações na tabela selecionada numa única transação. Se alguma falhar, nenhuma é
+// gravada e o erro é um *BatchError com a posição da operação que falhou.
+func (s *sqlStore) Batch(tableName string, ops []BatchOp) ([]interface{}, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	results := make([]interface{}, 0, len(ops))
+	for i, op := range ops {
+		var result interface{}
+		if op.Id == 0 {
+			result, err = insertTx(tx, tableName, op.Entity)
+		} else {
+			result, err = updateTx(tx, tableName, op.Entity, op.Id)
+		}
+		if err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+		results = append(results, result)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetByIDs retorna os registros da tabela selecionada com os ids informados, num único SELECT
+func (s *sqlStore) GetByIDs(ctx context.Context, ids []int, tableName string) (interface{}, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	in := " IN (" + placeholders(len(ids)) + ")"
+	switch tableName {
+	case AP:
+		return s.selectAppointments(ctx, "SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id"+in, ints(ids)...)
+	case DE:
+		return s.selectDentists(ctx, "SELECT "+dentistColumns+" FROM dentists d WHERE d.id"+in, ints(ids)...)
+	case PE:
+		return s.selectPatients(ctx, "SELECT "+patientColumns+" FROM patients p WHERE p.id"+in, ints(ids)...)
+	default:
+		return nil, errors.New("failed to get by ids from db")
+	}
+}
+
+// GetByKeys retorna os dentistas pelo CRO ou os pacientes pelo documento, num único SELECT
+func (s *sqlStore) GetByKeys(ctx context.Context, keys []string, tableName string) (interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	in := " IN (" + placeholders(len(keys)) + ")"
+	switch tableName {
+	case DE:
+		return s.selectDentists(ctx, "SELECT "+dentistColumns+" FROM dentists d WHERE d.registration"+in, strs(keys)...)
+	case PE:
+		return s.selectPatients(ctx, "SELECT "+patientColumns+" FROM patients p WHERE p.document"+in, strs(keys)...)
+	default:
+		return nil, errors.New("failed to get by keys from db")
+	}
+}
+
+// GetPage retorna uma página dos registros da tabela selecionada, ordenados por id
+func (s *sqlStore) GetPage(ctx context.Context, page domain.Page, tableName string) (interface{}, error) {
+	switch tableName {
+	case AP:
+		return s.selectAppointments(ctx, "SELECT "+appointmentColumns+appointmentJoins+" ORDER BY a.id LIMIT ? OFFSET ?", page.Limit, page.Offset)
+	case DE:
+		return s.selectDentists(ctx, "SELECT "+dentistColumns+" FROM dentists d ORDER BY d.id LIMIT ? OFFSET ?", page.Limit, page.Offset)
+	case PE:
+		return s.selectPatients(ctx, "SELECT "+patientColumns+" FROM patients p ORDER BY p.id LIMIT ? OFFSET ?", page.Limit, page.Offset)
+	default:
+		return nil, errors.New("failed to get page from db")
+	}
+}
+
+const dentistColumns = "d.id, d.surname, d.name, d.registration"
+
+func (s *sqlStore) selectDentists(ctx context.Context, query string, args ...interface{}) ([]domain.Dentist, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var dentists []domain.Dentist
+	for rows.Next() {
+		var dentist domain.Dentist
+		if err := rows.Scan(&dentist.Id, &dentist.Surname, &dentist.Name, &dentist.Registration); err != nil {
+			return nil, err
+		}
+		dentists = append(dentists, dentist)
+	}
+	return dentists, rows.Err()
+}
+
+func (s *sqlStore) selectPatients(ctx context.Context, query string, args ...interface{}) ([]domain.Patient, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var patients []domain.Patient
+	for rows.Next() {
+		patient, err := scanPatient(rows)
+		if err != nil {
+			return nil, err
+		}
+		patients = append(patients, patient)
+	}
+	return patients, rows.Err()
+}
+
+func (s *sqlStore) selectAppointments(ctx context.Context, query string, args ...interface{}) ([]domain.AppointmentDTO, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var appointments []domain.AppointmentDTO
+	for rows.Next() {
+		appointment, err := scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, appointment)
+	}
+	return appointments, rows.Err()
+}
+
+// placeholders monta a lista de parâmetros de uma cláusula IN
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func ints(values []int) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func strs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
 // auxGetAllByTable - Função chamada por GetAll, aqui a tabela selecionada é validada e todas as consultas de seleção são feitas.
 func auxGetAllByTable(tableName string, s *sqlStore) (interface{}, error) {
 	var entities []struct{}
 
 	switch tableName {
 	case AP:
-		Query := "SELECT a.id, a.description, DATE_FORMAT(a.appointment_date,'%d/%m/%Y %H:%i') appointment_date,a.id_dentist,a.id_patient,d.id,d.surname,d.name,d.registration,p.id,p.surname,p.name,p.document,DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') created_at FROM appointments a INNER JOIN dentists d on a.id_dentist = d.registration INNER JOIN patients p on a.id_patient = p.document ORDER BY a.appointment_date"
-		rows, err := s.db.Query(Query)
+		rows, err := s.db.Query("SELECT " + appointmentColumns + appointmentJoins + " ORDER BY a.appointment_date")
 		if err != nil {
 			return entities, err
 		}
+		defer rows.Close()
 
-		var appointment domain.AppointmentDTO
 		var appointments []domain.AppointmentDTO
-
 		for rows.Next() {
-			if err := rows.Scan(
-				&appointment.Id,
-				&appointment.Description,
-				&appointment.AppointmentDate,
-				&appointment.IdDentist,
-				&appointment.IdPatient,
-				&appointment.Dentist.Id,
-				&appointment.Dentist.Surname,
-				&appointment.Dentist.Name,
-				&appointment.Dentist.Registration,
-				&appointment.Patient.Id,
-				&appointment.Patient.Surname,
-				&appointment.Patient.Name,
-				&appointment.Patient.Document,
-				&appointment.Patient.CreatedAt); err != nil {
+			appointment, err := scanAppointment(rows)
+			if err != nil {
 				return appointments, err
 			}
 			appointments = append(appointments, appointment)
@@ -159,20 +301,16 @@ func auxGetAllByTable(tableName string, s *sqlStore) (interface{}, error) {
 		}
 		return dentists, nil
 	case PE:
-		rows, err := s.db.Query("SELECT p.id, p.surname,p.name,p.document, DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') FROM patients p")
+		rows, err := s.db.Query("SELECT " + patientColumns + " FROM patients p")
 		if err != nil {
 			return entities, err
 		}
+		defer rows.Close()
 
-		var patient domain.Patient
 		var patients []domain.Patient
 		for rows.Next() {
-			if err := rows.Scan(
-				&patient.Id,
-				&patient.Surname,
-				&patient.Name,
-				&patient.Document,
-				&patient.CreatedAt); err != nil {
+			patient, err := scanPatient(rows)
+			if err != nil {
 				return patients, err
 			}
 			patients = append(patients, patient)
@@ -189,37 +327,19 @@ func auxGetByIDByTable(tableName string, entityID int, s *sqlStore) (interface{}
 
 	switch tableName {
 	case AP:
-		query := "SELECT a.id, a.description, DATE_FORMAT(a.appointment_date,'%d/%m/%Y %H:%i') appointment_date,a.id_dentist,a.id_patient,d.id,d.surname,d.name,d.registration,p.id,p.surname,p.name,p.document,DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') created_at FROM appointments a INNER JOIN dentists d on a.id_dentist = d.registration INNER JOIN patients p on a.id_patient = p.document WHERE a.id = ? ORDER BY a.appointment_date"
-		rows, err := s.db.Query(query, entityID)
+		rows, err := s.db.Query("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id = ?", entityID)
 		if err != nil {
 			return entity, err
 		}
 		defer rows.Close()
 
-		var appointment domain.AppointmentDTO
 		for rows.Next() {
-			if err := rows.Scan(
-				&appointment.Id,
-				&appointment.Description,
-				&appointment.AppointmentDate,
-				&appointment.IdDentist,
-				&appointment.IdPatient,
-				&appointment.Dentist.Id,
-				&appointment.Dentist.Surname,
-				&appointment.Dentist.Name,
-				&appointment.Dentist.Registration,
-				&appointment.Patient.Id,
-				&appointment.Patient.Surname,
-				&appointment.Patient.Name,
-				&appointment.Patient.Document,
-				&appointment.Patient.CreatedAt); err != nil {
+			appointment, err := scanAppointment(rows)
+			if err != nil {
 				return appointment, err
 			}
 			return appointment, nil
 		}
-		if rows.Next() {
-			return appointment, nil
-		}
 		return nil, err
 	case DE:
 		rows, err := s.db.Query("SELECT * FROM dentists WHERE id = ?", entityID)
@@ -244,35 +364,46 @@ func auxGetByIDByTable(tableName string, entityID int, s *sqlStore) (interface{}
 		}
 		return nil, err
 	case PE:
-		rows, err := s.db.Query("SELECT p.id, p.surname,p.name,p.document, DATE_FORMAT(p.created_at,'%d/%m/%Y %H:%i') FROM patients p WHERE id = ?", entityID)
+		rows, err := s.db.Query("SELECT "+patientColumns+" FROM patients p WHERE id = ?", entityID)
 		if err != nil {
 			return entity, err
 		}
 		defer rows.Close()
 
-		var patient domain.Patient
 		for rows.Next() {
-			if err = rows.Scan(
-				&patient.Id,
-				&patient.Surname,
-				&patient.Name,
-				&patient.Document,
-				&patient.CreatedAt); err != nil {
+			patient, err := scanPatient(rows)
+			if err != nil {
 				return nil, err
 			}
 			return patient, nil
 		}
-		if rows.Next() {
-			return patient, nil
-		}
 		return nil, err
 	default:
 		return nil, errors.New("failed to get by id from db")
 	}
 }
 
-// auxSave - Função chamada por Save, aqui as inserções são feitas na tabela selecionada.
+// auxSave - Função chamada por Save, aqui as inserções são feitas na tabela selecionada. Cada inserção
+// grava o evento correspondente no outbox na mesma transação.
 func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	saved, err := insertTx(tx, tableName, entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// insertTx faz a inserção e grava o evento no outbox dentro da transação informada
+func insertTx(tx *sql.Tx, tableName string, entity interface{}) (interface{}, error) {
 	switch tableName {
 	case AP:
 		log.Println("... inserting data into appointments table.")
@@ -286,11 +417,19 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 			}
 			//
 			log.Println(apAppointmentDateParsed.String())
-			result, err := s.db.Exec("INSERT INTO appointments(DESCRIPTION, appointment_date, id_dentist, id_patient) VALUES(?,?,?,?)",
+			if domain.OccupiesSchedule(appointment.Status) {
+				if err := checkScheduleTx(tx, appointment.IdDentist, apAppointmentDateParsed, appointment.Duration, 0); err != nil {
+					return nil, err
+				}
+			}
+			result, err := tx.Exec("INSERT INTO appointments(DESCRIPTION, appointment_date, id_dentist, id_patient, status, procedure_code, duration) VALUES(?,?,?,?,?,?,?)",
 				appointment.Description,
 				apAppointmentDateParsed,
 				appointment.IdDentist,
-				appointment.IdPatient)
+				appointment.IdPatient,
+				appointment.Status,
+				appointment.ProcedureCode,
+				appointment.Duration)
 			if err != nil {
 				fmt.Println("inserting data failed :", err.Error())
 				return nil, err
@@ -300,21 +439,27 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 				fmt.Println("error trying to get id inserted:", err.Error())
 				return nil, err
 			}
-			appointment.Id = int(lastInsertedID)
+			saved, err := scanAppointment(tx.QueryRow("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id = ?", lastInsertedID))
+			if err != nil {
+				return nil, err
+			}
+			if err := insertEvent(tx, event.AppointmentCreated, AP, saved.Id, saved); err != nil {
+				return nil, err
+			}
 			log.Println("... INSERT operation was successfully")
-			return s.GetByID(appointment.Id, AP)
+			return saved, nil
 		}
 	case DE:
 		var dentist domain.Dentist
 		dentist, ok := entity.(domain.Dentist)
 		if ok {
-			result, err := s.db.Exec("INSERT INTO dentists(surname, name, registration) VALUES (?,?,?)",
+			result, err := tx.Exec("INSERT INTO dentists(surname, name, registration) VALUES (?,?,?)",
 				dentist.Surname,
 				dentist.Name,
 				dentist.Registration)
 			if err != nil {
 				fmt.Println("inserting data failed :", err.Error())
-				return nil, err
+				return nil, mapError(err)
 			}
 			lastInsertedID, err := result.LastInsertId()
 			if err != nil {
@@ -322,6 +467,9 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 				return nil, err
 			}
 			dentist.Id = int(lastInsertedID)
+			if err := insertEvent(tx, event.DentistCreated, DE, dentist.Id, dentist); err != nil {
+				return nil, err
+			}
 			fmt.Println("dentist inserted at db:", dentist)
 			return dentist, nil
 		}
@@ -333,11 +481,12 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 			if err != nil {
 				return nil, errors.New("failed to convert patient created_at field")
 			}
-			result, err := s.db.Exec("INSERT INTO patients(surname, name, document, created_at) VALUES (?,?,?,?)",
-				patient.Surname,
-				patient.Name,
-				patient.Document,
-				patCreatedAtParsed)
+			profile, err := patientProfileArgs(patient)
+			if err != nil {
+				return nil, errors.New("failed to convert patient profile fields")
+			}
+			args := append([]interface{}{patient.Surname, patient.Name, patient.Document, patCreatedAtParsed}, profile...)
+			result, err := tx.Exec("INSERT INTO patients(surname, name, document, created_at, email, phones, birth_date, address, guardian, emergency_contact, preferred_language, consent) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)", args...)
 			if err != nil {
 				fmt.Println("inserting data failed :", err.Error())
 				return nil, err
@@ -348,6 +497,9 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 				return nil, err
 			}
 			patient.Id = int(lastInsertedID)
+			if err := insertEvent(tx, event.PatientCreated, PE, patient.Id, patient); err != nil {
+				return nil, err
+			}
 			return patient, nil
 		}
 	default:
@@ -356,8 +508,27 @@ func auxSave(tableName string, s *sqlStore, entity interface{}) (interface{}, er
 	return nil, errors.New("failed to insert data at database")
 }
 
-// auxUpdate - Função chamada por Update, aqui as atualizações são feitas na tabela selecionada.
+// auxUpdate - Função chamada por Update, aqui as atualizações são feitas na tabela selecionada. Cada
+// atualização grava o evento correspondente no outbox na mesma transação.
 func auxUpdate(tableName string, s *sqlStore, entity interface{}, entityId int) (interface{}, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated, err := updateTx(tx, tableName, entity, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// updateTx faz a atualização e grava o evento no outbox dentro da transação informada
+func updateTx(tx *sql.Tx, tableName string, entity interface{}, entityId int) (interface{}, error) {
 	switch tableName {
 	case AP:
 		var appointment domain.Appointment
@@ -368,29 +539,85 @@ func auxUpdate(tableName string, s *sqlStore, entity interface{}, entityId int)
 				log.Println(err.Error(), "\nDate parsed: ", apAppointmentDateParsed)
 				return nil, errors.New("failed to convert datetime")
 			}
-			_, err = s.db.Exec("UPDATE appointments SET description = ?, appointment_date = ?, id_dentist = ?, id_patient = ? WHERE id = ?",
+			var previousStatus, previousDentist, previousDate string
+			var previousDuration int
+			if err := tx.QueryRow("SELECT status, id_dentist, DATE_FORMAT(appointment_date,'%d/%m/%Y %H:%i'), duration FROM appointments WHERE id = ? FOR UPDATE", entityId).Scan(&previousStatus, &previousDentist, &previousDate, &previousDuration); err != nil {
+				return nil, err
+			}
+			// o horário só é conferido quando a consulta passa a ocupar a agenda ou muda de lugar nela
+			moved := appointment.AppointmentDate != previousDate || appointment.IdDentist != previousDentist || appointment.Duration != previousDuration
+			if domain.OccupiesSchedule(appointment.Status) && (moved || !domain.OccupiesSchedule(previousStatus)) {
+				if err := checkScheduleTx(tx, appointment.IdDentist, apAppointmentDateParsed, appointment.Duration, entityId); err != nil {
+					return nil, err
+				}
+			}
+			// a versão é conferida no próprio UPDATE; zero atualiza sem conferir
+			result, err := tx.Exec("UPDATE appointments SET description = ?, appointment_date = ?, id_dentist = ?, id_patient = ?, status = ?, procedure_code = ?, duration = ?, version = version + 1 WHERE id = ? AND (? = 0 OR version = ?)",
 				appointment.Description,
 				apAppointmentDateParsed,
 				appointment.IdDentist,
 				appointment.IdPatient,
-				entityId)
+				appointment.Status,
+				appointment.ProcedureCode,
+				appointment.Duration,
+				entityId,
+				appointment.Version,
+				appointment.Version)
+			if err != nil {
+				return nil, err
+			}
+			count, err := result.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			// a linha existe, já que foi travada acima, então nenhuma alteração indica outra versão
+			if count == 0 {
+				return nil, ErrVersionMismatch
+			}
+			// os lembretes já enviados eram para o horário antigo; a nova data recebe os seus próprios
+			if appointment.AppointmentDate != previousDate {
+				if _, err := tx.Exec("DELETE FROM appointment_reminders WHERE id_appointment = ?", entityId); err != nil {
+					return nil, err
+				}
+			}
+			updated, err := scanAppointment(tx.QueryRow("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id = ?", entityId))
 			if err != nil {
 				return nil, err
 			}
-			return s.GetByID(entityId, AP)
+			eventType := event.AppointmentUpdated
+			if updated.Status == domain.StatusCancelled && previousStatus != domain.StatusCancelled {
+				eventType = event.AppointmentCancelled
+			}
+			if err := insertEvent(tx, eventType, AP, entityId, updated); err != nil {
+				return nil, err
+			}
+			return updated, nil
 		}
 	case DE:
 		var dentist domain.Dentist
 		dentist, ok := entity.(domain.Dentist)
 		if ok {
-			_, err := s.db.Exec("UPDATE dentists SET surname = ?, name = ?, registration = ? WHERE id = ?",
+			var previousRegistration string
+			if err := tx.QueryRow("SELECT registration FROM dentists WHERE id = ? FOR UPDATE", entityId).Scan(&previousRegistration); err != nil {
+				return nil, err
+			}
+			_, err := tx.Exec("UPDATE dentists SET surname = ?, name = ?, registration = ? WHERE id = ?",
 				dentist.Surname,
 				dentist.Name,
 				dentist.Registration,
 				entityId)
 			if err != nil {
+				return nil, mapError(err)
+			}
+			dentist.Id = entityId
+			if err := insertEvent(tx, event.DentistUpdated, DE, entityId, dentist); err != nil {
 				return nil, err
 			}
+			if dentist.Registration != previousRegistration {
+				if err := moveRegistration(tx, previousRegistration, dentist.Registration); err != nil {
+					return nil, err
+				}
+			}
 			return dentist, nil
 		}
 	case PE:
@@ -402,13 +629,18 @@ func auxUpdate(tableName string, s *sqlStore, entity interface{}, entityId int)
 				return nil, errors.New("failed to convert patient created_at field: " + patient.CreatedAt)
 			}
 
-			_, err = s.db.Exec("UPDATE patients SET surname = ?, name = ?, document = ?, created_at = ? WHERE id = ?",
-				patient.Surname,
-				patient.Name,
-				patient.Document,
-				paCreatedAtParsed,
-				entityId)
+			profile, err := patientProfileArgs(patient)
 			if err != nil {
+				return nil, errors.New("failed to convert patient profile fields")
+			}
+			args := append([]interface{}{patient.Surname, patient.Name, patient.Document, paCreatedAtParsed}, profile...)
+			_, err = tx.Exec("UPDATE patients SET surname = ?, name = ?, document = ?, created_at = ?, email = ?, phones = ?, birth_date = ?, address = ?, guardian = ?, emergency_contact = ?, preferred_language = ?, consent = ? WHERE id = ?",
+				append(args, entityId)...)
+			if err != nil {
+				return nil, err
+			}
+			patient.Id = entityId
+			if err := insertEvent(tx, event.PatientUpdated, PE, entityId, patient); err != nil {
 				return nil, err
 			}
 			return patient, nil
@@ -419,40 +651,127 @@ func auxUpdate(tableName string, s *sqlStore, entity interface{}, entityId int)
 	return nil, errors.New("failed to update data into database")
 }
 
-// auxDelete - Função chamada por Delete, aqui as deleções são feitas na tabela selecionada.
-func auxDelete(tableName string, s *sqlStore, entityID int) error {
-	switch tableName {
-	case AP:
-		result, err := s.db.Exec("DELETE FROM appointments WHERE id =?", entityID)
-		if err != nil {
+// checkScheduleTx confere, dentro da transação que grava a consulta, se o intervalo está livre na agenda do
+// dentista, ignorando a própria consulta. A linha do dentista é travada antes da leitura para que duas gravações
+// simultâneas na mesma agenda sejam feitas uma após a outra.
+func checkScheduleTx(tx *sql.Tx, registration string, start time.Time, duration, ignoreID int) error {
+	var dentistID int
+	err := tx.QueryRow("SELECT id FROM dentists WHERE registration = ? FOR UPDATE", registration).Scan(&dentistID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	end := start.Add(time.Duration(duration) * time.Minute)
+
+	var overlapping int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM appointments WHERE id_dentist = ? AND id <> ? AND status NOT IN (?, ?) AND appointment_date < ? AND appointment_date + INTERVAL duration MINUTE > ?",
+		registration,
+		ignoreID,
+		domain.StatusCancelled,
+		domain.StatusNoShow,
+		end,
+		start).Scan(&overlapping); err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrOverlap
+	}
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schedule_blocks WHERE id_dentist = ? AND start_at < ? AND end_at > ?", registration, end, start).Scan(&overlapping); err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrOverlap
+	}
+	return nil
+}
+
+// moveRegistration leva as consultas e os horários bloqueados do dentista para o novo número do
+// CRO, que é a referência usada por essas tabelas. Cada consulta alterada ganha uma nova versão e
+// o seu evento no outbox, como numa atualização feita pela API.
+func moveRegistration(tx *sql.Tx, from, to string) error {
+	rows, err := tx.Query("SELECT id FROM appointments WHERE id_dentist = ? FOR UPDATE", from)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
 			return err
 		}
-		count, err := result.RowsAffected()
-		if count == 0 {
-			return errors.New("entity not found at database")
-		}
-		return nil
-	case DE:
-		result, err := s.db.Exec("DELETE FROM dentists WHERE id =?", entityID)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE appointments SET id_dentist = ?, version = version + 1 WHERE id_dentist = ?", to, from); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE schedule_blocks SET id_dentist = ? WHERE id_dentist = ?", to, from); err != nil {
+		return mapError(err)
+	}
+	for _, id := range ids {
+		updated, err := scanAppointment(tx.QueryRow("SELECT "+appointmentColumns+appointmentJoins+" WHERE a.id = ?", id))
 		if err != nil {
 			return err
 		}
-		count, err := result.RowsAffected()
-		if count == 0 {
-			return errors.New("entity not found at database")
-		}
-		return nil
-	case PE:
-		result, err := s.db.Exec("DELETE FROM patients WHERE id =?", entityID)
-		if err != nil {
+		if err := insertEvent(tx, event.AppointmentUpdated, AP, id, updated); err != nil {
 			return err
 		}
-		count, err := result.RowsAffected()
-		if count == 0 {
-			return errors.New("entity not found at database")
-		}
-		return nil
+	}
+	return nil
+}
+
+// auxDelete - Função chamada por Delete, aqui as deleções são feitas na tabela selecionada. Cada
+// deleção grava o evento correspondente no outbox na mesma transação. Com version diferente de
+// zero, a linha só é excluída se ainda estiver nessa versão.
+func auxDelete(tableName string, s *sqlStore, entityID, version int) error {
+	var eventType string
+	switch tableName {
+	case AP:
+		eventType = event.AppointmentDeleted
+	case DE:
+		eventType = event.DentistDeleted
+	case PE:
+		eventType = event.PatientDeleted
 	default:
 		return errors.New("failed to delete row")
 	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query, args := "DELETE FROM "+tableName+" WHERE id =?", []interface{}{entityID}
+	if version != 0 {
+		query, args = query+" AND version = ?", append(args, version)
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if version != 0 {
+			var exists bool
+			if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM "+tableName+" WHERE id = ?)", entityID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrVersionMismatch
+			}
+		}
+		return errors.New("entity not found at database")
+	}
+	if err := insertEvent(tx, eventType, tableName, entityID, event.Deleted{Id: entityID}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -1,9 +1,43 @@
 package store
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
 type Store interface {
 	GetAll(tableName string) (interface{}, error)
 	GetByID(entityID int, tableName string) (interface{}, error)
 	Save(entity interface{}, tableName string) (interface{}, error)
 	Update(entityID int, entity interface{}, tableName string) (interface{}, error)
 	Delete(entityID int, tableName string) error
+	Batch(tableName string, ops []BatchOp) ([]interface{}, error)
+	// GetByIDs retorna os registros da tabela selecionada com os ids informados, num único SELECT
+	GetByIDs(ctx context.Context, ids []int, tableName string) (interface{}, error)
+	// GetByKeys é como GetByIDs, pela chave natural: o CRO dos dentistas e o documento dos pacientes
+	GetByKeys(ctx context.Context, keys []string, tableName string) (interface{}, error)
+	// GetPage retorna uma página dos registros da tabela selecionada, ordenados por id
+	GetPage(ctx context.Context, page domain.Page, tableName string) (interface{}, error)
+}
+
+// BatchOp é uma operação de um lote: inserção quando Id é zero, atualização do registro Id caso contrário
+type BatchOp struct {
+	Id     int
+	Entity interface{}
+}
+
+// BatchError indica a operação que fez o lote inteiro ser desfeito
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
 }
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// UserStore - Define o contrato de persistência dos usuários da API.
+type UserStore interface {
+	GetUserByUsername(username string) (domain.User, error)
+	SaveUser(u domain.User) (domain.User, error)
+	UpdateUserPassword(username, passwordHash string) error
+}
+
+// NewSQLUser - Inicializa interface UserStore
+func NewSQLUser() UserStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &userStore{db: database}
+}
+
+type userStore struct {
+	db *sql.DB
+}
+
+// GetUserByUsername - retorna o usuário com o login informado
+func (su *userStore) GetUserByUsername(username string) (domain.User, error) {
+	var user domain.User
+	var dentistID sql.NullInt64
+	err := su.db.QueryRow("SELECT id, username, password_hash, role, id_dentist FROM users WHERE username = ?", username).Scan(
+		&user.Id,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&dentistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, ErrNotFound
+	}
+	user.IdDentist = int(dentistID.Int64)
+	return user, err
+}
+
+// SaveUser - insere um novo usuário
+func (su *userStore) SaveUser(u domain.User) (domain.User, error) {
+	var dentistID interface{}
+	if u.IdDentist != 0 {
+		dentistID = u.IdDentist
+	}
+	result, err := su.db.Exec("INSERT INTO users(username, password_hash, role, id_dentist) VALUES (?,?,?,?)",
+		u.Username,
+		u.PasswordHash,
+		u.Role,
+		dentistID)
+	if err != nil {
+		return domain.User{}, mapError(err)
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.Id = int(lastInsertedID)
+	return u, nil
+}
+
+// UpdateUserPassword - substitui o hash da senha do usuário
+func (su *userStore) UpdateUserPassword(username, passwordHash string) error {
+	result, err := su.db.Exec("UPDATE users SET password_hash = ? WHERE username = ?", passwordHash, username)
+	return expectOneRow(result, err)
+}
@@ -0,0 +1,261 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/meirafa/prova2-golang/internal/domain"
+)
+
+// WebhookStore - Define o contrato de persistência das assinaturas de webhooks e das entregas de eventos.
+type WebhookStore interface {
+	GetSubscriptions() ([]domain.WebhookSubscription, error)
+	GetSubscriptionByID(id int) (domain.WebhookSubscription, error)
+	SaveSubscription(s domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	UpdateSubscription(s domain.WebhookSubscription) error
+	DeleteSubscription(id int) error
+	SaveDeliveries(deliveries []domain.WebhookDelivery) error
+	GetDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	GetDeliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error)
+	GetDeliveryByID(id int) (domain.WebhookDelivery, error)
+	UpdateDelivery(d domain.WebhookDelivery, nextAttemptAt time.Time) error
+}
+
+// NewSQLWebhook - Inicializa interface WebhookStore
+func NewSQLWebhook() WebhookStore {
+	database, err := sql.Open("mysql", DataSourceName)
+	if err != nil {
+		panic(err)
+	}
+	return &webhookStore{db: database}
+}
+
+type webhookStore struct {
+	db *sql.DB
+}
+
+const subscriptionColumns = "id, url, secret, events, active, DATE_FORMAT(created_at,'%d/%m/%Y %H:%i')"
+
+func scanSubscription(row rowScanner) (domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	var events []byte
+	var active bool
+	if err := row.Scan(
+		&subscription.Id,
+		&subscription.URL,
+		&subscription.Secret,
+		&events,
+		&active,
+		&subscription.CreatedAt); err != nil {
+		return subscription, err
+	}
+	subscription.Active = &active
+	return subscription, json.Unmarshal(events, &subscription.Events)
+}
+
+// GetSubscriptions - retorna todas as assinaturas de webhooks
+func (sw *webhookStore) GetSubscriptions() ([]domain.WebhookSubscription, error) {
+	rows, err := sw.db.Query("SELECT " + subscriptionColumns + " FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return subscriptions, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// GetSubscriptionByID - retorna uma assinatura de webhook por id
+func (sw *webhookStore) GetSubscriptionByID(id int) (domain.WebhookSubscription, error) {
+	subscription, err := scanSubscription(sw.db.QueryRow("SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return subscription, ErrNotFound
+	}
+	return subscription, err
+}
+
+// SaveSubscription - insere uma nova assinatura de webhook
+func (sw *webhookStore) SaveSubscription(s domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	events, err := json.Marshal(s.Events)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	result, err := sw.db.Exec("INSERT INTO webhook_subscriptions(url, secret, events, active, created_at) VALUES (?,?,?,?,?)",
+		s.URL,
+		s.Secret,
+		string(events),
+		s.Active == nil || *s.Active,
+		time.Now())
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	lastInsertedID, err := result.LastInsertId()
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return sw.GetSubscriptionByID(int(lastInsertedID))
+}
+
+// UpdateSubscription - atualiza url, segredo, eventos e situação de uma assinatura
+func (sw *webhookStore) UpdateSubscription(s domain.WebhookSubscription) error {
+	events, err := json.Marshal(s.Events)
+	if err != nil {
+		return err
+	}
+	result, err := sw.db.Exec("UPDATE webhook_subscriptions SET url = ?, secret = ?, events = ?, active = ? WHERE id = ?",
+		s.URL,
+		s.Secret,
+		string(events),
+		s.Active == nil || *s.Active,
+		s.Id)
+	return expectOneRow(result, err)
+}
+
+// DeleteSubscription - exclui uma assinatura e o seu histórico de entregas
+func (sw *webhookStore) DeleteSubscription(id int) error {
+	tx, err := sw.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE id_subscription = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM webhook_subscriptions WHERE id = ?", id)
+	if err := expectOneRow(result, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveDeliveries - agenda as entregas de um evento para envio imediato. Uma entrega já agendada para a
+// mesma assinatura e o mesmo evento é mantida, então republicar um evento não duplica as entregas.
+func (sw *webhookStore) SaveDeliveries(deliveries []domain.WebhookDelivery) error {
+	tx, err := sw.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, d := range deliveries {
+		if _, err := tx.Exec("INSERT INTO webhook_deliveries(id_subscription, event_id, event_type, payload, status, attempts, next_attempt_at, created_at) VALUES (?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE id = id",
+			d.IdSubscription,
+			d.EventId,
+			d.EventType,
+			string(d.Payload),
+			domain.DeliveryPending,
+			0,
+			now,
+			now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// maxDeliveryError é o tamanho da coluna last_error
+const maxDeliveryError = 500
+
+const deliveryColumns = "id, id_subscription, event_id, event_type, payload, status, attempts, DATE_FORMAT(next_attempt_at,'%d/%m/%Y %H:%i:%s'), last_error, response_status, DATE_FORMAT(created_at,'%d/%m/%Y %H:%i:%s'), DATE_FORMAT(delivered_at,'%d/%m/%Y %H:%i:%s')"
+
+func scanDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt, lastError, deliveredAt sql.NullString
+	var responseStatus sql.NullInt64
+	err := row.Scan(
+		&delivery.Id,
+		&delivery.IdSubscription,
+		&delivery.EventId,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&lastError,
+		&responseStatus,
+		&delivery.CreatedAt,
+		&deliveredAt)
+	delivery.Payload = payload
+	delivery.NextAttemptAt = nextAttemptAt.String
+	delivery.LastError = lastError.String
+	delivery.ResponseStatus = int(responseStatus.Int64)
+	delivery.DeliveredAt = deliveredAt.String
+	return delivery, err
+}
+
+func (sw *webhookStore) queryDeliveries(query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := sw.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetDueDeliveries - retorna as entregas pendentes cuja próxima tentativa já chegou, das mais antigas às mais novas
+func (sw *webhookStore) GetDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return sw.queryDeliveries("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?", domain.DeliveryPending, now, limit)
+}
+
+// GetDeliveries - retorna o histórico de entregas de uma assinatura, ou de todas quando subscriptionID é zero,
+// opcionalmente filtrado pela situação
+func (sw *webhookStore) GetDeliveries(subscriptionID int, status string) ([]domain.WebhookDelivery, error) {
+	return sw.queryDeliveries("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE (? = 0 OR id_subscription = ?) AND (? = '' OR status = ?) ORDER BY created_at DESC, id DESC", subscriptionID, subscriptionID, status, status)
+}
+
+// GetDeliveryByID - retorna uma entrega por id
+func (sw *webhookStore) GetDeliveryByID(id int) (domain.WebhookDelivery, error) {
+	delivery, err := scanDelivery(sw.db.QueryRow("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return delivery, ErrNotFound
+	}
+	return delivery, err
+}
+
+// UpdateDelivery - registra o resultado de uma tentativa de entrega e agenda a próxima
+func (sw *webhookStore) UpdateDelivery(d domain.WebhookDelivery, nextAttemptAt time.Time) error {
+	var deliveredAt, next interface{}
+	if d.Status == domain.DeliveryDelivered {
+		deliveredAt = time.Now()
+	}
+	if !nextAttemptAt.IsZero() {
+		next = nextAttemptAt
+	}
+	if len(d.LastError) > maxDeliveryError {
+		d.LastError = d.LastError[:maxDeliveryError]
+	}
+	var responseStatus interface{}
+	if d.ResponseStatus != 0 {
+		responseStatus = d.ResponseStatus
+	}
+	result, err := sw.db.Exec("UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, response_status = ?, delivered_at = ? WHERE id = ?",
+		d.Status,
+		d.Attempts,
+		next,
+		nullString(d.LastError),
+		responseStatus,
+		deliveredAt,
+		d.Id)
+	return expectOneRow(result, err)
+}
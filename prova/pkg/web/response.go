@@ -2,24 +2,42 @@ package web
 
 import "github.com/gin-gonic/gin"
 
-type errorResponse struct {
+// ErrorResponse é o envelope das respostas de erro e de exclusão
+type ErrorResponse struct {
 	StatusCode int    `json:"status_code"`
 	Status     string `json:"status"`
 	Message    string `json:"message"`
 }
 
-type response struct {
+// FieldError descreve um campo inválido da requisição: onde está (body, path ou query), o nome,
+// com a posição em listas e objetos, e o problema encontrado
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse é o envelope de erro com a lista dos campos inválidos
+type ValidationErrorResponse struct {
+	StatusCode int          `json:"status_code"`
+	Status     string       `json:"status"`
+	Message    string       `json:"message"`
+	Errors     []FieldError `json:"errors"`
+}
+
+// Response é o envelope das respostas de êxito
+type Response struct {
 	Data interface{} `json:"data"`
 }
 
 // ResponseOK escreve uma mensagem de êxito
 func ResponseOK(ctx *gin.Context, statusCode int, data interface{}) {
-	ctx.JSON(statusCode, response{data})
+	ctx.JSON(statusCode, Response{data})
 }
 
 // BadResponse escreve uma mensagem indicando que a operação não foi bem sucedida
 func BadResponse(ctx *gin.Context, statusCode int, status, message string) {
-	ctx.JSON(status, errorResponse{
+	ctx.JSON(statusCode, ErrorResponse{
 		StatusCode: statusCode,
 		Status:     status,
 		Message:    message,
@@ -27,9 +45,19 @@ func BadResponse(ctx *gin.Context, statusCode int, status, message string) {
 }
 
 func DeleteResponse(ctx *gin.Context, statusCode int, message string) {
-	ctx.JSON(statusCode, errorResponse{
+	ctx.JSON(statusCode, ErrorResponse{
 		StatusCode: statusCode,
 		Status:     "success",
 		Message:    message,
 	})
 }
+
+// ValidationResponse escreve uma mensagem de erro com os campos inválidos
+func ValidationResponse(ctx *gin.Context, statusCode int, message string, errors []FieldError) {
+	ctx.JSON(statusCode, ValidationErrorResponse{
+		StatusCode: statusCode,
+		Status:     "error",
+		Message:    message,
+		Errors:     errors,
+	})
+}